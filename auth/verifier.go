@@ -24,7 +24,7 @@ func (v *VerifierDetails) FromVerifierDetailsID(vID VerifierDetailsID) error {
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 2 {
-		return errors.New("Error parsing PSSIDDetails, too few fields")
+		return errors.New("Error parsing VerifierDetails, did not find 2 fields exactly")
 	}
 
 	v.Verifier = substrings[0]
@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func FuzzFromVerifierDetailsID(f *testing.F) {
+	f.Add(string((&VerifierDetails{Verifier: "google", VerifierID: "someone@gmail.com"}).ToVerifierDetailsID()))
+	f.Add(string((&VerifierDetails{Verifier: "test", VerifierID: ""}).ToVerifierDetailsID()))
+	f.Add("google")
+	f.Add("google\x1ca\x1cb")
+	f.Fuzz(func(t *testing.T, id string) {
+		var verifierDetails VerifierDetails
+		if verifierDetails.FromVerifierDetailsID(VerifierDetailsID(id)) != nil {
+			assert.Equal(t, VerifierDetails{}, verifierDetails)
+			return
+		}
+		assert.Equal(t, VerifierDetailsID(id), verifierDetails.ToVerifierDetailsID())
+	})
+}
@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/torusresearch/bijson"
+)
+
+const (
+	// ManifestVersion is bumped whenever the archive layout changes
+	ManifestVersion = 1
+	manifestName    = "manifest.json"
+)
+
+// FileEntry describes a file in the archive, paths are slash separated and relative
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest is the first entry of every archive
+type Manifest struct {
+	Version     int         `json:"version"`
+	CreatedAt   int64       `json:"created_at"`
+	NodeVersion string      `json:"node_version"`
+	NodeAddress string      `json:"node_address"`
+	Epoch       int         `json:"epoch"`
+	BlockHeight int64       `json:"block_height"`
+	DBBackend   string      `json:"db_backend"`
+	Files       []FileEntry `json:"files"`
+}
+
+// Validate checks that the archive was taken by nodeAddress in epoch
+func (m Manifest) Validate(epoch int, nodeAddress string) error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	if m.Epoch != epoch {
+		return fmt.Errorf("backup was taken in epoch %d, expected epoch %d", m.Epoch, epoch)
+	}
+	if !strings.EqualFold(m.NodeAddress, nodeAddress) {
+		return fmt.Errorf("backup was taken by node %s, expected node %s", m.NodeAddress, nodeAddress)
+	}
+	return nil
+}
+
+func hashFile(path string) (size int64, sum string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err = io.Copy(h, f)
+	if err != nil {
+		return
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Write archives every regular file under dir into w. File entries of the manifest
+// are filled in here, the remaining fields are left as provided.
+func Write(w io.Writer, dir string, manifest Manifest, passphrase string) (Manifest, error) {
+	manifest.Version = ManifestVersion
+	manifest.Files = nil
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		size, sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, FileEntry{Path: filepath.ToSlash(rel), Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return manifest, err
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+	manifestBytes, err := bijson.Marshal(manifest)
+	if err != nil {
+		return manifest, err
+	}
+
+	ew, err := newEncryptWriter(w, passphrase)
+	if err != nil {
+		return manifest, err
+	}
+	gw := gzip.NewWriter(ew)
+	tw := tar.NewWriter(gw)
+	err = tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0600, Size: int64(len(manifestBytes))})
+	if err != nil {
+		return manifest, err
+	}
+	if _, err = tw.Write(manifestBytes); err != nil {
+		return manifest, err
+	}
+	for _, entry := range manifest.Files {
+		if err = writeTarFile(tw, dir, entry); err != nil {
+			return manifest, err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return manifest, err
+	}
+	if err = gw.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, ew.Close()
+}
+
+func writeTarFile(tw *tar.Writer, dir string, entry FileEntry) error {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = tw.WriteHeader(&tar.Header{Name: entry.Path, Mode: 0600, Size: entry.Size})
+	if err != nil {
+		return err
+	}
+	// the file must not change after it was hashed
+	n, err := io.Copy(tw, io.LimitReader(f, entry.Size))
+	if err != nil {
+		return err
+	}
+	if n != entry.Size {
+		return fmt.Errorf("%s changed while being archived", entry.Path)
+	}
+	return nil
+}
+
+// Reader reads an archive written by Write
+type Reader struct {
+	tr       *tar.Reader
+	manifest Manifest
+}
+
+// Open decrypts the archive and reads its manifest
+func Open(r io.Reader, passphrase string) (*Reader, error) {
+	dr, err := newDecryptReader(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	gr, err := gzip.NewReader(dr)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if hdr.Name != manifestName {
+		return nil, errors.New("backup archive does not start with a manifest")
+	}
+	var manifest Manifest
+	manifestBytes, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	err = bijson.Unmarshal(manifestBytes, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{tr: tr, manifest: manifest}, nil
+}
+
+func (r *Reader) Manifest() Manifest {
+	return r.manifest
+}
+
+// Extract writes all files into dir, verifying them against the manifest.
+// dir should be a fresh staging directory, on error it may contain partial files.
+func (r *Reader) Extract(dir string) error {
+	expected := make(map[string]FileEntry)
+	for _, entry := range r.manifest.Files {
+		expected[entry.Path] = entry
+	}
+	for {
+		hdr, err := r.tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		entry, ok := expected[hdr.Name]
+		if !ok {
+			return fmt.Errorf("unexpected file %s in backup archive", hdr.Name)
+		}
+		delete(expected, hdr.Name)
+		if err = extractFile(r.tr, dir, entry); err != nil {
+			return err
+		}
+	}
+	for path := range expected {
+		return fmt.Errorf("file %s is missing from backup archive", path)
+	}
+	return nil
+}
+
+func extractFile(src io.Reader, dir string, entry FileEntry) error {
+	target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+	if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("illegal file path %s in backup archive", entry.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), src)
+	if err != nil {
+		return err
+	}
+	if n != entry.Size || hex.EncodeToString(h.Sum(nil)) != entry.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s in backup archive", entry.Path)
+	}
+	return f.Sync()
+}
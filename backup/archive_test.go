@@ -0,0 +1,121 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "0x2E1A7d8a3C4c9f3bB1A9E0c1e5D6F7a8B9c0D1e2"
+
+func writeTestTree(t *testing.T) (dir string, files map[string][]byte) {
+	dir, err := ioutil.TempDir("", "backup_src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := make([]byte, 3*chunkSize+17)
+	_, _ = rand.Read(large)
+	files = map[string][]byte{
+		"torusdb.kv": []byte("torus records"),
+		"tmstate.kv": {},
+		"tendermint/config/priv_validator_key.json": []byte(`{"priv_key":"secret"}`),
+		"tendermint/data/blockstore.db/000001.ldb":  large,
+	}
+	for path, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+		assert.NoError(t, ioutil.WriteFile(target, content, 0600))
+	}
+	return dir, files
+}
+
+func createTestArchive(t *testing.T) (archive []byte, files map[string][]byte) {
+	dir, files := writeTestTree(t)
+	defer os.RemoveAll(dir)
+	var buf bytes.Buffer
+	manifest, err := Write(&buf, dir, Manifest{NodeAddress: testAddress, Epoch: 3, BlockHeight: 42}, "passphrase")
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Files, len(files))
+	return buf.Bytes(), files
+}
+
+func TestRoundTrip(t *testing.T) {
+	archive, files := createTestArchive(t)
+	// nothing in the archive should be readable without the passphrase
+	assert.False(t, bytes.Contains(archive, []byte("secret")))
+	assert.False(t, bytes.Contains(archive, []byte("manifest")))
+
+	r, err := Open(bytes.NewReader(archive), "passphrase")
+	assert.NoError(t, err)
+	manifest := r.Manifest()
+	assert.Equal(t, ManifestVersion, manifest.Version)
+	assert.Equal(t, int64(42), manifest.BlockHeight)
+	assert.NoError(t, manifest.Validate(3, testAddress))
+
+	dir, err := ioutil.TempDir("", "backup_dst")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, r.Extract(dir))
+	for path, content := range files {
+		restored, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		assert.NoError(t, err)
+		assert.Equal(t, content, restored, path)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	archive, _ := createTestArchive(t)
+	_, err := Open(bytes.NewReader(archive), "wrong")
+	assert.Equal(t, ErrDecrypt, err)
+}
+
+func TestTamperedArchive(t *testing.T) {
+	archive, _ := createTestArchive(t)
+	for _, offset := range []int{headerSize - 1, headerSize + 10, len(archive) / 2, len(archive) - 1} {
+		tampered := append([]byte{}, archive...)
+		tampered[offset] ^= 0xff
+		err := openAndExtract(t, tampered)
+		assert.Error(t, err, "tampering at offset %d should be detected", offset)
+	}
+}
+
+func TestTruncatedArchive(t *testing.T) {
+	archive, _ := createTestArchive(t)
+	for _, length := range []int{headerSize, headerSize + 100, len(archive) / 2, len(archive) - 1} {
+		err := openAndExtract(t, archive[:length])
+		assert.Error(t, err, "truncation to %d bytes should be detected", length)
+	}
+}
+
+func openAndExtract(t *testing.T, archive []byte) error {
+	r, err := Open(bytes.NewReader(archive), "passphrase")
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "backup_dst")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	return r.Extract(dir)
+}
+
+func TestManifestValidate(t *testing.T) {
+	manifest := Manifest{Version: ManifestVersion, NodeAddress: testAddress, Epoch: 3}
+	assert.NoError(t, manifest.Validate(3, testAddress))
+	assert.Error(t, manifest.Validate(4, testAddress))
+	assert.Error(t, manifest.Validate(3, "0x0000000000000000000000000000000000000000"))
+	manifest.Version = ManifestVersion + 1
+	assert.Error(t, manifest.Validate(3, testAddress))
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup_dst")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = extractFile(bytes.NewReader(nil), dir, FileEntry{Path: "../escaped"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Archives are encrypted with AES-256-GCM in fixed size chunks so that
+// large databases can be streamed. Each chunk is authenticated with the header
+// and a final flag, which detects reordering, truncation and tampering.
+//
+//	header: magic (8) | version (1) | salt (16) | nonce prefix (4)
+//	chunk:  final flag (1) | ciphertext length (4) | ciphertext
+var archiveMagic = []byte("TORUSBAK")
+
+const (
+	cryptoVersion   = 1
+	saltSize        = 16
+	noncePrefixSize = 4
+	chunkSize       = 64 * 1024
+	headerSize      = 8 + 1 + saltSize + noncePrefixSize
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var ErrDecrypt = errors.New("could not decrypt backup archive, wrong passphrase or corrupted archive")
+
+func deriveKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint64) []byte {
+	nonce := make([]byte, noncePrefixSize+8)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint64(nonce[noncePrefixSize:], counter)
+	return nonce
+}
+
+func chunkAAD(header []byte, final bool) []byte {
+	aad := make([]byte, len(header)+1)
+	copy(aad, header)
+	if final {
+		aad[len(header)] = 1
+	}
+	return aad
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// newEncryptWriter writes the header to w and returns a writer which must be closed
+// to write the final chunk
+func newEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	header := make([]byte, headerSize)
+	copy(header, archiveMagic)
+	header[len(archiveMagic)] = cryptoVersion
+	if _, err := io.ReadFull(rand.Reader, header[len(archiveMagic)+1:]); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, header[len(archiveMagic)+1:len(archiveMagic)+1+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, header: header}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encryptWriter")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		space := chunkSize - len(e.buf)
+		if space > len(p) {
+			space = len(p)
+		}
+		e.buf = append(e.buf, p[:space]...)
+		p = p[space:]
+		// only flush full chunks once more data arrives, the last chunk is written on Close
+		if len(e.buf) == chunkSize && len(p) > 0 {
+			if err := e.flush(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	nonce := chunkNonce(e.header[headerSize-noncePrefixSize:], e.counter)
+	ciphertext := e.aead.Seal(nil, nonce, e.buf, chunkAAD(e.header, final))
+	frame := make([]byte, 5)
+	if final {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(ciphertext)))
+	if _, err := e.w.Write(frame); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(ciphertext); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+type decryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	header  []byte
+	buf     *bytes.Reader
+	counter uint64
+	final   bool
+}
+
+func newDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.New("backup archive is too short")
+	}
+	if !bytes.Equal(header[:len(archiveMagic)], archiveMagic) {
+		return nil, errors.New("not a torus backup archive")
+	}
+	if header[len(archiveMagic)] != cryptoVersion {
+		return nil, errors.New("unsupported backup archive version")
+	}
+	aead, err := deriveKey(passphrase, header[len(archiveMagic)+1:len(archiveMagic)+1+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: r, aead: aead, header: header, buf: bytes.NewReader(nil)}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for d.buf.Len() == 0 {
+		if d.final {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	return d.buf.Read(p)
+}
+
+func (d *decryptReader) next() error {
+	frame := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, frame); err != nil {
+		// a missing final chunk means the archive was truncated
+		return io.ErrUnexpectedEOF
+	}
+	final := frame[0] == 1
+	length := binary.BigEndian.Uint32(frame[1:])
+	if length > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecrypt
+	}
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(d.r, ciphertext); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	nonce := chunkNonce(d.header[headerSize-noncePrefixSize:], d.counter)
+	plaintext, err := d.aead.Open(nil, nonce, ciphertext, chunkAAD(d.header, final))
+	if err != nil {
+		return ErrDecrypt
+	}
+	d.counter++
+	d.final = final
+	d.buf = bytes.NewReader(plaintext)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-node/backup"
+	"github.com/torusresearch/torus-node/dkgnode"
+	"github.com/torusresearch/torus-node/mrpc"
+)
+
+// passphrases can be passed through the environment to keep them out of the process list
+const passphraseEnv = "BACKUP_PASSPHRASE"
+
+func passphraseOrEnv(passphrase string) (string, error) {
+	if passphrase == "" {
+		passphrase = os.Getenv(passphraseEnv)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("a passphrase is required, use -passphrase or %s", passphraseEnv)
+	}
+	return passphrase, nil
+}
+
+func printManifest(manifest backup.Manifest) {
+	byt, err := bijson.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Println(string(byt))
+}
+
+// runBackup asks a running node to create a backup through its management RPC
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	rpcURL := fs.String("rpc", "", "management RPC url of the running node, e.g. http://localhost:<managementRPCPort>")
+	path := fs.String("path", "", "path of the archive on the node's filesystem, must not exist yet")
+	passphrase := fs.String("passphrase", "", "passphrase used to encrypt the archive, defaults to $"+passphraseEnv)
+	_ = fs.Parse(args)
+	if *rpcURL == "" || *path == "" {
+		return errors.New("-rpc and -path are required")
+	}
+	pass, err := passphraseOrEnv(*passphrase)
+	if err != nil {
+		return err
+	}
+
+	reqBody, err := bijson.Marshal(struct {
+		JSONRPC string                  `json:"jsonrpc"`
+		ID      int                     `json:"id"`
+		Method  string                  `json:"method"`
+		Params  mrpc.CreateBackupParams `json:"params"`
+	}{"2.0", 1, "CreateBackup", mrpc.CreateBackupParams{Path: *path, Passphrase: pass}})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(*rpcURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var rpcResp struct {
+		Result *mrpc.CreateBackupResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err = bijson.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("unexpected response %s: %v", string(respBody), err)
+	}
+	if rpcResp.Error != nil {
+		return errors.New(rpcResp.Error.Message)
+	}
+	if rpcResp.Result == nil {
+		return fmt.Errorf("unexpected response %s", string(respBody))
+	}
+	printManifest(rpcResp.Result.Manifest)
+	return nil
+}
+
+// runRestore restores a backup archive while the node is stopped
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	opts := dkgnode.RestoreOptions{}
+	fs.StringVar(&opts.ArchivePath, "archive", "", "path of the backup archive")
+	fs.StringVar(&opts.BasePath, "basePath", "/.torus", "base path of the node to restore into")
+	fs.StringVar(&opts.DBBackend, "dbBackend", "", "database backend to restore into, defaults to goleveldb")
+	fs.StringVar(&opts.Passphrase, "passphrase", "", "passphrase of the archive, defaults to $"+passphraseEnv)
+	fs.IntVar(&opts.Epoch, "epoch", 0, "epoch the backup must have been taken in")
+	fs.StringVar(&opts.NodeAddress, "address", "", "ethereum address of the node the backup must belong to")
+	fs.BoolVar(&opts.Force, "force", false, "move existing databases aside instead of refusing to restore")
+	_ = fs.Parse(args)
+	// -epoch has no safe default, so it has to be given like -archive and -address
+	epochSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "epoch" {
+			epochSet = true
+		}
+	})
+	if opts.ArchivePath == "" || opts.NodeAddress == "" || !epochSet {
+		return errors.New("-archive, -address and -epoch are required")
+	}
+	var err error
+	opts.Passphrase, err = passphraseOrEnv(opts.Passphrase)
+	if err != nil {
+		return err
+	}
+	manifest, err := dkgnode.RestoreBackup(opts)
+	if err != nil {
+		return err
+	}
+	printManifest(manifest)
+	return nil
+}
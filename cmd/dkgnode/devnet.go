@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/torusresearch/torus-node/dkgnode"
+)
+
+// runDevnet runs a local cluster of nodes with generated keys until it is interrupted
+func runDevnet(args []string) error {
+	fs := flag.NewFlagSet("devnet", flag.ExitOnError)
+	opts := dkgnode.DevnetOptions{}
+	fs.StringVar(&opts.Dir, "dir", "devnet", "directory of the devnet, a devnet already in it is started again")
+	fs.IntVar(&opts.Nodes, "nodes", 5, "number of nodes")
+	fs.IntVar(&opts.K, "k", 0, "threshold of the epoch, defaults to a majority of the nodes")
+	fs.IntVar(&opts.T, "t", 0, "number of malicious nodes tolerated, defaults to (k-1)/2")
+	fs.IntVar(&opts.KeyBuffer, "keyBuffer", 10, "keys generated before the devnet is ready")
+	fs.BoolVar(&opts.EncryptShares, "encryptShares", false, "have nodes encrypt the shares they return")
+	fs.IntVar(&opts.BasePort, "basePort", 7000, "node i listens for HTTP on basePort+10*i, and uses the next 5 ports")
+	fs.StringVar(&opts.Binary, "binary", "", "dkgnode binary the nodes run, defaults to this one")
+	fs.StringVar(&opts.LogLevel, "logLevel", "info", "log level of the nodes")
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	osSignal := make(chan os.Signal, 1)
+	signal.Notify(osSignal, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-osSignal
+		cancel()
+	}()
+	return dkgnode.RunDevnet(ctx, opts)
+}
@@ -1,12 +1,30 @@
 package main
 
 import (
+	"os"
+
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/torus-node/dkgnode"
 	"github.com/torusresearch/torus-node/version"
 )
 
+// subcommands run instead of the node, they parse their own flags
+var subcommands = map[string]func(args []string) error{
+	"backup":  runBackup,
+	"restore": runRestore,
+	"replay":  runReplay,
+	"devnet":  runDevnet,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				logging.WithError(err).Fatal(os.Args[1] + " failed")
+			}
+			return
+		}
+	}
 	/* Parse the provided parameters on command line */
 	logging.WithField("version", version.NodeVersion).Info("TORUS NODE STARTING...")
 	dkgnode.New()
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/torusresearch/torus-node/dkgnode"
+)
+
+// runReplay replays a recording made with recordEventsPath against some of the node's services,
+// the other services answer with the responses they gave during the recording
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	opts := dkgnode.ReplayOptions{}
+	fs.StringVar(&opts.RecordingPath, "recording", "", "path of the event recording")
+	services := fs.String("services", "", "comma separated services to run, e.g. pss,database")
+	fs.Float64Var(&opts.Speed, "speed", 0, "speed relative to the recording, 0 replays without waiting")
+	_ = fs.Parse(args)
+	if opts.RecordingPath == "" || *services == "" {
+		return errors.New("-recording and -services are required")
+	}
+	for _, service := range strings.Split(*services, ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			opts.Services = append(opts.Services, service)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	osSignal := make(chan os.Signal, 1)
+	signal.Notify(osSignal, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-osSignal
+		cancel()
+	}()
+	return dkgnode.ReplayRecording(ctx, opts)
+}
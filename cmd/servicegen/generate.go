@@ -0,0 +1,560 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const annotationPrefix = "//servicegen:"
+
+// imports needed by the generated code regardless of the definitions
+var requiredImports = []importSpec{
+	{name: "context", path: "context"},
+	{name: "fmt", path: "fmt"},
+	{name: "retry", path: "github.com/avast/retry-go"},
+	{name: "logging", path: "github.com/sirupsen/logrus", alias: true},
+	{name: "eventbus", path: "github.com/torusresearch/torus-node/eventbus"},
+}
+
+// names used by the generated client stubs
+var reservedNames = map[string]bool{
+	"m":              true,
+	"err":            true,
+	"data":           true,
+	"methodResponse": true,
+}
+
+type importSpec struct {
+	name  string
+	path  string
+	alias bool
+}
+
+type service struct {
+	iface   string
+	name    string
+	prefix  string
+	methods []*method
+}
+
+type method struct {
+	goName     string
+	methodName string
+	params     []field
+	results    []field
+	returnsErr bool
+
+	policy  string
+	message string
+
+	wire   []string
+	decode string
+	manual bool
+}
+
+type field struct {
+	name      string
+	typ       string
+	ptr       bool
+	generated bool
+	// funcs and channels stay with the client and are not sent to the service
+	local bool
+}
+
+// Generate returns the generated source for the service definitions in src
+func Generate(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []*service
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			args, found := annotation(doc, "service")
+			if !found {
+				continue
+			}
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			if !ok {
+				return nil, fmt.Errorf("%v: %v is not an interface", fset.Position(typeSpec.Pos()), typeSpec.Name.Name)
+			}
+			if len(args) != 2 {
+				return nil, fmt.Errorf("%v: expected //servicegen:service <service name> <Go prefix>", fset.Position(typeSpec.Pos()))
+			}
+			s := &service{iface: typeSpec.Name.Name, name: args[0], prefix: args[1]}
+			for _, m := range iface.Methods.List {
+				parsed, err := parseMethod(fset, m)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", fset.Position(m.Pos()), err)
+				}
+				s.methods = append(s.methods, parsed)
+			}
+			services = append(services, s)
+		}
+	}
+	if len(services) == 0 {
+		return nil, errors.New("no //servicegen:service interfaces found")
+	}
+
+	var body bytes.Buffer
+	for _, s := range services {
+		writeService(&body, s)
+	}
+	return finish(filepath.Base(filename), file, body.Bytes())
+}
+
+// annotation returns the space separated arguments of //servicegen:<key>
+func annotation(doc *ast.CommentGroup, key string) ([]string, bool) {
+	if doc == nil {
+		return nil, false
+	}
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, annotationPrefix) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(c.Text, annotationPrefix))
+		if len(fields) > 0 && fields[0] == key {
+			return fields[1:], true
+		}
+	}
+	return nil, false
+}
+
+// message returns the rest of the line after //servicegen:<key>
+func message(doc *ast.CommentGroup, key string) (string, bool) {
+	args, found := annotation(doc, key)
+	return strings.Join(args, " "), found
+}
+
+func parseMethod(fset *token.FileSet, f *ast.Field) (*method, error) {
+	if len(f.Names) != 1 {
+		return nil, errors.New("embedded interfaces are not supported")
+	}
+	funcType := f.Type.(*ast.FuncType)
+	m := &method{goName: f.Names[0].Name, methodName: snakeCase(f.Names[0].Name)}
+
+	params := expandFields(fset, funcType.Params, "arg")
+	if len(params) == 0 || params[0].typ != "context.Context" {
+		return nil, fmt.Errorf("%v must take a context.Context first", m.goName)
+	}
+	m.params = params[1:]
+	results := expandFields(fset, funcType.Results, "r")
+	if len(results) > 0 && results[len(results)-1].typ == "error" {
+		m.returnsErr = true
+		results = results[:len(results)-1]
+	}
+	m.results = results
+	for _, p := range append(append([]field{}, m.params...), m.results...) {
+		if reservedNames[p.name] {
+			return nil, fmt.Errorf("%v: %v is reserved for the generated code", m.goName, p.name)
+		}
+	}
+	if len(m.results) > 1 {
+		for _, r := range m.results {
+			if r.generated {
+				return nil, fmt.Errorf("%v: results must be named when there is more than one", m.goName)
+			}
+		}
+	}
+
+	if args, found := annotation(f.Doc, "method"); found {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%v: expected //servicegen:method <name>", m.goName)
+		}
+		m.methodName = args[0]
+	}
+	for _, policy := range []string{"retry", "fatal", "log"} {
+		msg, found := message(f.Doc, policy)
+		if !found {
+			continue
+		}
+		if m.policy != "" {
+			return nil, fmt.Errorf("%v: only one of retry, fatal and log may be used", m.goName)
+		}
+		if msg == "" {
+			return nil, fmt.Errorf("%v: %v needs a message", m.goName, policy)
+		}
+		m.policy, m.message = policy, msg
+	}
+	if args, found := annotation(f.Doc, "decode"); found {
+		if len(args) != 2 || len(m.results) != 1 {
+			return nil, fmt.Errorf("%v: expected //servicegen:decode <type> <func> on a method with one result", m.goName)
+		}
+		m.wire = []string{args[0]}
+		m.decode = args[1]
+	}
+	if args, found := annotation(f.Doc, "manual"); found {
+		if m.decode != "" {
+			return nil, fmt.Errorf("%v: manual methods can not be decoded", m.goName)
+		}
+		m.manual = true
+		m.wire = args
+	} else if m.returnsErr == (m.policy != "") {
+		return nil, fmt.Errorf("%v: methods need one of retry, fatal and log unless they return an error", m.goName)
+	} else {
+		for _, p := range m.params {
+			if p.local {
+				return nil, fmt.Errorf("%v: %v can not be sent to the service, the method has to be manual", m.goName, p.name)
+			}
+		}
+	}
+	if m.wire == nil {
+		for _, r := range m.results {
+			m.wire = append(m.wire, r.typ)
+		}
+	}
+	return m, nil
+}
+
+// expandFields lists every name of fl separately, naming unnamed fields prefix<i>
+func expandFields(fset *token.FileSet, fl *ast.FieldList, prefix string) []field {
+	if fl == nil {
+		return nil
+	}
+	var fields []field
+	for _, f := range fl.List {
+		typ := exprString(fset, f.Type)
+		_, ptr := f.Type.(*ast.StarExpr)
+		_, isFunc := f.Type.(*ast.FuncType)
+		_, isChan := f.Type.(*ast.ChanType)
+		if len(f.Names) == 0 {
+			fields = append(fields, field{name: prefix + strconv.Itoa(len(fields)), typ: typ, ptr: ptr, generated: true, local: isFunc || isChan})
+			continue
+		}
+		for _, name := range f.Names {
+			fields = append(fields, field{name: name.Name, typ: typ, ptr: ptr, local: isFunc || isChan})
+		}
+	}
+	return fields
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+// snakeCase turns GetP2PConnection into get_p2p_connection
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var out []rune
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
+
+func exported(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func (s *service) impl() string {
+	return s.prefix + "MethodsImpl"
+}
+
+func (s *service) handler() string {
+	return s.name + "Handler"
+}
+
+func (s *service) resultType(m *method) string {
+	return s.name + m.goName + "Result"
+}
+
+func writeService(w *bytes.Buffer, s *service) {
+	fmt.Fprintf(w, "// %sMethods calls the %s service, see %s\n", s.prefix, s.name, s.iface)
+	fmt.Fprintf(w, "type %sMethods interface {\n", s.prefix)
+	fmt.Fprintf(w, "SetOwner(owner string)\nGetOwner() (owner string)\n\n%s\n}\n\n", s.iface)
+	fmt.Fprintf(w, "type %s struct {\nowner string\neventBus eventbus.Bus\n}\n\n", s.impl())
+	fmt.Fprintf(w, "func (m *%s) GetOwner() (owner string) {\nreturn m.owner\n}\n\n", s.impl())
+	fmt.Fprintf(w, "func (m *%s) SetOwner(owner string) {\nm.owner = owner\n}\n\n", s.impl())
+	for _, m := range s.methods {
+		if !m.manual {
+			writeClientMethod(w, s, m)
+		}
+	}
+	for _, m := range s.methods {
+		if len(m.wire) > 1 {
+			fmt.Fprintf(w, "type %s struct {\n", s.resultType(m))
+			for i, r := range m.results {
+				fmt.Fprintf(w, "%s %s\n", exported(r.name), m.wire[i])
+			}
+			fmt.Fprintf(w, "}\n\n")
+		}
+	}
+	writeHandler(w, s)
+	writeDispatch(w, s)
+}
+
+func writeClientMethod(w *bytes.Buffer, s *service, m *method) {
+	params := []string{"ctx context.Context"}
+	args := []string{"ctx", "m.eventBus", "m.owner", strconv.Quote(s.name), strconv.Quote(m.methodName)}
+	for _, p := range m.params {
+		params = append(params, p.name+" "+p.typ)
+		args = append(args, p.name)
+	}
+	var results []string
+	for _, r := range m.results {
+		results = append(results, r.name+" "+r.typ)
+	}
+	if m.returnsErr {
+		results = append(results, "err error")
+	}
+	fmt.Fprintf(w, "func (m *%s) %s(%s)", s.impl(), m.goName, strings.Join(params, ", "))
+	if len(results) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(results, ", "))
+	}
+	fmt.Fprintf(w, " {\n")
+	call := fmt.Sprintf("methodResponse := ServiceMethod(%s)\n", strings.Join(args, ", "))
+
+	if m.returnsErr {
+		fmt.Fprint(w, call)
+		fmt.Fprintf(w, "if methodResponse.Error != nil {\nerr = methodResponse.Error\nreturn\n}\n")
+		writeDecode(w, s, m, true)
+		fmt.Fprintf(w, "}\n\n")
+		return
+	}
+
+	body := new(bytes.Buffer)
+	fmt.Fprint(body, call)
+	if len(m.results) == 0 {
+		fmt.Fprintf(body, "return methodResponse.Error\n")
+	} else {
+		fmt.Fprintf(body, "if methodResponse.Error != nil {\nreturn methodResponse.Error\n}\n")
+		writeDecode(body, s, m, false)
+	}
+	switch m.policy {
+	case "retry":
+		fmt.Fprintf(w, "err := retry.Do(func() error {\n%s}, retryUntilDone(ctx))\n", body)
+		fmt.Fprintf(w, "if err != nil && ctx.Err() == nil {\nlogging.WithError(err).Fatal(%q)\n}\n", m.message)
+	case "fatal":
+		fmt.Fprintf(w, "err := func() error {\n%s}()\n", body)
+		fmt.Fprintf(w, "if err != nil && ctx.Err() == nil {\nlogging.WithError(err).Fatal(%q)\n}\n", m.message)
+	case "log":
+		fmt.Fprintf(w, "err := func() error {\n%s}()\n", body)
+		fmt.Fprintf(w, "if err != nil {\nlogging.WithError(err).Error(%q)\n}\n", m.message)
+	}
+	if len(m.results) > 0 {
+		fmt.Fprintf(w, "return\n")
+	}
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// writeDecode assigns methodResponse.Data to the results, either to the named
+// results of the method or from within a func() error
+func writeDecode(w *bytes.Buffer, s *service, m *method, named bool) {
+	var names, values []string
+	for _, r := range m.results {
+		names = append(names, r.name)
+		values = append(values, "data."+exported(r.name))
+	}
+	switch {
+	case len(m.results) == 0:
+		fmt.Fprintf(w, "return\n")
+	case m.decode == "" && len(m.results) == 1 && named:
+		fmt.Fprintf(w, "err = castOrUnmarshal(methodResponse.Data, &%s)\nreturn\n", names[0])
+	case m.decode == "" && len(m.results) == 1:
+		fmt.Fprintf(w, "return castOrUnmarshal(methodResponse.Data, &%s)\n", names[0])
+	default:
+		dataType := s.resultType(m)
+		if m.decode != "" {
+			dataType = m.wire[0]
+		}
+		fmt.Fprintf(w, "var data %s\n", dataType)
+		if named {
+			fmt.Fprintf(w, "err = castOrUnmarshal(methodResponse.Data, &data)\nif err != nil {\nreturn\n}\n")
+		} else {
+			fmt.Fprintf(w, "if err := castOrUnmarshal(methodResponse.Data, &data); err != nil {\nreturn err\n}\n")
+		}
+		switch {
+		case m.decode != "" && named:
+			fmt.Fprintf(w, "%s, err = %s(data)\nreturn\n", names[0], m.decode)
+		case m.decode != "":
+			fmt.Fprintf(w, "var err error\n%s, err = %s(data)\nreturn err\n", names[0], m.decode)
+		case named:
+			fmt.Fprintf(w, "%s = %s\nreturn\n", strings.Join(names, ", "), strings.Join(values, ", "))
+		default:
+			fmt.Fprintf(w, "%s = %s\nreturn nil\n", strings.Join(names, ", "), strings.Join(values, ", "))
+		}
+	}
+}
+
+// remoteParams are the parameters sent to the service
+func (m *method) remoteParams() []field {
+	var params []field
+	for _, p := range m.params {
+		if !p.local {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func (m *method) handlerSignature() string {
+	params := []string{"ctx context.Context"}
+	for _, p := range m.remoteParams() {
+		params = append(params, p.name+" "+p.typ)
+	}
+	results := append(append([]string{}, m.wire...), "error")
+	if len(results) == 1 {
+		return fmt.Sprintf("handle%s(%s) error", m.goName, strings.Join(params, ", "))
+	}
+	return fmt.Sprintf("handle%s(%s) (%s)", m.goName, strings.Join(params, ", "), strings.Join(results, ", "))
+}
+
+func writeHandler(w *bytes.Buffer, s *service) {
+	fmt.Fprintf(w, "// %s serves the methods of %s, it is implemented by the %s service\n", s.handler(), s.iface, s.name)
+	if len(s.methods) == 0 {
+		fmt.Fprintf(w, "type %s interface{}\n\n", s.handler())
+		return
+	}
+	fmt.Fprintf(w, "type %s interface {\n", s.handler())
+	for _, m := range s.methods {
+		fmt.Fprintf(w, "%s\n", m.handlerSignature())
+	}
+	fmt.Fprintf(w, "}\n\n")
+}
+
+func writeDispatch(w *bytes.Buffer, s *service) {
+	fmt.Fprintf(w, "// dispatch%s calls the handler of method with the context of the call and args unmarshalled to their types\n", s.prefix)
+	fmt.Fprintf(w, "func dispatch%s(ctx context.Context, h %s, method string, args []interface{}) (interface{}, error) {\n", s.prefix, s.handler())
+	if len(s.methods) > 0 {
+		fmt.Fprintf(w, "switch method {\n")
+	}
+	for _, m := range s.methods {
+		fmt.Fprintf(w, "case %q:\n", m.methodName)
+		params := m.remoteParams()
+		fmt.Fprintf(w, "if len(args) != %d {\nreturn nil, fmt.Errorf(\"%s service method %%v expects %d arguments, got %%d\", method, len(args))\n}\n", len(params), s.name, len(params))
+		args := []string{"ctx"}
+		for i, p := range params {
+			arg := "args" + strconv.Itoa(i)
+			typ := p.typ
+			if p.ptr {
+				// pointers are passed for types with custom marshallers, which are decoded by value
+				typ = strings.TrimPrefix(typ, "*")
+				args = append(args, "&"+arg)
+			} else {
+				args = append(args, arg)
+			}
+			fmt.Fprintf(w, "var %s %s\n", arg, typ)
+			fmt.Fprintf(w, "if err := castOrUnmarshal(args[%d], &%s); err != nil {\nreturn nil, fmt.Errorf(\"%s service method %%v could not read argument %d: %%v\", method, err)\n}\n", i, arg, s.name, i)
+		}
+		call := fmt.Sprintf("h.handle%s(%s)", m.goName, strings.Join(args, ", "))
+		switch len(m.wire) {
+		case 0:
+			fmt.Fprintf(w, "return nil, %s\n", call)
+		case 1:
+			fmt.Fprintf(w, "return %s\n", call)
+		default:
+			var names, values []string
+			for i, r := range m.results {
+				names = append(names, "r"+strconv.Itoa(i))
+				values = append(values, exported(r.name)+": r"+strconv.Itoa(i))
+			}
+			fmt.Fprintf(w, "%s, err := %s\n", strings.Join(names, ", "), call)
+			fmt.Fprintf(w, "if err != nil {\nreturn nil, err\n}\n")
+			fmt.Fprintf(w, "return %s{%s}, nil\n", s.resultType(m), strings.Join(values, ", "))
+		}
+	}
+	if len(s.methods) > 0 {
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return nil, fmt.Errorf(\"%s service method %%v not found\", method)\n}\n\n", s.name)
+}
+
+// finish adds the header and the imports used by body and formats the result
+func finish(filename string, file *ast.File, body []byte) ([]byte, error) {
+	imports := append([]importSpec{}, requiredImports...)
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		imp := importSpec{name: path.Base(p), path: p}
+		if spec.Name != nil {
+			imp.name, imp.alias = spec.Name.Name, true
+		}
+		imports = append(imports, imp)
+	}
+
+	used, err := usedPackages(body)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by servicegen from %s. DO NOT EDIT.\n\n", filename)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", file.Name.Name)
+	// standard library imports first, like goimports
+	seen := make(map[string]bool)
+	var std, other []string
+	for _, imp := range imports {
+		if !used[imp.name] || seen[imp.path] {
+			continue
+		}
+		seen[imp.path] = true
+		line := strconv.Quote(imp.path)
+		if imp.alias {
+			line = imp.name + " " + line
+		}
+		if strings.Contains(strings.Split(imp.path, "/")[0], ".") {
+			other = append(other, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+	fmt.Fprintf(&out, "%s\n\n%s\n)\n\n", strings.Join(std, "\n"), strings.Join(other, "\n"))
+	out.Write(body)
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("could not format generated code: %v", err)
+	}
+	return formatted, nil
+}
+
+// usedPackages lists the identifiers that qualify a selector in body
+func usedPackages(body []byte) (map[string]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n\n"), body...), 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse generated code: %v", err)
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return used, nil
+}
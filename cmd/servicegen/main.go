@@ -0,0 +1,61 @@
+// Command servicegen generates the ServiceLibrary client stubs and the
+// service dispatch from the annotated service interfaces in dkgnode.
+//
+// Each interface annotated with
+//
+//	//servicegen:service <service name> <Go prefix>
+//
+// becomes a <Go prefix>Methods interface and *<Go prefix>MethodsImpl client
+// that call the service over the event bus, a <service name>Handler interface
+// with one handle<Method> per method, and a dispatch<Go prefix> function that
+// unmarshals the arguments of a MethodRequest and calls the handler with the
+// context of the call, which carries the span of the call when it is traced.
+//
+// Every method takes a context.Context first. The method string defaults to the
+// snake case of the method name. Methods may be annotated with
+//
+//	//servicegen:method <name>            overrides the method string
+//	//servicegen:retry <message>          retries until ctx is done, then exits with message
+//	//servicegen:fatal <message>          exits with message on error
+//	//servicegen:log <message>            logs message on error and returns zero values
+//	//servicegen:decode <type> <func>     the service returns type, converted with func(type) (result, error)
+//	//servicegen:manual [type]            the client stub is hand-written, the service returns type
+//
+// Methods that return an error pass it on and need none of retry, fatal or log.
+// Handlers always return an error in addition to the results of the method.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "service_defs.go", "file with the annotated service interfaces")
+	out := flag.String("out", "service_library_gen.go", "generated file")
+	flag.Parse()
+
+	if err := run(*in, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "servicegen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string) error {
+	src, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	generated, err := Generate(in, src)
+	if err != nil {
+		return err
+	}
+	existing, err := ioutil.ReadFile(out)
+	if err == nil && bytes.Equal(existing, generated) {
+		return nil
+	}
+	return ioutil.WriteFile(out, generated, 0644)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedCodeIsUpToDate(t *testing.T) {
+	src, err := ioutil.ReadFile("../../dkgnode/service_defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := Generate("service_defs.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing, err := ioutil.ReadFile("../../dkgnode/service_library_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(existing, generated) {
+		t.Fatal("dkgnode/service_library_gen.go is out of date, run go generate ./dkgnode")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"GetCurrentEpoch":    "get_current_epoch",
+		"GetP2PConnection":   "get_p2p_connection",
+		"GetTMP2PConnection": "get_tmp2p_connection",
+		"SnapshotDB":         "snapshot_db",
+		"ID":                 "id",
+	} {
+		if got := snakeCase(name); got != expected {
+			t.Errorf("snakeCase(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, test := range []struct {
+		methods string
+		err     string
+	}{
+		{"Ping() error", "must take a context.Context first"},
+		{"Count(ctx context.Context) int", "need one of retry, fatal and log"},
+		{"//servicegen:retry\n\tCount(ctx context.Context) int", "retry needs a message"},
+		{"Pair(ctx context.Context) (int, string, error)", "results must be named"},
+		{"Send(ctx context.Context, data []byte) error", "data is reserved"},
+		{"Watch(ctx context.Context, handler func()) error", "has to be manual"},
+	} {
+		src := "package dkgnode\n\nimport \"context\"\n\n//servicegen:service test Test\ntype testService interface {\n\t" + test.methods + "\n}\n"
+		_, err := Generate("service_defs.go", []byte(src))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected error containing %q, got %v", test.methods, test.err, err)
+		}
+	}
+}
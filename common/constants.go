@@ -43,6 +43,9 @@ type abciServerConstants struct {
 	GetIndexesFromVerifierIdCounter string
 	GetVerifierIteratorCounter      string
 	GetVerifierIteratorNextCounter  string
+	PauseCommitsCounter             string
+	ResumeCommitsCounter            string
+	SnapshotStateCounter            string
 }
 
 type bftRuleSetConstants struct {
@@ -67,6 +70,8 @@ type dbConstants struct {
 	StorePSSCommitmentMatrixCounter    string
 	StoreCompletedKeygenShareCounter   string
 	StoreCompletedPssShareCounter      string
+	StoreCompletedKeygenCounter        string
+	StoreCompletedPSSCounter           string
 	StorePublicKeyToIndexCounter       string
 	RetrieveCommitmentMatrixCounter    string
 	RetrievePublicKeyToIndexCounter    string
@@ -80,6 +85,7 @@ type dbConstants struct {
 	RetrieveNodePubKeyCounter          string
 	StoreConnectionDetailsCounter      string
 	RetrieveConnectionDetailsCounter   string
+	SnapshotDBCounter                  string
 }
 
 type cacheConstants struct {
@@ -204,6 +210,10 @@ type p2pConstants struct {
 	GetHostAddressCounter             string
 	SignP2PMessageCounter             string
 	SendP2PMessageCounter             string
+	HandshakeCounter                  string
+	LegacyPeerCounter                 string
+	IncompatiblePeerCounter           string
+	IncompatibleSendCounter           string
 }
 
 type pingConstants struct {
@@ -257,6 +267,7 @@ type tendermintConstants struct {
 	BroadcastCounter       string
 	RegisterQueryCounter   string
 	DeRegisterQueryCounter string
+	SnapshotStoresCounter  string
 }
 
 type verifierConstants struct {
@@ -297,6 +308,9 @@ var TelemetryConstants = telemetryConstants{
 		GetIndexesFromVerifierIdCounter: "service_count_get_indexes_from_verifier_id_total",
 		GetVerifierIteratorCounter:      "service_get_verifier_iterator_total",
 		GetVerifierIteratorNextCounter:  "service_count_get_verifier_iterator_total",
+		PauseCommitsCounter:             "service_count_pause_commits_total",
+		ResumeCommitsCounter:            "service_count_resume_commits_total",
+		SnapshotStateCounter:            "service_count_snapshot_state_total",
 	},
 	BFTRuleSet: bftRuleSetConstants{
 		Prefix:                      "bft_",
@@ -319,6 +333,8 @@ var TelemetryConstants = telemetryConstants{
 		StorePSSCommitmentMatrixCounter:    "service_store_pss_commitment_matrix_total",
 		StoreCompletedKeygenShareCounter:   "service_completed_keygen_share_total",
 		StoreCompletedPssShareCounter:      "service_completed_PSS_share_total",
+		StoreCompletedKeygenCounter:        "service_store_completed_keygen_total",
+		StoreCompletedPSSCounter:           "service_store_completed_PSS_total",
 		StorePublicKeyToIndexCounter:       "service_public_key_to_index_total",
 		RetrieveCommitmentMatrixCounter:    "service_retrieve_commitment_matrix_total",
 		RetrievePublicKeyToIndexCounter:    "service_retrieve_public_key_to_index_total",
@@ -332,6 +348,7 @@ var TelemetryConstants = telemetryConstants{
 		RetrieveNodePubKeyCounter:          "service_retrieve_node_pub_key_total",
 		StoreConnectionDetailsCounter:      "service_store_connection_details_total",
 		RetrieveConnectionDetailsCounter:   "service_retrieve_connection_details_total",
+		SnapshotDBCounter:                  "service_snapshot_db_total",
 	},
 	Ethereum: ethereumConstants{
 		Prefix:                               "ethereum_",
@@ -441,6 +458,10 @@ var TelemetryConstants = telemetryConstants{
 		GetHostAddressCounter:             "service_get_host_address_total",
 		SignP2PMessageCounter:             "service_sign_p2p_message_total",
 		SendP2PMessageCounter:             "service_send_p2p_message_total",
+		HandshakeCounter:                  "handshake_total",
+		LegacyPeerCounter:                 "handshake_legacy_peer_total",
+		IncompatiblePeerCounter:           "handshake_incompatible_peer_total",
+		IncompatibleSendCounter:           "incompatible_send_total",
 	},
 	Ping: pingConstants{
 		Prefix: "ping_",
@@ -490,6 +511,7 @@ var TelemetryConstants = telemetryConstants{
 		BroadcastCounter:       "service_get_status_total",
 		RegisterQueryCounter:   "service_register_query_total",
 		DeRegisterQueryCounter: "service_deregister_query_total",
+		SnapshotStoresCounter:  "service_snapshot_stores_total",
 	},
 	Verifier: verifierConstants{
 		Prefix:              "verifier_",
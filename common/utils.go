@@ -1,6 +1,7 @@
 package common
 
 import (
+	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-node/telemetry"
 	"math/rand"
@@ -8,14 +9,18 @@ import (
 )
 
 func RandIndexes(min int, max int, length int) (res []int) {
+	return RandIndexesFrom(rand.New(rand.NewSource(time.Now().UnixNano())), min, max, length)
+}
+
+// RandIndexesFrom - RandIndexes drawing from r, so that seeded simulations pick the same indexes
+func RandIndexesFrom(r *rand.Rand, min int, max int, length int) (res []int) {
 	if max < min {
 		return
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	p := rand.Perm(max - min)
-	for _, r := range p[:length] {
-		res = append(res, r+min)
+	p := r.Perm(max - min)
+	for _, i := range p[:length] {
+		res = append(res, i+min)
 	}
 	return
 }
@@ -33,6 +38,27 @@ func GetColumnPoint(matrix [][]common.Point, columnIndex int) (column []common.P
 	return
 }
 
+// SendToAll - sends a message to every node of a network, through gossip if it is set. Any node in
+// the network can read a gossiped message, so only messages that are the same for every node and
+// carry no secrets may be gossiped. If gossip is nil or fails, each of sends runs in its own goroutine.
+func SendToAll(method string, gossip func() error, sends []func() error) {
+	if gossip != nil {
+		err := gossip()
+		if err == nil {
+			return
+		}
+		logging.WithField("method", method).WithError(err).Error("could not gossip message, sending it to each node")
+	}
+	for _, send := range sends {
+		go func(send func() error) {
+			err := send()
+			if err != nil {
+				logging.WithField("method", method).WithError(err).Error("could not send message")
+			}
+		}(send)
+	}
+}
+
 type Semaphore struct {
 	sem chan struct{}
 }
@@ -63,6 +63,8 @@ type Config struct {
 	// KeyBufferTriggerPercentage int    `json:"keybuffertriggerpercetage" env:"KEY_BUFFER_TRIGGER_PERCENTAGE"` // percetage threshold of keys left to trigger buffering 90 - 20
 	BasePath  string `json:"basepath" env:"BASE_PATH"`
 	InitEpoch int    `json:"initepoch" env:"INIT_EPOCH"`
+	// DBBackend is the storage engine for the torus db: goleveldb (default), pebble or badger
+	DBBackend string `json:"dbBackend" env:"DB_BACKEND"`
 
 	ShouldRegister          bool   `json:"register" env:"REGISTER"`
 	CPUProfileToFile        string `json:"cpuProfile" env:"CPU_PROFILE"`
@@ -86,6 +88,81 @@ type Config struct {
 	EthPollFreq       int    `json:"ethPollFreq" env:"ETH_POLL_FREQ"`
 	TendermintMetrics bool   `json:"tendermintMetrics" env:"TENDERMINT_METRICS"`
 
+	// EthConfirmations is how many blocks NodeList changes must stay unchanged before they are
+	// acted on, 0 for dev chains that mine on every transaction. Heads are polled every
+	// EthPollFreq seconds (10 by default) when the endpoint does not push them
+	EthConfirmations int `json:"ethConfirmations" env:"ETH_CONFIRMATIONS"`
+
+	// EthConnection may list several comma separated endpoints, calls fail over between them in
+	// that order. EthQuorum is how many of them must return the same result for contract reads
+	// such as epoch info and node lists, log queries and block headers, 0 reads from one endpoint.
+	// Endpoints are health checked every EthHealthCheckIntervalS seconds (15 by default)
+	EthQuorum               int `json:"ethQuorum" env:"ETH_QUORUM"`
+	EthHealthCheckIntervalS int `json:"ethHealthCheckIntervalS" env:"ETH_HEALTH_CHECK_INTERVAL_S"`
+
+	// NodeRegistry is where epochs and nodes are found, contract (default) for the NodeList at
+	// NodeListAddress or file for the directory at NodeRegistryPath. The epochs file of a file
+	// registry must be signed by NodeRegistryOwner, nodes register by writing signed files into it
+	NodeRegistry      string `json:"nodeRegistry" env:"NODE_REGISTRY"`
+	NodeRegistryPath  string `json:"nodeRegistryPath" env:"NODE_REGISTRY_PATH"`
+	NodeRegistryOwner string `json:"nodeRegistryOwner" env:"NODE_REGISTRY_OWNER"`
+
+	// Registration transactions are replaced at a 20% higher gas price when they are not mined
+	// within EthTxBumpAfterS seconds (180 by default), up to EthMaxGasPriceGwei if it is set.
+	// They are confirmed after EthConfirmations blocks
+	EthTxBumpAfterS    int `json:"ethTxBumpAfterS" env:"ETH_TX_BUMP_AFTER_S"`
+	EthMaxGasPriceGwei int `json:"ethMaxGasPriceGwei" env:"ETH_MAX_GAS_PRICE_GWEI"`
+
+	// ServiceTimeoutMS bounds calls between services, ServiceMethodTimeoutsMS overrides it with
+	// comma separated service.method=milliseconds pairs where 0 disables the timeout
+	ServiceTimeoutMS        int    `json:"serviceTimeoutMS" env:"SERVICE_TIMEOUT_MS"`
+	ServiceMethodTimeoutsMS string `json:"serviceMethodTimeoutsMS" env:"SERVICE_METHOD_TIMEOUTS_MS"`
+
+	// EventBusTopicLimits bounds the async handlers of event bus topics with comma separated
+	// topic=workers/queue/policy entries, where policy is block, drop-oldest or reject and
+	// topics ending in * match prefixes. Entries replace the defaults for their topic
+	EventBusTopicLimits string `json:"eventBusTopicLimits" env:"EVENT_BUS_TOPIC_LIMITS"`
+
+	// RecordEventsPath records every event on the system event bus to this file for replaying a
+	// session with `dkgnode replay`. Shares and keys are redacted unless RecordSecrets is set
+	RecordEventsPath string `json:"recordEventsPath" env:"RECORD_EVENTS_PATH"`
+	RecordSecrets    bool   `json:"recordSecrets" env:"RECORD_SECRETS"`
+
+	// TracingExporter enables tracing of requests, service calls, p2p messages and BFT transactions:
+	// "otlp" sends spans to the collector at TracingEndpoint, "file" appends them to the file at
+	// TracingEndpoint as JSON lines. TracingHeaders are comma separated key=value pairs added to
+	// OTLP requests, TracingServiceName defaults to dkgnode and the public URL
+	TracingExporter    string `json:"tracingExporter" env:"TRACING_EXPORTER"`
+	TracingEndpoint    string `json:"tracingEndpoint" env:"TRACING_ENDPOINT"`
+	TracingHeaders     string `json:"tracingHeaders" env:"TRACING_HEADERS"`
+	TracingServiceName string `json:"tracingServiceName" env:"TRACING_SERVICE_NAME"`
+
+	// PeerBanThreshold disconnects and bans peers whose score falls to -PeerBanThreshold (default 100,
+	// negative to never ban) for PeerBanDurationS seconds (default 600). Scores drop for invalid or
+	// unauthenticated messages and ping timeouts, and recover with a half life of PeerScoreHalfLifeS seconds (default 600)
+	PeerBanThreshold   int `json:"peerBanThreshold" env:"PEER_BAN_THRESHOLD"`
+	PeerBanDurationS   int `json:"peerBanDurationS" env:"PEER_BAN_DURATION_S"`
+	PeerScoreHalfLifeS int `json:"peerScoreHalfLifeS" env:"PEER_SCORE_HALF_LIFE_S"`
+
+	// P2PMaxMessageSize bounds p2p messages sent and received in bytes (default 16MB). Messages on
+	// persistent streams larger than P2PCompressThreshold bytes are compressed (default 64KB, negative to disable)
+	P2PMaxMessageSize    int `json:"p2pMaxMessageSize" env:"P2P_MAX_MESSAGE_SIZE"`
+	P2PCompressThreshold int `json:"p2pCompressThreshold" env:"P2P_COMPRESS_THRESHOLD"`
+
+	// PeerHealthIntervalS is how often the nodes of the current and next epoch are pinged in seconds
+	// (default 30), peers that miss PeerOfflineFailures pings in a row are reported offline (default 3)
+	PeerHealthIntervalS int `json:"peerHealthIntervalS" env:"PEER_HEALTH_INTERVAL_S"`
+	PeerOfflineFailures int `json:"peerOfflineFailures" env:"PEER_OFFLINE_FAILURES"`
+
+	// Signer selects where the node key is held: "memory" (EthPrivateKey), "keystore" or "pkcs11"
+	Signer           string `json:"signer" env:"SIGNER"`
+	KeystorePath     string `json:"keystorePath" env:"KEYSTORE_PATH"`
+	KeystorePassword string `json:"keystorePassword" env:"KEYSTORE_PASSWORD"`
+	PKCS11Module     string `json:"pkcs11Module" env:"PKCS11_MODULE"`
+	PKCS11TokenLabel string `json:"pkcs11TokenLabel" env:"PKCS11_TOKEN_LABEL"`
+	PKCS11Pin        string `json:"pkcs11Pin" env:"PKCS11_PIN"`
+	PKCS11KeyLabel   string `json:"pkcs11KeyLabel" env:"PKCS11_KEY_LABEL"`
+
 	// IDs used for oauth verification.
 	GoogleClientID       string `json:"googleClientID" env:"GOOGLE_CLIENT_ID" mutable:"yes"`
 	FacebookAppID        string `json:"facebookAppID" env:"FACEBOOK_APP_ID" mutable:"yes"`
@@ -237,7 +314,7 @@ func (c *Config) createConfigWithFlags() string {
 	ethPrivateKey := flag.String("ethprivateKey", "", "provide private key here to run node on")
 	ipAddress := flag.String("ipAddress", "", "specified IPAdress, necessary for running in an internal env e.g. docker")
 	cpuProfile := flag.String("cpuProfile", "", "write cpu profile to file")
-	ethConnection := flag.String("ethConnection", "", "ethereum endpoint")
+	ethConnection := flag.String("ethConnection", "", "ethereum endpoints, comma separated")
 	nodeListAddress := flag.String("nodeListAddress", "", "node list address on ethereum")
 	basePath := flag.String("basePath", "/.torus", "basePath for Torus node artifacts")
 	configPath := flag.String("configPath", "", "override configPath")
@@ -337,10 +414,12 @@ func LoadConfig(configPath string) *Config {
 	if conf.ProvidedIPAddress != "" {
 		logging.WithField("IPAddress", conf.ProvidedIPAddress).Info("Running")
 		conf.MainServerAddress = conf.ProvidedIPAddress + ":" + conf.HttpServerPort
-		conf.P2PListenAddress = fmt.Sprintf("/ip4/%s/tcp/1080", conf.ProvidedIPAddress)
 	}
 	conf.MainServerAddress = "0.0.0.0" + ":" + conf.HttpServerPort
-	conf.P2PListenAddress = fmt.Sprintf("/ip4/%s/tcp/1080", "0.0.0.0")
+	// nodes sharing a host, such as those of a devnet, listen on their own ports
+	if conf.P2PListenAddress == "" {
+		conf.P2PListenAddress = fmt.Sprintf("/ip4/%s/tcp/1080", "0.0.0.0")
+	}
 
 	bytConf, _ := bijson.Marshal(conf)
 	logging.WithField("finalConfiguration", string(bytConf)).Info()
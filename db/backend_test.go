@@ -0,0 +1,238 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var testBackends = []BackendType{GoLevelDBBackend, PebbleDBBackend, BadgerDBBackend}
+
+func newTempDB(t testing.TB, backend BackendType) (DB, func()) {
+	dir, err := ioutil.TempDir("", fmt.Sprintf("testdb_%s", backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewDB(dir, backend)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// forEachBackend runs the same test against every DB implementation
+func forEachBackend(t *testing.T, test func(t *testing.T, db DB)) {
+	for _, backend := range testBackends {
+		backend := backend
+		t.Run(string(backend), func(t *testing.T) {
+			db, cleanup := newTempDB(t, backend)
+			defer cleanup()
+			test(t, db)
+		})
+	}
+}
+
+func TestNewDBUnknownBackend(t *testing.T) {
+	_, err := NewDB(os.TempDir(), BackendType("unknown"))
+	if err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestBackendGetSetDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		key := []byte("key")
+		if db.Get(key) != nil || db.Has(key) {
+			t.Fatal("expected missing key")
+		}
+		db.Set(key, []byte("value"))
+		if !bytes.Equal(db.Get(key), []byte("value")) || !db.Has(key) {
+			t.Fatal("expected value after Set")
+		}
+		db.SetSync(key, []byte("value2"))
+		if !bytes.Equal(db.Get(key), []byte("value2")) {
+			t.Fatal("expected overwritten value after SetSync")
+		}
+		db.Delete(key)
+		if db.Get(key) != nil || db.Has(key) {
+			t.Fatal("expected missing key after Delete")
+		}
+		db.Set(key, []byte("value"))
+		db.DeleteSync(key)
+		if db.Has(key) {
+			t.Fatal("expected missing key after DeleteSync")
+		}
+		// deleting a missing key is a noop
+		db.Delete([]byte("missing"))
+	})
+}
+
+func TestBackendEmptyValue(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		key := []byte("empty")
+		db.Set(key, nil)
+		value := db.Get(key)
+		if value == nil || len(value) != 0 {
+			t.Fatalf("expected empty non-nil value, got %v", value)
+		}
+		if !db.Has(key) {
+			t.Fatal("expected key with empty value to exist")
+		}
+	})
+}
+
+func TestBackendGetReturnsCopy(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		key := []byte("key")
+		value := []byte("value")
+		db.Set(key, value)
+		value[0] = 'X'
+		res := db.Get(key)
+		if !bytes.Equal(res, []byte("value")) {
+			t.Fatal("db must not retain the caller's value slice")
+		}
+		res[0] = 'Y'
+		if !bytes.Equal(db.Get(key), []byte("value")) {
+			t.Fatal("db must not return its internal buffer")
+		}
+	})
+}
+
+func setKeys(db DB, keys ...string) {
+	for _, k := range keys {
+		db.Set([]byte(k), []byte("v"+k))
+	}
+}
+
+func collect(t *testing.T, itr Iterator) (keys []string) {
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		k, v := itr.Key(), itr.Value()
+		if !bytes.Equal(v, append([]byte("v"), k...)) {
+			t.Fatalf("unexpected value %s for key %s", v, k)
+		}
+		keys = append(keys, string(k))
+	}
+	return
+}
+
+func assertKeys(t *testing.T, expected []string, actual []string) {
+	if fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Fatalf("expected keys %v, got %v", expected, actual)
+	}
+}
+
+func TestBackendIterator(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		setKeys(db, "a1", "a2", "a3", "b1", "c1")
+
+		assertKeys(t, []string{"a1", "a2", "a3", "b1", "c1"}, collect(t, db.Iterator(nil, nil)))
+		assertKeys(t, []string{"a1", "a2", "a3"}, collect(t, db.Iterator([]byte("a"), []byte("b"))))
+		assertKeys(t, []string{"a2", "a3", "b1"}, collect(t, db.Iterator([]byte("a2"), []byte("c1"))))
+		assertKeys(t, []string{"b1", "c1"}, collect(t, db.Iterator([]byte("a4"), nil)))
+		assertKeys(t, nil, collect(t, db.Iterator([]byte("d"), nil)))
+
+		itr := db.Iterator([]byte("a"), []byte("b"))
+		start, end := itr.Domain()
+		itr.Close()
+		if !bytes.Equal(start, []byte("a")) || !bytes.Equal(end, []byte("b")) {
+			t.Fatal("unexpected iterator domain")
+		}
+	})
+}
+
+func TestBackendReverseIterator(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		setKeys(db, "a1", "a2", "a3", "b1", "c1")
+
+		assertKeys(t, []string{"c1", "b1", "a3", "a2", "a1"}, collect(t, db.ReverseIterator(nil, nil)))
+		// start is inclusive, end is exclusive
+		assertKeys(t, []string{"b1", "a3", "a2"}, collect(t, db.ReverseIterator([]byte("b1"), []byte("a1"))))
+		// start between keys begins at the largest key below it
+		assertKeys(t, []string{"a3", "a2", "a1"}, collect(t, db.ReverseIterator([]byte("a4"), nil)))
+		// start past the last key begins at the last key
+		assertKeys(t, []string{"c1", "b1"}, collect(t, db.ReverseIterator([]byte("d"), []byte("a3"))))
+		assertKeys(t, nil, collect(t, db.ReverseIterator([]byte("0"), nil)))
+	})
+}
+
+func TestBackendIteratorInvalid(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		itr := db.Iterator(nil, nil)
+		defer itr.Close()
+		if itr.Valid() {
+			t.Fatal("iterator over empty db should be invalid")
+		}
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Key on invalid iterator should panic")
+			}
+		}()
+		itr.Key()
+	})
+}
+
+func TestBackendBatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		setKeys(db, "a", "b")
+
+		batch := db.NewBatch()
+		batch.Set([]byte("c"), []byte("vc"))
+		batch.Set([]byte("d"), []byte("vd"))
+		batch.Delete([]byte("a"))
+		if db.Has([]byte("c")) || !db.Has([]byte("a")) {
+			t.Fatal("batch must not be applied before Write")
+		}
+		batch.Write()
+		assertKeys(t, []string{"b", "c", "d"}, collect(t, db.Iterator(nil, nil)))
+
+		batch = db.NewBatch()
+		batch.Set([]byte("e"), []byte("ve"))
+		batch.Delete([]byte("b"))
+		batch.WriteSync()
+		assertKeys(t, []string{"c", "d", "e"}, collect(t, db.Iterator(nil, nil)))
+	})
+}
+
+func TestBackendStats(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		setKeys(db, "a")
+		if len(db.Stats()) == 0 {
+			t.Fatal("expected stats")
+		}
+	})
+}
+
+func TestBackendReopen(t *testing.T) {
+	for _, backend := range testBackends {
+		backend := backend
+		t.Run(string(backend), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", fmt.Sprintf("testdb_%s", backend))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			db, err := NewDB(dir, backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db.SetSync([]byte("persisted"), []byte("value"))
+			db.Close()
+
+			db, err = NewDB(dir, backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if !bytes.Equal(db.Get([]byte("persisted")), []byte("value")) {
+				t.Fatal("value not persisted across reopen")
+			}
+		})
+	}
+}
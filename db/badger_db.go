@@ -0,0 +1,298 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	logging "github.com/sirupsen/logrus"
+)
+
+// BadgerDB implements DB on top of badger.
+// NOTE: badger does not accept empty keys, so nil keys cannot be stored.
+type BadgerDB struct {
+	db *badger.DB
+}
+
+func NewBadgerDB(name string) (*BadgerDB, error) {
+	return NewBadgerDBWithOpts(badger.DefaultOptions(name).WithLogger(nil))
+}
+
+func NewBadgerDBWithOpts(o badger.Options) (*BadgerDB, error) {
+	db, err := badger.Open(o)
+	if err != nil {
+		return nil, err
+	}
+	database := &BadgerDB{
+		db: db,
+	}
+	return database, nil
+}
+
+// Implements DB.
+func (db *BadgerDB) Get(key []byte) []byte {
+	key = nonNilBytes(key)
+	var res []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		res, err = item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		// badger returns nil for empty values, DB.Get returns nil only for missing keys
+		res = nonNilBytes(res)
+		return nil
+	})
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		panic(err)
+	}
+	return res
+}
+
+// Implements DB.
+func (db *BadgerDB) Has(key []byte) bool {
+	return db.Get(key) != nil
+}
+
+// Implements DB.
+func (db *BadgerDB) Set(key []byte, value []byte) {
+	key = nonNilBytes(key)
+	value = nonNilBytes(value)
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+// Implements DB.
+func (db *BadgerDB) SetSync(key []byte, value []byte) {
+	db.Set(key, value)
+	db.sync()
+}
+
+// Implements DB.
+func (db *BadgerDB) Delete(key []byte) {
+	key = nonNilBytes(key)
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+// Implements DB.
+func (db *BadgerDB) DeleteSync(key []byte) {
+	db.Delete(key)
+	db.sync()
+}
+
+// badger only supports syncing on every write (Options.SyncWrites) or syncing
+// the whole value log, so sync writes flush everything written so far
+func (db *BadgerDB) sync() {
+	err := db.db.Sync()
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+func (db *BadgerDB) DB() *badger.DB {
+	return db.db
+}
+
+// Implements DB.
+func (db *BadgerDB) Close() {
+	db.db.Close()
+}
+
+// Implements DB.
+func (db *BadgerDB) Print() {
+	lsm, vlog := db.db.Size()
+	fmt.Printf("lsm size: %v, vlog size: %v\n", lsm, vlog)
+
+	itr := db.Iterator(nil, nil)
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		fmt.Printf("[%X]:\t[%X]\n", itr.Key(), itr.Value())
+	}
+}
+
+// Implements DB.
+func (db *BadgerDB) Stats() map[string]string {
+	lsm, vlog := db.db.Size()
+	return map[string]string{
+		"badger.lsm_size":  fmt.Sprintf("%d", lsm),
+		"badger.vlog_size": fmt.Sprintf("%d", vlog),
+	}
+}
+
+//----------------------------------------
+// Batch
+
+// Implements DB.
+func (db *BadgerDB) NewBatch() Batch {
+	return &badgerDBBatch{db, db.db.NewWriteBatch()}
+}
+
+type badgerDBBatch struct {
+	db    *BadgerDB
+	batch *badger.WriteBatch
+}
+
+// Implements Batch.
+func (mBatch *badgerDBBatch) Set(key, value []byte) {
+	err := mBatch.batch.Set(cp(nonNilBytes(key)), cp(nonNilBytes(value)))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Implements Batch.
+func (mBatch *badgerDBBatch) Delete(key []byte) {
+	err := mBatch.batch.Delete(cp(nonNilBytes(key)))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Implements Batch.
+func (mBatch *badgerDBBatch) Write() {
+	err := mBatch.batch.Flush()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Implements Batch.
+func (mBatch *badgerDBBatch) WriteSync() {
+	mBatch.Write()
+	mBatch.db.sync()
+}
+
+//----------------------------------------
+// Iterator
+
+// Implements DB.
+func (db *BadgerDB) Iterator(start, end []byte) Iterator {
+	return newBadgerDBIterator(db, start, end, false)
+}
+
+// Implements DB.
+func (db *BadgerDB) ReverseIterator(start, end []byte) Iterator {
+	return newBadgerDBIterator(db, start, end, true)
+}
+
+// badgerDBIterator iterates over a read only transaction. Badger iterators
+// cannot change direction, so unlike sourceIterator the direction is fixed on creation.
+type badgerDBIterator struct {
+	txn       *badger.Txn
+	source    *badger.Iterator
+	start     []byte
+	end       []byte
+	isReverse bool
+	isInvalid bool
+}
+
+var _ Iterator = (*badgerDBIterator)(nil)
+
+func newBadgerDBIterator(db *BadgerDB, start, end []byte, isReverse bool) *badgerDBIterator {
+	txn := db.db.NewTransaction(false)
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = isReverse
+	source := txn.NewIterator(opts)
+	if start == nil {
+		source.Rewind()
+	} else {
+		// in reverse mode Seek finds the largest key <= start
+		source.Seek(start)
+	}
+	return &badgerDBIterator{
+		txn:       txn,
+		source:    source,
+		start:     start,
+		end:       end,
+		isReverse: isReverse,
+		isInvalid: false,
+	}
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Domain() ([]byte, []byte) {
+	return itr.start, itr.end
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Valid() bool {
+
+	// Once invalid, forever invalid.
+	if itr.isInvalid {
+		return false
+	}
+
+	// If source is invalid, invalid.
+	if !itr.source.Valid() {
+		itr.isInvalid = true
+		return false
+	}
+
+	// If key is end or past it, invalid.
+	var end = itr.end
+	var key = itr.source.Item().Key()
+
+	if itr.isReverse {
+		if end != nil && bytes.Compare(key, end) <= 0 {
+			itr.isInvalid = true
+			return false
+		}
+	} else {
+		if end != nil && bytes.Compare(end, key) <= 0 {
+			itr.isInvalid = true
+			return false
+		}
+	}
+
+	// Valid
+	return true
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Key() []byte {
+	itr.assertIsValid()
+	return itr.source.Item().KeyCopy(nil)
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Value() []byte {
+	itr.assertIsValid()
+	value, err := itr.source.Item().ValueCopy(nil)
+	if err != nil {
+		panic(err)
+	}
+	return nonNilBytes(value)
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Next() {
+	itr.assertIsValid()
+	itr.source.Next()
+}
+
+// Implements Iterator.
+func (itr *badgerDBIterator) Close() {
+	itr.source.Close()
+	itr.txn.Discard()
+}
+
+func (itr *badgerDBIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("badgerDBIterator is invalid")
+	}
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+)
+
+// records kept per key index by a completed keygen or PSS
+const (
+	hasKeygenShare = 1 << iota
+	hasPSSShare
+	hasShareCount
+	hasKeygenCommitment
+	hasPSSCommitment
+)
+
+var keyIndexRecordPrefixes = []struct {
+	prefix []byte
+	flag   int
+}{
+	{completedKeygenShareBytes, hasKeygenShare},
+	{completedPSSShareBytes, hasPSSShare},
+	{completedShareCountBytes, hasShareCount},
+	{keygenCommitmentMatrixBytes, hasKeygenCommitment},
+	{pssCommitmentMatrixBytes, hasPSSCommitment},
+}
+
+// ConsistencyIssue is a key index with half written records
+type ConsistencyIssue struct {
+	KeyIndex big.Int
+	Problem  string
+	Repaired bool
+}
+
+// ConsistencyReport is returned by CheckConsistency
+type ConsistencyReport struct {
+	KeyIndexes int
+	Issues     []ConsistencyIssue
+}
+
+// CheckConsistency looks for key indexes that were left half written, e.g. by a crash
+// between storing a commitment matrix and its share before these writes were batched.
+// With repair set, records that can be fixed without losing share data are repaired:
+// orphaned keygen commitments are removed and share count markers are made to match the
+// stored shares. Shares are never deleted, and PSS records are only reported since dealer
+// updates store the PSS share and commitment separately.
+func (t *TorusLDB) CheckConsistency(repair bool) ConsistencyReport {
+	found := make(map[string]int)
+	for _, p := range keyIndexRecordPrefixes {
+		itr := t.db.Iterator(p.prefix, []byte{p.prefix[0] + 1})
+		for ; itr.Valid(); itr.Next() {
+			found[string(itr.Key()[len(p.prefix):])] |= p.flag
+		}
+		itr.Close()
+	}
+	keyIndexes := make([][]byte, 0, len(found))
+	for keyIndexBytes := range found {
+		keyIndexes = append(keyIndexes, []byte(keyIndexBytes))
+	}
+	sort.Slice(keyIndexes, func(i, j int) bool {
+		return bytes.Compare(keyIndexes[i], keyIndexes[j]) < 0
+	})
+
+	report := ConsistencyReport{KeyIndexes: len(keyIndexes)}
+	batch := t.db.NewBatch()
+	for _, keyIndexBytes := range keyIndexes {
+		flags := found[string(keyIndexBytes)]
+		has := func(flag int) bool { return flags&flag != 0 }
+		addIssue := func(problem string, fix func()) {
+			issue := ConsistencyIssue{Problem: problem}
+			issue.KeyIndex.SetBytes(keyIndexBytes)
+			if repair && fix != nil {
+				fix()
+				issue.Repaired = true
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+
+		if has(hasKeygenCommitment) && !has(hasKeygenShare) {
+			addIssue("keygen commitment matrix without share", func() {
+				batch.Delete(prefixKey(keygenCommitmentMatrixBytes, keyIndexBytes))
+			})
+		}
+		if has(hasKeygenShare) && !has(hasKeygenCommitment) {
+			addIssue("keygen share without commitment matrix", nil)
+		}
+		if has(hasPSSCommitment) && !has(hasPSSShare) {
+			addIssue("PSS commitment matrix without share", nil)
+		}
+		if has(hasPSSShare) && !has(hasPSSCommitment) {
+			addIssue("PSS share without commitment matrix", nil)
+		}
+		hasShare := has(hasKeygenShare) || has(hasPSSShare)
+		if hasShare && !has(hasShareCount) {
+			addIssue("share is not counted", func() {
+				batch.Set(prefixKey(completedShareCountBytes, keyIndexBytes), []byte("1"))
+			})
+		}
+		if !hasShare && has(hasShareCount) {
+			addIssue("share is counted but missing", func() {
+				batch.Delete(prefixKey(completedShareCountBytes, keyIndexBytes))
+			})
+		}
+	}
+	batch.WriteSync()
+	return report
+}
@@ -1 +1,62 @@
 package db
+
+import "fmt"
+
+type BackendType string
+
+// These are valid backend types.
+const (
+	// GoLevelDBBackend represents goleveldb (github.com/syndtr/goleveldb - most
+	// popular implementation)
+	//   - pure go
+	//   - stable
+	GoLevelDBBackend BackendType = "goleveldb"
+	// PebbleDBBackend represents pebble (github.com/cockroachdb/pebble)
+	//   - pure go
+	//   - LevelDB/RocksDB inspired LSM with faster compactions
+	PebbleDBBackend BackendType = "pebble"
+	// BadgerDBBackend represents badger (github.com/dgraph-io/badger)
+	//   - pure go
+	//   - separates values from keys, suited for large values
+	//   - empty keys are not supported
+	BadgerDBBackend BackendType = "badger"
+)
+
+type dbCreator func(dir string) (DB, error)
+
+var backends = map[BackendType]dbCreator{}
+
+func registerDBCreator(backend BackendType, creator dbCreator) {
+	backends[backend] = creator
+}
+
+func init() {
+	registerDBCreator(GoLevelDBBackend, func(dir string) (DB, error) {
+		return NewGoLevelDB(dir)
+	})
+	registerDBCreator(PebbleDBBackend, func(dir string) (DB, error) {
+		return NewPebbleDB(dir)
+	})
+	registerDBCreator(BadgerDBBackend, func(dir string) (DB, error) {
+		return NewBadgerDB(dir)
+	})
+}
+
+// ResolveBackend returns the backend used for backend, which is goleveldb when unset
+func ResolveBackend(backend BackendType) BackendType {
+	if backend == "" {
+		return GoLevelDBBackend
+	}
+	return backend
+}
+
+// NewDB creates a new database of type backend in dir.
+// An empty backend defaults to GoLevelDBBackend.
+func NewDB(dir string, backend BackendType) (DB, error) {
+	backend = ResolveBackend(backend)
+	creator, ok := backends[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown db backend %v", backend)
+	}
+	return creator(dir)
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// KVSource is the part of Iterator used by Dump. Tendermint's tm-db iterators satisfy it too.
+type KVSource interface {
+	Valid() bool
+	Next()
+	Key() []byte
+	Value() []byte
+}
+
+// Setter is the part of Batch used by Load. Tendermint's tm-db batches satisfy it too.
+type Setter interface {
+	Set(key, value []byte)
+}
+
+// maxDumpRecordSize guards Load against corrupted length prefixes
+const maxDumpRecordSize = 1 << 30
+
+// Dump writes every record of itr to w as length prefixed key value pairs.
+// Iterators are backed by a snapshot in all backends, so the dump is consistent
+// even if the database is written to concurrently.
+func Dump(itr KVSource, w io.Writer) (count int, err error) {
+	bw := bufio.NewWriter(w)
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	writeField := func(b []byte) error {
+		n := binary.PutUvarint(lenBuf, uint64(len(b)))
+		if _, err := bw.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		_, err := bw.Write(b)
+		return err
+	}
+	for ; itr.Valid(); itr.Next() {
+		if err = writeField(itr.Key()); err != nil {
+			return
+		}
+		if err = writeField(itr.Value()); err != nil {
+			return
+		}
+		count++
+	}
+	err = bw.Flush()
+	return
+}
+
+// Load reads records written by Dump into s
+func Load(r io.Reader, s Setter) (count int, err error) {
+	br := bufio.NewReader(r)
+	readField := func() ([]byte, error) {
+		l, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if l > maxDumpRecordSize {
+			return nil, fmt.Errorf("dump record of size %d exceeds maximum", l)
+		}
+		b := make([]byte, l)
+		_, err = io.ReadFull(br, b)
+		return b, err
+	}
+	for {
+		key, err := readField()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		value, err := readField()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return count, err
+		}
+		s.Set(key, value)
+		count++
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDumpLoad(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		setKeys(db, "a", "b", "c")
+		db.Set([]byte("empty"), nil)
+
+		var buf bytes.Buffer
+		itr := db.Iterator(nil, nil)
+		count, err := Dump(itr, &buf)
+		itr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 4 {
+			t.Fatalf("expected 4 records, got %d", count)
+		}
+
+		for _, backend := range testBackends {
+			restored, cleanup := newTempDB(t, backend)
+			batch := restored.NewBatch()
+			count, err = Load(bytes.NewReader(buf.Bytes()), batch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			batch.Write()
+			if count != 4 {
+				t.Fatalf("expected 4 records loaded into %s, got %d", backend, count)
+			}
+			assertKeys(t, []string{"a", "b", "c"}, collect(t, restored.Iterator(nil, []byte("d"))))
+			if value := restored.Get([]byte("empty")); value == nil || len(value) != 0 {
+				t.Fatalf("expected empty value in %s, got %v", backend, value)
+			}
+			cleanup()
+		}
+	})
+}
+
+type mapSetter map[string]string
+
+func (m mapSetter) Set(key, value []byte) {
+	m[string(key)] = string(value)
+}
+
+func TestLoadTruncated(t *testing.T) {
+	db, cleanup := newTempDB(t, GoLevelDBBackend)
+	defer cleanup()
+	setKeys(db, "a", "b")
+
+	var buf bytes.Buffer
+	itr := db.Iterator(nil, nil)
+	_, err := Dump(itr, &buf)
+	itr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-1]
+	_, err = Load(bytes.NewReader(truncated), mapSetter{})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"bytes"
+)
+
+// iteratorSource is the cursor shared by goleveldb and pebble, which sourceIterator
+// turns into a domain bounded Iterator.
+// See https://github.com/syndtr/goleveldb/blob/52c212e6c196a1404ea59592d3f1c227c9f034b2/leveldb/iterator/iter.go#L88
+// for the semantics of each method.
+type iteratorSource interface {
+	First() bool
+	Last() bool
+	Seek(key []byte) bool
+	Next() bool
+	Prev() bool
+	Valid() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
+}
+
+type sourceIterator struct {
+	source    iteratorSource
+	start     []byte
+	end       []byte
+	isReverse bool
+	isInvalid bool
+}
+
+var _ Iterator = (*sourceIterator)(nil)
+
+func newSourceIterator(source iteratorSource, start, end []byte, isReverse bool) *sourceIterator {
+	if isReverse {
+		if start == nil {
+			source.Last()
+		} else {
+			valid := source.Seek(start)
+			if valid {
+				soakey := source.Key() // start or after key
+				if bytes.Compare(start, soakey) < 0 {
+					source.Prev()
+				}
+			} else {
+				source.Last()
+			}
+		}
+	} else {
+		if start == nil {
+			source.First()
+		} else {
+			source.Seek(start)
+		}
+	}
+	return &sourceIterator{
+		source:    source,
+		start:     start,
+		end:       end,
+		isReverse: isReverse,
+		isInvalid: false,
+	}
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Domain() ([]byte, []byte) {
+	return itr.start, itr.end
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Valid() bool {
+
+	// Once invalid, forever invalid.
+	if itr.isInvalid {
+		return false
+	}
+
+	// Panic on DB error.  No way to recover.
+	itr.assertNoError()
+
+	// If source is invalid, invalid.
+	if !itr.source.Valid() {
+		itr.isInvalid = true
+		return false
+	}
+
+	// If key is end or past it, invalid.
+	var end = itr.end
+	var key = itr.source.Key()
+
+	if itr.isReverse {
+		if end != nil && bytes.Compare(key, end) <= 0 {
+			itr.isInvalid = true
+			return false
+		}
+	} else {
+		if end != nil && bytes.Compare(end, key) <= 0 {
+			itr.isInvalid = true
+			return false
+		}
+	}
+
+	// Valid
+	return true
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Key() []byte {
+	// Sources may reuse the key buffer, return a copy.
+	itr.assertNoError()
+	itr.assertIsValid()
+	return cp(itr.source.Key())
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Value() []byte {
+	// Sources may reuse the value buffer, return a copy.
+	itr.assertNoError()
+	itr.assertIsValid()
+	return cp(itr.source.Value())
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Next() {
+	itr.assertNoError()
+	itr.assertIsValid()
+	if itr.isReverse {
+		itr.source.Prev()
+	} else {
+		itr.source.Next()
+	}
+}
+
+// Implements Iterator.
+func (itr *sourceIterator) Close() {
+	itr.source.Release()
+}
+
+func (itr *sourceIterator) assertNoError() {
+	if err := itr.source.Error(); err != nil {
+		panic(err)
+	}
+}
+
+func (itr sourceIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("iterator is invalid")
+	}
+}
@@ -1,13 +1,11 @@
 package db
 
 import (
-	"bytes"
 	"fmt"
 
 	logging "github.com/sirupsen/logrus"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 )
 
@@ -173,146 +171,15 @@ func (mBatch *goLevelDBBatch) WriteSync() {
 
 //----------------------------------------
 // Iterator
-// NOTE This is almost identical to db/c_level_db.Iterator
-// Before creating a third version, refactor.
 
 // Implements DB.
 func (db *GoLevelDB) Iterator(start, end []byte) Iterator {
 	itr := db.db.NewIterator(nil, nil)
-	return newGoLevelDBIterator(itr, start, end, false)
+	return newSourceIterator(itr, start, end, false)
 }
 
 // Implements DB.
 func (db *GoLevelDB) ReverseIterator(start, end []byte) Iterator {
 	itr := db.db.NewIterator(nil, nil)
-	return newGoLevelDBIterator(itr, start, end, true)
-}
-
-type goLevelDBIterator struct {
-	source    iterator.Iterator
-	start     []byte
-	end       []byte
-	isReverse bool
-	isInvalid bool
-}
-
-var _ Iterator = (*goLevelDBIterator)(nil)
-
-func newGoLevelDBIterator(source iterator.Iterator, start, end []byte, isReverse bool) *goLevelDBIterator {
-	if isReverse {
-		if start == nil {
-			source.Last()
-		} else {
-			valid := source.Seek(start)
-			if valid {
-				soakey := source.Key() // start or after key
-				if bytes.Compare(start, soakey) < 0 {
-					source.Prev()
-				}
-			} else {
-				source.Last()
-			}
-		}
-	} else {
-		if start == nil {
-			source.First()
-		} else {
-			source.Seek(start)
-		}
-	}
-	return &goLevelDBIterator{
-		source:    source,
-		start:     start,
-		end:       end,
-		isReverse: isReverse,
-		isInvalid: false,
-	}
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Domain() ([]byte, []byte) {
-	return itr.start, itr.end
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Valid() bool {
-
-	// Once invalid, forever invalid.
-	if itr.isInvalid {
-		return false
-	}
-
-	// Panic on DB error.  No way to recover.
-	itr.assertNoError()
-
-	// If source is invalid, invalid.
-	if !itr.source.Valid() {
-		itr.isInvalid = true
-		return false
-	}
-
-	// If key is end or past it, invalid.
-	var end = itr.end
-	var key = itr.source.Key()
-
-	if itr.isReverse {
-		if end != nil && bytes.Compare(key, end) <= 0 {
-			itr.isInvalid = true
-			return false
-		}
-	} else {
-		if end != nil && bytes.Compare(end, key) <= 0 {
-			itr.isInvalid = true
-			return false
-		}
-	}
-
-	// Valid
-	return true
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Key() []byte {
-	// Key returns a copy of the current key.
-	// See https://github.com/syndtr/goleveldb/blob/52c212e6c196a1404ea59592d3f1c227c9f034b2/leveldb/iterator/iter.go#L88
-	itr.assertNoError()
-	itr.assertIsValid()
-	return cp(itr.source.Key())
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Value() []byte {
-	// Value returns a copy of the current value.
-	// See https://github.com/syndtr/goleveldb/blob/52c212e6c196a1404ea59592d3f1c227c9f034b2/leveldb/iterator/iter.go#L88
-	itr.assertNoError()
-	itr.assertIsValid()
-	return cp(itr.source.Value())
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Next() {
-	itr.assertNoError()
-	itr.assertIsValid()
-	if itr.isReverse {
-		itr.source.Prev()
-	} else {
-		itr.source.Next()
-	}
-}
-
-// Implements Iterator.
-func (itr *goLevelDBIterator) Close() {
-	itr.source.Release()
-}
-
-func (itr *goLevelDBIterator) assertNoError() {
-	if err := itr.source.Error(); err != nil {
-		panic(err)
-	}
-}
-
-func (itr goLevelDBIterator) assertIsValid() {
-	if !itr.Valid() {
-		panic("goLevelDBIterator is invalid")
-	}
+	return newSourceIterator(itr, start, end, true)
 }
@@ -29,7 +29,18 @@ func TestNewGoLevelDB(t *testing.T) {
 
 }
 
+// BenchmarkRandomReadsWrites compares backends, e.g.
+// go test ./db -run none -bench RandomReadsWrites -benchtime 200000x
 func BenchmarkRandomReadsWrites(b *testing.B) {
+	for _, backend := range testBackends {
+		backend := backend
+		b.Run(string(backend), func(b *testing.B) {
+			benchmarkRandomReadsWrites(b, backend)
+		})
+	}
+}
+
+func benchmarkRandomReadsWrites(b *testing.B, backend BackendType) {
 	b.StopTimer()
 
 	numItems := int64(1000000)
@@ -38,9 +49,9 @@ func BenchmarkRandomReadsWrites(b *testing.B) {
 		internal[int64(i)] = int64(0)
 	}
 	tmpFile, _ := ioutil.TempDir("", "testdb")
-	defer os.Remove(tmpFile)
+	defer os.RemoveAll(tmpFile)
 
-	db, err := NewGoLevelDB(tmpFile)
+	db, err := NewDB(tmpFile, backend)
 	if err != nil {
 		b.Fatal(err.Error())
 		return
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+	logging "github.com/sirupsen/logrus"
+)
+
+type PebbleDB struct {
+	db *pebble.DB
+}
+
+func NewPebbleDB(name string) (*PebbleDB, error) {
+	return NewPebbleDBWithOpts(name, &pebble.Options{})
+}
+
+func NewPebbleDBWithOpts(name string, o *pebble.Options) (*PebbleDB, error) {
+	db, err := pebble.Open(name, o)
+	if err != nil {
+		return nil, err
+	}
+	database := &PebbleDB{
+		db: db,
+	}
+	return database, nil
+}
+
+// Implements DB.
+func (db *PebbleDB) Get(key []byte) []byte {
+	key = nonNilBytes(key)
+	res, closer, err := db.db.Get(key)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil
+		}
+		panic(err)
+	}
+	defer closer.Close()
+	// res is only valid until closer is closed
+	return cp(res)
+}
+
+// Implements DB.
+func (db *PebbleDB) Has(key []byte) bool {
+	return db.Get(key) != nil
+}
+
+// Implements DB.
+func (db *PebbleDB) Set(key []byte, value []byte) {
+	key = nonNilBytes(key)
+	value = nonNilBytes(value)
+	err := db.db.Set(key, value, pebble.NoSync)
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+// Implements DB.
+func (db *PebbleDB) SetSync(key []byte, value []byte) {
+	key = nonNilBytes(key)
+	value = nonNilBytes(value)
+	err := db.db.Set(key, value, pebble.Sync)
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+// Implements DB.
+func (db *PebbleDB) Delete(key []byte) {
+	key = nonNilBytes(key)
+	err := db.db.Delete(key, pebble.NoSync)
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+// Implements DB.
+func (db *PebbleDB) DeleteSync(key []byte) {
+	key = nonNilBytes(key)
+	err := db.db.Delete(key, pebble.Sync)
+	if err != nil {
+		logging.WithError(err).Fatal()
+	}
+}
+
+func (db *PebbleDB) DB() *pebble.DB {
+	return db.db
+}
+
+// Implements DB.
+func (db *PebbleDB) Close() {
+	db.db.Close()
+}
+
+// Implements DB.
+func (db *PebbleDB) Print() {
+	fmt.Printf("%v\n", db.db.Metrics())
+
+	itr := db.db.NewIter(nil)
+	defer itr.Close()
+	for itr.First(); itr.Valid(); itr.Next() {
+		key := itr.Key()
+		value := itr.Value()
+		fmt.Printf("[%X]:\t[%X]\n", key, value)
+	}
+}
+
+// Implements DB.
+func (db *PebbleDB) Stats() map[string]string {
+	return map[string]string{
+		"pebble.metrics": db.db.Metrics().String(),
+	}
+}
+
+//----------------------------------------
+// Batch
+
+// Implements DB.
+func (db *PebbleDB) NewBatch() Batch {
+	return &pebbleDBBatch{db, db.db.NewBatch()}
+}
+
+type pebbleDBBatch struct {
+	db    *PebbleDB
+	batch *pebble.Batch
+}
+
+// Implements Batch.
+func (mBatch *pebbleDBBatch) Set(key, value []byte) {
+	err := mBatch.batch.Set(nonNilBytes(key), nonNilBytes(value), nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Implements Batch.
+func (mBatch *pebbleDBBatch) Delete(key []byte) {
+	err := mBatch.batch.Delete(nonNilBytes(key), nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Implements Batch.
+func (mBatch *pebbleDBBatch) Write() {
+	err := mBatch.batch.Commit(pebble.NoSync)
+	if err != nil {
+		panic(err)
+	}
+	// pebble batches are pooled and cannot be reused after commit
+	mBatch.batch.Close()
+}
+
+// Implements Batch.
+func (mBatch *pebbleDBBatch) WriteSync() {
+	err := mBatch.batch.Commit(pebble.Sync)
+	if err != nil {
+		panic(err)
+	}
+	// pebble batches are pooled and cannot be reused after commit
+	mBatch.batch.Close()
+}
+
+//----------------------------------------
+// Iterator
+
+// Implements DB.
+func (db *PebbleDB) Iterator(start, end []byte) Iterator {
+	itr := db.db.NewIter(nil)
+	return newSourceIterator(pebbleIteratorSource{itr}, start, end, false)
+}
+
+// Implements DB.
+func (db *PebbleDB) ReverseIterator(start, end []byte) Iterator {
+	itr := db.db.NewIter(nil)
+	return newSourceIterator(pebbleIteratorSource{itr}, start, end, true)
+}
+
+// pebbleIteratorSource adapts pebble's iterator to iteratorSource
+type pebbleIteratorSource struct {
+	*pebble.Iterator
+}
+
+func (p pebbleIteratorSource) Seek(key []byte) bool {
+	return p.SeekGE(key)
+}
+
+func (p pebbleIteratorSource) Release() {
+	p.Iterator.Close()
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"strings"
 
@@ -25,7 +26,7 @@ var keyIndexToPubKeyBytes = []byte("h")
 var connectionDetailsBytes = []byte("i")
 var nodePubKeyBytes = []byte("j")
 
-// TorusLDB implements TorusDB on top of LevelDB
+// TorusLDB implements TorusDB on top of any DB backend, LevelDB by default
 type TorusLDB struct {
 	db DB
 }
@@ -33,7 +34,13 @@ type TorusLDB struct {
 // NewTorusLDB returns a leveldb implementation of TorusDB
 // NOTE: dbDirPath MUST be a directory
 func NewTorusLDB(dbDirPath string) (*TorusLDB, error) {
-	db, err := NewGoLevelDB(dbDirPath)
+	return NewTorusLDBWithBackend(dbDirPath, GoLevelDBBackend)
+}
+
+// NewTorusLDBWithBackend returns TorusDB stored in the given backend
+// NOTE: dbDirPath MUST be a directory
+func NewTorusLDBWithBackend(dbDirPath string, backend BackendType) (*TorusLDB, error) {
+	db, err := NewDB(dbDirPath, backend)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +55,7 @@ type completedShare struct {
 }
 
 func (t *TorusLDB) StoreNodePubKey(nodeAddress ethCommon.Address, pubKey common.Point) error {
-	key := append(nodePubKeyBytes, nodeAddress[:]...)
+	key := prefixKey(nodePubKeyBytes, nodeAddress[:])
 	data, err := bijson.Marshal(pubKey)
 	if err != nil {
 		return err
@@ -58,7 +65,7 @@ func (t *TorusLDB) StoreNodePubKey(nodeAddress ethCommon.Address, pubKey common.
 }
 
 func (t *TorusLDB) RetrieveNodePubKey(nodeAddress ethCommon.Address) (pubKey common.Point, err error) {
-	key := append(nodePubKeyBytes, nodeAddress[:]...)
+	key := prefixKey(nodePubKeyBytes, nodeAddress[:])
 	data := t.db.Get(key)
 	if data == nil {
 		return pubKey, fmt.Errorf("could not find pubkey for nodeAddress %s", nodeAddress.String())
@@ -68,14 +75,14 @@ func (t *TorusLDB) RetrieveNodePubKey(nodeAddress ethCommon.Address) (pubKey com
 }
 
 func (t *TorusLDB) StoreConnectionDetails(nodeAddress ethCommon.Address, tmP2PConnection string, p2pConnection string) error {
-	connectionDetailsKey := append(connectionDetailsBytes, nodeAddress[:]...)
+	connectionDetailsKey := prefixKey(connectionDetailsBytes, nodeAddress[:])
 	connectionData := strings.Join([]string{tmP2PConnection, p2pConnection}, pcmn.Delimiter1)
 	t.db.Set(connectionDetailsKey, []byte(connectionData))
 	return nil
 }
 
 func (t *TorusLDB) RetrieveConnectionDetails(nodeAddress ethCommon.Address) (tmP2PConnection string, p2pConnection string, err error) {
-	connectionDetailsKey := append(connectionDetailsBytes, nodeAddress[:]...)
+	connectionDetailsKey := prefixKey(connectionDetailsBytes, nodeAddress[:])
 	res := t.db.Get(connectionDetailsKey)
 	if res != nil {
 		substrs := strings.Split(string(res), pcmn.Delimiter1)
@@ -89,34 +96,75 @@ func (t *TorusLDB) RetrieveConnectionDetails(nodeAddress ethCommon.Address) (tmP
 	return "", "", errors.New("could not get data from db for connection details")
 }
 
-func (t *TorusLDB) StoreKeygenCommitmentMatrix(keyIndex big.Int, c [][]common.Point) error {
-	keyIndexBytes := keyIndex.Bytes()
-	commitmentMatrixKey := append(keygenCommitmentMatrixBytes, keyIndexBytes...)
+// record is a single key value pair of a multi record write
+type record struct {
+	key   []byte
+	value []byte
+}
+
+// prefixKey allocates a new key. Appending to the shared prefix slices directly
+// may reuse their backing array across concurrent callers.
+func prefixKey(prefix []byte, suffix []byte) []byte {
+	key := make([]byte, 0, len(prefix)+len(suffix))
+	return append(append(key, prefix...), suffix...)
+}
+
+// writeRecords writes all records atomically
+func (t *TorusLDB) writeRecords(records ...record) {
+	batch := t.db.NewBatch()
+	for _, r := range records {
+		batch.Set(r.key, r.value)
+	}
+	batch.WriteSync()
+}
+
+func commitmentMatrixRecord(prefix []byte, keyIndex big.Int, c [][]common.Point) (record, error) {
 	b, err := bijson.Marshal(c)
 	if err != nil {
 		logging.WithField("c", c).WithField("keyIndex", keyIndex).Debug("could not store commitment matrix")
+		return record{}, err
+	}
+	return record{key: prefixKey(prefix, keyIndex.Bytes()), value: b}, nil
+}
+
+// completedShareRecords returns the share and the marker counted by GetShareCount
+func completedShareRecords(prefix []byte, keyIndex big.Int, si big.Int, siprime big.Int) ([]record, error) {
+	keyIndexBytes := keyIndex.Bytes()
+	marshalledShare, err := bijson.Marshal(completedShare{
+		Si:      si,
+		SiPrime: siprime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []record{
+		{key: prefixKey(prefix, keyIndexBytes), value: marshalledShare},
+		{key: prefixKey(completedShareCountBytes, keyIndexBytes), value: []byte("1")},
+	}, nil
+}
+
+func (t *TorusLDB) StoreKeygenCommitmentMatrix(keyIndex big.Int, c [][]common.Point) error {
+	r, err := commitmentMatrixRecord(keygenCommitmentMatrixBytes, keyIndex, c)
+	if err != nil {
 		return err
 	}
-	t.db.Set(commitmentMatrixKey, b)
+	t.db.Set(r.key, r.value)
 	return nil
 }
 
 func (t *TorusLDB) StorePSSCommitmentMatrix(keyIndex big.Int, c [][]common.Point) error {
-	keyIndexBytes := keyIndex.Bytes()
-	commitmentMatrixKey := append(pssCommitmentMatrixBytes, keyIndexBytes...)
-	b, err := bijson.Marshal(c)
+	r, err := commitmentMatrixRecord(pssCommitmentMatrixBytes, keyIndex, c)
 	if err != nil {
-		logging.WithField("c", c).WithField("keyIndex", keyIndex).Debug("could not store commitment matrix")
 		return err
 	}
-	t.db.Set(commitmentMatrixKey, b)
+	t.db.Set(r.key, r.value)
 	return nil
 }
 
 func (t *TorusLDB) RetrieveCommitmentMatrix(keyIndex big.Int) ([][]common.Point, error) {
 	keyIndexBytes := keyIndex.Bytes()
 
-	pssCommitmentMatrixKey := append(pssCommitmentMatrixBytes, keyIndexBytes...)
+	pssCommitmentMatrixKey := prefixKey(pssCommitmentMatrixBytes, keyIndexBytes)
 	res := t.db.Get(pssCommitmentMatrixKey)
 	if res != nil {
 		var retrievedCommitmentMatrix [][]common.Point
@@ -128,7 +176,7 @@ func (t *TorusLDB) RetrieveCommitmentMatrix(keyIndex big.Int) ([][]common.Point,
 		return retrievedCommitmentMatrix, nil
 	}
 
-	keygenCommitmentMatrixKey := append(keygenCommitmentMatrixBytes, keyIndexBytes...)
+	keygenCommitmentMatrixKey := prefixKey(keygenCommitmentMatrixBytes, keyIndexBytes)
 	res = t.db.Get(keygenCommitmentMatrixKey)
 	if res != nil {
 		var retrievedCommitmentMatrix [][]common.Point
@@ -148,14 +196,12 @@ func (t *TorusLDB) StorePublicKeyToKeyIndex(publicKey common.Point, keyIndex big
 	if err != nil {
 		return err
 	}
-	// store pubkey -> key index
-	pkkey := append(pubkeyToKeyIndexBytes, b...)
-	t.db.Set(pkkey, keyIndex.Bytes())
-
-	// store key index -> pubkey
-	kikey := append(keyIndexToPubKeyBytes, keyIndex.Bytes()...)
-	t.db.Set(kikey, b)
-
+	t.writeRecords(
+		// store pubkey -> key index
+		record{key: prefixKey(pubkeyToKeyIndexBytes, b), value: keyIndex.Bytes()},
+		// store key index -> pubkey
+		record{key: prefixKey(keyIndexToPubKeyBytes, keyIndex.Bytes()), value: b},
+	)
 	return nil
 }
 
@@ -164,7 +210,7 @@ func (t *TorusLDB) RetrievePublicKeyToKeyIndex(publicKey common.Point) (*big.Int
 	if err != nil {
 		return nil, err
 	}
-	key := append(pubkeyToKeyIndexBytes, b...)
+	key := prefixKey(pubkeyToKeyIndexBytes, b)
 	var keyIndex big.Int
 	keyIndexBytes := t.db.Get(key)
 	keyIndex.SetBytes(keyIndexBytes)
@@ -172,7 +218,7 @@ func (t *TorusLDB) RetrievePublicKeyToKeyIndex(publicKey common.Point) (*big.Int
 }
 
 func (t *TorusLDB) RetrieveKeyIndexToPublicKey(keyIndex big.Int) (*common.Point, error) {
-	key := append(keyIndexToPubKeyBytes, keyIndex.Bytes()...)
+	key := prefixKey(keyIndexToPubKeyBytes, keyIndex.Bytes())
 	pubKeyBytes := t.db.Get(key)
 	var pubKey common.Point
 	err := bijson.Unmarshal(pubKeyBytes, &pubKey)
@@ -183,44 +229,55 @@ func (t *TorusLDB) RetrieveKeyIndexToPublicKey(keyIndex big.Int) (*common.Point,
 }
 
 func (t *TorusLDB) KeyIndexToPublicKeyExists(keyIndex big.Int) bool {
-	key := append(keyIndexToPubKeyBytes, keyIndex.Bytes()...)
+	key := prefixKey(keyIndexToPubKeyBytes, keyIndex.Bytes())
 	return t.db.Has(key)
 }
 
 func (t *TorusLDB) StoreCompletedKeygenShare(keyIndex big.Int, si big.Int, siprime big.Int) error {
-	keyIndexBytes := keyIndex.Bytes()
-	completedShareKey := append(completedKeygenShareBytes, keyIndexBytes...)
-	marshalledShare, err := bijson.Marshal(completedShare{
-		Si:      si,
-		SiPrime: siprime,
-	})
+	records, err := completedShareRecords(completedKeygenShareBytes, keyIndex, si, siprime)
 	if err != nil {
 		return err
 	}
-
-	t.db.Set(completedShareKey, marshalledShare)
-	t.db.Set(append(completedShareCountBytes, keyIndexBytes...), []byte("1"))
+	t.writeRecords(records...)
 	return nil
 }
 
 func (t *TorusLDB) StoreCompletedPSSShare(keyIndex big.Int, si big.Int, siprime big.Int) error {
-	keyIndexBytes := keyIndex.Bytes()
-	completedShareKey := append(completedPSSShareBytes, keyIndexBytes...)
-	marshalledShare, err := bijson.Marshal(completedShare{
-		Si:      si,
-		SiPrime: siprime,
-	})
+	records, err := completedShareRecords(completedPSSShareBytes, keyIndex, si, siprime)
+	if err != nil {
+		return err
+	}
+	t.writeRecords(records...)
+	return nil
+}
+
+// StoreCompletedKeygen stores the commitment matrix and share of a completed keygen in a single batch,
+// so that a crash can not leave a key index with only one of them
+func (t *TorusLDB) StoreCompletedKeygen(keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error {
+	return t.storeCompleted(keygenCommitmentMatrixBytes, completedKeygenShareBytes, keyIndex, c, si, siprime)
+}
+
+// StoreCompletedPSS stores the commitment matrix and share of a completed PSS in a single batch
+func (t *TorusLDB) StoreCompletedPSS(keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error {
+	return t.storeCompleted(pssCommitmentMatrixBytes, completedPSSShareBytes, keyIndex, c, si, siprime)
+}
+
+func (t *TorusLDB) storeCompleted(commitmentPrefix, sharePrefix []byte, keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error {
+	commitment, err := commitmentMatrixRecord(commitmentPrefix, keyIndex, c)
+	if err != nil {
+		return err
+	}
+	shares, err := completedShareRecords(sharePrefix, keyIndex, si, siprime)
 	if err != nil {
 		return err
 	}
-	t.db.Set(completedShareKey, marshalledShare)
-	t.db.Set(append(completedShareCountBytes, keyIndexBytes...), []byte("1"))
+	t.writeRecords(append(shares, commitment)...)
 	return nil
 }
 
 func (t *TorusLDB) RetrieveCompletedShare(keyIndex big.Int) (*big.Int, *big.Int, error) {
 	keyIndexBytes := keyIndex.Bytes()
-	completedPSSShareKey := append(completedPSSShareBytes, keyIndexBytes...)
+	completedPSSShareKey := prefixKey(completedPSSShareBytes, keyIndexBytes)
 	var res []byte
 	res = t.db.Get(completedPSSShareKey)
 	if res != nil {
@@ -231,7 +288,7 @@ func (t *TorusLDB) RetrieveCompletedShare(keyIndex big.Int) (*big.Int, *big.Int,
 		}
 		return &retrievedShare.Si, &retrievedShare.SiPrime, nil
 	}
-	completedKeygenShareKey := append(completedKeygenShareBytes, keyIndexBytes...)
+	completedKeygenShareKey := prefixKey(completedKeygenShareBytes, keyIndexBytes)
 	res = t.db.Get(completedKeygenShareKey)
 	if res != nil {
 		var retrievedShare completedShare
@@ -259,7 +316,7 @@ type KeygenStarted struct {
 }
 
 func (t *TorusLDB) SetKeygenStarted(keygenID string, started bool) {
-	key := append(keygenIDBytes, []byte(keygenID)...)
+	key := prefixKey(keygenIDBytes, []byte(keygenID))
 	data, err := bijson.Marshal(KeygenStarted{Started: started})
 	if err != nil {
 		logging.WithError(err).Error("Could not marshal set keygen started")
@@ -267,7 +324,7 @@ func (t *TorusLDB) SetKeygenStarted(keygenID string, started bool) {
 	t.db.Set(key, data)
 }
 func (t *TorusLDB) GetKeygenStarted(keygenID string) bool {
-	key := append(keygenIDBytes, []byte(keygenID)...)
+	key := prefixKey(keygenIDBytes, []byte(keygenID))
 	data := t.db.Get(key)
 	if data == nil {
 		return false
@@ -279,3 +336,25 @@ func (t *TorusLDB) GetKeygenStarted(keygenID string) bool {
 	}
 	return keygenStarted.Started
 }
+
+// Dump writes a consistent snapshot of all records to w, see db.Dump
+func (t *TorusLDB) Dump(w io.Writer) (int, error) {
+	itr := t.db.Iterator(nil, nil)
+	defer itr.Close()
+	return Dump(itr, w)
+}
+
+// Load writes records produced by Dump in a single batch
+func (t *TorusLDB) Load(r io.Reader) (int, error) {
+	batch := t.db.NewBatch()
+	count, err := Load(r, batch)
+	if err != nil {
+		return count, err
+	}
+	batch.WriteSync()
+	return count, nil
+}
+
+func (t *TorusLDB) Close() {
+	t.db.Close()
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/torusresearch/torus-common/common"
+)
+
+func testCommitmentMatrix() [][]common.Point {
+	return [][]common.Point{{{X: *big.NewInt(1), Y: *big.NewInt(2)}}}
+}
+
+func TestStoreCompletedKeygen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		torusDB := &TorusLDB{db: db}
+		keyIndex := *big.NewInt(7)
+		err := torusDB.StoreCompletedKeygen(keyIndex, testCommitmentMatrix(), *big.NewInt(11), *big.NewInt(13))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := torusDB.RetrieveCommitmentMatrix(keyIndex)
+		if err != nil || len(c) != 1 || c[0][0].X.Int64() != 1 {
+			t.Fatalf("unexpected commitment matrix %v, %v", c, err)
+		}
+		si, siprime, err := torusDB.RetrieveCompletedShare(keyIndex)
+		if err != nil || si.Int64() != 11 || siprime.Int64() != 13 {
+			t.Fatalf("unexpected share %v %v, %v", si, siprime, err)
+		}
+		if torusDB.GetShareCount() != 1 {
+			t.Fatalf("expected share count 1, got %d", torusDB.GetShareCount())
+		}
+		if report := torusDB.CheckConsistency(false); len(report.Issues) != 0 {
+			t.Fatalf("unexpected issues %v", report.Issues)
+		}
+	})
+}
+
+func TestStoreCompletedPSSOverridesKeygen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db DB) {
+		torusDB := &TorusLDB{db: db}
+		keyIndex := *big.NewInt(7)
+		_ = torusDB.StoreCompletedKeygen(keyIndex, testCommitmentMatrix(), *big.NewInt(11), *big.NewInt(13))
+		pssMatrix := [][]common.Point{{{X: *big.NewInt(3), Y: *big.NewInt(4)}}}
+		err := torusDB.StoreCompletedPSS(keyIndex, pssMatrix, *big.NewInt(17), *big.NewInt(19))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, _ := torusDB.RetrieveCommitmentMatrix(keyIndex)
+		si, _, _ := torusDB.RetrieveCompletedShare(keyIndex)
+		if c[0][0].X.Int64() != 3 || si.Int64() != 17 {
+			t.Fatal("expected PSS records to take precedence")
+		}
+		if torusDB.GetShareCount() != 1 {
+			t.Fatalf("expected share count 1, got %d", torusDB.GetShareCount())
+		}
+	})
+}
+
+func TestCheckConsistency(t *testing.T) {
+	db, cleanup := newTempDB(t, GoLevelDBBackend)
+	defer cleanup()
+	torusDB := &TorusLDB{db: db}
+
+	// complete
+	_ = torusDB.StoreCompletedKeygen(*big.NewInt(1), testCommitmentMatrix(), *big.NewInt(1), *big.NewInt(1))
+	// crashed after the commitment matrix was written
+	_ = torusDB.StoreKeygenCommitmentMatrix(*big.NewInt(2), testCommitmentMatrix())
+	// crashed after the share was written, the share is kept
+	_ = torusDB.StoreCompletedKeygenShare(*big.NewInt(3), *big.NewInt(3), *big.NewInt(3))
+	// share count marker without share
+	db.Set(prefixKey(completedShareCountBytes, big.NewInt(4).Bytes()), []byte("1"))
+	// share without share count marker
+	_ = torusDB.StoreCompletedKeygen(*big.NewInt(5), testCommitmentMatrix(), *big.NewInt(5), *big.NewInt(5))
+	db.Delete(prefixKey(completedShareCountBytes, big.NewInt(5).Bytes()))
+
+	report := torusDB.CheckConsistency(false)
+	if report.KeyIndexes != 5 {
+		t.Fatalf("expected 5 key indexes, got %d", report.KeyIndexes)
+	}
+	expected := map[int64]bool{2: true, 3: false, 4: true, 5: true}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %v", len(expected), report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if _, ok := expected[issue.KeyIndex.Int64()]; !ok || issue.Repaired {
+			t.Fatalf("unexpected issue %v", issue)
+		}
+	}
+	if torusDB.GetShareCount() != 3 {
+		t.Fatalf("check without repair should not write, share count %d", torusDB.GetShareCount())
+	}
+
+	report = torusDB.CheckConsistency(true)
+	for _, issue := range report.Issues {
+		if issue.Repaired != expected[issue.KeyIndex.Int64()] {
+			t.Fatalf("unexpected repair state %v", issue)
+		}
+	}
+	if c, _ := torusDB.RetrieveCommitmentMatrix(*big.NewInt(2)); c != nil {
+		t.Fatal("expected orphaned commitment matrix to be removed")
+	}
+	if si, _, _ := torusDB.RetrieveCompletedShare(*big.NewInt(3)); si == nil {
+		t.Fatal("expected share without commitment matrix to be kept")
+	}
+	// key indexes 1, 3 and 5
+	if torusDB.GetShareCount() != 3 {
+		t.Fatalf("expected share count 3 after repair, got %d", torusDB.GetShareCount())
+	}
+	report = torusDB.CheckConsistency(true)
+	if len(report.Issues) != 1 || report.Issues[0].KeyIndex.Int64() != 3 {
+		t.Fatalf("expected only the unrepairable issue to remain, got %v", report.Issues)
+	}
+}
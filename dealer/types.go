@@ -17,7 +17,7 @@ type Message struct {
 	Method    string       `json:"method"`
 	KeyIndex  big.Int      `json:"keyindex"`
 	PubKey    common.Point `json:"pubkey"`
-	Data      []byte       `json:"data"`
+	Data      []byte       `json:"data" secret:"true"`
 	Signature []byte       `json:"signature"`
 }
 
@@ -38,8 +38,8 @@ type TMessage struct {
 type MsgUpdateShare struct {
 	KeyIndex big.Int  `json:"keyindex"`
 	TMessage TMessage `json:"tmessage"`
-	Si       big.Int  `json:"si"`
-	Siprime  big.Int  `json:"siprime"`
+	Si       big.Int  `json:"si" secret:"true"`
+	Siprime  big.Int  `json:"siprime" secret:"true"`
 }
 
 type MsgUpdateCommitment struct {
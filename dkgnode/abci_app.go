@@ -1,6 +1,8 @@
 package dkgnode
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -24,6 +26,7 @@ import (
 	"github.com/torusresearch/torus-node/keygennofsm"
 	"github.com/torusresearch/torus-node/mapping"
 	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/tracing"
 )
 
 // KeyAssignmentPublic - holds key fields safe to be shown to non-keyholders
@@ -44,7 +47,7 @@ type KeyAssignmentOld struct {
 // contains sensitive share data
 type KeyAssignment struct {
 	KeyAssignmentPublic
-	Share    []byte
+	Share    []byte `secret:"true"`
 	Metadata tronCrypto.EciesMetadata
 }
 
@@ -251,6 +254,8 @@ type ABCIApp struct {
 	laggingState *State
 	db           dbm.DB
 	dbIterators  *DBIteratorsSyncMap
+	// backups pause it to hold back blocks at a block boundary
+	commits *commitGate
 }
 
 func (a *ABCIService) NewABCIApp() *ABCIApp {
@@ -263,42 +268,36 @@ func (a *ABCIService) NewABCIApp() *ABCIApp {
 		logging.WithError(err).Fatal("could not start GoLevelDB for tendermint state")
 	}
 	// initialize app and telemetry
-	abciApp := ABCIApp{db: db, dbIterators: &DBIteratorsSyncMap{}}
+	abciApp := ABCIApp{db: db, dbIterators: &DBIteratorsSyncMap{}, commits: newCommitGate()}
 
 	// Load or initialize state
 	_, stateExists := abciApp.LoadState()
 	if !stateExists {
-		abciApp.state = &State{
-			LastUnassignedIndex:    0,
-			LastCreatedIndex:       0,
-			PSSDecisions:           make(map[string]bool),
-			KeygenDecisions:        make(map[string]bool),
-			KeygenPubKeys:          make(map[string]KeygenPubKey),
-			MappingProposeFreezes:  make(map[mapping.MappingID]map[NodeDetailsID]bool),
-			MappingProposeSummarys: make(map[mapping.MappingID]map[mapping.TransferSummaryID]map[NodeDetailsID]bool),
-			MappingProposeKeys:     make(map[mapping.MappingID]map[mapping.MappingKeyID]map[NodeDetailsID]bool),
-			MappingThawed:          make(map[mapping.MappingID]bool),
-			MappingCounters:        make(map[mapping.MappingID]MappingCounter),
-		}
+		abciApp.state = newState()
 		abciApp.info = &AppInfo{
 			Height: 0,
 		}
-		abciApp.laggingState = &State{
-			LastUnassignedIndex:    0,
-			LastCreatedIndex:       0,
-			PSSDecisions:           make(map[string]bool),
-			KeygenDecisions:        make(map[string]bool),
-			KeygenPubKeys:          make(map[string]KeygenPubKey),
-			MappingProposeFreezes:  make(map[mapping.MappingID]map[NodeDetailsID]bool),
-			MappingProposeSummarys: make(map[mapping.MappingID]map[mapping.TransferSummaryID]map[NodeDetailsID]bool),
-			MappingProposeKeys:     make(map[mapping.MappingID]map[mapping.MappingKeyID]map[NodeDetailsID]bool),
-			MappingThawed:          make(map[mapping.MappingID]bool),
-			MappingCounters:        make(map[mapping.MappingID]MappingCounter),
-		}
+		abciApp.laggingState = newState()
 	}
 	return &abciApp
 }
 
+// newState returns the state of a chain without blocks
+func newState() *State {
+	return &State{
+		LastUnassignedIndex:    0,
+		LastCreatedIndex:       0,
+		PSSDecisions:           make(map[string]bool),
+		KeygenDecisions:        make(map[string]bool),
+		KeygenPubKeys:          make(map[string]KeygenPubKey),
+		MappingProposeFreezes:  make(map[mapping.MappingID]map[NodeDetailsID]bool),
+		MappingProposeSummarys: make(map[mapping.MappingID]map[mapping.TransferSummaryID]map[NodeDetailsID]bool),
+		MappingProposeKeys:     make(map[mapping.MappingID]map[mapping.MappingKeyID]map[NodeDetailsID]bool),
+		MappingThawed:          make(map[mapping.MappingID]bool),
+		MappingCounters:        make(map[mapping.MappingID]MappingCounter),
+	}
+}
+
 func (app *ABCIApp) isThawed(mappingID mapping.MappingID) bool {
 	// If MappingCounters hasn't been set, do not trigger thaw
 	mappingCounter := app.state.MappingCounters[mappingID]
@@ -339,12 +338,18 @@ func (app *ABCIApp) DeliverTx(req types.RequestDeliverTx) types.ResponseDeliverT
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIApp.RejectedBftTxCounter, pcmn.TelemetryConstants.ABCIApp.Prefix)
 		return types.ResponseDeliverTx{Code: code.CodeTypeUnauthorized}
 	}
+	_, span := startRemoteSpan(context.Background(), "bft.deliver", tracing.KindConsumer, parsedTx.TraceParent)
+	span.SetAttribute("msg_type", parsedTx.MsgType)
+	span.SetAttribute("sender", senderDetails.Index)
+	defer span.End()
 
 	// Validate transaction here
 	correct, tags, err := app.ValidateAndUpdateAndTagBFTTx(parsedTx.BFTTx, parsedTx.MsgType, senderDetails)
 	if err != nil {
 		logging.WithError(err).Error("could not validate BFTTx")
+		span.RecordError(err)
 	}
+	span.SetAttribute("accepted", correct)
 
 	if !correct {
 		// If validated, we save the transaction into the db
@@ -404,12 +409,15 @@ func (app *ABCIApp) Commit() types.ResponseCommit {
 		logging.WithError(err).Fatal("could not copy lagging state")
 	}
 
+	app.commits.commit()
+
 	// submit consensus data with current app hash that is derived from current state (including the previous app hash)
 	return types.ResponseCommit{Data: currAppHash}
 }
 
 // Track the block hash and header information
 func (app *ABCIApp) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock {
+	app.commits.beginBlock()
 	// store time for later use
 	app.state.BlockTime = req.Header.GetTime()
 	// remove new key assignments
@@ -436,10 +444,10 @@ func (app *ABCIApp) EndBlock(req types.RequestEndBlock) types.ResponseEndBlock {
 				logging.WithError(err).Error("Could not marshal keygenMsgShare")
 				continue
 			}
-			err = abciServiceLibrary.KeygennofsmMethods().ReceiveMessage(keygennofsm.CreateKeygenMessage(keygennofsm.KeygenMessageRaw{
+			err = abciServiceLibrary.KeygennofsmMethods().ReceiveMessage(context.Background(), keygennofsm.CreateKeygenMessage(keygennofsm.KeygenMessageRaw{
 				KeygenID: (&keygennofsm.KeygenIDDetails{
 					DKGID:       dkgID,
-					DealerIndex: abciServiceLibrary.EthereumMethods().GetSelfIndex(),
+					DealerIndex: abciServiceLibrary.EthereumMethods().GetSelfIndex(context.Background()),
 				}).ToKeygenID(),
 				Method: "share",
 				Data:   data,
@@ -557,28 +565,29 @@ func (n *NodeDetails) ToNodeDetailsID() NodeDetailsID {
 			n.PubKey.Y.Text(16),
 		}, pcmn.Delimiter1))
 }
-func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) {
+func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) error {
 	s := string(nodeDetailsID)
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 3 {
-		return
+		return errors.New("Error parsing NodeDetails, did not find 3 fields exactly")
 	}
 	index, err := strconv.Atoi(substrings[0])
 	if err != nil {
-		return
+		return err
 	}
-	n.Index = index
 	pubkeyX, ok := new(big.Int).SetString(substrings[1], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey X")
 	}
-	n.PubKey.X = *pubkeyX
 	pubkeyY, ok := new(big.Int).SetString(substrings[2], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey Y")
 	}
+	n.Index = index
+	n.PubKey.X = *pubkeyX
 	n.PubKey.Y = *pubkeyY
+	return nil
 }
 
 type MappingCounter struct {
@@ -594,8 +603,8 @@ func authenticateBftTx(tx []byte) (parsedTx DefaultBFTTxWrapper, senderDetails N
 		return parsedTx, senderDetails, err
 	}
 
-	curEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch()
-	senderDetails, err = abciServiceLibrary.EthereumMethods().VerifyDataWithEpoch(parsedTx.PubKey, parsedTx.Signature, parsedTx.GetSerializedBody(), curEpoch)
+	curEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background())
+	senderDetails, err = abciServiceLibrary.EthereumMethods().VerifyDataWithEpoch(context.Background(), parsedTx.PubKey, parsedTx.Signature, parsedTx.GetSerializedBody(), curEpoch)
 	if err != nil {
 		logging.Errorf("bfttx not valid: error %v, tx %v", err, stringify(parsedTx))
 		return parsedTx, senderDetails, err
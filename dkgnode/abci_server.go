@@ -3,8 +3,9 @@ package dkgnode
 import (
 	"context"
 	"fmt"
-	"github.com/torusresearch/torus-node/telemetry"
+	"io"
 	"math/big"
+	"time"
 
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
@@ -13,8 +14,10 @@ import (
 	"github.com/torusresearch/tendermint/libs/log"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/db"
 	"github.com/torusresearch/torus-node/eventbus"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/telemetry"
 	"github.com/torusresearch/torus-node/tmlog"
 )
 
@@ -50,89 +53,111 @@ func (a *ABCIService) OnStop() error {
 	return nil
 }
 
-func (a *ABCIService) Call(method string, args ...interface{}) (interface{}, error) {
+func (a *ABCIService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.ABCIServer.Prefix)
 
-	switch method {
-	// LastCreatedIndex() (keyIndex uint)
-	case "last_created_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.LastCreatedIndexCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		return a.ABCIApp.state.LastCreatedIndex, nil
-	// LastUnassignedIndex() (keyIndex uint)
-	case "last_unassigned_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.LastUnAssignedIndexCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		return a.ABCIApp.state.LastUnassignedIndex, nil
-	// RetrieveKeyMapping(keyIndex big.Int) (keyDetails KeyAssignmentPublic, err error)
-	// Retrieves KeyAssignment mapping which provides information such as, which verifiers/access structure to the key
-	// This is safe to the public
-	case "retrieve_key_mapping":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.RetrieveKeyMappingCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		var args0 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		keyDetails, err := a.ABCIApp.retrieveKeyMapping(args0)
-		if err != nil {
-			return nil, err
-		}
-		return *keyDetails, err
-	// GetIndexesFromVerifierID(verifier, veriferID string) (keyIndexes []big.Int, err error)
-	case "get_indexes_from_verifier_id":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetIndexesFromVerifierIdCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		var args0, args1 string
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		keyIndexes, err := a.ABCIApp.getIndexesFromVerifierID(args0, args1)
-		return keyIndexes, err
-	case "get_verifier_iterator":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetVerifierIteratorCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		randomID := pvss.RandomBigInt().Text(16)
-		iterator := a.ABCIApp.db.Iterator(verifierToKeyIndexPrefixKey, endVerifierToKeyIndexKey)
-		a.ABCIApp.dbIterators.Set(randomID, iterator)
-		return randomID, nil
-	case "get_verifier_iterator_next":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetVerifierIteratorNextCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-
-		var responseStruct pcmn.VerifierData
-		randomID := args0
-		if !a.ABCIApp.dbIterators.Exists(randomID) {
-			return nil, fmt.Errorf("Could not find dbIterator for randomID %s", randomID)
-		}
-		iterator := a.ABCIApp.dbIterators.Get(randomID)
-		if iterator == nil {
-			return nil, fmt.Errorf("Iterator invalid for randomID %s", randomID)
-		}
-		if !iterator.Valid() {
-			iterator.Close()
-			a.ABCIApp.dbIterators.Delete(randomID)
-			responseStruct.Ok = false
-			return responseStruct, nil
-		}
-		verifier, verifierID, err := deconstructVerifierKey(iterator.Key())
-		if err != nil {
-			responseStruct.Err = fmt.Errorf("key is not in right format for %s", randomID)
-			responseStruct.Ok = true
-			return responseStruct, nil
-		}
-		var keyIndexes []big.Int
-		err = bijson.Unmarshal(iterator.Value(), &keyIndexes)
-		if err != nil {
-			responseStruct.Ok = true
-			responseStruct.Err = fmt.Errorf("could not unmarshal keyIndexes %s", randomID)
-			return responseStruct, nil
-		}
-		iterator.Next()
+	return dispatchABCI(ctx, a, method, args)
+}
+
+func (a *ABCIService) handleLastCreatedIndex(ctx context.Context) (uint, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.LastCreatedIndexCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	return a.ABCIApp.state.LastCreatedIndex, nil
+}
+
+func (a *ABCIService) handleLastUnassignedIndex(ctx context.Context) (uint, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.LastUnAssignedIndexCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	return a.ABCIApp.state.LastUnassignedIndex, nil
+}
+
+// handleRetrieveKeyMapping retrieves KeyAssignment mapping which provides information such as, which verifiers/access structure to the key
+// This is safe to the public
+func (a *ABCIService) handleRetrieveKeyMapping(ctx context.Context, keyIndex big.Int) (KeyAssignmentPublic, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.RetrieveKeyMappingCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	keyDetails, err := a.ABCIApp.retrieveKeyMapping(keyIndex)
+	if err != nil {
+		return KeyAssignmentPublic{}, err
+	}
+	return *keyDetails, nil
+}
+
+func (a *ABCIService) handleGetIndexesFromVerifierID(ctx context.Context, verifier string, verifierID string) ([]big.Int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetIndexesFromVerifierIdCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	return a.ABCIApp.getIndexesFromVerifierID(verifier, verifierID)
+}
+
+func (a *ABCIService) handleGetVerifierIterator(ctx context.Context) (string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetVerifierIteratorCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	randomID := pvss.RandomBigInt().Text(16)
+	iterator := a.ABCIApp.db.Iterator(verifierToKeyIndexPrefixKey, endVerifierToKeyIndexKey)
+	a.ABCIApp.dbIterators.Set(randomID, iterator)
+	return randomID, nil
+}
+
+func (a *ABCIService) handleGetVerifierIteratorNext(ctx context.Context, randomID string) (pcmn.VerifierData, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.GetVerifierIteratorNextCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+
+	var responseStruct pcmn.VerifierData
+	if !a.ABCIApp.dbIterators.Exists(randomID) {
+		return responseStruct, fmt.Errorf("Could not find dbIterator for randomID %s", randomID)
+	}
+	iterator := a.ABCIApp.dbIterators.Get(randomID)
+	if iterator == nil {
+		return responseStruct, fmt.Errorf("Iterator invalid for randomID %s", randomID)
+	}
+	if !iterator.Valid() {
+		iterator.Close()
+		a.ABCIApp.dbIterators.Delete(randomID)
+		responseStruct.Ok = false
+		return responseStruct, nil
+	}
+	verifier, verifierID, err := deconstructVerifierKey(iterator.Key())
+	if err != nil {
+		responseStruct.Err = fmt.Errorf("key is not in right format for %s", randomID)
 		responseStruct.Ok = true
-		responseStruct.Verifier = verifier
-		responseStruct.VerifierID = verifierID
-		responseStruct.KeyIndexes = keyIndexes
 		return responseStruct, nil
 	}
+	var keyIndexes []big.Int
+	err = bijson.Unmarshal(iterator.Value(), &keyIndexes)
+	if err != nil {
+		responseStruct.Ok = true
+		responseStruct.Err = fmt.Errorf("could not unmarshal keyIndexes %s", randomID)
+		return responseStruct, nil
+	}
+	iterator.Next()
+	responseStruct.Ok = true
+	responseStruct.Verifier = verifier
+	responseStruct.VerifierID = verifierID
+	responseStruct.KeyIndexes = keyIndexes
+	return responseStruct, nil
+}
 
-	return nil, fmt.Errorf("ABCI service method %v not found", method)
+// handlePauseCommits blocks until the current block is committed and holds back the next block until
+// ResumeCommits is called with token or leaseMS passed
+func (a *ABCIService) handlePauseCommits(ctx context.Context, token string, leaseMS int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.PauseCommitsCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	return a.ABCIApp.commits.pause(token, time.Duration(leaseMS)*time.Millisecond)
 }
+
+func (a *ABCIService) handleResumeCommits(ctx context.Context, token string) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.ResumeCommitsCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+	return a.ABCIApp.commits.resume(token)
+}
+
+// handleSnapshotState dumps the tendermint app state, commits should be paused so that it matches the returned height
+func (a *ABCIService) handleSnapshotState(ctx context.Context, path string) (int64, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.ABCIServer.SnapshotStateCounter, pcmn.TelemetryConstants.ABCIServer.Prefix)
+
+	iterator := a.ABCIApp.db.Iterator(nil, nil)
+	defer iterator.Close()
+	_, err := dumpToFile(path, func(w io.Writer) (int, error) {
+		return db.Dump(iterator, w)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return a.ABCIApp.info.Height, nil
+}
+
 func (a *ABCIService) SetBaseService(bs *BaseService) {
 	a.bs = bs
 }
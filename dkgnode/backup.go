@@ -0,0 +1,312 @@
+package dkgnode
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	logging "github.com/sirupsen/logrus"
+	dbm "github.com/torusresearch/tm-db"
+	"github.com/torusresearch/torus-node/backup"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/db"
+	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/version"
+)
+
+// layout of the staging directory that is archived
+const (
+	torusDBDumpName = "torusdb.kv"
+	tmStateDumpName = "tmstate.kv"
+	tendermintDir   = "tendermint"
+	// dumps of the tendermint databases, which are left out of tendermintDir
+	tmStoresDir = "tmstores"
+)
+
+// backupCommitPauseLease bounds how long a backup holds back blocks, the backup fails if it takes longer
+const backupCommitPauseLease = 30 * time.Minute
+
+// dumpToFile creates path and writes a database dump into it
+func dumpToFile(path string, dump func(io.Writer) (int, error)) (int, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	count, err := dump(f)
+	if err != nil {
+		return count, err
+	}
+	return count, f.Sync()
+}
+
+// copyTree copies the regular files under src into dst, skipping directories for which skip returns true
+func copyTree(src, dst string, skip func(info os.FileInfo) bool) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && skip(info) {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
+
+// createBackup writes an encrypted archive of the node databases to archivePath.
+// Commits are paused while the databases are snapshotted so that the tendermint
+// app state and block stores agree on the block height, tendermint may save the
+// next block before it is held back, which it replays on start. The torus database is
+// not tied to blocks and is dumped from a point in time snapshot.
+func createBackup(eventBus eventbus.Bus, archivePath string, passphrase string) (manifest backup.Manifest, err error) {
+	if passphrase == "" {
+		return manifest, errors.New("backup passphrase must not be empty")
+	}
+	serviceLibrary := NewServiceLibrary(eventBus, "backup")
+	staging, err := ioutil.TempDir(config.GlobalConfig.BasePath, "backup")
+	if err != nil {
+		return manifest, err
+	}
+	defer os.RemoveAll(staging)
+
+	height, err := snapshotDatabases(serviceLibrary, staging)
+	if err != nil {
+		return manifest, err
+	}
+	manifest = backup.Manifest{
+		CreatedAt:   time.Now().Unix(),
+		NodeVersion: version.NodeVersion,
+		NodeAddress: serviceLibrary.EthereumMethods().GetSelfAddress(context.Background()).Hex(),
+		Epoch:       serviceLibrary.EthereumMethods().GetCurrentEpoch(context.Background()),
+		BlockHeight: height,
+		DBBackend:   config.GlobalConfig.DBBackend,
+	}
+
+	f, err := os.OpenFile(archivePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return manifest, err
+	}
+	manifest, err = backup.Write(f, staging, manifest, passphrase)
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return manifest, err
+	}
+	logging.WithFields(logging.Fields{
+		"path":   archivePath,
+		"height": manifest.BlockHeight,
+		"files":  len(manifest.Files),
+	}).Info("created backup")
+	return manifest, nil
+}
+
+func snapshotDatabases(serviceLibrary ServiceLibrary, staging string) (height int64, err error) {
+	tokenBytes := make([]byte, 16)
+	if _, err = io.ReadFull(nodeEntropy, tokenBytes); err != nil {
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+	// resume even if the pause failed, it may have taken effect after the call returned
+	defer func() {
+		resumeErr := serviceLibrary.ABCIMethods().ResumeCommits(context.Background(), token)
+		if resumeErr != nil && err == nil {
+			err = fmt.Errorf("could not resume commits after backup: %v", resumeErr)
+		}
+	}()
+	err = serviceLibrary.ABCIMethods().PauseCommits(context.Background(), token, int(backupCommitPauseLease/time.Millisecond))
+	if err != nil {
+		return
+	}
+	_, err = serviceLibrary.DatabaseMethods().SnapshotDB(context.Background(), filepath.Join(staging, torusDBDumpName))
+	if err != nil {
+		return
+	}
+	height, err = serviceLibrary.ABCIMethods().SnapshotState(context.Background(), filepath.Join(staging, tmStateDumpName))
+	if err != nil {
+		return
+	}
+	_, err = serviceLibrary.TendermintMethods().SnapshotStores(context.Background(), filepath.Join(staging, tmStoresDir))
+	if err != nil {
+		return
+	}
+	// the databases are written to while the node runs and are restored from their dumps,
+	// the consensus WAL is removed on every start and is not needed
+	err = copyTree(filepath.Join(config.GlobalConfig.BasePath, tendermintDir), filepath.Join(staging, tendermintDir), func(info os.FileInfo) bool {
+		return info.Name() == "cs.wal" || filepath.Ext(info.Name()) == ".db"
+	})
+	return
+}
+
+// RestoreOptions configures an offline restore of a backup archive
+type RestoreOptions struct {
+	BasePath    string
+	DBBackend   string
+	ArchivePath string
+	Passphrase  string
+	// the archive must have been created by NodeAddress in Epoch
+	Epoch       int
+	NodeAddress string
+	// move existing databases aside instead of refusing to restore
+	Force bool
+}
+
+// RestoreBackup restores an archive created by the create_backup management
+// RPC into opts.BasePath. The node must not be running. Database dumps are
+// loaded into opts.DBBackend, which may differ from the backend that was backed up.
+func RestoreBackup(opts RestoreOptions) (manifest backup.Manifest, err error) {
+	f, err := os.Open(opts.ArchivePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	r, err := backup.Open(f, opts.Passphrase)
+	if err != nil {
+		return
+	}
+	manifest = r.Manifest()
+	err = manifest.Validate(opts.Epoch, opts.NodeAddress)
+	if err != nil {
+		return
+	}
+
+	targets := []string{
+		filepath.Join(opts.BasePath, "torusdb"),
+		filepath.Join(opts.BasePath, "tmstate"),
+		filepath.Join(opts.BasePath, tendermintDir),
+	}
+	suffix := fmt.Sprintf(".pre-restore-%d", time.Now().Unix())
+	for _, target := range targets {
+		if _, statErr := os.Stat(target); os.IsNotExist(statErr) {
+			continue
+		}
+		if !opts.Force {
+			return manifest, fmt.Errorf("%s already exists, refusing to restore over it", target)
+		}
+		logging.WithField("path", target+suffix).Info("moving existing database aside")
+		if err = os.Rename(target, target+suffix); err != nil {
+			return
+		}
+	}
+
+	if err = os.MkdirAll(opts.BasePath, 0700); err != nil {
+		return
+	}
+	staging, err := ioutil.TempDir(opts.BasePath, "restore")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(staging)
+	if err = r.Extract(staging); err != nil {
+		return
+	}
+
+	torusDB, err := db.NewTorusLDBWithBackend(targets[0], db.BackendType(opts.DBBackend))
+	if err != nil {
+		return
+	}
+	_, err = loadFromFile(filepath.Join(staging, torusDBDumpName), torusDB.Load)
+	torusDB.Close()
+	if err != nil {
+		return
+	}
+
+	stateDB, err := dbm.NewGoLevelDB("tmstate", targets[1])
+	if err != nil {
+		return
+	}
+	_, err = loadFromFile(filepath.Join(staging, tmStateDumpName), func(r io.Reader) (int, error) {
+		return loadIntoStore(stateDB, r)
+	})
+	stateDB.Close()
+	if err != nil {
+		return
+	}
+
+	if err = os.Rename(filepath.Join(staging, tendermintDir), targets[2]); err != nil {
+		return
+	}
+	err = restoreTendermintStores(filepath.Join(staging, tmStoresDir), filepath.Join(targets[2], "data"))
+	return
+}
+
+// restoreTendermintStores loads the dumps written by SnapshotStores into the goleveldb
+// databases the tendermint node opens in dataDir
+func restoreTendermintStores(dumpDir, dataDir string) error {
+	dumps, err := ioutil.ReadDir(dumpDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return err
+	}
+	for _, dump := range dumps {
+		id := strings.TrimSuffix(dump.Name(), ".kv")
+		store, err := dbm.NewGoLevelDB(id, dataDir)
+		if err != nil {
+			return err
+		}
+		_, err = loadFromFile(filepath.Join(dumpDir, dump.Name()), func(r io.Reader) (int, error) {
+			return loadIntoStore(store, r)
+		})
+		store.Close()
+		if err != nil {
+			return fmt.Errorf("could not restore tendermint %v db: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func loadIntoStore(store dbm.DB, r io.Reader) (int, error) {
+	batch := store.NewBatch()
+	defer batch.Close()
+	count, err := db.Load(r, batch)
+	if err != nil {
+		return count, err
+	}
+	batch.WriteSync()
+	return count, nil
+}
+
+func loadFromFile(path string, load func(io.Reader) (int, error)) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return load(f)
+}
@@ -1,10 +1,13 @@
 package dkgnode
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
@@ -15,11 +18,13 @@ import (
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/config"
 	"github.com/torusresearch/torus-node/dealer"
+	"github.com/torusresearch/torus-node/faults"
 	"github.com/torusresearch/torus-node/keygennofsm"
 	"github.com/torusresearch/torus-node/mapping"
 	"github.com/torusresearch/torus-node/msgqueue"
 	"github.com/torusresearch/torus-node/pss"
 	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/tracing"
 )
 
 type BFTRPC struct {
@@ -41,6 +46,8 @@ type DefaultBFTTxWrapper struct {
 	PubKey    common.Point `json:"pub_key,omitempty"`
 	MsgType   byte         `json:"msg_type,omitempty"`
 	Signature []byte       `json:"signature,omitempty"`
+	// TraceParent continues the trace of the broadcasting node, unsigned like P2PBasicMsg.TraceParent
+	TraceParent string `json:"trace_parent,omitempty"`
 }
 
 type AssignmentBFTTx struct {
@@ -69,7 +76,7 @@ func (wrapper *DefaultBFTTxWrapper) PrepareBFTTx(bftTx interface{}) ([]byte, err
 		return nil, fmt.Errorf("Could not generate random number")
 	}
 	wrapper.Nonce = uint32(nonce.Int64())
-	pk := abciServiceLibrary.EthereumMethods().GetSelfPublicKey()
+	pk := abciServiceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
 	wrapper.PubKey.X = pk.X
 	wrapper.PubKey.Y = pk.Y
 	bftRaw, err := bijson.Marshal(bftTx)
@@ -80,7 +87,7 @@ func (wrapper *DefaultBFTTxWrapper) PrepareBFTTx(bftTx interface{}) ([]byte, err
 
 	// sign message data
 	data := wrapper.GetSerializedBody()
-	wrapper.Signature = abciServiceLibrary.EthereumMethods().SelfSignData(data)
+	wrapper.Signature = abciServiceLibrary.EthereumMethods().SelfSignData(context.Background(), data)
 
 	rawMsg, err := bijson.Marshal(wrapper)
 	if err != nil {
@@ -99,6 +106,7 @@ func (wrapper *DefaultBFTTxWrapper) DecodeBFTTx(data []byte) error {
 
 func (wrapper DefaultBFTTxWrapper) GetSerializedBody() []byte {
 	wrapper.Signature = nil
+	wrapper.TraceParent = ""
 	bin, err := bijson.Marshal(wrapper)
 	if err != nil {
 		logging.Errorf("could not GetSerializedBody bfttx, %v", err)
@@ -116,13 +124,28 @@ func getType(myvar interface{}) string {
 
 // BroadcastTxSync Wrapper (input should be bijsoned) to tendermint.
 // blocks until message is sent
-func (bftrpc BFTRPC) Broadcast(bftTx interface{}) (*pcmn.Hash, error) {
-
-	var wrapper DefaultBFTTxWrapper
+func (bftrpc BFTRPC) Broadcast(bftTx interface{}) (hash *pcmn.Hash, err error) {
+	span := startInstanceSpan("bft.broadcast", tracing.KindProducer, instanceTraceKey(bftTx))
+	span.SetAttribute("msg_type", getType(bftTx))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	wrapper := DefaultBFTTxWrapper{TraceParent: span.Traceparent()}
 	preparedTx, err := wrapper.PrepareBFTTx(bftTx)
 	if err != nil {
 		return nil, err
 	}
+	preparedTx, dropped, err := bftrpc.injectFaults(&wrapper, preparedTx)
+	if err != nil {
+		return nil, err
+	}
+	if dropped {
+		// the tx is lost on its way to tendermint, as far as the caller can tell it was broadcast
+		hash := sha256.Sum256(preparedTx)
+		return &pcmn.Hash{HexBytes: hash[:]}, nil
+	}
 
 	res := bftrpc.BftMsgQueue.Add(preparedTx)
 	response, ok := res.(*tmtypes.ResultBroadcastTx)
@@ -139,6 +162,33 @@ func (bftrpc BFTRPC) Broadcast(bftTx interface{}) (*pcmn.Hash, error) {
 	return &pcmn.Hash{HexBytes: response.Hash.Bytes()}, nil
 }
 
+// injectFaults applies the faults of faultInjector to preparedTx, the prepared wrapper. Delays
+// hold up the broadcast, duplicates are broadcast in the background and a corrupted tx is signed
+// again so that it reaches the ABCI app.
+func (bftrpc BFTRPC) injectFaults(wrapper *DefaultBFTTxWrapper, preparedTx []byte) (tx []byte, dropped bool, err error) {
+	decision := faultInjector.Apply(faults.ProtocolBFT, faults.Send, "", wrapper.BFTTx)
+	if decision.Drop {
+		logging.WithField("msg_type", wrapper.MsgType).Debug("fault injection dropped bft tx")
+		return preparedTx, true, nil
+	}
+	if decision.Delay > 0 {
+		time.Sleep(decision.Delay)
+	}
+	if decision.Corrupted {
+		logging.WithField("msg_type", wrapper.MsgType).Debug("fault injection corrupted bft tx")
+		wrapper.BFTTx = decision.Payload
+		wrapper.Signature = abciServiceLibrary.EthereumMethods().SelfSignData(context.Background(), wrapper.GetSerializedBody())
+		preparedTx, err = bijson.Marshal(wrapper)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	for i := 0; i < decision.Duplicates; i++ {
+		go bftrpc.BftMsgQueue.Add(preparedTx)
+	}
+	return preparedTx, false, nil
+}
+
 // Retrieves tx from the bft and gives back results.
 func (bftrpc BFTRPC) Retrieve(hash []byte, txStruct BFTTxWrapper) (err error) {
 	result, err := bftrpc.Tx(hash, false)
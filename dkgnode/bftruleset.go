@@ -1,6 +1,7 @@
 package dkgnode
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sort"
@@ -30,8 +31,8 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.BFTRuleSet.TransactionsCounter, pcmn.TelemetryConstants.BFTRuleSet.Prefix)
 
 	var tags []tmcommon.KVPair
-	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch()
-	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(currEpoch, false)
+	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background())
+	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, false)
 	if err != nil {
 		return false, &tags, fmt.Errorf("could not get current epoch with err: %v", err)
 	}
@@ -190,14 +191,16 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 				}
 				for nodeDetailsID, proposeProof := range keygenMsgPropose.ProposeProofs[i] {
 					var nodeDetails keygennofsm.NodeDetails
-					nodeDetails.FromNodeDetailsID(nodeDetailsID)
+					if err := nodeDetails.FromNodeDetailsID(nodeDetailsID); err != nil {
+						return false, &tags, err
+					}
 					// validate node
 					foundNode, err := validateNode(
 						abciServiceLibrary,
 						nodeDetails.PubKey.X,
 						nodeDetails.PubKey.Y,
 						nodeDetails.Index,
-						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(),
+						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background()),
 					)
 					if err != nil {
 						return false, &tags, err
@@ -229,7 +232,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 					logging.WithError(err).Error("Could not marshal keygenMsgDecide")
 					return
 				}
-				err = abciServiceLibrary.KeygennofsmMethods().ReceiveBFTMessage(keygennofsm.CreateKeygenMessage(keygennofsm.KeygenMessageRaw{
+				err = abciServiceLibrary.KeygennofsmMethods().ReceiveBFTMessage(context.Background(), keygennofsm.CreateKeygenMessage(keygennofsm.KeygenMessageRaw{
 					KeygenID: keygennofsm.NullKeygenID,
 					Method:   "decide",
 					Data:     data,
@@ -304,7 +307,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 			if err != nil {
 				return false, &tags, err
 			}
-			err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(*GS, keyIndex)
+			err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(context.Background(), *GS, keyIndex)
 			if err != nil {
 				logging.Error("Could not store completed keygen pubkey")
 				return false, &tags, err
@@ -392,14 +395,16 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 				}
 				for nodeDetailsID, signedText := range pssMsgPropose.SignedTexts[i] {
 					var nodeDetails pss.NodeDetails
-					nodeDetails.FromNodeDetailsID(nodeDetailsID)
+					if err := nodeDetails.FromNodeDetailsID(nodeDetailsID); err != nil {
+						return false, &tags, err
+					}
 					// validate node
 					foundNode, err := validateNode(
 						abciServiceLibrary,
 						nodeDetails.PubKey.X,
 						nodeDetails.PubKey.Y,
 						nodeDetails.Index,
-						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(),
+						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background()),
 					)
 					if err != nil {
 						return false, &tags, err
@@ -437,7 +442,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 			})
 			protocolPrefix := PSSProtocolPrefix("pss" + "-" + strconv.Itoa(epochOld) + "-" + strconv.Itoa(epochNew) + "/")
 			go func(prefix PSSProtocolPrefix, pssMsg pss.PSSMessage) {
-				err := abciServiceLibrary.PSSMethods().ReceiveBFTMessage(prefix, pssMsg)
+				err := abciServiceLibrary.PSSMethods().ReceiveBFTMessage(context.Background(), prefix, pssMsg)
 				if err != nil {
 					logging.WithError(err).Error("could not receive BFT message when sending decide")
 					return
@@ -481,7 +486,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 				go func(proposedMID mapping.MappingID) {
 					// continue pss trigger
 					// external state updates should be run in goroutines
-					abciServiceLibrary.MappingMethods().SetFreezeState(proposedMID, 2, app.state.LastUnassignedIndex)
+					abciServiceLibrary.MappingMethods().SetFreezeState(context.Background(), proposedMID, 2, app.state.LastUnassignedIndex)
 				}(proposedMappingID)
 			}
 			return true, &tags, nil
@@ -518,11 +523,11 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 
 				for i := 0; i < int(mappingSummaryBroadcastMessage.TransferSummary.LastUnassignedIndex); i++ {
 					dkgID := keygennofsm.GenerateDKGID(*big.NewInt(int64(i)))
-					if abciServiceLibrary.DatabaseMethods().GetKeygenStarted(string(dkgID)) {
+					if abciServiceLibrary.DatabaseMethods().GetKeygenStarted(context.Background(), string(dkgID)) {
 						logging.WithField("dkgID", dkgID).Info("Keygen already started for pss")
 						continue
 					}
-					err = abciServiceLibrary.DatabaseMethods().SetKeygenStarted(string(dkgID), true)
+					err = abciServiceLibrary.DatabaseMethods().SetKeygenStarted(context.Background(), string(dkgID), true)
 					if err != nil {
 						logging.WithError(err).Error("could not write to database")
 						continue
@@ -557,7 +562,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 				mappingCounter.KeyCount++
 				app.state.MappingCounters[mappingID] = mappingCounter
 				mappingKey := mappingKeyBroadcastMessage.MappingKey
-				err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(mappingKey.PublicKey, mappingKey.Index)
+				err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(context.Background(), mappingKey.PublicKey, mappingKey.Index)
 				if err != nil {
 					logging.WithError(err).Error("could not store key mapping in database")
 				}
@@ -640,7 +645,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 			if err != nil {
 				return false, &tags, errors.New("could not store key mapping")
 			}
-			err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(newKeyAssignmentPublic.PublicKey, newKeyAssignmentPublic.Index)
+			err = abciServiceLibrary.DatabaseMethods().StorePublicKeyToIndex(context.Background(), newKeyAssignmentPublic.PublicKey, newKeyAssignmentPublic.Index)
 			if err != nil {
 				return false, &tags, errors.New("could not store public key to index")
 			}
@@ -654,7 +659,7 @@ func (app *ABCIApp) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sen
 
 // Checks if status update is from a valid node in a particular epoch
 func validateNode(serviceLibrary ServiceLibrary, x big.Int, y big.Int, index int, epoch int) (*pss.NodeDetails, error) {
-	nodeRef := serviceLibrary.EthereumMethods().GetNodeDetailsByEpochAndIndex(epoch, index)
+	nodeRef := serviceLibrary.EthereumMethods().GetNodeDetailsByEpochAndIndex(context.Background(), epoch, index)
 	if nodeRef.PublicKey.X.Cmp(&x) != 0 || nodeRef.PublicKey.Y.Cmp(&y) != 0 {
 		return nil, errors.New("could not find node")
 	}
@@ -665,8 +670,8 @@ func (app *ABCIApp) validateTx(bftTx []byte, msgType byte, senderDetails NodeDet
 
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.BFTRuleSet.TransactionsCounter, pcmn.TelemetryConstants.ABCIApp.CheckTxPrefix)
 
-	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch()
-	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(currEpoch, false)
+	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background())
+	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, false)
 	if err != nil {
 		return false, fmt.Errorf("could not get current epoch with err: %v", err)
 	}
@@ -767,14 +772,16 @@ func (app *ABCIApp) validateTx(bftTx []byte, msgType byte, senderDetails NodeDet
 				}
 				for nodeDetailsID, proposeProof := range keygenMsgPropose.ProposeProofs[i] {
 					var nodeDetails keygennofsm.NodeDetails
-					nodeDetails.FromNodeDetailsID(nodeDetailsID)
+					if err := nodeDetails.FromNodeDetailsID(nodeDetailsID); err != nil {
+						return false, err
+					}
 					// validate node
 					foundNode, err := validateNode(
 						abciServiceLibrary,
 						nodeDetails.PubKey.X,
 						nodeDetails.PubKey.Y,
 						nodeDetails.Index,
-						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(),
+						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background()),
 					)
 					if err != nil {
 						return false, err
@@ -917,14 +924,16 @@ func (app *ABCIApp) validateTx(bftTx []byte, msgType byte, senderDetails NodeDet
 				}
 				for nodeDetailsID, signedText := range pssMsgPropose.SignedTexts[i] {
 					var nodeDetails pss.NodeDetails
-					nodeDetails.FromNodeDetailsID(nodeDetailsID)
+					if err := nodeDetails.FromNodeDetailsID(nodeDetailsID); err != nil {
+						return false, err
+					}
 					// validate node
 					foundNode, err := validateNode(
 						abciServiceLibrary,
 						nodeDetails.PubKey.X,
 						nodeDetails.PubKey.Y,
 						nodeDetails.Index,
-						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(),
+						abciServiceLibrary.EthereumMethods().GetCurrentEpoch(context.Background()),
 					)
 					if err != nil {
 						return false, err
@@ -0,0 +1,117 @@
+package dkgnode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/telemetry"
+)
+
+// defaultEventBusTopics bounds the topics fed by other nodes, so that a burst of p2p
+// messages or BFT transactions applies backpressure instead of spawning a goroutine each.
+// "method" and the response topics are not bounded as services call other services
+// while handling a call, which would deadlock once every worker waits on a nested call.
+var defaultEventBusTopics = map[string]eventbus.TopicConfig{
+	"p2p:forward:*":        {Workers: 256, QueueSize: 4096, Overflow: eventbus.Block},
+	"tendermint:forward:*": {Workers: 64, QueueSize: 4096, Overflow: eventbus.Block},
+}
+
+var overflowPolicies = map[string]eventbus.OverflowPolicy{
+	"block":       eventbus.Block,
+	"drop-oldest": eventbus.DropOldest,
+	"reject":      eventbus.Reject,
+}
+
+// parseEventBusTopicLimits parses comma separated "topic=workers/queue/policy" entries
+func parseEventBusTopicLimits(s string) (map[string]eventbus.TopicConfig, error) {
+	limits := make(map[string]eventbus.TopicConfig)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid event bus topic limit %v, expected topic=workers/queue/policy", entry)
+		}
+		parts := strings.Split(kv[1], "/")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid event bus topic limit %v, expected topic=workers/queue/policy", entry)
+		}
+		workers, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || workers < 0 {
+			return nil, fmt.Errorf("invalid workers in event bus topic limit %v", entry)
+		}
+		queueSize, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || queueSize < 0 {
+			return nil, fmt.Errorf("invalid queue size in event bus topic limit %v", entry)
+		}
+		policy, ok := overflowPolicies[strings.TrimSpace(parts[2])]
+		if !ok {
+			return nil, fmt.Errorf("invalid policy in event bus topic limit %v, expected block, drop-oldest or reject", entry)
+		}
+		limits[strings.TrimSpace(kv[0])] = eventbus.TopicConfig{Workers: workers, QueueSize: queueSize, Overflow: policy}
+	}
+	return limits, nil
+}
+
+// eventBusTopicLabel groups the topics of the system event bus for metrics,
+// topics are named either by a fixed word or by a prefix followed by an ID
+func eventBusTopicLabel(topic string) string {
+	for _, prefix := range []string{"p2p:forward:", "tendermint:forward:", "iterator_value:"} {
+		if strings.HasPrefix(topic, prefix) {
+			return strings.TrimSuffix(prefix, ":")
+		}
+	}
+	if topic == "method" {
+		return topic
+	}
+	return "response"
+}
+
+// onEventDropped answers dropped method requests so that callers do not wait for the timeout
+func onEventDropped(eventBus *eventbus.Bus) func(string, interface{}) {
+	return func(topic string, data interface{}) {
+		logging.WithField("topic", topic).Warn("event bus queue is full, dropped event")
+		if methodRequest, ok := data.(MethodRequest); ok {
+			(*eventBus).Publish(methodRequest.ID, MethodResponse{
+				Request: methodRequest,
+				Error:   fmt.Errorf("service %v is overloaded, dropped call to %v", methodRequest.Service, methodRequest.Method),
+			})
+		}
+	}
+}
+
+// newSystemEventBus creates the event bus of the node, bounded by the configured topic limits
+func newSystemEventBus() eventbus.Bus {
+	var systemEventBus eventbus.Bus
+	topics := make(map[string]eventbus.TopicConfig)
+	for topic, topicConfig := range defaultEventBusTopics {
+		topics[topic] = topicConfig
+	}
+	limits, err := parseEventBusTopicLimits(config.GlobalConfig.EventBusTopicLimits)
+	if err != nil {
+		logging.WithError(err).Error("could not parse event bus topic limits, using defaults")
+	}
+	for topic, topicConfig := range limits {
+		topics[topic] = topicConfig
+	}
+	for topic, topicConfig := range topics {
+		topicConfig.OnDrop = onEventDropped(&systemEventBus)
+		topics[topic] = topicConfig
+	}
+
+	metrics := telemetry.NewEventBusMetrics(eventBusTopicLabel)
+	if err := telemetry.Register(metrics); err != nil {
+		logging.WithError(err).Error("could not register event bus metrics")
+	}
+	systemEventBus = eventbus.NewWithConfig(eventbus.Config{
+		Topics:  topics,
+		Metrics: metrics,
+	})
+	return systemEventBus
+}
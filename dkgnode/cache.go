@@ -2,7 +2,6 @@ package dkgnode
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
 
@@ -77,58 +76,41 @@ func (c *CacheService) OnStop() error {
 	return nil
 }
 
-func (c *CacheService) Call(method string, args ...interface{}) (interface{}, error) {
+func (c *CacheService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Cache.Prefix)
-	switch method {
-	// TokenCommitExists(verifier string, tokenCommitment string) (exists bool)
-	case "token_commit_exists":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.TokenCommitExistsCounter, pcmn.TelemetryConstants.Cache.Prefix)
-
-		var args0, args1 string
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		exists := c.tokenCommitExists(args0, args1)
-		return exists, nil
-	// GetTokenCommitKey(verifier string, tokenCommitment string) (pubKey common.Point)
-	case "get_token_commit_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.GetTokenCommitKeyCounter, pcmn.TelemetryConstants.Cache.Prefix)
-
-		var args0, args1 string
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		pubKey := c.getTokenCommitKey(args0, args1)
-		return pubKey, nil
-	// RecordTokenCommit(verifier string, tokenCommitment string, pubKey common.Point)
-	case "record_token_commit":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.RecordTokenCommitCounter, pcmn.TelemetryConstants.Cache.Prefix)
-
-		var args0, args1 string
-		var args2 common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		c.recordTokenCommit(args0, args1, args2)
-		return nil, nil
-	// SignerSigExists(signature string) (exists bool)
-	case "signer_sig_exists":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.SignerSigExistsCounter, pcmn.TelemetryConstants.Cache.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-		exists := c.signerSigExists(args0)
-		return exists, nil
-	// RecordSignerSig(signature string)
-	case "record_signer_sig":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.RecordSignerSigCounter, pcmn.TelemetryConstants.Cache.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-		return nil, c.recordSignerSig(args0)
-	}
-	return nil, fmt.Errorf("cache service method %v not found", method)
+
+	return dispatchCache(ctx, c, method, args)
+}
+
+func (c *CacheService) handleTokenCommitExists(ctx context.Context, verifier string, tokenCommitment string) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.TokenCommitExistsCounter, pcmn.TelemetryConstants.Cache.Prefix)
+
+	return c.tokenCommitExists(verifier, tokenCommitment), nil
+}
+
+func (c *CacheService) handleGetTokenCommitKey(ctx context.Context, verifier string, tokenCommitment string) (common.Point, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.GetTokenCommitKeyCounter, pcmn.TelemetryConstants.Cache.Prefix)
+
+	return c.getTokenCommitKey(verifier, tokenCommitment), nil
+}
+
+func (c *CacheService) handleRecordTokenCommit(ctx context.Context, verifier string, tokenCommitment string, pubKey common.Point) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.RecordTokenCommitCounter, pcmn.TelemetryConstants.Cache.Prefix)
+
+	c.recordTokenCommit(verifier, tokenCommitment, pubKey)
+	return nil
+}
+
+func (c *CacheService) handleSignerSigExists(ctx context.Context, signature string) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.SignerSigExistsCounter, pcmn.TelemetryConstants.Cache.Prefix)
+
+	return c.signerSigExists(signature), nil
+}
+
+func (c *CacheService) handleRecordSignerSig(ctx context.Context, signature string) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Cache.RecordSignerSigCounter, pcmn.TelemetryConstants.Cache.Prefix)
+
+	return c.recordSignerSig(signature)
 }
 
 func (c *CacheService) SetBaseService(bs *BaseService) {
@@ -0,0 +1,15 @@
+package dkgnode
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/torusresearch/torus-node/sim"
+)
+
+// nodeClock and nodeEntropy are the clock and randomness of the monitors and of the keygen, PSS
+// and mapping nodes started by this node, tests replace them with those of a sim.Simulation
+var (
+	nodeClock   sim.Clock = sim.RealClock
+	nodeEntropy io.Reader = rand.Reader
+)
@@ -0,0 +1,105 @@
+package dkgnode
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	logging "github.com/sirupsen/logrus"
+)
+
+var errCommitPauseLapsed = errors.New("commit pause was lifted before it was resumed")
+
+// commitGate holds back blocks while a backup snapshots the databases. A pause is
+// identified by a token chosen by the caller and lapses after its lease, so a backup
+// that never resumes cannot stall the chain.
+type commitGate struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// set from BeginBlock until Commit
+	inBlock bool
+	token   string
+	expires time.Time
+}
+
+func newCommitGate() *commitGate {
+	g := &commitGate{}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// pausedLocked reports whether a pause is active, lifting it once its lease expired
+func (g *commitGate) pausedLocked() bool {
+	if g.token == "" {
+		return false
+	}
+	if nodeClock.Now().Before(g.expires) {
+		return true
+	}
+	logging.WithField("token", g.token).Warn("commit pause lease expired, resuming commits")
+	g.token = ""
+	g.cond.Broadcast()
+	return false
+}
+
+// beginBlock waits until no pause is active and marks a block as in progress
+func (g *commitGate) beginBlock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for g.pausedLocked() {
+		g.cond.Wait()
+	}
+	g.inBlock = true
+}
+
+func (g *commitGate) commit() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inBlock = false
+	g.cond.Broadcast()
+}
+
+// pause holds back the next block for at most lease and waits for the block in progress
+// to be committed. Pausing again with the same token renews the lease, pausing with another
+// token while a pause is active fails.
+func (g *commitGate) pause(token string, lease time.Duration) error {
+	if token == "" {
+		return errors.New("commit pause token must not be empty")
+	}
+	if lease <= 0 {
+		return errors.New("commit pause lease must be positive")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pausedLocked() && g.token != token {
+		return errors.New("commits are already paused by another caller")
+	}
+	g.token = token
+	g.expires = nodeClock.Now().Add(lease)
+	go func() {
+		<-nodeClock.After(lease)
+		g.mu.Lock()
+		g.pausedLocked()
+		g.mu.Unlock()
+	}()
+	for g.inBlock {
+		g.cond.Wait()
+		if !g.pausedLocked() || g.token != token {
+			return errCommitPauseLapsed
+		}
+	}
+	return nil
+}
+
+// resume lifts the pause identified by token. It fails if the pause is no longer active,
+// as blocks may have been committed in the meantime.
+func (g *commitGate) resume(token string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.pausedLocked() || g.token != token {
+		return errCommitPauseLapsed
+	}
+	g.token = ""
+	g.cond.Broadcast()
+	return nil
+}
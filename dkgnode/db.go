@@ -7,6 +7,7 @@ import (
 	"math/big"
 
 	ethCommon "github.com/ethereum/go-ethereum/common"
+	logging "github.com/sirupsen/logrus"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/telemetry"
 
@@ -50,215 +51,170 @@ func (d *DatabaseService) Name() string {
 }
 
 func (d *DatabaseService) OnStart() error {
-	torusLdb, err := db.NewTorusLDB(fmt.Sprintf("%s/torusdb", config.GlobalConfig.BasePath))
+	backend := db.ResolveBackend(db.BackendType(config.GlobalConfig.DBBackend))
+	torusLdb, err := db.NewTorusLDBWithBackend(fmt.Sprintf("%s/torusdb", config.GlobalConfig.BasePath), backend)
 	if err != nil {
-		return errors.New("Was not able to start leveldb: " + err.Error())
+		return errors.New("Was not able to start " + string(backend) + " db: " + err.Error())
 	}
 	d.dbInstance = torusLdb
+	report := torusLdb.CheckConsistency(true)
+	for _, issue := range report.Issues {
+		logging.WithFields(logging.Fields{
+			"keyIndex": issue.KeyIndex.Text(16),
+			"problem":  issue.Problem,
+			"repaired": issue.Repaired,
+		}).Warn("found half written key index in db")
+	}
 	return nil
 }
 func (d *DatabaseService) OnStop() error {
 	return nil
 }
-func (d *DatabaseService) Call(method string, args ...interface{}) (interface{}, error) {
+func (d *DatabaseService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.DB.Prefix)
 
-	switch method {
-	// StoreNodePubKey(nodeAddress ethCommon.Address, pubKey common.Point) error
-	case "store_node_pub_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreNodePubKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 ethCommon.Address
-		var args1 common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		err := d.dbInstance.StoreNodePubKey(args0, args1)
-		return nil, err
-
-		// RetrieveNodePubKey(nodeAddress ethCommon.Address) (pubKey common.Point, err error)
-	case "retrieve_node_pub_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveNodePubKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 ethCommon.Address
-		_ = castOrUnmarshal(args[0], &args0)
-
-		return d.dbInstance.RetrieveNodePubKey(args0)
-
-	// StoreConnectionDetails(nodeAddress ethCommon.Address, connectionDetails ConnectionDetails) error
-	case "store_connection_details":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreConnectionDetailsCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 ethCommon.Address
-		var args1 ConnectionDetails
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		err := d.dbInstance.StoreConnectionDetails(args0, args1.TMP2PConnection, args1.P2PConnection)
-		return nil, err
-
-	// RetrieveConnectionDetails(nodeAddress ethCommon.Address) (connectionDetails ConnectionDetails, err error)
-	case "retrieve_connection_details":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveConnectionDetailsCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 ethCommon.Address
-		_ = castOrUnmarshal(args[0], &args0)
-
-		tmP2PConnection, P2PConnection, err := d.dbInstance.RetrieveConnectionDetails(args0)
-		return ConnectionDetails{
-			TMP2PConnection: tmP2PConnection,
-			P2PConnection:   P2PConnection,
-		}, err
-
-	// StoreKeygenCommitmentMatrix(keyIndex big.Int, c [][]common.Point) error
-	case "store_keygen_commitment_matrix":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreKeygenCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		var args1 [][]common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		err := d.dbInstance.StoreKeygenCommitmentMatrix(args0, args1)
-		return nil, err
-	// StorePSSCommitmentMatrix(keyIndex big.Int, c [][]common.Point) error
-	case "store_PSS_commitment_matrix":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StorePSSCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		var args1 [][]common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		err := d.dbInstance.StorePSSCommitmentMatrix(args0, args1)
-		return nil, err
-	// StoreCompletedKeygenShare(keyIndex big.Int, si big.Int, siprime big.Int) error
-	case "store_completed_keygen_share":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedKeygenShareCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0, args1, args2 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		err := d.dbInstance.StoreCompletedKeygenShare(args0, args1, args2)
-		return nil, err
-	// StoreCompletedPSSShare(keyIndex big.Int, si big.Int, siprime big.Int) error
-	case "store_completed_PSS_share":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedPssShareCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0, args1, args2 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		err := d.dbInstance.StoreCompletedPSSShare(args0, args1, args2)
-		return nil, err
-	// StorePublicKeyToIndex(publicKey common.Point, keyIndex big.Int) error
-	case "store_public_key_to_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StorePublicKeyToIndexCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 common.Point
-		var args1 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		err := d.dbInstance.StorePublicKeyToKeyIndex(args0, args1)
-		return nil, err
-	// RetrieveCommitmentMatrix(keyIndex big.Int) (c [][]common.Point, err error)
-	case "retrieve_commitment_matrix":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		keyIndex := args0
-		c, err := d.dbInstance.RetrieveCommitmentMatrix(keyIndex)
-		if err != nil {
-			return nil, err
-		}
-		return c, nil
-	// RetrievePublicKeyToIndex(publicKey common.Point) (keyIndex big.Int, err error)
-	case "retrieve_public_key_to_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrievePublicKeyToIndexCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-
-		keyIndex, err := d.dbInstance.RetrievePublicKeyToKeyIndex(args0)
-		if err != nil || keyIndex == nil {
-			return big.NewInt(-1), err
-		}
-		return *keyIndex, err
-	// RetrieveIndexToPublicKey(keyIndex big.Int) (publicKey common.Point, err error)
-	case "retrieve_index_to_public_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveIndexToPublicKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		publicKey, err := d.dbInstance.RetrieveKeyIndexToPublicKey(args0)
-		if err != nil || publicKey == nil {
-			return common.Point{}, err
-		}
-		return *publicKey, err
-		// IndexToPublicKeyExists(keyIndex big.Int) (bool)
-	case "index_to_public_key_exists":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.IndexToPublicKeyCounterExists, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		exists := d.dbInstance.KeyIndexToPublicKeyExists(args0)
-		return exists, nil
-
-	// RetrieveCompletedShare(keyIndex big.Int) (Si big.Int, Siprime big.Int, err error)
-	case "retrieve_completed_share":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveCompletedShareCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 big.Int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		rs := new(struct {
-			Si      big.Int
-			Siprime big.Int
-		})
-		si, sip, err := d.dbInstance.RetrieveCompletedShare(args0)
-		if si == nil {
-			si = big.NewInt(0)
-		}
-		if sip == nil {
-			sip = big.NewInt(0)
-		}
-		rs.Si = *si
-		rs.Siprime = *sip
-		return *rs, err
-	// GetShareCount() (count int)
-	case "get_share_count":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.GetShareCountCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		return d.dbInstance.GetShareCount(), nil
-	case "get_keygen_started":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.GetKeygenStartedCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-
-		return d.dbInstance.GetKeygenStarted(args0), nil
-	case "set_keygen_started":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.SetKeygenStartedCounter, pcmn.TelemetryConstants.DB.Prefix)
-
-		var args0 string
-		var args1 bool
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		d.dbInstance.SetKeygenStarted(args0, args1)
-		return nil, nil
+	return dispatchDatabase(ctx, d, method, args)
+}
+
+func (d *DatabaseService) handleStoreNodePubKey(ctx context.Context, nodeAddress ethCommon.Address, pubKey common.Point) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreNodePubKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreNodePubKey(nodeAddress, pubKey)
+}
+
+func (d *DatabaseService) handleRetrieveNodePubKey(ctx context.Context, nodeAddress ethCommon.Address) (common.Point, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveNodePubKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.RetrieveNodePubKey(nodeAddress)
+}
+
+func (d *DatabaseService) handleStoreConnectionDetails(ctx context.Context, nodeAddress ethCommon.Address, connectionDetails ConnectionDetails) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreConnectionDetailsCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreConnectionDetails(nodeAddress, connectionDetails.TMP2PConnection, connectionDetails.P2PConnection)
+}
+
+func (d *DatabaseService) handleRetrieveConnectionDetails(ctx context.Context, nodeAddress ethCommon.Address) (ConnectionDetails, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveConnectionDetailsCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	tmP2PConnection, P2PConnection, err := d.dbInstance.RetrieveConnectionDetails(nodeAddress)
+	return ConnectionDetails{
+		TMP2PConnection: tmP2PConnection,
+		P2PConnection:   P2PConnection,
+	}, err
+}
+
+func (d *DatabaseService) handleStoreKeygenCommitmentMatrix(ctx context.Context, keyIndex big.Int, c [][]common.Point) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreKeygenCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreKeygenCommitmentMatrix(keyIndex, c)
+}
+
+func (d *DatabaseService) handleStorePSSCommitmentMatrix(ctx context.Context, keyIndex big.Int, c [][]common.Point) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StorePSSCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StorePSSCommitmentMatrix(keyIndex, c)
+}
+
+func (d *DatabaseService) handleStoreCompletedKeygenShare(ctx context.Context, keyIndex big.Int, si big.Int, siprime big.Int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedKeygenShareCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreCompletedKeygenShare(keyIndex, si, siprime)
+}
+
+func (d *DatabaseService) handleStoreCompletedPSSShare(ctx context.Context, keyIndex big.Int, si big.Int, siprime big.Int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedPssShareCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreCompletedPSSShare(keyIndex, si, siprime)
+}
+
+func (d *DatabaseService) handleStoreCompletedKeygen(ctx context.Context, keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedKeygenCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreCompletedKeygen(keyIndex, c, si, siprime)
+}
+
+func (d *DatabaseService) handleStoreCompletedPSS(ctx context.Context, keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StoreCompletedPSSCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StoreCompletedPSS(keyIndex, c, si, siprime)
+}
+
+func (d *DatabaseService) handleStorePublicKeyToIndex(ctx context.Context, publicKey common.Point, keyIndex big.Int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.StorePublicKeyToIndexCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.StorePublicKeyToKeyIndex(publicKey, keyIndex)
+}
+
+func (d *DatabaseService) handleRetrieveCommitmentMatrix(ctx context.Context, keyIndex big.Int) ([][]common.Point, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveCommitmentMatrixCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.RetrieveCommitmentMatrix(keyIndex)
+}
+
+func (d *DatabaseService) handleRetrievePublicKeyToIndex(ctx context.Context, publicKey common.Point) (big.Int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrievePublicKeyToIndexCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	keyIndex, err := d.dbInstance.RetrievePublicKeyToKeyIndex(publicKey)
+	if err != nil || keyIndex == nil {
+		return *big.NewInt(-1), err
+	}
+	return *keyIndex, nil
+}
+
+func (d *DatabaseService) handleRetrieveIndexToPublicKey(ctx context.Context, keyIndex big.Int) (common.Point, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveIndexToPublicKeyCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	publicKey, err := d.dbInstance.RetrieveKeyIndexToPublicKey(keyIndex)
+	if err != nil || publicKey == nil {
+		return common.Point{}, err
+	}
+	return *publicKey, nil
+}
+
+func (d *DatabaseService) handleIndexToPublicKeyExists(ctx context.Context, keyIndex big.Int) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.IndexToPublicKeyCounterExists, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.KeyIndexToPublicKeyExists(keyIndex), nil
+}
+
+func (d *DatabaseService) handleRetrieveCompletedShare(ctx context.Context, keyIndex big.Int) (big.Int, big.Int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.RetrieveCompletedShareCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	si, sip, err := d.dbInstance.RetrieveCompletedShare(keyIndex)
+	if si == nil {
+		si = big.NewInt(0)
+	}
+	if sip == nil {
+		sip = big.NewInt(0)
 	}
+	return *si, *sip, err
+}
+
+func (d *DatabaseService) handleGetShareCount(ctx context.Context) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.GetShareCountCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.GetShareCount(), nil
+}
+
+func (d *DatabaseService) handleGetKeygenStarted(ctx context.Context, keygenID string) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.GetKeygenStartedCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	return d.dbInstance.GetKeygenStarted(keygenID), nil
+}
+
+func (d *DatabaseService) handleSetKeygenStarted(ctx context.Context, keygenID string, started bool) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.SetKeygenStartedCounter, pcmn.TelemetryConstants.DB.Prefix)
+
+	d.dbInstance.SetKeygenStarted(keygenID, started)
+	return nil
+}
+
+func (d *DatabaseService) handleSnapshotDB(ctx context.Context, path string) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.DB.SnapshotDBCounter, pcmn.TelemetryConstants.DB.Prefix)
 
-	return nil, fmt.Errorf("database service method %v not found", method)
+	return dumpToFile(path, d.dbInstance.Dump)
 }
 func (d *DatabaseService) SetBaseService(bs *BaseService) {
 	d.bs = bs
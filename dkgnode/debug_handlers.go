@@ -5,6 +5,7 @@ import (
 
 	"github.com/torusresearch/bijson"
 	"github.com/torusresearch/jsonrpc"
+	"github.com/torusresearch/torus-node/ethrpc"
 	"github.com/torusresearch/torus-node/eventbus"
 )
 
@@ -17,13 +18,81 @@ type (
 	ShareCountResult struct {
 		Count int `json:"count"`
 	}
+	PeerScoresHandler struct {
+		eventBus eventbus.Bus
+	}
+	PeerScoresParams struct {
+	}
+	PeerScoresResult struct {
+		Peers []PeerScore `json:"peers"`
+	}
+	PeerHealthHandler struct {
+		eventBus eventbus.Bus
+	}
+	PeerHealthParams struct {
+	}
+	PeerHealthResult struct {
+		Peers []PeerHealth `json:"peers"`
+	}
+	EthEndpointsHandler struct {
+		eventBus eventbus.Bus
+	}
+	EthEndpointsParams struct {
+	}
+	EthEndpointsResult struct {
+		Endpoints []ethrpc.EndpointStatus `json:"endpoints"`
+	}
+	RegistrationStatusHandler struct {
+		eventBus eventbus.Bus
+	}
+	RegistrationStatusParams struct {
+	}
+	RegistrationStatusResult struct {
+		RegistrationStatus
+	}
 )
 
 // For testing purposes
 func (h ShareCountHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
-	shareCount := NewServiceLibrary(h.eventBus, "share_count_handler").DatabaseMethods().GetShareCount()
+	shareCount := NewServiceLibrary(h.eventBus, "share_count_handler").DatabaseMethods().GetShareCount(c)
 	var res = ShareCountResult{
 		Count: shareCount,
 	}
 	return res, nil
 }
+
+// PeerScoresHandler returns the reputation of the peers that misbehaved, lowest score first
+func (h PeerScoresHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	scores, err := NewServiceLibrary(h.eventBus, "peer_scores_handler").P2PMethods().PeerScores(c)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	return PeerScoresResult{Peers: scores}, nil
+}
+
+// PeerHealthHandler returns the results of pinging the nodes of the current and next epoch, offline peers first
+func (h PeerHealthHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	health, err := NewServiceLibrary(h.eventBus, "peer_health_handler").P2PMethods().PeerHealth(c)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	return PeerHealthResult{Peers: health}, nil
+}
+
+// EthEndpointsHandler returns the health of the ethereum endpoints in the order they are preferred in
+func (h EthEndpointsHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	endpoints, err := NewServiceLibrary(h.eventBus, "eth_endpoints_handler").EthereumMethods().EthEndpoints(c)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	return EthEndpointsResult{Endpoints: endpoints}, nil
+}
+
+// RegistrationStatusHandler returns the registration of the node in its current epoch and the state of its registration transaction
+func (h RegistrationStatusHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	status, err := NewServiceLibrary(h.eventBus, "registration_status_handler").EthereumMethods().GetRegistrationStatus(c)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	return RegistrationStatusResult{status}, nil
+}
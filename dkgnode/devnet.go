@@ -0,0 +1,634 @@
+package dkgnode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/registry"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+const (
+	// devnetFile keeps the keys and settings of a devnet, so that it can be started again
+	devnetFile = "devnet.json"
+	// devnetRegistryDir is the file registry the nodes of a devnet register in
+	devnetRegistryDir = "registry"
+	// devnetPortsPerNode ports are used by each node, from BasePort + 10 * its index
+	devnetPortsPerNode = 10
+	devnetStopTimeout  = 10 * time.Second
+	devnetPollInterval = 2 * time.Second
+)
+
+// DevnetOptions configures a local cluster
+type DevnetOptions struct {
+	// Dir holds the registry and the base path of every node. A devnet that was started in Dir
+	// before is started again with its keys and data, the other options are then ignored.
+	Dir   string
+	Nodes int
+	// K defaults to a majority of the nodes and T to (K-1)/2
+	K int
+	T int
+	// KeyBuffer is how many keys the cluster generates before it is ready
+	KeyBuffer int
+	// EncryptShares has nodes encrypt the shares they return with the key of the client
+	EncryptShares bool
+	// BasePort defaults to 7000, node i listens for HTTP on BasePort + 10 * i
+	BasePort int
+	// Binary is the dkgnode binary the nodes run, it defaults to the running executable
+	Binary   string
+	LogLevel string
+}
+
+// devnetState is what is kept in the devnet file
+type devnetState struct {
+	OwnerKey      string        `json:"ownerKey"`
+	KeyBuffer     int           `json:"keyBuffer"`
+	EncryptShares bool          `json:"encryptShares"`
+	BasePort      int           `json:"basePort"`
+	Epochs        []devnetEpoch `json:"epochs"`
+	Nodes         []devnetNode  `json:"nodes"`
+}
+
+type devnetEpoch struct {
+	ID int `json:"id"`
+	K  int `json:"k"`
+	T  int `json:"t"`
+}
+
+type devnetNode struct {
+	Epoch      int            `json:"epoch"`
+	Address    common.Address `json:"address"`
+	PrivateKey string         `json:"privateKey"`
+}
+
+// devnetPorts are the ports of the node with index i over all epochs, starting at 1
+type devnetPorts struct {
+	HTTP, TMP2P, BFT, ABCI, P2P, ManagementRPC int
+}
+
+func (s devnetState) ports(i int) devnetPorts {
+	base := s.BasePort + devnetPortsPerNode*i
+	return devnetPorts{HTTP: base, TMP2P: base + 1, BFT: base + 2, ABCI: base + 3, P2P: base + 4, ManagementRPC: base + 5}
+}
+
+// thresholds returns the K and T of an epoch of n nodes
+func thresholds(n, k, t int) (int, int, error) {
+	if k == 0 {
+		k = n/2 + 1
+	}
+	if t == 0 {
+		t = (k - 1) / 2
+	}
+	if k > n || k+t > n {
+		return 0, 0, fmt.Errorf("k %v and t %v do not fit %v nodes", k, t, n)
+	}
+	return k, t, nil
+}
+
+// addEpoch adds an epoch of n nodes with generated keys
+func (s *devnetState) addEpoch(n, k, t int) (devnetEpoch, error) {
+	if n < 1 {
+		return devnetEpoch{}, errors.New("an epoch needs at least one node")
+	}
+	k, t, err := thresholds(n, k, t)
+	if err != nil {
+		return devnetEpoch{}, err
+	}
+	epoch := devnetEpoch{ID: len(s.Epochs) + 1, K: k, T: t}
+	for i := 0; i < n; i++ {
+		key, err := ethCrypto.GenerateKey()
+		if err != nil {
+			return devnetEpoch{}, err
+		}
+		s.Nodes = append(s.Nodes, devnetNode{
+			Epoch:      epoch.ID,
+			Address:    ethCrypto.PubkeyToAddress(key.PublicKey),
+			PrivateKey: common.Bytes2Hex(ethCrypto.FromECDSA(key)),
+		})
+	}
+	s.Epochs = append(s.Epochs, epoch)
+	return epoch, nil
+}
+
+func (s devnetState) save(dir string) error {
+	file, err := bijson.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file holds the keys of the nodes
+	return ioutil.WriteFile(filepath.Join(dir, devnetFile), file, 0600)
+}
+
+func loadOrCreateDevnet(opts DevnetOptions) (devnetState, error) {
+	var s devnetState
+	path := filepath.Join(opts.Dir, devnetFile)
+	file, err := ioutil.ReadFile(path)
+	if err == nil {
+		if err := bijson.Unmarshal(file, &s); err != nil {
+			return s, fmt.Errorf("could not parse %v: %v", path, err)
+		}
+		logging.WithFields(logging.Fields{
+			"dir":    opts.Dir,
+			"nodes":  len(s.Nodes),
+			"epochs": len(s.Epochs),
+		}).Info("starting existing devnet")
+		return s, nil
+	}
+	if !os.IsNotExist(err) {
+		return s, err
+	}
+
+	s = devnetState{KeyBuffer: opts.KeyBuffer, EncryptShares: opts.EncryptShares, BasePort: opts.BasePort}
+	if s.BasePort == 0 {
+		s.BasePort = 7000
+	}
+	owner, err := ethCrypto.GenerateKey()
+	if err != nil {
+		return s, err
+	}
+	s.OwnerKey = common.Bytes2Hex(ethCrypto.FromECDSA(owner))
+	epoch, err := s.addEpoch(opts.Nodes, opts.K, opts.T)
+	if err != nil {
+		return s, err
+	}
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return s, err
+	}
+	if err := s.save(opts.Dir); err != nil {
+		return s, err
+	}
+	logging.WithFields(logging.Fields{
+		"dir":   opts.Dir,
+		"nodes": opts.Nodes,
+		"k":     epoch.K,
+		"t":     epoch.T,
+	}).Info("created devnet")
+	return s, nil
+}
+
+// position returns the index of node i in its epoch, starting at 1
+func (s devnetState) position(i int) int {
+	position := 0
+	for j := 1; j <= i; j++ {
+		if s.Nodes[j-1].Epoch == s.Nodes[i-1].Epoch {
+			position++
+		}
+	}
+	return position
+}
+
+// writeRegistry writes the epochs of the devnet, each epoch handing over to the next one
+func (s devnetState) writeRegistry(dir string) (common.Address, error) {
+	owner, err := signer.NewMemorySignerFromHex(s.OwnerKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var epochs registry.Epochs
+	for _, e := range s.Epochs {
+		epoch := registry.Epoch{ID: e.ID, K: e.K, T: e.T}
+		if e.ID > 1 {
+			epoch.PrevEpoch = e.ID - 1
+			epochs.PSSStatus = append(epochs.PSSStatus, registry.PSSStatus{OldEpoch: e.ID - 1, NewEpoch: e.ID, Status: 1})
+		}
+		if e.ID < len(s.Epochs) {
+			epoch.NextEpoch = e.ID + 1
+		}
+		for _, node := range s.Nodes {
+			if node.Epoch == e.ID {
+				epoch.Nodes = append(epoch.Nodes, node.Address)
+			}
+		}
+		epochs.Epochs = append(epochs.Epochs, epoch)
+	}
+	return owner.Address(), registry.WriteEpochs(dir, epochs, owner)
+}
+
+// nodeConfig is the config of node i, which runs with basePath
+func (s devnetState) nodeConfig(i int, basePath string, registryDir string, owner common.Address, logLevel string) config.Config {
+	ports := s.ports(i)
+	return config.Config{
+		HttpServerPort:          strconv.Itoa(ports.HTTP),
+		ManagementRPCPort:       strconv.Itoa(ports.ManagementRPC),
+		UseManagementRPC:        true,
+		EthPrivateKey:           s.Nodes[i-1].PrivateKey,
+		BftURI:                  fmt.Sprintf("tcp://127.0.0.1:%d", ports.BFT),
+		ABCIServer:              fmt.Sprintf("tcp://127.0.0.1:%d", ports.ABCI),
+		TMP2PListenAddress:      fmt.Sprintf("tcp://0.0.0.0:%d", ports.TMP2P),
+		P2PListenAddress:        fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", ports.P2P),
+		KeyBuffer:               s.KeyBuffer,
+		BasePath:                basePath,
+		InitEpoch:               s.Nodes[i-1].Epoch,
+		ShouldRegister:          true,
+		IsDebug:                 true,
+		ProvidedIPAddress:       "127.0.0.1",
+		LogLevel:                logLevel,
+		TMMessageQueueProcesses: 4,
+		MaxBftConnections:       100,
+		OSFreeMemoryMS:          60000,
+		EthPollFreq:             1,
+		NodeRegistry:            registry.TypeFile,
+		NodeRegistryPath:        registryDir,
+		NodeRegistryOwner:       owner.Hex(),
+		EncryptShares:           s.EncryptShares,
+	}
+}
+
+// devnetEnv is the environment of the nodes, without the variables the config is read from so
+// that every node is configured by its config file only
+func devnetEnv() []string {
+	configured := make(map[string]bool)
+	t := reflect.TypeOf(config.Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("env"); name != "" {
+			configured[name] = true
+		}
+	}
+	var env []string
+	for _, v := range os.Environ() {
+		if !configured[strings.SplitN(v, "=", 2)[0]] {
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+// devnetProcess is a running node of a devnet
+type devnetProcess struct {
+	index   int
+	ports   devnetPorts
+	cmd     *exec.Cmd
+	logPath string
+	done    chan struct{}
+	err     error
+}
+
+// exited returns whether the node exited, and the error it exited with
+func (p *devnetProcess) exited() (bool, error) {
+	select {
+	case <-p.done:
+		return true, p.err
+	default:
+		return false, nil
+	}
+}
+
+// stop asks the node to shut down, and kills it if it does not within devnetStopTimeout
+func (p *devnetProcess) stop() {
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return
+	}
+	select {
+	case <-p.done:
+	case <-time.After(devnetStopTimeout):
+		logging.WithField("node", p.index).Warn("devnet node did not stop, killing it")
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// devnetNodeStatus is how far a node of a devnet is from serving ShareRequests
+type devnetNodeStatus struct {
+	Registered bool
+	BFT        bool
+	Shares     int
+}
+
+func (p *devnetProcess) status(client *http.Client) devnetNodeStatus {
+	var status devnetNodeStatus
+	base := fmt.Sprintf("http://127.0.0.1:%d", p.ports.HTTP)
+	if resp, err := client.Get(base + "/registrationStatus"); err == nil {
+		resp.Body.Close()
+		status.Registered = resp.StatusCode == 200
+	}
+	if resp, err := client.Get(base + "/bftStatus"); err == nil {
+		resp.Body.Close()
+		status.BFT = resp.StatusCode == 200
+	}
+	body, _ := bijson.Marshal(struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      int              `json:"id"`
+		Method  string           `json:"method"`
+		Params  ShareCountParams `json:"params"`
+	}{"2.0", 1, ShareCountMethod, ShareCountParams{}})
+	resp, err := client.Post(base+"/debug", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return status
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Result ShareCountResult `json:"result"`
+	}
+	if respBody, err := ioutil.ReadAll(resp.Body); err == nil && bijson.Unmarshal(respBody, &res) == nil {
+		status.Shares = res.Result.Count
+	}
+	return status
+}
+
+// DevnetNode is a node of a devnet
+type DevnetNode struct {
+	Epoch int
+	// Index is the index of the node in its epoch, which its shares are evaluated at
+	Index   int
+	Address common.Address
+	// URL is the base URL of the node, its JRPC endpoint is URL + "/jrpc"
+	URL string
+}
+
+// Devnet is a local cluster of nodes. Every node runs in its own process with embedded
+// tendermint, on loopback ports, and registers in a file registry in place of the NodeList
+// contract.
+type Devnet struct {
+	dir         string
+	binary      string
+	logLevel    string
+	registryDir string
+	owner       common.Address
+	client      *http.Client
+
+	lock      sync.Mutex
+	state     devnetState
+	processes []*devnetProcess
+}
+
+// StartDevnet starts the nodes of the devnet in opts.Dir, creating it first if needed
+func StartDevnet(opts DevnetOptions) (*Devnet, error) {
+	if opts.Binary == "" {
+		binary, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		opts.Binary = binary
+	}
+	state, err := loadOrCreateDevnet(opts)
+	if err != nil {
+		return nil, err
+	}
+	registryDir, err := filepath.Abs(filepath.Join(opts.Dir, devnetRegistryDir))
+	if err != nil {
+		return nil, err
+	}
+	owner, err := state.writeRegistry(registryDir)
+	if err != nil {
+		return nil, err
+	}
+	d := &Devnet{
+		dir:         opts.Dir,
+		binary:      opts.Binary,
+		logLevel:    opts.LogLevel,
+		registryDir: registryDir,
+		owner:       owner,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		state:       state,
+	}
+	for i := 1; i <= len(state.Nodes); i++ {
+		if err := d.startNode(i); err != nil {
+			d.Stop()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// startNode starts node i, the lock must be held or the devnet not shared yet
+func (d *Devnet) startNode(i int) error {
+	basePath, err := filepath.Abs(filepath.Join(d.dir, "node-"+strconv.Itoa(i)))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(basePath, 0700); err != nil {
+		return err
+	}
+	conf, err := bijson.MarshalIndent(d.state.nodeConfig(i, basePath, d.registryDir, d.owner, d.logLevel), "", "  ")
+	if err != nil {
+		return err
+	}
+	configPath := filepath.Join(basePath, "config.json")
+	if err := ioutil.WriteFile(configPath, conf, 0600); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(filepath.Join(basePath, "node.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(d.binary, "-configPath", configPath)
+	cmd.Env = devnetEnv()
+	cmd.Stdout = log
+	cmd.Stderr = log
+	if err := cmd.Start(); err != nil {
+		log.Close()
+		return err
+	}
+	p := &devnetProcess{index: i, ports: d.state.ports(i), cmd: cmd, logPath: log.Name(), done: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		log.Close()
+		close(p.done)
+	}()
+	d.processes = append(d.processes, p)
+	logging.WithFields(logging.Fields{
+		"node":    i,
+		"epoch":   d.state.Nodes[i-1].Epoch,
+		"address": d.state.Nodes[i-1].Address.Hex(),
+		"pid":     cmd.Process.Pid,
+		"log":     p.logPath,
+	}).Info("started devnet node")
+	return nil
+}
+
+// CurrentEpoch returns the latest epoch of the devnet
+func (d *Devnet) CurrentEpoch() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.state.Epochs)
+}
+
+// K returns the threshold of epoch
+func (d *Devnet) K(epoch int) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if epoch < 1 || epoch > len(d.state.Epochs) {
+		return 0
+	}
+	return d.state.Epochs[epoch-1].K
+}
+
+// Nodes returns the nodes of epoch
+func (d *Devnet) Nodes(epoch int) []DevnetNode {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var nodes []DevnetNode
+	for i, node := range d.state.Nodes {
+		if node.Epoch != epoch {
+			continue
+		}
+		nodes = append(nodes, DevnetNode{
+			Epoch:   epoch,
+			Index:   d.state.position(i + 1),
+			Address: node.Address,
+			URL:     fmt.Sprintf("http://127.0.0.1:%d", d.state.ports(i+1).HTTP),
+		})
+	}
+	return nodes
+}
+
+// NextEpoch whitelists n new nodes in a new epoch, starts them and has the current epoch hand
+// its keys over to them. K and T of 0 take their defaults.
+func (d *Devnet) NextEpoch(n, k, t int) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	first := len(d.state.Nodes) + 1
+	epoch, err := d.state.addEpoch(n, k, t)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.state.save(d.dir); err != nil {
+		return 0, err
+	}
+	if _, err := d.state.writeRegistry(d.registryDir); err != nil {
+		return 0, err
+	}
+	logging.WithFields(logging.Fields{
+		"epoch": epoch.ID,
+		"nodes": n,
+		"k":     epoch.K,
+		"t":     epoch.T,
+	}).Info("added devnet epoch")
+	for i := first; i <= len(d.state.Nodes); i++ {
+		if err := d.startNode(i); err != nil {
+			return 0, err
+		}
+	}
+	return epoch.ID, nil
+}
+
+// AwaitReady waits until every node of epoch is registered, connected to tendermint and holds
+// shares of KeyBuffer keys. It fails if a node of the devnet exits.
+func (d *Devnet) AwaitReady(ctx context.Context, epoch int) error {
+	ticker := time.NewTicker(devnetPollInterval)
+	defer ticker.Stop()
+	statuses := make(map[int]devnetNodeStatus)
+	for {
+		if err := d.checkExited(); err != nil {
+			return err
+		}
+		d.lock.Lock()
+		var processes []*devnetProcess
+		for _, p := range d.processes {
+			if d.state.Nodes[p.index-1].Epoch == epoch {
+				processes = append(processes, p)
+			}
+		}
+		keyBuffer := d.state.KeyBuffer
+		d.lock.Unlock()
+
+		ready := len(processes) > 0
+		for _, p := range processes {
+			status := p.status(d.client)
+			if status != statuses[p.index] {
+				logging.WithFields(logging.Fields{
+					"node":       p.index,
+					"epoch":      epoch,
+					"registered": status.Registered,
+					"bft":        status.BFT,
+					"shares":     status.Shares,
+					"keyBuffer":  keyBuffer,
+				}).Info("devnet node status")
+			}
+			statuses[p.index] = status
+			ready = ready && status.Registered && status.BFT && status.Shares >= keyBuffer
+		}
+		if ready {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkExited returns an error if a node of the devnet exited
+func (d *Devnet) checkExited() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, p := range d.processes {
+		if exited, err := p.exited(); exited {
+			return fmt.Errorf("devnet node %v exited: %v, see %v", p.index, err, p.logPath)
+		}
+	}
+	return nil
+}
+
+// Stop stops every node of the devnet, its keys and data are kept
+func (d *Devnet) Stop() {
+	d.lock.Lock()
+	processes := d.processes
+	d.processes = nil
+	d.lock.Unlock()
+	var wg sync.WaitGroup
+	for _, p := range processes {
+		wg.Add(1)
+		go func(p *devnetProcess) {
+			defer wg.Done()
+			p.stop()
+		}(p)
+	}
+	wg.Wait()
+}
+
+// RunDevnet runs a devnet until ctx is done or one of its nodes exits. The devnet is reported
+// ready once the nodes of its current epoch are.
+func RunDevnet(ctx context.Context, opts DevnetOptions) error {
+	d, err := StartDevnet(opts)
+	if err != nil {
+		return err
+	}
+	defer d.Stop()
+	epoch := d.CurrentEpoch()
+	if err := d.AwaitReady(ctx, epoch); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	for _, node := range d.Nodes(epoch) {
+		logging.WithFields(logging.Fields{
+			"index":   node.Index,
+			"address": node.Address.Hex(),
+			"jrpc":    node.URL + "/jrpc",
+		}).Info("devnet node ready")
+	}
+	logging.WithField("epoch", epoch).Info("devnet is ready for share requests")
+
+	ticker := time.NewTicker(devnetPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.checkExited(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package dkgnode
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/torusresearch/torus-node/registry"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+func TestThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		n, k, t int
+		wantK   int
+		wantT   int
+		wantErr bool
+	}{
+		{name: "single node", n: 1, wantK: 1, wantT: 0},
+		{name: "k defaults to a majority", n: 5, wantK: 3, wantT: 1},
+		{name: "even number of nodes", n: 4, wantK: 3, wantT: 1},
+		{name: "t defaults from the given k", n: 10, k: 7, wantK: 7, wantT: 3},
+		{name: "given k and t", n: 9, k: 5, t: 4, wantK: 5, wantT: 4},
+		{name: "k larger than the nodes", n: 3, k: 4, wantErr: true},
+		{name: "k and t larger than the nodes", n: 5, k: 3, t: 3, wantErr: true},
+		{name: "no nodes", n: 0, wantErr: true},
+	}
+	for _, test := range tests {
+		k, tt, err := thresholds(test.n, test.k, test.t)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%v: expected an error, got k %v and t %v", test.name, k, tt)
+			}
+			continue
+		}
+		if err != nil || k != test.wantK || tt != test.wantT {
+			t.Errorf("%v: expected k %v and t %v, got %v, %v, %v", test.name, test.wantK, test.wantT, k, tt, err)
+		}
+	}
+}
+
+func TestDevnetEpochs(t *testing.T) {
+	var s devnetState
+	if _, err := s.addEpoch(0, 0, 0); err == nil {
+		t.Fatal("expected an epoch without nodes to be refused")
+	}
+	if _, err := s.addEpoch(2, 3, 0); err == nil {
+		t.Fatal("expected thresholds that do not fit the nodes to be refused")
+	}
+	if len(s.Epochs) != 0 || len(s.Nodes) != 0 {
+		t.Fatal("expected refused epochs not to be added")
+	}
+	for _, n := range []int{3, 2, 4} {
+		if _, err := s.addEpoch(n, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		node     int
+		epoch    int
+		position int
+	}{
+		{node: 1, epoch: 1, position: 1},
+		{node: 3, epoch: 1, position: 3},
+		{node: 4, epoch: 2, position: 1},
+		{node: 5, epoch: 2, position: 2},
+		{node: 6, epoch: 3, position: 1},
+		{node: 9, epoch: 3, position: 4},
+	}
+	for _, test := range tests {
+		if epoch := s.Nodes[test.node-1].Epoch; epoch != test.epoch {
+			t.Errorf("node %v: expected epoch %v, got %v", test.node, test.epoch, epoch)
+		}
+		if position := s.position(test.node); position != test.position {
+			t.Errorf("node %v: expected position %v, got %v", test.node, test.position, position)
+		}
+	}
+	for i, e := range s.Epochs {
+		if e.ID != i+1 {
+			t.Errorf("expected epoch %v to have ID %v, got %v", i, i+1, e.ID)
+		}
+	}
+}
+
+func TestDevnetRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := loadOrCreateDevnet(DevnetOptions{Dir: dir, Nodes: 3, KeyBuffer: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.addEpoch(4, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	owner, err := s.writeRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		epoch      int
+		n, k, t    int
+		prev, next int
+		pssStatus  int
+	}{
+		{epoch: 1, n: 3, k: 2, t: 0, prev: 0, next: 2},
+		{epoch: 2, n: 4, k: 3, t: 1, prev: 1, next: 0, pssStatus: 1},
+	}
+	for i, node := range s.Nodes {
+		nodeSigner, err := signer.NewMemorySignerFromHex(node.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := registry.NewFileRegistry(dir, owner, nodeSigner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range tests {
+			info, err := r.EpochInfo(ctx, test.epoch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.N != test.n || info.K != test.k || info.T != test.t || info.PrevEpoch != test.prev || info.NextEpoch != test.next {
+				t.Errorf("epoch %v: expected %+v, got %+v", test.epoch, test, info)
+			}
+			status, err := r.PSSStatus(ctx, test.epoch-1, test.epoch)
+			if err != nil || status != test.pssStatus {
+				t.Errorf("epoch %v: expected pss status %v, got %v, %v", test.epoch, test.pssStatus, status, err)
+			}
+			whitelisted, err := r.Whitelisted(ctx, test.epoch, node.Address)
+			if err != nil || whitelisted != (node.Epoch == test.epoch) {
+				t.Errorf("node %v: expected to be whitelisted in its epoch %v only, got %v in epoch %v", i+1, node.Epoch, whitelisted, test.epoch)
+			}
+		}
+
+		conf := s.nodeConfig(i+1, dir, dir, owner, "info")
+		ports := s.ports(i + 1)
+		if conf.HttpServerPort != strconv.Itoa(7000+devnetPortsPerNode*(i+1)) || conf.ManagementRPCPort != strconv.Itoa(ports.ManagementRPC) {
+			t.Errorf("node %v: expected the ports of its index, got %v and %v", i+1, conf.HttpServerPort, conf.ManagementRPCPort)
+		}
+		if conf.EthPrivateKey != node.PrivateKey || conf.InitEpoch != node.Epoch || conf.KeyBuffer != 5 {
+			t.Errorf("node %v: expected its key, epoch and key buffer, got %+v", i+1, conf)
+		}
+		if conf.NodeRegistry != registry.TypeFile || conf.NodeRegistryPath != dir || conf.NodeRegistryOwner != owner.Hex() {
+			t.Errorf("node %v: expected the devnet registry, got %v %v %v", i+1, conf.NodeRegistry, conf.NodeRegistryPath, conf.NodeRegistryOwner)
+		}
+	}
+}
@@ -14,7 +14,8 @@ import (
 	logging "github.com/sirupsen/logrus"
 	"github.com/stackimpact/stackimpact-go"
 	"github.com/torusresearch/torus-node/config"
-	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/signer"
 	"github.com/torusresearch/torus-node/tcontext"
 	"github.com/torusresearch/torus-node/telemetry"
 	"github.com/torusresearch/torus-node/version"
@@ -25,20 +26,30 @@ const defaultConfigPath = "/.torus/config.json"
 // New initializes the Torus node, spawns and sets up appropriate services
 
 func New() {
-	systemEventBus := eventbus.New()
+	// shares and keys must never reach the logs
+	secret.RedactLogs(logging.StandardLogger())
 	ctx, cancel := context.WithCancel(context.Background())
 	systemContext := context.WithValue(ctx, tcontext.ContextID, 1)
 	logging.RegisterExitHandler(func() {
 		debug.PrintStack()
 	})
-	logging.WithFields(logging.Fields{
-		"systemEventBus": systemEventBus,
-		"systemContext":  systemContext,
-	}).Info("initialized system event bus and system context")
 
 	// Load configs
 	config.GlobalConfig = config.LoadConfig(defaultConfigPath)
 	config.GlobalMutableConfig = config.InitMutableConfig(config.GlobalConfig)
+
+	stopTracing, err := setupTracing()
+	if err != nil {
+		logging.WithError(err).Fatal("could not set up tracing")
+	}
+	defer stopTracing()
+
+	// the event bus is bounded by the config
+	systemEventBus := newSystemEventBus()
+	logging.WithFields(logging.Fields{
+		"systemEventBus": systemEventBus,
+		"systemContext":  systemContext,
+	}).Info("initialized system event bus and system context")
 	logging.WithFields(logging.Fields{
 		"BftURI":             config.GlobalConfig.BftURI,
 		"MainServerAddress":  config.GlobalConfig.MainServerAddress,
@@ -65,12 +76,22 @@ func New() {
 		}()
 	}
 
+	// Load node key, shared by the ethereum, tendermint and p2p services
+	nodeSigner, err := signer.New(config.GlobalConfig)
+	if err != nil {
+		logging.WithError(err).Fatal("could not load node signer")
+	}
+	logging.WithFields(logging.Fields{
+		"signer":      nodeSigner.Type(),
+		"nodeAddress": nodeSigner.Address().Hex(),
+	}).Info("loaded node signer")
+
 	// Start services
 	telemetryService := NewTelemetryService(systemContext, systemEventBus)
-	ethereumService := NewEthereumService(systemContext, systemEventBus)
+	ethereumService := NewEthereumService(systemContext, systemEventBus, nodeSigner)
 	abciService := NewABCIService(systemContext, systemEventBus)
-	tendermintService := NewTendermintService(systemContext, systemEventBus)
-	p2pService := NewP2PService(systemContext, systemEventBus)
+	tendermintService := NewTendermintService(systemContext, systemEventBus, nodeSigner)
+	p2pService := NewP2PService(systemContext, systemEventBus, nodeSigner)
 	serverService := NewServerService(systemContext, systemEventBus)
 	keygennofsmService := NewKeygennofsmService(systemContext, systemEventBus)
 	pssService := NewPSSService(systemContext, systemEventBus)
@@ -97,6 +118,13 @@ func New() {
 	setupRequestLoggingMiddleware(serviceRegistry)
 	// setupDiskQueueMiddleware(serviceRegistry)
 	setupResponseLoggingMiddleware(serviceRegistry)
+	if config.GlobalConfig.RecordEventsPath != "" {
+		stopRecording, err := setupRecordingMiddleware(serviceRegistry, config.GlobalConfig.RecordEventsPath, config.GlobalConfig.RecordSecrets)
+		if err != nil {
+			logging.WithError(err).Fatal("could not record events")
+		}
+		defer stopRecording()
+	}
 
 	for baseServiceName, baseService := range serviceRegistry.Services {
 		go func(name string, service *BaseService) {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -15,11 +16,7 @@ import (
 	"github.com/torusresearch/torus-node/telemetry"
 
 	"github.com/avast/retry-go"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethCommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	ethCrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 	logging "github.com/sirupsen/logrus"
@@ -30,11 +27,15 @@ import (
 	"github.com/torusresearch/torus-common/secp256k1"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/ethrpc"
+	"github.com/torusresearch/torus-node/ethwatch"
 	"github.com/torusresearch/torus-node/eventbus"
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/mapping"
 	"github.com/torusresearch/torus-node/pss"
-	nodelist "github.com/torusresearch/torus-node/solidity/goContracts"
+	"github.com/torusresearch/torus-node/registry"
+	"github.com/torusresearch/torus-node/signer"
+	"github.com/torusresearch/torus-node/txmanager"
 )
 
 type epochInfo struct {
@@ -53,6 +54,18 @@ type EthNodeDetails struct {
 	P2pListenAddress   string
 }
 
+// txManagerDir is where the transactions of the node are persisted, under BasePath
+const txManagerDir = "txmanager"
+
+// RegistrationStatus is the registration of the node in its current epoch. Transaction is the
+// latest registration transaction, if the node registers with the NodeList contract and sent it.
+type RegistrationStatus struct {
+	Epoch       int              `json:"epoch"`
+	Whitelisted bool             `json:"whitelisted"`
+	Registered  bool             `json:"registered"`
+	Transaction *txmanager.State `json:"transaction,omitempty"`
+}
+
 type EthEpochParams struct {
 	EpochID int
 	N       int
@@ -130,21 +143,21 @@ func (nr *NodeRegister) GetNodeByIndex(index int) *NodeReference {
 }
 
 func (e *EthereumService) GetNodeRef(nodeAddress ethCommon.Address) (n *NodeReference, err error) {
-	details, err := e.nodeList.NodeDetails(nil, nodeAddress)
+	details, err := e.nodeRegistry.NodeDetails(e.context, nodeAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	var connectionDetails ConnectionDetails
-	if details.DeclaredIp != "" && details.P2pListenAddress == "" || details.TmP2PListenAddress == "" {
+	if details.DeclaredIP != "" && details.P2PListenAddress == "" || details.TMP2PListenAddress == "" {
 		err = retry.Do(func() error {
 			var retryErr error
-			connectionDetails, retryErr = e.serviceLibrary.ServerMethods().RequestConnectionDetails(details.DeclaredIp)
+			connectionDetails, retryErr = e.serviceLibrary.ServerMethods().RequestConnectionDetails(e.context, details.DeclaredIP)
 			logging.WithField("connectionDetails", connectionDetails).Debug("got back connection details from node")
 			if retryErr != nil {
 				return fmt.Errorf("could not get hidden connection details %v", retryErr)
 			}
-			retryErr = e.serviceLibrary.DatabaseMethods().StoreConnectionDetails(nodeAddress, connectionDetails)
+			retryErr = e.serviceLibrary.DatabaseMethods().StoreConnectionDetails(e.context, nodeAddress, connectionDetails)
 			if retryErr != nil {
 				return fmt.Errorf("could not store connection details %v", retryErr)
 			}
@@ -152,7 +165,7 @@ func (e *EthereumService) GetNodeRef(nodeAddress ethCommon.Address) (n *NodeRefe
 		})
 		if err != nil {
 			logging.WithField("nodeAddress", nodeAddress).WithError(err).Error("could not get connection details from node, get from DB")
-			connectionDetails, err = e.serviceLibrary.DatabaseMethods().RetrieveConnectionDetails(nodeAddress)
+			connectionDetails, err = e.serviceLibrary.DatabaseMethods().RetrieveConnectionDetails(e.context, nodeAddress)
 			if err != nil {
 				logging.WithField("nodeAddress", nodeAddress).Error("could not get connection details from DB either")
 				return nil, fmt.Errorf("unable to get connection details for nodeAddress %v", nodeAddress)
@@ -160,8 +173,8 @@ func (e *EthereumService) GetNodeRef(nodeAddress ethCommon.Address) (n *NodeRefe
 		}
 	} else {
 		connectionDetails = ConnectionDetails{
-			TMP2PConnection: details.TmP2PListenAddress,
-			P2PConnection:   details.P2pListenAddress,
+			TMP2PConnection: details.TMP2PListenAddress,
+			P2PConnection:   details.P2PListenAddress,
 		}
 	}
 
@@ -185,20 +198,21 @@ func (e *EthereumService) GetNodeRef(nodeAddress ethCommon.Address) (n *NodeRefe
 	return &NodeReference{
 		Address:         &nodeAddress,
 		PeerID:          peerid,
-		Index:           details.Position,
+		Index:           big.NewInt(int64(details.Position)),
 		PublicKey:       &ecdsa.PublicKey{Curve: e.ethCurve, X: details.PubKx, Y: details.PubKy},
 		TMP2PConnection: connectionDetails.TMP2PConnection,
 		P2PConnection:   connectionDetails.P2PConnection,
 	}, nil
 }
 
-func NewEthereumService(ctx context.Context, eventBus eventbus.Bus) *BaseService {
+func NewEthereumService(ctx context.Context, eventBus eventbus.Bus, nodeSigner signer.Signer) *BaseService {
 	ethereumCtx, cancel := context.WithCancel(context.WithValue(ctx, ContextID, "ethereum"))
 	ethereumService := EthereumService{
 		cancel:        cancel,
 		parentContext: ctx,
 		context:       ethereumCtx,
 		eventBus:      eventBus,
+		signer:        nodeSigner,
 	}
 	ethereumService.serviceLibrary = NewServiceLibrary(ethereumService.eventBus, ethereumService.Name())
 	return NewBaseService(&ethereumService)
@@ -213,15 +227,17 @@ type EthereumService struct {
 	serviceLibrary ServiceLibrary
 
 	nodePubK         *ecdsa.PublicKey
-	nodePrivK        *ecdsa.PrivateKey
+	signer           signer.Signer
 	nodeAddr         *ethCommon.Address
 	tmp2pConnection  string
 	p2pConnection    string
-	ethClient        *ethclient.Client
+	ethClient        *ethrpc.Client     // nil unless the node registry is the NodeList contract
+	txManager        *txmanager.Manager // nil unless the node registry is the NodeList contract
 	ethCurve         elliptic.Curve
 	nodeRegisterMap  map[int]*NodeRegister // only filled when nodes have all registered
 	cachedEpochInfo  *cachedEpochInfoSyncMap
-	nodeList         *nodelist.NodeList
+	nodeRegistry     registry.NodeRegistry
+	watcher          *ethwatch.Watcher
 	nodeIndex        int
 	currentEpoch     int
 	connectionStatus ConnectionStatus
@@ -259,44 +275,33 @@ func (e *EthereumService) Name() string {
 }
 
 func (e *EthereumService) OnStart() error {
-	client, err := ethclient.Dial(config.GlobalConfig.EthConnection)
-	if err != nil {
-		return err
-	}
-	e.connectionStatus = EthClientConnected
-	privateKeyECDSA, err := ethCrypto.HexToECDSA(string(config.GlobalConfig.EthPrivateKey))
-	if err != nil {
-		return err
-	}
-	nodePublicKey := privateKeyECDSA.Public()
-	nodePublicKeyEC, ok := nodePublicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return errors.New("error casting to Public Key")
-	}
-	nodeAddress := ethCrypto.PubkeyToAddress(*nodePublicKeyEC)
-	nodeListAddress := ethCommon.HexToAddress(config.GlobalConfig.NodeListAddress)
-
-	logging.WithFields(logging.Fields{
-		"ETHConnection":  config.GlobalConfig.EthConnection,
-		"nodePrivateKey": config.GlobalConfig.EthPrivateKey,
-		"nodePublicKey":  nodeAddress.Hex(),
-	}).Info("successful connection")
-
-	NodeListContract, err := nodelist.NewNodeList(nodeListAddress, client)
+	nodePublicKeyEC := e.signer.PublicKey()
+	nodeAddress := e.signer.Address()
+	nodeRegistry, chain, err := e.newNodeRegistry()
 	if err != nil {
 		return err
 	}
 	e.nodePubK = nodePublicKeyEC
-	e.nodePrivK = privateKeyECDSA
 	e.nodeAddr = &nodeAddress
-	e.ethClient = client
 	e.ethCurve = secp256k1.Curve
 	e.nodeRegisterMap = make(map[int]*NodeRegister)
 	e.cachedEpochInfo = &cachedEpochInfoSyncMap{}
-	e.nodeList = NodeListContract
+	e.nodeRegistry = nodeRegistry
 	e.nodeIndex = 0 // will be set after it has been registered
 	e.currentEpoch = config.GlobalConfig.InitEpoch
 
+	// Follow the node list, the monitors below wait for its changes
+	e.watcher = ethwatch.NewWatcher(chain, nodeRegistry, ethwatch.Config{
+		Confirmations: uint64(config.GlobalConfig.EthConfirmations),
+		PollInterval:  ethPollInterval(),
+	})
+	e.watcher.WatchEpoch(e.currentEpoch)
+	go func() {
+		if err := e.watcher.Run(e.context); err != nil && e.context.Err() == nil {
+			logging.WithError(err).Error("stopped following the node list")
+		}
+	}()
+
 	// Check if node is whitelisted
 	go whitelistMonitor(e)
 
@@ -318,10 +323,59 @@ func (e *EthereumService) OnStart() error {
 	return nil
 }
 
+// newNodeRegistry creates the node registry selected in the config, and the chain its changes are followed on
+func (e *EthereumService) newNodeRegistry() (registry.NodeRegistry, ethwatch.Chain, error) {
+	switch config.GlobalConfig.NodeRegistry {
+	case "", registry.TypeContract:
+		client, err := ethrpc.Dial(ethrpc.SplitURLs(config.GlobalConfig.EthConnection), ethrpc.Config{
+			Quorum:              config.GlobalConfig.EthQuorum,
+			HealthCheckInterval: time.Duration(config.GlobalConfig.EthHealthCheckIntervalS) * time.Second,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		go client.Run(e.context)
+		e.ethClient = client
+		e.connectionStatus = EthClientConnected
+		logging.WithFields(logging.Fields{
+			"ETHEndpoints":  client.Endpoints(),
+			"signer":        e.signer.Type(),
+			"nodePublicKey": e.signer.Address().Hex(),
+		}).Info("successful connection")
+		store, err := txmanager.NewFileStore(filepath.Join(config.GlobalConfig.BasePath, txManagerDir))
+		if err != nil {
+			return nil, nil, err
+		}
+		txCfg := txmanager.Config{
+			Confirmations: uint64(config.GlobalConfig.EthConfirmations),
+			BumpAfter:     time.Duration(config.GlobalConfig.EthTxBumpAfterS) * time.Second,
+		}
+		if config.GlobalConfig.EthMaxGasPriceGwei > 0 {
+			txCfg.MaxGasPrice = new(big.Int).Mul(big.NewInt(int64(config.GlobalConfig.EthMaxGasPriceGwei)), big.NewInt(1e9)) // gwei
+		}
+		e.txManager = txmanager.NewManager(client, e.signer, store, txCfg)
+		nodeRegistry, err := registry.NewContractRegistry(ethCommon.HexToAddress(config.GlobalConfig.NodeListAddress), client, e.txManager)
+		return nodeRegistry, client, err
+	case registry.TypeFile:
+		nodeRegistry, err := registry.NewFileRegistry(config.GlobalConfig.NodeRegistryPath, ethCommon.HexToAddress(config.GlobalConfig.NodeRegistryOwner), e.signer)
+		if err != nil {
+			return nil, nil, err
+		}
+		logging.WithFields(logging.Fields{
+			"path":          config.GlobalConfig.NodeRegistryPath,
+			"signer":        e.signer.Type(),
+			"nodePublicKey": e.signer.Address().Hex(),
+		}).Info("using file node registry")
+		return nodeRegistry, nodeRegistry, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown node registry %v", config.GlobalConfig.NodeRegistry)
+	}
+}
+
 func (e *EthereumService) startPSSMonitor() {
 	// Start PSS monitors
-	go incomingPSSMonitor(e.eventBus)
-	go outgoingPSSMonitor(e.eventBus)
+	go incomingPSSMonitor(e.eventBus, e.watcher)
+	go outgoingPSSMonitor(e.eventBus, e.watcher)
 
 	// Start prev nodes monitor
 	go previousNodesMonitor(e)
@@ -331,316 +385,291 @@ func (e *EthereumService) startPSSMonitor() {
 }
 
 func (e *EthereumService) OnStop() error {
-	return nil
+	return e.signer.Close()
 }
 
-func (e *EthereumService) Call(method string, args ...interface{}) (interface{}, error) {
+func (e *EthereumService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-	switch method {
-	// GetCurrentEpoch() (epoch int)
-	case "get_current_epoch":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetCurrentEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	return dispatchEthereum(ctx, e, method, args)
+}
 
-		e.Lock()
-		defer e.Unlock()
-		return e.currentEpoch, nil
-	// GetPreviousEpoch() (epoch int)
-	case "get_previous_epoch":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetPreviousEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+func (e *EthereumService) handleGetCurrentEpoch(ctx context.Context) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetCurrentEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		epochInfo, err := e.GetEpochInfo(e.currentEpoch, false)
-		if err != nil {
-			return nil, err
-		}
-		prevEpoch := int(epochInfo.PrevEpoch.Int64())
-		return prevEpoch, nil
-	// GetNextEpoch() (epoch int)
-	case "get_next_epoch":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNextEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	e.Lock()
+	defer e.Unlock()
+	return e.currentEpoch, nil
+}
 
-		epochInfo, err := e.GetEpochInfo(e.currentEpoch, false)
-		if err != nil {
-			return nil, err
-		}
-		nextEpoch := int(epochInfo.NextEpoch.Int64())
-		return nextEpoch, nil
-	// GetEpochInfo(epoch int) (epochInfo epochInfo, err error)
-	case "get_epoch_info":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetEpochInfoCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
-
-		var args0 int
-		var args1 bool
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		eInfo, err := e.GetEpochInfo(args0, args1)
-		return eInfo, err
-	// GetSelfIndex() (index int)
-	case "get_self_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+func (e *EthereumService) handleGetPreviousEpoch(ctx context.Context) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetPreviousEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		e.Lock()
-		defer e.Unlock()
-		for {
-			if e.nodeIndex != 0 {
-				return e.nodeIndex, nil
-			}
-			e.Unlock()
-			time.Sleep(1 * time.Second)
-			e.Lock()
-		}
-	// GetSelfPrivateKey() (privKey ecdsa.PrivateKey)
-	case "get_self_private_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfPrivateKeyCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	epochInfo, err := e.GetEpochInfo(e.currentEpoch, false)
+	if err != nil {
+		return 0, err
+	}
+	return int(epochInfo.PrevEpoch.Int64()), nil
+}
 
-		e.Lock()
-		defer e.Unlock()
-		if e.nodePrivK == nil {
-			return nil, errors.New("Ethereum private key has not been initialized")
-		}
-		return *e.nodePrivK.D, nil
-	// GetSelfPublicKey() (pubKey ecdsa.PublicKey)
-	case "get_self_public_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfPublicKeyCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+func (e *EthereumService) handleGetNextEpoch(ctx context.Context) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNextEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		e.Lock()
-		defer e.Unlock()
-		if e.nodePubK == nil {
-			return nil, errors.New("Ethereum public key has not been initialized")
-		}
-		return common.Point{
-			X: *e.nodePubK.X,
-			Y: *e.nodePubK.Y,
-		}, nil
-	// GetSelfAddress() (address ethCommon.Address)
-	case "get_self_address":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfAddressCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	epochInfo, err := e.GetEpochInfo(e.currentEpoch, false)
+	if err != nil {
+		return 0, err
+	}
+	return int(epochInfo.NextEpoch.Int64()), nil
+}
 
-		e.Lock()
-		defer e.Unlock()
-		if e.nodeAddr == nil {
-			return nil, errors.New("Ethereum node address has not been initialized")
-		}
-		return *e.nodeAddr, nil
-	// SetSelfIndex(int)
-	case "set_self_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+func (e *EthereumService) handleGetEpochInfo(ctx context.Context, epoch int, skipCache bool) (epochInfo, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetEpochInfoCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		e.Lock()
-		defer e.Unlock()
-		var args0 int
-		fmt.Println("setselfindex args", args)
-		_ = castOrUnmarshal(args[0], &args0)
-
-		e.nodeIndex = args0
-		return nil, nil
-	// SelfSignData(data []byte) (rawSig []byte)
-	case "self_sign_data":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.SelfSignDataCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
-
-		var args0 []byte
-		_ = castOrUnmarshal(args[0], &args0)
-
-		// doesn't lock as this should NEVER change
-		rawSig := e.selfSignData(args0)
-		return rawSig, nil
-	case "await_complete_node_list":
-		var args0 int
-		_ = castOrUnmarshal(args[0], &args0)
-
-		nodeEpoch := args0
-		if e.nodeList == nil {
-			return nil, errors.New("nodelist contract is undefined")
-		}
-		first := true
-		for {
-			if !first {
-				time.Sleep(10 * time.Second)
-			}
-			first = false
-			logging.Debug("attempting to retrieve complete node list")
-			if e.nodeRegisterMap[nodeEpoch] == nil {
-				logging.WithField("nodeRegisterMap", e.nodeRegisterMap).Error("could not get node list")
-				continue
-			}
-			currEpochInfo, err := e.GetEpochInfo(nodeEpoch, true)
-			if err != nil {
-				logging.WithError(err).Error("could not get current epoch")
-				continue
-			}
-			nodeList := e.nodeRegisterMap[nodeEpoch].NodeList
-			if currEpochInfo.N.Cmp(big.NewInt(int64(len(nodeList)))) != 0 {
-				logging.WithFields(logging.Fields{
-					"nodeList":      nodeList,
-					"expectedNodes": currEpochInfo.N,
-				}).Error("NodeList and expected nodes not equal")
-			} else {
-				break
-			}
-		}
-		nodeReferences := make([]SerializedNodeReference, 0)
-		for _, nodeDetails := range e.nodeRegisterMap[nodeEpoch].NodeList {
-			nodeReferences = append(nodeReferences, nodeDetails.Serialize())
-		}
-		return nodeReferences, nil
-	// GetNodeList(epoch int) []NodeReference
-	case "get_node_list":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeListCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	return e.GetEpochInfo(epoch, skipCache)
+}
 
-		var args0 int
-		_ = castOrUnmarshal(args[0], &args0)
+func (e *EthereumService) handleGetSelfIndex(ctx context.Context) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
+	e.Lock()
+	defer e.Unlock()
+	for {
+		if e.nodeIndex != 0 {
+			return e.nodeIndex, nil
+		}
+		e.Unlock()
+		nodeClock.Sleep(1 * time.Second)
 		e.Lock()
-		defer e.Unlock()
-		nodeEpoch := args0
-		if e.nodeRegisterMap[nodeEpoch] == nil {
-			return nil, fmt.Errorf("Could not get node list %v", nodeEpoch)
+	}
+}
+
+func (e *EthereumService) handleGetSelfPrivateKey(ctx context.Context) (big.Int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfPrivateKeyCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	privKey, err := signer.PrivateKey(e.signer)
+	if err != nil {
+		return big.Int{}, fmt.Errorf("could not get private key from %v signer: %v", e.signer.Type(), err)
+	}
+	return *privKey.D, nil
+}
+
+func (e *EthereumService) handleGetSelfPublicKey(ctx context.Context) (common.Point, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfPublicKeyCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	e.Lock()
+	defer e.Unlock()
+	if e.nodePubK == nil {
+		return common.Point{}, errors.New("Ethereum public key has not been initialized")
+	}
+	return common.Point{
+		X: *e.nodePubK.X,
+		Y: *e.nodePubK.Y,
+	}, nil
+}
+
+func (e *EthereumService) handleGetSelfAddress(ctx context.Context) (ethCommon.Address, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfAddressCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	e.Lock()
+	defer e.Unlock()
+	if e.nodeAddr == nil {
+		return ethCommon.Address{}, errors.New("Ethereum node address has not been initialized")
+	}
+	return *e.nodeAddr, nil
+}
+
+func (e *EthereumService) handleSetSelfIndex(ctx context.Context, index int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetSelfIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	e.Lock()
+	defer e.Unlock()
+	e.nodeIndex = index
+	return nil
+}
+
+func (e *EthereumService) handleSelfSignData(ctx context.Context, input []byte) ([]byte, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.SelfSignDataCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	// doesn't lock as this should NEVER change
+	return e.selfSignData(input)
+}
+
+func (e *EthereumService) handleAwaitCompleteNodeList(ctx context.Context, epoch int) ([]SerializedNodeReference, error) {
+	if e.nodeRegistry == nil {
+		return nil, errors.New("node registry is undefined")
+	}
+	first := true
+	for {
+		if !first {
+			nodeClock.Sleep(10 * time.Second)
 		}
-		nodeReferences := make([]SerializedNodeReference, 0)
-		for _, nodeDetails := range e.nodeRegisterMap[nodeEpoch].NodeList {
-			nodeReferences = append(nodeReferences, nodeDetails.Serialize())
+		first = false
+		logging.Debug("attempting to retrieve complete node list")
+		if e.nodeRegisterMap[epoch] == nil {
+			logging.WithField("nodeRegisterMap", e.nodeRegisterMap).Error("could not get node list")
+			continue
 		}
-		return nodeReferences, nil
-	// GetNodeDetailsByAddress(address ethCommon.Address) NodeReference
-	case "get_node_details_by_address":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeDetailsByAddressCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+		currEpochInfo, err := e.GetEpochInfo(epoch, true)
+		if err != nil {
+			logging.WithError(err).Error("could not get current epoch")
+			continue
+		}
+		nodeList := e.nodeRegisterMap[epoch].NodeList
+		if currEpochInfo.N.Cmp(big.NewInt(int64(len(nodeList)))) != 0 {
+			logging.WithFields(logging.Fields{
+				"nodeList":      nodeList,
+				"expectedNodes": currEpochInfo.N,
+			}).Error("NodeList and expected nodes not equal")
+		} else {
+			break
+		}
+	}
+	nodeReferences := make([]SerializedNodeReference, 0)
+	for _, nodeDetails := range e.nodeRegisterMap[epoch].NodeList {
+		nodeReferences = append(nodeReferences, nodeDetails.Serialize())
+	}
+	return nodeReferences, nil
+}
 
-		var args0 ethCommon.Address
-		_ = castOrUnmarshal(args[0], &args0)
+func (e *EthereumService) handleGetNodeList(ctx context.Context, epoch int) ([]SerializedNodeReference, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeListCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		e.Lock()
-		defer e.Unlock()
-		for _, nodeRegister := range e.nodeRegisterMap {
-			for _, nodeDetails := range nodeRegister.NodeList {
-				if nodeDetails.Address.String() == args0.String() {
-					return nodeDetails.Serialize(), nil
-				}
-			}
-		}
-		return nil, fmt.Errorf("node could not be found for address %s", args0.String())
-	// GetNodeDetailsByEpochAndIndex(epoch int, index int) NodeReference
-	case "get_node_details_by_epoch_and_index":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeDetailsByEpochAndIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	e.Lock()
+	defer e.Unlock()
+	if e.nodeRegisterMap[epoch] == nil {
+		return nil, fmt.Errorf("Could not get node list %v", epoch)
+	}
+	nodeReferences := make([]SerializedNodeReference, 0)
+	for _, nodeDetails := range e.nodeRegisterMap[epoch].NodeList {
+		nodeReferences = append(nodeReferences, nodeDetails.Serialize())
+	}
+	return nodeReferences, nil
+}
 
-		var args0, args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (e *EthereumService) handleGetNodeDetailsByAddress(ctx context.Context, address ethCommon.Address) (SerializedNodeReference, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeDetailsByAddressCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		e.Lock()
-		defer e.Unlock()
-		for _, nodeDetails := range e.nodeRegisterMap[args0].NodeList {
-			if int(nodeDetails.Index.Int64()) == args1 {
+	e.Lock()
+	defer e.Unlock()
+	for _, nodeRegister := range e.nodeRegisterMap {
+		for _, nodeDetails := range nodeRegister.NodeList {
+			if nodeDetails.Address.String() == address.String() {
 				return nodeDetails.Serialize(), nil
 			}
 		}
-		return nil, fmt.Errorf("node could not be found for %v %v", args0, args1)
-	case "await_nodes_connected":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.AwaitNodesConnectedCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	}
+	return SerializedNodeReference{}, fmt.Errorf("node could not be found for address %s", address.String())
+}
 
-		var args0 int
-		_ = castOrUnmarshal(args[0], &args0)
+func (e *EthereumService) handleGetNodeDetailsByEpochAndIndex(ctx context.Context, epoch int, index int) (SerializedNodeReference, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetNodeDetailsByEpochAndIndexCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		interval := time.NewTicker(1 * time.Second)
-		if e.nodeRegisterMap[args0] != nil && len(e.nodeRegisterMap[args0].NodeList) > 0 {
-			return nil, nil
-		}
-		for range interval.C {
-			if e.nodeRegisterMap[args0] != nil && len(e.nodeRegisterMap[args0].NodeList) > 0 {
-				return nil, nil
+	e.Lock()
+	defer e.Unlock()
+	if e.nodeRegisterMap[epoch] != nil {
+		for _, nodeDetails := range e.nodeRegisterMap[epoch].NodeList {
+			if int(nodeDetails.Index.Int64()) == index {
+				return nodeDetails.Serialize(), nil
 			}
-			logging.WithField("epoch", args0).Debug("waiting for nodes to be connected")
 		}
-	case "get_PSS_status":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetPSSStatusCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+	}
+	return SerializedNodeReference{}, fmt.Errorf("node could not be found for %v %v", epoch, index)
+}
 
-		var args0, args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (e *EthereumService) handleAwaitNodesConnected(ctx context.Context, epoch int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.AwaitNodesConnectedCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
 
-		oldEpoch := args0
-		newEpoch := args1
-		pssStatus, err := e.nodeList.GetPssStatus(nil, big.NewInt(int64(oldEpoch)), big.NewInt(int64(newEpoch)))
-		if err != nil {
-			return nil, err
-		}
-		return int(pssStatus.Int64()), nil
-	case "verify_data_with_nodelist":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.VerifyDataWithNodeListCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
-
-		var args0 common.Point
-		var args1, args2 []byte
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		return e.verifyDataWithNodelist(args0, args1, args2)
-
-	case "verify_data_with_epoch":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.VerifyDataWithEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
-
-		var args0 common.Point
-		var args1, args2 []byte
-		var args3 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-		_ = castOrUnmarshal(args[3], &args3)
-
-		return e.verifyDataWithEpoch(args0, args1, args2, args3)
-
-	case "start_PSS_monitor":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.StartPSSMonitorCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
-
-		go e.startPSSMonitor()
-		return nil, nil
-
-	case "get_tm_p2p_connection":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetTMP2PConnection, pcmn.TelemetryConstants.Ethereum.Prefix)
-		return e.tmp2pConnection, nil
-
-	case "get_p2p_connection":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetP2PConnection, pcmn.TelemetryConstants.Ethereum.Prefix)
-		return e.p2pConnection, nil
-
-	case "validate_epoch_pub_key":
-		var args0 ethCommon.Address
-		var args1 common.Point
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		pubKey, err := e.serviceLibrary.DatabaseMethods().RetrieveNodePubKey(args0)
-		if err != nil {
-			return false, err
-		}
-		if pubKey.X.Cmp(&args1.X) == 0 && pubKey.Y.Cmp(&args1.Y) == 0 {
-			return true, nil
+	interval := nodeClock.NewTicker(1 * time.Second)
+	defer interval.Stop()
+	for {
+		if e.nodeRegisterMap[epoch] != nil && len(e.nodeRegisterMap[epoch].NodeList) > 0 {
+			return nil
 		}
-		return false, errors.New("incorrect pubkey")
+		<-interval.C()
+		logging.WithField("epoch", epoch).Debug("waiting for nodes to be connected")
 	}
-	return nil, fmt.Errorf("ethereum service method %v not found", method)
 }
 
-func (e *EthereumService) SetBaseService(bs *BaseService) {
-	e.bs = bs
+func (e *EthereumService) handleGetPSSStatus(ctx context.Context, oldEpoch int, newEpoch int) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetPSSStatusCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	return e.nodeRegistry.PSSStatus(e.context, oldEpoch, newEpoch)
 }
 
-func (e *EthereumService) newStandardEthCallOpts() (*bind.CallOpts, error) {
-	auth := bind.CallOpts{
-		From: *e.nodeAddr,
-	}
-	return &auth, nil
+func (e *EthereumService) handleVerifyDataWithNodelist(ctx context.Context, pk common.Point, sig []byte, input []byte) (NodeDetails, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.VerifyDataWithNodeListCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	return e.verifyDataWithNodelist(pk, sig, input)
 }
 
-func (e *EthereumService) IsSelfRegistered(epoch int) (bool, error) {
-	opts, err := e.newStandardEthCallOpts()
+func (e *EthereumService) handleVerifyDataWithEpoch(ctx context.Context, pk common.Point, sig []byte, input []byte, epoch int) (NodeDetails, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.VerifyDataWithEpochCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	return e.verifyDataWithEpoch(pk, sig, input, epoch)
+}
+
+func (e *EthereumService) handleStartPSSMonitor(ctx context.Context) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.StartPSSMonitorCounter, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	go e.startPSSMonitor()
+	return nil
+}
+
+func (e *EthereumService) handleGetTMP2PConnection(ctx context.Context) (string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetTMP2PConnection, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	return e.tmp2pConnection, nil
+}
+
+func (e *EthereumService) handleGetP2PConnection(ctx context.Context) (string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Ethereum.GetP2PConnection, pcmn.TelemetryConstants.Ethereum.Prefix)
+
+	return e.p2pConnection, nil
+}
+
+func (e *EthereumService) handleValidateEpochPubKey(ctx context.Context, nodeAddress ethCommon.Address, pubK common.Point) (bool, error) {
+	ctx = withSpanOf(e.context, ctx)
+	pubKey, err := e.serviceLibrary.DatabaseMethods().RetrieveNodePubKey(ctx, nodeAddress)
 	if err != nil {
 		return false, err
 	}
-	result, err := e.nodeList.NodeRegistered(opts, big.NewInt(int64(epoch)), *e.nodeAddr)
+	return pubKey.X.Cmp(&pubK.X) == 0 && pubKey.Y.Cmp(&pubK.Y) == 0, nil
+}
+
+func (e *EthereumService) handleEthEndpoints(ctx context.Context) ([]ethrpc.EndpointStatus, error) {
+	if e.ethClient == nil {
+		return nil, errors.New("the node registry does not use ethereum")
+	}
+	return e.ethClient.Status(), nil
+}
+
+func (e *EthereumService) handleGetRegistrationStatus(ctx context.Context) (RegistrationStatus, error) {
+	e.Lock()
+	status := RegistrationStatus{
+		Epoch:       e.currentEpoch,
+		Whitelisted: e.isWhitelisted,
+		Registered:  e.isRegistered,
+	}
+	e.Unlock()
+	if e.txManager == nil {
+		return status, nil
+	}
+	_, state, sent, err := registry.RegistrationTx(e.txManager, status.Epoch)
 	if err != nil {
-		return false, err
+		return status, err
+	}
+	if sent {
+		status.Transaction = &state
 	}
-	return result, nil
+	return status, nil
+}
+
+func (e *EthereumService) SetBaseService(bs *BaseService) {
+	e.bs = bs
+}
+
+func (e *EthereumService) IsSelfRegistered(epoch int) (bool, error) {
+	return e.nodeRegistry.NodeRegistered(e.context, epoch, *e.nodeAddr)
 }
 
 func (e *EthereumService) GetEpochInfo(epoch int, skipCache bool) (epochInfo, error) {
@@ -650,71 +679,89 @@ func (e *EthereumService) GetEpochInfo(epoch int, skipCache bool) (epochInfo, er
 			return eInfo, nil
 		}
 	}
-	opts, err := e.newStandardEthCallOpts()
-	if err != nil {
-		return epochInfo{}, err
-	}
 	if epoch == 0 {
 		return epochInfo{}, fmt.Errorf("Epoch %v is invalid", epoch)
 	}
-	result, err := e.nodeList.GetEpochInfo(opts, big.NewInt(int64(epoch)))
+	result, err := e.nodeRegistry.EpochInfo(e.context, epoch)
 	if err != nil {
 		return epochInfo{}, err
 	}
-	if result.Id.Cmp(big.NewInt(0)) == 0 {
+	if result.ID == 0 {
 		return epochInfo{}, fmt.Errorf("Epoch %v has not been initialized", epoch)
 	}
 	eInfo := epochInfo{
-		Id:        *result.Id,
-		N:         *result.N,
-		K:         *result.K,
-		T:         *result.T,
-		PrevEpoch: *result.PrevEpoch,
-		NextEpoch: *result.NextEpoch,
+		Id:        *big.NewInt(int64(result.ID)),
+		N:         *big.NewInt(int64(result.N)),
+		K:         *big.NewInt(int64(result.K)),
+		T:         *big.NewInt(int64(result.T)),
+		PrevEpoch: *big.NewInt(int64(result.PrevEpoch)),
+		NextEpoch: *big.NewInt(int64(result.NextEpoch)),
 	}
 	e.cachedEpochInfo.Set(epoch, eInfo)
 	return eInfo, nil
 }
 
-// RegisterNode - the main function that registered the node on Ethereum node list with the details provided
-func (e *EthereumService) RegisterNode(epoch int, declaredIP string, TMP2PConnection string, P2PConnection string) (*types.Transaction, error) {
-	nonce, err := e.ethClient.PendingNonceAt(context.Background(), ethCrypto.PubkeyToAddress(*e.nodePubK))
-	if err != nil {
-		return nil, err
-	}
+// RegisterNode - the main function that registered the node on the node registry with the details provided
+func (e *EthereumService) RegisterNode(epoch int, declaredIP string, TMP2PConnection string, P2PConnection string) error {
+	return e.nodeRegistry.Register(e.context, epoch, registry.NodeDetails{
+		DeclaredIP:         declaredIP,
+		PubKx:              e.nodePubK.X,
+		PubKy:              e.nodePubK.Y,
+		TMP2PListenAddress: TMP2PConnection,
+		P2PListenAddress:   P2PConnection,
+	})
+}
 
-	gasPrice, err := e.ethClient.SuggestGasPrice(context.Background())
-	gasPrice.Add(gasPrice, gasPrice) // doubles gas price
-	gasPrice.Add(gasPrice, gasPrice) // quadruples gas price
-	if err != nil {
-		return nil, err
+// ethPollInterval is how often the chain is polled when it cannot push new heads, and how long
+// monitors wait before retrying steps that failed, such as reading the chain or connecting to nodes
+func ethPollInterval() time.Duration {
+	if config.GlobalConfig.EthPollFreq > 0 {
+		return time.Duration(config.GlobalConfig.EthPollFreq) * time.Second
 	}
+	return ethwatch.DefaultPollInterval
+}
 
-	auth := bind.NewKeyedTransactor(e.nodePrivK)
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)      // in wei
-	auth.GasLimit = uint64(4700000) // in units
-	auth.GasPrice = gasPrice
+// awaitNodeListChange blocks until sub receives a change of the node list, or until retry passes
+// if it is not 0. It returns false once ctx is done.
+func awaitNodeListChange(ctx context.Context, sub *ethwatch.Subscription, retry time.Duration) bool {
+	var timeout <-chan time.Time
+	if retry > 0 {
+		timeout = nodeClock.After(retry)
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-sub.C:
+	case <-timeout:
+	}
+	return true
+}
 
-	tx, err := e.nodeList.ListNode(auth, big.NewInt(int64(epoch)), declaredIP, e.nodePubK.X, e.nodePubK.Y, "", "")
+// retryAfter returns the interval to retry after err, or 0 to wait for the node list to change
+func retryAfter(err error) time.Duration {
 	if err != nil {
-		return nil, err
+		return ethPollInterval()
 	}
-	return tx, nil
+	return 0
 }
 
 func whitelistMonitor(e *EthereumService) {
-	interval := time.NewTicker(10 * time.Second)
-	for range interval.C {
-		isWhitelisted, err := e.nodeList.IsWhitelisted(nil, big.NewInt(int64(e.currentEpoch)), *e.nodeAddr)
+	sub := e.watcher.Subscribe(ethwatch.WhitelistUpdated)
+	defer sub.Unsubscribe()
+	e.watcher.WatchWhitelist(e.currentEpoch, *e.nodeAddr)
+	for {
+		isWhitelisted, err := e.nodeRegistry.Whitelisted(e.context, e.currentEpoch, *e.nodeAddr)
 		if err != nil {
-			logging.WithError(err).Error("could not check ethereum whitelist")
+			logging.WithError(err).Error("could not check whitelist")
 		}
 		if isWhitelisted {
 			e.isWhitelisted = true
 			break
 		}
 		logging.Warn("node is not whitelisted")
+		if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+			return
+		}
 	}
 }
 
@@ -728,7 +775,7 @@ func registerNode(e *EthereumService) {
 			break
 		}
 		logging.WithField("nodeIndex", e.nodeIndex).Debug("node is not whitelisted yet")
-		time.Sleep(10 * time.Second)
+		nodeClock.Sleep(10 * time.Second)
 	}
 	var registered bool
 	err := retry.Do(func() error {
@@ -743,8 +790,8 @@ func registerNode(e *EthereumService) {
 		logging.WithError(err).Fatal()
 	}
 	externalAddr := "tcp://" + config.GlobalConfig.ProvidedIPAddress + ":" + strings.Split(config.GlobalConfig.TMP2PListenAddress, ":")[2]
-	tmp2pNodeKey := e.serviceLibrary.TendermintMethods().GetNodeKey()
-	p2pHostAddress := e.serviceLibrary.P2PMethods().GetHostAddress()
+	tmp2pNodeKey := e.serviceLibrary.TendermintMethods().GetNodeKey(e.context)
+	p2pHostAddress := e.serviceLibrary.P2PMethods().GetHostAddress(e.context)
 	splitP2PHostAddr := strings.Split(p2pHostAddress, "/")
 	splitP2PHostAddr[2] = config.GlobalConfig.ProvidedIPAddress
 	hostP2PAddressWithIP := strings.Join(splitP2PHostAddr, "/")
@@ -766,70 +813,89 @@ func registerNode(e *EthereumService) {
 			"hostP2PAddressWithIP": hostP2PAddressWithIP,
 		}).Info("registering node")
 
-		_, err := e.RegisterNode(
-			e.currentEpoch,
-			registeredDeclaredConEndpoint,
-			e.tmp2pConnection,
-			e.p2pConnection,
-		)
-		if err != nil {
-			logging.WithError(err).Fatal()
+		for {
+			err := e.RegisterNode(
+				e.currentEpoch,
+				registeredDeclaredConEndpoint,
+				e.tmp2pConnection,
+				e.p2pConnection,
+			)
+			if err == nil {
+				break
+			}
+			if err != txmanager.ErrReverted {
+				logging.WithError(err).Fatal()
+			}
+			// the registry may refuse the node until it is whitelisted again or the epoch changes
+			logging.WithField("epoch", e.currentEpoch).WithError(err).Error("registration reverted, registering again")
+			nodeClock.Sleep(ethPollInterval())
+			if registered, err := e.IsSelfRegistered(e.currentEpoch); err == nil && registered {
+				break
+			}
 		}
 	}
 	e.isRegistered = true
 }
 
-func outgoingPSSMonitor(e eventbus.Bus) {
+func outgoingPSSMonitor(e eventbus.Bus, watcher *ethwatch.Watcher) {
 	serviceLibrary := NewServiceLibrary(e, "outgoing_PSS_monitor")
 	logging.Info("started outgoingPSSMonitor")
-	currEpoch := serviceLibrary.EthereumMethods().GetCurrentEpoch()
-	interval := time.NewTicker(10 * time.Second)
+	currEpoch := serviceLibrary.EthereumMethods().GetCurrentEpoch(context.Background())
+	sub := watcher.Subscribe(ethwatch.EpochUpdated, ethwatch.PSSStatusUpdated)
+	defer sub.Unsubscribe()
+	watcher.WatchEpoch(currEpoch)
 	var currEpochInfo epochInfo
 	var nextEpoch int
 	var err error
-	for range interval.C {
-		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(currEpoch, true)
-		if err != nil || currEpochInfo.NextEpoch.Int64() == 0 {
-			logging.WithField("err", err).Debug("could not get previous epoch")
-			continue
+	for {
+		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, true)
+		if err == nil && currEpochInfo.NextEpoch.Int64() != 0 {
+			nextEpoch = int(currEpochInfo.PrevEpoch.Int64())
+			break
 		}
-		nextEpoch = int(currEpochInfo.PrevEpoch.Int64())
-		break
+		logging.WithField("err", err).Debug("could not get previous epoch")
+		awaitNodeListChange(context.Background(), sub, retryAfter(err))
 	}
-	for range interval.C {
-		nextEpoch, err = serviceLibrary.EthereumMethods().GetNextEpoch()
-		if err != nil || nextEpoch == 0 {
-			logging.WithField("err", err).Debug("could not get currentEpoch")
-			continue
+	for {
+		nextEpoch, err = serviceLibrary.EthereumMethods().GetNextEpoch(context.Background())
+		if err == nil && nextEpoch != 0 {
+			break
 		}
-		break
+		logging.WithField("err", err).Debug("could not get currentEpoch")
+		awaitNodeListChange(context.Background(), sub, retryAfter(err))
 	}
-	serviceLibrary.EthereumMethods().AwaitNodesConnected(nextEpoch)
+	watcher.WatchEpoch(nextEpoch)
+	watcher.WatchPSS(currEpoch, nextEpoch)
+	serviceLibrary.EthereumMethods().AwaitNodesConnected(context.Background(), nextEpoch)
 	var nextEpochInfo epochInfo
-	for range interval.C {
+	for {
 		var err error
-		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(currEpoch, true)
+		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, true)
 		if err != nil {
 			logging.WithError(err).Error("could not get currEpochInfo")
+			awaitNodeListChange(context.Background(), sub, retryAfter(err))
 			continue
 		}
-		nextEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(nextEpoch, true)
+		nextEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), nextEpoch, true)
 		if err != nil {
 			logging.WithError(err).Error("could not get nextEpochInfo")
+			awaitNodeListChange(context.Background(), sub, retryAfter(err))
 			continue
 		}
-		pssStatus, err := serviceLibrary.EthereumMethods().GetPSSStatus(currEpoch, nextEpoch)
+		pssStatus, err := serviceLibrary.EthereumMethods().GetPSSStatus(context.Background(), currEpoch, nextEpoch)
 		if err != nil {
 			logging.WithError(err).Error("could not get pssStatus")
+			awaitNodeListChange(context.Background(), sub, retryAfter(err))
 			continue
 		}
 		if pssStatus != 1 {
 			logging.Error("pssStatus is not 1 yet, waiting...")
+			awaitNodeListChange(context.Background(), sub, 0)
 			continue
 		}
 		break
 	}
-	err = serviceLibrary.PSSMethods().NewPSSNode(PSSStartData{
+	err = serviceLibrary.PSSMethods().NewPSSNode(context.Background(), PSSStartData{
 		Message:   "start",
 		OldEpoch:  int(currEpochInfo.Id.Int64()),
 		OldEpochN: int(currEpochInfo.N.Int64()),
@@ -843,7 +909,7 @@ func outgoingPSSMonitor(e eventbus.Bus) {
 	if err != nil {
 		logging.WithError(err).Error("could not start new PSSNode")
 	}
-	err = serviceLibrary.MappingMethods().NewMappingNode(MappingStartData{
+	err = serviceLibrary.MappingMethods().NewMappingNode(context.Background(), MappingStartData{
 		OldEpoch:  int(currEpochInfo.Id.Int64()),
 		OldEpochN: int(currEpochInfo.N.Int64()),
 		OldEpochK: int(currEpochInfo.K.Int64()),
@@ -856,20 +922,23 @@ func outgoingPSSMonitor(e eventbus.Bus) {
 	if err != nil {
 		logging.WithError(err).Error("could not start new mappingNode")
 	}
-	mappingID := serviceLibrary.MappingMethods().GetMappingID(currEpoch, nextEpoch)
+	mappingID := serviceLibrary.MappingMethods().GetMappingID(context.Background(), currEpoch, nextEpoch)
 
 	var currFreezeState int
 	var endIndex uint
 
-	if currFreezeState, _ = serviceLibrary.MappingMethods().GetFreezeState(mappingID); currFreezeState == 0 {
-		err := serviceLibrary.MappingMethods().ProposeFreeze(mappingID)
+	if currFreezeState, _ = serviceLibrary.MappingMethods().GetFreezeState(context.Background(), mappingID); currFreezeState == 0 {
+		err := serviceLibrary.MappingMethods().ProposeFreeze(context.Background(), mappingID)
 		if err != nil {
 			logging.WithError(err).Error("could not send propose freeze broadcast")
 		}
-		serviceLibrary.MappingMethods().SetFreezeState(mappingID, 1, 0)
+		serviceLibrary.MappingMethods().SetFreezeState(context.Background(), mappingID, 1, 0)
 	}
-	for range interval.C {
-		if currFreezeState, endIndex = serviceLibrary.MappingMethods().GetFreezeState(mappingID); currFreezeState == 2 {
+	// the freeze state is agreed on over BFT, not on chain
+	interval := nodeClock.NewTicker(10 * time.Second)
+	defer interval.Stop()
+	for range interval.C() {
+		if currFreezeState, endIndex = serviceLibrary.MappingMethods().GetFreezeState(context.Background(), mappingID); currFreezeState == 2 {
 			break
 		}
 		logging.Debug("waiting for FreezeState to be 2")
@@ -877,7 +946,7 @@ func outgoingPSSMonitor(e eventbus.Bus) {
 
 	go triggerMapping(e, endIndex, mappingID, currEpochInfo, nextEpochInfo)
 	go triggerPSS(e, endIndex, currEpochInfo, nextEpochInfo)
-	serviceLibrary.MappingMethods().SetFreezeState(mappingID, 3, endIndex)
+	serviceLibrary.MappingMethods().SetFreezeState(context.Background(), mappingID, 3, endIndex)
 }
 
 func triggerMapping(e eventbus.Bus, endIndex uint, mappingID mapping.MappingID, currEpochInfo epochInfo, nextEpochInfo epochInfo) {
@@ -891,7 +960,7 @@ func triggerMapping(e eventbus.Bus, endIndex uint, mappingID mapping.MappingID,
 	if err != nil {
 		logging.WithError(err).Error("could not marshal mapping summary message")
 	}
-	err = serviceLibrary.MappingMethods().ReceiveBFTMessage(mappingID, mapping.CreateMappingMessage(mapping.MappingMessageRaw{
+	err = serviceLibrary.MappingMethods().ReceiveBFTMessage(context.Background(), mappingID, mapping.CreateMappingMessage(mapping.MappingMessageRaw{
 		MappingID: mappingID,
 		Method:    "mapping_summary_frozen",
 		Data:      byt,
@@ -905,9 +974,9 @@ func triggerPSS(e eventbus.Bus, endIndex uint, currEpochInfo epochInfo, nextEpoc
 	serviceLibrary := NewServiceLibrary(e, "trigger_PSS")
 	currEpoch := int(currEpochInfo.Id.Int64())
 	nextEpoch := int(nextEpochInfo.Id.Int64())
-	pssProtocolPrefix := serviceLibrary.PSSMethods().GetPSSProtocolPrefix(currEpoch, nextEpoch)
+	pssProtocolPrefix := serviceLibrary.PSSMethods().GetPSSProtocolPrefix(context.Background(), currEpoch, nextEpoch)
 	for i := 0; i < int(endIndex); i++ {
-		time.Sleep(time.Duration(config.GlobalMutableConfig.GetI("PSSShareDelayMS")) * time.Millisecond)
+		nodeClock.Sleep(time.Duration(config.GlobalMutableConfig.GetI("PSSShareDelayMS")) * time.Millisecond)
 		keygenID := pss.GenerateKeygenID(i)
 		serviceLibrary := NewServiceLibrary(e, "send_PSS_message")
 		logging.WithFields(logging.Fields{
@@ -934,14 +1003,14 @@ func triggerPSS(e eventbus.Bus, endIndex uint, currEpochInfo epochInfo, nextEpoc
 		}
 		pssID := (&pss.PSSIDDetails{
 			SharingID:   sharingID,
-			DealerIndex: serviceLibrary.EthereumMethods().GetSelfIndex(),
+			DealerIndex: serviceLibrary.EthereumMethods().GetSelfIndex(context.Background()),
 		}).ToPSSID()
 		logging.WithField("pssID", pssID).Debug()
-		selfPublicKey := serviceLibrary.EthereumMethods().GetSelfPublicKey()
-		err = serviceLibrary.PSSMethods().SendPSSMessageToNode(
+		selfPublicKey := serviceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
+		err = serviceLibrary.PSSMethods().SendPSSMessageToNode(context.Background(),
 			pssProtocolPrefix,
 			pss.NodeDetails(pcmn.Node{
-				Index:  serviceLibrary.EthereumMethods().GetSelfIndex(),
+				Index:  serviceLibrary.EthereumMethods().GetSelfIndex(context.Background()),
 				PubKey: selfPublicKey,
 			}),
 			pss.CreatePSSMessage(pss.PSSMessageRaw{
@@ -956,36 +1025,41 @@ func triggerPSS(e eventbus.Bus, endIndex uint, currEpochInfo epochInfo, nextEpoc
 	}
 }
 
-func incomingPSSMonitor(e eventbus.Bus) {
+func incomingPSSMonitor(e eventbus.Bus, watcher *ethwatch.Watcher) {
 	serviceLibrary := NewServiceLibrary(e, "incoming_PSS_monitor")
 	logging.Info("started IncomingPSSMonitor")
-	currEpoch := serviceLibrary.EthereumMethods().GetCurrentEpoch()
-	interval := time.NewTicker(10 * time.Second)
+	currEpoch := serviceLibrary.EthereumMethods().GetCurrentEpoch(context.Background())
+	sub := watcher.Subscribe(ethwatch.EpochUpdated)
+	defer sub.Unsubscribe()
+	watcher.WatchEpoch(currEpoch)
 	var currEpochInfo epochInfo
 	var prevEpoch int
 	var err error
-	for range interval.C {
-		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(currEpoch, true)
-		if err != nil || currEpochInfo.PrevEpoch.Int64() == 0 {
-			logging.WithField("err", err).Debug("could not get previous epoch")
-			continue
+	for {
+		currEpochInfo, err = serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, true)
+		if err == nil && currEpochInfo.PrevEpoch.Int64() != 0 {
+			prevEpoch = int(currEpochInfo.PrevEpoch.Int64())
+			break
 		}
-		prevEpoch = int(currEpochInfo.PrevEpoch.Int64())
-		break
+		logging.WithField("err", err).Debug("could not get previous epoch")
+		awaitNodeListChange(context.Background(), sub, retryAfter(err))
 	}
-	serviceLibrary.EthereumMethods().AwaitNodesConnected(prevEpoch)
-	for range interval.C {
-		prevEpochInfo, err := serviceLibrary.EthereumMethods().GetEpochInfo(prevEpoch, true)
+	watcher.WatchEpoch(prevEpoch)
+	serviceLibrary.EthereumMethods().AwaitNodesConnected(context.Background(), prevEpoch)
+	for {
+		prevEpochInfo, err := serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), prevEpoch, true)
 		if err != nil {
 			logging.WithField("err", err).Debug("could not get prevEpochInfo")
+			awaitNodeListChange(context.Background(), sub, retryAfter(err))
 			continue
 		}
-		currEpochInfo, err := serviceLibrary.EthereumMethods().GetEpochInfo(currEpoch, true)
+		currEpochInfo, err := serviceLibrary.EthereumMethods().GetEpochInfo(context.Background(), currEpoch, true)
 		if err != nil {
 			logging.WithField("err", err).Debug("could not get currEpochInfo")
+			awaitNodeListChange(context.Background(), sub, retryAfter(err))
 			continue
 		}
-		err = serviceLibrary.PSSMethods().NewPSSNode(PSSStartData{
+		err = serviceLibrary.PSSMethods().NewPSSNode(context.Background(), PSSStartData{
 			Message:   "start",
 			OldEpoch:  int(prevEpochInfo.Id.Int64()),
 			OldEpochN: int(prevEpochInfo.N.Int64()),
@@ -999,7 +1073,7 @@ func incomingPSSMonitor(e eventbus.Bus) {
 		if err != nil {
 			logging.WithError(err).Error("could not start new pss node")
 		}
-		err = serviceLibrary.MappingMethods().NewMappingNode(MappingStartData{
+		err = serviceLibrary.MappingMethods().NewMappingNode(context.Background(), MappingStartData{
 			OldEpoch:  int(prevEpochInfo.Id.Int64()),
 			OldEpochN: int(prevEpochInfo.N.Int64()),
 			OldEpochK: int(prevEpochInfo.K.Int64()),
@@ -1016,22 +1090,23 @@ func incomingPSSMonitor(e eventbus.Bus) {
 	}
 }
 
-// getNodeRefsByEpoch - Retreives NodeList from ethereum and gets their details
+// getNodeRefsByEpoch - Retreives NodeList from the node registry and gets their details
 // appends and returns an array of node references
 func (e *EthereumService) getNodeRefsByEpoch(epoch int) ([]*NodeReference, error) {
 	logging.WithField("epoch", epoch).Debug("getNodeRefsByEpoch called")
-	ethList, err := e.nodeList.GetNodes(nil, big.NewInt(int64(epoch)))
+	info, err := e.nodeRegistry.EpochInfo(e.context, epoch)
 	if err != nil {
 		return nil, fmt.Errorf("Could not get node list %v", err.Error())
 	}
+	ethList := info.Nodes
 	var currNodeList []*NodeReference
 
 	for i := 0; i < len(ethList); i++ {
-		detailsWithPubK, err := e.nodeList.NodeDetails(nil, ethList[i])
+		detailsWithPubK, err := e.nodeRegistry.NodeDetails(e.context, ethList[i])
 		if err != nil {
 			return nil, fmt.Errorf("could not get node details with pub key %v", err.Error())
 		}
-		err = e.serviceLibrary.DatabaseMethods().StoreNodePubKey(ethList[i], common.Point{X: *detailsWithPubK.PubKx, Y: *detailsWithPubK.PubKy})
+		err = e.serviceLibrary.DatabaseMethods().StoreNodePubKey(e.context, ethList[i], common.Point{X: *detailsWithPubK.PubKx, Y: *detailsWithPubK.PubKy})
 		if err != nil {
 			return nil, fmt.Errorf("could not store node details with pub key %v", err.Error())
 		}
@@ -1048,11 +1123,15 @@ func (e *EthereumService) getNodeRefsByEpoch(epoch int) ([]*NodeReference, error
 }
 
 func previousNodesMonitor(e *EthereumService) {
-	interval := time.NewTicker(10 * time.Second)
-	for range interval.C {
-		previousEpoch, err := e.serviceLibrary.EthereumMethods().GetPreviousEpoch()
+	sub := e.watcher.Subscribe(ethwatch.EpochUpdated)
+	defer sub.Unsubscribe()
+	for {
+		previousEpoch, err := e.serviceLibrary.EthereumMethods().GetPreviousEpoch(e.context)
 		if err != nil || previousEpoch == 0 {
 			logging.WithField("err", err).Debug("could not get previous epoch in previous nodes monitor")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		e.Lock()
@@ -1064,10 +1143,13 @@ func previousNodesMonitor(e *EthereumService) {
 		prevNodeList, err := e.getNodeRefsByEpoch(previousEpoch)
 		if err != nil {
 			logging.WithError(err).Error("could not get previous node list")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		for _, nodeRef := range prevNodeList {
-			err := e.serviceLibrary.P2PMethods().ConnectToP2PNode(nodeRef.P2PConnection, nodeRef.PeerID)
+			err := e.serviceLibrary.P2PMethods().ConnectToP2PNode(e.context, nodeRef.P2PConnection, nodeRef.PeerID)
 			if err != nil {
 				logging.WithField("Address", *nodeRef.Address).Error("could not connect to p2p node ...continuing...")
 			}
@@ -1080,12 +1162,16 @@ func previousNodesMonitor(e *EthereumService) {
 }
 
 func currentNodesMonitor(e *EthereumService) {
-	interval := time.NewTicker(10 * time.Second)
-	for range interval.C {
-		currEpoch := e.serviceLibrary.EthereumMethods().GetCurrentEpoch()
+	sub := e.watcher.Subscribe(ethwatch.EpochUpdated, ethwatch.NodeListed)
+	defer sub.Unsubscribe()
+	for {
+		currEpoch := e.serviceLibrary.EthereumMethods().GetCurrentEpoch(e.context)
 		currEpochInfo, err := e.GetEpochInfo(currEpoch, true)
 		if err != nil {
 			logging.WithError(err).Error("could not get curr epoch")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		e.Lock()
@@ -1097,6 +1183,9 @@ func currentNodesMonitor(e *EthereumService) {
 		currNodeList, err := e.getNodeRefsByEpoch(currEpoch)
 		if err != nil {
 			logging.WithError(err).Error("could not get currNodeList")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		if currEpochInfo.N.Cmp(big.NewInt(int64(len(currNodeList)))) != 0 {
@@ -1104,21 +1193,27 @@ func currentNodesMonitor(e *EthereumService) {
 				"currNodeList":  currNodeList,
 				"currEpochInfo": currEpochInfo,
 			}).Error("currentNodeList does not equal in length to expected currEpochInfo")
+			if !awaitNodeListChange(e.context, sub, 0) {
+				return
+			}
 			continue
 		}
 		allNodesConnected := true
 		for _, nodeRef := range currNodeList {
-			err = e.serviceLibrary.P2PMethods().ConnectToP2PNode(nodeRef.P2PConnection, nodeRef.PeerID)
+			err = e.serviceLibrary.P2PMethods().ConnectToP2PNode(e.context, nodeRef.P2PConnection, nodeRef.PeerID)
 			if err != nil {
 				logging.WithField("Address", *nodeRef.Address).Error("could not connect to p2p node ...continuing...")
 				allNodesConnected = false
 			}
 			// exported out of ConnectToP2PNode
-			if nodeRef.PeerID == e.serviceLibrary.P2PMethods().ID() {
-				e.serviceLibrary.EthereumMethods().SetSelfIndex(int(nodeRef.Index.Int64()))
+			if nodeRef.PeerID == e.serviceLibrary.P2PMethods().ID(e.context) {
+				e.serviceLibrary.EthereumMethods().SetSelfIndex(e.context, int(nodeRef.Index.Int64()))
 			}
 		}
 		if !allNodesConnected {
+			if !awaitNodeListChange(e.context, sub, ethPollInterval()) {
+				return
+			}
 			continue
 		}
 		logging.WithField("currNodeList", currNodeList).Debug("connected to all nodes in current epoch")
@@ -1130,16 +1225,24 @@ func currentNodesMonitor(e *EthereumService) {
 }
 
 func nextNodesMonitor(e *EthereumService) {
-	interval := time.NewTicker(10 * time.Second)
-	for range interval.C {
-		nextEpoch, err := e.serviceLibrary.EthereumMethods().GetNextEpoch()
+	sub := e.watcher.Subscribe(ethwatch.EpochUpdated, ethwatch.NodeListed)
+	defer sub.Unsubscribe()
+	for {
+		nextEpoch, err := e.serviceLibrary.EthereumMethods().GetNextEpoch(e.context)
 		if err != nil || nextEpoch == 0 {
 			logging.WithField("err", err).Debug("could not get next epoch in next nodes monitor")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
+		e.watcher.WatchEpoch(nextEpoch)
 		nextEpochInfo, err := e.GetEpochInfo(nextEpoch, true)
 		if err != nil {
 			logging.WithError(err).Debug()
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		e.Lock()
@@ -1154,6 +1257,9 @@ func nextNodesMonitor(e *EthereumService) {
 		nextNodeList, err := e.getNodeRefsByEpoch(nextEpoch)
 		if err != nil {
 			logging.WithError(err).Error("could not get nextNodeList")
+			if !awaitNodeListChange(e.context, sub, retryAfter(err)) {
+				return
+			}
 			continue
 		}
 		if nextEpochInfo.N.Cmp(big.NewInt(int64(len(nextNodeList)))) != 0 {
@@ -1161,11 +1267,14 @@ func nextNodesMonitor(e *EthereumService) {
 				"nextNodeList":  nextNodeList,
 				"nextEpochInfo": nextEpochInfo,
 			}).Error("nextNodeList does not equal in length to expected nextEpochInfo")
+			if !awaitNodeListChange(e.context, sub, 0) {
+				return
+			}
 			continue
 		}
 		allNodesConnected := true
 		for _, nodeRef := range nextNodeList {
-			err = e.serviceLibrary.P2PMethods().ConnectToP2PNode(nodeRef.P2PConnection, nodeRef.PeerID)
+			err = e.serviceLibrary.P2PMethods().ConnectToP2PNode(e.context, nodeRef.P2PConnection, nodeRef.PeerID)
 			if err != nil {
 				logging.WithField("Address", *nodeRef.Address).Error("could not connect to p2p node ...continuing...", *nodeRef.Address)
 				allNodesConnected = false
@@ -1173,6 +1282,9 @@ func nextNodesMonitor(e *EthereumService) {
 			}
 		}
 		if !allNodesConnected {
+			if !awaitNodeListChange(e.context, sub, ethPollInterval()) {
+				return
+			}
 			continue
 		}
 		logging.WithField("nextNodeList", nextNodeList).Debug("connected to all nodes in next epoch")
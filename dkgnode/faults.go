@@ -0,0 +1,65 @@
+package dkgnode
+
+import (
+	"strings"
+	"time"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/faults"
+)
+
+// faultInjector injects the faults set through the management RPC of nodes in debug mode into
+// the p2p traffic of the keygen, PSS and mapping protocols and into BFT broadcasts
+var faultInjector = faults.NewInjector()
+
+// faultProtocol returns the protocol of the p2p protocol prefix proto, such as keygen for keygen-1/
+func faultProtocol(proto string) string {
+	return strings.SplitN(strings.TrimSuffix(proto, "/"), "-", 2)[0]
+}
+
+// withFaults delivers payload with deliver once the faults of faultInjector are applied to it.
+// Dropped messages are reported as delivered, and delayed ones are delivered in the background,
+// their errors only being logged.
+func withFaults(protocol, direction, peer string, payload []byte, deliver func(payload []byte) error) error {
+	decision := faultInjector.Apply(protocol, direction, peer, payload)
+	fields := logging.Fields{
+		"protocol":  protocol,
+		"direction": direction,
+		"peer":      peer,
+	}
+	if decision.Drop {
+		logging.WithFields(fields).Debug("fault injection dropped message")
+		return nil
+	}
+	if decision.Corrupted {
+		logging.WithFields(fields).Debug("fault injection corrupted message")
+	}
+	send := func() error {
+		err := deliver(decision.Payload)
+		for i := 0; i < decision.Duplicates; i++ {
+			if err := deliver(decision.Payload); err != nil {
+				logging.WithFields(fields).WithError(err).Debug("could not deliver duplicate of message")
+			}
+		}
+		return err
+	}
+	if decision.Delay == 0 {
+		return send()
+	}
+	logging.WithFields(fields).WithField("delay", decision.Delay).Debug("fault injection delayed message")
+	time.AfterFunc(decision.Delay, func() {
+		if err := send(); err != nil {
+			logging.WithFields(fields).WithError(err).Error("could not deliver delayed message")
+		}
+	})
+	return nil
+}
+
+// setFaults replaces the faults injected by this node
+func setFaults(rules []faults.Rule) error {
+	if err := faultInjector.SetRules(rules); err != nil {
+		return err
+	}
+	logging.WithField("rules", stringify(rules)).Warn("fault injection rules changed")
+	return nil
+}
@@ -0,0 +1,289 @@
+package dkgnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/patrickmn/go-cache"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/crypto"
+	"github.com/torusresearch/torus-node/version"
+)
+
+// gossipSeenTTL is how long a delivered message is remembered, so that copies
+// arriving later, such as retries or the same message sent over a stream, are dropped
+const gossipSeenTTL = 5 * time.Minute
+
+// gossipTopic returns the topic on which the nodes of epoch receive messages gossiped in
+// proto. Protocol prefixes already name the epochs of the protocol, the epoch in the topic
+// separates the sides of protocols run between two epochs, such as PSS and mapping.
+func gossipTopic(proto string, epoch int) string {
+	return "/torus/gossip/" + strings.TrimSuffix(proto, "/") + "/epoch/" + strconv.Itoa(epoch)
+}
+
+// gossipMessageKey identifies a message by its author and ID
+func gossipMessageKey(msg P2PBasicMsg) string {
+	return msg.NodeId + "/" + msg.MsgType + "/" + msg.Id
+}
+
+type gossipSubscription struct {
+	proto        string
+	subscription *pubsub.Subscription
+}
+
+// errGossipDelivered is returned by decode for copies of delivered messages, which honest peers relay too
+var errGossipDelivered = errors.New("message was already delivered")
+
+// gossipRouter broadcasts P2P messages over GossipSub. Messages are validated with the same
+// authentication as messages received over streams, and delivered once per author and ID.
+// Peers that relay invalid messages are reported to scores, messages relayed by banned peers are rejected.
+type gossipRouter struct {
+	ctx          context.Context
+	pubsub       *pubsub.PubSub
+	authenticate func(P2PMessage) error
+	deliver      func(proto string, from peer.ID, msg P2PBasicMsg)
+	scores       *peerScores
+	seen         *cache.Cache
+
+	lock   sync.Mutex
+	topics map[string]*gossipSubscription
+}
+
+func newGossipRouter(
+	ctx context.Context,
+	h host.Host,
+	authenticate func(P2PMessage) error,
+	deliver func(proto string, from peer.ID, msg P2PBasicMsg),
+	scores *peerScores,
+) (*gossipRouter, error) {
+	ps, err := pubsub.NewGossipSub(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	return &gossipRouter{
+		ctx:          ctx,
+		pubsub:       ps,
+		authenticate: authenticate,
+		deliver:      deliver,
+		scores:       scores,
+		seen:         cache.New(gossipSeenTTL, gossipSeenTTL),
+		topics:       make(map[string]*gossipSubscription),
+	}, nil
+}
+
+// decode returns the P2P message gossiped in message if it is authentic and has not been delivered yet,
+// otherwise the offence of the peer that relayed it, if any
+func (g *gossipRouter) decode(message *pubsub.Message) (P2PBasicMsg, peerOffence, error) {
+	var msg P2PBasicMsg
+	if err := bijson.Unmarshal(message.GetData(), &msg); err != nil {
+		return msg, offenceMalformedMessage, err
+	}
+	// the flag is signed, so messages sent to a single node cannot be gossiped by others
+	if !msg.Gossip {
+		return msg, offenceMalformedMessage, errors.New("message was not signed for gossip")
+	}
+	if _, found := g.seen.Get(gossipMessageKey(msg)); found {
+		return msg, "", errGossipDelivered
+	}
+	if err := g.authenticate(&msg); err != nil {
+		return msg, offenceUnauthenticated, err
+	}
+	return msg, "", nil
+}
+
+func (g *gossipRouter) validate(ctx context.Context, from peer.ID, message *pubsub.Message) bool {
+	if g.scores.banned(from) {
+		return false
+	}
+	_, offence, err := g.decode(message)
+	if err != nil {
+		logging.WithFields(logging.Fields{
+			"from":  from.Pretty(),
+			"topic": message.GetTopicIDs(),
+		}).WithError(err).Debug("rejected gossiped message")
+		if offence != "" {
+			g.scores.report(from, offence)
+		}
+		return false
+	}
+	return true
+}
+
+// join subscribes to the messages gossiped to the nodes of epoch in proto
+func (g *gossipRouter) join(proto string, epoch int) error {
+	topic := gossipTopic(proto, epoch)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if _, ok := g.topics[topic]; ok {
+		return nil
+	}
+	if err := g.pubsub.RegisterTopicValidator(topic, g.validate); err != nil {
+		return err
+	}
+	subscription, err := g.pubsub.Subscribe(topic)
+	if err != nil {
+		_ = g.pubsub.UnregisterTopicValidator(topic)
+		return err
+	}
+	g.topics[topic] = &gossipSubscription{proto: proto, subscription: subscription}
+	go g.run(proto, subscription)
+	return nil
+}
+
+// leave unsubscribes from every topic of proto
+func (g *gossipRouter) leave(proto string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for topic, sub := range g.topics {
+		if sub.proto != proto {
+			continue
+		}
+		sub.subscription.Cancel()
+		if err := g.pubsub.UnregisterTopicValidator(topic); err != nil {
+			logging.WithField("topic", topic).WithError(err).Error("could not unregister gossip validator")
+		}
+		delete(g.topics, topic)
+	}
+}
+
+func (g *gossipRouter) run(proto string, subscription *pubsub.Subscription) {
+	for {
+		message, err := subscription.Next(g.ctx)
+		if err != nil {
+			// the subscription was cancelled or the node is stopping
+			return
+		}
+		msg, _, err := g.decode(message)
+		if err != nil {
+			continue
+		}
+		// Add fails if another copy of the message was delivered since it was validated
+		if err := g.seen.Add(gossipMessageKey(msg), struct{}{}, cache.DefaultExpiration); err != nil {
+			continue
+		}
+		g.deliver(proto, message.ReceivedFrom, msg)
+	}
+}
+
+// publish gossips msg to the nodes of epoch in proto, the message must be signed with Gossip set
+func (g *gossipRouter) publish(proto string, epoch int, msg *P2PBasicMsg) error {
+	if !msg.Gossip {
+		return fmt.Errorf("could not gossip message %v, it was not created for gossip", msg.Id)
+	}
+	data, err := bijson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return g.pubsub.Publish(gossipTopic(proto, epoch), data)
+}
+
+// gossipP2PMessage signs payload as a gossip message and gossips it to the nodes of epoch in proto
+func gossipP2PMessage(serviceLibrary ServiceLibrary, proto string, epoch int, payload []byte, msgType string) error {
+	p2pMsg := serviceLibrary.P2PMethods().NewP2PMessage(context.Background(), HashToString(payload), true, payload, msgType)
+	signature, err := serviceLibrary.P2PMethods().SignP2PMessage(context.Background(), &p2pMsg)
+	if err != nil {
+		return errors.New("failed to sign p2p message " + err.Error())
+	}
+	p2pMsg.Sign = signature
+	return serviceLibrary.P2PMethods().GossipP2PMessage(context.Background(), proto, epoch, &p2pMsg)
+}
+
+// gossipSupportEpochs is how many epochs the gossip support of nodes is remembered for
+const gossipSupportEpochs = 4
+
+type gossipCapability struct {
+	peerID      peer.ID
+	known       bool
+	supported   bool
+	nodeVersion string
+}
+
+// gossipSupportCache remembers for each epoch the peer of every node and whether it negotiated gossip,
+// so that broadcasts do not look up every node again. Nodes whose handshake is pending are looked up
+// again until it completes.
+type gossipSupportCache struct {
+	lock   sync.Mutex
+	epochs map[int]map[ethCommon.Address]gossipCapability
+}
+
+var gossipSupport = &gossipSupportCache{epochs: make(map[int]map[ethCommon.Address]gossipCapability)}
+
+func (c *gossipSupportCache) get(epoch int, address ethCommon.Address) gossipCapability {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.epochs[epoch][address]
+}
+
+func (c *gossipSupportCache) set(epoch int, address ethCommon.Address, capability gossipCapability) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	nodes, ok := c.epochs[epoch]
+	if !ok {
+		// forget the oldest epoch
+		if len(c.epochs) >= gossipSupportEpochs {
+			oldest := epoch
+			for e := range c.epochs {
+				if e < oldest {
+					oldest = e
+				}
+			}
+			if oldest == epoch {
+				return
+			}
+			delete(c.epochs, oldest)
+		}
+		nodes = make(map[ethCommon.Address]gossipCapability)
+		c.epochs[epoch] = nodes
+	}
+	nodes[address] = capability
+}
+
+// checkGossipSupport returns an error if any of the nodes of epoch with pubKeys, other than this node,
+// did not negotiate gossip, such as nodes that predate it. Messages are then sent to each node instead.
+// Nodes whose handshake is pending are not yet known and do not prevent gossip, nodes that predate
+// gossip reply to the handshake as soon as they are connected.
+func checkGossipSupport(serviceLibrary ServiceLibrary, epoch int, pubKeys []common.Point) error {
+	self := serviceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
+	for _, pubKey := range pubKeys {
+		if pubKey.X.Cmp(&self.X) == 0 && pubKey.Y.Cmp(&self.Y) == 0 {
+			continue
+		}
+		address := *crypto.PointToEthAddress(pubKey)
+		capability := gossipSupport.get(epoch, address)
+		if !capability.known {
+			if capability.peerID == "" {
+				ethNodeDetails := serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), address)
+				peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
+				if err != nil {
+					return err
+				}
+				capability.peerID = *peerID
+			}
+			agreement, err := serviceLibrary.P2PMethods().PeerVersions(context.Background(), capability.peerID)
+			if err != nil && !isHandshakePending(err) {
+				return err
+			}
+			if err == nil {
+				capability.known = true
+				capability.supported = agreement.Has(version.CapabilityGossip)
+				capability.nodeVersion = agreement.NodeVersion
+			}
+			gossipSupport.set(epoch, address, capability)
+		}
+		if capability.known && !capability.supported {
+			return fmt.Errorf("peer %v running node version %v does not support gossip", capability.peerID.Pretty(), capability.nodeVersion)
+		}
+	}
+	return nil
+}
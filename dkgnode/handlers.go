@@ -1,6 +1,7 @@
 package dkgnode
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -37,7 +38,13 @@ const (
 )
 
 // Debug Handelers
-const ShareCountMethod = "ShareCount"
+const (
+	ShareCountMethod         = "ShareCount"
+	PeerScoresMethod         = "PeerScores"
+	PeerHealthMethod         = "PeerHealth"
+	EthEndpointsMethod       = "EthEndpoints"
+	RegistrationStatusMethod = "RegistrationStatus"
+)
 
 type (
 	PingHandler struct {
@@ -216,8 +223,8 @@ func (c *ConnectionDetailsMessage) Validate(pubKeyX, pubKeyY big.Int, sig []byte
 
 func (nodeSig *NodeSignature) NodeValidation(eventBus eventbus.Bus) (*NodeReference, error) {
 	var node *NodeReference
-	currentEpoch := NewServiceLibrary(eventBus, "node_validation").EthereumMethods().GetCurrentEpoch()
-	nodeList := NewServiceLibrary(eventBus, "node_validation").EthereumMethods().AwaitCompleteNodeList(currentEpoch)
+	currentEpoch := NewServiceLibrary(eventBus, "node_validation").EthereumMethods().GetCurrentEpoch(context.Background())
+	nodeList := NewServiceLibrary(eventBus, "node_validation").EthereumMethods().AwaitCompleteNodeList(context.Background(), currentEpoch)
 	for i, currNode := range nodeList {
 		logging.WithFields(logging.Fields{
 			"currNode": stringify(currNode),
@@ -234,7 +241,19 @@ func (nodeSig *NodeSignature) NodeValidation(eventBus eventbus.Bus) (*NodeRefere
 		return nil, fmt.Errorf("Node not found for nodeSig: %v", *nodeSig)
 	}
 	logging.WithField("node", stringify(node)).Debug("selected node")
+	if !nodeSig.verify(*node.PublicKey) {
+		return nil, fmt.Errorf("Could not validate ecdsa signature %v", nodeSig.Signature)
+	}
+
+	return node, nil
+}
+
+// verify checks that the signature over Data was made by pubKey, with V being 27 or 28 as in sigToHex
+func (nodeSig *NodeSignature) verify(pubKey common.Point) bool {
 	recSig := torusCrypto.HexToSig(nodeSig.Signature)
+	if recSig.V != 27 && recSig.V != 28 {
+		return false
+	}
 	var sig32 [32]byte
 	copy(sig32[:], secp256k1.Keccak256([]byte(nodeSig.Data))[:32])
 	recoveredSig := torusCrypto.Signature{
@@ -244,12 +263,7 @@ func (nodeSig *NodeSignature) NodeValidation(eventBus eventbus.Bus) (*NodeRefere
 		S:    recSig.S,
 		V:    recSig.V - 27,
 	}
-	valid := torusCrypto.IsValidSignature(*node.PublicKey, recoveredSig)
-	if !valid {
-		return nil, fmt.Errorf("Could not validate ecdsa signature %v", stringify(recoveredSig))
-	}
-
-	return node, nil
+	return torusCrypto.IsValidSignature(pubKey, recoveredSig)
 }
 
 func (p *CommitmentRequestParams) ToString() string {
@@ -276,7 +290,7 @@ func (c *CommitmentRequestResultData) ToString() string {
 func (c *CommitmentRequestResultData) FromString(data string) (bool, error) {
 	dataArray := strings.Split(data, pcmn.Delimiter1)
 
-	if len(dataArray) < 6 {
+	if len(dataArray) != 6 {
 		return false, errors.New("Could not parse commitmentrequestresultdata")
 	}
 	c.MessagePrefix = dataArray[0]
@@ -329,6 +343,18 @@ func setUpDebugHandler(eventBus eventbus.Bus) (*jsonrpc.MethodRepository, error)
 	if err := mr.RegisterMethod(ShareCountMethod, ShareCountHandler{eventBus}, ShareCountParams{}, ShareCountResult{}); err != nil {
 		return nil, err
 	}
+	if err := mr.RegisterMethod(PeerScoresMethod, PeerScoresHandler{eventBus}, PeerScoresParams{}, PeerScoresResult{}); err != nil {
+		return nil, err
+	}
+	if err := mr.RegisterMethod(PeerHealthMethod, PeerHealthHandler{eventBus}, PeerHealthParams{}, PeerHealthResult{}); err != nil {
+		return nil, err
+	}
+	if err := mr.RegisterMethod(EthEndpointsMethod, EthEndpointsHandler{eventBus}, EthEndpointsParams{}, EthEndpointsResult{}); err != nil {
+		return nil, err
+	}
+	if err := mr.RegisterMethod(RegistrationStatusMethod, RegistrationStatusHandler{eventBus}, RegistrationStatusParams{}, RegistrationStatusResult{}); err != nil {
+		return nil, err
+	}
 	return mr, nil
 }
 
@@ -338,9 +364,30 @@ func GETHealthz(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetBftStatus(w http.ResponseWriter, r *http.Request) {
-	status := serverServiceLibrary.TendermintMethods().GetStatus()
+	status := serverServiceLibrary.TendermintMethods().GetStatus(r.Context())
 	if status == BftRPCWSStatusUp {
 		w.WriteHeader(200)
 	}
 	w.WriteHeader(400)
 }
+
+// GetRegistrationStatus responds with the registration status of the node, with 200 once it is registered
+func GetRegistrationStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := serverServiceLibrary.EthereumMethods().GetRegistrationStatus(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	body, err := bijson.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if status.Registered {
+		w.WriteHeader(200)
+	} else {
+		w.WriteHeader(400)
+	}
+	w.Write(body)
+}
@@ -0,0 +1,48 @@
+package dkgnode
+
+import (
+	"encoding/hex"
+	"testing"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+func TestCommitmentSignatureVerifies(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := signer.NewMemorySigner(privKey)
+	pubKey := common.Point{X: *privKey.X, Y: *privKey.Y}
+	data := (&CommitmentRequestResultData{"mug00", "commitment", "1", "2", "google", "1577836800"}).ToString()
+	sig, err := signer.SignData(s, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeSig := NodeSignature{
+		Signature:   sigToHex(sig),
+		Data:        data,
+		NodePubKeyX: pubKey.X.Text(16),
+		NodePubKeyY: pubKey.Y.Text(16),
+	}
+	if !nodeSig.verify(pubKey) {
+		t.Fatal("expected the commitment signature to verify")
+	}
+	if sig[64] > 1 {
+		t.Fatalf("expected the signer to return V as 0 or 1, got %v", sig[64])
+	}
+
+	raw := nodeSig
+	raw.Signature = hex.EncodeToString(sig)
+	if raw.verify(pubKey) {
+		t.Fatal("expected a signature with V as 0 or 1 to be rejected")
+	}
+	tampered := nodeSig
+	tampered.Data = data + "0"
+	if tampered.verify(pubKey) {
+		t.Fatal("expected a signature over other data to be rejected")
+	}
+}
@@ -0,0 +1,234 @@
+package dkgnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	inet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	msmux "github.com/multiformats/go-multistream"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/crypto"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/framing"
+	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/version"
+)
+
+// handshakeProtocol is opened on every new connection to exchange the versions and capabilities of both nodes
+const handshakeProtocol = "/torus/handshake/1.0.0"
+
+const (
+	handshakeMaxMessageSize = 64 << 10
+	handshakeTimeout        = 30 * time.Second
+	// handshakeAttempts is how often a failed handshake is tried, waiting handshakeBackoff
+	// before the first retry and twice as long before each further one
+	handshakeAttempts = 5
+	handshakeBackoff  = time.Second
+)
+
+// errHandshakePending is returned for peers whose handshake has not completed, nothing is assumed about them
+var errHandshakePending = errors.New("handshake with peer has not completed")
+
+type peerHandshake struct {
+	agreement version.Agreement
+	err       error
+}
+
+// peerVersions negotiates versions with every peer this node connects to. Peers that predate
+// the handshake are assumed to speak the first version of every protocol without optional features.
+type peerVersions struct {
+	host  host.Host
+	local version.Hello
+
+	lock  sync.Mutex
+	peers map[peer.ID]peerHandshake
+}
+
+func newPeerVersions(h host.Host) *peerVersions {
+	pv := &peerVersions{
+		host:  h,
+		local: version.LocalHello(),
+		peers: make(map[peer.ID]peerHandshake),
+	}
+	h.SetStreamHandler(handshakeProtocol, pv.handleHandshake)
+	h.Network().Notify(&inet.NotifyBundle{
+		ConnectedF: func(n inet.Network, c inet.Conn) {
+			go pv.handshake(c.RemotePeer())
+		},
+		DisconnectedF: func(n inet.Network, c inet.Conn) {
+			if n.Connectedness(c.RemotePeer()) != inet.Connected {
+				pv.lock.Lock()
+				delete(pv.peers, c.RemotePeer())
+				pv.lock.Unlock()
+			}
+		},
+	})
+	return pv
+}
+
+func writeHello(s inet.Stream, hello version.Hello) error {
+	data, err := bijson.Marshal(hello)
+	if err != nil {
+		return err
+	}
+	return framing.NewWriter(s, handshakeMaxMessageSize, 0).WriteMessage(data)
+}
+
+func readHello(s inet.Stream) (version.Hello, error) {
+	var hello version.Hello
+	data, err := framing.NewReader(s, handshakeMaxMessageSize).ReadMessage()
+	if err != nil {
+		return hello, err
+	}
+	err = bijson.Unmarshal(data, &hello)
+	return hello, err
+}
+
+// handshake negotiates with id, retrying with backoff while the peer is connected and its hello
+// has not been received otherwise, such as by the handshake the peer opened
+func (pv *peerVersions) handshake(id peer.ID) {
+	backoff := handshakeBackoff
+	for attempt := 1; ; attempt++ {
+		err := pv.tryHandshake(id)
+		if err == nil {
+			return
+		}
+		if attempt == handshakeAttempts {
+			logging.WithField("peer", id.Pretty()).WithError(err).Warn("could not complete handshake, versions of peer remain unknown")
+			return
+		}
+		logging.WithFields(logging.Fields{
+			"peer":    id.Pretty(),
+			"attempt": attempt,
+		}).WithError(err).Debug("could not complete handshake, retrying")
+		nodeClock.Sleep(backoff)
+		backoff *= 2
+		if pv.host.Network().Connectedness(id) != inet.Connected || pv.known(id) {
+			return
+		}
+	}
+}
+
+// tryHandshake sends the hello of this node to id and negotiates with its reply
+func (pv *peerVersions) tryHandshake(id peer.ID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	s, err := pv.host.NewStream(ctx, id, handshakeProtocol)
+	if err == nil {
+		_ = s.SetDeadline(time.Now().Add(handshakeTimeout))
+		err = writeHello(s, pv.local)
+	}
+	var remote version.Hello
+	if err == nil {
+		remote, err = readHello(s)
+	}
+	if s != nil {
+		if err != nil {
+			_ = s.Reset()
+		} else {
+			_ = s.Close()
+		}
+	}
+	if err == msmux.ErrNotSupported {
+		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.LegacyPeerCounter, pcmn.TelemetryConstants.P2P.Prefix)
+		pv.set(id, version.LegacyHello())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pv.set(id, remote)
+	return nil
+}
+
+// handleHandshake replies to the hello of a peer with the hello of this node
+func (pv *peerVersions) handleHandshake(s inet.Stream) {
+	_ = s.SetDeadline(time.Now().Add(handshakeTimeout))
+	remote, err := readHello(s)
+	if err == nil {
+		err = writeHello(s, pv.local)
+	}
+	if err != nil {
+		logging.WithField("peer", s.Conn().RemotePeer().Pretty()).WithError(err).Debug("could not reply to handshake")
+		_ = s.Reset()
+		return
+	}
+	_ = s.Close()
+	pv.set(s.Conn().RemotePeer(), remote)
+}
+
+func (pv *peerVersions) set(id peer.ID, remote version.Hello) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.HandshakeCounter, pcmn.TelemetryConstants.P2P.Prefix)
+	agreement, err := version.Negotiate(pv.local, remote)
+	if err != nil {
+		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.IncompatiblePeerCounter, pcmn.TelemetryConstants.P2P.Prefix)
+		logging.WithFields(logging.Fields{
+			"peer":        id.Pretty(),
+			"nodeVersion": remote.NodeVersion,
+		}).WithError(err).Error("peer is incompatible with this node, messages to it will be refused")
+	} else {
+		logging.WithFields(logging.Fields{
+			"peer":         id.Pretty(),
+			"nodeVersion":  remote.NodeVersion,
+			"versions":     agreement.Versions,
+			"capabilities": agreement.Capabilities,
+		}).Debug("negotiated versions with peer")
+	}
+	pv.lock.Lock()
+	pv.peers[id] = peerHandshake{agreement: agreement, err: err}
+	pv.lock.Unlock()
+}
+
+// known reports whether the handshake with id has completed
+func (pv *peerVersions) known(id peer.ID) bool {
+	pv.lock.Lock()
+	defer pv.lock.Unlock()
+	_, ok := pv.peers[id]
+	return ok
+}
+
+// isHandshakePending reports whether err is errHandshakePending, also once it was passed through the event bus
+func isHandshakePending(err error) bool {
+	return err != nil && err.Error() == errHandshakePending.Error()
+}
+
+// negotiatedVersion returns the version of protocol negotiated with the node of pubKey, "" for the
+// latest version if it is this node or its version is not known
+func negotiatedVersion(serviceLibrary ServiceLibrary, pubKey common.Point, protocol string) string {
+	self := serviceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
+	if pubKey.X.Cmp(&self.X) == 0 && pubKey.Y.Cmp(&self.Y) == 0 {
+		return ""
+	}
+	ethNodeDetails := serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(pubKey))
+	peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
+	if err != nil {
+		return ""
+	}
+	agreement, err := serviceLibrary.P2PMethods().PeerVersions(context.Background(), *peerID)
+	if err != nil {
+		return ""
+	}
+	return agreement.Version(protocol)
+}
+
+// get returns what was negotiated with id, errHandshakePending if the handshake has not
+// completed, or an error describing why the peer is incompatible
+func (pv *peerVersions) get(id peer.ID) (version.Agreement, error) {
+	pv.lock.Lock()
+	handshake, ok := pv.peers[id]
+	pv.lock.Unlock()
+	if !ok {
+		return version.Agreement{}, errHandshakePending
+	}
+	if handshake.err != nil {
+		return handshake.agreement, fmt.Errorf("peer %v running node version %v is incompatible: %v", id.Pretty(), handshake.agreement.NodeVersion, handshake.err)
+	}
+	return handshake.agreement, nil
+}
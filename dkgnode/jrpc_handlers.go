@@ -12,6 +12,7 @@ import (
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/dealer"
+	"github.com/torusresearch/torus-node/secret"
 	"github.com/torusresearch/torus-node/telemetry"
 
 	tronCrypto "github.com/TRON-US/go-eccrypto"
@@ -48,7 +49,7 @@ func (h CommitmentRequestHandler) ServeJSONRPC(c context.Context, params *bijson
 		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Incorrect message prefix"}
 	}
 
-	found := serviceLibrary.CacheMethods().TokenCommitExists(verifierIdentifier, tokenCommitment)
+	found := serviceLibrary.CacheMethods().TokenCommitExists(c, verifierIdentifier, tokenCommitment)
 	if found {
 		// allow for loadtesting in debug mode
 		if !config.GlobalConfig.IsDebug {
@@ -65,7 +66,7 @@ func (h CommitmentRequestHandler) ServeJSONRPC(c context.Context, params *bijson
 		Y: *common.HexToBigInt(p.TempPubY),
 	}
 
-	serviceLibrary.CacheMethods().RecordTokenCommit(verifierIdentifier, tokenCommitment, tempPubKey)
+	serviceLibrary.CacheMethods().RecordTokenCommit(c, verifierIdentifier, tokenCommitment, tempPubKey)
 
 	// sign data
 	commitmentRequestResultData := CommitmentRequestResultData{
@@ -79,11 +80,10 @@ func (h CommitmentRequestHandler) ServeJSONRPC(c context.Context, params *bijson
 
 	logging.WithField("CURRENTTIME", strconv.FormatInt(time.Now().Unix(), 10)).Debug()
 
-	k := serviceLibrary.EthereumMethods().GetSelfPrivateKey()
-	pk := serviceLibrary.EthereumMethods().GetSelfPublicKey()
-	sig := crypto.SignData([]byte(commitmentRequestResultData.ToString()), crypto.BigIntToECDSAPrivateKey(k))
+	pk := serviceLibrary.EthereumMethods().GetSelfPublicKey(c)
+	sig := serviceLibrary.EthereumMethods().SelfSignData(c, []byte(commitmentRequestResultData.ToString()))
 	res := CommitmentRequestResult{
-		Signature: crypto.SigToHex(sig),
+		Signature: sigToHex(sig),
 		Data:      commitmentRequestResultData.ToString(),
 		NodePubX:  pk.X.Text(16),
 		NodePubY:  pk.Y.Text(16),
@@ -92,6 +92,16 @@ func (h CommitmentRequestHandler) ServeJSONRPC(c context.Context, params *bijson
 	return res, nil
 }
 
+// sigToHex encodes a signature of the signer as crypto.SigToHex does, with V being 27 or 28 instead of 0 or 1
+func sigToHex(sig []byte) string {
+	ethSig := make([]byte, len(sig))
+	copy(ethSig, sig)
+	if len(ethSig) == 65 {
+		ethSig[64] += 27
+	}
+	return hex.EncodeToString(ethSig)
+}
+
 func (h PingHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.JRPC.PingRequestCounter, pcmn.TelemetryConstants.JRPC.Prefix)
 
@@ -101,7 +111,7 @@ func (h PingHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage)
 		return nil, err
 	}
 	return PingResult{
-		Message: serviceLibrary.EthereumMethods().GetSelfAddress().Hex(),
+		Message: serviceLibrary.EthereumMethods().GetSelfAddress(c).Hex(),
 	}, nil
 }
 
@@ -113,7 +123,7 @@ func (h ConnectionDetailsHandler) ServeJSONRPC(c context.Context, params *bijson
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
 	}
-	if !serviceLibrary.EthereumMethods().ValidateEpochPubKey(p.ConnectionDetailsMessage.NodeAddress, common.Point{X: p.PubKeyX, Y: p.PubKeyY}) {
+	if !serviceLibrary.EthereumMethods().ValidateEpochPubKey(c, p.ConnectionDetailsMessage.NodeAddress, common.Point{X: p.PubKeyX, Y: p.PubKeyY}) {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Invalid pub key for provided epoch"}
 	}
 	valid, err := p.ConnectionDetailsMessage.Validate(p.PubKeyX, p.PubKeyY, p.Signature)
@@ -124,8 +134,8 @@ func (h ConnectionDetailsHandler) ServeJSONRPC(c context.Context, params *bijson
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "invalid connection details message"}
 	}
 	return ConnectionDetailsResult{
-		TMP2PConnection: serviceLibrary.EthereumMethods().GetTMP2PConnection(),
-		P2PConnection:   serviceLibrary.EthereumMethods().GetP2PConnection(),
+		TMP2PConnection: serviceLibrary.EthereumMethods().GetTMP2PConnection(c),
+		P2PConnection:   serviceLibrary.EthereumMethods().GetP2PConnection(c),
 	}, nil
 }
 
@@ -135,8 +145,8 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.JRPC.ShareRequestCounter, pcmn.TelemetryConstants.JRPC.Prefix)
 
 	serviceLibrary := NewServiceLibrary(h.eventBus, "share_request_handler")
-	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch()
-	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(currEpoch, false)
+	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch(c)
+	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(c, currEpoch, false)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while current epoch"}
 	}
@@ -172,7 +182,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while marshalling" + err.Error()}
 		}
-		verified, verifierID, err := serviceLibrary.VerifierMethods().Verify((*bijson.RawMessage)(&redactedRawItem))
+		verified, verifierID, err := serviceLibrary.VerifierMethods().Verify(c, (*bijson.RawMessage)(&redactedRawItem))
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying params" + err.Error()}
 		}
@@ -206,6 +216,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
 			if !ok || err != nil {
 				logging.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
+				continue
 			}
 			stringData := strings.Join([]string{
 				commitmentRequestResultData.MessagePrefix,
@@ -227,6 +238,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
 			if !ok || err != nil {
 				logging.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
+				continue
 			}
 			stringData := strings.Join([]string{
 				commitmentRequestResultData.MessagePrefix,
@@ -252,7 +264,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 
 		// Lookup verifier and
 		// verify that hash of token = tokenCommitment
-		cleanedToken, err := serviceLibrary.VerifierMethods().CleanToken(commonVerifierIdentifier, parsedVerifierParams.IDToken)
+		cleanedToken, err := serviceLibrary.VerifierMethods().CleanToken(c, commonVerifierIdentifier, parsedVerifierParams.IDToken)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error when cleaning token " + err.Error()}
 		}
@@ -260,7 +272,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Token commitment and token are not compatible"}
 		}
 
-		keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(commonVerifierIdentifier, verifierID)
+		keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(c, commonVerifierIdentifier, verifierID)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("share request could not retrieve keyIndexes: %v", err)}
 		}
@@ -270,7 +282,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			allKeyIndexes[index.Text(16)] = index
 		}
 
-		pubKey = serviceLibrary.CacheMethods().GetTokenCommitKey(commonVerifierIdentifier, commonTokenCommitment)
+		pubKey = serviceLibrary.CacheMethods().GetTokenCommitKey(c, commonVerifierIdentifier, commonTokenCommitment)
 
 		allValidVerifierIDs[strings.Join([]string{parsedVerifierParams.VerifierIdentifier, verifierID}, pcmn.Delimiter1)] = true
 	}
@@ -285,7 +297,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 	})
 	for _, index := range allKeyIndexesSorted {
 		// check if we have enough validTokens according to Access Structure
-		pubKeyAccessStructure, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(index)
+		pubKeyAccessStructure, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(c, index)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not retrieve access structure: %v", err)}
 		}
@@ -301,20 +313,23 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			}
 		}
 
-		si, _, err := serviceLibrary.DatabaseMethods().RetrieveCompletedShare(index)
+		si, siprime, err := serviceLibrary.DatabaseMethods().RetrieveCompletedShare(c, index)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not retrieve completed share"}
 		}
+		share := secret.NewInt(si)
+		secret.Wipe(si, siprime)
 
 		if validCount >= pubKeyAccessStructure.Threshold { // if we have enough authenticators we return Si
 
 			keyAssignment := KeyAssignment{
 				KeyAssignmentPublic: pubKeyAccessStructure,
-				Share:               si.Bytes(),
+				Share:               share.Bytes(),
 			}
 			if config.GlobalMutableConfig.GetB("EncryptShares") {
 				pubKeyHex := "04" + fmt.Sprintf("%064s", pubKey.X.Text(16)) + fmt.Sprintf("%064s", pubKey.Y.Text(16))
 				encrypted, metadata, err := tronCrypto.Encrypt(pubKeyHex, keyAssignment.Share)
+				secret.WipeBytes(keyAssignment.Share)
 				if err != nil {
 					return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not encrypt shares with err: %v", err)}
 				}
@@ -328,6 +343,7 @@ func (h ShareRequestHandler) ServeJSONRPC(c context.Context, params *bijson.RawM
 			}
 			response.Keys = append(response.Keys, keyAssignment)
 		}
+		share.Wipe()
 	}
 	return response, nil
 }
@@ -344,7 +360,7 @@ func assignKey(c context.Context, eventBus eventbus.Bus, verifier string, verifi
 
 	logging.Debug("checking if verifier is supported")
 	// check if verifier is valid
-	verifiers := serviceLibrary.VerifierMethods().ListVerifiers()
+	verifiers := serviceLibrary.VerifierMethods().ListVerifiers(c)
 	found := false
 	for _, v := range verifiers {
 		if v == verifier {
@@ -361,7 +377,7 @@ func assignKey(c context.Context, eventBus eventbus.Bus, verifier string, verifi
 	// new assignment
 	// broadcast assignment transaction
 	assMsg := AssignmentBFTTx{VerifierID: verifierID, Verifier: verifier}
-	hash, err := serviceLibrary.TendermintMethods().Broadcast(assMsg)
+	hash, err := serviceLibrary.TendermintMethods().Broadcast(c, assMsg)
 	if err != nil {
 		return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
 	}
@@ -371,7 +387,7 @@ func assignKey(c context.Context, eventBus eventbus.Bus, verifier string, verifi
 	query := tmquery.MustParse("tx.hash='" + hash.String() + "'")
 	logging.WithField("queryString", query.String()).Debug("BFTWS")
 
-	responseCh, err := serviceLibrary.TendermintMethods().RegisterQuery(query.String(), 1)
+	responseCh, err := serviceLibrary.TendermintMethods().RegisterQuery(c, query.String(), 1)
 	if err != nil {
 		logging.WithField("queryString", query.String()).Debug("BFTWS could not register query")
 	}
@@ -424,7 +440,7 @@ func assignKey(c context.Context, eventBus eventbus.Bus, verifier string, verifi
 func retrieveKeysFromVerifierID(c context.Context, eventBus eventbus.Bus, verifier string, verifierID string) ([]KeyAssignItem, *jsonrpc.Error) {
 	serviceLibrary := NewServiceLibrary(eventBus, "key_assign_handler")
 
-	keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(verifier, verifierID)
+	keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(c, verifier, verifierID)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("Unable to retieve keyIndexes error: %v", err)}
 	}
@@ -432,7 +448,7 @@ func retrieveKeysFromVerifierID(c context.Context, eventBus eventbus.Bus, verifi
 	var keys []KeyAssignItem
 
 	for _, index := range keyIndexes {
-		pk, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(index)
+		pk, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(c, index)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: fmt.Sprintf("Could not find address to key index error: %v", err)}
 		}
@@ -484,7 +500,7 @@ func (h VerifierLookupHandler) ServeJSONRPC(c context.Context, params *bijson.Ra
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "VerifierID is empty"}
 	}
 	// check if verifier is valid
-	verifiers := serviceLibrary.VerifierMethods().ListVerifiers()
+	verifiers := serviceLibrary.VerifierMethods().ListVerifiers(c)
 	found := false
 	for _, verifier := range verifiers {
 		if verifier == p.Verifier {
@@ -495,7 +511,7 @@ func (h VerifierLookupHandler) ServeJSONRPC(c context.Context, params *bijson.Ra
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Verifier not supported"}
 	}
 	// retrieve index
-	keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(p.Verifier, p.VerifierID)
+	keyIndexes, err := serviceLibrary.ABCIMethods().GetIndexesFromVerifierID(c, p.Verifier, p.VerifierID)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Input Error", Data: fmt.Sprintf("Verifier + VerifierID has not yet been assigned %v", err)}
 	}
@@ -503,7 +519,7 @@ func (h VerifierLookupHandler) ServeJSONRPC(c context.Context, params *bijson.Ra
 	// prepare and send response
 	result := VerifierLookupResult{}
 	for _, index := range keyIndexes {
-		publicKeyAss, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(index)
+		publicKeyAss, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(c, index)
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: fmt.Sprintf("Could not find address to key index error: %v", err)}
 		}
@@ -537,11 +553,11 @@ func (h KeyLookupHandler) ServeJSONRPC(c context.Context, params *bijson.RawMess
 	pubKey := common.BigIntToPoint(&p.PubKeyX, &p.PubKeyY)
 
 	// prepare and send response
-	keyIndex, err := serviceLibrary.DatabaseMethods().RetrievePublicKeyToIndex(pubKey)
+	keyIndex, err := serviceLibrary.DatabaseMethods().RetrievePublicKeyToIndex(c, pubKey)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "no log of public key"}
 	}
-	keyAssignmentPublic, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(keyIndex)
+	keyAssignmentPublic, err := serviceLibrary.ABCIMethods().RetrieveKeyMapping(c, keyIndex)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "no key assignment found for public key"}
 	}
@@ -558,7 +574,7 @@ func (h UpdatePublicKeyHandler) ServeJSONRPC(c context.Context, params *bijson.R
 		return nil, err
 	}
 
-	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(dealerMessage.KeyIndex)
+	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(c, dealerMessage.KeyIndex)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while getting the existing public key"}
 	}
@@ -566,7 +582,7 @@ func (h UpdatePublicKeyHandler) ServeJSONRPC(c context.Context, params *bijson.R
 		return nil, &jsonrpc.Error{Code: -32602, Message: "validation error", Data: "invalid dealer message"}
 	}
 
-	_, err = serviceLibrary.TendermintMethods().Broadcast(dealerMessage)
+	_, err = serviceLibrary.TendermintMethods().Broadcast(c, dealerMessage)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while doing the tendermint broadcast"}
 	}
@@ -586,7 +602,7 @@ func (h UpdateShareHandler) ServeJSONRPC(c context.Context, params *bijson.RawMe
 		return nil, err
 	}
 
-	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(dealerMessage.KeyIndex)
+	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(c, dealerMessage.KeyIndex)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while getting the existing public key"}
 	}
@@ -603,7 +619,7 @@ func (h UpdateShareHandler) ServeJSONRPC(c context.Context, params *bijson.RawMe
 		return nil, &jsonrpc.Error{Code: -32602, Message: "validation error", Data: "invalid dealer.MsgUpdateShare"}
 	}
 
-	err = serviceLibrary.DatabaseMethods().StoreCompletedPSSShare(updateShareMessage.KeyIndex, updateShareMessage.Si, updateShareMessage.Siprime)
+	err = serviceLibrary.DatabaseMethods().StoreCompletedPSSShare(c, updateShareMessage.KeyIndex, updateShareMessage.Si, updateShareMessage.Siprime)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while storing completed PSS share"}
 	}
@@ -623,7 +639,7 @@ func (h UpdateCommitmentHandler) ServeJSONRPC(c context.Context, params *bijson.
 		return nil, err
 	}
 
-	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(dealerMessage.KeyIndex)
+	existingPubKey, err := serviceLibrary.DatabaseMethods().RetrieveIndexToPublicKey(c, dealerMessage.KeyIndex)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while getting the existing public key"}
 	}
@@ -642,7 +658,7 @@ func (h UpdateCommitmentHandler) ServeJSONRPC(c context.Context, params *bijson.
 		return nil, &jsonrpc.Error{Code: -32602, Message: "validation error", Data: "invalid dealer.MsgUpdateCommitment"}
 	}
 
-	err = serviceLibrary.DatabaseMethods().StorePSSCommitmentMatrix(updateCommitmentMessage.KeyIndex, updateCommitmentMessage.Commitment)
+	err = serviceLibrary.DatabaseMethods().StorePSSCommitmentMatrix(c, updateCommitmentMessage.KeyIndex, updateCommitmentMessage.Commitment)
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32602, Message: err.Error(), Data: "error while storing the commitment matrix"}
 	}
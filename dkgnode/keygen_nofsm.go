@@ -3,7 +3,6 @@ package dkgnode
 import (
 	"context"
 	"errors"
-	"fmt"
 	"math/big"
 	"strconv"
 
@@ -18,8 +17,9 @@ import (
 	"github.com/torusresearch/torus-node/eventbus"
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/keygennofsm"
-	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/secret"
 	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/version"
 )
 
 type KeygenProtocolPrefix string
@@ -49,16 +49,16 @@ func (k *KeygennofsmService) Name() string {
 }
 func (k *KeygennofsmService) OnStart() error {
 	serviceLibrary := NewServiceLibrary(k.eventBus, "keygennofsm")
-	selfIndex := serviceLibrary.EthereumMethods().GetSelfIndex()
-	selfPubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey()
-	currNodeList := serviceLibrary.EthereumMethods().AwaitCompleteNodeList(config.GlobalConfig.InitEpoch)
-	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch()
-	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(currEpoch, true)
+	selfIndex := serviceLibrary.EthereumMethods().GetSelfIndex(k.ctx)
+	selfPubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey(k.ctx)
+	currNodeList := serviceLibrary.EthereumMethods().AwaitCompleteNodeList(k.ctx, config.GlobalConfig.InitEpoch)
+	currEpoch := abciServiceLibrary.EthereumMethods().GetCurrentEpoch(k.ctx)
+	currEpochInfo, err := abciServiceLibrary.EthereumMethods().GetEpochInfo(k.ctx, currEpoch, true)
 	if err != nil {
 		return err
 	}
 
-	k.KeygenNode = keygennofsm.NewKeygenNode(
+	keygenNode := keygennofsm.NewKeygenNode(
 		pcmn.Node{
 			Index:  selfIndex,
 			PubKey: selfPubKey,
@@ -69,64 +69,59 @@ func (k *KeygennofsmService) OnStart() error {
 		selfIndex,
 		&DKGKeygennofsmTransport{
 			Prefix:   k.GetKeygenProtocolPrefix(config.GlobalConfig.InitEpoch),
+			Epoch:    config.GlobalConfig.InitEpoch,
 			eventBus: k.eventBus,
 		},
 		config.GlobalConfig.StaggerDelay,
 	)
+	keygenNode.Clock = nodeClock
+	keygenNode.Entropy = nodeEntropy
+	k.KeygenNode = keygenNode
 
 	return nil
 }
 func (k *KeygennofsmService) OnStop() error {
 	return nil
 }
-func (k *KeygennofsmService) Call(method string, args ...interface{}) (interface{}, error) {
+func (k *KeygennofsmService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.KeygenTotalCallCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 
-	serviceLibrary := NewServiceLibrary(k.eventBus, "keygennofsm")
-
-	switch method {
-
-	case "receive_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ReceiveMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
+	return dispatchKeygennofsm(ctx, k, method, args)
+}
 
-		var args0 keygennofsm.KeygenMessage
-		_ = castOrUnmarshal(args[0], &args0)
+func (k *KeygennofsmService) handleReceiveMessage(ctx context.Context, keygenMessage keygennofsm.KeygenMessage) error {
+	ctx = withSpanOf(k.ctx, ctx)
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ReceiveMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 
-		keygenMessage := args0
-		// Only run keygen once even if the share message is sent multiple times
-		if keygenMessage.Method == "share" {
-			k.Lock()
-			defer k.Unlock()
-			if serviceLibrary.DatabaseMethods().GetKeygenStarted(string(keygenMessage.KeygenID)) {
-				logging.WithField("keygenID", keygenMessage.KeygenID).Info("Keygen already started")
-				return nil, nil
-			}
-			err := serviceLibrary.DatabaseMethods().SetKeygenStarted(string(keygenMessage.KeygenID), true)
-			if err != nil {
-				return nil, err
-			}
+	serviceLibrary := NewServiceLibrary(k.eventBus, "keygennofsm")
+	// Only run keygen once even if the share message is sent multiple times
+	if keygenMessage.Method == "share" {
+		k.Lock()
+		defer k.Unlock()
+		if serviceLibrary.DatabaseMethods().GetKeygenStarted(ctx, string(keygenMessage.KeygenID)) {
+			logging.WithField("keygenID", keygenMessage.KeygenID).Info("Keygen already started")
+			return nil
 		}
-		selfPubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey()
-		selfIndex := serviceLibrary.EthereumMethods().GetSelfIndex()
-		selfNode := pcmn.Node{
-			PubKey: selfPubKey,
-			Index:  selfIndex,
+		err := serviceLibrary.DatabaseMethods().SetKeygenStarted(ctx, string(keygenMessage.KeygenID), true)
+		if err != nil {
+			return err
 		}
+	}
+	selfPubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey(ctx)
+	selfIndex := serviceLibrary.EthereumMethods().GetSelfIndex(ctx)
+	selfNode := pcmn.Node{
+		PubKey: selfPubKey,
+		Index:  selfIndex,
+	}
 
-		return nil, k.KeygenNode.Transport.Receive(keygennofsm.NodeDetails(selfNode), args0)
-
-	case "receive_BFT_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
-
-		var args0 keygennofsm.KeygenMessage
-		_ = castOrUnmarshal(args[0], &args0)
-
-		return nil, k.KeygenNode.Transport.ReceiveBroadcast(args0)
+	return k.KeygenNode.Transport.Receive(keygennofsm.NodeDetails(selfNode), keygenMessage)
+}
 
-	}
+func (k *KeygennofsmService) handleReceiveBFTMessage(ctx context.Context, keygenMessage keygennofsm.KeygenMessage) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 
-	return nil, fmt.Errorf("keygen service method %v not found", method)
+	return k.KeygenNode.Transport.ReceiveBroadcast(keygenMessage)
 }
 func (k *KeygennofsmService) SetBaseService(bs *BaseService) {
 	k.bs = bs
@@ -139,6 +134,7 @@ type DKGKeygennofsmTransport struct {
 	eventBus eventbus.Bus
 
 	Prefix     KeygenProtocolPrefix
+	Epoch      int
 	KeygenNode *keygennofsm.KeygenNode
 }
 
@@ -148,7 +144,7 @@ func (tp *DKGKeygennofsmTransport) streamHandler(streamMessage StreamMessage) {
 
 	// get request data
 	p2pBasicMsg := streamMessage.Message
-	if NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").P2PMethods().AuthenticateMessage(p2pBasicMsg) != nil {
+	if NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").P2PMethods().AuthenticateMessage(context.Background(), p2pBasicMsg) != nil {
 		logging.WithField("p2pBasicMsg", p2pBasicMsg).Error("could not authenticate incoming p2pBasicMsg in keygennofsm")
 		return
 	}
@@ -166,7 +162,7 @@ func (tp *DKGKeygennofsmTransport) streamHandler(streamMessage StreamMessage) {
 		return
 	}
 	logging.Debugf("able to get pubk X: %s, Y: %s", pubKey.X.Text(16), pubKey.Y.Text(16))
-	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(pubKey))
+	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(pubKey))
 	index := int(ethNodeDetails.Index.Int64())
 	go func(ind int, pubK common.Point, keygenMsg keygennofsm.KeygenMessage) {
 		err := tp.Receive(keygennofsm.NodeDetails(pcmn.Node{
@@ -181,10 +177,14 @@ func (tp *DKGKeygennofsmTransport) streamHandler(streamMessage StreamMessage) {
 }
 
 func (tp *DKGKeygennofsmTransport) Init() {
-	err := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").P2PMethods().SetStreamHandler(string(tp.Prefix), tp.streamHandler)
+	err := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").P2PMethods().SetStreamHandler(context.Background(), string(tp.Prefix), tp.streamHandler)
 	if err != nil {
 		logging.WithField("DKGPSSTransportPrefix", tp.Prefix).WithError(err).Error("could not set stream handler")
 	}
+	err = NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").P2PMethods().JoinGossipTopic(context.Background(), string(tp.Prefix), tp.Epoch)
+	if err != nil {
+		logging.WithField("DKGKeygenTransportPrefix", tp.Prefix).WithError(err).Error("could not join gossip topic")
+	}
 }
 func (tp *DKGKeygennofsmTransport) GetType() string {
 	return "dkgkeygen"
@@ -194,8 +194,7 @@ func (tp *DKGKeygennofsmTransport) SetKeygenNode(keygenNode *keygennofsm.KeygenN
 	return nil
 }
 func (tp *DKGKeygennofsmTransport) Sign(s []byte) ([]byte, error) {
-	k := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").EthereumMethods().GetSelfPrivateKey()
-	return pvss.ECDSASignBytes(s, &k), nil
+	return NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").EthereumMethods().SelfSignData(context.Background(), s), nil
 }
 func (tp *DKGKeygennofsmTransport) Send(nodeDetails keygennofsm.NodeDetails, keygenMessage keygennofsm.KeygenMessage) error {
 
@@ -203,13 +202,12 @@ func (tp *DKGKeygennofsmTransport) Send(nodeDetails keygennofsm.NodeDetails, key
 	logging.WithFields(logging.Fields{
 		"to":      stringify(nodeDetails),
 		"message": stringify(keygenMessage),
-		"data":    string(keygenMessage.Data),
 	}).Debug("trying to send message")
 	// get recipient details
-	ethNodeDetails := serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(nodeDetails.PubKey))
+	ethNodeDetails := serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(nodeDetails.PubKey))
 
 	logging.WithField("ethNodeDetails", ethNodeDetails).Debug("managed to get ethNodeDetails")
-	pubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey()
+	pubKey := serviceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
 	if nodeDetails.PubKey.X.Cmp(&pubKey.X) == 0 && nodeDetails.PubKey.Y.Cmp(&pubKey.Y) == 0 {
 		return tp.Receive(nodeDetails, keygenMessage)
 	}
@@ -218,19 +216,19 @@ func (tp *DKGKeygennofsmTransport) Send(nodeDetails keygennofsm.NodeDetails, key
 	if err != nil {
 		return err
 	}
-	p2pMsg := serviceLibrary.P2PMethods().NewP2PMessage(HashToString(byt), false, byt, "transportKeygenMessage")
+	p2pMsg := serviceLibrary.P2PMethods().NewP2PMessage(context.Background(), HashToString(byt), false, byt, "transportKeygenMessage")
 	peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
 	if err != nil {
 		return err
 	}
 	// sign the data
-	signature, err := serviceLibrary.P2PMethods().SignP2PMessage(&p2pMsg)
+	signature, err := serviceLibrary.P2PMethods().SignP2PMessage(context.Background(), &p2pMsg)
 	if err != nil {
 		return errors.New("failed to sign p2p Message" + err.Error())
 	}
 	p2pMsg.Sign = signature
 	err = retry.Do(func() error {
-		err := serviceLibrary.P2PMethods().SendP2PMessage(*peerID, protocol.ID(tp.Prefix), &p2pMsg)
+		err := serviceLibrary.P2PMethods().SendP2PMessage(context.Background(), *peerID, protocol.ID(tp.Prefix), &p2pMsg)
 		if err != nil {
 			logging.WithFields(logging.Fields{
 				"peerID":     peerID,
@@ -249,11 +247,42 @@ func (tp *DKGKeygennofsmTransport) Send(nodeDetails keygennofsm.NodeDetails, key
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.SentMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 	return nil
 }
+
+// MessageVersion implements keygennofsm.KeygenVersionNegotiator
+func (tp *DKGKeygennofsmTransport) MessageVersion(nodeDetails keygennofsm.NodeDetails) string {
+	return negotiatedVersion(NewServiceLibrary(tp.eventBus, "dkgKeygenTransport"), nodeDetails.PubKey, version.ProtocolKeygen)
+}
+
+// Gossip implements keygennofsm.KeygenGossipTransport, every node in the keygen is in the epoch of the transport
+func (tp *DKGKeygennofsmTransport) Gossip(nodeNetwork keygennofsm.NodeNetwork, keygenMessage keygennofsm.KeygenMessage) error {
+	serviceLibrary := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport")
+	var pubKeys []common.Point
+	for _, node := range nodeNetwork.Nodes {
+		pubKeys = append(pubKeys, node.PubKey)
+	}
+	if err := checkGossipSupport(serviceLibrary, tp.Epoch, pubKeys); err != nil {
+		return err
+	}
+	byt, err := bijson.Marshal(keygenMessage)
+	if err != nil {
+		return err
+	}
+	err = gossipP2PMessage(serviceLibrary, string(tp.Prefix), tp.Epoch, byt, "transportKeygenMessage")
+	if err != nil {
+		return err
+	}
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.SentMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
+	return nil
+}
+
+// ReportInvalidMessage implements keygennofsm.KeygenMisbehaviourReporter
+func (tp *DKGKeygennofsmTransport) ReportInvalidMessage(sender keygennofsm.NodeDetails, reason string) {
+	reportNode(NewServiceLibrary(tp.eventBus, "dkgKeygenTransport"), sender.PubKey, offenceInvalidProtocol, "keygen "+reason)
+}
 func (tp *DKGKeygennofsmTransport) Receive(senderDetails keygennofsm.NodeDetails, keygenMessage keygennofsm.KeygenMessage) error {
 	logging.WithFields(logging.Fields{
 		"senderDetails": stringify(senderDetails),
 		"message":       stringify(keygenMessage),
-		"data":          stringify(keygenMessage.Data),
 	}).Debug("message received")
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.ReceivedMessageCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 
@@ -262,9 +291,8 @@ func (tp *DKGKeygennofsmTransport) Receive(senderDetails keygennofsm.NodeDetails
 func (tp *DKGKeygennofsmTransport) SendBroadcast(keygenMessage keygennofsm.KeygenMessage) error {
 	logging.WithFields(logging.Fields{
 		"pssMessage": stringify(keygenMessage),
-		"data":       string(keygenMessage.Data),
 	}).Debug("sending broadcast")
-	_, err := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").TendermintMethods().Broadcast(keygenMessage)
+	_, err := NewServiceLibrary(tp.eventBus, "dkgKeygenTransport").TendermintMethods().Broadcast(context.Background(), keygenMessage)
 
 	if err == nil {
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Broadcast.SentBroadcastCounter, pcmn.TelemetryConstants.Keygen.Prefix)
@@ -276,7 +304,6 @@ func (tp *DKGKeygennofsmTransport) SendBroadcast(keygenMessage keygennofsm.Keyge
 func (tp *DKGKeygennofsmTransport) ReceiveBroadcast(keygenMessage keygennofsm.KeygenMessage) error {
 	logging.WithFields(logging.Fields{
 		"pssMessage": stringify(keygenMessage),
-		"data":       string(keygenMessage.Data),
 	}).Debug("revived broadcast")
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Broadcast.ReceivedBroadcastCounter, pcmn.TelemetryConstants.Keygen.Prefix)
 
@@ -292,6 +319,7 @@ func (tp *DKGKeygennofsmTransport) Output(inter interface{}) {
 	}
 	keyStorage, ok := inter.(keygennofsm.KeyStorage)
 	if ok {
+		defer keyStorage.Wipe()
 		if len(keyStorage.CommitmentPoly) == 0 || keyStorage.CommitmentPoly[0].X.Cmp(big.NewInt(0)) == 0 {
 			logging.WithField("keystorage", keyStorage).Error("Invalid keystorage commitment poly")
 			return
@@ -305,18 +333,19 @@ func (tp *DKGKeygennofsmTransport) Output(inter interface{}) {
 			}
 			fakeCommitmentMatrix = append(fakeCommitmentMatrix, fakePoly)
 		}
-		err := serviceLibrary.DatabaseMethods().StoreKeygenCommitmentMatrix(keyStorage.KeyIndex, fakeCommitmentMatrix)
-		if err != nil {
-			logging.WithField("keyStorage", keyStorage).Error("Could not store commitment matrix")
-		}
-		err = serviceLibrary.DatabaseMethods().StoreCompletedKeygenShare(
+		// the database gets its own copy of the share, which it may still use after a timeout
+		si, siprime := new(big.Int).Set(keyStorage.Si.Value()), new(big.Int).Set(keyStorage.Siprime.Value())
+		err := serviceLibrary.DatabaseMethods().StoreCompletedKeygen(context.Background(),
 			keyStorage.KeyIndex,
-			keyStorage.Si,
-			keyStorage.Siprime,
+			fakeCommitmentMatrix,
+			*si,
+			*siprime,
 		)
 		if err != nil {
-			logging.WithField("keyStorage", keyStorage).Error("Could not store completed share and pub key to index")
+			logging.WithField("keyStorage", keyStorage).Error("Could not store commitment matrix and completed share")
+			return
 		}
+		secret.Wipe(si, siprime)
 		return
 	}
 	logging.WithField("inter", inter).Error("Unexpected output type in dkgkeygennofsmtransport")
@@ -324,5 +353,5 @@ func (tp *DKGKeygennofsmTransport) Output(inter interface{}) {
 
 func (tp *DKGKeygennofsmTransport) CheckIfNIZKPProcessed(keyIndex big.Int) bool {
 	serviceLibrary := NewServiceLibrary(tp.eventBus, "dkgkeygennofsmtransport")
-	return serviceLibrary.DatabaseMethods().IndexToPublicKeyExists(keyIndex)
+	return serviceLibrary.DatabaseMethods().IndexToPublicKeyExists(context.Background(), keyIndex)
 }
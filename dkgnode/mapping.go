@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/version"
 
 	"github.com/avast/retry-go"
 	"github.com/libp2p/go-libp2p-core/protocol"
@@ -19,7 +20,6 @@ import (
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/eventbus"
 	"github.com/torusresearch/torus-node/mapping"
-	"github.com/torusresearch/torus-node/pvss"
 )
 
 type MappingProtocolPrefix string
@@ -50,163 +50,125 @@ func (m *MappingService) OnStart() error {
 func (m *MappingService) OnStop() error {
 	return nil
 }
-func (m *MappingService) Call(method string, args ...interface{}) (result interface{}, err error) {
+func (m *MappingService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Mapping.Prefix)
 
-	switch method {
-	case "new_mapping_node":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.NewMappingNodeCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-		var args0 MappingStartData
-		var args1, args2 bool
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		mappingStartData := args0
-		isOldNode := args1
-		isNewNode := args2
-		mappingID := m.GetMappingID(mappingStartData.OldEpoch, mappingStartData.NewEpoch)
-		oldNodeList := getCommonNodesFromNodeRefArray(mappingServiceLibrary.EthereumMethods().AwaitCompleteNodeList(mappingStartData.OldEpoch))
-		newNodeList := getCommonNodesFromNodeRefArray(mappingServiceLibrary.EthereumMethods().AwaitCompleteNodeList(mappingStartData.NewEpoch))
-		selfPubKey := mappingServiceLibrary.EthereumMethods().GetSelfPublicKey()
-		m.MappingInstances[mappingID] = mapping.NewMappingNode(
-			pcmn.Node{
-				Index:  mappingServiceLibrary.EthereumMethods().GetSelfIndex(),
-				PubKey: selfPubKey,
-			},
-			mappingStartData.OldEpoch,
-			oldNodeList,
-			mappingStartData.OldEpochT,
-			mappingStartData.OldEpochK,
-			mappingStartData.NewEpoch,
-			newNodeList,
-			mappingStartData.NewEpochT,
-			mappingStartData.NewEpochK,
-			mappingServiceLibrary.EthereumMethods().GetSelfIndex(),
-			NewDKGMappingTransport(m.eventBus, mappingStartData.OldEpoch, mappingStartData.NewEpoch),
-			NewDKGMappingDataSource(m.eventBus),
-			isOldNode,
-			isNewNode,
-		)
-		return nil, nil
-	case "receive_BFT_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		var args1 mapping.MappingMessage
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		mappingID := args0
-		mappingMessage := args1
-		if !m.MappingInstanceExists(mappingID) {
-			return nil, fmt.Errorf("Mapping instance not available %v", mappingID)
-		}
-		return m.MappingInstances[mappingID].Transport.ReceiveBroadcast(mappingMessage), nil
-	case "mapping_instance_exists":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.MappingInstanceExistsCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		_ = castOrUnmarshal(args[0], &args0)
-
-		mappingID := args0
-		return m.MappingInstanceExists(mappingID), nil
-	case "get_mapping_ID":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetMappingIDCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0, args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		return m.GetMappingID(args0, args1), nil
-	case "get_freeze_state":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetFreezeStateCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		_ = castOrUnmarshal(args[0], &args0)
-
-		mappingID := args0
-		return m.FreezeState.Get(mappingID), nil
-	case "set_freeze_state":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.SetFreezeStateCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		var args1 int
-		var args2 uint
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		mappingID := args0
-		freezeState := args1
-		lastUnassignedIndex := args2
-		m.FreezeState.Set(mappingID, FreezeStateData{
-			FreezeState:         freezeState,
-			LastUnassignedIndex: lastUnassignedIndex,
-		})
-		return nil, nil
-	case "propose_freeze":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.ProposeFreezeCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		_ = castOrUnmarshal(args[0], &args0)
-
-		mappingID := args0
-		if !m.MappingInstanceExists(mappingID) {
-			return nil, fmt.Errorf("could not find mappingInstance for mappingID %s", mappingID)
-		}
-		mappingProposeFreezeMessage := mapping.MappingProposeFreezeMessage{
-			MappingID: mappingID,
-		}
-		byt, err := bijson.Marshal(mappingProposeFreezeMessage)
-		if err != nil {
-			return nil, fmt.Errorf("could not marshal mapping propose freeze message %v", mappingProposeFreezeMessage)
-		}
-		err = m.MappingInstances[mappingID].Transport.Receive(mapping.NodeDetails{
-			Index:  mappingServiceLibrary.EthereumMethods().GetSelfIndex(),
-			PubKey: mappingServiceLibrary.EthereumMethods().GetSelfPublicKey(),
-		}, mapping.CreateMappingMessage(mapping.MappingMessageRaw{
-			Method:    "mapping_propose_freeze",
-			MappingID: mappingID,
-			Data:      byt,
-		}))
-		return nil, err
-	case "mapping_summary_frozen":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.MappingSummaryFrozenCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0 mapping.MappingID
-		var args1 mapping.MappingSummaryMessage
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		mappingID := args0
-		mappingSummaryMessage := args1
-		if !m.MappingInstanceExists(mappingID) {
-			return nil, fmt.Errorf("could not find mappingInstance for mappingID %s", mappingID)
-		}
-		byt, err := bijson.Marshal(mappingSummaryMessage)
-		if err != nil {
-			return nil, err
-		}
-		err = m.MappingInstances[mappingID].Transport.ReceiveBroadcast(mapping.MappingMessage{
-			MappingID: mappingID,
-			Method:    "mapping_summary_frozen",
-			Data:      byt,
-		})
-		return nil, err
-	case "get_mapping_protocol_prefix":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetMappingProtocolPrefixCounter, pcmn.TelemetryConstants.Mapping.Prefix)
-
-		var args0, args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		oldEpoch := args0
-		newEpoch := args1
-		return m.GetMappingProtocolPrefix(oldEpoch, newEpoch), nil
-	default:
-		return nil, fmt.Errorf("Unimplemented MappingService method %s", method)
+	return dispatchMapping(ctx, m, method, args)
+}
+
+func (m *MappingService) handleNewMappingNode(ctx context.Context, mappingStartData MappingStartData, isOldNode bool, isNewNode bool) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.NewMappingNodeCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	mappingID := m.GetMappingID(mappingStartData.OldEpoch, mappingStartData.NewEpoch)
+	oldNodeList := getCommonNodesFromNodeRefArray(mappingServiceLibrary.EthereumMethods().AwaitCompleteNodeList(ctx, mappingStartData.OldEpoch))
+	newNodeList := getCommonNodesFromNodeRefArray(mappingServiceLibrary.EthereumMethods().AwaitCompleteNodeList(ctx, mappingStartData.NewEpoch))
+	selfPubKey := mappingServiceLibrary.EthereumMethods().GetSelfPublicKey(ctx)
+	mappingNode := mapping.NewMappingNode(
+		pcmn.Node{
+			Index:  mappingServiceLibrary.EthereumMethods().GetSelfIndex(ctx),
+			PubKey: selfPubKey,
+		},
+		mappingStartData.OldEpoch,
+		oldNodeList,
+		mappingStartData.OldEpochT,
+		mappingStartData.OldEpochK,
+		mappingStartData.NewEpoch,
+		newNodeList,
+		mappingStartData.NewEpochT,
+		mappingStartData.NewEpochK,
+		mappingServiceLibrary.EthereumMethods().GetSelfIndex(ctx),
+		NewDKGMappingTransport(m.eventBus, mappingStartData.OldEpoch, mappingStartData.NewEpoch),
+		NewDKGMappingDataSource(m.eventBus),
+		isOldNode,
+		isNewNode,
+	)
+	mappingNode.Clock = nodeClock
+	m.MappingInstances[mappingID] = mappingNode
+	return nil
+}
+
+func (m *MappingService) handleReceiveBFTMessage(ctx context.Context, mappingID mapping.MappingID, mappingMessage mapping.MappingMessage) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	if !m.MappingInstanceExists(mappingID) {
+		return fmt.Errorf("Mapping instance not available %v", mappingID)
+	}
+	return m.MappingInstances[mappingID].Transport.ReceiveBroadcast(mappingMessage)
+}
+
+func (m *MappingService) handleMappingInstanceExists(ctx context.Context, mappingID mapping.MappingID) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.MappingInstanceExistsCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	return m.MappingInstanceExists(mappingID), nil
+}
+
+func (m *MappingService) handleGetMappingID(ctx context.Context, oldEpoch int, newEpoch int) (mapping.MappingID, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetMappingIDCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	return m.GetMappingID(oldEpoch, newEpoch), nil
+}
+
+func (m *MappingService) handleGetFreezeState(ctx context.Context, mappingID mapping.MappingID) (int, uint, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetFreezeStateCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	freezeStateData := m.FreezeState.Get(mappingID)
+	return freezeStateData.FreezeState, freezeStateData.LastUnassignedIndex, nil
+}
+
+func (m *MappingService) handleSetFreezeState(ctx context.Context, mappingID mapping.MappingID, freezeState int, lastUnassignedIndex uint) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.SetFreezeStateCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	m.FreezeState.Set(mappingID, FreezeStateData{
+		FreezeState:         freezeState,
+		LastUnassignedIndex: lastUnassignedIndex,
+	})
+	return nil
+}
+
+func (m *MappingService) handleProposeFreeze(ctx context.Context, mappingID mapping.MappingID) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.ProposeFreezeCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	if !m.MappingInstanceExists(mappingID) {
+		return fmt.Errorf("could not find mappingInstance for mappingID %s", mappingID)
 	}
+	mappingProposeFreezeMessage := mapping.MappingProposeFreezeMessage{
+		MappingID: mappingID,
+	}
+	byt, err := bijson.Marshal(mappingProposeFreezeMessage)
+	if err != nil {
+		return fmt.Errorf("could not marshal mapping propose freeze message %v", mappingProposeFreezeMessage)
+	}
+	return m.MappingInstances[mappingID].Transport.Receive(mapping.NodeDetails{
+		Index:  mappingServiceLibrary.EthereumMethods().GetSelfIndex(ctx),
+		PubKey: mappingServiceLibrary.EthereumMethods().GetSelfPublicKey(ctx),
+	}, mapping.CreateMappingMessage(mapping.MappingMessageRaw{
+		Method:    "mapping_propose_freeze",
+		MappingID: mappingID,
+		Data:      byt,
+	}))
+}
+
+func (m *MappingService) handleMappingSummaryFrozen(ctx context.Context, mappingID mapping.MappingID, mappingSummaryMessage mapping.MappingSummaryMessage) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.MappingSummaryFrozenCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	if !m.MappingInstanceExists(mappingID) {
+		return fmt.Errorf("could not find mappingInstance for mappingID %s", mappingID)
+	}
+	byt, err := bijson.Marshal(mappingSummaryMessage)
+	if err != nil {
+		return err
+	}
+	return m.MappingInstances[mappingID].Transport.ReceiveBroadcast(mapping.MappingMessage{
+		MappingID: mappingID,
+		Method:    "mapping_summary_frozen",
+		Data:      byt,
+	})
+}
+
+func (m *MappingService) handleGetMappingProtocolPrefix(ctx context.Context, oldEpoch int, newEpoch int) (MappingProtocolPrefix, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.GetMappingProtocolPrefixCounter, pcmn.TelemetryConstants.Mapping.Prefix)
+
+	return m.GetMappingProtocolPrefix(oldEpoch, newEpoch), nil
 }
 func (m *MappingService) MappingInstanceExists(mappingID mapping.MappingID) bool {
 	_, found := m.MappingInstances[mappingID]
@@ -276,7 +238,7 @@ func (ds *DKGMappingDataSource) SetMappingNode(m *mapping.MappingNode) error {
 	return nil
 }
 func (ds *DKGMappingDataSource) RetrieveKeyMapping(index big.Int) (mapping.MappingKey, error) {
-	keyDetails, err := NewServiceLibrary(ds.eventBus, "dkgMappingDataSource").ABCIMethods().RetrieveKeyMapping(index)
+	keyDetails, err := NewServiceLibrary(ds.eventBus, "dkgMappingDataSource").ABCIMethods().RetrieveKeyMapping(context.Background(), index)
 	var mappingKeyAssignmentPublic mapping.MappingKey
 	if err != nil {
 		return mappingKeyAssignmentPublic, err
@@ -292,7 +254,7 @@ func NewDKGMappingTransport(e eventbus.Bus, oldEpoch, newEpoch int) *DKGMappingT
 	tp := &DKGMappingTransport{}
 	tp.SetEventBus(e)
 	tp.serviceLibrary = NewServiceLibrary(tp.eventBus, "dkgMappingTransport")
-	tp.Prefix = tp.serviceLibrary.MappingMethods().GetMappingProtocolPrefix(oldEpoch, newEpoch)
+	tp.Prefix = tp.serviceLibrary.MappingMethods().GetMappingProtocolPrefix(context.Background(), oldEpoch, newEpoch)
 	return tp
 }
 
@@ -307,7 +269,7 @@ func (tp *DKGMappingTransport) SetEventBus(e eventbus.Bus) {
 	tp.eventBus = e
 }
 func (tp *DKGMappingTransport) Init() {
-	err := tp.serviceLibrary.P2PMethods().SetStreamHandler(string(tp.Prefix), tp.streamHandler)
+	err := tp.serviceLibrary.P2PMethods().SetStreamHandler(context.Background(), string(tp.Prefix), tp.streamHandler)
 	if err != nil {
 		logging.WithField("DKGMappingTransportPrefix", tp.Prefix).WithError(err).Error("could not set stream handler")
 	}
@@ -317,16 +279,58 @@ func (tp *DKGMappingTransport) GetType() string {
 }
 func (tp *DKGMappingTransport) SetMappingNode(m *mapping.MappingNode) error {
 	tp.MappingNode = m
+	for _, nodeNetwork := range tp.memberOf() {
+		err := tp.serviceLibrary.P2PMethods().JoinGossipTopic(context.Background(), string(tp.Prefix), nodeNetwork.EpochID)
+		if err != nil {
+			logging.WithField("DKGMappingTransportPrefix", tp.Prefix).WithError(err).Error("could not join gossip topic")
+		}
+	}
+	return nil
+}
+
+// memberOf returns the networks of the mapping that this node is in
+func (tp *DKGMappingTransport) memberOf() (nodeNetworks []mapping.NodeNetwork) {
+	if tp.MappingNode.IsOldNode {
+		nodeNetworks = append(nodeNetworks, tp.MappingNode.OldNodes)
+	}
+	if tp.MappingNode.IsNewNode {
+		nodeNetworks = append(nodeNetworks, tp.MappingNode.NewNodes)
+	}
+	return
+}
+
+// MessageVersion implements mapping.MappingVersionNegotiator
+func (tp *DKGMappingTransport) MessageVersion(nodeDetails mapping.NodeDetails) string {
+	return negotiatedVersion(tp.serviceLibrary, nodeDetails.PubKey, version.ProtocolMapping)
+}
+
+// Gossip implements mapping.MappingGossipTransport
+func (tp *DKGMappingTransport) Gossip(nodeNetwork mapping.NodeNetwork, mappingMessage mapping.MappingMessage) error {
+	var pubKeys []common.Point
+	for _, node := range nodeNetwork.Nodes {
+		pubKeys = append(pubKeys, node.PubKey)
+	}
+	if err := checkGossipSupport(tp.serviceLibrary, nodeNetwork.EpochID, pubKeys); err != nil {
+		return err
+	}
+	byt, err := bijson.Marshal(mappingMessage)
+	if err != nil {
+		return err
+	}
+	err = gossipP2PMessage(tp.serviceLibrary, string(tp.Prefix), nodeNetwork.EpochID, byt, "transportMappingMessage")
+	if err != nil {
+		return err
+	}
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.SentMessageCounter, pcmn.TelemetryConstants.Mapping.Prefix)
 	return nil
 }
 func (tp *DKGMappingTransport) Sign(s []byte) ([]byte, error) {
-	k := tp.serviceLibrary.EthereumMethods().GetSelfPrivateKey()
-	return pvss.ECDSASignBytes(s, &k), nil
+	return tp.serviceLibrary.EthereumMethods().SelfSignData(context.Background(), s), nil
 }
 func (tp *DKGMappingTransport) Send(nodeDetails mapping.NodeDetails, mappingMessage mapping.MappingMessage) error {
 	// get recipient details
-	ethNodeDetails := tp.serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(nodeDetails.PubKey))
-	pubKey := tp.serviceLibrary.EthereumMethods().GetSelfPublicKey()
+	ethNodeDetails := tp.serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(nodeDetails.PubKey))
+	pubKey := tp.serviceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
 	if nodeDetails.PubKey.X.Cmp(&pubKey.X) == 0 && nodeDetails.PubKey.Y.Cmp(&pubKey.Y) == 0 {
 		return tp.Receive(nodeDetails, mappingMessage)
 	}
@@ -335,19 +339,19 @@ func (tp *DKGMappingTransport) Send(nodeDetails mapping.NodeDetails, mappingMess
 	if err != nil {
 		return err
 	}
-	p2pMsg := tp.serviceLibrary.P2PMethods().NewP2PMessage(HashToString(byt), false, byt, "transportMappingMessage")
+	p2pMsg := tp.serviceLibrary.P2PMethods().NewP2PMessage(context.Background(), HashToString(byt), false, byt, "transportMappingMessage")
 	peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
 	if err != nil {
 		return err
 	}
 	// sign the data
-	signature, err := tp.serviceLibrary.P2PMethods().SignP2PMessage(&p2pMsg)
+	signature, err := tp.serviceLibrary.P2PMethods().SignP2PMessage(context.Background(), &p2pMsg)
 	if err != nil {
 		return errors.New("failed to sign p2pMsg" + err.Error())
 	}
 	p2pMsg.Sign = signature
 	err = retry.Do(func() error {
-		err = tp.serviceLibrary.P2PMethods().SendP2PMessage(*peerID, protocol.ID(tp.Prefix), &p2pMsg)
+		err = tp.serviceLibrary.P2PMethods().SendP2PMessage(context.Background(), *peerID, protocol.ID(tp.Prefix), &p2pMsg)
 		if err != nil {
 			logging.WithFields(logging.Fields{
 				"peerID":     peerID,
@@ -372,7 +376,7 @@ func (tp *DKGMappingTransport) Receive(nodeDetails mapping.NodeDetails, mappingM
 	return tp.MappingNode.ProcessMessage(nodeDetails, mappingMessage)
 }
 func (tp *DKGMappingTransport) SendBroadcast(mappingMessage mapping.MappingMessage) error {
-	_, err := tp.serviceLibrary.TendermintMethods().Broadcast(mappingMessage)
+	_, err := tp.serviceLibrary.TendermintMethods().Broadcast(context.Background(), mappingMessage)
 	if err != nil {
 		return err
 	}
@@ -391,7 +395,7 @@ func (tp *DKGMappingTransport) streamHandler(streamMessage StreamMessage) {
 	logging.Debug("streamHandler receiving message")
 	// get request data
 	p2pBasicMsg := streamMessage.Message
-	if tp.serviceLibrary.P2PMethods().AuthenticateMessage(p2pBasicMsg) != nil {
+	if tp.serviceLibrary.P2PMethods().AuthenticateMessage(context.Background(), p2pBasicMsg) != nil {
 		logging.WithField("p2pBasicMsg", p2pBasicMsg).Error("could not authenticate incoming p2pBasicMsg iin dkgmappingtransport")
 		return
 	}
@@ -409,7 +413,7 @@ func (tp *DKGMappingTransport) streamHandler(streamMessage StreamMessage) {
 		return
 	}
 	logging.Debugf("able to get pubk X: %s, Y: %s", pubKey.X.Text(16), pubKey.Y.Text(16))
-	ethNodeDetails := tp.serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(pubKey))
+	ethNodeDetails := tp.serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(pubKey))
 	err = tp.Receive(mapping.NodeDetails(pcmn.Node{
 		Index:  int(ethNodeDetails.Index.Int64()),
 		PubKey: pubKey,
@@ -0,0 +1,328 @@
+//go:build go1.18
+// +build go1.18
+
+package dkgnode
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/tendermint/abci/example/code"
+	"github.com/torusresearch/tendermint/abci/types"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/keygennofsm"
+	"github.com/torusresearch/torus-node/mapping"
+	"github.com/torusresearch/torus-node/pss"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+// fuzzServiceLibrary authenticates and validates messages against a node list with a single node
+// in epoch 1, and drops what the BFT rule set passes on to the protocols and the database
+type fuzzServiceLibrary struct {
+	ServiceLibrary
+	ethereum *EthereumService
+}
+
+func (s fuzzServiceLibrary) EthereumMethods() EthereumMethods {
+	return fuzzEthereumMethods{e: s.ethereum}
+}
+func (s fuzzServiceLibrary) KeygennofsmMethods() KeygennofsmMethods {
+	return fuzzKeygennofsmMethods{}
+}
+func (s fuzzServiceLibrary) PSSMethods() PSSMethods {
+	return fuzzPSSMethods{}
+}
+func (s fuzzServiceLibrary) MappingMethods() MappingMethods {
+	return fuzzMappingMethods{}
+}
+func (s fuzzServiceLibrary) DatabaseMethods() DatabaseMethods {
+	return fuzzDatabaseMethods{}
+}
+
+type fuzzEthereumMethods struct {
+	EthereumMethods
+	e *EthereumService
+}
+
+func (m fuzzEthereumMethods) GetCurrentEpoch(ctx context.Context) int {
+	return 1
+}
+func (m fuzzEthereumMethods) GetEpochInfo(ctx context.Context, epoch int, skipCache bool) (epochInfo, error) {
+	return epochInfo{Id: *big.NewInt(1), N: *big.NewInt(1), K: *big.NewInt(1), NextEpoch: *big.NewInt(2)}, nil
+}
+func (m fuzzEthereumMethods) GetNodeDetailsByEpochAndIndex(ctx context.Context, epoch int, index int) NodeReference {
+	// unknown nodes are returned without a key instead of being looked up again until found
+	serializedNodeRef, _ := m.e.handleGetNodeDetailsByEpochAndIndex(ctx, epoch, index)
+	nodeRef, _ := deserializeNodeReference(serializedNodeRef)
+	return nodeRef
+}
+func (m fuzzEthereumMethods) VerifyDataWithNodelist(ctx context.Context, pk common.Point, sig []byte, input []byte) (NodeDetails, error) {
+	return m.e.verifyDataWithNodelist(pk, sig, input)
+}
+func (m fuzzEthereumMethods) VerifyDataWithEpoch(ctx context.Context, pk common.Point, sig []byte, input []byte, epoch int) (NodeDetails, error) {
+	return m.e.verifyDataWithEpoch(pk, sig, input, epoch)
+}
+
+type fuzzKeygennofsmMethods struct {
+	KeygennofsmMethods
+}
+
+func (m fuzzKeygennofsmMethods) ReceiveBFTMessage(ctx context.Context, keygenMessage keygennofsm.KeygenMessage) error {
+	return nil
+}
+
+type fuzzPSSMethods struct {
+	PSSMethods
+}
+
+func (m fuzzPSSMethods) ReceiveBFTMessage(ctx context.Context, protocolPrefix PSSProtocolPrefix, pssMessage pss.PSSMessage) error {
+	return nil
+}
+
+type fuzzMappingMethods struct {
+	MappingMethods
+}
+
+func (m fuzzMappingMethods) SetFreezeState(ctx context.Context, mappingID mapping.MappingID, freezeState int, lastUnassignedIndex uint) {
+}
+
+type fuzzDatabaseMethods struct {
+	DatabaseMethods
+}
+
+func (m fuzzDatabaseMethods) GetKeygenStarted(ctx context.Context, keygenID string) bool {
+	return false
+}
+func (m fuzzDatabaseMethods) SetKeygenStarted(ctx context.Context, keygenID string, started bool) error {
+	return nil
+}
+func (m fuzzDatabaseMethods) StorePublicKeyToIndex(ctx context.Context, publicKey common.Point, keyIndex big.Int) error {
+	return nil
+}
+
+// setupFuzzNode registers a node with index 1 and returns its signer, which signs the seeds of the fuzz targets
+func setupFuzzNode(f *testing.F) (signer.Signer, common.Point) {
+	privKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		f.Fatal(err)
+	}
+	serviceLibrary := fuzzServiceLibrary{ethereum: &EthereumService{
+		nodeRegisterMap: map[int]*NodeRegister{
+			1: {NodeList: []*NodeReference{{Index: big.NewInt(1), PublicKey: &privKey.PublicKey}}},
+		},
+	}}
+	abciServiceLibrary = serviceLibrary
+	p2pServiceLibrary = serviceLibrary
+	conf := config.DefaultConfigSettings()
+	config.GlobalMutableConfig = config.InitMutableConfig(&conf)
+	return signer.NewMemorySigner(privKey), common.Point{X: *privKey.X, Y: *privKey.Y}
+}
+
+// newFuzzApp returns an ABCI app without blocks, each input is run against a new one so that
+// failures do not depend on the inputs run before
+func newFuzzApp() *ABCIApp {
+	return &ABCIApp{info: &AppInfo{}, state: newState(), laggingState: newState(), commits: newCommitGate()}
+}
+
+// recordedTraffic returns the BFT transactions broadcast and the P2P messages sent in the
+// recordings of testdata/recordings, which nodes write to recordEventsPath. Messages carry shares
+// and are only recorded whole with recordSecrets, those that were redacted are skipped.
+func recordedTraffic(f *testing.F) (txs []interface{}, p2pMsgs []P2PBasicMsg) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "recordings", "*.rec"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		records, err := eventbus.ReadRecords(file)
+		file.Close()
+		if err != nil {
+			f.Fatalf("could not read recording %v: %v", path, err)
+		}
+		for _, record := range records {
+			if record.Topic != "method" || !record.Replayable() {
+				continue
+			}
+			data, err := record.Decode(recordCodec{})
+			if err != nil {
+				f.Fatalf("could not decode event %v of recording %v: %v", record.Seq, path, err)
+			}
+			request, ok := data.(MethodRequest)
+			if !ok {
+				continue
+			}
+			switch {
+			case request.Service == "tendermint" && request.Method == "broadcast" && len(request.Data) == 1:
+				if _, ok := bftTxs[getType(request.Data[0])]; ok {
+					txs = append(txs, request.Data[0])
+				}
+			case request.Service == "p2p" && request.Method == "send_p2p_message" && len(request.Data) == 3:
+				if msg, ok := request.Data[2].(*P2PBasicMsg); ok && msg != nil {
+					p2pMsgs = append(p2pMsgs, *msg)
+				}
+			}
+		}
+	}
+	return txs, p2pMsgs
+}
+
+// signedBFTTx wraps bftTx the way PrepareBFTTx does
+func signedBFTTx(f *testing.F, s signer.Signer, pk common.Point, bftTx interface{}) []byte {
+	wrapper := DefaultBFTTxWrapper{MsgType: bftTxs[getType(bftTx)], Nonce: 7, PubKey: pk}
+	bftRaw, err := bijson.Marshal(bftTx)
+	if err != nil {
+		f.Fatal(err)
+	}
+	wrapper.BFTTx = bftRaw
+	wrapper.Signature, err = signer.SignData(s, wrapper.GetSerializedBody())
+	if err != nil {
+		f.Fatal(err)
+	}
+	rawMsg, err := bijson.Marshal(wrapper)
+	if err != nil {
+		f.Fatal(err)
+	}
+	return rawMsg
+}
+
+func FuzzValidateBFTTx(f *testing.F) {
+	_, pk := setupFuzzNode(f)
+	recordedTxs, _ := recordedTraffic(f)
+	for _, bftTx := range recordedTxs {
+		data, err := bijson.Marshal(bftTx)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(bftTxs[getType(bftTx)], data)
+	}
+	senderDetails := NodeDetails{Index: 1, PubKey: pk}
+	f.Fuzz(func(t *testing.T, msgType byte, bftTx []byte) {
+		app := newFuzzApp()
+		_, _ = app.validateTx(bftTx, msgType, senderDetails, app.laggingState)
+		_, _, _ = app.ValidateAndUpdateAndTagBFTTx(bftTx, msgType, senderDetails)
+	})
+}
+
+func FuzzDeliverTx(f *testing.F) {
+	s, pk := setupFuzzNode(f)
+	recordedTxs, _ := recordedTraffic(f)
+	for _, bftTx := range recordedTxs {
+		f.Add(signedBFTTx(f, s, pk, bftTx))
+	}
+	f.Fuzz(func(t *testing.T, tx []byte) {
+		_, senderDetails, err := authenticateBftTx(tx)
+		if err == nil && senderDetails.Index != 1 {
+			t.Fatalf("authenticated tx from unexpected sender %v", senderDetails)
+		}
+		app := newFuzzApp()
+		checked := app.CheckTx(types.RequestCheckTx{Tx: tx})
+		delivered := app.DeliverTx(types.RequestDeliverTx{Tx: tx})
+		if err != nil && (checked.Code != code.CodeTypeUnauthorized || delivered.Code != code.CodeTypeUnauthorized) {
+			t.Fatalf("accepted tx that could not be authenticated: %v", err)
+		}
+	})
+}
+
+func FuzzP2PBasicMsg(f *testing.F) {
+	s, pk := setupFuzzNode(f)
+	rawPk, err := bijson.Marshal(pk)
+	if err != nil {
+		f.Fatal(err)
+	}
+	_, recordedMsgs := recordedTraffic(f)
+	for _, msg := range recordedMsgs {
+		data, err := bijson.Marshal(msg)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		// the same message from the fuzz node, so that it is authenticated
+		msg.NodePubKey = rawPk
+		msg.Sign, err = signer.SignData(s, msg.GetSerializedBody())
+		if err != nil {
+			f.Fatal(err)
+		}
+		data, err = bijson.Marshal(msg)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg P2PBasicMsg
+		if bijson.Unmarshal(data, &msg) != nil {
+			return
+		}
+		sigErr := verifySigOnMsg(msg.GetSerializedBody(), msg.GetSign(), msg.GetNodePubKey())
+		if authenticateMessage(&msg) == nil && sigErr != nil {
+			t.Fatalf("message authenticated with an invalid signature: %v", sigErr)
+		}
+		_ = authenticateMessageInEpoch(&msg, 1)
+	})
+}
+
+func FuzzFromNodeDetailsID(f *testing.F) {
+	for i := 1; i <= 3; i++ {
+		nodeDetails := NodeDetails{
+			Index:  i,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(big.NewInt(int64(i)).Bytes())),
+		}
+		f.Add(string(nodeDetails.ToNodeDetailsID()))
+	}
+	f.Add("1\x1c2")
+	f.Add("1\x1cz\x1c2")
+	f.Fuzz(func(t *testing.T, id string) {
+		var nodeDetails NodeDetails
+		if nodeDetails.FromNodeDetailsID(NodeDetailsID(id)) != nil {
+			if nodeDetails.ToNodeDetailsID() != (&NodeDetails{}).ToNodeDetailsID() {
+				t.Fatalf("failed parse of %q modified node details to %v", id, nodeDetails)
+			}
+			return
+		}
+		var reparsed NodeDetails
+		if err := reparsed.FromNodeDetailsID(nodeDetails.ToNodeDetailsID()); err != nil {
+			t.Fatalf("could not parse back %v: %v", nodeDetails, err)
+		}
+		if reparsed.ToNodeDetailsID() != nodeDetails.ToNodeDetailsID() {
+			t.Fatalf("round trip of %q gave %v, expected %v", id, reparsed, nodeDetails)
+		}
+	})
+}
+
+func FuzzCommitmentRequestResultData(f *testing.F) {
+	tempPub := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(big.NewInt(int64(42)).Bytes()))
+	f.Add((&CommitmentRequestResultData{
+		MessagePrefix:      "mug00",
+		TokenCommitment:    ethCrypto.Keccak256Hash([]byte("idtoken")).Hex()[2:],
+		TempPubX:           tempPub.X.Text(16),
+		TempPubY:           tempPub.Y.Text(16),
+		VerifierIdentifier: "google",
+		TimeSigned:         "1600000000",
+	}).ToString())
+	f.Add("mug00\x1c\x1c\x1c\x1cgoogle")
+	f.Add("mug00\x1c\x1c\x1c\x1cgoogle\x1c1\x1c2")
+	f.Fuzz(func(t *testing.T, data string) {
+		var c CommitmentRequestResultData
+		ok, err := c.FromString(data)
+		if !ok || err != nil {
+			if ok || err == nil || c != (CommitmentRequestResultData{}) {
+				t.Fatalf("failed parse of %q returned %v, %v and %v", data, ok, err, c)
+			}
+			return
+		}
+		if c.ToString() != data {
+			t.Fatalf("round trip of %q gave %q", data, c.ToString())
+		}
+	})
+}
@@ -14,13 +14,15 @@ import (
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/tcontext"
+	"github.com/torusresearch/torus-node/tracing"
 
 	"github.com/gorilla/context"
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/crypto"
-	"github.com/torusresearch/torus-common/secp256k1"
 	"github.com/torusresearch/torus-node/config"
 
 	"github.com/torusresearch/torus-node/telemetry"
@@ -31,6 +33,7 @@ const nonceHeaderKey = "torus-nonce"
 const timestampHeaderKey = "torus-timestamp"
 const jrpcMethod = "method"
 const requestBody = "body"
+const traceparentHeaderKey = "traceparent"
 
 func parseBodyMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,6 +90,38 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requestIDMiddleware gives every request a tcontext ID which is passed on to the services it calls
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// NOTE: This has to be the last middleware, WithContext copies the request
+		// and values set through gorilla/context are not found on the copy
+		next.ServeHTTP(w, r.WithContext(tcontext.From(r.Context())))
+	})
+}
+
+// tracingMiddleware starts a span for every request, continuing the trace of the caller
+// if it sent a traceparent header, and returns the traceparent of the span to the caller
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, _ := context.Get(r, jrpcMethod).(string)
+		name := r.URL.Path
+		if method != "" {
+			name = "jrpc." + method
+		}
+		ctx, span := tracing.StartFromTraceparent(r.Context(), name, tracing.KindServer, r.Header.Get(traceparentHeaderKey))
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer span.End()
+		span.SetAttribute("remote_addr", r.RemoteAddr)
+		w.Header().Set(traceparentHeaderKey, span.Traceparent())
+		// NOTE: WithContext copies the request, like requestIDMiddleware this must come after
+		// every middleware that reads values set through gorilla/context
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 type jRPCRequest struct {
 	Method string `json:"method"`
 }
@@ -173,12 +208,12 @@ func authMiddleware(eventBus eventbus.Bus) func(next http.Handler) http.Handler
 					return
 				}
 
-				if authServiceLibrary.CacheMethods().SignerSigExists(signatureHeader) {
+				if authServiceLibrary.CacheMethods().SignerSigExists(r.Context(), signatureHeader) {
 					rejectResponse(w, fmt.Errorf("signature %v already seen", signatureHeader))
 					return
 				}
 
-				authServiceLibrary.CacheMethods().RecordSignerSig(signatureHeader)
+				authServiceLibrary.CacheMethods().RecordSignerSig(r.Context(), signatureHeader)
 
 				var captchaX, captchaY big.Int
 				captchaX.SetString(config.GlobalMutableConfig.GetS("JRPCAuthPubKeyX"), 16)
@@ -192,17 +227,11 @@ func authMiddleware(eventBus eventbus.Bus) func(next http.Handler) http.Handler
 					return
 				}
 
-				ethPrivKeyStr := config.GlobalConfig.EthPrivateKey
-				ethPrivKey, ok := new(big.Int).SetString(ethPrivKeyStr, 16)
-				if !ok {
-					rejectResponse(w, fmt.Errorf("could not setString for ethPrivKey"))
-					return
-				}
 				suffix := pcmn.Delimiter1 + timestampHeader + pcmn.Delimiter1 + nonceHeader
 				sigData := append(bodyBytes, suffix...)
 
-				pubKeyX, pubKeyY := secp256k1.Curve.ScalarBaseMult(ethPrivKey.Bytes())
-				if !crypto.VerifyPtFromRawWithPubKey(sigData, pubKeyX.Text(16), pubKeyY.Text(16), captchaPubKey, rawSig) {
+				nodePubKey := authServiceLibrary.EthereumMethods().GetSelfPublicKey(r.Context())
+				if !crypto.VerifyPtFromRawWithPubKey(sigData, nodePubKey.X.Text(16), nodePubKey.Y.Text(16), captchaPubKey, rawSig) {
 					rejectResponse(w, fmt.Errorf("invalid signature"))
 					return
 				}
@@ -234,12 +263,12 @@ func authMiddleware(eventBus eventbus.Bus) func(next http.Handler) http.Handler
 				return
 			}
 
-			if authServiceLibrary.CacheMethods().SignerSigExists(base64.StdEncoding.EncodeToString(customAuthFields.Signature)) {
+			if authServiceLibrary.CacheMethods().SignerSigExists(r.Context(), base64.StdEncoding.EncodeToString(customAuthFields.Signature)) {
 				rejectResponse(w, fmt.Errorf("in deprecated, signature %v already seen", customAuthFields.Signature))
 				return
 			}
 
-			authServiceLibrary.CacheMethods().RecordSignerSig(base64.StdEncoding.EncodeToString(customAuthFields.Signature))
+			authServiceLibrary.CacheMethods().RecordSignerSig(r.Context(), base64.StdEncoding.EncodeToString(customAuthFields.Signature))
 
 			var captchaX, captchaY big.Int
 			captchaX.SetString(config.GlobalMutableConfig.GetS("JRPCAuthPubKeyX"), 16)
@@ -255,17 +284,11 @@ func authMiddleware(eventBus eventbus.Bus) func(next http.Handler) http.Handler
 
 			filteredBytes := filterBytes(bodyBytes, "torus-signature", "torus-nonce", "torus-timestamp")
 
-			ethPrivKeyStr := config.GlobalConfig.EthPrivateKey
-			ethPrivKey, ok := new(big.Int).SetString(ethPrivKeyStr, 16)
-			if !ok {
-				rejectResponse(w, fmt.Errorf("in deprecated, could not setString for ethPrivKey"))
-				return
-			}
 			suffix := pcmn.Delimiter1 + customAuthFields.Timestamp + pcmn.Delimiter1 + customAuthFields.Nonce
 			sigData := append(filteredBytes, suffix...)
 
-			pubKeyX, pubKeyY := secp256k1.Curve.ScalarBaseMult(ethPrivKey.Bytes())
-			if !crypto.VerifyPtFromRawWithPubKey(sigData, pubKeyX.Text(16), pubKeyY.Text(16), captchaPubKey, rawSig) {
+			nodePubKey := authServiceLibrary.EthereumMethods().GetSelfPublicKey(r.Context())
+			if !crypto.VerifyPtFromRawWithPubKey(sigData, nodePubKey.X.Text(16), nodePubKey.Y.Text(16), captchaPubKey, rawSig) {
 				rejectResponse(w, fmt.Errorf("in deprecated, invalid signature in body %v", string(filteredBytes)))
 				return
 			}
@@ -300,9 +323,28 @@ func rejectResponse(w http.ResponseWriter, errLog error) {
 	}
 }
 
+// secretRequestMethods and secretResponseMethods pass shares or keys as untyped
+// arguments and results, which can not be redacted by their type
+var secretRequestMethods = map[string]map[string]bool{
+	"database": {
+		"store_completed_keygen":       true,
+		"store_completed_PSS":          true,
+		"store_completed_keygen_share": true,
+		"store_completed_PSS_share":    true,
+	},
+}
+var secretResponseMethods = map[string]map[string]bool{
+	"database": {"retrieve_completed_share": true},
+	"ethereum": {"get_self_private_key": true},
+}
+
 func setupRequestLoggingMiddleware(serviceRegistry *ServiceRegistry) {
 	requestLoggingMiddleware := func(methodRequest MethodRequest) MethodRequest {
-		logging.Debugf("ID %v, Caller %v called Method %v with Data %v", methodRequest.ID, methodRequest.Caller, methodRequest.Method, stringify(methodRequest.Data))
+		data := secret.Redacted
+		if !secretRequestMethods[methodRequest.Service][methodRequest.Method] {
+			data = stringify(methodRequest.Data)
+		}
+		logging.Debugf("ID %v, RequestID %v, Caller %v called Method %v with Data %v", methodRequest.ID, methodRequest.RequestID, methodRequest.Caller, methodRequest.Method, data)
 		return methodRequest
 	}
 	serviceRegistry.AddRequestMiddleware(&requestLoggingMiddleware)
@@ -310,7 +352,11 @@ func setupRequestLoggingMiddleware(serviceRegistry *ServiceRegistry) {
 
 func setupResponseLoggingMiddleware(serviceRegistry *ServiceRegistry) {
 	responseLoggingMiddleware := func(methodResponse MethodResponse) MethodResponse {
-		logging.Debugf("ID %v Caller %v called Method %v returned Data %v", methodResponse.Request.ID, methodResponse.Request.Caller, methodResponse.Request.Method, methodResponse.Data)
+		var data interface{} = secret.Redacted
+		if !secretResponseMethods[methodResponse.Request.Service][methodResponse.Request.Method] {
+			data = secret.Redact(methodResponse.Data)
+		}
+		logging.Debugf("ID %v RequestID %v Caller %v called Method %v returned Data %v", methodResponse.Request.ID, methodResponse.Request.RequestID, methodResponse.Request.Caller, methodResponse.Request.Method, data)
 		return methodResponse
 	}
 	serviceRegistry.AddResponseMiddleware(&responseLoggingMiddleware)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/crypto"
+	"github.com/torusresearch/torus-node/signer"
 )
 
 // verifyDataWithNodelist- returns if data is valid
@@ -28,7 +29,7 @@ func (e *EthereumService) verifyDataWithNodelist(pk common.Point, sig []byte, da
 		return
 	}
 	// Check validity of signature
-	valid := crypto.VerifyPtFromRaw(data, pk, sig)
+	valid := verifyNodeSignature(data, pk, sig)
 	if !valid {
 		err = fmt.Errorf("invalid ecdsa sig for data %v", data)
 		return
@@ -50,6 +51,7 @@ func (e *EthereumService) verifyDataWithEpoch(pk common.Point, sig []byte, data
 	e.Lock()
 	nodeRegister, ok := e.nodeRegisterMap[epoch]
 	if !ok {
+		e.Unlock()
 		err = fmt.Errorf("epoch doesnt exist in node register map, verifyDataWithEpoch")
 		return
 	}
@@ -66,7 +68,7 @@ func (e *EthereumService) verifyDataWithEpoch(pk common.Point, sig []byte, data
 	}
 
 	// Check validity of signature
-	valid := crypto.VerifyPtFromRaw(data, pk, sig)
+	valid := verifyNodeSignature(data, pk, sig)
 	if !valid {
 		err = fmt.Errorf("invalid ecdsa sig for data %v", data)
 		return
@@ -80,7 +82,12 @@ func (e *EthereumService) verifyDataWithEpoch(pk common.Point, sig []byte, data
 	}, err
 }
 
-func (e *EthereumService) selfSignData(data []byte) []byte {
-	ecSig := crypto.SignData(data, e.nodePrivK)
-	return ecSig.Raw
+// verifyNodeSignature - returns if sig of a node on data is valid, signatures of malformed messages
+// can be shorter than the 64 bytes of r and s that VerifyPtFromRaw reads
+func verifyNodeSignature(data []byte, pk common.Point, sig []byte) bool {
+	return len(sig) >= 64 && crypto.VerifyPtFromRaw(data, pk, sig)
+}
+
+func (e *EthereumService) selfSignData(data []byte) ([]byte, error) {
+	return signer.SignData(e.signer, data)
 }
@@ -1,10 +1,11 @@
 package dkgnode
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"math/big"
 	"time"
 
@@ -12,8 +13,6 @@ import (
 	"github.com/torusresearch/torus-node/telemetry"
 
 	libp2p "github.com/libp2p/go-libp2p"
-	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
-	"github.com/libp2p/go-libp2p-core/helpers"
 	"github.com/libp2p/go-libp2p-core/host"
 	inet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
@@ -23,10 +22,13 @@ import (
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
 	"github.com/torusresearch/torus-common/common"
-	"github.com/torusresearch/torus-common/crypto"
 	"github.com/torusresearch/torus-node/config"
 	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/faults"
+	"github.com/torusresearch/torus-node/framing"
+	"github.com/torusresearch/torus-node/signer"
 	"github.com/torusresearch/torus-node/tcontext"
+	"github.com/torusresearch/torus-node/tracing"
 	"github.com/torusresearch/torus-node/version"
 )
 
@@ -49,7 +51,7 @@ type p2pMessageVersion string
 
 func CreateP2PBasicMsg(r P2PBasicMsgRaw) P2PBasicMsg {
 	return P2PBasicMsg{
-		Version:    p2pMessageVersion(version.NodeVersion),
+		Version:    p2pMessageVersion(version.P2PMessageVersion),
 		Timestamp:  r.Timestamp,
 		Id:         r.Id,
 		Gossip:     r.Gossip,
@@ -76,14 +78,17 @@ type P2PBasicMsgRaw struct {
 type P2PBasicMsg struct {
 	// shared between all requests
 	Version    p2pMessageVersion `json:"version,omitempty"`
-	Timestamp  big.Int           `json:"timestamp,omitempty"`  // unix time
-	Id         string            `json:"id,omitempty"`         // allows requesters to use request data when processing a response
-	Gossip     bool              `json:"gossip,omitempty"`     // true to have receiver peer gossip the message to neighbors
-	NodeId     string            `json:"nodeId,omitempty"`     // id of node that created the message (not the peer that may have sent it). =base58(multihash(nodePubKey))
-	NodePubKey []byte            `json:"nodePubKey,omitempty"` // Authoring node Secp256k1 public key (32bytes)
-	Sign       []byte            `json:"sign,omitempty"`       // signature of message data + method specific data by message authoring node.
-	MsgType    string            `json:"msgtype,omitempty"`    // identifyng message type
-	Payload    []byte            `json:"payload"`              // payload data to be unmarshalled
+	Timestamp  big.Int           `json:"timestamp,omitempty"`   // unix time
+	Id         string            `json:"id,omitempty"`          // allows requesters to use request data when processing a response
+	Gossip     bool              `json:"gossip,omitempty"`      // true to have receiver peer gossip the message to neighbors
+	NodeId     string            `json:"nodeId,omitempty"`      // id of node that created the message (not the peer that may have sent it). =base58(multihash(nodePubKey))
+	NodePubKey []byte            `json:"nodePubKey,omitempty"`  // Authoring node Secp256k1 public key (32bytes)
+	Sign       []byte            `json:"sign,omitempty"`        // signature of message data + method specific data by message authoring node.
+	MsgType    string            `json:"msgtype,omitempty"`     // identifyng message type
+	Payload    []byte            `json:"payload" secret:"true"` // payload data to be unmarshalled, may carry shares
+	// TraceParent continues the trace of the sending node, it is not signed
+	// so that the signatures of nodes that do not trace stay valid
+	TraceParent string `json:"traceparent,omitempty"`
 }
 
 type P2PSuite struct {
@@ -125,6 +130,7 @@ func (msg *P2PBasicMsg) SetSign(sig []byte) {
 }
 func (msg P2PBasicMsg) GetSerializedBody() []byte {
 	msg.SetSign(nil)
+	msg.TraceParent = ""
 	// marshall msg without the signature to bytes format
 	bin, err := bijson.Marshal(msg)
 	if err != nil {
@@ -134,13 +140,14 @@ func (msg P2PBasicMsg) GetSerializedBody() []byte {
 	return bin
 }
 
-func NewP2PService(ctx context.Context, eventBus eventbus.Bus) *BaseService {
+func NewP2PService(ctx context.Context, eventBus eventbus.Bus, nodeSigner signer.Signer) *BaseService {
 	p2pCtx, cancel := context.WithCancel(context.WithValue(ctx, ContextID, "p2p"))
 	p2pService := P2PService{
 		cancel:        cancel,
 		parentContext: ctx,
 		context:       p2pCtx,
 		eventBus:      eventBus,
+		signer:        nodeSigner,
 	}
 	p2pServiceLibrary = NewServiceLibrary(p2pService.eventBus, p2pService.Name())
 	return NewBaseService(&p2pService)
@@ -152,50 +159,136 @@ type P2PService struct {
 	parentContext context.Context
 	context       context.Context
 	eventBus      eventbus.Bus
+	signer        signer.Signer
 
 	host        host.Host
 	hostAddress ma.Multiaddr
 	// p2pNodeKey                 *p2p.NodeKey
 	pingProto                  *PingProtocol
+	gossip                     *gossipRouter
+	scores                     *peerScores
+	versions                   *peerVersions
+	health                     *peerHealthTable
+	streams                    *streamPool
+	inbound                    *inboundStreams
 	authenticateMessage        func(data P2PMessage) (err error)
 	authenticateMessageInEpoch func(data P2PMessage, epoch int) (err error)
-	signData                   func(data []byte) (rawSig []byte)
+	signData                   func(data []byte) (rawSig []byte, err error)
 }
 
 func (p *P2PService) StopForwardP2PToEventBus(proto string) {
 	p.host.RemoveStreamHandler(protocol.ID(proto))
+	p.host.RemoveStreamHandler(framedProtocol(protocol.ID(proto)))
+	p.inbound.closeAll(protocol.ID(proto))
+	p.gossip.leave(proto)
+}
+
+// forwardP2PMessage publishes an authenticated message received over a stream or gossip to the
+// event bus. Gossip is subject to the faults of its author, whichever peer relayed it.
+func (p *P2PService) forwardP2PMessage(proto string, from peer.ID, p2pMsg P2PBasicMsg) {
+	author := from.Pretty()
+	if p2pMsg.Gossip && p2pMsg.NodeId != "" {
+		author = p2pMsg.NodeId
+	}
+	_ = withFaults(faultProtocol(proto), faults.Receive, author, p2pMsg.Payload, func([]byte) error {
+		p.publishP2PMessage(proto, from, p2pMsg)
+		return nil
+	})
+}
+
+func (p *P2PService) publishP2PMessage(proto string, from peer.ID, p2pMsg P2PBasicMsg) {
+	_, span := startRemoteSpan(context.Background(), "p2p.receive", tracing.KindConsumer, p2pMsg.TraceParent)
+	span.SetAttribute("protocol", proto)
+	span.SetAttribute("msg_type", p2pMsg.MsgType)
+	span.SetAttribute("peer", from.Pretty())
+	span.SetAttribute("gossip", p2pMsg.Gossip)
+	p.eventBus.Publish("p2p:forward:"+proto, p2pMsg)
+	span.End()
+}
+
+// receiveP2PMessage authenticates a message received over a stream and forwards it to the event bus
+func (p *P2PService) receiveP2PMessage(proto string, from peer.ID, buf []byte) {
+	var p2pMsg P2PBasicMsg
+	err := bijson.Unmarshal(buf, &p2pMsg)
+	if err != nil {
+		logging.WithError(err).Error("could not unmarshal p2pmsg")
+		p.scores.report(from, offenceMalformedMessage)
+		return
+	}
+	logging.WithFields(logging.Fields{
+		"proto":  proto,
+		"p2pMsg": stringify(p2pMsg),
+	}).Debug("SetStreamHandler working and forwarding")
+
+	err = p.authenticateMessage(&p2pMsg)
+	if err != nil {
+		logging.WithField("Payload", string(p2pMsg.Payload)).Error("failed to authenticate p2pMsg")
+		p.scores.report(from, offenceUnauthenticated)
+		return
+	}
+	p.forwardP2PMessage(proto, from, p2pMsg)
 }
 
 func (p *P2PService) ForwardP2PToEventBus(proto string) {
+	// nodes that do not support framed streams send one message per stream
 	p.host.SetStreamHandler(protocol.ID(proto), func(s inet.Stream) {
-		buf, err := ioutil.ReadAll(s)
+		if p.scores.banned(s.Conn().RemotePeer()) {
+			_ = s.Reset()
+			return
+		}
+		buf, err := framing.ReadAll(s, p2pMaxMessageSize())
 		if err != nil {
 			e := s.Reset()
 			logging.WithError(err).Error("could not ReadAll from io")
 			if e != nil {
 				logging.WithError(e).Error("could not reset stream")
 			}
+			if err == framing.ErrTooLarge {
+				p.scores.report(s.Conn().RemotePeer(), offenceMalformedMessage)
+			}
 			return
 		}
 		s.Close()
-
-		var p2pMsg P2PBasicMsg
-		err = bijson.Unmarshal(buf, &p2pMsg)
-		if err != nil {
-			logging.WithError(err).Error("could not unmarshal p2pmsg")
+		p.receiveP2PMessage(proto, s.Conn().RemotePeer(), buf)
+	})
+	p.host.SetStreamHandler(framedProtocol(protocol.ID(proto)), func(s inet.Stream) {
+		from := s.Conn().RemotePeer()
+		if p.scores.banned(from) {
+			_ = s.Reset()
 			return
 		}
-		logging.WithFields(logging.Fields{
-			"proto":  proto,
-			"p2pMsg": stringify(p2pMsg),
-		}).Debug("SetStreamHandler working and forwarding")
-
-		err = p.authenticateMessage(&p2pMsg)
-		if err != nil {
-			logging.WithField("Payload", string(p2pMsg.Payload)).Error("failed to authenticate p2pMsg")
-			return
+		p.inbound.add(protocol.ID(proto), s)
+		defer p.inbound.remove(protocol.ID(proto), s)
+		reader := framing.NewReader(s, p2pMaxMessageSize())
+		for {
+			buf, err := reader.ReadMessage()
+			if err == io.EOF {
+				s.Close()
+				return
+			}
+			if err != nil {
+				logging.WithFields(logging.Fields{
+					"proto": proto,
+					"peer":  from.Pretty(),
+				}).WithError(err).Debug("closing framed stream")
+				if err == framing.ErrTooLarge {
+					p.scores.report(from, offenceMalformedMessage)
+				}
+				_ = s.Reset()
+				return
+			}
+			// the peer may have been banned since the stream was opened
+			if p.scores.banned(from) {
+				_, _ = s.Write([]byte{streamAckRejected})
+				_ = s.Reset()
+				return
+			}
+			if _, err := s.Write([]byte{streamAckDelivered}); err != nil {
+				_ = s.Reset()
+				return
+			}
+			p.receiveP2PMessage(proto, from, buf)
 		}
-		p.eventBus.Publish("p2p:forward:"+proto, p2pMsg)
 	})
 }
 
@@ -212,12 +305,10 @@ func padPrivKeyBytes(kBytes []byte) []byte {
 }
 
 func (p *P2PService) OnStart() error {
-	// Set keypair to node private key
-	k := p2pServiceLibrary.EthereumMethods().GetSelfPrivateKey()
-
-	priv, err := libp2pcrypto.UnmarshalSecp256k1PrivateKey(padPrivKeyBytes(k.Bytes()))
+	// Set keypair to node signing key
+	priv, err := signer.Libp2pPrivKey(p.signer)
 	if err != nil {
-		logging.WithError(err).Fatal("could not Unmarshal privateKey")
+		logging.WithError(err).Fatal("could not get libp2p identity from signer")
 	}
 
 	opts := []libp2p.Option{
@@ -242,10 +333,34 @@ func (p *P2PService) OnStart() error {
 
 	p.host = h
 	p.hostAddress = fullAddr
+	p.scores = newPeerScoresFromConfig(func(id peer.ID) {
+		if err := h.Network().ClosePeer(id); err != nil {
+			logging.WithField("peer", id.Pretty()).WithError(err).Error("could not disconnect banned peer")
+		}
+	})
+	if err := telemetry.Register(p.scores.metrics); err != nil {
+		logging.WithError(err).Error("could not register peer score metrics")
+	}
+	p.versions = newPeerVersions(h)
+	p.health = newPeerHealthTableFromConfig()
+	if err := telemetry.Register(p.health.metrics); err != nil {
+		logging.WithError(err).Error("could not register peer health metrics")
+	}
+	p.streams = newStreamPool(p.context, h, p.versions)
+	p.inbound = newInboundStreams()
+	p.gossip, err = newGossipRouter(p.context, h, func(msg P2PMessage) error {
+		return p.authenticateMessage(msg)
+	}, p.forwardP2PMessage, p.scores)
+	if err != nil {
+		return err
+	}
 	p.pingProto = NewPingProtocol(p)
 	p.authenticateMessage = authenticateMessage
 	p.authenticateMessageInEpoch = authenticateMessageInEpoch
-	p.signData = p2pServiceLibrary.EthereumMethods().SelfSignData
+	p.signData = func(data []byte) ([]byte, error) {
+		return signer.SignData(p.signer, data)
+	}
+	go p.monitorPeerHealth(p.context)
 
 	logging.WithField("LocalHostID", p.host.ID().String()).Debug()
 	return nil
@@ -259,115 +374,191 @@ func (p2p *P2PService) OnStop() error {
 	return nil
 }
 
-func (p2p *P2PService) Call(method string, args ...interface{}) (interface{}, error) {
+func (p2p *P2PService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.P2P.Prefix)
 
-	switch method {
-	// ID() peer.ID
-	case "id":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.GetPeerIDCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		return p2p.host.ID(), nil
-	// SetStreamHandler(protoName string, handler func(StreamMessage)) (err error)
-	case "set_stream_handler":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SetStreamHandlerCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-
-		proto := args0
-		p2p.ForwardP2PToEventBus(proto)
-		return true, nil
-	// RemoveStreamHandler(protoName string) (err error)
-	case "remove_stream_handler":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.RemoveStreamHandlerCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-
-		proto := args0
-		p2p.StopForwardP2PToEventBus(proto)
-		return true, nil
-	// AuthenticateMessage(p2pMsg P2PMessage) (err error)
-	case "authenticate_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.AuthenticateMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0 P2PBasicMsg
-		_ = castOrUnmarshal(args[0], &args0)
-
-		p2pMsg := args0
-		err := p2p.authenticateMessage(&p2pMsg)
-		return nil, err
-	case "authenticate_message_in_epoch":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.AuthenticateMessageInEpochCounter, pcmn.TelemetryConstants.P2P.Prefix)
+	return dispatchP2P(ctx, p2p, method, args)
+}
+
+func (p2p *P2PService) handleID(ctx context.Context) (peer.ID, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.GetPeerIDCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return p2p.host.ID(), nil
+}
+
+func (p2p *P2PService) handleSetStreamHandler(ctx context.Context, protoName string) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SetStreamHandlerCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	p2p.ForwardP2PToEventBus(protoName)
+	return nil
+}
+
+func (p2p *P2PService) handleRemoveStreamHandler(ctx context.Context, protoName string) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.RemoveStreamHandlerCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	p2p.StopForwardP2PToEventBus(protoName)
+	return nil
+}
+
+func (p2p *P2PService) handleAuthenticateMessage(ctx context.Context, p2pBasicMsg P2PBasicMsg) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.AuthenticateMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return authenticateMessage(&p2pBasicMsg)
+}
+
+func (p2p *P2PService) handleAuthenticateMessageInEpoch(ctx context.Context, p2pBasicMsg P2PBasicMsg, epoch int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.AuthenticateMessageInEpochCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return authenticateMessageInEpoch(&p2pBasicMsg, epoch)
+}
+
+func (p2p *P2PService) handleNewP2PMessage(ctx context.Context, messageID string, gossip bool, payload []byte, msgType string) (P2PBasicMsg, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.NewP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return *p2p.NewP2PMessage(messageID, gossip, payload, msgType), nil
+}
+
+// handleSignP2PMessage is NOT CONCURRENT SAFE
+func (p2p *P2PService) handleSignP2PMessage(ctx context.Context, message *P2PBasicMsg) ([]byte, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SignP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return p2p.signP2PMessage(message)
+}
 
-		var args0 P2PBasicMsg
-		var args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (p2p *P2PService) handleSendP2PMessage(ctx context.Context, id peer.ID, p protocol.ID, msg *P2PBasicMsg) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SendP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
 
-		p2pMsg := args0
-		err := p2p.authenticateMessageInEpoch(&p2pMsg, args1)
+	span := startInstanceSpan("p2p.send", tracing.KindProducer, payloadTraceKey(msg.Payload))
+	span.SetAttribute("protocol", string(p))
+	span.SetAttribute("msg_type", msg.MsgType)
+	span.SetAttribute("peer", id.Pretty())
+	msg, err := p2p.stampP2PVersion(id, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.End()
+		return err
+	}
+	traceparent := span.Traceparent()
+	err = withFaults(faultProtocol(string(p)), faults.Send, id.Pretty(), msg.Payload, func(payload []byte) error {
+		faulty, err := p2p.withPayload(msg, payload)
+		if err != nil {
+			return err
+		}
+		// the same message may be sent to several peers at once, so the copy carries the traceparent
+		tracedMsg := *faulty
+		if traceparent != "" {
+			tracedMsg.TraceParent = traceparent
+		}
+		return p2p.sendP2PMessage(context.Background(), id, p, &tracedMsg)
+	})
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+// withPayload returns msg with payload, signed again if the payload was changed by fault injection
+func (p2p *P2PService) withPayload(msg *P2PBasicMsg, payload []byte) (*P2PBasicMsg, error) {
+	if bytes.Equal(msg.Payload, payload) {
+		return msg, nil
+	}
+	changed := *msg
+	changed.Payload = payload
+	changed.Sign = nil
+	changed.TraceParent = ""
+	signature, err := p2p.signP2PMessage(&changed)
+	if err != nil {
 		return nil, err
-	// NewP2PMessage(messageId string, gossip bool, payload []byte, msgType string) (newMsg P2PBasicMsg)
-	case "new_p2p_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.NewP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0, args3 string
-		var args1 bool
-		var args2 []byte
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-		_ = castOrUnmarshal(args[3], &args3)
-
-		newMsg := *p2p.NewP2PMessage(args0, args1, args2, args3)
-		return newMsg, nil
-	// SignP2PMessage(message P2PMessage) (signature []byte, err error)  NOT CONCURRENT SAFE
-	case "sign_p2p_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SignP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0 P2PBasicMsg
-		_ = castOrUnmarshal(args[0], &args0)
-
-		sig, err := p2p.signP2PMessage(&args0)
-		return sig, err
-	// SendP2PMessage(id peer.ID, p protocol.ID, msg P2PMessage) error
-	case "send_p2p_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SendP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
-
-		var args0 peer.ID
-		var args1 protocol.ID
-		var args2 P2PBasicMsg
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		ctx := context.Background()
-		err := p2p.sendP2PMessage(ctx, args0, args1, &args2)
+	}
+	changed.Sign = signature
+	return &changed, nil
+}
+
+// stampP2PVersion returns msg in the p2p message version negotiated with id, signed again if the
+// version changed. Messages to peers whose handshake has not completed are sent as they are.
+func (p2p *P2PService) stampP2PVersion(id peer.ID, msg *P2PBasicMsg) (*P2PBasicMsg, error) {
+	agreement, err := p2p.versions.get(id)
+	if err != nil {
+		return msg, nil
+	}
+	agreed := p2pMessageVersion(agreement.Version(version.ProtocolP2P))
+	if agreed == "" || agreed == msg.Version {
+		return msg, nil
+	}
+	stamped := *msg
+	stamped.Version = agreed
+	stamped.Sign = nil
+	stamped.TraceParent = ""
+	signature, err := p2p.signP2PMessage(&stamped)
+	if err != nil {
 		return nil, err
-	// ConnectToP2PNode(nodeP2PConnection string, nodePeerID peer.ID) error
-	case "connect_to_p2p_node":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.ConnectToP2PNodeCounter, pcmn.TelemetryConstants.P2P.Prefix)
+	}
+	stamped.Sign = signature
+	return &stamped, nil
+}
 
-		var args0 string
-		var args1 peer.ID
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (p2p *P2PService) handleJoinGossipTopic(ctx context.Context, protoName string, epoch int) error {
+	return p2p.gossip.join(protoName, epoch)
+}
 
-		err := p2p.ConnectToP2PNode(args0, args1)
-		return nil, err
-	// GetHostAddress() (hostAddres ma.Multiaddr) NOT CONCURRENT SAFE
-	case "get_host_address":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.GetHostAddressCounter, pcmn.TelemetryConstants.P2P.Prefix)
+func (p2p *P2PService) handleGossipP2PMessage(ctx context.Context, protoName string, epoch int, msg *P2PBasicMsg) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.SendP2PMessageCounter, pcmn.TelemetryConstants.P2P.Prefix)
 
-		if p2p.hostAddress == nil {
-			return nil, errors.New("hostAddress not initialized")
+	span := startInstanceSpan("p2p.gossip", tracing.KindProducer, payloadTraceKey(msg.Payload))
+	span.SetAttribute("protocol", protoName)
+	span.SetAttribute("msg_type", msg.MsgType)
+	span.SetAttribute("epoch", epoch)
+	traceparent := span.Traceparent()
+	err := withFaults(faultProtocol(protoName), faults.Send, "", msg.Payload, func(payload []byte) error {
+		faulty, err := p2p.withPayload(msg, payload)
+		if err != nil {
+			return err
+		}
+		tracedMsg := *faulty
+		if traceparent != "" {
+			tracedMsg.TraceParent = traceparent
 		}
-		return p2p.hostAddress.String(), nil
-	default:
-		return nil, fmt.Errorf("P2P service method %v not found", method)
+		return p2p.gossip.publish(protoName, epoch, &tracedMsg)
+	})
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+func (p2p *P2PService) handleReportPeer(ctx context.Context, peerID peer.ID, offence string) error {
+	o, err := parsePeerOffence(offence)
+	if err != nil {
+		return err
+	}
+	p2p.scores.report(peerID, o)
+	return nil
+}
+
+func (p2p *P2PService) handlePeerScores(ctx context.Context) ([]PeerScore, error) {
+	return p2p.scores.scores(), nil
+}
+
+func (p2p *P2PService) handlePeerHealth(ctx context.Context) ([]PeerHealth, error) {
+	return p2p.health.table(), nil
+}
+
+func (p2p *P2PService) handlePeerVersions(ctx context.Context, peerID peer.ID) (version.Agreement, error) {
+	return p2p.versions.get(peerID)
+}
+
+func (p2p *P2PService) handleConnectToP2PNode(ctx context.Context, nodeP2PConnection string, nodePeerID peer.ID) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.ConnectToP2PNodeCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	return p2p.ConnectToP2PNode(nodeP2PConnection, nodePeerID)
+}
+
+// handleGetHostAddress is NOT CONCURRENT SAFE
+func (p2p *P2PService) handleGetHostAddress(ctx context.Context) (string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.GetHostAddressCounter, pcmn.TelemetryConstants.P2P.Prefix)
+
+	if p2p.hostAddress == nil {
+		return "", errors.New("hostAddress not initialized")
 	}
+	return p2p.hostAddress.String(), nil
 }
 
 func (p2p *P2PService) SetBaseService(bs *BaseService) {
@@ -389,7 +580,7 @@ func authenticateMessage(data P2PMessage) error {
 	bin := data.GetSerializedBody()
 	// verify the data was authored by the signing peer identified by the public key
 	// and signature included in the message
-	_, err = p2pServiceLibrary.EthereumMethods().VerifyDataWithNodelist(pk, data.GetSign(), bin)
+	_, err = p2pServiceLibrary.EthereumMethods().VerifyDataWithNodelist(context.Background(), pk, data.GetSign(), bin)
 	return err
 }
 
@@ -412,7 +603,7 @@ func (p2pService *P2PService) signP2PMessage(message P2PMessage) ([]byte, error)
 	if err != nil {
 		return nil, err
 	}
-	return p2pService.signData(data), nil
+	return p2pService.signData(data)
 }
 
 // verifySigOnMsg -  verifies that the corresponding message signature
@@ -425,7 +616,7 @@ func verifySigOnMsg(data []byte, signature []byte, rawPubKey []byte) error {
 	}
 
 	// Check validity of signature
-	valid := crypto.VerifyPtFromRaw(data, pk, signature)
+	valid := verifyNodeSignature(data, pk, signature)
 	if !valid {
 		return fmt.Errorf("invalid ecdsa sig in verifySigOnMsg  %v", data)
 	}
@@ -437,7 +628,7 @@ func verifySigOnMsg(data []byte, signature []byte, rawPubKey []byte) error {
 func (p2pService *P2PService) NewP2PMessage(messageId string, gossip bool, payload []byte, msgType string) *P2PBasicMsg {
 	// Add protobufs bin data for message author public key
 	// this is useful for authenticating  messages forwarded by a node authored by another node
-	ptPk := p2pServiceLibrary.EthereumMethods().GetSelfPublicKey()
+	ptPk := p2pServiceLibrary.EthereumMethods().GetSelfPublicKey(context.Background())
 	rawPk, err := bijson.Marshal(ptPk)
 	if err != nil {
 		logging.Error("could not marshal pk in newp2pmessage" + err.Error())
@@ -454,36 +645,13 @@ func (p2pService *P2PService) NewP2PMessage(messageId string, gossip bool, paylo
 	return &p2pBasicMsg
 }
 
-// helper method - writes a p2pMessage go data object to a network stream
-// s: network stream to write the data to
+// helper method - writes a p2pMessage go data object to the stream of the peer for protocol p
 func (localHost *P2PService) sendP2PMessage(ctx context.Context, id peer.ID, p protocol.ID, msg P2PMessage) error {
 	data, err := bijson.Marshal(msg)
 	if err != nil {
 		return err
 	}
-
-	s, err := localHost.host.NewStream(ctx, id, p)
-	if err != nil {
-		return err
-	}
-	_, err = s.Write(data)
-	if err != nil {
-		e := s.Reset()
-		if e != nil {
-			logging.WithError(e).Error("could not reset stream")
-		}
-		return err
-	}
-	// FullClose closes the stream and waits for the other side to close their half.
-	err = helpers.FullClose(s)
-	if err != nil {
-		e := s.Reset()
-		if e != nil {
-			logging.WithError(e).Error("could not reset stream")
-		}
-		return err
-	}
-	return nil
+	return localHost.streams.send(ctx, id, p, data)
 }
 
 func (p2p *P2PService) ConnectToP2PNode(nodeP2PConnection string, nodePeerID peer.ID) error {
@@ -0,0 +1,221 @@
+package dkgnode
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/telemetry"
+)
+
+const (
+	defaultPeerHealthInterval  = 30 * time.Second
+	defaultPeerOfflineFailures = 3
+	// peerListTimeout bounds waiting for the epochs and node lists, which are retried until they are known
+	peerListTimeout = 5 * time.Second
+)
+
+// PeerHealth is what pinging a peer found out about it
+type PeerHealth struct {
+	PeerID        string     `json:"peerID"`
+	Epochs        []int      `json:"epochs"`
+	LastSeen      *time.Time `json:"lastSeen,omitempty"`
+	LastRTTMs     float64    `json:"lastRTTMs"`
+	Pings         int        `json:"pings"`
+	Failures      int        `json:"failures"`
+	FailureStreak int        `json:"failureStreak"`
+	Offline       bool       `json:"offline"`
+}
+
+type peerHealth struct {
+	epochs        []int
+	lastSeen      time.Time
+	lastRTT       time.Duration
+	pings         int
+	failures      int
+	failureStreak int
+}
+
+// peerHealthTable keeps the results of pinging the nodes of the current and next epoch. Peers
+// that did not respond to offlineAfter pings in a row are considered offline until they respond again.
+type peerHealthTable struct {
+	offlineAfter int
+	now          func() time.Time
+	metrics      *telemetry.PeerHealthMetrics
+
+	lock  sync.Mutex
+	peers map[peer.ID]*peerHealth
+}
+
+func newPeerHealthTable(offlineAfter int) *peerHealthTable {
+	return &peerHealthTable{
+		offlineAfter: offlineAfter,
+		now:          time.Now,
+		metrics:      telemetry.NewPeerHealthMetrics(),
+		peers:        make(map[peer.ID]*peerHealth),
+	}
+}
+
+func newPeerHealthTableFromConfig() *peerHealthTable {
+	offlineAfter := config.GlobalConfig.PeerOfflineFailures
+	if offlineAfter <= 0 {
+		offlineAfter = defaultPeerOfflineFailures
+	}
+	return newPeerHealthTable(offlineAfter)
+}
+
+// peerHealthInterval is how often the nodes of the current and next epoch are pinged
+func peerHealthInterval() time.Duration {
+	if config.GlobalConfig.PeerHealthIntervalS > 0 {
+		return time.Duration(config.GlobalConfig.PeerHealthIntervalS) * time.Second
+	}
+	return defaultPeerHealthInterval
+}
+
+// setPeers replaces the monitored peers with peers and the epochs they are in, the results of
+// peers that are still monitored are kept
+func (t *peerHealthTable) setPeers(peers map[peer.ID][]int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for id := range t.peers {
+		if _, ok := peers[id]; !ok {
+			delete(t.peers, id)
+			t.metrics.Remove(id.Pretty())
+		}
+	}
+	for id, epochs := range peers {
+		ph, ok := t.peers[id]
+		if !ok {
+			ph = &peerHealth{}
+			t.peers[id] = ph
+		}
+		ph.epochs = epochs
+	}
+}
+
+// success records a ping that id responded to after rtt, pings to peers that are not monitored are ignored
+func (t *peerHealthTable) success(id peer.ID, rtt time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	ph, ok := t.peers[id]
+	if !ok {
+		return
+	}
+	if ph.failureStreak >= t.offlineAfter {
+		logging.WithField("peer", id.Pretty()).Info("peer is back online")
+	}
+	now := t.now()
+	ph.pings++
+	ph.lastSeen = now
+	ph.lastRTT = rtt
+	ph.failureStreak = 0
+	t.metrics.Success(id.Pretty(), rtt.Seconds(), float64(now.Unix()))
+}
+
+// failure records a ping that id did not respond to
+func (t *peerHealthTable) failure(id peer.ID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	ph, ok := t.peers[id]
+	if !ok {
+		return
+	}
+	ph.pings++
+	ph.failures++
+	ph.failureStreak++
+	if ph.failureStreak == t.offlineAfter {
+		logging.WithFields(logging.Fields{
+			"peer":   id.Pretty(),
+			"epochs": ph.epochs,
+		}).Warn("peer did not respond to pings, it is considered offline")
+	}
+	t.metrics.Failure(id.Pretty(), ph.failureStreak)
+}
+
+// table returns the health of the monitored peers, offline peers and the longest failure streaks first
+func (t *peerHealthTable) table() []PeerHealth {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	table := make([]PeerHealth, 0, len(t.peers))
+	for id, ph := range t.peers {
+		health := PeerHealth{
+			PeerID:        id.Pretty(),
+			Epochs:        append([]int(nil), ph.epochs...),
+			LastRTTMs:     float64(ph.lastRTT) / float64(time.Millisecond),
+			Pings:         ph.pings,
+			Failures:      ph.failures,
+			FailureStreak: ph.failureStreak,
+			Offline:       ph.failureStreak >= t.offlineAfter,
+		}
+		if !ph.lastSeen.IsZero() {
+			lastSeen := ph.lastSeen
+			health.LastSeen = &lastSeen
+		}
+		table = append(table, health)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].FailureStreak != table[j].FailureStreak {
+			return table[i].FailureStreak > table[j].FailureStreak
+		}
+		return table[i].PeerID < table[j].PeerID
+	})
+	return table
+}
+
+// monitoredPeers returns the peers of the nodes in the current and next epoch, other than this
+// node, with the epochs they are in. The next epoch is skipped until its nodes are known.
+func monitoredPeers(ctx context.Context, serviceLibrary ServiceLibrary, self peer.ID) map[peer.ID][]int {
+	peers := make(map[peer.ID][]int)
+	epochCtx, cancel := context.WithTimeout(ctx, peerListTimeout)
+	defer cancel()
+	currentEpoch := serviceLibrary.EthereumMethods().GetCurrentEpoch(epochCtx)
+	if currentEpoch == 0 {
+		return peers
+	}
+	epochs := []int{currentEpoch}
+	nextEpoch, err := serviceLibrary.EthereumMethods().GetNextEpoch(epochCtx)
+	if err == nil && nextEpoch != 0 && nextEpoch != currentEpoch {
+		epochs = append(epochs, nextEpoch)
+	}
+	for _, epoch := range epochs {
+		listCtx, cancel := context.WithTimeout(ctx, peerListTimeout)
+		nodeRefs := serviceLibrary.EthereumMethods().GetNodeList(listCtx, epoch)
+		cancel()
+		for _, nodeRef := range nodeRefs {
+			if nodeRef.PeerID == "" || nodeRef.PeerID == self {
+				continue
+			}
+			peers[nodeRef.PeerID] = append(peers[nodeRef.PeerID], epoch)
+		}
+	}
+	return peers
+}
+
+// monitorPeerHealth pings the nodes of the current and next epoch every peerHealthInterval until ctx is done
+func (p2p *P2PService) monitorPeerHealth(ctx context.Context) {
+	ticker := nodeClock.NewTicker(peerHealthInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		peers := monitoredPeers(ctx, p2pServiceLibrary, p2p.host.ID())
+		if ctx.Err() != nil {
+			return
+		}
+		p2p.health.setPeers(peers)
+		for id := range peers {
+			go func(id peer.ID) {
+				if err := p2p.pingProto.HealthPing(id); err != nil {
+					logging.WithField("peer", id.Pretty()).WithError(err).Debug("could not ping peer")
+				}
+			}(id)
+		}
+	}
+}
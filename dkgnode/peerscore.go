@@ -0,0 +1,236 @@
+package dkgnode
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/crypto"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/telemetry"
+)
+
+// peerOffence is a kind of misbehaviour that lowers the score of a peer
+type peerOffence string
+
+const (
+	offenceMalformedMessage peerOffence = "malformed_message"
+	offenceUnauthenticated  peerOffence = "unauthenticated"
+	offenceInvalidProtocol  peerOffence = "invalid_protocol_message"
+	offencePingTimeout      peerOffence = "ping_timeout"
+)
+
+// peerOffencePenalties are subtracted from the score of a peer for each offence. Ping timeouts
+// are cheap as honest nodes restart, invalid commitments and proofs can only be sent on purpose.
+var peerOffencePenalties = map[peerOffence]float64{
+	offenceMalformedMessage: 10,
+	offenceUnauthenticated:  25,
+	offenceInvalidProtocol:  50,
+	offencePingTimeout:      5,
+}
+
+const (
+	defaultPeerBanThreshold  = 100
+	defaultPeerBanDuration   = 10 * time.Minute
+	defaultPeerScoreHalfLife = 10 * time.Minute
+)
+
+// PeerScore is the reputation of a peer
+type PeerScore struct {
+	PeerID      string         `json:"peerID"`
+	Score       float64        `json:"score"`
+	Offences    map[string]int `json:"offences"`
+	BannedUntil *time.Time     `json:"bannedUntil,omitempty"`
+}
+
+type peerScore struct {
+	score       float64
+	updated     time.Time
+	offences    map[peerOffence]int
+	bannedUntil time.Time
+}
+
+// peerScores keeps the reputation of peers. Scores start at 0, drop with every offence and
+// recover towards 0 with halfLife. Peers whose score falls to -threshold are banned for banDuration.
+type peerScores struct {
+	threshold   float64
+	banDuration time.Duration
+	halfLife    time.Duration
+	now         func() time.Time
+	onBan       func(peer.ID)
+	metrics     *telemetry.PeerScoreMetrics
+
+	lock  sync.Mutex
+	peers map[peer.ID]*peerScore
+}
+
+func newPeerScores(threshold float64, banDuration, halfLife time.Duration, onBan func(peer.ID)) *peerScores {
+	return &peerScores{
+		threshold:   threshold,
+		banDuration: banDuration,
+		halfLife:    halfLife,
+		now:         time.Now,
+		onBan:       onBan,
+		metrics:     telemetry.NewPeerScoreMetrics(),
+		peers:       make(map[peer.ID]*peerScore),
+	}
+}
+
+// newPeerScoresFromConfig creates peerScores with the thresholds in the global config
+func newPeerScoresFromConfig(onBan func(peer.ID)) *peerScores {
+	threshold := float64(defaultPeerBanThreshold)
+	if config.GlobalConfig.PeerBanThreshold > 0 {
+		threshold = float64(config.GlobalConfig.PeerBanThreshold)
+	} else if config.GlobalConfig.PeerBanThreshold < 0 {
+		threshold = 0
+	}
+	banDuration := defaultPeerBanDuration
+	if config.GlobalConfig.PeerBanDurationS > 0 {
+		banDuration = time.Duration(config.GlobalConfig.PeerBanDurationS) * time.Second
+	}
+	halfLife := defaultPeerScoreHalfLife
+	if config.GlobalConfig.PeerScoreHalfLifeS > 0 {
+		halfLife = time.Duration(config.GlobalConfig.PeerScoreHalfLifeS) * time.Second
+	}
+	return newPeerScores(threshold, banDuration, halfLife, onBan)
+}
+
+// update decays the score of id and ends its ban if it expired, it must be called with the lock held
+func (s *peerScores) update(id peer.ID, now time.Time) *peerScore {
+	ps, ok := s.peers[id]
+	if !ok {
+		ps = &peerScore{updated: now, offences: make(map[peerOffence]int)}
+		s.peers[id] = ps
+		return ps
+	}
+	if !ps.bannedUntil.IsZero() && !now.Before(ps.bannedUntil) {
+		ps.bannedUntil = time.Time{}
+		ps.score = 0
+		s.metrics.Banned(id.Pretty(), false)
+		logging.WithField("peer", id.Pretty()).Info("ban of peer ended")
+	}
+	if elapsed := now.Sub(ps.updated); elapsed > 0 && s.halfLife > 0 {
+		ps.score *= math.Pow(0.5, elapsed.Seconds()/s.halfLife.Seconds())
+	}
+	ps.updated = now
+	return ps
+}
+
+// report lowers the score of id for offence and bans it if the score falls to the threshold,
+// it returns true if the peer was banned by this offence
+func (s *peerScores) report(id peer.ID, offence peerOffence) bool {
+	penalty, ok := peerOffencePenalties[offence]
+	if !ok {
+		logging.WithField("offence", offence).Error("unknown peer offence")
+		return false
+	}
+	s.lock.Lock()
+	now := s.now()
+	ps := s.update(id, now)
+	ps.score -= penalty
+	ps.offences[offence]++
+	s.metrics.Offence(id.Pretty(), string(offence), ps.score)
+	banned := s.threshold > 0 && ps.bannedUntil.IsZero() && ps.score <= -s.threshold
+	if banned {
+		ps.bannedUntil = now.Add(s.banDuration)
+		s.metrics.Banned(id.Pretty(), true)
+	}
+	score := ps.score
+	s.lock.Unlock()
+
+	logging.WithFields(logging.Fields{
+		"peer":    id.Pretty(),
+		"offence": offence,
+		"score":   score,
+	}).Debug("peer offence reported")
+	if banned {
+		logging.WithFields(logging.Fields{
+			"peer":     id.Pretty(),
+			"score":    score,
+			"duration": s.banDuration,
+		}).Warn("banning peer")
+		if s.onBan != nil {
+			s.onBan(id)
+		}
+	}
+	return banned
+}
+
+// banned returns true if id is banned
+func (s *peerScores) banned(id peer.ID) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.peers[id]; !ok {
+		return false
+	}
+	return !s.update(id, s.now()).bannedUntil.IsZero()
+}
+
+// scores returns the scores of every peer that committed an offence, lowest first
+func (s *peerScores) scores() []PeerScore {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	scores := make([]PeerScore, 0, len(s.peers))
+	for id := range s.peers {
+		ps := s.update(id, now)
+		s.metrics.Score(id.Pretty(), ps.score)
+		score := PeerScore{
+			PeerID:   id.Pretty(),
+			Score:    ps.score,
+			Offences: make(map[string]int, len(ps.offences)),
+		}
+		for offence, count := range ps.offences {
+			score.Offences[string(offence)] = count
+		}
+		if !ps.bannedUntil.IsZero() {
+			bannedUntil := ps.bannedUntil
+			score.BannedUntil = &bannedUntil
+		}
+		scores = append(scores, score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score < scores[j].Score
+		}
+		return scores[i].PeerID < scores[j].PeerID
+	})
+	return scores
+}
+
+// reportNode reports an offence of the node with pubKey, such as an invalid commitment found by
+// a protocol, to the P2P service. It does not block as protocols report while holding their locks.
+func reportNode(serviceLibrary ServiceLibrary, pubKey common.Point, offence peerOffence, reason string) {
+	go func() {
+		ethNodeDetails := serviceLibrary.EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(pubKey))
+		peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
+		if err != nil {
+			logging.WithField("reason", reason).WithError(err).Error("could not get peer ID of node to report")
+			return
+		}
+		logging.WithFields(logging.Fields{
+			"peer":    peerID.Pretty(),
+			"offence": offence,
+			"reason":  reason,
+		}).Warn("reporting node")
+		err = serviceLibrary.P2PMethods().ReportPeer(context.Background(), *peerID, string(offence))
+		if err != nil {
+			logging.WithError(err).Error("could not report node")
+		}
+	}()
+}
+
+// parsePeerOffence returns the offence named s
+func parsePeerOffence(s string) (peerOffence, error) {
+	offence := peerOffence(s)
+	if _, ok := peerOffencePenalties[offence]; !ok {
+		return "", fmt.Errorf("unknown peer offence %v", s)
+	}
+	return offence, nil
+}
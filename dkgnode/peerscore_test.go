@@ -0,0 +1,107 @@
+package dkgnode
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+type peerScoreStep struct {
+	// advance moves the clock before the step
+	advance time.Duration
+	// offence is reported if set, reportBan is what report returns
+	offence   peerOffence
+	reportBan bool
+	banned    bool
+	score     float64
+}
+
+func TestPeerScores(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		steps     []peerScoreStep
+	}{
+		{
+			name:      "score decays with half life",
+			threshold: 100,
+			steps: []peerScoreStep{
+				{offence: offenceInvalidProtocol, score: -50},
+				{advance: 10 * time.Minute, score: -25},
+				{advance: 10 * time.Minute, offence: offenceMalformedMessage, score: -22.5},
+			},
+		},
+		{
+			name:      "peer is banned when its score falls to the threshold",
+			threshold: 100,
+			steps: []peerScoreStep{
+				{offence: offenceInvalidProtocol, score: -50},
+				{offence: offenceUnauthenticated, score: -75},
+				{offence: offenceUnauthenticated, reportBan: true, banned: true, score: -100},
+				// a banned peer is not banned again
+				{offence: offenceInvalidProtocol, banned: true, score: -150},
+			},
+		},
+		{
+			name:      "ban expires and resets the score",
+			threshold: 100,
+			steps: []peerScoreStep{
+				{offence: offenceInvalidProtocol, score: -50},
+				{offence: offenceInvalidProtocol, reportBan: true, banned: true, score: -100},
+				{advance: 5 * time.Minute, banned: true, score: -100 * math.Pow(0.5, 0.5)},
+				{advance: 5 * time.Minute, score: 0},
+				{offence: offencePingTimeout, score: -5},
+			},
+		},
+		{
+			name:      "threshold 0 disables bans",
+			threshold: 0,
+			steps: []peerScoreStep{
+				{offence: offenceInvalidProtocol, score: -50},
+				{offence: offenceInvalidProtocol, score: -100},
+				{offence: offenceInvalidProtocol, score: -150},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Unix(1600000000, 0)
+			var bans []peer.ID
+			scores := newPeerScores(test.threshold, 10*time.Minute, 10*time.Minute, func(id peer.ID) {
+				bans = append(bans, id)
+			})
+			scores.now = func() time.Time { return now }
+			id := peer.ID("peer")
+			expectedBans := 0
+			for i, step := range test.steps {
+				now = now.Add(step.advance)
+				if step.offence != "" {
+					if banned := scores.report(id, step.offence); banned != step.reportBan {
+						t.Fatalf("step %v: report returned %v, expected %v", i, banned, step.reportBan)
+					}
+				}
+				if step.reportBan {
+					expectedBans++
+				}
+				if banned := scores.banned(id); banned != step.banned {
+					t.Fatalf("step %v: banned is %v, expected %v", i, banned, step.banned)
+				}
+				current := scores.scores()
+				if len(current) != 1 {
+					t.Fatalf("step %v: expected the score of one peer, got %v", i, len(current))
+				}
+				if math.Abs(current[0].Score-step.score) > 1e-9 {
+					t.Fatalf("step %v: score is %v, expected %v", i, current[0].Score, step.score)
+				}
+				if (current[0].BannedUntil != nil) != step.banned {
+					t.Fatalf("step %v: bannedUntil is %v, expected banned %v", i, current[0].BannedUntil, step.banned)
+				}
+			}
+			if len(bans) != expectedBans {
+				t.Fatalf("expected onBan to be called %v times, got %v", expectedBans, len(bans))
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package dkgnode
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"sync"
+	"time"
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/telemetry"
@@ -13,16 +15,30 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-node/framing"
 )
 
 // pattern: /protocol-name/request-or-response-message/version
 const pingRequest = "/ping/pingreq/0.0.1"
 const pingResponse = "/ping/pingresp/0.0.1"
 
+// pingMaxMessageSize bounds ping requests and responses, which carry a short message
+const pingMaxMessageSize = 64 << 10
+
+// pingTimeout is how long a peer has to respond to a ping before it is reported
+const pingTimeout = 30 * time.Second
+
 // PingProtocol type
 type PingProtocol struct {
 	p2pService *P2PService
-	requests   map[string]*P2PBasicMsg // used to access request data from response handlers
+	lock       sync.Mutex
+	requests   map[string]pendingPing // used to access request data from response handlers
+}
+
+// pendingPing is a ping that was sent and not responded to yet
+type pendingPing struct {
+	peer peer.ID
+	sent time.Time
 }
 
 type Ping struct {
@@ -30,7 +46,7 @@ type Ping struct {
 }
 
 func NewPingProtocol(p2pService *P2PService) *PingProtocol {
-	p := &PingProtocol{p2pService: p2pService, requests: make(map[string]*P2PBasicMsg)}
+	p := &PingProtocol{p2pService: p2pService, requests: make(map[string]pendingPing)}
 	p2pService.host.SetStreamHandler(pingRequest, p.onPingRequest)
 	p2pService.host.SetStreamHandler(pingResponse, p.onPingResponse)
 	return p
@@ -38,9 +54,13 @@ func NewPingProtocol(p2pService *P2PService) *PingProtocol {
 
 // remote peer requests handler
 func (p *PingProtocol) onPingRequest(s inet.Stream) {
+	if p.p2pService.scores.banned(s.Conn().RemotePeer()) {
+		_ = s.Reset()
+		return
+	}
 	// get request data
 	data := &P2PBasicMsg{}
-	buf, err := ioutil.ReadAll(s)
+	buf, err := framing.ReadAll(s, pingMaxMessageSize)
 	if err != nil {
 		e := s.Reset()
 		if e != nil {
@@ -58,12 +78,14 @@ func (p *PingProtocol) onPingRequest(s inet.Stream) {
 	err = bijson.Unmarshal(buf, data)
 	if err != nil {
 		logging.WithError(err).Error("could not unmarshal data in onpingrequest")
+		p.p2pService.scores.report(s.Conn().RemotePeer(), offenceMalformedMessage)
 		return
 	}
 
 	err = verifySigOnMsg(data.GetSerializedBody(), data.GetSign(), data.GetNodePubKey())
 	if err != nil {
 		logging.WithError(err).Error("Failed to verify p2pMsg error")
+		p.p2pService.scores.report(s.Conn().RemotePeer(), offenceUnauthenticated)
 		return
 	}
 
@@ -77,7 +99,7 @@ func (p *PingProtocol) onPingRequest(s inet.Stream) {
 	resp := p.p2pService.NewP2PMessage(data.GetId(), false, pingBytes, "")
 
 	// sign the data
-	signature, err := p2pServiceLibrary.P2PMethods().SignP2PMessage(resp)
+	signature, err := p2pServiceLibrary.P2PMethods().SignP2PMessage(context.Background(), resp)
 	if err != nil {
 		logging.Error("failed to sign response")
 		return
@@ -87,7 +109,7 @@ func (p *PingProtocol) onPingRequest(s inet.Stream) {
 	resp.Sign = signature
 
 	// send the response
-	err = p2pServiceLibrary.P2PMethods().SendP2PMessage(s.Conn().RemotePeer(), pingResponse, resp)
+	err = p2pServiceLibrary.P2PMethods().SendP2PMessage(context.Background(), s.Conn().RemotePeer(), pingResponse, resp)
 
 	if err == nil {
 		logging.WithFields(logging.Fields{
@@ -99,8 +121,12 @@ func (p *PingProtocol) onPingRequest(s inet.Stream) {
 
 // remote ping response handler
 func (p *PingProtocol) onPingResponse(s inet.Stream) {
+	if p.p2pService.scores.banned(s.Conn().RemotePeer()) {
+		_ = s.Reset()
+		return
+	}
 	data := &P2PBasicMsg{}
-	buf, err := ioutil.ReadAll(s)
+	buf, err := framing.ReadAll(s, pingMaxMessageSize)
 	if err != nil {
 		logging.WithError(err).Error("could not read from buffer")
 		e := s.Reset()
@@ -118,12 +144,14 @@ func (p *PingProtocol) onPingResponse(s inet.Stream) {
 	err = bijson.Unmarshal(buf, data)
 	if err != nil {
 		logging.WithError(err).Error("could not unmarshal data in onpingresponse")
+		p.p2pService.scores.report(s.Conn().RemotePeer(), offenceMalformedMessage)
 		return
 	}
 
 	err = verifySigOnMsg(data.GetSerializedBody(), data.GetSign(), data.GetNodePubKey())
 	if err != nil {
 		logging.Error("Failed to verifySigOnMsg message")
+		p.p2pService.scores.report(s.Conn().RemotePeer(), offenceUnauthenticated)
 		return
 	}
 
@@ -135,14 +163,18 @@ func (p *PingProtocol) onPingResponse(s inet.Stream) {
 	}
 
 	// locate request data and remove it if found
-	_, ok := p.requests[data.GetId()]
-	if ok {
+	p.lock.Lock()
+	request, ok := p.requests[data.GetId()]
+	if ok && request.peer == s.Conn().RemotePeer() {
 		// remove request from map as we have processed it here
 		delete(p.requests, data.GetId())
-	} else {
+	}
+	p.lock.Unlock()
+	if !ok || request.peer != s.Conn().RemotePeer() {
 		logging.Error("Failed to locate request data object for response")
 		return
 	}
+	p.p2pService.health.success(request.peer, time.Since(request.sent))
 
 	logging.WithFields(logging.Fields{
 		"LocalPeer":  s.Conn().LocalPeer(),
@@ -152,24 +184,34 @@ func (p *PingProtocol) onPingResponse(s inet.Stream) {
 	}).Debug("received ping response")
 }
 
-// Pings a peer
+// Pings a peer, a timeout is reported to the peer scores
 func (p *PingProtocol) Ping(peerID peer.ID) error {
+	return p.ping(peerID, true)
+}
+
+// HealthPing pings a peer for the health table only, timeouts are not offences as the
+// peers monitored include nodes that are restarting or not yet running
+func (p *PingProtocol) HealthPing(peerID peer.ID) error {
+	return p.ping(peerID, false)
+}
+
+func (p *PingProtocol) ping(peerID peer.ID, reportTimeout bool) error {
 	logging.WithFields(logging.Fields{
-		"From": p2pServiceLibrary.P2PMethods().ID(),
+		"From": p2pServiceLibrary.P2PMethods().ID(context.Background()),
 		"To":   peerID,
 	}).Debug("sending ping")
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Ping.Prefix)
 
-	pBytes, err := bijson.Marshal(Ping{fmt.Sprintf("Ping from %s", p2pServiceLibrary.P2PMethods().ID())})
+	pBytes, err := bijson.Marshal(Ping{fmt.Sprintf("Ping from %s", p2pServiceLibrary.P2PMethods().ID(context.Background()))})
 	if err != nil {
-		return fmt.Errorf("Failed marshal ping %v", Ping{fmt.Sprintf("Ping from %s", p2pServiceLibrary.P2PMethods().ID())})
+		return fmt.Errorf("Failed marshal ping %v", Ping{fmt.Sprintf("Ping from %s", p2pServiceLibrary.P2PMethods().ID(context.Background()))})
 
 	}
 	// create message data
-	req := p2pServiceLibrary.P2PMethods().NewP2PMessage(uuid.New().String(), false, pBytes, "")
+	req := p2pServiceLibrary.P2PMethods().NewP2PMessage(context.Background(), uuid.New().String(), false, pBytes, "")
 
 	// sign the data
-	signature, err := p2pServiceLibrary.P2PMethods().SignP2PMessage(&req)
+	signature, err := p2pServiceLibrary.P2PMethods().SignP2PMessage(context.Background(), &req)
 	if err != nil {
 		return fmt.Errorf("Failed to sign pb data: %s", err.Error())
 	}
@@ -177,13 +219,33 @@ func (p *PingProtocol) Ping(peerID peer.ID) error {
 	// add the signature to the message
 	req.Sign = signature
 
-	err = p2pServiceLibrary.P2PMethods().SendP2PMessage(peerID, pingRequest, &req)
+	// store ref request so response handler has access to it, before sending as the response may arrive first
+	p.lock.Lock()
+	p.requests[req.GetId()] = pendingPing{peer: peerID, sent: time.Now()}
+	p.lock.Unlock()
+
+	err = p2pServiceLibrary.P2PMethods().SendP2PMessage(context.Background(), peerID, pingRequest, &req)
 	if err != nil {
+		p.lock.Lock()
+		delete(p.requests, req.GetId())
+		p.lock.Unlock()
+		p.p2pService.health.failure(peerID)
 		return fmt.Errorf("Failed to send proto message: %s", err.Error())
 	}
 
-	// store ref request so response handler has access to it
-	p.requests[req.GetId()] = &req
+	time.AfterFunc(pingTimeout, func() {
+		p.lock.Lock()
+		_, pending := p.requests[req.GetId()]
+		delete(p.requests, req.GetId())
+		p.lock.Unlock()
+		if pending {
+			logging.WithField("peer", peerID.Pretty()).Warn("ping timed out")
+			if reportTimeout {
+				p.p2pService.scores.report(peerID, offencePingTimeout)
+			}
+			p.p2pService.health.failure(peerID)
+		}
+	})
 
 	return nil
 }
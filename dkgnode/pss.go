@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/version"
 
 	"github.com/libp2p/go-libp2p-core/protocol"
 	pcmn "github.com/torusresearch/torus-node/common"
@@ -27,7 +28,7 @@ import (
 
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/pss"
-	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/secret"
 )
 
 type PSSProtocolPrefix string
@@ -84,172 +85,148 @@ func (p *PSSService) OnStop() error {
 	return nil
 }
 
-func (p *PSSService) Call(method string, args ...interface{}) (interface{}, error) {
+func (p *PSSService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.PSS.Prefix)
 
-	switch method {
-	case "PSS_instance_exists":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.PSSInstanceExistsCounter, pcmn.TelemetryConstants.PSS.Prefix)
+	return dispatchPSS(ctx, p, method, args)
+}
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+func (p *PSSService) handlePSSInstanceExists(ctx context.Context, protocolPrefix PSSProtocolPrefix) (bool, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.PSSInstanceExistsCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		return p.PSSInstanceExists(args0), nil
-	case "get_PSS_protocol_prefix":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetPSSProtocolPrefixCounter, pcmn.TelemetryConstants.PSS.Prefix)
+	return p.PSSInstanceExists(protocolPrefix), nil
+}
 
-		var args0, args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (p *PSSService) handleGetPSSProtocolPrefix(ctx context.Context, oldEpoch int, newEpoch int) (PSSProtocolPrefix, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetPSSProtocolPrefixCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		return p.GetPSSProtocolPrefix(args0, args1), nil
-	case "receive_BFT_message":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.PSS.Prefix)
+	return p.GetPSSProtocolPrefix(oldEpoch, newEpoch), nil
+}
 
-		var args0 PSSProtocolPrefix
-		var args1 pss.PSSMessage
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
+func (p *PSSService) handleReceiveBFTMessage(ctx context.Context, protocolPrefix PSSProtocolPrefix, pssMessage pss.PSSMessage) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.ReceiveBFTMessageCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		pssProtocolPrefix := args0
-		pssMessage := args1
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return nil, p.PSSNodeInstances[pssProtocolPrefix].Transport.ReceiveBroadcast(pssMessage)
-	case "get_new_nodes_n":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesNCounter, pcmn.TelemetryConstants.PSS.Prefix)
+	if !p.PSSInstanceExists(protocolPrefix) {
+		return fmt.Errorf("PSS instance not available %v", protocolPrefix)
+	}
+	return p.PSSNodeInstances[protocolPrefix].Transport.ReceiveBroadcast(pssMessage)
+}
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+// pssNode returns the PSS node for protocolPrefix or an error if it does not exist
+func (p *PSSService) pssNode(protocolPrefix PSSProtocolPrefix) (*pss.PSSNode, error) {
+	if !p.PSSInstanceExists(protocolPrefix) {
+		return nil, fmt.Errorf("PSS instance not available %v", protocolPrefix)
+	}
+	return p.PSSNodeInstances[protocolPrefix], nil
+}
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].NewNodes.N, nil
-	case "get_new_nodes_k":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesKCounter, pcmn.TelemetryConstants.PSS.Prefix)
+func (p *PSSService) handleGetNewNodesN(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesNCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return pssNode.NewNodes.N, nil
+}
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].NewNodes.K, nil
-	case "get_new_nodes_t":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesTCounter, pcmn.TelemetryConstants.PSS.Prefix)
+func (p *PSSService) handleGetNewNodesK(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesKCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return pssNode.NewNodes.K, nil
+}
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].NewNodes.T, nil
-	case "get_old_nodes_n":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesNCounter, pcmn.TelemetryConstants.PSS.Prefix)
+func (p *PSSService) handleGetNewNodesT(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetNewNodesTCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return pssNode.NewNodes.T, nil
+}
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].OldNodes.N, nil
-	case "get_old_nodes_k":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesKCounter, pcmn.TelemetryConstants.PSS.Prefix)
+func (p *PSSService) handleGetOldNodesN(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesNCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return pssNode.OldNodes.N, nil
+}
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].OldNodes.K, nil
-	case "get_old_nodes_t":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesTCounter, pcmn.TelemetryConstants.PSS.Prefix)
+func (p *PSSService) handleGetOldNodesK(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesKCounter, pcmn.TelemetryConstants.PSS.Prefix)
+
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return pssNode.OldNodes.K, nil
+}
 
-		var args0 PSSProtocolPrefix
-		_ = castOrUnmarshal(args[0], &args0)
+func (p *PSSService) handleGetOldNodesT(ctx context.Context, protocolPrefix PSSProtocolPrefix) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.GetOldNodesTCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
-		pssProtocolPrefix := args0
-		if !p.PSSInstanceExists(pssProtocolPrefix) {
-			return nil, fmt.Errorf("PSS instance not available %v", pssProtocolPrefix)
-		}
-		return p.PSSNodeInstances[pssProtocolPrefix].OldNodes.T, nil
-	case "new_PSS_node":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.NewPSSNodeCounter, pcmn.TelemetryConstants.PSS.Prefix)
-
-		var args0 PSSStartData
-		var args1, args2 bool
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		pssSendMsg := args0
-		isDealer := args1
-		isPlayer := args2
-		protocolPrefix := p.GetPSSProtocolPrefix(pssSendMsg.OldEpoch, pssSendMsg.NewEpoch)
-		oldNodeList := getCommonNodesFromNodeRefArray(pssServiceLibrary.EthereumMethods().AwaitCompleteNodeList(pssSendMsg.OldEpoch))
-		newNodeList := getCommonNodesFromNodeRefArray(pssServiceLibrary.EthereumMethods().AwaitCompleteNodeList(pssSendMsg.NewEpoch))
-		selfPubKey := pssServiceLibrary.EthereumMethods().GetSelfPublicKey()
-		p.PSSNodeInstances[protocolPrefix] = pss.NewPSSNode(
-			pcmn.Node{
-				Index:  pssServiceLibrary.EthereumMethods().GetSelfIndex(),
-				PubKey: selfPubKey,
-			},
-			pssSendMsg.OldEpoch,
-			oldNodeList,
-			pssSendMsg.OldEpochT,
-			pssSendMsg.OldEpochK,
-			pssSendMsg.NewEpoch,
-			newNodeList,
-			pssSendMsg.NewEpochT,
-			pssSendMsg.NewEpochK,
-			pssServiceLibrary.EthereumMethods().GetSelfIndex(),
-			&DKGPSSDataSource{
-				eventBus: p.eventBus,
-			},
-			&DKGPSSTransport{
-				eventBus: p.eventBus,
-				Prefix:   protocolPrefix,
-			},
-			isDealer,
-			isPlayer,
-			config.GlobalConfig.StaggerDelay,
-		)
-		err := p.PSSNodeInstances[protocolPrefix].Transport.SetPSSNode(p.PSSNodeInstances[protocolPrefix])
-		if err != nil {
-			return nil, err
-		}
-		return nil, nil
-	case "send_PSS_message_to_node":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.SendPSSMessageToNodeCounter, pcmn.TelemetryConstants.PSS.Prefix)
-
-		var args0 PSSProtocolPrefix
-		var args1 pss.NodeDetails
-		var args2 pss.PSSMessage
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-		_ = castOrUnmarshal(args[2], &args2)
-
-		prefix := args0
-		nodeDetails := args1
-		pssMessage := args2
-		pssN, ok := p.PSSNodeInstances[prefix]
-		if !ok {
-			return nil, fmt.Errorf("Could not get pssNode for prefix %v", prefix)
-		}
-		err := pssN.Transport.Send(nodeDetails, pssMessage)
-		return nil, err
+	pssNode, err := p.pssNode(protocolPrefix)
+	if err != nil {
+		return 0, err
 	}
-	return nil, fmt.Errorf("PSS service method %v not found", method)
+	return pssNode.OldNodes.T, nil
+}
+
+func (p *PSSService) handleNewPSSNode(ctx context.Context, pssStartData PSSStartData, isDealer bool, isPlayer bool) error {
+	ctx = withSpanOf(p.ctx, ctx)
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.NewPSSNodeCounter, pcmn.TelemetryConstants.PSS.Prefix)
+
+	protocolPrefix := p.GetPSSProtocolPrefix(pssStartData.OldEpoch, pssStartData.NewEpoch)
+	oldNodeList := getCommonNodesFromNodeRefArray(pssServiceLibrary.EthereumMethods().AwaitCompleteNodeList(ctx, pssStartData.OldEpoch))
+	newNodeList := getCommonNodesFromNodeRefArray(pssServiceLibrary.EthereumMethods().AwaitCompleteNodeList(ctx, pssStartData.NewEpoch))
+	selfPubKey := pssServiceLibrary.EthereumMethods().GetSelfPublicKey(ctx)
+	pssNode := pss.NewPSSNode(
+		pcmn.Node{
+			Index:  pssServiceLibrary.EthereumMethods().GetSelfIndex(ctx),
+			PubKey: selfPubKey,
+		},
+		pssStartData.OldEpoch,
+		oldNodeList,
+		pssStartData.OldEpochT,
+		pssStartData.OldEpochK,
+		pssStartData.NewEpoch,
+		newNodeList,
+		pssStartData.NewEpochT,
+		pssStartData.NewEpochK,
+		pssServiceLibrary.EthereumMethods().GetSelfIndex(ctx),
+		&DKGPSSDataSource{
+			eventBus: p.eventBus,
+		},
+		&DKGPSSTransport{
+			eventBus: p.eventBus,
+			Prefix:   protocolPrefix,
+		},
+		isDealer,
+		isPlayer,
+		config.GlobalConfig.StaggerDelay,
+	)
+	pssNode.Clock = nodeClock
+	pssNode.Entropy = nodeEntropy
+	p.PSSNodeInstances[protocolPrefix] = pssNode
+	return pssNode.Transport.SetPSSNode(pssNode)
+}
+
+func (p *PSSService) handleSendPSSMessageToNode(ctx context.Context, protocolPrefix PSSProtocolPrefix, pssNodeDetails pss.NodeDetails, pssMessage pss.PSSMessage) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.SendPSSMessageToNodeCounter, pcmn.TelemetryConstants.PSS.Prefix)
+
+	pssN, ok := p.PSSNodeInstances[protocolPrefix]
+	if !ok {
+		return fmt.Errorf("Could not get pssNode for prefix %v", protocolPrefix)
+	}
+	return pssN.Transport.Send(pssNodeDetails, pssMessage)
 }
 
 func getCommonNodesFromNodeRefArray(nodeRefs []NodeReference) (commonNodes []pcmn.Node) {
@@ -284,7 +261,7 @@ func (ds *DKGPSSDataSource) Init() {}
 
 func (ds *DKGPSSDataSource) GetSharing(keygenID pss.KeygenID) *pss.Sharing {
 	serviceLibrary := NewServiceLibrary(ds.eventBus, "dkgPSSDataSource")
-	matrix, err := serviceLibrary.DatabaseMethods().RetrieveCommitmentMatrix(*big.NewInt(int64(keygenID.GetIndex())))
+	matrix, err := serviceLibrary.DatabaseMethods().RetrieveCommitmentMatrix(context.Background(), *big.NewInt(int64(keygenID.GetIndex())))
 	if err != nil {
 		logging.WithError(err).Error("could not retrieve commitmentmatrix")
 	}
@@ -292,7 +269,7 @@ func (ds *DKGPSSDataSource) GetSharing(keygenID pss.KeygenID) *pss.Sharing {
 	for _, arr := range matrix {
 		c = append(c, arr[0])
 	}
-	si, siprime, err := serviceLibrary.DatabaseMethods().RetrieveCompletedShare(*big.NewInt(int64(keygenID.GetIndex())))
+	si, siprime, err := serviceLibrary.DatabaseMethods().RetrieveCompletedShare(context.Background(), *big.NewInt(int64(keygenID.GetIndex())))
 	logging.WithFields(logging.Fields{
 		"si":      si,
 		"siprime": siprime,
@@ -300,7 +277,7 @@ func (ds *DKGPSSDataSource) GetSharing(keygenID pss.KeygenID) *pss.Sharing {
 	if err != nil {
 		logging.WithError(err).Error("error in suite dbsuite retrieve completed share")
 	}
-	nodeRefArray := serviceLibrary.EthereumMethods().GetNodeList(ds.PSSNode.OldNodes.EpochID)
+	nodeRefArray := serviceLibrary.EthereumMethods().GetNodeList(context.Background(), ds.PSSNode.OldNodes.EpochID)
 	var oldNodeList []pcmn.Node
 	for j := 0; j < len(nodeRefArray); j++ {
 		oldNodeList = append(oldNodeList, pcmn.Node{
@@ -315,7 +292,7 @@ func (ds *DKGPSSDataSource) GetSharing(keygenID pss.KeygenID) *pss.Sharing {
 		KeygenID: keygenID,
 		Nodes:    oldNodeList,
 		Epoch:    ds.PSSNode.OldNodes.EpochID,
-		I:        serviceLibrary.EthereumMethods().GetSelfIndex(),
+		I:        serviceLibrary.EthereumMethods().GetSelfIndex(context.Background()),
 		Si:       si,
 		Siprime:  siprime,
 		C:        c,
@@ -337,7 +314,7 @@ type DKGPSSTransport struct {
 }
 
 func (tp *DKGPSSTransport) Init() {
-	err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SetStreamHandler(string(tp.Prefix), tp.streamHandler)
+	err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SetStreamHandler(context.Background(), string(tp.Prefix), tp.streamHandler)
 	if err != nil {
 		logging.WithField("DKGPSSTransportPrefix", tp.Prefix).WithError(err).Error("could not set stream handler")
 	}
@@ -389,7 +366,7 @@ func (tp *DKGPSSTransport) streamHandler(streamMessage StreamMessage) {
 	logging.Debug("streamHandler receiving message")
 	// get request data
 	p2pBasicMsg := streamMessage.Message
-	if NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().AuthenticateMessage(p2pBasicMsg) != nil {
+	if NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().AuthenticateMessage(context.Background(), p2pBasicMsg) != nil {
 		logging.WithField("p2pBasicMsg", p2pBasicMsg).Error("could not authenticate incoming p2pBasicMsg iin dkgpsstransport")
 		return
 	}
@@ -407,7 +384,7 @@ func (tp *DKGPSSTransport) streamHandler(streamMessage StreamMessage) {
 		return
 	}
 	logging.Debugf("able to get pubk X: %s, Y: %s", pubKey.X.Text(16), pubKey.Y.Text(16))
-	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(pubKey))
+	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(pubKey))
 	err = tp.Receive(pss.NodeDetails(pcmn.Node{
 		Index:  int(ethNodeDetails.Index.Int64()),
 		PubKey: pubKey,
@@ -425,14 +402,64 @@ func (tp *DKGPSSTransport) GetType() string {
 
 func (tp *DKGPSSTransport) SetPSSNode(ref *pss.PSSNode) error {
 	tp.PSSNode = ref
+	// dealers are in the old epoch and players in the new one
+	var epochs []int
+	if ref.IsDealer {
+		epochs = append(epochs, ref.OldNodes.EpochID)
+	}
+	if ref.IsPlayer {
+		epochs = append(epochs, ref.NewNodes.EpochID)
+	}
+	for _, epoch := range epochs {
+		err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().JoinGossipTopic(context.Background(), string(tp.Prefix), epoch)
+		if err != nil {
+			logging.WithField("DKGPSSTransportPrefix", tp.Prefix).WithError(err).Error("could not join gossip topic")
+		}
+	}
+	return nil
+}
+
+// MessageVersion implements pss.PSSVersionNegotiator
+func (tp *DKGPSSTransport) MessageVersion(nodeDetails pss.NodeDetails) string {
+	return negotiatedVersion(NewServiceLibrary(tp.eventBus, "dkgPSSTransport"), nodeDetails.PubKey, version.ProtocolPSS)
+}
+
+// Gossip implements pss.PSSGossipTransport
+func (tp *DKGPSSTransport) Gossip(nodeNetwork pss.NodeNetwork, originalPSSMessage pss.PSSMessage) error {
+	serviceLibrary := NewServiceLibrary(tp.eventBus, "dkgPSSTransport")
+	var pubKeys []common.Point
+	for _, node := range nodeNetwork.Nodes {
+		pubKeys = append(pubKeys, node.PubKey)
+	}
+	if err := checkGossipSupport(serviceLibrary, nodeNetwork.EpochID, pubKeys); err != nil {
+		return err
+	}
+	pssMessage, err := tp.runSendMiddleware(originalPSSMessage)
+	if err != nil {
+		logging.WithError(err).Error("Could not run send middleware")
+		return err
+	}
+	byt, err := bijson.Marshal(pssMessage)
+	if err != nil {
+		return err
+	}
+	err = gossipP2PMessage(serviceLibrary, string(tp.Prefix), nodeNetwork.EpochID, byt, "transportPSSMessage")
+	if err != nil {
+		return err
+	}
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.SentMessageCounter, pcmn.TelemetryConstants.PSS.Prefix)
 	return nil
 }
 
+// ReportInvalidMessage implements pss.PSSMisbehaviourReporter
+func (tp *DKGPSSTransport) ReportInvalidMessage(sender pss.NodeDetails, reason string) {
+	reportNode(NewServiceLibrary(tp.eventBus, "dkgPSSTransport"), sender.PubKey, offenceInvalidProtocol, "pss "+reason)
+}
+
 func (tp *DKGPSSTransport) Send(nodeDetails pss.NodeDetails, originalPSSMessage pss.PSSMessage) error {
 	logging.WithFields(logging.Fields{
 		"to":      stringify(nodeDetails),
 		"message": stringify(originalPSSMessage),
-		"data":    string(originalPSSMessage.Data),
 	}).Debug("trying to send message")
 
 	pssMessage, err := tp.runSendMiddleware(originalPSSMessage)
@@ -442,10 +469,10 @@ func (tp *DKGPSSTransport) Send(nodeDetails pss.NodeDetails, originalPSSMessage
 	}
 
 	// get recipient details
-	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(*crypto.PointToEthAddress(nodeDetails.PubKey))
+	ethNodeDetails := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetNodeDetailsByAddress(context.Background(), *crypto.PointToEthAddress(nodeDetails.PubKey))
 
 	logging.WithField("ethNodeDetails", ethNodeDetails).Debug("managed to get ethNodeDetails")
-	pubKey := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetSelfPublicKey()
+	pubKey := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetSelfPublicKey(context.Background())
 	if nodeDetails.PubKey.X.Cmp(&pubKey.X) == 0 && nodeDetails.PubKey.Y.Cmp(&pubKey.Y) == 0 {
 		return tp.Receive(nodeDetails, pssMessage)
 	}
@@ -454,19 +481,19 @@ func (tp *DKGPSSTransport) Send(nodeDetails pss.NodeDetails, originalPSSMessage
 	if err != nil {
 		return err
 	}
-	p2pMsg := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().NewP2PMessage(HashToString(byt), false, byt, "transportPSSMessage")
+	p2pMsg := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().NewP2PMessage(context.Background(), HashToString(byt), false, byt, "transportPSSMessage")
 	peerID, err := GetPeerIDFromP2pListenAddress(ethNodeDetails.P2PConnection)
 	if err != nil {
 		return err
 	}
 	// sign the data
-	signature, err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SignP2PMessage(&p2pMsg)
+	signature, err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SignP2PMessage(context.Background(), &p2pMsg)
 	if err != nil {
 		return errors.New("failed to sign p2pMsgonse" + err.Error())
 	}
 	p2pMsg.Sign = signature
 	err = retry.Do(func() error {
-		err = NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SendP2PMessage(*peerID, protocol.ID(tp.Prefix), &p2pMsg)
+		err = NewServiceLibrary(tp.eventBus, "dkgPSSTransport").P2PMethods().SendP2PMessage(context.Background(), *peerID, protocol.ID(tp.Prefix), &p2pMsg)
 		if err != nil {
 			logging.WithFields(logging.Fields{
 				"peerID":     peerID,
@@ -490,7 +517,6 @@ func (tp *DKGPSSTransport) Receive(senderDetails pss.NodeDetails, originalPSSMes
 	logging.WithFields(logging.Fields{
 		"senderDetails": stringify(senderDetails),
 		"message":       stringify(originalPSSMessage),
-		"data":          stringify(originalPSSMessage.Data),
 	}).Debug("message received")
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Message.ReceivedMessageCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
@@ -506,7 +532,6 @@ func (tp *DKGPSSTransport) Receive(senderDetails pss.NodeDetails, originalPSSMes
 func (tp *DKGPSSTransport) SendBroadcast(originalPSSMessage pss.PSSMessage) error {
 	logging.WithFields(logging.Fields{
 		"pssMessage": stringify(originalPSSMessage),
-		"data":       string(originalPSSMessage.Data),
 	}).Debug("sending broadcast")
 
 	pssMessage, err := tp.runSendMiddleware(originalPSSMessage)
@@ -515,7 +540,7 @@ func (tp *DKGPSSTransport) SendBroadcast(originalPSSMessage pss.PSSMessage) erro
 		return err
 	}
 
-	_, err = NewServiceLibrary(tp.eventBus, "dkgPSSTransport").TendermintMethods().Broadcast(pssMessage)
+	_, err = NewServiceLibrary(tp.eventBus, "dkgPSSTransport").TendermintMethods().Broadcast(context.Background(), pssMessage)
 	if err == nil {
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Broadcast.SentBroadcastCounter, pcmn.TelemetryConstants.PSS.Prefix)
 		return nil
@@ -527,7 +552,6 @@ func (tp *DKGPSSTransport) SendBroadcast(originalPSSMessage pss.PSSMessage) erro
 func (tp *DKGPSSTransport) ReceiveBroadcast(originalPSSMessage pss.PSSMessage) error {
 	logging.WithFields(logging.Fields{
 		"pssMessage": stringify(originalPSSMessage),
-		"data":       string(originalPSSMessage.Data),
 	}).Debug("received broadcast")
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Broadcast.ReceivedBroadcastCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
@@ -551,6 +575,7 @@ func (tp *DKGPSSTransport) Output(inter interface{}) {
 
 	rks, ok := inter.(pss.RefreshKeyStorage)
 	if ok {
+		defer rks.Wipe()
 		logging.WithField("rks", stringify(rks)).Debug()
 		var fakeCommitmentMatrix [][]common.Point
 		for _, pt := range rks.CommitmentPoly {
@@ -562,14 +587,14 @@ func (tp *DKGPSSTransport) Output(inter interface{}) {
 			fakeCommitmentMatrix = append(fakeCommitmentMatrix, fakePoly)
 		}
 
-		err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").DatabaseMethods().StorePSSCommitmentMatrix(rks.KeyIndex, fakeCommitmentMatrix)
-		if err != nil {
-			logging.WithError(err).Error("StoreCommitmentMatrix failed")
-		}
-		err = NewServiceLibrary(tp.eventBus, "dkgPSSTransport").DatabaseMethods().StoreCompletedPSSShare(rks.KeyIndex, rks.Si, rks.Siprime)
+		// the database gets its own copy of the share, which it may still use after a timeout
+		si, siprime := new(big.Int).Set(rks.Si.Value()), new(big.Int).Set(rks.Siprime.Value())
+		err := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").DatabaseMethods().StoreCompletedPSS(context.Background(), rks.KeyIndex, fakeCommitmentMatrix, *si, *siprime)
 		if err != nil {
-			logging.WithError(err).Error("StoreCompletedPSSShare failed")
+			logging.WithError(err).Error("StoreCompletedPSS failed")
+			return
 		}
+		secret.Wipe(si, siprime)
 		return
 	}
 
@@ -577,6 +602,5 @@ func (tp *DKGPSSTransport) Output(inter interface{}) {
 }
 
 func (tp *DKGPSSTransport) Sign(s []byte) ([]byte, error) {
-	k := NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().GetSelfPrivateKey()
-	return pvss.ECDSASignBytes(s, &k), nil
+	return NewServiceLibrary(tp.eventBus, "dkgPSSTransport").EthereumMethods().SelfSignData(context.Background(), s), nil
 }
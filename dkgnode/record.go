@@ -0,0 +1,150 @@
+package dkgnode
+
+import (
+	"math/big"
+	"os"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-common/common"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/keygennofsm"
+	"github.com/torusresearch/torus-node/mapping"
+	"github.com/torusresearch/torus-node/pss"
+	"github.com/torusresearch/torus-node/secret"
+)
+
+// recordedError keeps the message of errors returned by services, which gob can not encode
+type recordedError struct {
+	Message string
+}
+
+func (e recordedError) Error() string {
+	return e.Message
+}
+
+// the types passed between services, events holding other types are recorded as text and not replayed
+func init() {
+	for _, value := range []interface{}{
+		MethodRequest{},
+		MethodResponse{},
+		recordedError{},
+		big.Int{},
+		&big.Int{},
+		[]big.Int{},
+		common.Point{},
+		[]common.Point{},
+		[][]common.Point{},
+		ethCommon.Address{},
+		peer.ID(""),
+		protocol.ID(""),
+		[]string{},
+		uint(0),
+		pcmn.Hash{},
+		pcmn.VerifierData{},
+		PSSProtocolPrefix(""),
+		MappingProtocolPrefix(""),
+		P2PBasicMsg{},
+		&P2PBasicMsg{},
+		ConnectionDetails{},
+		SerializedNodeReference{},
+		[]SerializedNodeReference{},
+		NodeDetails{},
+		KeyAssignmentPublic{},
+		PSSStartData{},
+		MappingStartData{},
+		BFTRPCWSStatus(0),
+		pss.PSSMessage{},
+		pss.NodeDetails{},
+		keygennofsm.KeygenMessage{},
+		mapping.MappingID(""),
+		mapping.MappingMessage{},
+		mapping.MappingSummaryMessage{},
+		// values holding secrets are recorded as the maps and slices secret.Redact returns
+		map[string]interface{}{},
+		[]interface{}{},
+	} {
+		eventbus.RegisterType(value)
+	}
+}
+
+// recordCodec encodes the events of a node session with gob. Errors are kept as their message
+// and, unless secrets are recorded, every payload is passed through secret.Redact and the data
+// of the methods that pass shares or keys untyped is dropped
+type recordCodec struct {
+	recordSecrets bool
+}
+
+func (c recordCodec) Marshal(data interface{}) ([]byte, error) {
+	switch event := data.(type) {
+	case MethodRequest:
+		data = c.redactRequest(event)
+	case MethodResponse:
+		if event.Error != nil {
+			event.Error = recordedError{event.Error.Error()}
+		}
+		if !c.recordSecrets {
+			if secretResponseMethods[event.Request.Service][event.Request.Method] {
+				event.Data = secret.Redacted
+			} else {
+				event.Data = secret.Redact(event.Data)
+			}
+		}
+		event.Request = c.redactRequest(event.Request)
+		data = event
+	default:
+		if !c.recordSecrets {
+			data = secret.Redact(data)
+		}
+	}
+	return eventbus.DefaultCodec.Marshal(data)
+}
+
+func (c recordCodec) redactRequest(request MethodRequest) MethodRequest {
+	if c.recordSecrets {
+		return request
+	}
+	if secretRequestMethods[request.Service][request.Method] {
+		request.Data = []interface{}{secret.Redacted}
+		return request
+	}
+	args := make([]interface{}, len(request.Data))
+	for i, arg := range request.Data {
+		args[i] = secret.Redact(arg)
+	}
+	request.Data = args
+	return request
+}
+
+func (c recordCodec) Unmarshal(b []byte) (interface{}, error) {
+	return eventbus.DefaultCodec.Unmarshal(b)
+}
+
+// setupRecordingMiddleware records the events of the node to path, the returned function
+// stops the recording
+func setupRecordingMiddleware(serviceRegistry *ServiceRegistry, path string, recordSecrets bool) (func(), error) {
+	// the recording holds protocol messages and possibly shares, keep it private to the node operator
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	recorder, err := eventbus.NewRecorder(file, recordCodec{recordSecrets})
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	serviceRegistry.eventBus.AddObserver(recorder.Observer())
+	logging.WithFields(logging.Fields{
+		"path":          path,
+		"recordSecrets": recordSecrets,
+	}).Warn("recording all events of the node")
+	return func() {
+		serviceRegistry.eventBus.RemoveObserver(recorder.Observer())
+		if err := recorder.Close(); err != nil {
+			logging.WithError(err).Error("could not close event recording")
+		}
+	}, nil
+}
@@ -0,0 +1,274 @@
+package dkgnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/eventbus"
+)
+
+// callerServices maps the owners of service libraries that are not named after a service
+// to the service they run in, so that their calls are not replayed when that service runs
+var callerServices = map[string]string{
+	"dkgPSSTransport":         "pss",
+	"dkgPSSDataSource":        "pss",
+	"transportPSSMessage":     "pss",
+	"dkgKeygenTransport":      "keygennofsm",
+	"dkgkeygennofsmtransport": "keygennofsm",
+	"dkgMappingDataSource":    "mapping",
+	"dkgMappingTransport":     "mapping",
+	"incoming_PSS_monitor":    "ethereum",
+	"outgoing_PSS_monitor":    "ethereum",
+	"trigger_mapping":         "ethereum",
+	"trigger_PSS":             "ethereum",
+	"send_PSS_message":        "ethereum",
+	"mrpc":                    "server",
+	"backup":                  "server",
+	"auth":                    "server",
+	"node_validation":         "server",
+}
+
+func callerService(caller string) string {
+	if service, ok := callerServices[caller]; ok {
+		return service
+	}
+	if strings.HasSuffix(caller, "_handler") {
+		return "server"
+	}
+	return caller
+}
+
+// recordedService mocks a service by answering calls with the responses it gave during a recording
+type recordedService struct {
+	name string
+	bs   *BaseService
+
+	lock      sync.Mutex
+	responses map[string][]MethodResponse
+}
+
+func newRecordedService(name string) *recordedService {
+	return &recordedService{
+		name:      name,
+		responses: make(map[string][]MethodResponse),
+	}
+}
+
+func (r *recordedService) Name() string {
+	return r.name
+}
+
+func (r *recordedService) OnStart() error {
+	return nil
+}
+
+func (r *recordedService) OnStop() error {
+	return nil
+}
+
+func (r *recordedService) SetBaseService(bs *BaseService) {
+	r.bs = bs
+}
+
+func (r *recordedService) add(response MethodResponse) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.responses[response.Request.Method] = append(r.responses[response.Request.Method], response)
+}
+
+func sameArgs(a []interface{}, b []interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Call returns the first unused response recorded for a call with the same arguments,
+// or the first unused response of the method if the arguments differ from the recording
+func (r *recordedService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	responses := r.responses[method]
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("replay: no recorded response left for %v.%v", r.name, method)
+	}
+	index := 0
+	for i, response := range responses {
+		if sameArgs(response.Request.Data, args) {
+			index = i
+			break
+		}
+	}
+	response := responses[index]
+	r.responses[method] = append(responses[:index:index], responses[index+1:]...)
+	return response.Data, response.Error
+}
+
+// Replayer feeds a recorded node session into a fresh ServiceRegistry. Services registered
+// with Register run for real, every other service called during the recording is mocked
+// with the responses it gave. The calls that the real services received from mocked
+// services or from outside the node are replayed, calls between real services happen anew.
+type Replayer struct {
+	EventBus eventbus.Bus
+	Registry *ServiceRegistry
+	records  []eventbus.Record
+	mocks    map[string]*recordedService
+	real     map[string]bool
+}
+
+// NewReplayer prepares the replay of records read with eventbus.ReadRecords
+func NewReplayer(records []eventbus.Record) (*Replayer, error) {
+	eventBus := eventbus.New()
+	replayer := &Replayer{
+		EventBus: eventBus,
+		Registry: NewServiceRegistry(eventBus),
+		records:  records,
+		mocks:    make(map[string]*recordedService),
+		real:     make(map[string]bool),
+	}
+	skipped := 0
+	for _, record := range records {
+		if !record.Replayable() {
+			skipped++
+			continue
+		}
+		data, err := record.Decode(recordCodec{})
+		if err != nil {
+			return nil, err
+		}
+		response, ok := data.(MethodResponse)
+		if !ok || response.Request.Service == "" {
+			continue
+		}
+		mock, ok := replayer.mocks[response.Request.Service]
+		if !ok {
+			mock = newRecordedService(response.Request.Service)
+			replayer.mocks[response.Request.Service] = mock
+		}
+		mock.add(response)
+	}
+	if skipped > 0 {
+		logging.WithField("skipped", skipped).Warn("recording holds events that could not be encoded, they are not replayed")
+	}
+	return replayer, nil
+}
+
+// LoadReplayer prepares the replay of the recording at path
+func LoadReplayer(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := eventbus.ReadRecords(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(records)
+}
+
+// Register runs services for real instead of mocking them, they have to be created on the EventBus of the replayer
+func (r *Replayer) Register(services ...*BaseService) {
+	for _, service := range services {
+		r.Registry.RegisterService(service)
+		r.real[service.Name()] = true
+	}
+}
+
+// isInput is true for the recorded calls to real services that real services did not make
+func (r *Replayer) isInput(record eventbus.Record, data interface{}) bool {
+	request, ok := data.(MethodRequest)
+	if !ok || record.Topic != "method" {
+		return false
+	}
+	return r.real[request.Service] && !r.real[callerService(request.Caller)]
+}
+
+// Run starts the services and replays the recording, speed scales the time between calls
+// relative to the recording and 0 replays them without waiting
+func (r *Replayer) Run(ctx context.Context, speed float64) error {
+	if len(r.real) == 0 {
+		return errors.New("replay: no services registered to replay against")
+	}
+	for name, mock := range r.mocks {
+		if !r.real[name] {
+			r.Registry.RegisterService(NewBaseService(mock))
+		}
+	}
+	for name, service := range r.Registry.Services {
+		if _, err := service.Start(); err != nil {
+			return fmt.Errorf("replay: could not start %v: %v", name, err)
+		}
+	}
+	return eventbus.Replay(r.EventBus, r.records, eventbus.ReplayConfig{
+		Codec:  recordCodec{},
+		Filter: r.isInput,
+		Speed:  speed,
+		Stop:   ctx.Done(),
+	})
+}
+
+// Stop stops the services started by Run
+func (r *Replayer) Stop() {
+	for _, service := range r.Registry.Services {
+		service.Stop()
+	}
+}
+
+// replayableServices creates the services that can run in a replay, ethereum and p2p
+// need the node signer and are always mocked
+var replayableServices = map[string]func(context.Context, eventbus.Bus) *BaseService{
+	"telemetry":   NewTelemetryService,
+	"abci":        NewABCIService,
+	"tendermint":  NewTendermintService,
+	"server":      NewServerService,
+	"keygennofsm": NewKeygennofsmService,
+	"pss":         NewPSSService,
+	"mapping":     NewMappingService,
+	"verifier":    NewVerifierService,
+	"database":    NewDatabaseService,
+	"cache":       NewCacheService,
+}
+
+// ReplayOptions - options of ReplayRecording
+type ReplayOptions struct {
+	RecordingPath string
+	// Services run for real, the others are mocked from the recording
+	Services []string
+	// Speed scales the time between calls, 0 replays them without waiting
+	Speed float64
+}
+
+// ReplayRecording replays a recording against the services in opts with the node config
+func ReplayRecording(ctx context.Context, opts ReplayOptions) error {
+	config.GlobalConfig = config.LoadConfig(defaultConfigPath)
+	config.GlobalMutableConfig = config.InitMutableConfig(config.GlobalConfig)
+
+	replayer, err := LoadReplayer(opts.RecordingPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range opts.Services {
+		newService, ok := replayableServices[name]
+		if !ok {
+			return fmt.Errorf("replay: service %v can not be replayed", name)
+		}
+		replayer.Register(newService(ctx, replayer.EventBus))
+	}
+	setupRequestLoggingMiddleware(replayer.Registry)
+	setupResponseLoggingMiddleware(replayer.Registry)
+	defer replayer.Stop()
+	if err := replayer.Run(ctx, opts.Speed); err != nil {
+		return err
+	}
+	// let the services finish handling the last calls
+	replayer.EventBus.WaitAsync()
+	return nil
+}
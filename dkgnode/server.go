@@ -19,11 +19,12 @@ import (
 	"github.com/rs/cors"
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-node/backup"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/config"
 	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/faults"
 	"github.com/torusresearch/torus-node/mrpc"
-	"github.com/torusresearch/torus-node/pvss"
 	"github.com/torusresearch/torus-node/telemetry"
 )
 
@@ -63,8 +64,17 @@ func startManagementRPC(e eventbus.Bus) {
 	triggerFunctions := mrpc.Actions{
 		RetriggerPSS: func() error {
 			logging.Debug("retriggering pss...")
-			return mrpcServiceLibrary.EthereumMethods().StartPSSMonitor()
+			return mrpcServiceLibrary.EthereumMethods().StartPSSMonitor(context.Background())
 		},
+		CreateBackup: func(path string, passphrase string) (backup.Manifest, error) {
+			return createBackup(e, path, passphrase)
+		},
+	}
+	if config.GlobalConfig.IsDebug {
+		triggerFunctions.SetFaults = setFaults
+		triggerFunctions.GetFaults = func() ([]faults.Rule, faults.Stats) {
+			return faultInjector.Rules(), faultInjector.Stats()
+		}
 	}
 
 	managementRPCHandler, err := mrpc.SetupManagementRPCHander(triggerFunctions)
@@ -144,19 +154,16 @@ func (s *ServerService) OnStart() error {
 func (s *ServerService) OnStop() error {
 	return nil
 }
-func (s *ServerService) Call(method string, args ...interface{}) (interface{}, error) {
+func (s *ServerService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Server.Prefix)
 
-	switch method {
-	case "request_connection_details":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Server.RequestConnectionDetailsCounter, pcmn.TelemetryConstants.Server.Prefix)
+	return dispatchServer(ctx, s, method, args)
+}
 
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
+func (s *ServerService) handleRequestConnectionDetails(ctx context.Context, endpoint string) (ConnectionDetails, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Server.RequestConnectionDetailsCounter, pcmn.TelemetryConstants.Server.Prefix)
 
-		return s.RequestConnectionDetails(args0)
-	}
-	return nil, fmt.Errorf("server service method %v not found", method)
+	return s.RequestConnectionDetails(endpoint)
 }
 
 type ConnectionDetailsRequestBody struct {
@@ -179,14 +186,13 @@ type ConnectionDetailsJRPCResponse struct {
 
 func (s *ServerService) RequestConnectionDetails(endpoint string) (connectionDetails ConnectionDetails, err error) {
 	sL := NewServiceLibrary(s.eventBus, "server")
-	pubKey := sL.EthereumMethods().GetSelfPublicKey()
-	privKey := sL.EthereumMethods().GetSelfPrivateKey()
+	pubKey := sL.EthereumMethods().GetSelfPublicKey(context.Background())
 	connectionDetailsMessage := ConnectionDetailsMessage{
 		Message:     "ConnectionDetails",
 		Timestamp:   strconv.FormatInt(time.Now().Unix(), 10),
-		NodeAddress: sL.EthereumMethods().GetSelfAddress(),
+		NodeAddress: sL.EthereumMethods().GetSelfAddress(context.Background()),
 	}
-	sig := pvss.ECDSASign(connectionDetailsMessage.String(), &privKey)
+	sig := sL.EthereumMethods().SelfSignData(context.Background(), []byte(connectionDetailsMessage.String()))
 	connectionDetailsParams := ConnectionDetailsParams{
 		PubKeyX:                  pubKey.X,
 		PubKeyY:                  pubKey.Y,
@@ -270,12 +276,15 @@ func setUpRouter(eventBus eventbus.Bus) http.Handler {
 	router.Handle("/jrpc", mr)
 	router.HandleFunc("/healthz", GETHealthz)
 	router.HandleFunc("/bftStatus", GetBftStatus)
+	router.HandleFunc("/registrationStatus", GetRegistrationStatus)
 
 	router.Use(parseBodyMiddleware)
 	router.Use(augmentRequestMiddleware)
 	router.Use(loggingMiddleware)
 	router.Use(telemetryMiddleware)
 	router.Use(authMiddleware(eventBus))
+	router.Use(tracingMiddleware)
+	router.Use(requestIDMiddleware)
 
 	// Handles functions that should only be availible during debug mode
 	if config.GlobalConfig.IsDebug {
@@ -1,6 +1,7 @@
 package dkgnode
 
 import (
+	"context"
 	"sync/atomic"
 
 	logging "github.com/sirupsen/logrus"
@@ -11,7 +12,7 @@ type ServiceCore interface {
 	Name() string
 	OnStart() error
 	OnStop() error
-	Call(method string, args ...interface{}) (result interface{}, err error)
+	Call(ctx context.Context, method string, args ...interface{}) (result interface{}, err error)
 	SetBaseService(*BaseService)
 }
 
@@ -86,8 +87,8 @@ func (bs *BaseService) Name() string {
 }
 
 // Query implements Service
-func (bs *BaseService) Call(method string, args ...interface{}) (interface{}, error) {
-	return bs.impl.Call(method, args...)
+func (bs *BaseService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	return bs.impl.Call(ctx, method, args...)
 }
 
 // Start implements Service
@@ -0,0 +1,268 @@
+package dkgnode
+
+//go:generate go run ../cmd/servicegen -in service_defs.go -out service_library_gen.go
+
+import (
+	"context"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/torusresearch/bijson"
+	tmp2p "github.com/torusresearch/tendermint/p2p"
+	"github.com/torusresearch/torus-common/common"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/ethrpc"
+	"github.com/torusresearch/torus-node/keygennofsm"
+	"github.com/torusresearch/torus-node/mapping"
+	"github.com/torusresearch/torus-node/pss"
+	"github.com/torusresearch/torus-node/version"
+)
+
+// The interfaces below define the methods each service serves over the event bus.
+// The ServiceLibrary clients and the dispatch of each service's Call are generated
+// from them by cmd/servicegen, run go generate after changing them.
+
+//servicegen:service telemetry Telemetry
+type telemetryService interface{}
+
+//servicegen:service ethereum Ethereum
+type ethereumService interface {
+	//servicegen:retry could not get current epoch
+	//servicegen:decode int nonZeroEpoch
+	GetCurrentEpoch(ctx context.Context) (epoch int)
+	GetPreviousEpoch(ctx context.Context) (epoch int, err error)
+	GetNextEpoch(ctx context.Context) (epoch int, err error)
+	//servicegen:decode epochInfo initializedEpochInfo
+	GetEpochInfo(ctx context.Context, epoch int, skipCache bool) (eInfo epochInfo, err error)
+	// blocks until the index is set
+	//servicegen:fatal could not get self index
+	GetSelfIndex(ctx context.Context) (index int)
+	//servicegen:retry could not get self private key
+	GetSelfPrivateKey(ctx context.Context) (privKey big.Int)
+	//servicegen:retry could not get self public key
+	GetSelfPublicKey(ctx context.Context) (pubKey common.Point)
+	//servicegen:retry could not get self address
+	GetSelfAddress(ctx context.Context) (address ethCommon.Address)
+	//servicegen:retry could not set self index
+	SetSelfIndex(ctx context.Context, index int)
+	//servicegen:retry could not sign data
+	SelfSignData(ctx context.Context, input []byte) (rawSig []byte)
+
+	// blocks until every node of the epoch has registered
+	//servicegen:retry could not get complete node list
+	//servicegen:decode []SerializedNodeReference deserializeNodeReferences
+	AwaitCompleteNodeList(ctx context.Context, epoch int) (nodeRefs []NodeReference)
+	//servicegen:retry could not get node list
+	//servicegen:decode []SerializedNodeReference deserializeNodeReferences
+	GetNodeList(ctx context.Context, epoch int) (nodeRefs []NodeReference)
+	//servicegen:retry could not get node details by address
+	//servicegen:decode SerializedNodeReference deserializeNodeReference
+	GetNodeDetailsByAddress(ctx context.Context, address ethCommon.Address) (nodeRef NodeReference)
+	//servicegen:retry could not get node details by epoch and index
+	//servicegen:decode SerializedNodeReference deserializeNodeReference
+	GetNodeDetailsByEpochAndIndex(ctx context.Context, epoch int, index int) (nodeRef NodeReference)
+	//servicegen:fatal await nodes connected returned error
+	AwaitNodesConnected(ctx context.Context, epoch int)
+	//servicegen:method get_PSS_status
+	GetPSSStatus(ctx context.Context, oldEpoch int, newEpoch int) (pssStatus int, err error)
+	VerifyDataWithNodelist(ctx context.Context, pk common.Point, sig []byte, input []byte) (senderDetails NodeDetails, err error)
+	VerifyDataWithEpoch(ctx context.Context, pk common.Point, sig []byte, input []byte, epoch int) (senderDetails NodeDetails, err error)
+	//servicegen:method start_PSS_monitor
+	StartPSSMonitor(ctx context.Context) error
+	//servicegen:method get_tm_p2p_connection
+	//servicegen:retry could not get tendermint p2p connection
+	GetTMP2PConnection(ctx context.Context) (tmp2pConnection string)
+	//servicegen:retry could not get p2p connection
+	GetP2PConnection(ctx context.Context) (p2pConnection string)
+	//servicegen:log could not validate epoch public key
+	ValidateEpochPubKey(ctx context.Context, nodeAddress ethCommon.Address, pubK common.Point) (valid bool)
+	EthEndpoints(ctx context.Context) (endpoints []ethrpc.EndpointStatus, err error)
+	GetRegistrationStatus(ctx context.Context) (status RegistrationStatus, err error)
+}
+
+//servicegen:service abci ABCI
+type abciService interface {
+	LastCreatedIndex(ctx context.Context) (keyIndex uint, err error)
+	LastUnassignedIndex(ctx context.Context) (keyIndex uint, err error)
+	// safe to the public, provides the verifiers of the key
+	RetrieveKeyMapping(ctx context.Context, keyIndex big.Int) (keyDetails KeyAssignmentPublic, err error)
+	GetIndexesFromVerifierID(ctx context.Context, verifier, verifierID string) (keyIndexes []big.Int, err error)
+	// the service returns the ID of the iterator, which is advanced with GetVerifierIteratorNext
+	//servicegen:manual string
+	GetVerifierIterator(ctx context.Context) (iterator *mapping.VerifierIterator, err error)
+	GetVerifierIteratorNext(ctx context.Context, randomID string) (verifierData pcmn.VerifierData, err error)
+	// blocks until the current block is committed and holds back the next block until ResumeCommits
+	// is called with token or leaseMS passed, pausing again with the same token renews the lease
+	PauseCommits(ctx context.Context, token string, leaseMS int) error
+	// fails if the pause lapsed, as blocks may have been committed since it was taken
+	ResumeCommits(ctx context.Context, token string) error
+	// dumps the app state, commits should be paused so that it matches the returned height
+	SnapshotState(ctx context.Context, path string) (height int64, err error)
+}
+
+//servicegen:service tendermint Tendermint
+type tendermintService interface {
+	//servicegen:retry could not get nodeKey
+	//servicegen:decode []byte unmarshalNodeKey
+	GetNodeKey(ctx context.Context) (nodeKey tmp2p.NodeKey)
+	//servicegen:retry could not get tendermint status
+	GetStatus(ctx context.Context) (status BFTRPCWSStatus)
+	// dumps every database of the tendermint node into dir, commits should be paused
+	SnapshotStores(ctx context.Context, dir string) (count int, err error)
+
+	Broadcast(ctx context.Context, tx interface{}) (txHash pcmn.Hash, err error)
+	// responses are forwarded on the event bus until count responses are received
+	//servicegen:manual
+	RegisterQuery(ctx context.Context, query string, count int) (respChannel chan []byte, err error)
+	//servicegen:manual
+	DeregisterQuery(ctx context.Context, query string) (err error)
+}
+
+//servicegen:service server Server
+type serverService interface {
+	RequestConnectionDetails(ctx context.Context, endpoint string) (connectionDetails ConnectionDetails, err error)
+}
+
+//servicegen:service p2p P2P
+type p2pService interface {
+	//servicegen:method id
+	//servicegen:retry could not get id
+	ID(ctx context.Context) (peerID peer.ID)
+	// messages are forwarded on the event bus to handler
+	//servicegen:manual
+	SetStreamHandler(ctx context.Context, protoName string, handler func(StreamMessage)) (err error)
+	//servicegen:manual
+	RemoveStreamHandler(ctx context.Context, protoName string) (err error)
+	AuthenticateMessage(ctx context.Context, p2pBasicMsg P2PBasicMsg) (err error)
+	AuthenticateMessageInEpoch(ctx context.Context, p2pBasicMsg P2PBasicMsg, epoch int) (err error)
+	//servicegen:fatal could not create p2p message
+	NewP2PMessage(ctx context.Context, messageID string, gossip bool, payload []byte, msgType string) (newMsg P2PBasicMsg)
+	// not concurrent safe
+	SignP2PMessage(ctx context.Context, message *P2PBasicMsg) (signature []byte, err error)
+	SendP2PMessage(ctx context.Context, id peer.ID, p protocol.ID, msg *P2PBasicMsg) error
+	// messages gossiped to the nodes of epoch in the protocol are forwarded to the handler of SetStreamHandler,
+	// the topics of a protocol are left by RemoveStreamHandler
+	JoinGossipTopic(ctx context.Context, protoName string, epoch int) error
+	// the message must be created with gossip set, it is delivered to every node that joined the topic
+	GossipP2PMessage(ctx context.Context, protoName string, epoch int, msg *P2PBasicMsg) error
+	// lowers the score of the peer for offence, peers are disconnected and banned when it falls to the ban threshold
+	ReportPeer(ctx context.Context, peerID peer.ID, offence string) error
+	PeerScores(ctx context.Context) (scores []PeerScore, err error)
+	// returns the results of pinging the nodes of the current and next epoch, offline peers first
+	PeerHealth(ctx context.Context) (health []PeerHealth, err error)
+	// returns the versions and capabilities negotiated with the peer on connection
+	PeerVersions(ctx context.Context, peerID peer.ID) (agreement version.Agreement, err error)
+	ConnectToP2PNode(ctx context.Context, nodeP2PConnection string, nodePeerID peer.ID) error
+	//servicegen:retry could not get host address
+	GetHostAddress(ctx context.Context) (hostAddress string)
+}
+
+//servicegen:service keygennofsm Keygennofsm
+type keygennofsmService interface {
+	ReceiveMessage(ctx context.Context, keygenMessage keygennofsm.KeygenMessage) error
+	//servicegen:method receive_BFT_message
+	ReceiveBFTMessage(ctx context.Context, keygenMessage keygennofsm.KeygenMessage) error
+}
+
+//servicegen:service pss PSS
+type pssService interface {
+	//servicegen:method PSS_instance_exists
+	//servicegen:retry could not check if pss instance exists
+	PSSInstanceExists(ctx context.Context, protocolPrefix PSSProtocolPrefix) (exists bool)
+	//servicegen:method get_PSS_protocol_prefix
+	//servicegen:fatal could not get pss protocol prefix
+	GetPSSProtocolPrefix(ctx context.Context, oldEpoch int, newEpoch int) (pssProtocolPrefix PSSProtocolPrefix)
+	//servicegen:method receive_BFT_message
+	ReceiveBFTMessage(ctx context.Context, protocolPrefix PSSProtocolPrefix, pssMessage pss.PSSMessage) error
+	//servicegen:retry could not get new N
+	GetNewNodesN(ctx context.Context, protocolPrefix PSSProtocolPrefix) (newN int)
+	//servicegen:retry could not get new K
+	GetNewNodesK(ctx context.Context, protocolPrefix PSSProtocolPrefix) (newK int)
+	//servicegen:retry could not get new T
+	GetNewNodesT(ctx context.Context, protocolPrefix PSSProtocolPrefix) (newT int)
+	//servicegen:retry could not get old N
+	GetOldNodesN(ctx context.Context, protocolPrefix PSSProtocolPrefix) (oldN int)
+	//servicegen:retry could not get old K
+	GetOldNodesK(ctx context.Context, protocolPrefix PSSProtocolPrefix) (oldK int)
+	//servicegen:retry could not get old T
+	GetOldNodesT(ctx context.Context, protocolPrefix PSSProtocolPrefix) (oldT int)
+	//servicegen:method new_PSS_node
+	NewPSSNode(ctx context.Context, pssStartData PSSStartData, isDealer bool, isPlayer bool) error
+	//servicegen:method send_PSS_message_to_node
+	SendPSSMessageToNode(ctx context.Context, protocolPrefix PSSProtocolPrefix, pssNodeDetails pss.NodeDetails, pssMessage pss.PSSMessage) error
+}
+
+//servicegen:service mapping Mapping
+type mappingService interface {
+	NewMappingNode(ctx context.Context, mappingStartData MappingStartData, isOldNode bool, isNewNode bool) error
+	//servicegen:method receive_BFT_message
+	ReceiveBFTMessage(ctx context.Context, mappingID mapping.MappingID, mappingMessage mapping.MappingMessage) error
+	//servicegen:method get_mapping_ID
+	//servicegen:retry could not get mappingID
+	GetMappingID(ctx context.Context, oldEpoch int, newEpoch int) (mappingID mapping.MappingID)
+	//servicegen:retry could not check if mapping instance exists
+	MappingInstanceExists(ctx context.Context, mappingID mapping.MappingID) (exists bool)
+	//servicegen:log could not get freeze state
+	GetFreezeState(ctx context.Context, mappingID mapping.MappingID) (freezeState int, lastUnassignedIndex uint)
+	//servicegen:log could not set freeze state
+	SetFreezeState(ctx context.Context, mappingID mapping.MappingID, freezeState int, lastUnassignedIndex uint)
+	ProposeFreeze(ctx context.Context, mappingID mapping.MappingID) error
+	MappingSummaryFrozen(ctx context.Context, mappingID mapping.MappingID, mappingSummaryMessage mapping.MappingSummaryMessage) error
+	//servicegen:retry could not get mapping protocol prefix
+	GetMappingProtocolPrefix(ctx context.Context, oldEpoch int, newEpoch int) (mappingProtocolPrefix MappingProtocolPrefix)
+}
+
+//servicegen:service database Database
+type databaseService interface {
+	StoreKeygenCommitmentMatrix(ctx context.Context, keyIndex big.Int, c [][]common.Point) error
+	//servicegen:method store_PSS_commitment_matrix
+	StorePSSCommitmentMatrix(ctx context.Context, keyIndex big.Int, c [][]common.Point) error
+	StoreCompletedKeygenShare(ctx context.Context, keyIndex big.Int, si big.Int, siprime big.Int) error
+	//servicegen:method store_completed_PSS_share
+	StoreCompletedPSSShare(ctx context.Context, keyIndex big.Int, si big.Int, siprime big.Int) error
+	StoreCompletedKeygen(ctx context.Context, keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error
+	//servicegen:method store_completed_PSS
+	StoreCompletedPSS(ctx context.Context, keyIndex big.Int, c [][]common.Point, si big.Int, siprime big.Int) error
+	StorePublicKeyToIndex(ctx context.Context, publicKey common.Point, keyIndex big.Int) error
+	RetrieveCommitmentMatrix(ctx context.Context, keyIndex big.Int) (c [][]common.Point, err error)
+	RetrievePublicKeyToIndex(ctx context.Context, publicKey common.Point) (keyIndex big.Int, err error)
+	RetrieveIndexToPublicKey(ctx context.Context, keyIndex big.Int) (publicKey common.Point, err error)
+	//servicegen:retry could not get index_to_public_key_exists
+	IndexToPublicKeyExists(ctx context.Context, keyIndex big.Int) (exists bool)
+	RetrieveCompletedShare(ctx context.Context, keyIndex big.Int) (si big.Int, siprime big.Int, err error)
+	//servicegen:retry could not get share count
+	GetShareCount(ctx context.Context) (count int)
+	//servicegen:retry could not get keygen started
+	GetKeygenStarted(ctx context.Context, keygenID string) (started bool)
+	SetKeygenStarted(ctx context.Context, keygenID string, started bool) error
+	StoreConnectionDetails(ctx context.Context, nodeAddress ethCommon.Address, connectionDetails ConnectionDetails) error
+	RetrieveConnectionDetails(ctx context.Context, nodeAddress ethCommon.Address) (connectionDetails ConnectionDetails, err error)
+	StoreNodePubKey(ctx context.Context, nodeAddress ethCommon.Address, pubKey common.Point) error
+	RetrieveNodePubKey(ctx context.Context, nodeAddress ethCommon.Address) (pubKey common.Point, err error)
+	SnapshotDB(ctx context.Context, path string) (count int, err error)
+}
+
+//servicegen:service verifier Verifier
+type verifierService interface {
+	// the raw message is passed as a pointer because it has a custom marshaller
+	Verify(ctx context.Context, rawMessage *bijson.RawMessage) (valid bool, verifierID string, err error)
+	CleanToken(ctx context.Context, verifierIdentifier string, idtoken string) (cleanedToken string, err error)
+	//servicegen:retry could not list verifiers
+	ListVerifiers(ctx context.Context) (verifiers []string)
+}
+
+//servicegen:service cache Cache
+type cacheService interface {
+	//servicegen:retry could not check if token commit exists
+	TokenCommitExists(ctx context.Context, verifier string, tokenCommitment string) (exists bool)
+	//servicegen:retry could not get token commit key
+	GetTokenCommitKey(ctx context.Context, verifier string, tokenCommitment string) (pubKey common.Point)
+	//servicegen:log could not record token commit
+	RecordTokenCommit(ctx context.Context, verifier string, tokenCommitment string, pubKey common.Point)
+	//servicegen:retry could not check if signer signature exists
+	SignerSigExists(ctx context.Context, signature string) (exists bool)
+	//servicegen:log could not record signer signature
+	RecordSignerSig(ctx context.Context, signature string)
+}
@@ -1,17 +1,24 @@
 package dkgnode
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/avast/retry-go"
 
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/config"
 	"github.com/torusresearch/torus-node/eventbus"
+	"github.com/torusresearch/torus-node/tcontext"
+	"github.com/torusresearch/torus-node/tracing"
 )
 
 type ServiceRegistry struct {
@@ -100,6 +107,113 @@ type MethodRequest struct {
 	Method  string
 	ID      string
 	Data    []interface{}
+	// RequestID is the tcontext ID of the caller, shared by every call made for one request
+	RequestID string
+	// Deadline is the time after which the caller no longer waits for the response, zero if none
+	Deadline time.Time
+	// TraceParent is the traceparent of the span of the call, empty if the caller is not traced
+	TraceParent string
+}
+
+// ServiceTimeoutError is returned by ServiceMethod when a service did not respond before the deadline
+type ServiceTimeoutError struct {
+	Service   string
+	Method    string
+	RequestID string
+}
+
+func (e *ServiceTimeoutError) Error() string {
+	return fmt.Sprintf("service %v did not respond to method %v before the deadline, requestID %v", e.Service, e.Method, e.RequestID)
+}
+
+// IsServiceTimeout reports whether err is a ServiceTimeoutError
+func IsServiceTimeout(err error) bool {
+	_, ok := err.(*ServiceTimeoutError)
+	return ok
+}
+
+// defaultServiceTimeout bounds every ServiceMethod call unless it is overridden for the method
+const defaultServiceTimeout = 30 * time.Second
+
+// defaultServiceMethodTimeouts overrides defaultServiceTimeout by "service.method", 0 disables the timeout.
+// These methods wait for the network or a protocol to make progress, or copy whole databases.
+var defaultServiceMethodTimeouts = map[string]time.Duration{
+	"ethereum.await_complete_node_list": 0,
+	"ethereum.await_nodes_connected":    0,
+	"keygennofsm.receive_message":       0,
+	"keygennofsm.receive_BFT_message":   0,
+	"pss.receive_BFT_message":           0,
+	"pss.send_PSS_message_to_node":      0,
+	"mapping.receive_BFT_message":       0,
+	"abci.pause_commits":                0,
+	"abci.snapshot_state":               0,
+	"tendermint.snapshot_stores":        0,
+	"database.snapshot_db":              0,
+}
+
+var serviceTimeouts struct {
+	sync.Once
+	fallback time.Duration
+	methods  map[string]time.Duration
+}
+
+// parseServiceMethodTimeouts parses comma separated "service.method=milliseconds" pairs
+func parseServiceMethodTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || !strings.Contains(kv[0], ".") {
+			return nil, fmt.Errorf("invalid service method timeout %v, expected service.method=milliseconds", pair)
+		}
+		ms, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid service method timeout %v, expected service.method=milliseconds", pair)
+		}
+		timeouts[strings.TrimSpace(kv[0])] = time.Duration(ms) * time.Millisecond
+	}
+	return timeouts, nil
+}
+
+func loadServiceTimeouts() {
+	serviceTimeouts.fallback = defaultServiceTimeout
+	serviceTimeouts.methods = make(map[string]time.Duration)
+	for method, timeout := range defaultServiceMethodTimeouts {
+		serviceTimeouts.methods[method] = timeout
+	}
+	if config.GlobalConfig == nil {
+		return
+	}
+	if config.GlobalConfig.ServiceTimeoutMS > 0 {
+		serviceTimeouts.fallback = time.Duration(config.GlobalConfig.ServiceTimeoutMS) * time.Millisecond
+	}
+	overrides, err := parseServiceMethodTimeouts(config.GlobalConfig.ServiceMethodTimeoutsMS)
+	if err != nil {
+		logging.WithError(err).Error("could not parse service method timeouts, using defaults")
+		return
+	}
+	for method, timeout := range overrides {
+		serviceTimeouts.methods[method] = timeout
+	}
+}
+
+// serviceMethodTimeout returns the timeout of a ServiceMethod call, 0 if it may block indefinitely
+func serviceMethodTimeout(service string, method string) time.Duration {
+	serviceTimeouts.Do(loadServiceTimeouts)
+	if timeout, ok := serviceTimeouts.methods[service+"."+method]; ok {
+		return timeout
+	}
+	return serviceTimeouts.fallback
+}
+
+// retryUntilDone stops retrying a ServiceLibrary call once its context is done
+func retryUntilDone(ctx context.Context) retry.Option {
+	return retry.RetryIf(func(error) bool {
+		return ctx.Err() == nil
+	})
 }
 
 func (s *ServiceRegistry) SetupMethodRouting() {
@@ -136,11 +250,12 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 				defer func() {
 					if err := recover(); err != nil {
 						logging.WithFields(logging.Fields{
-							"Caller": methodRequest.Caller,
-							"Method": methodRequest.Method,
-							"data":   data,
-							"error":  err,
-							"Stack":  stringify(debug.Stack()),
+							"Caller":    methodRequest.Caller,
+							"Method":    methodRequest.Method,
+							"RequestID": methodRequest.RequestID,
+							"data":      data,
+							"error":     err,
+							"Stack":     stringify(debug.Stack()),
 						}).Error("panicked during baseService.Call")
 						resp := MethodResponse{
 							Error: fmt.Errorf("%v", err),
@@ -149,7 +264,19 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 						s.eventBus.Publish(methodRequest.ID, resp)
 					}
 				}()
-				data, err := baseService.Call(methodRequest.Method, methodRequest.Data...)
+				// the caller has stopped waiting, do not start work nobody will use
+				if !methodRequest.Deadline.IsZero() && time.Now().After(methodRequest.Deadline) {
+					s.eventBus.Publish(methodRequest.ID, MethodResponse{
+						Request: methodRequest,
+						Error:   &ServiceTimeoutError{Service: methodRequest.Service, Method: methodRequest.Method, RequestID: methodRequest.RequestID},
+					})
+					return
+				}
+				ctx, span := startRemoteSpan(context.Background(), methodRequest.Service+"."+methodRequest.Method, tracing.KindServer, methodRequest.TraceParent)
+				span.SetAttribute("caller", methodRequest.Caller)
+				data, err := baseService.Call(ctx, methodRequest.Method, methodRequest.Data...)
+				span.RecordError(err)
+				span.End()
 				resp := MethodResponse{
 					Request: methodRequest,
 					Error:   err,
@@ -171,7 +298,8 @@ type MethodResponse struct {
 }
 
 func AwaitTopic(eventBus eventbus.Bus, topic string) <-chan interface{} {
-	responseCh := make(chan interface{})
+	// buffered so that the handler does not block if the receiver has stopped waiting
+	responseCh := make(chan interface{}, 1)
 	err := eventBus.SubscribeOnceAsync(topic, func(res interface{}) {
 		responseCh <- res
 		close(responseCh)
@@ -182,7 +310,14 @@ func AwaitTopic(eventBus eventbus.Bus, topic string) <-chan interface{} {
 	return responseCh
 }
 
-func ServiceMethod(eventBus eventbus.Bus, caller string, service string, method string, data ...interface{}) MethodResponse {
+// ServiceMethod calls method on service and waits for the response until ctx is done or the
+// timeout of the method has passed, in which case the response holds a ServiceTimeoutError
+func ServiceMethod(ctx context.Context, eventBus eventbus.Bus, caller string, service string, method string, data ...interface{}) MethodResponse {
+	if timeout := serviceMethodTimeout(service, method); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	nonce, err := rand.Int(rand.Reader, secp256k1.GeneratorOrder)
 	if err != nil {
 		return MethodResponse{
@@ -191,23 +326,59 @@ func ServiceMethod(eventBus eventbus.Bus, caller string, service string, method
 		}
 	}
 	nonceStr := nonce.Text(16)
-	responseCh := AwaitTopic(eventBus, nonceStr)
-	eventBus.Publish("method", MethodRequest{
-		Caller:  caller,
-		Service: service,
-		Method:  method,
-		ID:      nonceStr,
-		Data:    data,
-	})
-	methodResponseInter := <-responseCh
-	methodResponse, ok := methodResponseInter.(MethodResponse)
+	requestID, ok := ctx.Value(tcontext.ContextID).(string)
 	if !ok {
+		requestID = nonceStr
+	}
+	deadline, _ := ctx.Deadline()
+	ctx, span := startChildSpan(ctx, service+"."+method, tracing.KindClient)
+	span.SetAttribute("caller", caller)
+	methodResponse := awaitServiceMethod(ctx, eventBus, MethodRequest{
+		Caller:      caller,
+		Service:     service,
+		Method:      method,
+		ID:          nonceStr,
+		Data:        data,
+		RequestID:   requestID,
+		Deadline:    deadline,
+		TraceParent: span.Traceparent(),
+	})
+	span.RecordError(methodResponse.Error)
+	span.End()
+	return methodResponse
+}
+
+// awaitServiceMethod publishes methodRequest and waits for its response until ctx is done
+func awaitServiceMethod(ctx context.Context, eventBus eventbus.Bus, methodRequest MethodRequest) MethodResponse {
+	service, method, requestID := methodRequest.Service, methodRequest.Method, methodRequest.RequestID
+	responseCh := AwaitTopic(eventBus, methodRequest.ID)
+	eventBus.Publish("method", methodRequest)
+	select {
+	case methodResponseInter := <-responseCh:
+		methodResponse, ok := methodResponseInter.(MethodResponse)
+		if !ok {
+			return MethodResponse{
+				Error: errors.New("Method response was not of MethodResponse type"),
+				Data:  nil,
+			}
+		}
+		return methodResponse
+	case <-ctx.Done():
+		err := eventBus.UnsubscribeAll(methodRequest.ID)
+		if err != nil {
+			logging.WithError(err).Error("could not unsubscribe from method response")
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return MethodResponse{
+				Error: &ServiceTimeoutError{Service: service, Method: method, RequestID: requestID},
+				Data:  nil,
+			}
+		}
 		return MethodResponse{
-			Error: errors.New("Method response was not of MethodResponse type"),
+			Error: ctx.Err(),
 			Data:  nil,
 		}
 	}
-	return methodResponse
 }
 
 func EmptyHandler(name string) func() {
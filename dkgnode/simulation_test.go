@@ -0,0 +1,61 @@
+package dkgnode
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/torusresearch/torus-node/sim"
+)
+
+var (
+	seed  = flag.Int64("seed", 0, "seed of the simulated node, picked from the time if 0")
+	seeds = flag.Int("seeds", 1, "number of seeds the simulated node runs with, drawn from -seed")
+)
+
+// simulate runs f with the clock and entropy of the node replaced by those of the simulation of
+// each of -seeds seeds drawn from -seed
+func simulate(t *testing.T, f func(t *testing.T, simulation *sim.Simulation)) {
+	sim.Run(t, *seed, *seeds, func(t *testing.T, simulation *sim.Simulation) {
+		clock, entropy := nodeClock, nodeEntropy
+		nodeClock, nodeEntropy = simulation.Clock, simulation.Entropy
+		defer func() {
+			nodeClock, nodeEntropy = clock, entropy
+		}()
+		f(t, simulation)
+	})
+}
+
+func TestCommitGateLease(t *testing.T) {
+	simulate(t, func(t *testing.T, simulation *sim.Simulation) {
+		start := simulation.Clock.Now()
+		g := newCommitGate()
+		g.beginBlock()
+		paused := make(chan error)
+		go func() {
+			paused <- g.pause("backup", time.Minute)
+		}()
+		// the pause waits for the block in progress to be committed
+		simulation.Clock.BlockUntil(1)
+		g.commit()
+		if err := <-paused; err != nil {
+			t.Fatal(err)
+		}
+		if err := g.pause("other", time.Minute); err == nil {
+			t.Fatal("expected a pause with another token to be refused")
+		}
+
+		began := make(chan struct{})
+		go func() {
+			g.beginBlock()
+			close(began)
+		}()
+		<-began
+		if elapsed := simulation.Clock.Now().Sub(start); elapsed != time.Minute {
+			t.Fatalf("expected the next block to begin once the lease expired, after %v", elapsed)
+		}
+		if err := g.resume("backup"); err != errCommitPauseLapsed {
+			t.Fatalf("expected resuming a lapsed pause to fail, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,328 @@
+package dkgnode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/host"
+	inet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	logging "github.com/sirupsen/logrus"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/framing"
+	"github.com/torusresearch/torus-node/telemetry"
+	"github.com/torusresearch/torus-node/version"
+)
+
+const (
+	defaultP2PMaxMessageSize    = 16 << 20
+	defaultP2PCompressThreshold = 64 << 10
+	// streamOpenTimeout bounds dialing a peer and negotiating a protocol
+	streamOpenTimeout = 30 * time.Second
+	// streamWriteTimeout bounds writing a message to a peer that does not read its streams
+	streamWriteTimeout = 30 * time.Second
+	// streamIdleTimeout closes streams that were not used, such as those of protocols of past epochs
+	streamIdleTimeout = 5 * time.Minute
+)
+
+// every message on a framed stream is answered with one of these bytes once it was read,
+// so that the sender learns of streams the receiver reset before the message arrived
+const (
+	streamAckDelivered byte = iota
+	streamAckRejected
+)
+
+// errStreamRejected is returned for messages refused by the receiver, such as by a peer that banned this node
+var errStreamRejected = errors.New("peer rejected the message")
+
+// p2pMaxMessageSize is the largest p2p message sent or accepted, in bytes
+func p2pMaxMessageSize() int {
+	if config.GlobalConfig.P2PMaxMessageSize > 0 {
+		return config.GlobalConfig.P2PMaxMessageSize
+	}
+	return defaultP2PMaxMessageSize
+}
+
+// p2pCompressThreshold is the size above which messages on framed streams are compressed, 0 if disabled
+func p2pCompressThreshold() int {
+	if config.GlobalConfig.P2PCompressThreshold < 0 {
+		return 0
+	}
+	if config.GlobalConfig.P2PCompressThreshold > 0 {
+		return config.GlobalConfig.P2PCompressThreshold
+	}
+	return defaultP2PCompressThreshold
+}
+
+// framedProtocol returns the protocol of the long-lived streams carrying framed messages of proto.
+// Nodes that do not support it are sent one message per stream opened on proto itself.
+func framedProtocol(proto protocol.ID) protocol.ID {
+	return protocol.ID(strings.TrimSuffix(string(proto), "/") + "/framed/1.0.0")
+}
+
+type streamKey struct {
+	peer  peer.ID
+	proto protocol.ID
+}
+
+type outboundStream struct {
+	lock   sync.Mutex
+	stream inet.Stream
+	writer *framing.Writer
+	ack    [1]byte
+	// lastUsed is guarded by the lock of the pool
+	lastUsed time.Time
+}
+
+// streamPool keeps a long-lived framed stream per peer and protocol to send messages on
+type streamPool struct {
+	ctx      context.Context
+	host     host.Host
+	versions *peerVersions
+
+	lock    sync.Mutex
+	streams map[streamKey]*outboundStream
+}
+
+func newStreamPool(ctx context.Context, h host.Host, versions *peerVersions) *streamPool {
+	sp := &streamPool{
+		ctx:      ctx,
+		host:     h,
+		versions: versions,
+		streams:  make(map[streamKey]*outboundStream),
+	}
+	h.Network().Notify(&inet.NotifyBundle{
+		DisconnectedF: func(n inet.Network, c inet.Conn) {
+			if n.Connectedness(c.RemotePeer()) != inet.Connected {
+				sp.closePeer(c.RemotePeer())
+			}
+		},
+	})
+	go sp.closeIdle()
+	return sp
+}
+
+// get returns the framed stream to key, opening it if needed. Peers that do not support framed
+// streams negotiate the protocol itself, the stream is then returned as legacy for a single message.
+// Until the handshake with the peer completes, both protocols are offered.
+func (sp *streamPool) get(ctx context.Context, key streamKey, agreement version.Agreement, negotiated bool) (out *outboundStream, legacy inet.Stream, err error) {
+	framed := !negotiated || agreement.Has(version.CapabilityFramedStreams)
+	if framed {
+		sp.lock.Lock()
+		out, ok := sp.streams[key]
+		sp.lock.Unlock()
+		if ok {
+			return out, nil, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamOpenTimeout)
+	defer cancel()
+	protocols := []protocol.ID{key.proto}
+	if framed {
+		protocols = []protocol.ID{framedProtocol(key.proto), key.proto}
+	}
+	s, err := sp.host.NewStream(ctx, key.peer, protocols...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.Protocol() != framedProtocol(key.proto) {
+		return nil, s, nil
+	}
+	compressAbove := p2pCompressThreshold()
+	if negotiated && !agreement.Has(version.CapabilityCompression) {
+		compressAbove = 0
+	}
+	out = &outboundStream{
+		stream:   s,
+		writer:   framing.NewWriter(s, p2pMaxMessageSize(), compressAbove),
+		lastUsed: time.Now(),
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	// another message to the peer may have opened a stream meanwhile
+	if existing, ok := sp.streams[key]; ok {
+		_ = s.Close()
+		return existing, nil, nil
+	}
+	sp.streams[key] = out
+	return out, nil, nil
+}
+
+// remove resets out if it is still the stream to key
+func (sp *streamPool) remove(key streamKey, out *outboundStream) {
+	sp.lock.Lock()
+	if sp.streams[key] == out {
+		delete(sp.streams, key)
+	}
+	sp.lock.Unlock()
+	_ = out.stream.Reset()
+}
+
+// send writes data to the stream of id and proto. Messages are framed on a long-lived stream and
+// acknowledged by the receiver, or sent on a stream of their own to nodes that do not support framed
+// streams. A message whose framed stream failed is sent again once on a new stream, as the peer may
+// have reset the stream since it was last used, such as when it restarted.
+func (sp *streamPool) send(ctx context.Context, id peer.ID, proto protocol.ID, data []byte) error {
+	if len(data) > p2pMaxMessageSize() {
+		return framing.ErrTooLarge
+	}
+	agreement, err := sp.versions.get(id)
+	negotiated := err == nil
+	if err != nil && err != errHandshakePending {
+		telemetry.IncrementCounter(pcmn.TelemetryConstants.P2P.IncompatibleSendCounter, pcmn.TelemetryConstants.P2P.Prefix)
+		return err
+	}
+	key := streamKey{peer: id, proto: proto}
+	for attempt := 0; ; attempt++ {
+		out, legacy, err := sp.get(ctx, key, agreement, negotiated)
+		if err != nil {
+			return err
+		}
+		if legacy != nil {
+			return writeLegacyMessage(legacy, data)
+		}
+		err = sp.sendFramed(out, data)
+		if err == nil {
+			return nil
+		}
+		// the stream cannot be used after a failed write, the next message opens a new one
+		sp.remove(key, out)
+		if err == errStreamRejected || attempt > 0 {
+			return err
+		}
+		logging.WithFields(logging.Fields{
+			"peer":  id.Pretty(),
+			"proto": proto,
+		}).WithError(err).Debug("framed stream failed, sending again on a new stream")
+	}
+}
+
+// sendFramed writes data to out and waits for the receiver to acknowledge it
+func (sp *streamPool) sendFramed(out *outboundStream, data []byte) error {
+	sp.lock.Lock()
+	out.lastUsed = time.Now()
+	sp.lock.Unlock()
+	out.lock.Lock()
+	defer out.lock.Unlock()
+	if err := out.stream.SetDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	if err := out.writer.WriteMessage(data); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(out.stream, out.ack[:]); err != nil {
+		return err
+	}
+	if out.ack[0] != streamAckDelivered {
+		return errStreamRejected
+	}
+	return nil
+}
+
+// closePeer resets the streams to id, such as after it disconnected
+func (sp *streamPool) closePeer(id peer.ID) {
+	sp.lock.Lock()
+	var closed []*outboundStream
+	for key, out := range sp.streams {
+		if key.peer == id {
+			closed = append(closed, out)
+			delete(sp.streams, key)
+		}
+	}
+	sp.lock.Unlock()
+	for _, out := range closed {
+		_ = out.stream.Reset()
+	}
+}
+
+func (sp *streamPool) closeIdle() {
+	ticker := time.NewTicker(streamIdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sp.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sp.lock.Lock()
+		var idle []*outboundStream
+		for key, out := range sp.streams {
+			if time.Since(out.lastUsed) > streamIdleTimeout {
+				idle = append(idle, out)
+				delete(sp.streams, key)
+			}
+		}
+		sp.lock.Unlock()
+		for _, out := range idle {
+			// closing lets the peer read the messages written before
+			_ = out.stream.Close()
+		}
+	}
+}
+
+// writeLegacyMessage sends data on a stream of its own and waits for the peer to close it
+func writeLegacyMessage(s inet.Stream, data []byte) error {
+	_, err := s.Write(data)
+	if err != nil {
+		e := s.Reset()
+		if e != nil {
+			logging.WithError(e).Error("could not reset stream")
+		}
+		return err
+	}
+	// FullClose closes the stream and waits for the other side to close their half.
+	err = helpers.FullClose(s)
+	if err != nil {
+		e := s.Reset()
+		if e != nil {
+			logging.WithError(e).Error("could not reset stream")
+		}
+		return err
+	}
+	return nil
+}
+
+// inboundStreams tracks the framed streams read for each protocol, so that they are
+// closed when the protocol stops forwarding messages
+type inboundStreams struct {
+	lock    sync.Mutex
+	streams map[protocol.ID]map[inet.Stream]struct{}
+}
+
+func newInboundStreams() *inboundStreams {
+	return &inboundStreams{streams: make(map[protocol.ID]map[inet.Stream]struct{})}
+}
+
+func (in *inboundStreams) add(proto protocol.ID, s inet.Stream) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	if in.streams[proto] == nil {
+		in.streams[proto] = make(map[inet.Stream]struct{})
+	}
+	in.streams[proto][s] = struct{}{}
+}
+
+func (in *inboundStreams) remove(proto protocol.ID, s inet.Stream) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	delete(in.streams[proto], s)
+}
+
+// closeAll resets the streams of proto
+func (in *inboundStreams) closeAll(proto protocol.ID) {
+	in.lock.Lock()
+	streams := in.streams[proto]
+	delete(in.streams, proto)
+	in.lock.Unlock()
+	for s := range streams {
+		_ = s.Reset()
+	}
+}
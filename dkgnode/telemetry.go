@@ -48,8 +48,8 @@ func (t *TelemetryService) OnStop() error {
 	return nil
 }
 
-func (t *TelemetryService) Call(method string, args ...interface{}) (interface{}, error) {
-	return nil, nil
+func (t *TelemetryService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	return dispatchTelemetry(ctx, t, method, args)
 }
 
 func (t *TelemetryService) SetBaseService(bs *BaseService) {
@@ -3,10 +3,13 @@ package dkgnode
 /* All useful imports */
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 
 	"github.com/torusresearch/torus-node/telemetry"
 
@@ -27,24 +30,30 @@ import (
 	tmnode "github.com/torusresearch/tendermint/node"
 	tmp2p "github.com/torusresearch/tendermint/p2p"
 	"github.com/torusresearch/tendermint/privval"
+	"github.com/torusresearch/tendermint/proxy"
 	tmclient "github.com/torusresearch/tendermint/rpc/client"
 	rpcclient "github.com/torusresearch/tendermint/rpc/lib/client"
 	rpctypes "github.com/torusresearch/tendermint/rpc/lib/types"
 	tmtypes "github.com/torusresearch/tendermint/types"
+	dbm "github.com/torusresearch/tm-db"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/db"
 	"github.com/torusresearch/torus-node/eventbus"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/signer"
 
 	"github.com/torusresearch/torus-node/tmlog"
 )
 
-func NewTendermintService(ctx context.Context, eventBus eventbus.Bus) *BaseService {
+func NewTendermintService(ctx context.Context, eventBus eventbus.Bus, nodeSigner signer.Signer) *BaseService {
 	tendermintCtx, cancel := context.WithCancel(context.WithValue(ctx, ContextID, "tendermint"))
 	tendermintService := TendermintService{
 		cancel:   cancel,
 		ctx:      tendermintCtx,
 		eventBus: eventBus,
+		signer:   nodeSigner,
+		stores:   &tmStores{dbs: make(map[string]dbm.DB)},
 	}
 	tendermintService.serviceLibrary = NewServiceLibrary(tendermintService.eventBus, tendermintService.Name())
 	return NewBaseService(&tendermintService)
@@ -57,6 +66,7 @@ type TendermintService struct {
 	ctx            context.Context
 	eventBus       eventbus.Bus
 	serviceLibrary ServiceLibrary
+	signer         signer.Signer
 
 	bftSemaphore         *pcmn.Semaphore
 	bftRPC               *BFTRPC
@@ -64,6 +74,7 @@ type TendermintService struct {
 	bftNode              *node.Node
 	bftRPCWSQueryHandler *BFTRPCWSQueryHandler
 	bftRPCWSStatus       BFTRPCWSStatus
+	stores               *tmStores
 	// responseChannelMap   map[string]chan []byte
 }
 
@@ -98,17 +109,16 @@ func (t *TendermintService) OnStart() error {
 	} else {
 		logging.Debug("Removed write ahead log")
 	}
-	nodeKey, err := os.Open(config.GlobalConfig.BasePath + "/tendermint/config/node_key.json")
+	// the keys themselves are never logged
+	_, err = os.Stat(config.GlobalConfig.BasePath + "/tendermint/config/node_key.json")
 	if err == nil {
-		bytVal, _ := ioutil.ReadAll(nodeKey)
-		logging.WithField("NodeKey", string(bytVal)).Debug()
+		logging.Debug("Found NodeKey")
 	} else {
 		logging.Debug("Could not find NodeKey")
 	}
-	privValidatorKey, err := os.Open(config.GlobalConfig.BasePath + "/tendermint/config/priv_validator_key.json")
+	_, err = os.Stat(config.GlobalConfig.BasePath + "/tendermint/config/priv_validator_key.json")
 	if err == nil {
-		bytVal, _ := ioutil.ReadAll(privValidatorKey)
-		logging.WithField("PrivValidatorKey", stringify(bytVal)).Debug()
+		logging.Debug("Found PrivValidatorKey")
 	} else {
 		logging.Debug("Could not find PrivValidatorKey")
 	}
@@ -206,92 +216,108 @@ func (t *TendermintService) OnStop() error {
 	return nil
 }
 
-func (t *TendermintService) Call(method string, args ...interface{}) (interface{}, error) {
+func (t *TendermintService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Tendermint.Prefix)
 
-	switch method {
-	case "get_node_key":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.GetNodeKeyCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+	return dispatchTendermint(ctx, t, method, args)
+}
 
-		cdc := amino.NewCodec()
-		cryptoAmino.RegisterAmino(cdc)
-		return cdc.MarshalJSON(*t.tmNodeKey)
-	case "get_status":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.GetStatusCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+func (t *TendermintService) handleGetNodeKey(ctx context.Context) ([]byte, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.GetNodeKeyCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
 
-		return t.bftRPCWSStatus, nil
-		// Broadcast(tx DefaultBFTTxWrapper) (txHash common.Hash, err error)
-	case "broadcast":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.BroadcastCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+	cdc := amino.NewCodec()
+	cryptoAmino.RegisterAmino(cdc)
+	return cdc.MarshalJSON(*t.tmNodeKey)
+}
 
-		var args0 interface{}
-		_ = castOrUnmarshal(args[0], &args0)
+func (t *TendermintService) handleGetStatus(ctx context.Context) (BFTRPCWSStatus, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.GetStatusCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
 
-		err := retry.Do(func() error {
-			if t.bftRPC == nil {
-				logging.Error("bftRPC is still uninitialized")
-				return fmt.Errorf("bftRPC is still uninitialized")
-			}
-			if t.bftRPCWSStatus == BftRPCWSStatusDown {
-				logging.Error("bftrpcwsstatus is still down")
-				return fmt.Errorf("bftrpcwsstatus is still down")
-			}
-			return nil
-		})
-		if err != nil {
-			return nil, err
+	return t.bftRPCWSStatus, nil
+}
+
+func (t *TendermintService) handleBroadcast(ctx context.Context, tx interface{}) (pcmn.Hash, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.BroadcastCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+
+	err := retry.Do(func() error {
+		if t.bftRPC == nil {
+			logging.Error("bftRPC is still uninitialized")
+			return fmt.Errorf("bftRPC is still uninitialized")
 		}
-		release := t.bftSemaphore.Acquire()
-		txHash, err := t.bftRPC.Broadcast(args0)
-		release()
-		if err != nil {
-			return nil, err
+		if t.bftRPCWSStatus == BftRPCWSStatusDown {
+			logging.Error("bftrpcwsstatus is still down")
+			return fmt.Errorf("bftrpcwsstatus is still down")
 		}
-		return *txHash, nil
-	// RegisterQuery(query string, count int) (respChannel chan []byte, err error)
-	case "register_query":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.RegisterQueryCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
-
-		var args0 string
-		var args1 int
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		query := args0
-		count := args1
-		release := t.bftSemaphore.Acquire()
-		responseCh, cancel, err := t.RegisterQuery(query, count)
-		release()
-		if err != nil {
-			return nil, err
+		return nil
+	})
+	if err != nil {
+		return pcmn.Hash{}, err
+	}
+	release := t.bftSemaphore.Acquire()
+	txHash, err := t.bftRPC.Broadcast(tx)
+	release()
+	if err != nil {
+		return pcmn.Hash{}, err
+	}
+	return *txHash, nil
+}
+
+// handleRegisterQuery forwards the responses to the query on the "tendermint:forward:"+query topic
+func (t *TendermintService) handleRegisterQuery(ctx context.Context, query string, count int) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.RegisterQueryCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+
+	release := t.bftSemaphore.Acquire()
+	responseCh, cancel, err := t.RegisterQuery(query, count)
+	release()
+	if err != nil {
+		return err
+	}
+	go func() {
+		for response := range responseCh {
+			t.eventBus.Publish("tendermint:forward:"+query, MethodResponse{
+				Error: nil,
+				Data:  response,
+			})
 		}
-		go func() {
-			for response := range responseCh {
-				t.eventBus.Publish("tendermint:forward:"+query, MethodResponse{
-					Error: nil,
-					Data:  response,
-				})
-			}
-			cancel()
-		}()
-		return nil, nil
-	// DeregisterQuery(query string) (err error)
-	case "deregister_query":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.DeRegisterQueryCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
-
-		var args0 string
-		_ = castOrUnmarshal(args[0], &args0)
-
-		release := t.bftSemaphore.Acquire()
-		err := t.DeregisterQuery(args0)
-		release()
+		cancel()
+	}()
+	return nil
+}
+
+func (t *TendermintService) handleDeregisterQuery(ctx context.Context, query string) error {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.DeRegisterQueryCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+
+	release := t.bftSemaphore.Acquire()
+	defer release()
+	return t.DeregisterQuery(query)
+}
+
+// handleSnapshotStores dumps each tendermint database into dir from a point in time snapshot.
+// Commits should be paused so that the block and state stores are at most a block apart.
+func (t *TendermintService) handleSnapshotStores(ctx context.Context, dir string) (int, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Tendermint.SnapshotStoresCounter, pcmn.TelemetryConstants.Tendermint.Prefix)
+
+	t.stores.Lock()
+	defer t.stores.Unlock()
+	if len(t.stores.dbs) == 0 {
+		return 0, errors.New("tendermint node has not opened its databases")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, err
+	}
+	total := 0
+	for id, store := range t.stores.dbs {
+		iterator := store.Iterator(nil, nil)
+		count, err := dumpToFile(filepath.Join(dir, id+".kv"), func(w io.Writer) (int, error) {
+			return db.Dump(iterator, w)
+		})
+		iterator.Close()
 		if err != nil {
-			return nil, err
+			return total, fmt.Errorf("could not snapshot tendermint %v db: %v", id, err)
 		}
-		return nil, nil
-	default:
-		return nil, fmt.Errorf("Tendermint service method %v not found", method)
+		total += count
 	}
+	return total, nil
 }
 
 func (t *TendermintService) SetBaseService(bs *BaseService) {
@@ -299,7 +325,7 @@ func (t *TendermintService) SetBaseService(bs *BaseService) {
 }
 
 func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p.NodeKey, enableMetrics bool) {
-	nodeList := t.serviceLibrary.EthereumMethods().AwaitCompleteNodeList(t.serviceLibrary.EthereumMethods().GetCurrentEpoch())
+	nodeList := t.serviceLibrary.EthereumMethods().AwaitCompleteNodeList(t.ctx, t.serviceLibrary.EthereumMethods().GetCurrentEpoch(t.ctx))
 	// Starts tendermint node here
 	logging.WithField("nodeList", nodeList).Debug("Started tendermint node with nodelist")
 
@@ -322,11 +348,18 @@ func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p
 	defaultTmConfig.Instrumentation.Prometheus = enableMetrics
 	logger.Debug("Tendermint: Prometheus Metric - " + strconv.FormatBool(enableMetrics))
 
-	// converts own pv to tendermint key
-	// Note: DO NOT use tendermints GenPrivKeySecp256k1, it alters the key
-	pv := tmPrivateKeyFromBigInt(t.serviceLibrary.EthereumMethods().GetSelfPrivateKey())
-	pvF := privval.GenFilePVFromPrivKey(pv, defaultTmConfig.PrivValidatorKeyFile(), defaultTmConfig.PrivValidatorStateFile())
-	pvF.Save()
+	var privValidator tmtypes.PrivValidator
+	if _, err := signer.PrivateKey(t.signer); err == signer.ErrKeyNotExportable {
+		// the node key stays with the signer, so votes are signed through it
+		privValidator = newSignerPV(t.signer)
+	} else {
+		// converts own pv to tendermint key
+		// Note: DO NOT use tendermints GenPrivKeySecp256k1, it alters the key
+		pv := tmPrivateKeyFromBigInt(t.serviceLibrary.EthereumMethods().GetSelfPrivateKey(t.ctx))
+		pvF := privval.GenFilePVFromPrivKey(pv, defaultTmConfig.PrivValidatorKeyFile(), defaultTmConfig.PrivValidatorStateFile())
+		pvF.Save()
+		privValidator = pvF
+	}
 	genDoc := tmtypes.GenesisDoc{
 		ChainID:     "main-chain-BLUBLU",
 		GenesisTime: time.Unix(1578036594, 0),
@@ -379,7 +412,16 @@ func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p
 		logging.WithError(err).Fatal("config doesnt pass validation checks")
 	}
 
-	n, err := tmnode.DefaultNewNode(defaultTmConfig, logger)
+	// same as tmnode.DefaultNewNode, but keeps the databases to snapshot them for backups
+	n, err := tmnode.NewNode(defaultTmConfig,
+		privValidator,
+		nodeKey,
+		proxy.DefaultClientCreator(defaultTmConfig.ProxyApp, defaultTmConfig.ABCI, defaultTmConfig.DBDir()),
+		tmnode.DefaultGenesisDocProviderFunc(defaultTmConfig),
+		t.stores.provider,
+		tmnode.DefaultMetricsProvider(defaultTmConfig.Instrumentation),
+		logger,
+	)
 	if err != nil {
 		logging.WithError(err).Fatal("failed to create tendermint node")
 	}
@@ -394,6 +436,23 @@ func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p
 	logging.WithField("NodeInfo", n.Switch().NodeInfo()).Info("started tendermint")
 }
 
+// tmStores records the databases opened by the tendermint node
+type tmStores struct {
+	sync.Mutex
+	dbs map[string]dbm.DB
+}
+
+func (s *tmStores) provider(ctx *tmnode.DBContext) (dbm.DB, error) {
+	store, err := tmnode.DefaultDBProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.dbs[ctx.ID] = store
+	return store, nil
+}
+
 func tmPrivateKeyFromBigInt(key big.Int) tmsecp.PrivKeySecp256k1 {
 	var pv tmsecp.PrivKeySecp256k1
 	keyBytes := padPrivKeyBytes(key.Bytes())
@@ -0,0 +1,95 @@
+package dkgnode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	tmcrypto "github.com/torusresearch/tendermint/crypto"
+	tmtypes "github.com/torusresearch/tendermint/types"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+// steps of a height and round, in the order they are signed
+const (
+	signerPVStepPropose int8 = iota + 1
+	signerPVStepPrevote
+	signerPVStepPrecommit
+)
+
+// signerPV is a tendermint validator which signs votes and proposals through the node signer,
+// for signers such as PKCS#11 which never expose the node key to build a FilePV from.
+// Like the FilePV, it refuses to sign a height, round and step twice with different data.
+// Its sign state is not persisted, which matches the FilePV that is regenerated on every start.
+type signerPV struct {
+	sync.Mutex
+	signer    signer.Signer
+	pubKey    tmcrypto.PubKey
+	height    int64
+	round     int
+	step      int8
+	signBytes []byte
+	signature []byte
+}
+
+func newSignerPV(s signer.Signer) *signerPV {
+	pubKey := s.PublicKey()
+	return &signerPV{signer: s, pubKey: rawPointToTMPubKey(pubKey.X, pubKey.Y)}
+}
+
+func (pv *signerPV) GetPubKey() tmcrypto.PubKey {
+	return pv.pubKey
+}
+
+func (pv *signerPV) SignVote(chainID string, vote *tmtypes.Vote) error {
+	var step int8
+	switch vote.Type {
+	case tmtypes.PrevoteType:
+		step = signerPVStepPrevote
+	case tmtypes.PrecommitType:
+		step = signerPVStepPrecommit
+	default:
+		return fmt.Errorf("unknown vote type %v", vote.Type)
+	}
+	sig, err := pv.sign(vote.Height, vote.Round, step, vote.SignBytes(chainID))
+	if err != nil {
+		return err
+	}
+	vote.Signature = sig
+	return nil
+}
+
+func (pv *signerPV) SignProposal(chainID string, proposal *tmtypes.Proposal) error {
+	sig, err := pv.sign(proposal.Height, proposal.Round, signerPVStepPropose, proposal.SignBytes(chainID))
+	if err != nil {
+		return err
+	}
+	proposal.Signature = sig
+	return nil
+}
+
+func (pv *signerPV) sign(height int64, round int, step int8, signBytes []byte) ([]byte, error) {
+	pv.Lock()
+	defer pv.Unlock()
+	if height < pv.height ||
+		height == pv.height && round < pv.round ||
+		height == pv.height && round == pv.round && step < pv.step {
+		return nil, fmt.Errorf("refusing to sign %v/%v/%v after %v/%v/%v", height, round, step, pv.height, pv.round, pv.step)
+	}
+	if height == pv.height && round == pv.round && step == pv.step {
+		if bytes.Equal(signBytes, pv.signBytes) {
+			return pv.signature, nil
+		}
+		return nil, fmt.Errorf("refusing to sign conflicting data for %v/%v/%v", height, round, step)
+	}
+	// tendermint signs the sha256 hash of the sign bytes and takes r || s without the recovery id
+	hash := sha256.Sum256(signBytes)
+	sig, err := pv.signer.SignHash(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	pv.height, pv.round, pv.step = height, round, step
+	pv.signBytes, pv.signature = signBytes, sig[:64]
+	return pv.signature, nil
+}
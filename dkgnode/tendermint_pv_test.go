@@ -0,0 +1,50 @@
+package dkgnode
+
+import (
+	"testing"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	tmtypes "github.com/torusresearch/tendermint/types"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+func TestSignerPV(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv := newSignerPV(signer.NewMemorySigner(privKey))
+	// the validator key must be the one the genesis file lists for the node
+	if !pv.GetPubKey().Equals(tmPrivateKeyFromBigInt(*privKey.D).PubKey()) {
+		t.Fatal("expected the validator key to be the node key")
+	}
+
+	chainID := "main-chain-BLUBLU"
+	vote := &tmtypes.Vote{Type: tmtypes.PrevoteType, Height: 2, Round: 0}
+	if err := pv.SignVote(chainID, vote); err != nil {
+		t.Fatal(err)
+	}
+	if !pv.GetPubKey().VerifyBytes(vote.SignBytes(chainID), vote.Signature) {
+		t.Fatal("expected the vote signature to verify")
+	}
+	again := &tmtypes.Vote{Type: tmtypes.PrevoteType, Height: 2, Round: 0}
+	if err := pv.SignVote(chainID, again); err != nil {
+		t.Fatalf("expected the same vote to be signed again, got %v", err)
+	}
+
+	conflicting := &tmtypes.Vote{Type: tmtypes.PrevoteType, Height: 2, Round: 0, ValidatorIndex: 1}
+	if err := pv.SignVote(chainID, conflicting); err == nil {
+		t.Fatal("expected a conflicting vote for the same step to be refused")
+	}
+	proposal := &tmtypes.Proposal{Height: 2, Round: 0}
+	if err := pv.SignProposal(chainID, proposal); err == nil {
+		t.Fatal("expected a proposal after the prevote of the round to be refused")
+	}
+	proposal.Round = 1
+	if err := pv.SignProposal(chainID, proposal); err != nil {
+		t.Fatal(err)
+	}
+	if !pv.GetPubKey().VerifyBytes(proposal.SignBytes(chainID), proposal.Signature) {
+		t.Fatal("expected the proposal signature to verify")
+	}
+}
@@ -0,0 +1,159 @@
+package dkgnode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-node/config"
+	"github.com/torusresearch/torus-node/keygennofsm"
+	"github.com/torusresearch/torus-node/mapping"
+	"github.com/torusresearch/torus-node/pss"
+	"github.com/torusresearch/torus-node/tracing"
+)
+
+// parseTracingHeaders parses comma separated "key=value" pairs
+func parseTracingHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid tracing header %v, expected key=value", entry)
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers, nil
+}
+
+// setupTracing starts exporting spans to the configured exporter, the returned
+// function exports the remaining spans and is a no-op when tracing is disabled
+func setupTracing() (func(), error) {
+	var exporter tracing.Exporter
+	switch config.GlobalConfig.TracingExporter {
+	case "":
+		return func() {}, nil
+	case "otlp":
+		if config.GlobalConfig.TracingEndpoint == "" {
+			return nil, fmt.Errorf("tracingEndpoint is required for the otlp tracing exporter")
+		}
+		headers, err := parseTracingHeaders(config.GlobalConfig.TracingHeaders)
+		if err != nil {
+			return nil, err
+		}
+		exporter = tracing.NewOTLPExporter(config.GlobalConfig.TracingEndpoint, headers)
+	case "file":
+		if config.GlobalConfig.TracingEndpoint == "" {
+			return nil, fmt.Errorf("tracingEndpoint is required for the file tracing exporter")
+		}
+		fileExporter, err := tracing.NewFileExporter(config.GlobalConfig.TracingEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		exporter = fileExporter
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %v, expected otlp or file", config.GlobalConfig.TracingExporter)
+	}
+	serviceName := config.GlobalConfig.TracingServiceName
+	if serviceName == "" {
+		serviceName = "dkgnode"
+		if config.GlobalConfig.PublicURL != "" {
+			serviceName += " " + config.GlobalConfig.PublicURL
+		}
+	}
+	tracer := tracing.NewTracer(tracing.Config{
+		Exporter:    exporter,
+		ServiceName: serviceName,
+		OnError: func(err error) {
+			logging.WithError(err).Warn("could not export spans")
+		},
+	})
+	tracing.SetTracer(tracer)
+	logging.WithFields(logging.Fields{
+		"exporter": config.GlobalConfig.TracingExporter,
+		"endpoint": config.GlobalConfig.TracingEndpoint,
+	}).Info("tracing enabled")
+	return func() {
+		tracing.SetTracer(nil)
+		if err := tracer.Shutdown(); err != nil {
+			logging.WithError(err).Error("could not shut down tracing")
+		}
+	}, nil
+}
+
+// startChildSpan starts a span only if ctx is already traced, so that the many calls
+// between services made outside of a traced request do not each start a trace
+func startChildSpan(ctx context.Context, name string, kind tracing.SpanKind) (context.Context, *tracing.Span) {
+	if tracing.SpanFromContext(ctx) == nil {
+		return ctx, nil
+	}
+	return tracing.Start(ctx, name, kind)
+}
+
+// withSpanOf returns ctx carrying the span of traced, for handlers whose calls keep the
+// cancellation of their service but are recorded as children of the call being handled
+func withSpanOf(ctx context.Context, traced context.Context) context.Context {
+	return tracing.ContextWithSpan(ctx, tracing.SpanFromContext(traced))
+}
+
+// startRemoteSpan starts a span whose parent is given by traceparent, only if it is set
+func startRemoteSpan(ctx context.Context, name string, kind tracing.SpanKind, traceparent string) (context.Context, *tracing.Span) {
+	if traceparent == "" {
+		return ctx, nil
+	}
+	return tracing.StartFromTraceparent(ctx, name, kind, traceparent)
+}
+
+// instanceTraceKey returns the protocol instance a BFT transaction belongs to, "" if none
+func instanceTraceKey(bftTx interface{}) string {
+	switch msg := bftTx.(type) {
+	case keygennofsm.KeygenMessage:
+		return "keygen:" + string(msg.KeygenID)
+	case pss.PSSMessage:
+		return "pss:" + string(msg.PSSID)
+	case mapping.MappingMessage:
+		return "mapping:" + string(msg.MappingID)
+	}
+	return ""
+}
+
+// payloadTraceKey returns the protocol instance of the message in a p2p payload, "" if none
+func payloadTraceKey(payload []byte) string {
+	var ids struct {
+		KeygenID  keygennofsm.KeygenID `json:"keygenid"`
+		PSSID     pss.PSSID            `json:"pssid"`
+		MappingID mapping.MappingID    `json:"mappingID"`
+	}
+	if err := bijson.Unmarshal(payload, &ids); err != nil {
+		return ""
+	}
+	switch {
+	case ids.KeygenID != "":
+		return instanceTraceKey(keygennofsm.KeygenMessage{KeygenID: ids.KeygenID})
+	case ids.PSSID != "":
+		return instanceTraceKey(pss.PSSMessage{PSSID: ids.PSSID})
+	case ids.MappingID != "":
+		return instanceTraceKey(mapping.MappingMessage{MappingID: ids.MappingID})
+	}
+	return ""
+}
+
+// startInstanceSpan starts a span in the trace of the protocol instance key, which every
+// node derives on its own so that the spans of all nodes in a keygen, PSS or mapping
+// instance are in one trace. Messages without an instance start a new trace.
+func startInstanceSpan(name string, kind tracing.SpanKind, key string) *tracing.Span {
+	var parent tracing.SpanContext
+	if key != "" {
+		parent = tracing.InstanceContext(key)
+	}
+	_, span := tracing.StartWithParent(context.Background(), name, kind, parent)
+	if key != "" {
+		span.SetAttribute("instance", key)
+	}
+	return span
+}
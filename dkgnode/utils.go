@@ -19,6 +19,7 @@ import (
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/secp256k1"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/secret"
 )
 
 func init() {
@@ -51,7 +52,7 @@ func stringify(i interface{}) string {
 	if ok {
 		return str
 	}
-	byt, err := bijson.Marshal(i)
+	byt, err := bijson.Marshal(secret.Redact(i))
 	if err != nil {
 		logging.WithError(err).Error("Could not bijsonmarshal")
 	}
@@ -2,7 +2,6 @@ package dkgnode
 
 import (
 	"context"
-	"fmt"
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/telemetry"
@@ -63,47 +62,32 @@ func (v *VerifierService) OnStop() error {
 	return nil
 }
 
-func (v *VerifierService) Call(method string, args ...interface{}) (interface{}, error) {
+func (v *VerifierService) Call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Generic.TotalServiceCalls, pcmn.TelemetryConstants.Verifier.Prefix)
 
-	switch method {
-	// Verify(rawMessage *bijson.RawMessage) (valid bool, verifierID string, timeIssued time.Time, err error)
-	case "verify":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.VerifyCounter, pcmn.TelemetryConstants.Verifier.Prefix)
-
-		// this has a custom marshaller
-		var args0 bijson.RawMessage
-		_ = castOrUnmarshal(args[0], &args0)
-		rs := new(struct {
-			Valid      bool
-			VerifierID string
-		})
-		token := args0
-		valid, verifierID, err := v.defaultVerifier.Verify(&token)
-		rs.Valid = valid
-		rs.VerifierID = verifierID
-		return *rs, err
-	// Lookup(verifierIdentifier string) (verifier auth.Verifier, err error)
-	case "clean_token":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.CleanTokenCounter, pcmn.TelemetryConstants.Verifier.Prefix)
-
-		var args0, args1 string
-		_ = castOrUnmarshal(args[0], &args0)
-		_ = castOrUnmarshal(args[1], &args1)
-
-		verifier, err := v.defaultVerifier.Lookup(args0)
-		if err != nil {
-			return nil, err
-		}
-		return verifier.CleanToken(args1), nil
-	// ListVerifiers() (verifiers []string)
-	case "list_verifiers":
-		telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.ListVerifierCounter, pcmn.TelemetryConstants.Verifier.Prefix)
-
-		verifiers := v.defaultVerifier.ListVerifiers()
-		return verifiers, nil
+	return dispatchVerifier(ctx, v, method, args)
+}
+
+func (v *VerifierService) handleVerify(ctx context.Context, rawMessage *bijson.RawMessage) (bool, string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.VerifyCounter, pcmn.TelemetryConstants.Verifier.Prefix)
+
+	return v.defaultVerifier.Verify(rawMessage)
+}
+
+func (v *VerifierService) handleCleanToken(ctx context.Context, verifierIdentifier string, idtoken string) (string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.CleanTokenCounter, pcmn.TelemetryConstants.Verifier.Prefix)
+
+	verifier, err := v.defaultVerifier.Lookup(verifierIdentifier)
+	if err != nil {
+		return "", err
 	}
-	return nil, fmt.Errorf("verifier service method %v not found", method)
+	return verifier.CleanToken(idtoken), nil
+}
+
+func (v *VerifierService) handleListVerifiers(ctx context.Context) ([]string, error) {
+	telemetry.IncrementCounter(pcmn.TelemetryConstants.Verifier.ListVerifierCounter, pcmn.TelemetryConstants.Verifier.Prefix)
+
+	return v.defaultVerifier.ListVerifiers(), nil
 }
 
 func (v *VerifierService) SetBaseService(bs *BaseService) {
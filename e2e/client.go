@@ -0,0 +1,281 @@
+// Package e2e logs in to a running cluster the way a client does, requesting commitments and
+// shares from its nodes and reconstructing keys from them.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/dkgnode"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+// commitmentPrefix is the message prefix nodes sign commitments with
+const commitmentPrefix = "mug00"
+
+// Node is a node of the cluster. Index is its index in the current epoch, which its shares
+// are evaluated at, URL its base URL and Address the address of its key.
+type Node struct {
+	Index   int
+	URL     string
+	Address ethCommon.Address
+}
+
+// Key is a key reconstructed from the shares of the nodes
+type Key struct {
+	Index      big.Int
+	PublicKey  common.Point
+	PrivateKey *big.Int
+}
+
+// Client talks to the nodes of a cluster whose epoch has threshold k
+type Client struct {
+	nodes []Node
+	k     int
+	http  *http.Client
+}
+
+// NewClient returns a client of nodes, which need k shares to reconstruct a key
+func NewClient(nodes []Node, k int) *Client {
+	return &Client{nodes: nodes, k: k, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type jrpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type jrpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+func (e *jrpcError) Error() string {
+	return fmt.Sprintf("%v (%v): %v", e.Message, e.Code, e.Data)
+}
+
+type jrpcResponse struct {
+	Result bijson.RawMessage `json:"result"`
+	Error  *jrpcError        `json:"error"`
+}
+
+// call calls method on the JRPC endpoint of node and parses its result into result
+func (c *Client) call(ctx context.Context, node Node, method string, params interface{}, result interface{}) error {
+	body, err := bijson.Marshal(jrpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", node.URL+"/jrpc", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res jrpcResponse
+	if err := bijson.Unmarshal(respBody, &res); err != nil {
+		return fmt.Errorf("could not parse response of node %v to %v: %v", node.Index, method, err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("node %v failed %v: %v", node.Index, method, res.Error)
+	}
+	return bijson.Unmarshal(res.Result, result)
+}
+
+// KeyAssign assigns a key to verifierID, or returns the key it was assigned before
+func (c *Client) KeyAssign(ctx context.Context, verifier, verifierID string) (dkgnode.KeyAssignItem, error) {
+	var errs []string
+	for _, node := range c.nodes {
+		var res dkgnode.KeyAssignResult
+		err := c.call(ctx, node, dkgnode.KeyAssignMethod, dkgnode.KeyAssignParams{Verifier: verifier, VerifierID: verifierID}, &res)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if len(res.Keys) == 0 {
+			errs = append(errs, fmt.Sprintf("node %v assigned no key", node.Index))
+			continue
+		}
+		return res.Keys[0], nil
+	}
+	return dkgnode.KeyAssignItem{}, fmt.Errorf("could not assign a key: %v", strings.Join(errs, "; "))
+}
+
+// shareRequestItem is a login with a verifier that identifies users by the id field, such as
+// the test verifier
+type shareRequestItem struct {
+	IDToken            string                  `json:"idtoken"`
+	ID                 string                  `json:"id"`
+	NodeSignatures     []dkgnode.NodeSignature `json:"nodesignatures"`
+	VerifierIdentifier string                  `json:"verifieridentifier"`
+}
+
+// shareRequestKey is a key returned by ShareRequest. Share is the hex encoded ciphertext of
+// the share when Metadata is set.
+type shareRequestKey struct {
+	dkgnode.KeyAssignmentPublic
+	Share    []byte
+	Metadata eciesMetadata
+}
+
+type shareRequestResult struct {
+	Keys []shareRequestKey `json:"keys"`
+}
+
+// Login logs in as verifierID with idToken, and reconstructs the keys of verifierID from the
+// shares of the nodes. Every key is checked against its public key.
+func (c *Client) Login(ctx context.Context, verifier, verifierID, idToken string) ([]Key, error) {
+	tmpKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	commitment := dkgnode.CommitmentRequestParams{
+		MessagePrefix:      commitmentPrefix,
+		TokenCommitment:    hex.EncodeToString(secp256k1.Keccak256([]byte(idToken))),
+		TempPubX:           tmpKey.X.Text(16),
+		TempPubY:           tmpKey.Y.Text(16),
+		VerifierIdentifier: verifier,
+	}
+	var signatures []dkgnode.NodeSignature
+	var committed []Node
+	var errs []string
+	for _, node := range c.nodes {
+		var signature dkgnode.NodeSignature
+		if err := c.call(ctx, node, dkgnode.CommitmentRequestMethod, commitment, &signature); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := verifySignature(node, commitment, signature); err != nil {
+			errs = append(errs, fmt.Sprintf("node %v: %v", node.Index, err))
+			continue
+		}
+		signatures = append(signatures, signature)
+		committed = append(committed, node)
+	}
+	if len(signatures) < c.k {
+		return nil, fmt.Errorf("got %v commitment signatures, %v needed: %v", len(signatures), c.k, strings.Join(errs, "; "))
+	}
+
+	item, err := bijson.Marshal(shareRequestItem{
+		IDToken:            idToken,
+		ID:                 verifierID,
+		NodeSignatures:     signatures,
+		VerifierIdentifier: verifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+	params := dkgnode.ShareRequestParams{Item: []bijson.RawMessage{item}}
+	shares := make(map[string][]pcmn.PrimaryShare)
+	public := make(map[string]dkgnode.KeyAssignmentPublic)
+	for _, node := range committed {
+		var res shareRequestResult
+		if err := c.call(ctx, node, dkgnode.ShareRequestMethod, params, &res); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, key := range res.Keys {
+			value, err := shareValue(tmpKey, key)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("node %v: %v", node.Index, err))
+				continue
+			}
+			index := key.Index.Text(16)
+			shares[index] = append(shares[index], pcmn.PrimaryShare{Index: node.Index, Value: *value})
+			public[index] = key.KeyAssignmentPublic
+		}
+	}
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("got no shares: %v", strings.Join(errs, "; "))
+	}
+
+	var keys []Key
+	for index, indexShares := range shares {
+		if len(indexShares) < c.k {
+			return nil, fmt.Errorf("got %v shares of key %v, %v needed: %v", len(indexShares), index, c.k, strings.Join(errs, "; "))
+		}
+		key, err := reconstruct(public[index], indexShares)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// verifySignature checks that signature is the signature of node over commitment
+func verifySignature(node Node, commitment dkgnode.CommitmentRequestParams, signature dkgnode.NodeSignature) error {
+	var data dkgnode.CommitmentRequestResultData
+	if ok, err := data.FromString(signature.Data); !ok || err != nil {
+		return fmt.Errorf("could not parse signed commitment %q: %v", signature.Data, err)
+	}
+	if data.MessagePrefix != commitment.MessagePrefix || data.TokenCommitment != commitment.TokenCommitment ||
+		data.TempPubX != commitment.TempPubX || data.TempPubY != commitment.TempPubY ||
+		data.VerifierIdentifier != commitment.VerifierIdentifier {
+		return fmt.Errorf("signed commitment %q is not the requested one", signature.Data)
+	}
+	sig, err := hex.DecodeString(signature.Signature)
+	if err != nil || len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		return fmt.Errorf("signature %q is not a hex encoded signature with V as 27 or 28", signature.Signature)
+	}
+	sig[64] -= 27
+	pubKey, err := ethCrypto.SigToPub(secp256k1.Keccak256([]byte(signature.Data)), sig)
+	if err != nil {
+		return err
+	}
+	if pubKey.X.Text(16) != signature.NodePubKeyX || pubKey.Y.Text(16) != signature.NodePubKeyY {
+		return errors.New("signature is not signed by the key it lists")
+	}
+	if address := ethCrypto.PubkeyToAddress(*pubKey); address != node.Address {
+		return fmt.Errorf("signature is signed by %v instead of %v", address.Hex(), node.Address.Hex())
+	}
+	return nil
+}
+
+// shareValue returns the share of key, decrypting it with tmpKey if it is encrypted
+func shareValue(tmpKey *ecdsa.PrivateKey, key shareRequestKey) (*big.Int, error) {
+	share := key.Share
+	if key.Metadata.Mac != "" {
+		decrypted, err := decrypt(tmpKey, string(key.Share), key.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("could not decrypt share of key %v: %v", key.Index.Text(16), err)
+		}
+		share = decrypted
+	}
+	return new(big.Int).SetBytes(share), nil
+}
+
+// reconstruct interpolates shares at 0 and checks the private key against the public key of key
+func reconstruct(key dkgnode.KeyAssignmentPublic, shares []pcmn.PrimaryShare) (Key, error) {
+	priv := pvss.LagrangeScalar(shares, 0)
+	x, y := secp256k1.Curve.ScalarBaseMult(priv.Bytes())
+	if x.Cmp(&key.PublicKey.X) != 0 || y.Cmp(&key.PublicKey.Y) != 0 {
+		return Key{}, errors.New("reconstructed key " + key.Index.Text(16) + " does not match its public key")
+	}
+	return Key{Index: key.Index, PublicKey: key.PublicKey, PrivateKey: priv}, nil
+}
@@ -0,0 +1,245 @@
+package e2e
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tronCrypto "github.com/TRON-US/go-eccrypto"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/dkgnode"
+	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+const (
+	// testVerifier is registered by nodes in debug mode, and accepts testIDToken for any id
+	testVerifier = "test"
+	testIDToken  = "blublu"
+)
+
+func TestDecrypt(t *testing.T) {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("share of a key")
+	pubKeyHex := "04" + fmt.Sprintf("%064s", key.X.Text(16)) + fmt.Sprintf("%064s", key.Y.Text(16))
+	ciphertext, metadata, err := tronCrypto.Encrypt(pubKeyHex, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the metadata reaches clients as the JSON of a ShareRequest result
+	byt, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed eciesMetadata
+	if err := json.Unmarshal(byt, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := decrypt(key, ciphertext, parsed)
+	if err != nil || string(decrypted) != string(msg) {
+		t.Fatalf("expected %q, got %q, %v", msg, decrypted, err)
+	}
+
+	parsed.Mac = hex.EncodeToString(make([]byte, 32))
+	if _, err := decrypt(key, ciphertext, parsed); err == nil {
+		t.Fatal("expected a share with a wrong mac to be refused")
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	priv := pvss.RandomBigInt()
+	poly := pvss.RandomPoly(*priv, 3)
+	var shares []pcmn.PrimaryShare
+	for i := 1; i <= 5; i++ {
+		shares = append(shares, pcmn.PrimaryShare{Index: i, Value: *pvss.PolyEval(*poly, *big.NewInt(int64(i)))})
+	}
+	public := dkgnode.KeyAssignmentPublic{
+		Index:     *big.NewInt(1),
+		PublicKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(priv.Bytes())),
+	}
+	key, err := reconstruct(public, shares[2:])
+	if err != nil || key.PrivateKey.Cmp(priv) != 0 {
+		t.Fatalf("expected the key to be reconstructed from 3 of 5 shares, got %v", err)
+	}
+	shares[4].Value = *big.NewInt(1)
+	if _, err := reconstruct(public, shares[2:]); err == nil {
+		t.Fatal("expected a key reconstructed from a wrong share to be refused")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := signer.NewMemorySigner(privKey)
+	node := Node{Index: 1, Address: s.Address()}
+	commitment := dkgnode.CommitmentRequestParams{
+		MessagePrefix:      commitmentPrefix,
+		TokenCommitment:    "commitment",
+		TempPubX:           "1",
+		TempPubY:           "2",
+		VerifierIdentifier: testVerifier,
+	}
+	sign := func(data string) dkgnode.NodeSignature {
+		sig, err := signer.SignData(s, []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig[64] += 27
+		return dkgnode.NodeSignature{
+			Signature:   hex.EncodeToString(sig),
+			Data:        data,
+			NodePubKeyX: privKey.X.Text(16),
+			NodePubKeyY: privKey.Y.Text(16),
+		}
+	}
+	signed := dkgnode.CommitmentRequestResultData{
+		MessagePrefix:      commitmentPrefix,
+		TokenCommitment:    "commitment",
+		TempPubX:           "1",
+		TempPubY:           "2",
+		VerifierIdentifier: testVerifier,
+		TimeSigned:         "1577836800",
+	}
+	data := signed.ToString()
+	if err := verifySignature(node, commitment, sign(data)); err != nil {
+		t.Fatalf("expected the signature of the node to verify, got %v", err)
+	}
+
+	signed.TokenCommitment = "other"
+	other := signed.ToString()
+	tampered := sign(data)
+	tampered.Data = other
+	otherKey, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		node      Node
+		signature dkgnode.NodeSignature
+	}{
+		{name: "other commitment", node: node, signature: sign(other)},
+		{name: "tampered data", node: node, signature: tampered},
+		{name: "other node", node: Node{Index: 2, Address: ethCrypto.PubkeyToAddress(otherKey.PublicKey)}, signature: sign(data)},
+		{name: "unparsable data", node: node, signature: sign("data")},
+	}
+	for _, test := range tests {
+		if err := verifySignature(test.node, commitment, test.signature); err == nil {
+			t.Errorf("%v: expected the signature to be refused", test.name)
+		}
+	}
+}
+
+// TestLoginAcrossEpochs runs a devnet with the dkgnode binary in DKGNODE_BINARY, or one built
+// from cmd/dkgnode, logs in with the test verifier, and logs in again with the nodes of the
+// next epoch once the keys were handed over to them
+func TestLoginAcrossEpochs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a devnet")
+	}
+	dir, err := ioutil.TempDir("", "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	binary := os.Getenv("DKGNODE_BINARY")
+	if binary == "" {
+		binary = filepath.Join(dir, "dkgnode")
+		build := exec.Command("go", "build", "-o", binary, "github.com/torusresearch/torus-node/cmd/dkgnode")
+		if out, err := build.CombinedOutput(); err != nil {
+			t.Fatalf("could not build dkgnode: %v\n%s", err, out)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+
+	devnet, err := dkgnode.StartDevnet(dkgnode.DevnetOptions{
+		Dir:           dir,
+		Nodes:         5,
+		KeyBuffer:     5,
+		EncryptShares: true,
+		BasePort:      17000,
+		Binary:        binary,
+		LogLevel:      "info",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devnet.Stop()
+	if err := devnet.AwaitReady(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	verifierID := fmt.Sprintf("e2e-%d", time.Now().UnixNano())
+	client := clientOf(devnet, 1)
+	assigned, err := client.KeyAssign(ctx, testVerifier, verifierID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := client.Login(ctx, testVerifier, verifierID, testIDToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkKeys(t, assigned, keys)
+	if _, err := client.Login(ctx, testVerifier, verifierID, "wrong token"); err == nil {
+		t.Fatal("expected a login with a wrong token to fail")
+	}
+
+	epoch, err := devnet.NextEpoch(5, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := devnet.AwaitReady(ctx, epoch); err != nil {
+		t.Fatal(err)
+	}
+	// the nodes of the next epoch serve logins once they hold the shares of the keys
+	client = clientOf(devnet, epoch)
+	for {
+		keys, err = client.Login(ctx, testVerifier, verifierID, testIDToken)
+		if err == nil {
+			break
+		}
+		t.Logf("login with epoch %v failed, retrying: %v", epoch, err)
+		select {
+		case <-ctx.Done():
+			t.Fatalf("could not log in with epoch %v: %v", epoch, err)
+		case <-time.After(10 * time.Second):
+		}
+	}
+	checkKeys(t, assigned, keys)
+}
+
+func clientOf(devnet *dkgnode.Devnet, epoch int) *Client {
+	var nodes []Node
+	for _, node := range devnet.Nodes(epoch) {
+		nodes = append(nodes, Node{Index: node.Index, URL: node.URL, Address: node.Address})
+	}
+	return NewClient(nodes, devnet.K(epoch))
+}
+
+func checkKeys(t *testing.T, assigned dkgnode.KeyAssignItem, keys []Key) {
+	t.Helper()
+	if len(keys) != 1 {
+		t.Fatalf("expected the assigned key, got %v keys", len(keys))
+	}
+	if keys[0].PublicKey.X.Cmp(&assigned.PubKeyX) != 0 || keys[0].PublicKey.Y.Cmp(&assigned.PubKeyY) != 0 {
+		t.Fatalf("expected the public key %v, %v assigned by KeyAssign, got %v, %v",
+			assigned.PubKeyX.Text(16), assigned.PubKeyY.Text(16), keys[0].PublicKey.X.Text(16), keys[0].PublicKey.Y.Text(16))
+	}
+}
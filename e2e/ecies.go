@@ -0,0 +1,81 @@
+package e2e
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// eciesMetadata is the metadata nodes return with a share they encrypted for the key of the
+// client when EncryptShares is on. All fields are hex encoded.
+type eciesMetadata struct {
+	Iv             string `json:"iv"`
+	EphemPublicKey string `json:"ephemPublicKey"`
+	Mac            string `json:"mac"`
+	Mode           string `json:"mode"`
+}
+
+// decrypt decrypts the hex encoded ciphertext of a share with priv, the way eccrypto clients do:
+// the ECDH secret with the ephemeral key is hashed with sha512 into an AES-256-CBC key and an
+// HMAC-SHA256 key over the iv, the ephemeral key and the ciphertext
+func decrypt(priv *ecdsa.PrivateKey, ciphertext string, metadata eciesMetadata) ([]byte, error) {
+	ct, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %v", err)
+	}
+	iv, err := hex.DecodeString(metadata.Iv)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid iv")
+	}
+	mac, err := hex.DecodeString(metadata.Mac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac: %v", err)
+	}
+	ephemPub, err := hex.DecodeString(metadata.EphemPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %v", err)
+	}
+	ephem, err := ethCrypto.UnmarshalPubkey(ephemPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %v", err)
+	}
+	if len(ct) == 0 || len(ct)%aes.BlockSize != 0 {
+		return nil, errors.New("ciphertext is not a whole number of blocks")
+	}
+
+	sx, _ := priv.Curve.ScalarMult(ephem.X, ephem.Y, priv.D.Bytes())
+	shared := make([]byte, 32)
+	sxBytes := sx.Bytes()
+	copy(shared[32-len(sxBytes):], sxBytes)
+	hash := sha512.Sum512(shared)
+	encKey, macKey := hash[:32], hash[32:]
+
+	h := hmac.New(sha256.New, macKey)
+	h.Write(iv)
+	h.Write(ephemPub)
+	h.Write(ct)
+	if !hmac.Equal(h.Sum(nil), mac) {
+		return nil, errors.New("invalid mac")
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ct)
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid padding")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
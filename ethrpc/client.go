@@ -0,0 +1,614 @@
+// Package ethrpc is an Ethereum client backed by several RPC endpoints. Calls fail over to the
+// next healthy endpoint, and contract calls and log queries can require a quorum of endpoints to
+// agree, so that a single flaky or compromised provider can neither stall the node nor feed it a
+// false node list.
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHealthCheckInterval is how often the head of every endpoint is read
+	DefaultHealthCheckInterval = 15 * time.Second
+	// DefaultMaxBlockLag is how many blocks an endpoint may be behind the others before it is unhealthy
+	DefaultMaxBlockLag = 10
+)
+
+// ErrNoQuorum is returned by contract calls that not enough endpoints agreed on
+var ErrNoQuorum = errors.New("not enough ethereum endpoints agreed on the result")
+
+// ErrNoEndpoints is returned when no endpoint could be reached
+var ErrNoEndpoints = errors.New("no ethereum endpoint is available")
+
+// Backend is what the client uses of each endpoint, it is implemented by *ethclient.Client
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Endpoint is an RPC endpoint, dialled when it is first used and again after it could not be reached
+type Endpoint struct {
+	// Name identifies the endpoint in logs and statuses, it must not contain credentials
+	Name string
+	Dial func(ctx context.Context) (Backend, error)
+}
+
+// Config configures a Client
+type Config struct {
+	// Quorum is the number of endpoints that must return the same result for a contract call,
+	// filtered logs or the header of a given block, 0 or 1 reads from a single endpoint.
+	// Subscriptions and all other calls always use a single endpoint.
+	Quorum int
+	// HealthCheckInterval defaults to DefaultHealthCheckInterval
+	HealthCheckInterval time.Duration
+	// MaxBlockLag defaults to DefaultMaxBlockLag
+	MaxBlockLag uint64
+}
+
+// EndpointStatus is the health of an endpoint
+type EndpointStatus struct {
+	Endpoint      string     `json:"endpoint"`
+	Healthy       bool       `json:"healthy"`
+	Head          uint64     `json:"head"`
+	LastChecked   *time.Time `json:"lastChecked,omitempty"`
+	Failures      int        `json:"failures"`
+	Disagreements int        `json:"disagreements"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+type endpoint struct {
+	Endpoint
+
+	lock          sync.Mutex
+	backend       Backend
+	healthy       bool
+	head          uint64
+	lastChecked   time.Time
+	failures      int
+	disagreements int
+	lastErr       error
+}
+
+// connect returns the backend of the endpoint, dialling it if it is not connected
+func (e *endpoint) connect(ctx context.Context) (Backend, error) {
+	e.lock.Lock()
+	backend := e.backend
+	e.lock.Unlock()
+	if backend != nil {
+		return backend, nil
+	}
+	// dial without holding the lock, so that dialling an endpoint that is down does not block its status
+	backend, err := e.Dial(ctx)
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err != nil {
+		e.healthy = false
+		e.failures++
+		e.lastErr = err
+		return nil, err
+	}
+	if e.backend == nil {
+		e.backend = backend
+	}
+	return e.backend, nil
+}
+
+func (e *endpoint) isHealthy() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.healthy
+}
+
+// fail marks the endpoint unhealthy. If err means it could not be reached, its backend is
+// dropped, so that the endpoint is dialled again instead of reusing a broken connection.
+func (e *endpoint) fail(err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.healthy {
+		logging.WithField("endpoint", e.Name).WithError(err).Warn("ethereum endpoint failed, failing over")
+	}
+	e.healthy = false
+	e.failures++
+	e.lastErr = err
+	if isTransportError(err) && e.backend != nil {
+		if closer, ok := e.backend.(interface{ Close() }); ok {
+			closer.Close()
+		}
+		e.backend = nil
+	}
+}
+
+func (e *endpoint) disagreed() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.disagreements++
+}
+
+// Client is an Ethereum client that spreads calls over endpoints. Endpoints are preferred in the
+// order they are configured, healthy ones first. Reads move on to the next endpoint on any
+// error, while transactions are only sent again to another endpoint if sending failed to reach
+// the endpoint, as the other endpoints would reject them for the same reason.
+type Client struct {
+	endpoints           []*endpoint
+	quorum              int
+	healthCheckInterval time.Duration
+	maxBlockLag         uint64
+
+	lock    sync.Mutex
+	current *endpoint
+}
+
+// NewClient returns a client of endpoints. Endpoints are considered healthy until they fail.
+func NewClient(cfg Config, endpoints ...Endpoint) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no ethereum endpoints configured")
+	}
+	if cfg.Quorum > len(endpoints) {
+		return nil, fmt.Errorf("a quorum of %d needs at least as many ethereum endpoints, only %d configured", cfg.Quorum, len(endpoints))
+	}
+	c := &Client{
+		quorum:              cfg.Quorum,
+		healthCheckInterval: cfg.HealthCheckInterval,
+		maxBlockLag:         cfg.MaxBlockLag,
+	}
+	if c.healthCheckInterval == 0 {
+		c.healthCheckInterval = DefaultHealthCheckInterval
+	}
+	if c.maxBlockLag == 0 {
+		c.maxBlockLag = DefaultMaxBlockLag
+	}
+	for _, e := range endpoints {
+		c.endpoints = append(c.endpoints, &endpoint{Endpoint: e, healthy: true})
+	}
+	return c, nil
+}
+
+// Dial returns a client of the endpoints at urls, dialled with ethclient
+func Dial(urls []string, cfg Config) (*Client, error) {
+	var endpoints []Endpoint
+	for _, rawurl := range urls {
+		rawurl := rawurl
+		endpoints = append(endpoints, Endpoint{
+			Name: redact(rawurl),
+			Dial: func(ctx context.Context) (Backend, error) {
+				return ethclient.Dial(rawurl)
+			},
+		})
+	}
+	return NewClient(cfg, endpoints...)
+}
+
+// SplitURLs splits a comma separated list of endpoint urls
+func SplitURLs(urls string) []string {
+	var split []string
+	for _, rawurl := range strings.Split(urls, ",") {
+		if rawurl = strings.TrimSpace(rawurl); rawurl != "" {
+			split = append(split, rawurl)
+		}
+	}
+	return split
+}
+
+// redact drops everything but the scheme and host of rawurl, as providers keep API keys in the
+// path or the credentials of their urls
+func redact(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return "endpoint"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// isTransportError returns true if err means the endpoint could not be reached, rather than that
+// it handled and refused the request
+func isTransportError(err error) bool {
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// Endpoints returns the names of the endpoints
+func (c *Client) Endpoints() []string {
+	var names []string
+	for _, e := range c.endpoints {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// Status returns the health of every endpoint in the order they are preferred in
+func (c *Client) Status() []EndpointStatus {
+	var statuses []EndpointStatus
+	for _, e := range c.endpoints {
+		e.lock.Lock()
+		status := EndpointStatus{
+			Endpoint:      e.Name,
+			Healthy:       e.healthy,
+			Head:          e.head,
+			Failures:      e.failures,
+			Disagreements: e.disagreements,
+		}
+		if !e.lastChecked.IsZero() {
+			lastChecked := e.lastChecked
+			status.LastChecked = &lastChecked
+		}
+		if e.lastErr != nil {
+			status.LastError = e.lastErr.Error()
+		}
+		e.lock.Unlock()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// ordered returns the endpoints in the order they are tried in, healthy ones first
+func (c *Client) ordered() []*endpoint {
+	var healthy, unhealthy []*endpoint
+	for _, e := range c.endpoints {
+		if e.isHealthy() {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// use records that calls are served by e, logging when it changes
+func (c *Client) use(e *endpoint) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.current != e {
+		if c.current != nil {
+			logging.WithFields(logging.Fields{
+				"from": c.current.Name,
+				"to":   e.Name,
+			}).Info("switched ethereum endpoint")
+		}
+		c.current = e
+	}
+}
+
+// do calls f with the backend of each endpoint until it succeeds. If failover is false it only
+// moves on to the next endpoint when the previous one could not be reached.
+func (c *Client) do(ctx context.Context, failover bool, f func(Backend) error) error {
+	err := ErrNoEndpoints
+	for _, e := range c.ordered() {
+		var backend Backend
+		backend, err = e.connect(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		err = f(backend)
+		if err == nil {
+			c.use(e)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		transport := isTransportError(err)
+		if transport {
+			e.fail(err)
+		}
+		if !transport && !failover {
+			return err
+		}
+	}
+	return err
+}
+
+// agree calls f with the backend of every endpoint until quorum of them returned results with the
+// same key and returns that result. Endpoints that returned another result by then are counted as
+// disagreeing. what names the call in logs.
+func (c *Client) agree(ctx context.Context, what string, f func(Backend) (key string, result interface{}, err error)) (interface{}, error) {
+	type response struct {
+		endpoint *endpoint
+		key      string
+		result   interface{}
+		err      error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	responses := make(chan response, len(c.endpoints))
+	for _, e := range c.endpoints {
+		go func(e *endpoint) {
+			backend, err := e.connect(ctx)
+			if err != nil {
+				responses <- response{endpoint: e, err: err}
+				return
+			}
+			key, result, err := f(backend)
+			if err != nil && ctx.Err() == nil && isTransportError(err) {
+				e.fail(err)
+			}
+			responses <- response{endpoint: e, key: key, result: result, err: err}
+		}(e)
+	}
+
+	agreed := make(map[string][]*endpoint)
+	var lastErr error
+	for range c.endpoints {
+		r := <-responses
+		if r.err != nil {
+			lastErr = r.err
+			continue
+		}
+		agreed[r.key] = append(agreed[r.key], r.endpoint)
+		if len(agreed[r.key]) < c.quorum {
+			continue
+		}
+		for other, endpoints := range agreed {
+			if other == r.key {
+				continue
+			}
+			for _, e := range endpoints {
+				e.disagreed()
+				logging.WithField("endpoint", e.Name).Warn("ethereum endpoint disagreed with the quorum on " + what)
+			}
+		}
+		return r.result, nil
+	}
+	if len(agreed) > 1 {
+		logging.WithField("results", len(agreed)).Warn("ethereum endpoints returned different results for " + what)
+	}
+	if len(agreed) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrNoQuorum
+}
+
+// CallContract calls a contract on a single endpoint, or on every endpoint until Quorum of them
+// returned the same result
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.quorum <= 1 {
+		var result []byte
+		err := c.do(ctx, true, func(b Backend) (err error) {
+			result, err = b.CallContract(ctx, call, blockNumber)
+			return err
+		})
+		return result, err
+	}
+	result, err := c.agree(ctx, "a contract call", func(b Backend) (string, interface{}, error) {
+		result, err := b.CallContract(ctx, call, blockNumber)
+		return string(result), result, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+func (c *Client) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	var code []byte
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		code, err = b.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	var code []byte
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		code, err = b.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	var nonce uint64
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		nonce, err = b.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var price *big.Int
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		price, err = b.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		gas, err = b.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction sends tx to the first endpoint that can be reached. Sending a signed
+// transaction to more than one endpoint is safe, they all have the same hash.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.do(ctx, false, func(b Backend) error {
+		return b.SendTransaction(ctx, tx)
+	})
+}
+
+// FilterLogs reads logs from a single endpoint, or from every endpoint until Quorum of them returned
+// the same logs. Queries that should reach a quorum end at a block every endpoint has, as endpoints
+// whose head differs return different logs up to the latest block.
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if c.quorum <= 1 {
+		var logs []types.Log
+		err := c.do(ctx, true, func(b Backend) (err error) {
+			logs, err = b.FilterLogs(ctx, query)
+			return err
+		})
+		return logs, err
+	}
+	logs, err := c.agree(ctx, "filtered logs", func(b Backend) (string, interface{}, error) {
+		logs, err := b.FilterLogs(ctx, query)
+		if err != nil {
+			return "", nil, err
+		}
+		key, err := json.Marshal(logs)
+		return string(key), logs, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs.([]types.Log), nil
+}
+
+// SubscribeFilterLogs subscribes to the logs of the first endpoint that can push them, a single
+// endpoint cannot be checked against a quorum. The subscription ends with an error if that endpoint fails.
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		sub, err = b.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return sub, err
+}
+
+// HeaderByNumber reads the header of a block from a single endpoint, or from every endpoint until
+// Quorum of them returned the same header. The latest header, with number nil, is always read from
+// a single endpoint as the heads of endpoints differ while blocks propagate.
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if c.quorum <= 1 || number == nil {
+		var header *types.Header
+		err := c.do(ctx, true, func(b Backend) (err error) {
+			header, err = b.HeaderByNumber(ctx, number)
+			return err
+		})
+		return header, err
+	}
+	header, err := c.agree(ctx, "a block header", func(b Backend) (string, interface{}, error) {
+		header, err := b.HeaderByNumber(ctx, number)
+		if err != nil {
+			return "", nil, err
+		}
+		return header.Hash().Hex(), header, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header.(*types.Header), nil
+}
+
+// TransactionReceipt asks every endpoint until one has the receipt, as endpoints that lag
+// behind may not have seen the block of the transaction yet
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		receipt, err = b.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+// SubscribeNewHead subscribes to the heads of the first endpoint that can push them, a single
+// endpoint cannot be checked against a quorum. The subscription ends with an error if that endpoint fails.
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := c.do(ctx, true, func(b Backend) (err error) {
+		sub, err = b.SubscribeNewHead(ctx, ch)
+		return err
+	})
+	return sub, err
+}
+
+// Run checks the health of the endpoints every HealthCheckInterval until ctx is done
+func (c *Client) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		c.checkHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth reads the head of every endpoint. Endpoints are healthy if they returned a head
+// at most MaxBlockLag blocks behind the highest head returned.
+func (c *Client) checkHealth(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.healthCheckInterval)
+	defer cancel()
+	heads := make([]uint64, len(c.endpoints))
+	errs := make([]error, len(c.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range c.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			backend, err := e.connect(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			header, err := backend.HeaderByNumber(ctx, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			heads[i] = header.Number.Uint64()
+		}(i, e)
+	}
+	wg.Wait()
+	if ctx.Err() != nil && ctx.Err() != context.DeadlineExceeded {
+		return
+	}
+
+	var highest uint64
+	for i := range c.endpoints {
+		if errs[i] == nil && heads[i] > highest {
+			highest = heads[i]
+		}
+	}
+	now := time.Now()
+	for i, e := range c.endpoints {
+		err := errs[i]
+		if err == nil && heads[i]+c.maxBlockLag < highest {
+			err = fmt.Errorf("head %d is more than %d blocks behind %d", heads[i], c.maxBlockLag, highest)
+		}
+		e.lock.Lock()
+		e.lastChecked = now
+		e.lock.Unlock()
+		if err != nil {
+			e.fail(err)
+			continue
+		}
+		e.lock.Lock()
+		if !e.healthy {
+			logging.WithField("endpoint", e.Name).Info("ethereum endpoint is healthy again")
+		}
+		e.healthy = true
+		e.head = heads[i]
+		e.lock.Unlock()
+	}
+}
@@ -0,0 +1,67 @@
+package ethwatch
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	nodelist "github.com/torusresearch/torus-node/solidity/goContracts"
+)
+
+// contractReader reads the NodeList contract
+type contractReader struct {
+	contract *nodelist.NodeList
+}
+
+// NewContractReader returns a Reader of the NodeList contract
+func NewContractReader(contract *nodelist.NodeList) Reader {
+	return contractReader{contract: contract}
+}
+
+func (r contractReader) EpochInfo(ctx context.Context, epoch int) (EpochInfo, error) {
+	result, err := r.contract.GetEpochInfo(&bind.CallOpts{Context: ctx}, big.NewInt(int64(epoch)))
+	if err != nil {
+		return EpochInfo{}, err
+	}
+	return EpochInfo{
+		ID:        int(result.Id.Int64()),
+		N:         int(result.N.Int64()),
+		K:         int(result.K.Int64()),
+		T:         int(result.T.Int64()),
+		PrevEpoch: int(result.PrevEpoch.Int64()),
+		NextEpoch: int(result.NextEpoch.Int64()),
+		Nodes:     result.NodeList,
+	}, nil
+}
+
+func (r contractReader) PSSStatus(ctx context.Context, oldEpoch, newEpoch int) (int, error) {
+	status, err := r.contract.GetPssStatus(&bind.CallOpts{Context: ctx}, big.NewInt(int64(oldEpoch)), big.NewInt(int64(newEpoch)))
+	if err != nil {
+		return 0, err
+	}
+	return int(status.Int64()), nil
+}
+
+func (r contractReader) Whitelisted(ctx context.Context, epoch int, address common.Address) (bool, error) {
+	return r.contract.IsWhitelisted(&bind.CallOpts{Context: ctx}, big.NewInt(int64(epoch)), address)
+}
+
+func (r contractReader) NodesListed(ctx context.Context, from, to uint64) ([]NodeListing, error) {
+	it, err := r.contract.FilterNodeListed(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var listings []NodeListing
+	for it.Next() {
+		listings = append(listings, NodeListing{
+			Block:    it.Event.Raw.BlockNumber,
+			Node:     it.Event.PublicKey,
+			Epoch:    int(it.Event.Epoch.Int64()),
+			Position: int(it.Event.Position.Int64()),
+			Removed:  it.Event.Raw.Removed,
+		})
+	}
+	return listings, it.Error()
+}
@@ -0,0 +1,421 @@
+// Package ethwatch follows the NodeList contract and notifies subscribers when nodes are listed,
+// or when epochs, PSS statuses or the whitelist change, once the changes are confirmed.
+package ethwatch
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	logging "github.com/sirupsen/logrus"
+)
+
+// EventType is a kind of change of the NodeList
+type EventType string
+
+const (
+	NodeListed       EventType = "node_listed"
+	EpochUpdated     EventType = "epoch_updated"
+	PSSStatusUpdated EventType = "pss_status_updated"
+	WhitelistUpdated EventType = "whitelist_updated"
+)
+
+// DefaultPollInterval is how often heads are polled from clients that cannot push them
+const DefaultPollInterval = 10 * time.Second
+
+// subscriptionBuffer is the number of events a subscriber may fall behind before events are dropped
+const subscriptionBuffer = 64
+
+// EpochInfo is the state of an epoch in the NodeList
+type EpochInfo struct {
+	ID        int
+	N         int
+	K         int
+	T         int
+	PrevEpoch int
+	NextEpoch int
+	Nodes     []common.Address
+}
+
+// NodeListing is a node listed in an epoch, read from the logs of the NodeList
+type NodeListing struct {
+	Block    uint64
+	Node     common.Address
+	Epoch    int
+	Position int
+	Removed  bool
+}
+
+// Event is a confirmed change of the NodeList. Epoch is the epoch the node was listed in, the
+// epoch that was updated or whitelisted for, or the old epoch of a PSS.
+type Event struct {
+	Type        EventType
+	Block       uint64
+	Epoch       int
+	NewEpoch    int
+	Node        common.Address
+	Position    int
+	EpochInfo   EpochInfo
+	PSSStatus   int
+	Whitelisted bool
+}
+
+// Reader reads the NodeList at the latest block
+type Reader interface {
+	EpochInfo(ctx context.Context, epoch int) (EpochInfo, error)
+	PSSStatus(ctx context.Context, oldEpoch, newEpoch int) (int, error)
+	Whitelisted(ctx context.Context, epoch int, address common.Address) (bool, error)
+	// NodesListed returns the nodes listed between blocks from and to, inclusive
+	NodesListed(ctx context.Context, from, to uint64) ([]NodeListing, error)
+}
+
+// Chain returns the heads of the chain the NodeList is deployed on
+type Chain interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// HeadSubscriber is implemented by clients that push new heads, such as ethclient over websockets
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Config configures a Watcher
+type Config struct {
+	// Confirmations is the number of blocks a log or a change of state must be buried under before
+	// it is reported, so that changes undone by reorgs shallower than it are never reported
+	Confirmations uint64
+	// PollInterval is how often heads are polled when the chain cannot push them
+	PollInterval time.Duration
+}
+
+type pssKey struct {
+	oldEpoch, newEpoch int
+}
+
+type whitelistKey struct {
+	epoch   int
+	address common.Address
+}
+
+// observation is the last value read for a watched part of the state. Values are reported once
+// they were read unchanged for the confirmations of the watcher.
+type observation struct {
+	value    interface{}
+	since    uint64
+	reported interface{}
+}
+
+func newObservation(zero interface{}) *observation {
+	return &observation{value: zero, reported: zero}
+}
+
+// observe records value read at head, and returns true if it should be reported
+func (o *observation) observe(value interface{}, head, confirmations uint64) bool {
+	if !reflect.DeepEqual(value, o.value) {
+		o.value = value
+		o.since = head
+	}
+	if reflect.DeepEqual(o.value, o.reported) || head < o.since+confirmations {
+		return false
+	}
+	o.reported = o.value
+	return true
+}
+
+// Watcher follows the NodeList on every new head. Nodes listed are read from the logs of blocks
+// buried under Confirmations blocks. The contract only logs listings, so epochs, PSS statuses and
+// whitelist entries that are watched are read on every head, and reported once unchanged for
+// Confirmations blocks. Watched values that are already set are reported on their first read.
+type Watcher struct {
+	chain         Chain
+	reader        Reader
+	confirmations uint64
+	pollInterval  time.Duration
+	poke          chan struct{}
+
+	lock      sync.Mutex
+	epochs    map[int]*observation
+	pss       map[pssKey]*observation
+	whitelist map[whitelistKey]*observation
+	subs      map[*Subscription]struct{}
+	head      uint64
+	processed uint64
+	started   bool
+}
+
+// NewWatcher creates a Watcher of the NodeList read by reader on chain
+func NewWatcher(chain Chain, reader Reader, cfg Config) *Watcher {
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &Watcher{
+		chain:         chain,
+		reader:        reader,
+		confirmations: cfg.Confirmations,
+		pollInterval:  pollInterval,
+		poke:          make(chan struct{}, 1),
+		epochs:        make(map[int]*observation),
+		pss:           make(map[pssKey]*observation),
+		whitelist:     make(map[whitelistKey]*observation),
+		subs:          make(map[*Subscription]struct{}),
+	}
+}
+
+// WatchEpoch reports EpochUpdated when the info of epoch changes
+func (w *Watcher) WatchEpoch(epoch int) {
+	w.lock.Lock()
+	if _, ok := w.epochs[epoch]; !ok {
+		w.epochs[epoch] = newObservation(EpochInfo{})
+	}
+	w.lock.Unlock()
+	w.refresh()
+}
+
+// WatchPSS reports PSSStatusUpdated when the status of the PSS from oldEpoch to newEpoch changes
+func (w *Watcher) WatchPSS(oldEpoch, newEpoch int) {
+	key := pssKey{oldEpoch, newEpoch}
+	w.lock.Lock()
+	if _, ok := w.pss[key]; !ok {
+		w.pss[key] = newObservation(0)
+	}
+	w.lock.Unlock()
+	w.refresh()
+}
+
+// WatchWhitelist reports WhitelistUpdated when address is added to or removed from the whitelist of epoch
+func (w *Watcher) WatchWhitelist(epoch int, address common.Address) {
+	key := whitelistKey{epoch, address}
+	w.lock.Lock()
+	if _, ok := w.whitelist[key]; !ok {
+		w.whitelist[key] = newObservation(false)
+	}
+	w.lock.Unlock()
+	w.refresh()
+}
+
+// refresh reads the watched state again without waiting for the next head
+func (w *Watcher) refresh() {
+	select {
+	case w.poke <- struct{}{}:
+	default:
+	}
+}
+
+// Run follows the chain until ctx is done. Heads are subscribed to if the chain supports it,
+// and polled every PollInterval otherwise or once the subscription fails.
+func (w *Watcher) Run(ctx context.Context) error {
+	heads := make(chan *types.Header, subscriptionBuffer)
+	var sub ethereum.Subscription
+	if subscriber, ok := w.chain.(HeadSubscriber); ok {
+		var err error
+		sub, err = subscriber.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			logging.WithError(err).Info("could not subscribe to new heads, polling instead")
+			sub = nil
+		}
+	}
+	var subErr <-chan error
+	var poll <-chan time.Time
+	if sub != nil {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	} else {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	w.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case head := <-heads:
+			w.process(ctx, head.Number.Uint64())
+		case <-poll:
+			w.poll(ctx)
+		case <-w.poke:
+			w.lock.Lock()
+			head, started := w.head, w.started
+			w.lock.Unlock()
+			if started {
+				w.process(ctx, head)
+			} else {
+				w.poll(ctx)
+			}
+		case err := <-subErr:
+			logging.WithError(err).Warn("new heads subscription failed, polling instead")
+			sub.Unsubscribe()
+			subErr = nil
+			ticker := time.NewTicker(w.pollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context) {
+	header, err := w.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		logging.WithError(err).Error("could not get latest head")
+		return
+	}
+	w.process(ctx, header.Number.Uint64())
+}
+
+// process reads the logs confirmed by head and the watched state
+func (w *Watcher) process(ctx context.Context, head uint64) {
+	var events []Event
+	w.lock.Lock()
+	w.head = head
+	processed, started := w.processed, w.started
+	w.lock.Unlock()
+
+	// listings before the watcher started are not reported
+	if head >= w.confirmations {
+		confirmed := head - w.confirmations
+		if !started {
+			processed, started = confirmed, true
+		} else if confirmed > processed {
+			listings, err := w.reader.NodesListed(ctx, processed+1, confirmed)
+			if err != nil {
+				logging.WithError(err).Error("could not filter NodeList logs")
+			} else {
+				for _, listing := range listings {
+					if listing.Removed {
+						continue
+					}
+					events = append(events, Event{
+						Type:     NodeListed,
+						Block:    listing.Block,
+						Epoch:    listing.Epoch,
+						Node:     listing.Node,
+						Position: listing.Position,
+					})
+				}
+				processed = confirmed
+			}
+		}
+	}
+	w.lock.Lock()
+	w.processed, w.started = processed, started
+	w.lock.Unlock()
+
+	events = append(events, w.readState(ctx, head)...)
+	w.publish(events)
+}
+
+// readState reads the watched epochs, PSS statuses and whitelist entries at head
+func (w *Watcher) readState(ctx context.Context, head uint64) []Event {
+	w.lock.Lock()
+	epochs := make([]int, 0, len(w.epochs))
+	for epoch := range w.epochs {
+		epochs = append(epochs, epoch)
+	}
+	psss := make([]pssKey, 0, len(w.pss))
+	for key := range w.pss {
+		psss = append(psss, key)
+	}
+	whitelist := make([]whitelistKey, 0, len(w.whitelist))
+	for key := range w.whitelist {
+		whitelist = append(whitelist, key)
+	}
+	w.lock.Unlock()
+
+	var events []Event
+	for _, epoch := range epochs {
+		info, err := w.reader.EpochInfo(ctx, epoch)
+		if err != nil {
+			logging.WithField("epoch", epoch).WithError(err).Debug("could not read epoch info")
+			continue
+		}
+		if len(info.Nodes) == 0 {
+			info.Nodes = nil
+		}
+		w.lock.Lock()
+		if w.epochs[epoch].observe(info, head, w.confirmations) {
+			events = append(events, Event{Type: EpochUpdated, Block: head, Epoch: epoch, EpochInfo: info})
+		}
+		w.lock.Unlock()
+	}
+	for _, key := range psss {
+		status, err := w.reader.PSSStatus(ctx, key.oldEpoch, key.newEpoch)
+		if err != nil {
+			logging.WithField("oldEpoch", key.oldEpoch).WithField("newEpoch", key.newEpoch).WithError(err).Debug("could not read pss status")
+			continue
+		}
+		w.lock.Lock()
+		if w.pss[key].observe(status, head, w.confirmations) {
+			events = append(events, Event{Type: PSSStatusUpdated, Block: head, Epoch: key.oldEpoch, NewEpoch: key.newEpoch, PSSStatus: status})
+		}
+		w.lock.Unlock()
+	}
+	for _, key := range whitelist {
+		whitelisted, err := w.reader.Whitelisted(ctx, key.epoch, key.address)
+		if err != nil {
+			logging.WithField("epoch", key.epoch).WithError(err).Debug("could not read whitelist")
+			continue
+		}
+		w.lock.Lock()
+		if w.whitelist[key].observe(whitelisted, head, w.confirmations) {
+			events = append(events, Event{Type: WhitelistUpdated, Block: head, Epoch: key.epoch, Node: key.address, Whitelisted: whitelisted})
+		}
+		w.lock.Unlock()
+	}
+	return events
+}
+
+// Subscription receives the events of the types it was created for on C
+type Subscription struct {
+	C <-chan Event
+
+	c     chan Event
+	types map[EventType]bool
+	w     *Watcher
+}
+
+// Subscribe returns a subscription to events of types, or to every event if none are given.
+// Events are signals to read the NodeList again, those a subscriber falls too far behind on are dropped.
+func (w *Watcher) Subscribe(types ...EventType) *Subscription {
+	c := make(chan Event, subscriptionBuffer)
+	s := &Subscription{C: c, c: c, types: make(map[EventType]bool), w: w}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	w.lock.Lock()
+	w.subs[s] = struct{}{}
+	w.lock.Unlock()
+	return s
+}
+
+// Unsubscribe stops sending events to s
+func (s *Subscription) Unsubscribe() {
+	s.w.lock.Lock()
+	delete(s.w.subs, s)
+	s.w.lock.Unlock()
+}
+
+func (w *Watcher) publish(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, event := range events {
+		for s := range w.subs {
+			if len(s.types) > 0 && !s.types[event.Type] {
+				continue
+			}
+			select {
+			case s.c <- event:
+			default:
+				logging.WithField("type", event.Type).Warn("NodeList subscriber is not keeping up, dropping event")
+			}
+		}
+	}
+}
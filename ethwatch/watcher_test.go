@@ -0,0 +1,236 @@
+package ethwatch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	nodelist "github.com/torusresearch/torus-node/solidity/goContracts"
+)
+
+// simChain is a simulated chain that counts its blocks, as the simulated backend does not return heads
+type simChain struct {
+	backend  *backends.SimulatedBackend
+	contract *nodelist.NodeList
+	owner    *bind.TransactOpts
+	node     *bind.TransactOpts
+	nodeKey  *ecdsa.PrivateKey
+
+	lock  sync.Mutex
+	head  uint64
+	heads event.Feed
+}
+
+func newSimChain(t *testing.T) *simChain {
+	ownerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := bind.NewKeyedTransactor(ownerKey)
+	node := bind.NewKeyedTransactor(nodeKey)
+	balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		owner.From: {Balance: balance},
+		node.From:  {Balance: balance},
+	}, 8000000)
+	_, _, contract, err := nodelist.DeployNodeList(owner, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &simChain{backend: backend, contract: contract, owner: owner, node: node, nodeKey: nodeKey}
+	c.commit()
+	return c
+}
+
+func (c *simChain) commit() uint64 {
+	c.backend.Commit()
+	c.lock.Lock()
+	c.head++
+	head := c.head
+	c.lock.Unlock()
+	c.heads.Send(&types.Header{Number: new(big.Int).SetUint64(head)})
+	return head
+}
+
+func (c *simChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+}
+
+func (c *simChain) createEpoch(t *testing.T, epoch int) {
+	_, err := c.contract.UpdateEpoch(c.owner, big.NewInt(int64(epoch)), big.NewInt(1), big.NewInt(1), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.contract.UpdateWhitelist(c.owner, big.NewInt(int64(epoch)), c.node.From, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *simChain) listNode(t *testing.T, epoch int) {
+	_, err := c.contract.ListNode(c.node, big.NewInt(int64(epoch)), "127.0.0.1:443", c.nodeKey.X, c.nodeKey.Y, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pushChain pushes its heads to subscribers like ethclient over websockets
+type pushChain struct {
+	*simChain
+}
+
+func (c pushChain) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return c.heads.Subscribe(ch), nil
+}
+
+func expectEvent(t *testing.T, sub *Subscription) Event {
+	select {
+	case e := <-sub.C:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event")
+	}
+	return Event{}
+}
+
+func expectNoEvent(t *testing.T, sub *Subscription) {
+	select {
+	case e := <-sub.C:
+		t.Fatalf("expected no event, got %+v", e)
+	default:
+	}
+}
+
+func TestNodeListedConfirmations(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimChain(t)
+	chain.createEpoch(t, 1)
+	w := NewWatcher(chain, NewContractReader(chain.contract), Config{Confirmations: 2})
+	sub := w.Subscribe(NodeListed)
+	w.process(ctx, chain.commit())
+
+	chain.listNode(t, 1)
+	listed := chain.commit()
+	w.process(ctx, listed)
+	expectNoEvent(t, sub)
+	w.process(ctx, chain.commit())
+	expectNoEvent(t, sub)
+	w.process(ctx, chain.commit())
+	e := expectEvent(t, sub)
+	if e.Node != chain.node.From || e.Epoch != 1 || e.Position != 1 || e.Block != listed {
+		t.Fatalf("expected node %v listed at position 1 of epoch 1 in block %v, got %+v", chain.node.From.Hex(), listed, e)
+	}
+	w.process(ctx, chain.commit())
+	expectNoEvent(t, sub)
+}
+
+func TestStateChangesConfirmations(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimChain(t)
+	w := NewWatcher(chain, NewContractReader(chain.contract), Config{Confirmations: 1})
+	sub := w.Subscribe(EpochUpdated, PSSStatusUpdated, WhitelistUpdated)
+	w.WatchEpoch(2)
+	w.WatchPSS(1, 2)
+	w.WatchWhitelist(2, chain.node.From)
+	w.process(ctx, chain.commit())
+	expectNoEvent(t, sub)
+
+	chain.createEpoch(t, 2)
+	w.process(ctx, chain.commit())
+	expectNoEvent(t, sub)
+	w.process(ctx, chain.commit())
+	seen := map[EventType]Event{}
+	for i := 0; i < 2; i++ {
+		e := expectEvent(t, sub)
+		seen[e.Type] = e
+	}
+	if e := seen[EpochUpdated]; e.Epoch != 2 || e.EpochInfo.ID != 2 || e.EpochInfo.N != 1 {
+		t.Fatalf("expected epoch 2 to be updated, got %+v", e)
+	}
+	if e := seen[WhitelistUpdated]; e.Epoch != 2 || e.Node != chain.node.From || !e.Whitelisted {
+		t.Fatalf("expected the node to be whitelisted in epoch 2, got %+v", e)
+	}
+
+	// a status undone before it is confirmed, as by a reorg, is never reported
+	if _, err := chain.contract.UpdatePssStatus(chain.owner, big.NewInt(1), big.NewInt(2), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	w.process(ctx, chain.commit())
+	if _, err := chain.contract.UpdatePssStatus(chain.owner, big.NewInt(1), big.NewInt(2), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	w.process(ctx, chain.commit())
+	w.process(ctx, chain.commit())
+	expectNoEvent(t, sub)
+
+	if _, err := chain.contract.UpdatePssStatus(chain.owner, big.NewInt(1), big.NewInt(2), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	w.process(ctx, chain.commit())
+	w.process(ctx, chain.commit())
+	if e := expectEvent(t, sub); e.Type != PSSStatusUpdated || e.Epoch != 1 || e.NewEpoch != 2 || e.PSSStatus != 1 {
+		t.Fatalf("expected the status of the pss from epoch 1 to 2 to be 1, got %+v", e)
+	}
+}
+
+func TestRunPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain := newSimChain(t)
+	chain.createEpoch(t, 3)
+	chain.commit()
+	w := NewWatcher(chain, NewContractReader(chain.contract), Config{PollInterval: 10 * time.Millisecond})
+	sub := w.Subscribe()
+	go func() {
+		_ = w.Run(ctx)
+	}()
+	// values that are already set are reported on their first read
+	w.WatchEpoch(3)
+	if e := expectEvent(t, sub); e.Type != EpochUpdated || e.EpochInfo.ID != 3 {
+		t.Fatalf("expected epoch 3 to be reported, got %+v", e)
+	}
+}
+
+func TestRunSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain := newSimChain(t)
+	chain.createEpoch(t, 1)
+	chain.commit()
+	// polling is never used, heads are only received from the subscription
+	w := NewWatcher(pushChain{chain}, NewContractReader(chain.contract), Config{PollInterval: time.Hour})
+	sub := w.Subscribe(NodeListed)
+	go func() {
+		_ = w.Run(ctx)
+	}()
+	for {
+		w.lock.Lock()
+		started := w.started
+		w.lock.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	chain.listNode(t, 1)
+	chain.commit()
+	if e := expectEvent(t, sub); e.Node != chain.node.From || e.Epoch != 1 {
+		t.Fatalf("expected the node to be listed in epoch 1, got %+v", e)
+	}
+}
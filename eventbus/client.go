@@ -3,10 +3,8 @@ package eventbus
 import (
 	"errors"
 	"fmt"
-	"net"
-	"net/http"
-	"net/rpc"
 	"sync"
+	"time"
 )
 
 const (
@@ -25,16 +23,43 @@ type Client struct {
 	eventBus Bus
 	address  string
 	path     string
+	config   *NetworkConfig
+	pool     *rpcPool
+	listener *rpcListener
+	lock     sync.Mutex
+	remotes  map[string]*remoteServer
+	stop     chan struct{}
 	service  *ClientService
 }
 
+// remoteServer - a server the client subscribed to and the running instance it registered with
+type remoteServer struct {
+	address       string
+	path          string
+	serverID      string
+	subscriptions []*remoteSubscription
+}
+
+type remoteSubscription struct {
+	arg  SubscribeArg
+	done bool
+}
+
 // NewClient - create a client object with the address and server path
 func NewClient(address, path string, eventBus Bus) *Client {
+	return NewClientWithConfig(address, path, eventBus, DefaultNetworkConfig())
+}
+
+// NewClientWithConfig - create a client object with the address and server path with the transport settings in config
+func NewClientWithConfig(address, path string, eventBus Bus, config *NetworkConfig) *Client {
 	client := new(Client)
 	client.eventBus = eventBus
 	client.address = address
 	client.path = path
-	client.service = &ClientService{client, &sync.WaitGroup{}, false}
+	client.config = config
+	client.pool = newRPCPool(config)
+	client.remotes = make(map[string]*remoteServer)
+	client.service = &ClientService{client, false}
 	return client
 }
 
@@ -43,93 +68,189 @@ func (client *Client) EventBus() Bus {
 	return client.eventBus
 }
 
-func (client *Client) doSubscribe(topic string, fn func(interface{}), serverAddr, serverPath string, subscribeType SubscribeType) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("Server not found -", r)
-		}
-	}()
+// advertiseAddress - the address servers push events to
+func (client *Client) advertiseAddress() string {
+	if client.config.AdvertiseAddress != "" {
+		return client.config.AdvertiseAddress
+	}
+	return client.address
+}
 
-	rpcClient, err := rpc.DialHTTPPath("tcp", serverAddr, serverPath)
+func (client *Client) doSubscribe(topic string, fn func(interface{}), serverAddr, serverPath string, subscribeType SubscribeType) error {
+	sub := &remoteSubscription{
+		arg: SubscribeArg{client.advertiseAddress(), client.path, PublishService, subscribeType, topic},
+	}
+	var err error
+	switch subscribeType {
+	case Subscribe:
+		err = client.eventBus.Subscribe(topic, fn)
+	case SubscribeOnce:
+		err = client.eventBus.SubscribeOnce(topic, func(data interface{}) {
+			client.lock.Lock()
+			sub.done = true
+			client.lock.Unlock()
+			fn(data)
+		})
+	default:
+		err = fmt.Errorf("unknown subscribe type %v", subscribeType)
+	}
 	if err != nil {
-		fmt.Printf("dialing: %v", err)
+		return err
+	}
+
+	client.lock.Lock()
+	key := serverAddr + serverPath
+	remote, ok := client.remotes[key]
+	if !ok {
+		remote = &remoteServer{address: serverAddr, path: serverPath}
+		client.remotes[key] = remote
 	}
-	defer rpcClient.Close()
-	args := &SubscribeArg{client.address, client.path, PublishService, subscribeType, topic}
+	remote.subscriptions = append(remote.subscriptions, sub)
+	client.lock.Unlock()
+
+	return client.register(remote, sub)
+}
+
+func (client *Client) register(remote *remoteServer, sub *remoteSubscription) error {
 	reply := new(bool)
-	err = rpcClient.Call(RegisterService, args, reply)
+	err := client.pool.call(remote.address, remote.path, RegisterService, &sub.arg, reply)
 	if err != nil {
-		fmt.Printf("Register error: %v", err)
+		return fmt.Errorf("could not subscribe to %v on %v%v: %v", sub.arg.Topic, remote.address, remote.path, err)
 	}
-	if *reply {
-		err := client.eventBus.Subscribe(topic, fn)
-		if err != nil {
-			fmt.Printf("Error when subscribe: %v", err)
-		}
+	if !*reply {
+		return fmt.Errorf("%v%v refused subscription to %v", remote.address, remote.path, sub.arg.Topic)
 	}
+	return nil
 }
 
-// Subscribe subscribes to a topic in a remote event bus
-func (client *Client) Subscribe(topic string, fn func(interface{}), serverAddr, serverPath string) {
-	client.doSubscribe(topic, fn, serverAddr, serverPath, Subscribe)
+// Subscribe subscribes to a topic in a remote event bus.
+// If the server can not be reached the error is returned and the subscription is retried with every heartbeat
+func (client *Client) Subscribe(topic string, fn func(interface{}), serverAddr, serverPath string) error {
+	return client.doSubscribe(topic, fn, serverAddr, serverPath, Subscribe)
 }
 
 // SubscribeOnce subscribes once to a topic in a remote event bus
-func (client *Client) SubscribeOnce(topic string, fn func(interface{}), serverAddr, serverPath string) {
-	client.doSubscribe(topic, fn, serverAddr, serverPath, SubscribeOnce)
+func (client *Client) SubscribeOnce(topic string, fn func(interface{}), serverAddr, serverPath string) error {
+	return client.doSubscribe(topic, fn, serverAddr, serverPath, SubscribeOnce)
 }
 
-// Start - starts the client service to listen to remote events
-func (client *Client) Start() error {
-	var err error
-	service := client.service
-	if !service.started {
-		noErrors := true
-		server := rpc.NewServer()
-		err := server.Register(service)
-		if err != nil {
-			noErrors = false
+// heartbeat - checks the servers the client subscribed to every HeartbeatInterval
+func (client *Client) heartbeat() {
+	ticker := time.NewTicker(client.config.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-client.stop:
+			return
+		case <-ticker.C:
+			client.checkServers()
 		}
-		server.HandleHTTP(client.path, "/debug"+client.path)
-		l, err := net.Listen("tcp", client.address)
+	}
+}
+
+// checkServers - resubscribes to servers that restarted or could not be reached before
+func (client *Client) checkServers() {
+	client.lock.Lock()
+	remotes := make([]*remoteServer, 0, len(client.remotes))
+	for _, remote := range client.remotes {
+		remotes = append(remotes, remote)
+	}
+	client.lock.Unlock()
+
+	for _, remote := range remotes {
+		var reply HeartbeatReply
+		err := client.pool.call(remote.address, remote.path, HeartbeatService, &HeartbeatArg{client.advertiseAddress(), client.path}, &reply)
 		if err != nil {
-			noErrors = false
+			fmt.Printf("eventbus: heartbeat to %v%v failed: %v \n", remote.address, remote.path, err)
+			continue
+		}
+		client.lock.Lock()
+		if reply.ServerID == remote.serverID {
+			client.lock.Unlock()
+			continue
+		}
+		var subscriptions []*remoteSubscription
+		for _, sub := range remote.subscriptions {
+			if !sub.done {
+				subscriptions = append(subscriptions, sub)
+			}
+		}
+		remote.subscriptions = subscriptions
+		client.lock.Unlock()
+
+		// the server dedupes registrations, so subscriptions it still has are not doubled
+		resubscribed := true
+		for _, sub := range subscriptions {
+			if err := client.register(remote, sub); err != nil {
+				fmt.Printf("eventbus: %v \n", err)
+				resubscribed = false
+			}
 		}
-		if noErrors {
-			service.wg.Add(1)
-			service.started = true
-			go func() {
-				err := http.Serve(l, nil)
-				if err != nil {
-					fmt.Printf("Could not serve http %v \n", err)
-				}
-			}()
+		if resubscribed {
+			client.lock.Lock()
+			remote.serverID = reply.ServerID
+			client.lock.Unlock()
 		}
-	} else {
-		err = errors.New("Client service already started")
 	}
-	return err
+}
+
+// Start - starts the client service to listen to remote events
+func (client *Client) Start() error {
+	service := client.service
+	if service.started {
+		return errors.New("Client service already started")
+	}
+	listener, err := listenRPC(client.address, client.path, client.config, map[string]interface{}{
+		"ClientService": service,
+	})
+	if err != nil {
+		return err
+	}
+	client.listener = listener
+	client.start()
+	return nil
+}
+
+func (client *Client) start() {
+	client.service.started = true
+	client.stop = make(chan struct{})
+	if client.config.HeartbeatInterval > 0 {
+		go client.heartbeat()
+	}
 }
 
 // Stop - signal for the service to stop serving
 func (client *Client) Stop() {
 	service := client.service
-	if service.started {
-		service.wg.Done()
-		service.started = false
+	if !service.started {
+		return
+	}
+	service.started = false
+	close(client.stop)
+	if client.listener != nil {
+		client.listener.close()
+		client.listener = nil
 	}
+	client.pool.closeAll()
 }
 
 // ClientService - service object listening to events published in a remote event bus
 type ClientService struct {
 	client  *Client
-	wg      *sync.WaitGroup
 	started bool
 }
 
 // PushEvent - exported service to listening to remote events
 func (service *ClientService) PushEvent(arg *ClientArg, reply *bool) error {
-	service.client.eventBus.Publish(arg.Topic, arg.Data)
+	data := arg.Data
+	if event, ok := data.(EncodedEvent); ok {
+		var err error
+		data, err = decodeEvent(arg.Topic, event)
+		if err != nil {
+			return err
+		}
+	}
+	service.client.eventBus.Publish(arg.Topic, data)
 	*reply = true
 	return nil
 }
@@ -0,0 +1,123 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Codec - encodes the events of a topic for the network bus
+type Codec interface {
+	Marshal(data interface{}) ([]byte, error)
+	Unmarshal(b []byte) (interface{}, error)
+}
+
+// EncodedEvent - event data encoded by the codec of its topic
+type EncodedEvent struct {
+	Bytes []byte
+}
+
+var (
+	codecsLock sync.RWMutex
+	codecs     = make(map[string]Codec)
+)
+
+func init() {
+	gob.Register(EncodedEvent{})
+}
+
+// RegisterCodec - sets the codec used for events published on topic, both ends of the
+// network bus have to register the same codec. Topics without a codec use gob,
+// which needs the concrete types of the events registered with RegisterType
+func RegisterCodec(topic string, codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs[topic] = codec
+}
+
+// RegisterType - registers the concrete type of value for topics without a codec
+func RegisterType(value interface{}) {
+	gob.Register(value)
+}
+
+func codecFor(topic string) Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	if codec, ok := codecs[topic]; ok {
+		return codec
+	}
+	return DefaultCodec
+}
+
+// gobValue wraps event data so that gob keeps its concrete type
+type gobValue struct {
+	Value interface{}
+}
+
+type interfaceGobCodec struct{}
+
+// DefaultCodec - gob codec used for topics without a codec, the concrete types of the
+// events have to be registered with RegisterType
+var DefaultCodec Codec = interfaceGobCodec{}
+
+func (interfaceGobCodec) Marshal(data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobValue{data}); err != nil {
+		return nil, fmt.Errorf("%v, register the type with eventbus.RegisterType or a codec with eventbus.RegisterCodec", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (interfaceGobCodec) Unmarshal(b []byte) (interface{}, error) {
+	var value gobValue
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value.Value, nil
+}
+
+type typedGobCodec struct {
+	typ reflect.Type
+}
+
+// GobCodec - returns a Codec for events of the type of example, which does not need RegisterType
+func GobCodec(example interface{}) Codec {
+	return typedGobCodec{reflect.TypeOf(example)}
+}
+
+func (codec typedGobCodec) Marshal(data interface{}) ([]byte, error) {
+	if reflect.TypeOf(data) != codec.typ {
+		return nil, fmt.Errorf("codec expects %v, got %T", codec.typ, data)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (codec typedGobCodec) Unmarshal(b []byte) (interface{}, error) {
+	value := reflect.New(codec.typ)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
+
+func encodeEvent(topic string, data interface{}) (EncodedEvent, error) {
+	b, err := codecFor(topic).Marshal(data)
+	if err != nil {
+		return EncodedEvent{}, fmt.Errorf("could not encode event on topic %v: %v", topic, err)
+	}
+	return EncodedEvent{b}, nil
+}
+
+func decodeEvent(topic string, event EncodedEvent) (interface{}, error) {
+	data, err := codecFor(topic).Unmarshal(event.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event on topic %v: %v", topic, err)
+	}
+	return data, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/torusresearch/torus-node/idmutex"
 )
@@ -27,6 +28,9 @@ type BusPublisher interface {
 type BusController interface {
 	AddMiddleware(*func(string, interface{}) interface{})
 	RemoveMiddleware(*func(string, interface{}) interface{})
+	// AddObserver registers a function called once for every published event, whether or not it has handlers
+	AddObserver(*func(string, interface{}))
+	RemoveObserver(*func(string, interface{}))
 	HasCallback(topic string) bool
 	WaitAsync()
 }
@@ -41,7 +45,11 @@ type Bus interface {
 // EventBus - box for handlers and callbacks.
 type EventBus struct {
 	middleware []*func(string, interface{}) interface{}
+	observers  []*func(string, interface{})
 	handlers   map[string][]*eventHandler
+	pools      map[string]*topicPool
+	config     Config
+	metrics    Metrics
 	lock       idmutex.Mutex // a lock for the map
 	wg         sync.WaitGroup
 }
@@ -55,12 +63,22 @@ type eventHandler struct {
 }
 
 // New returns new EventBus with empty handlers.
+// Every async handler runs in its own goroutine.
 func New() Bus {
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig returns new EventBus with empty handlers, whose async handlers are
+// bounded by the settings of their topic in config
+func NewWithConfig(config Config) Bus {
 	b := &EventBus{
-		*new([]*func(string, interface{}) interface{}),
-		make(map[string][]*eventHandler),
-		idmutex.Mutex{},
-		sync.WaitGroup{},
+		handlers: make(map[string][]*eventHandler),
+		pools:    make(map[string]*topicPool),
+		config:   config,
+		metrics:  config.Metrics,
+	}
+	if b.metrics == nil {
+		b.metrics = noMetrics{}
 	}
 	return Bus(b)
 }
@@ -92,6 +110,23 @@ func (bus *EventBus) RemoveMiddleware(middleware *func(string, interface{}) inte
 	}
 }
 
+func (bus *EventBus) AddObserver(observer *func(string, interface{})) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	bus.observers = append(bus.observers, observer)
+}
+
+func (bus *EventBus) RemoveObserver(observer *func(string, interface{})) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	for i, o := range bus.observers {
+		if observer == o {
+			bus.observers = append(bus.observers[:i:i], bus.observers[i+1:]...)
+			return
+		}
+	}
+}
+
 func runMiddleware(middleware []*func(string, interface{}) interface{}, topic string, input interface{}) (output interface{}) {
 	output = input
 	for _, m := range middleware {
@@ -174,14 +209,18 @@ func (bus *EventBus) UnsubscribeAll(topic string) error {
 func (bus *EventBus) Publish(topic string, data interface{}) {
 	bus.lock.Lock() // will unlock if handler is not found or always after setUpPublish
 	defer bus.lock.Unlock()
+	for _, observer := range bus.observers {
+		(*observer)(topic, data)
+	}
 	if handlers, ok := bus.handlers[topic]; ok && 0 < len(handlers) {
 		// Handlers slice may be changed by removeHandler and Unsubscribe during iteration,
 		// so make a copy and iterate the copied slice.
 		copyHandlers := make([]*eventHandler, 0, len(handlers))
 		copyHandlers = append(copyHandlers, handlers...)
-		for i, handler := range copyHandlers {
+		pool := bus.pool(topic)
+		for _, handler := range copyHandlers {
 			if handler.flagOnce {
-				bus.removeHandler(topic, i)
+				bus.removeHandler(topic, bus.handlerIdx(topic, handler))
 			}
 			if !handler.async {
 				bus.doPublish(handler, topic, data)
@@ -192,12 +231,31 @@ func (bus *EventBus) Publish(topic string, data interface{}) {
 					handler.Lock()
 					bus.lock.Lock()
 				}
-				go bus.doPublishAsync(handler, topic, data)
+				job := asyncJob{handler, topic, data}
+				if pool == nil {
+					go bus.runAsync(job)
+				} else {
+					pool.submit(bus, job)
+				}
 			}
 		}
 	}
 }
 
+// pool returns the worker pool of topic, nil if its async handlers are not bounded
+func (bus *EventBus) pool(topic string) *topicPool {
+	if pool, ok := bus.pools[topic]; ok {
+		return pool
+	}
+	config := bus.config.TopicConfig(topic)
+	if config.Workers <= 0 {
+		return nil
+	}
+	pool := newTopicPool(config)
+	bus.pools[topic] = pool
+	return pool
+}
+
 func (bus *EventBus) doPublish(handler *eventHandler, topic string, origData interface{}) {
 	modData := runMiddleware(bus.middleware, topic, origData)
 	if modData == nil {
@@ -206,12 +264,29 @@ func (bus *EventBus) doPublish(handler *eventHandler, topic string, origData int
 	handler.callBack(modData)
 }
 
-func (bus *EventBus) doPublishAsync(handler *eventHandler, topic string, data interface{}) {
+func (bus *EventBus) runAsync(job asyncJob) {
 	defer bus.wg.Done()
-	if handler.transactional {
-		defer handler.Unlock()
+	if job.handler.transactional {
+		defer job.handler.Unlock()
+	}
+	bus.metrics.HandlerStarted(job.topic)
+	start := time.Now()
+	defer func() {
+		bus.metrics.HandlerFinished(job.topic, time.Since(start))
+	}()
+	bus.doPublish(job.handler, job.topic, job.data)
+}
+
+// drop discards an event that was not run because the queue of its topic was full
+func (bus *EventBus) drop(config TopicConfig, job asyncJob) {
+	if job.handler.transactional {
+		job.handler.Unlock()
+	}
+	bus.wg.Done()
+	bus.metrics.Dropped(job.topic)
+	if config.OnDrop != nil {
+		go config.OnDrop(job.topic, job.data)
 	}
-	bus.doPublish(handler, topic, data)
 }
 
 func (bus *EventBus) removeHandler(topic string, idx int) {
@@ -229,9 +304,20 @@ func (bus *EventBus) removeHandler(topic string, idx int) {
 	bus.handlers[topic] = bus.handlers[topic][:l-1]
 	if len(bus.handlers[topic]) == 0 {
 		delete(bus.handlers, topic)
+		// running and queued events keep a reference to the pool
+		delete(bus.pools, topic)
 	}
 }
 
+func (bus *EventBus) handlerIdx(topic string, handler *eventHandler) int {
+	for idx, h := range bus.handlers[topic] {
+		if h == handler {
+			return idx
+		}
+	}
+	return -1
+}
+
 func (bus *EventBus) findHandlerIdx(topic string, callback func(interface{})) int {
 	if _, ok := bus.handlers[topic]; ok {
 		for idx, handler := range bus.handlers[topic] {
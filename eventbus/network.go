@@ -0,0 +1,527 @@
+package eventbus
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// NetworkConfig - transport settings shared by Client, Server and NetworkBus
+type NetworkConfig struct {
+	// TLSConfig enables TLS on every connection. For mutual TLS set Certificates,
+	// RootCAs, ClientCAs and ClientAuth to tls.RequireAndVerifyClientCert,
+	// the same config is used to accept and to dial connections.
+	TLSConfig *tls.Config
+	// SharedKey authenticates both ends of a connection with HMAC-SHA256 and then every
+	// frame sent over it, connections between ends with different keys are refused. It does
+	// not encrypt, use TLSConfig for confidentiality. Without TLSConfig or SharedKey connections
+	// are only accepted from and dialled to loopback addresses.
+	SharedKey []byte
+	// AdvertiseAddress is the address remote servers dial to push events to a client,
+	// defaults to the address the client listens on
+	AdvertiseAddress string
+	// HandshakeTimeout bounds dialling and authenticating a connection
+	HandshakeTimeout time.Duration
+	// CallTimeout bounds a single remote call
+	CallTimeout time.Duration
+	// HeartbeatInterval is how often a client checks its servers and resubscribes
+	// after a server restarted, 0 disables heartbeats
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is how long a server keeps the subscriptions of a client
+	// that stopped sending heartbeats, 0 keeps them forever
+	HeartbeatTimeout time.Duration
+	// QueueSize is the number of events buffered for each remote client,
+	// events published while the queue is full are dropped
+	QueueSize int
+	// DeliveryAttempts is how many times an event is sent before it is dropped
+	DeliveryAttempts int
+}
+
+// DefaultNetworkConfig - unauthenticated config, limited to loopback addresses, with heartbeats every 5 seconds
+func DefaultNetworkConfig() *NetworkConfig {
+	return &NetworkConfig{
+		HandshakeTimeout:  10 * time.Second,
+		CallTimeout:       30 * time.Second,
+		HeartbeatInterval: 5 * time.Second,
+		HeartbeatTimeout:  30 * time.Second,
+		QueueSize:         1024,
+		DeliveryAttempts:  3,
+	}
+}
+
+const (
+	handshakeMagic = "TEB1"
+	nonceLength    = 32
+	// minSharedKeyLength is the length of the shortest SharedKey accepted
+	minSharedKeyLength = 16
+	// maxFrameLength bounds the payload of a frame on connections with a shared key
+	maxFrameLength = 1 << 16
+)
+
+const (
+	handshakeOK byte = iota
+	handshakeUnknownPath
+	handshakeUnauthenticated
+)
+
+var (
+	// ErrUnknownPath - the remote end does not serve the requested path
+	ErrUnknownPath = errors.New("eventbus: unknown path")
+	// ErrUnauthenticated - the remote end could not prove it has the shared key
+	ErrUnauthenticated = errors.New("eventbus: authentication failed")
+	// ErrInsecureRemote - neither TLS nor a shared key is configured for a connection to another host
+	ErrInsecureRemote = errors.New("eventbus: TLS or a shared key is required for connections to other hosts")
+	// ErrBadFrame - a frame was altered, replayed or reordered
+	ErrBadFrame = errors.New("eventbus: frame authentication failed")
+)
+
+// validate - rejects a shared key that is set but too short to authenticate anything
+func (config *NetworkConfig) validate() error {
+	if config.SharedKey != nil && len(config.SharedKey) < minSharedKeyLength {
+		return fmt.Errorf("eventbus: shared key must be at least %d bytes", minSharedKeyLength)
+	}
+	return nil
+}
+
+// secured - whether connections are authenticated by TLS or the shared key
+func (config *NetworkConfig) secured() bool {
+	return config.TLSConfig != nil || len(config.SharedKey) > 0
+}
+
+// isLoopback - whether the host of address is this machine
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func handshakeMAC(key []byte, role, path string, nonces ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(handshakeMagic + role + path))
+	for _, nonce := range nonces {
+		_, _ = mac.Write(nonce)
+	}
+	return mac.Sum(nil)
+}
+
+func randomNonce() ([]byte, error) {
+	nonce := make([]byte, nonceLength)
+	_, err := io.ReadFull(rand.Reader, nonce)
+	return nonce, err
+}
+
+// clientHandshake - asks the server for path and authenticates both ends with the shared key
+//
+//	client: magic, path length (uint16), path, client nonce
+//	server: status, server nonce, HMAC("server", path, client nonce, server nonce)
+//	client: HMAC("client", path, server nonce, client nonce)
+//	server: status
+//
+// With a shared key the returned connection MACs every frame, see framedConn.
+func clientHandshake(conn net.Conn, path string, key []byte) (net.Conn, error) {
+	if len(path) > 0xffff {
+		return nil, fmt.Errorf("eventbus: path too long")
+	}
+	clientNonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+	hello := make([]byte, 0, len(handshakeMagic)+2+len(path)+nonceLength)
+	hello = append(hello, handshakeMagic...)
+	hello = append(hello, byte(len(path)>>8), byte(len(path)))
+	hello = append(hello, path...)
+	hello = append(hello, clientNonce...)
+	if _, err := conn.Write(hello); err != nil {
+		return nil, err
+	}
+
+	status := make([]byte, 1)
+	if _, err := io.ReadFull(conn, status); err != nil {
+		return nil, err
+	}
+	if status[0] == handshakeUnknownPath {
+		return nil, ErrUnknownPath
+	}
+	serverProof := make([]byte, nonceLength+sha256.Size)
+	if _, err := io.ReadFull(conn, serverProof); err != nil {
+		return nil, err
+	}
+	serverNonce := serverProof[:nonceLength]
+	if !hmac.Equal(serverProof[nonceLength:], handshakeMAC(key, "server", path, clientNonce, serverNonce)) {
+		return nil, ErrUnauthenticated
+	}
+	if _, err := conn.Write(handshakeMAC(key, "client", path, serverNonce, clientNonce)); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(conn, status); err != nil {
+		return nil, err
+	}
+	if status[0] != handshakeOK {
+		return nil, ErrUnauthenticated
+	}
+	return newFramedConn(conn, key, "client", path, clientNonce, serverNonce), nil
+}
+
+// serverHandshake - the server side of clientHandshake
+func serverHandshake(conn net.Conn, path string, key []byte) (net.Conn, error) {
+	header := make([]byte, len(handshakeMagic)+2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(handshakeMagic)]) != handshakeMagic {
+		return nil, fmt.Errorf("eventbus: not an eventbus connection")
+	}
+	requested := make([]byte, int(binary.BigEndian.Uint16(header[len(handshakeMagic):]))+nonceLength)
+	if _, err := io.ReadFull(conn, requested); err != nil {
+		return nil, err
+	}
+	requestedPath := string(requested[:len(requested)-nonceLength])
+	clientNonce := requested[len(requested)-nonceLength:]
+	if requestedPath != path {
+		_, _ = conn.Write([]byte{handshakeUnknownPath})
+		return nil, ErrUnknownPath
+	}
+
+	serverNonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+	proof := append([]byte{handshakeOK}, serverNonce...)
+	proof = append(proof, handshakeMAC(key, "server", path, clientNonce, serverNonce)...)
+	if _, err := conn.Write(proof); err != nil {
+		return nil, err
+	}
+	clientProof := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, clientProof); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(clientProof, handshakeMAC(key, "client", path, serverNonce, clientNonce)) {
+		_, _ = conn.Write([]byte{handshakeUnauthenticated})
+		return nil, ErrUnauthenticated
+	}
+	if _, err := conn.Write([]byte{handshakeOK}); err != nil {
+		return nil, err
+	}
+	return newFramedConn(conn, key, "server", path, clientNonce, serverNonce), nil
+}
+
+// framedConn - splits the stream into frames each followed by
+// HMAC(session key of the sender, sequence number, length, payload), so that frames
+// altered, replayed or reordered after the handshake are rejected. The session keys
+// are derived from the shared key and both handshake nonces, one for each direction.
+type framedConn struct {
+	net.Conn
+	sendKey []byte
+	recvKey []byte
+
+	writeLock sync.Mutex
+	sendSeq   uint64
+
+	readLock sync.Mutex
+	recvSeq  uint64
+	pending  []byte
+	err      error
+}
+
+// newFramedConn - conn itself if there is no shared key, role is the local end
+func newFramedConn(conn net.Conn, key []byte, role, path string, clientNonce, serverNonce []byte) net.Conn {
+	if len(key) == 0 {
+		return conn
+	}
+	clientKey := handshakeMAC(key, "client frames", path, clientNonce, serverNonce)
+	serverKey := handshakeMAC(key, "server frames", path, clientNonce, serverNonce)
+	if role == "client" {
+		return &framedConn{Conn: conn, sendKey: clientKey, recvKey: serverKey}
+	}
+	return &framedConn{Conn: conn, sendKey: serverKey, recvKey: clientKey}
+}
+
+func frameMAC(key []byte, seq uint64, header, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	var seqBytes [8]byte
+	binary.BigEndian.PutUint64(seqBytes[:], seq)
+	_, _ = mac.Write(seqBytes[:])
+	_, _ = mac.Write(header)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (c *framedConn) Write(p []byte) (int, error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	written := 0
+	for written < len(p) {
+		payload := p[written:]
+		if len(payload) > maxFrameLength {
+			payload = payload[:maxFrameLength]
+		}
+		frame := make([]byte, 4, 4+len(payload)+sha256.Size)
+		binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+		frame = append(frame, payload...)
+		frame = append(frame, frameMAC(c.sendKey, c.sendSeq, frame[:4], payload)...)
+		if _, err := c.Conn.Write(frame); err != nil {
+			return written, err
+		}
+		c.sendSeq++
+		written += len(payload)
+	}
+	return written, nil
+}
+
+func (c *framedConn) Read(p []byte) (int, error) {
+	c.readLock.Lock()
+	defer c.readLock.Unlock()
+	for len(c.pending) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.pending, c.err = c.readFrame()
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *framedConn) readFrame() ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.Conn, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length > maxFrameLength {
+		return nil, ErrBadFrame
+	}
+	frame := make([]byte, int(length)+sha256.Size)
+	if _, err := io.ReadFull(c.Conn, frame); err != nil {
+		return nil, err
+	}
+	payload := frame[:length]
+	if !hmac.Equal(frame[length:], frameMAC(c.recvKey, c.recvSeq, header, payload)) {
+		return nil, ErrBadFrame
+	}
+	c.recvSeq++
+	return payload, nil
+}
+
+// rpcListener - serves net/rpc services on authenticated connections
+type rpcListener struct {
+	config   *NetworkConfig
+	path     string
+	server   *rpc.Server
+	listener net.Listener
+
+	lock  sync.Mutex
+	conns map[net.Conn]bool
+}
+
+func listenRPC(address, path string, config *NetworkConfig, services map[string]interface{}) (*rpcListener, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	server := rpc.NewServer()
+	for name, service := range services {
+		if err := server.RegisterName(name, service); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if config.TLSConfig != nil {
+		listener = tls.NewListener(listener, config.TLSConfig)
+	}
+	l := &rpcListener{
+		config:   config,
+		path:     path,
+		server:   server,
+		listener: listener,
+		conns:    make(map[net.Conn]bool),
+	}
+	go l.accept()
+	return l, nil
+}
+
+func (l *rpcListener) accept() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		go l.serve(conn)
+	}
+}
+
+func (l *rpcListener) serve(conn net.Conn) {
+	l.lock.Lock()
+	if l.conns == nil {
+		l.lock.Unlock()
+		conn.Close()
+		return
+	}
+	l.conns[conn] = true
+	l.lock.Unlock()
+	defer func() {
+		l.lock.Lock()
+		delete(l.conns, conn)
+		l.lock.Unlock()
+		conn.Close()
+	}()
+
+	if !l.config.secured() && !isLoopback(conn.RemoteAddr().String()) {
+		fmt.Printf("eventbus: refused connection from %v: %v \n", conn.RemoteAddr(), ErrInsecureRemote)
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(l.config.HandshakeTimeout))
+	framed, err := serverHandshake(conn, l.path, l.config.SharedKey)
+	if err != nil {
+		fmt.Printf("eventbus: refused connection from %v: %v \n", conn.RemoteAddr(), err)
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	l.server.ServeConn(framed)
+}
+
+func (l *rpcListener) close() {
+	l.listener.Close()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+// rpcPool - authenticated connections to remote eventbuses, redialled after they fail
+type rpcPool struct {
+	config *NetworkConfig
+
+	lock    sync.Mutex
+	clients map[string]*rpc.Client
+}
+
+func newRPCPool(config *NetworkConfig) *rpcPool {
+	return &rpcPool{
+		config:  config,
+		clients: make(map[string]*rpc.Client),
+	}
+}
+
+func (pool *rpcPool) dial(address, path string) (*rpc.Client, error) {
+	if err := pool.config.validate(); err != nil {
+		return nil, err
+	}
+	if !pool.config.secured() && !isLoopback(address) {
+		return nil, ErrInsecureRemote
+	}
+	dialer := &net.Dialer{Timeout: pool.config.HandshakeTimeout}
+	var conn net.Conn
+	var err error
+	if pool.config.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, pool.config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(pool.config.HandshakeTimeout))
+	framed, err := clientHandshake(conn, path, pool.config.SharedKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return rpc.NewClient(framed), nil
+}
+
+func (pool *rpcPool) get(address, path string) (*rpc.Client, error) {
+	key := address + path
+	pool.lock.Lock()
+	client, ok := pool.clients[key]
+	pool.lock.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	// dial without holding the lock so that an unreachable end does not hold up the others
+	client, err := pool.dial(address, path)
+	if err != nil {
+		return nil, err
+	}
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if existing, ok := pool.clients[key]; ok {
+		client.Close()
+		return existing, nil
+	}
+	pool.clients[key] = client
+	return client, nil
+}
+
+func (pool *rpcPool) drop(address, path string, client *rpc.Client) {
+	key := address + path
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if pool.clients[key] == client {
+		delete(pool.clients, key)
+	}
+	client.Close()
+}
+
+// call - calls serviceMethod on the eventbus at address and path, the connection
+// is dropped on transport errors so that the next call redials
+func (pool *rpcPool) call(address, path, serviceMethod string, args interface{}, reply interface{}) error {
+	client, err := pool.get(address, path)
+	if err != nil {
+		return err
+	}
+	call := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	timeout := time.NewTimer(pool.config.CallTimeout)
+	defer timeout.Stop()
+	select {
+	case <-call.Done:
+		err = call.Error
+	case <-timeout.C:
+		err = fmt.Errorf("eventbus: %v on %v%v timed out", serviceMethod, address, path)
+	}
+	if err != nil {
+		if _, ok := err.(rpc.ServerError); !ok {
+			pool.drop(address, path, client)
+		}
+	}
+	return err
+}
+
+// closeAll - closes the open connections, later calls dial again
+func (pool *rpcPool) closeAll() {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	for key, client := range pool.clients {
+		client.Close()
+		delete(pool.clients, key)
+	}
+}
+
+func randomID() string {
+	nonce, err := randomNonce()
+	if err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%x", nonce[:8])
+}
@@ -2,11 +2,6 @@ package eventbus
 
 import (
 	"errors"
-	"fmt"
-	"net"
-	"net/http"
-	"net/rpc"
-	"sync"
 )
 
 // NetworkBus - object capable of subscribing to remote event buses in addition to remote event
@@ -18,17 +13,26 @@ type NetworkBus struct {
 	sharedBus Bus
 	address   string
 	path      string
+	config    *NetworkConfig
+	listener  *rpcListener
 }
 
 // NewNetworkBus - returns a new network bus object at the server address and path
 func NewNetworkBus(address, path string) *NetworkBus {
+	return NewNetworkBusWithConfig(address, path, DefaultNetworkConfig())
+}
+
+// NewNetworkBusWithConfig - returns a new network bus object at the server address and path
+// with the transport settings in config
+func NewNetworkBusWithConfig(address, path string, config *NetworkConfig) *NetworkBus {
 	bus := new(NetworkBus)
 	bus.sharedBus = New()
-	bus.Server = NewServer(address, path, bus.sharedBus)
-	bus.Client = NewClient(address, path, bus.sharedBus)
-	bus.service = &NetworkBusService{&sync.WaitGroup{}, false}
+	bus.Server = NewServerWithConfig(address, path, bus.sharedBus, config)
+	bus.Client = NewClientWithConfig(address, path, bus.sharedBus, config)
+	bus.service = &NetworkBusService{false}
 	bus.address = address
 	bus.path = path
+	bus.config = config
 	return bus
 }
 
@@ -39,49 +43,38 @@ func (networkBus *NetworkBus) EventBus() Bus {
 
 // NetworkBusService - object capable of serving the network bus
 type NetworkBusService struct {
-	wg      *sync.WaitGroup
 	started bool
 }
 
-// Start - helper method to serve a network bus service
+// Start - serves the server and client services of the network bus on one listener
 func (networkBus *NetworkBus) Start() error {
-	var err error
 	service := networkBus.service
-	clientService := networkBus.Client.service
-	serverService := networkBus.Server.service
-	if !service.started {
-		server := rpc.NewServer()
-		err := server.RegisterName("ServerService", serverService)
-		if err != nil {
-			fmt.Printf("Could not register name for serverService %v \n", err)
-		}
-		err = server.RegisterName("ClientService", clientService)
-		if err != nil {
-			fmt.Printf("Could not register name for clientService %v \n", err)
-		}
-		server.HandleHTTP(networkBus.path, "/debug"+networkBus.path)
-		l, e := net.Listen("tcp", networkBus.address)
-		if e != nil {
-			fmt.Printf("listen error: %v", e)
-		}
-		service.wg.Add(1)
-		go func() {
-			err := http.Serve(l, nil)
-			if err != nil {
-				fmt.Printf("Could not serve http %v \n", err)
-			}
-		}()
-	} else {
-		err = errors.New("Server bus already started")
+	if service.started {
+		return errors.New("Server bus already started")
 	}
-	return err
+	listener, err := listenRPC(networkBus.address, networkBus.path, networkBus.config, map[string]interface{}{
+		"ServerService": networkBus.Server.service,
+		"ClientService": networkBus.Client.service,
+	})
+	if err != nil {
+		return err
+	}
+	networkBus.listener = listener
+	networkBus.Server.start()
+	networkBus.Client.start()
+	service.started = true
+	return nil
 }
 
 // Stop - signal for the service to stop serving
 func (networkBus *NetworkBus) Stop() {
 	service := networkBus.service
-	if service.started {
-		service.wg.Done()
-		service.started = false
+	if !service.started {
+		return
 	}
+	service.started = false
+	networkBus.listener.close()
+	networkBus.listener = nil
+	networkBus.Client.Stop()
+	networkBus.Server.Stop()
 }
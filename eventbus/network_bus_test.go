@@ -1,7 +1,18 @@
 package eventbus
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewServer(t *testing.T) {
@@ -113,3 +124,290 @@ func TestNetworkBus(t *testing.T) {
 	networkBusA.Stop()
 	networkBusB.Stop()
 }
+
+func testNetworkConfig() *NetworkConfig {
+	config := DefaultNetworkConfig()
+	config.HandshakeTimeout = time.Second
+	config.CallTimeout = time.Second
+	config.HeartbeatInterval = 50 * time.Millisecond
+	config.HeartbeatTimeout = 300 * time.Millisecond
+	return config
+}
+
+func expectEvent(t *testing.T, events <-chan interface{}, expected interface{}) {
+	select {
+	case event := <-events:
+		if event != expected {
+			t.Fatalf("expected event %v, got %v", expected, event)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("did not receive event %v", expected)
+	}
+}
+
+func channelHandler(events chan interface{}) func(interface{}) {
+	return func(data interface{}) {
+		events <- data
+	}
+}
+
+func TestSharedKeyAuthentication(t *testing.T) {
+	serverConfig := testNetworkConfig()
+	serverConfig.SharedKey = []byte("shared key of the test")
+	serverBus := NewServerWithConfig("localhost:2040", "/_auth_server_", New(), serverConfig)
+	if err := serverBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer serverBus.Stop()
+
+	clientConfig := testNetworkConfig()
+	clientConfig.SharedKey = []byte("shared key of the test")
+	clientBus := NewClientWithConfig("localhost:2041", "/_auth_client_", New(), clientConfig)
+	if err := clientBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer clientBus.Stop()
+
+	events := make(chan interface{}, 1)
+	if err := clientBus.Subscribe("topic", channelHandler(events), "localhost:2040", "/_auth_server_"); err != nil {
+		t.Fatal(err)
+	}
+	serverBus.EventBus().Publish("topic", 10)
+	expectEvent(t, events, 10)
+
+	wrongConfig := testNetworkConfig()
+	wrongConfig.SharedKey = []byte("wrong key of the test")
+	wrongClient := NewClientWithConfig("localhost:2042", "/_auth_wrong_", New(), wrongConfig)
+	err := wrongClient.Subscribe("topic", func(interface{}) {}, "localhost:2040", "/_auth_server_")
+	if err == nil || !strings.Contains(err.Error(), ErrUnauthenticated.Error()) {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+
+	err = clientBus.Subscribe("topic", func(interface{}) {}, "localhost:2040", "/_other_path_")
+	if err == nil || !strings.Contains(err.Error(), ErrUnknownPath.Error()) {
+		t.Fatalf("expected unknown path error, got %v", err)
+	}
+}
+
+func testTLSConfig(t *testing.T, withCertificate bool) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	config := &tls.Config{
+		RootCAs:    pool,
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}
+	if withCertificate {
+		config.Certificates = []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}
+	}
+	return config
+}
+
+func TestMutualTLS(t *testing.T) {
+	config := testNetworkConfig()
+	config.TLSConfig = testTLSConfig(t, true)
+
+	busA := NewNetworkBusWithConfig("localhost:2045", "/_tls_bus_A", config)
+	if err := busA.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer busA.Stop()
+	busB := NewNetworkBusWithConfig("localhost:2046", "/_tls_bus_B", config)
+	if err := busB.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer busB.Stop()
+
+	events := make(chan interface{}, 1)
+	if err := busA.Subscribe("topic", channelHandler(events), "localhost:2046", "/_tls_bus_B"); err != nil {
+		t.Fatal(err)
+	}
+	busB.EventBus().Publish("topic", "over tls")
+	expectEvent(t, events, "over tls")
+
+	// trusts the server but has no client certificate
+	noCertConfig := testNetworkConfig()
+	noCertConfig.TLSConfig = config.TLSConfig.Clone()
+	noCertConfig.TLSConfig.Certificates = nil
+	noCertClient := NewClientWithConfig("localhost:2047", "/_tls_no_cert_", New(), noCertConfig)
+	if err := noCertClient.Subscribe("topic", func(interface{}) {}, "localhost:2046", "/_tls_bus_B"); err == nil {
+		t.Fatal("expected a client without certificate to be refused")
+	}
+}
+
+func TestResubscribeAfterServerRestart(t *testing.T) {
+	serverBus := NewServerWithConfig("localhost:2050", "/_restart_server_", New(), testNetworkConfig())
+	if err := serverBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	clientBus := NewClientWithConfig("localhost:2051", "/_restart_client_", New(), testNetworkConfig())
+	if err := clientBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer clientBus.Stop()
+
+	events := make(chan interface{}, 10)
+	if err := clientBus.Subscribe("topic", channelHandler(events), "localhost:2050", "/_restart_server_"); err != nil {
+		t.Fatal(err)
+	}
+	serverBus.EventBus().Publish("topic", 1)
+	expectEvent(t, events, 1)
+
+	serverBus.Stop()
+	restartedBus := NewServerWithConfig("localhost:2050", "/_restart_server_", New(), testNetworkConfig())
+	if err := restartedBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer restartedBus.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !restartedBus.EventBus().HasCallback("topic") {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not resubscribe after the server restarted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	restartedBus.EventBus().Publish("topic", 2)
+	expectEvent(t, events, 2)
+}
+
+func TestExpireClients(t *testing.T) {
+	serverBus := NewServerWithConfig("localhost:2055", "/_expire_server_", New(), testNetworkConfig())
+	if err := serverBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer serverBus.Stop()
+
+	clientBus := NewClientWithConfig("localhost:2056", "/_expire_client_", New(), testNetworkConfig())
+	if err := clientBus.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := clientBus.Subscribe("topic", func(interface{}) {}, "localhost:2055", "/_expire_server_"); err != nil {
+		t.Fatal(err)
+	}
+
+	// heartbeats keep the subscription alive past the timeout
+	time.Sleep(500 * time.Millisecond)
+	if !serverBus.EventBus().HasCallback("topic") {
+		t.Fatal("subscription expired while the client was sending heartbeats")
+	}
+
+	clientBus.Stop()
+	deadline := time.Now().Add(3 * time.Second)
+	for serverBus.EventBus().HasCallback("topic") {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription of a stopped client was not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type codecEvent struct {
+	Name  string
+	Count int
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("codec-topic", GobCodec(codecEvent{}))
+
+	busA := NewNetworkBusWithConfig("localhost:2060", "/_codec_bus_A", testNetworkConfig())
+	if err := busA.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer busA.Stop()
+	busB := NewNetworkBusWithConfig("localhost:2061", "/_codec_bus_B", testNetworkConfig())
+	if err := busB.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer busB.Stop()
+
+	events := make(chan interface{}, 1)
+	if err := busA.Subscribe("codec-topic", channelHandler(events), "localhost:2061", "/_codec_bus_B"); err != nil {
+		t.Fatal(err)
+	}
+	busB.EventBus().Publish("codec-topic", codecEvent{"keygen", 3})
+	expectEvent(t, events, codecEvent{"keygen", 3})
+
+	if _, err := encodeEvent("codec-topic", 3); err == nil {
+		t.Fatal("expected the codec to refuse events of another type")
+	}
+	if _, err := encodeEvent("topic", codecEvent{}); err == nil {
+		t.Fatal("expected unregistered types to be refused without a codec")
+	}
+}
+
+func TestNetworkConfigRequiresKeyOrTLSForRemotes(t *testing.T) {
+	config := testNetworkConfig()
+	config.SharedKey = []byte{}
+	if _, err := listenRPC("localhost:2070", "/_empty_key_", config, nil); err == nil {
+		t.Fatal("expected an empty shared key to be refused")
+	}
+
+	pool := newRPCPool(testNetworkConfig())
+	if _, err := pool.dial("192.0.2.1:2071", "/_remote_"); err != ErrInsecureRemote {
+		t.Fatalf("expected dialling another host without TLS or a shared key to be refused, got %v", err)
+	}
+}
+
+func TestFramedConnRejectsTamperedFrames(t *testing.T) {
+	key := []byte("shared key of the test")
+	clientNonce, _ := randomNonce()
+	serverNonce, _ := randomNonce()
+
+	clientEnd, relayIn := net.Pipe()
+	relayOut, serverEnd := net.Pipe()
+	client := newFramedConn(clientEnd, key, "client", "/_framed_", clientNonce, serverNonce)
+	server := newFramedConn(serverEnd, key, "server", "/_framed_", clientNonce, serverNonce)
+
+	// the relay flips a bit of the second frame it forwards
+	go func() {
+		buf := make([]byte, 4+len("first")+32)
+		for frame := 0; frame < 2; frame++ {
+			if _, err := io.ReadFull(relayIn, buf); err != nil {
+				return
+			}
+			if frame == 1 {
+				buf[5] ^= 1
+			}
+			if _, err := relayOut.Write(buf); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		_, _ = client.Write([]byte("first"))
+		_, _ = client.Write([]byte("other"))
+	}()
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(server, buf); err != nil || string(buf) != "first" {
+		t.Fatalf("expected the first frame, got %q %v", buf, err)
+	}
+	if _, err := io.ReadFull(server, buf); err != ErrBadFrame {
+		t.Fatalf("expected a tampered frame to be rejected, got %v", err)
+	}
+}
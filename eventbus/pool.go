@@ -0,0 +1,169 @@
+package eventbus
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy - what Publish does with an event for async handlers when the queue of the topic is full
+type OverflowPolicy int
+
+const (
+	// Block - Publish waits until a worker takes an event from the queue
+	Block OverflowPolicy = iota
+	// DropOldest - the oldest queued event is dropped to make space
+	DropOldest
+	// Reject - the published event is dropped
+	Reject
+)
+
+// TopicConfig - bounds the async handlers of a topic
+type TopicConfig struct {
+	// Workers bounds the async handlers of the topic running at once,
+	// 0 runs every handler in its own goroutine
+	Workers int
+	// QueueSize bounds the events waiting for a worker
+	QueueSize int
+	// Overflow is applied when all workers are busy and the queue is full
+	Overflow OverflowPolicy
+	// OnDrop is called in its own goroutine with every event that is dropped
+	OnDrop func(topic string, data interface{})
+}
+
+// Metrics - receives the per topic metrics of a bus, topics are those of the events
+// so implementations should group topics that are not fixed, such as response IDs
+type Metrics interface {
+	// HandlerStarted - an async handler started running
+	HandlerStarted(topic string)
+	// HandlerFinished - an async handler returned after running for d
+	HandlerFinished(topic string, d time.Duration)
+	// Dropped - an event for an async handler was dropped by the overflow policy
+	Dropped(topic string)
+	// QueueDepth - the number of events of the topic waiting for a worker changed
+	QueueDepth(topic string, depth int)
+}
+
+// Config - settings of an EventBus
+type Config struct {
+	// Default applies to topics without an entry in Topics
+	Default TopicConfig
+	// Topics are matched exactly, keys ending in * match topics starting with
+	// the rest of the key and the longest matching key is used
+	Topics  map[string]TopicConfig
+	Metrics Metrics
+}
+
+// TopicConfig - returns the settings of topic
+func (config Config) TopicConfig(topic string) TopicConfig {
+	if topicConfig, ok := config.Topics[topic]; ok {
+		return topicConfig
+	}
+	match := ""
+	topicConfig := config.Default
+	for key, prefixConfig := range config.Topics {
+		if !strings.HasSuffix(key, "*") || len(key) <= len(match) {
+			continue
+		}
+		if strings.HasPrefix(topic, strings.TrimSuffix(key, "*")) {
+			match = key
+			topicConfig = prefixConfig
+		}
+	}
+	return topicConfig
+}
+
+type noMetrics struct{}
+
+func (noMetrics) HandlerStarted(string)                 {}
+func (noMetrics) HandlerFinished(string, time.Duration) {}
+func (noMetrics) Dropped(string)                        {}
+func (noMetrics) QueueDepth(string, int)                {}
+
+// asyncJob - an event for an async handler
+type asyncJob struct {
+	handler *eventHandler
+	topic   string
+	data    interface{}
+}
+
+// topicPool - runs the async handlers of a topic on at most config.Workers goroutines,
+// workers are started when events are published and exit when the queue is empty
+type topicPool struct {
+	config TopicConfig
+
+	lock    sync.Mutex
+	space   *sync.Cond
+	queue   []asyncJob
+	workers int
+}
+
+func newTopicPool(config TopicConfig) *topicPool {
+	pool := &topicPool{config: config}
+	pool.space = sync.NewCond(&pool.lock)
+	return pool
+}
+
+// submit - runs, queues or drops job. It is called with the bus locked, the bus
+// is unlocked while waiting for space so that handlers can use it
+func (pool *topicPool) submit(bus *EventBus, job asyncJob) {
+	pool.lock.Lock()
+	for {
+		if pool.workers < pool.config.Workers {
+			pool.workers++
+			pool.lock.Unlock()
+			go pool.work(bus, job)
+			return
+		}
+		if len(pool.queue) < pool.config.QueueSize {
+			pool.queue = append(pool.queue, job)
+			bus.metrics.QueueDepth(job.topic, len(pool.queue))
+			pool.lock.Unlock()
+			return
+		}
+		switch pool.config.Overflow {
+		case DropOldest:
+			if len(pool.queue) == 0 {
+				pool.lock.Unlock()
+				bus.drop(pool.config, job)
+				return
+			}
+			dropped := pool.queue[0]
+			pool.queue = append(pool.queue[1:], job)
+			pool.lock.Unlock()
+			bus.drop(pool.config, dropped)
+			return
+		case Reject:
+			pool.lock.Unlock()
+			bus.drop(pool.config, job)
+			return
+		default:
+			bus.lock.Unlock()
+			pool.space.Wait()
+			// the bus lock is taken before the pool lock everywhere else
+			pool.lock.Unlock()
+			bus.lock.Lock()
+			pool.lock.Lock()
+		}
+	}
+}
+
+// work - runs job and then the queued jobs until the queue is empty
+func (pool *topicPool) work(bus *EventBus, job asyncJob) {
+	for {
+		bus.runAsync(job)
+		pool.lock.Lock()
+		if len(pool.queue) == 0 {
+			pool.workers--
+			pool.space.Broadcast()
+			pool.lock.Unlock()
+			return
+		}
+		job = pool.queue[0]
+		pool.queue[0] = asyncJob{}
+		pool.queue = pool.queue[1:]
+		bus.metrics.QueueDepth(job.topic, len(pool.queue))
+		pool.space.Broadcast()
+		pool.lock.Unlock()
+	}
+}
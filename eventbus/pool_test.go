@@ -0,0 +1,212 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	lock     sync.Mutex
+	started  map[string]int
+	finished map[string]int
+	dropped  map[string]int
+	depth    map[string]int
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		started:  make(map[string]int),
+		finished: make(map[string]int),
+		dropped:  make(map[string]int),
+		depth:    make(map[string]int),
+	}
+}
+
+func (m *testMetrics) HandlerStarted(topic string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.started[topic]++
+}
+
+func (m *testMetrics) HandlerFinished(topic string, d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.finished[topic]++
+}
+
+func (m *testMetrics) Dropped(topic string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.dropped[topic]++
+}
+
+func (m *testMetrics) QueueDepth(topic string, depth int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.depth[topic] = depth
+}
+
+func TestBoundedWorkers(t *testing.T) {
+	metrics := newTestMetrics()
+	bus := NewWithConfig(Config{
+		Default: TopicConfig{Workers: 2, QueueSize: 100, Overflow: Block},
+		Metrics: metrics,
+	})
+	var running, maxRunning, count int32
+	_ = bus.SubscribeAsync("topic", func(interface{}) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&count, 1)
+		atomic.AddInt32(&running, -1)
+	}, false)
+	for i := 0; i < 20; i++ {
+		bus.Publish("topic", i)
+	}
+	bus.WaitAsync()
+	if count != 20 {
+		t.Fatalf("expected 20 handled events, got %v", count)
+	}
+	if maxRunning > 2 {
+		t.Fatalf("expected at most 2 handlers at once, got %v", maxRunning)
+	}
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	if metrics.started["topic"] != 20 || metrics.finished["topic"] != 20 || metrics.depth["topic"] != 0 {
+		t.Fatalf("unexpected metrics started %v finished %v depth %v", metrics.started, metrics.finished, metrics.depth)
+	}
+}
+
+// blockingBus returns a bus whose topic has one worker and a queue of one event, and a
+// handler that records events and waits for release to be closed
+func blockingBus(overflow OverflowPolicy, metrics Metrics, onDrop func(string, interface{})) (Bus, chan struct{}, func() []interface{}) {
+	bus := NewWithConfig(Config{
+		Topics:  map[string]TopicConfig{"topic": {Workers: 1, QueueSize: 1, Overflow: overflow, OnDrop: onDrop}},
+		Metrics: metrics,
+	})
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	var lock sync.Mutex
+	var handled []interface{}
+	_ = bus.SubscribeAsync("topic", func(data interface{}) {
+		started <- struct{}{}
+		<-release
+		lock.Lock()
+		handled = append(handled, data)
+		lock.Unlock()
+	}, false)
+	bus.Publish("topic", 0)
+	<-started
+	return bus, release, func() []interface{} {
+		lock.Lock()
+		defer lock.Unlock()
+		return handled
+	}
+}
+
+func TestOverflowReject(t *testing.T) {
+	metrics := newTestMetrics()
+	dropped := make(chan interface{}, 10)
+	bus, release, handled := blockingBus(Reject, metrics, func(topic string, data interface{}) {
+		dropped <- data
+	})
+	for i := 1; i < 5; i++ {
+		bus.Publish("topic", i)
+	}
+	close(release)
+	bus.WaitAsync()
+
+	if events := handled(); len(events) != 2 || events[0] != 0 || events[1] != 1 {
+		t.Fatalf("expected events 0 and 1 to be handled, got %v", events)
+	}
+	// OnDrop runs in its own goroutine, so the dropped events arrive in any order
+	droppedEvents := make(map[interface{}]bool)
+	for i := 2; i < 5; i++ {
+		select {
+		case data := <-dropped:
+			droppedEvents[data] = true
+		case <-time.After(time.Second):
+			t.Fatal("OnDrop was not called")
+		}
+	}
+	if !droppedEvents[2] || !droppedEvents[3] || !droppedEvents[4] {
+		t.Fatalf("expected events 2, 3 and 4 to be dropped, got %v", droppedEvents)
+	}
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	if metrics.dropped["topic"] != 3 {
+		t.Fatalf("expected 3 dropped events, got %v", metrics.dropped["topic"])
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	bus, release, handled := blockingBus(DropOldest, nil, nil)
+	for i := 1; i < 5; i++ {
+		bus.Publish("topic", i)
+	}
+	close(release)
+	bus.WaitAsync()
+
+	if events := handled(); len(events) != 2 || events[0] != 0 || events[1] != 4 {
+		t.Fatalf("expected events 0 and 4 to be handled, got %v", events)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	bus, release, handled := blockingBus(Block, nil, nil)
+	bus.Publish("topic", 1)
+
+	published := make(chan struct{})
+	go func() {
+		bus.Publish("topic", 2)
+		close(published)
+	}()
+	select {
+	case <-published:
+		t.Fatal("expected Publish to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// the bus is usable by others while a publisher is blocked
+	if !bus.HasCallback("topic") {
+		t.Fatal("expected the topic to have a callback")
+	}
+
+	close(release)
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish stayed blocked after the queue was emptied")
+	}
+	bus.WaitAsync()
+	if events := handled(); len(events) != 3 {
+		t.Fatalf("expected 3 handled events, got %v", events)
+	}
+}
+
+func TestTopicConfig(t *testing.T) {
+	config := Config{
+		Default: TopicConfig{Workers: 1},
+		Topics: map[string]TopicConfig{
+			"p2p:*":           {Workers: 2},
+			"p2p:forward:*":   {Workers: 3},
+			"p2p:forward:abc": {Workers: 4},
+		},
+	}
+	for topic, workers := range map[string]int{
+		"method":          1,
+		"p2p:other":       2,
+		"p2p:forward:xyz": 3,
+		"p2p:forward:abc": 4,
+	} {
+		if got := config.TopicConfig(topic).Workers; got != workers {
+			t.Fatalf("expected %v workers for %v, got %v", workers, topic, got)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const recordingMagic = "torus-eventbus-recording/1"
+
+// recordingHeader - first value of every recording
+type recordingHeader struct {
+	Magic   string
+	Started time.Time
+}
+
+// Record - an event published on a recorded bus
+type Record struct {
+	Seq   uint64
+	Time  time.Time
+	Topic string
+	Event EncodedEvent
+	// Text describes events that could not be encoded, these can not be replayed
+	Text string
+}
+
+// Replayable - true if the event of the record was encoded
+func (record Record) Replayable() bool {
+	return record.Text == ""
+}
+
+// Decode - decodes the event with codec, or the codec registered for the topic if codec is nil
+func (record Record) Decode(codec Codec) (interface{}, error) {
+	if !record.Replayable() {
+		return nil, fmt.Errorf("event %v on topic %v was not encoded: %v", record.Seq, record.Topic, record.Text)
+	}
+	if codec == nil {
+		return decodeEvent(record.Topic, record.Event)
+	}
+	data, err := codec.Unmarshal(record.Event.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event on topic %v: %v", record.Topic, err)
+	}
+	return data, nil
+}
+
+// Recorder - writes every event published on a bus to a recording
+type Recorder struct {
+	codec Codec
+
+	lock   sync.Mutex
+	writer *bufio.Writer
+	closer io.Closer
+	enc    *gob.Encoder
+	seq    uint64
+	err    error
+
+	observer func(string, interface{})
+}
+
+// NewRecorder - creates a recorder writing to w. Events are encoded with codec, or the codec
+// registered for their topic if codec is nil. If w is an io.Closer it is closed by Close
+func NewRecorder(w io.Writer, codec Codec) (*Recorder, error) {
+	recorder := &Recorder{
+		codec:  codec,
+		writer: bufio.NewWriter(w),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		recorder.closer = closer
+	}
+	recorder.enc = gob.NewEncoder(recorder.writer)
+	if err := recorder.enc.Encode(recordingHeader{recordingMagic, time.Now()}); err != nil {
+		return nil, err
+	}
+	if err := recorder.writer.Flush(); err != nil {
+		return nil, err
+	}
+	recorder.observer = recorder.Record
+	return recorder, nil
+}
+
+// Observer - bus observer recording every event once as it is published, including events without handlers
+func (recorder *Recorder) Observer() *func(string, interface{}) {
+	return &recorder.observer
+}
+
+func (recorder *Recorder) encode(topic string, data interface{}) (EncodedEvent, error) {
+	if recorder.codec == nil {
+		return encodeEvent(topic, data)
+	}
+	b, err := recorder.codec.Marshal(data)
+	if err != nil {
+		return EncodedEvent{}, fmt.Errorf("could not encode event on topic %v: %v", topic, err)
+	}
+	return EncodedEvent{b}, nil
+}
+
+// Record - appends an event to the recording, events that can not be encoded are kept as text.
+// The record is flushed before Record returns so that the recording survives a crash
+func (recorder *Recorder) Record(topic string, data interface{}) {
+	event, err := recorder.encode(topic, data)
+	record := Record{Time: time.Now(), Topic: topic, Event: event}
+	if err != nil {
+		record.Event = EncodedEvent{}
+		record.Text = fmt.Sprintf("%v (%T)", err, data)
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if recorder.err != nil {
+		return
+	}
+	recorder.seq++
+	record.Seq = recorder.seq
+	if err := recorder.enc.Encode(record); err != nil {
+		recorder.err = err
+		fmt.Printf("eventbus: stopped recording: %v \n", err)
+		return
+	}
+	if err := recorder.writer.Flush(); err != nil {
+		recorder.err = err
+		fmt.Printf("eventbus: stopped recording: %v \n", err)
+	}
+}
+
+// Close - flushes the recording and closes the underlying writer, events recorded afterwards are dropped
+func (recorder *Recorder) Close() error {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if recorder.err == nil {
+		recorder.err = errors.New("recorder closed")
+	}
+	err := recorder.writer.Flush()
+	if recorder.closer != nil {
+		if closeErr := recorder.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ReadRecords - reads a recording written by a Recorder. A recording that was cut off while
+// writing returns the records read before the cut
+func ReadRecords(r io.Reader) ([]Record, error) {
+	dec := gob.NewDecoder(bufio.NewReader(r))
+	var header recordingHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("could not read recording header: %v", err)
+	}
+	if header.Magic != recordingMagic {
+		return nil, fmt.Errorf("not an eventbus recording")
+	}
+	var records []Record
+	for {
+		var record Record
+		err := dec.Decode(&record)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// ReplayConfig - settings of Replay
+type ReplayConfig struct {
+	// Codec decodes the events, defaults to the codecs registered for their topics
+	Codec Codec
+	// Filter selects the records to publish, all replayable records if nil
+	Filter func(record Record, data interface{}) bool
+	// Speed scales the time between events relative to the recording, 2 replays twice as
+	// fast. 0 publishes the events without waiting
+	Speed float64
+	// Stop ends the replay early when it is closed
+	Stop <-chan struct{}
+}
+
+// Replay - publishes the recorded events on bus in the order they were recorded
+func Replay(bus Bus, records []Record, config ReplayConfig) error {
+	var previous time.Time
+	for _, record := range records {
+		if !record.Replayable() {
+			continue
+		}
+		data, err := record.Decode(config.Codec)
+		if err != nil {
+			return err
+		}
+		if config.Filter != nil && !config.Filter(record, data) {
+			continue
+		}
+		if config.Speed > 0 && !previous.IsZero() {
+			wait := time.Duration(float64(record.Time.Sub(previous)) / config.Speed)
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-config.Stop:
+					return nil
+				}
+			}
+		}
+		select {
+		case <-config.Stop:
+			return nil
+		default:
+		}
+		previous = record.Time
+		bus.Publish(record.Topic, data)
+	}
+	return nil
+}
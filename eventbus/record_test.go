@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type recordedEvent struct {
+	Name string
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	RegisterType(recordedEvent{})
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := New()
+	bus.AddObserver(recorder.Observer())
+	// events are recorded once however many handlers they have
+	for _, topic := range []string{"first", "first", "second", "third"} {
+		_ = bus.Subscribe(topic, func(interface{}) {})
+	}
+	bus.Publish("unsubscribed", 0)
+	bus.Publish("first", recordedEvent{"a"})
+	bus.Publish("second", 2)
+	// channels can not be encoded, they are kept as text
+	bus.Publish("third", make(chan int))
+	bus.Publish("first", recordedEvent{"b"})
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("first", recordedEvent{"after close"})
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %v", len(records))
+	}
+	for i, record := range records {
+		if record.Seq != uint64(i+1) {
+			t.Fatalf("expected sequence %v, got %v", i+1, record.Seq)
+		}
+	}
+	if records[3].Replayable() || records[3].Text == "" {
+		t.Fatal("expected the channel to be recorded as text")
+	}
+
+	replayBus := New()
+	var replayed []interface{}
+	_ = replayBus.Subscribe("first", func(data interface{}) { replayed = append(replayed, data) })
+	_ = replayBus.Subscribe("second", func(data interface{}) { replayed = append(replayed, data) })
+	_ = replayBus.Subscribe("third", func(data interface{}) { t.Fatal("replayed an unencoded event") })
+	err = Replay(replayBus, records, ReplayConfig{
+		Filter: func(record Record, data interface{}) bool {
+			return data != recordedEvent{"b"}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 2 || replayed[0] != (recordedEvent{"a"}) || replayed[1] != 2 {
+		t.Fatalf("unexpected replayed events %v", replayed)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	start := time.Now()
+	records := make([]Record, 3)
+	for i := range records {
+		event, err := encodeEvent("topic", i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records[i] = Record{Seq: uint64(i + 1), Time: start.Add(time.Duration(i) * 100 * time.Millisecond), Topic: "topic", Event: event}
+	}
+
+	replayStart := time.Now()
+	if err := Replay(New(), records, ReplayConfig{Speed: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(replayStart); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("expected the replay to take about 100ms, took %v", elapsed)
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	count := 0
+	bus := New()
+	_ = bus.Subscribe("topic", func(interface{}) { count++ })
+	if err := Replay(bus, records, ReplayConfig{Speed: 1, Stop: stop}); err != nil {
+		t.Fatal(err)
+	}
+	if count > 1 {
+		t.Fatalf("expected the replay to stop, published %v events", count)
+	}
+}
+
+func TestReadTruncatedRecording(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf, GobCodec(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Record("topic", "one")
+	recorder.Record("topic", "two")
+	truncated := buf.Bytes()[:buf.Len()-3]
+
+	records, err := ReadRecords(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 complete record, got %v", len(records))
+	}
+	data, err := records[0].Decode(GobCodec(""))
+	if err != nil || data != "one" {
+		t.Fatalf("expected one, got %v %v", data, err)
+	}
+
+	if _, err := ReadRecords(bytes.NewReader([]byte("not a recording"))); err == nil {
+		t.Fatal("expected an error reading something that is not a recording")
+	}
+}
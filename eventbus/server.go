@@ -3,10 +3,8 @@ package eventbus
 import (
 	"errors"
 	"fmt"
-	"net"
-	"net/http"
-	"net/rpc"
 	"sync"
+	"time"
 )
 
 // SubscribeType - how the client intends to subscribe
@@ -22,6 +20,8 @@ const (
 const (
 	// RegisterService - Server subscribe service method
 	RegisterService = "ServerService.Register"
+	// HeartbeatService - Server heartbeat service method
+	HeartbeatService = "ServerService.Heartbeat"
 )
 
 // SubscribeArg - object to hold subscribe arguments from remote event handlers
@@ -33,23 +33,71 @@ type SubscribeArg struct {
 	Topic         string
 }
 
+// HeartbeatArg - identifies the client sending a heartbeat
+type HeartbeatArg struct {
+	ClientAddr string
+	ClientPath string
+}
+
+// HeartbeatReply - identifies the running server, a new ServerID means that the
+// server restarted and lost its subscriptions
+type HeartbeatReply struct {
+	ServerID string
+}
+
 // Server - object capable of being subscribed to by remote handlers
 type Server struct {
 	eventBus    Bus
 	address     string
 	path        string
-	subscribers map[string][]*SubscribeArg
+	id          string
+	config      *NetworkConfig
+	pool        *rpcPool
+	listener    *rpcListener
+	lock        sync.Mutex
+	subscribers map[string][]*subscriber
+	clients     map[string]*remoteClient
+	stop        chan struct{}
 	service     *ServerService
 }
 
+// subscriber - a remote subscription and its callback on the local event bus
+type subscriber struct {
+	arg      SubscribeArg
+	callback func(interface{})
+}
+
+// remoteClient - queue of events for a subscribed client
+type remoteClient struct {
+	address  string
+	path     string
+	lastSeen time.Time
+	events   chan remoteEvent
+	done     chan struct{}
+}
+
+type remoteEvent struct {
+	serviceMethod string
+	arg           *ClientArg
+}
+
 // NewServer - create a new Server at the address and path
 func NewServer(address, path string, eventBus Bus) *Server {
+	return NewServerWithConfig(address, path, eventBus, DefaultNetworkConfig())
+}
+
+// NewServerWithConfig - create a new Server at the address and path with the transport settings in config
+func NewServerWithConfig(address, path string, eventBus Bus, config *NetworkConfig) *Server {
 	server := new(Server)
 	server.eventBus = eventBus
 	server.address = address
 	server.path = path
-	server.subscribers = make(map[string][]*SubscribeArg)
-	server.service = &ServerService{server, &sync.WaitGroup{}, false}
+	server.id = randomID()
+	server.config = config
+	server.pool = newRPCPool(config)
+	server.subscribers = make(map[string][]*subscriber)
+	server.clients = make(map[string]*remoteClient)
+	server.service = &ServerService{server, false}
 	return server
 }
 
@@ -58,78 +106,181 @@ func (server *Server) EventBus() Bus {
 	return server.eventBus
 }
 
-func (server *Server) rpcCallback(subscribeArg *SubscribeArg) func(data interface{}) {
+func (server *Server) rpcCallback(subscribeArg *SubscribeArg, client *remoteClient) func(data interface{}) {
 	return func(data interface{}) {
-		client, connErr := rpc.DialHTTPPath("tcp", subscribeArg.ClientAddr, subscribeArg.ClientPath)
-		if connErr != nil {
-			fmt.Printf("dialing: %v", connErr)
-		}
-		defer client.Close()
-		clientArg := new(ClientArg)
-		clientArg.Topic = subscribeArg.Topic
-		clientArg.Data = data
-		var reply bool
-		err := client.Call(subscribeArg.ServiceMethod, clientArg, &reply)
+		event, err := encodeEvent(subscribeArg.Topic, data)
 		if err != nil {
-			fmt.Printf("dialing: %v", err)
+			fmt.Printf("eventbus: %v \n", err)
+			return
+		}
+		select {
+		case client.events <- remoteEvent{subscribeArg.ServiceMethod, &ClientArg{event, subscribeArg.Topic}}:
+		default:
+			fmt.Printf("eventbus: queue for %v%v is full, dropped event on topic %v \n", client.address, client.path, subscribeArg.Topic)
+		}
+	}
+}
+
+// deliver - sends the queued events of client in order, retrying each up to DeliveryAttempts times
+func (server *Server) deliver(client *remoteClient) {
+	for {
+		select {
+		case <-client.done:
+			return
+		case event := <-client.events:
+			for attempt := 1; ; attempt++ {
+				var reply bool
+				err := server.pool.call(client.address, client.path, event.serviceMethod, event.arg, &reply)
+				if err == nil {
+					break
+				}
+				if attempt >= server.config.DeliveryAttempts {
+					fmt.Printf("eventbus: dropped event on topic %v for %v%v: %v \n", event.arg.Topic, client.address, client.path, err)
+					break
+				}
+				select {
+				case <-client.done:
+					return
+				case <-time.After(time.Duration(attempt) * time.Second):
+				}
+			}
 		}
 	}
 }
 
 // HasClientSubscribed - True if a client subscribed to this server with the same topic
 func (server *Server) HasClientSubscribed(arg *SubscribeArg) bool {
-	if topicSubscribers, ok := server.subscribers[arg.Topic]; ok {
-		for _, topicSubscriber := range topicSubscribers {
-			if *topicSubscriber == *arg {
-				return true
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.findSubscriber(arg) != nil
+}
+
+func (server *Server) findSubscriber(arg *SubscribeArg) *subscriber {
+	for _, topicSubscriber := range server.subscribers[arg.Topic] {
+		if topicSubscriber.arg == *arg {
+			return topicSubscriber
+		}
+	}
+	return nil
+}
+
+func (server *Server) removeSubscriber(sub *subscriber) {
+	topicSubscribers := server.subscribers[sub.arg.Topic]
+	for i, topicSubscriber := range topicSubscribers {
+		if topicSubscriber == sub {
+			server.subscribers[sub.arg.Topic] = append(topicSubscribers[:i:i], topicSubscribers[i+1:]...)
+			break
+		}
+	}
+	if len(server.subscribers[sub.arg.Topic]) == 0 {
+		delete(server.subscribers, sub.arg.Topic)
+	}
+}
+
+// client - returns the queue of the client at address and path, starting it if needed
+func (server *Server) client(address, path string) *remoteClient {
+	key := address + path
+	client, ok := server.clients[key]
+	if !ok {
+		client = &remoteClient{
+			address: address,
+			path:    path,
+			events:  make(chan remoteEvent, server.config.QueueSize),
+			done:    make(chan struct{}),
+		}
+		server.clients[key] = client
+		go server.deliver(client)
+	}
+	client.lastSeen = time.Now()
+	return client
+}
+
+// removeClient - drops the subscriptions and queue of client
+func (server *Server) removeClient(client *remoteClient) {
+	for _, topicSubscribers := range server.subscribers {
+		for _, sub := range topicSubscribers {
+			if sub.arg.ClientAddr == client.address && sub.arg.ClientPath == client.path {
+				_ = server.eventBus.Unsubscribe(sub.arg.Topic, sub.callback)
+				server.removeSubscriber(sub)
 			}
 		}
 	}
-	return false
+	delete(server.clients, client.address+client.path)
+	close(client.done)
+}
+
+// expireClients - removes clients that have not sent a heartbeat within HeartbeatTimeout
+func (server *Server) expireClients() {
+	ticker := time.NewTicker(server.config.HeartbeatTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-server.stop:
+			return
+		case <-ticker.C:
+			server.lock.Lock()
+			for _, client := range server.clients {
+				if time.Since(client.lastSeen) > server.config.HeartbeatTimeout {
+					fmt.Printf("eventbus: client %v%v timed out, removing its subscriptions \n", client.address, client.path)
+					server.removeClient(client)
+				}
+			}
+			server.lock.Unlock()
+		}
+	}
 }
 
 // Start - starts a service for remote clients to subscribe to events
 func (server *Server) Start() error {
-	var err error
 	service := server.service
-	if !service.started {
-		rpcServer := rpc.NewServer()
-		err := rpcServer.Register(service)
-		if err != nil {
-			fmt.Printf("Could not register service %v \n", err)
-		}
-		rpcServer.HandleHTTP(server.path, "/debug"+server.path)
-		l, e := net.Listen("tcp", server.address)
-		if e != nil {
-			fmt.Printf("listen error: %v", e)
-		}
-		service.started = true
-		service.wg.Add(1)
-		go func() {
-			err := http.Serve(l, nil)
-			if err != nil {
-				fmt.Printf("Could not serve http %v \n", err)
-			}
-		}()
-	} else {
-		err = errors.New("Server bus already started")
+	if service.started {
+		return errors.New("Server bus already started")
+	}
+	listener, err := listenRPC(server.address, server.path, server.config, map[string]interface{}{
+		"ServerService": service,
+	})
+	if err != nil {
+		return err
+	}
+	server.listener = listener
+	server.start()
+	return nil
+}
+
+func (server *Server) start() {
+	server.service.started = true
+	server.lock.Lock()
+	server.id = randomID()
+	server.lock.Unlock()
+	server.stop = make(chan struct{})
+	if server.config.HeartbeatTimeout > 0 {
+		go server.expireClients()
 	}
-	return err
 }
 
 // Stop - signal for the service to stop serving
 func (server *Server) Stop() {
 	service := server.service
-	if service.started {
-		service.wg.Done()
-		service.started = false
+	if !service.started {
+		return
+	}
+	service.started = false
+	close(server.stop)
+	if server.listener != nil {
+		server.listener.close()
+		server.listener = nil
 	}
+	server.lock.Lock()
+	for _, client := range server.clients {
+		server.removeClient(client)
+	}
+	server.lock.Unlock()
+	server.pool.closeAll()
 }
 
 // ServerService - service object to listen to remote subscriptions
 type ServerService struct {
 	server  *Server
-	wg      *sync.WaitGroup
 	started bool
 }
 
@@ -137,30 +288,49 @@ type ServerService struct {
 // for a remote subscribe - a given client address only needs to subscribe once
 // event will be republished in local event bus
 func (service *ServerService) Register(arg *SubscribeArg, success *bool) error {
-	subscribers := service.server.subscribers
-	if !service.server.HasClientSubscribed(arg) {
-		rpcCallback := service.server.rpcCallback(arg)
+	server := service.server
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	client := server.client(arg.ClientAddr, arg.ClientPath)
+	if server.findSubscriber(arg) == nil {
+		sub := &subscriber{arg: *arg}
+		rpcCallback := server.rpcCallback(&sub.arg, client)
+		var err error
 		switch arg.SubscribeType {
 		case Subscribe:
-			err := service.server.eventBus.Subscribe(arg.Topic, rpcCallback)
-			if err != nil {
-				fmt.Printf("Could not subscribe %v \n", err)
-			}
+			sub.callback = rpcCallback
+			err = server.eventBus.Subscribe(arg.Topic, sub.callback)
 		case SubscribeOnce:
-			err := service.server.eventBus.SubscribeOnce(arg.Topic, rpcCallback)
-			if err != nil {
-				fmt.Printf("Could not subscribe %v \n", err)
+			sub.callback = func(data interface{}) {
+				rpcCallback(data)
+				// the event bus is locked while publishing, so the server lock is taken separately
+				go func() {
+					server.lock.Lock()
+					server.removeSubscriber(sub)
+					server.lock.Unlock()
+				}()
 			}
+			err = server.eventBus.SubscribeOnce(arg.Topic, sub.callback)
+		default:
+			err = fmt.Errorf("unknown subscribe type %v", arg.SubscribeType)
 		}
-		var topicSubscribers []*SubscribeArg
-		if _, ok := subscribers[arg.Topic]; ok {
-			topicSubscribers = []*SubscribeArg{arg}
-		} else {
-			topicSubscribers = subscribers[arg.Topic]
-			topicSubscribers = append(topicSubscribers, arg)
+		if err != nil {
+			return err
 		}
-		subscribers[arg.Topic] = topicSubscribers
+		server.subscribers[arg.Topic] = append(server.subscribers[arg.Topic], sub)
 	}
 	*success = true
 	return nil
 }
+
+// Heartbeat - keeps the subscriptions of a client alive and tells it which server instance is running
+func (service *ServerService) Heartbeat(arg *HeartbeatArg, reply *HeartbeatReply) error {
+	server := service.server
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	if client, ok := server.clients[arg.ClientAddr+arg.ClientPath]; ok {
+		client.lastSeen = time.Now()
+	}
+	reply.ServerID = server.id
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package faults injects network faults into the traffic of a node for chaos drills. Rules drop,
+// delay, duplicate or corrupt messages of a protocol, or partition the node from some of its
+// peers, and can be changed while the node runs.
+package faults
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Protocols whose traffic faults are injected into. Messages of the keygen, PSS and mapping
+// protocols are sent over p2p, BFT is what nodes broadcast to tendermint.
+const (
+	ProtocolKeygen  = "keygen"
+	ProtocolPSS     = "pss"
+	ProtocolMapping = "mapping"
+	ProtocolBFT     = "bft"
+)
+
+// Directions of traffic
+const (
+	Send    = "send"
+	Receive = "receive"
+)
+
+var protocols = map[string]bool{
+	ProtocolKeygen:  true,
+	ProtocolPSS:     true,
+	ProtocolMapping: true,
+	ProtocolBFT:     true,
+}
+
+// Rule is a fault injected into the messages it matches. Probabilities are between 0 and 1.
+type Rule struct {
+	// Protocol is the traffic the rule applies to, all traffic if empty
+	Protocol string `json:"protocol,omitempty"`
+	// Direction is send or receive, both if empty
+	Direction string `json:"direction,omitempty"`
+	// Peers limits the rule to messages to or from these peers, by their p2p peer ID. Such rules
+	// do not apply to gossip that is sent nor to BFT broadcasts, which go to no single peer.
+	Peers []string `json:"peers,omitempty"`
+
+	Drop      float64 `json:"drop,omitempty"`
+	DelayMS   int     `json:"delayMS,omitempty"`
+	JitterMS  int     `json:"jitterMS,omitempty"`
+	Duplicate float64 `json:"duplicate,omitempty"`
+	// Corrupt flips a bit of the payload of sent messages, which are signed again so that
+	// receivers pass them on to the protocol
+	Corrupt float64 `json:"corrupt,omitempty"`
+	// Partition drops every message to and from Peers
+	Partition bool `json:"partition,omitempty"`
+}
+
+func validProbability(p float64) bool {
+	return p >= 0 && p <= 1
+}
+
+// Validate returns an error if the rule can not be applied
+func (r Rule) Validate() error {
+	if r.Protocol != "" && !protocols[r.Protocol] {
+		return fmt.Errorf("unknown protocol %q", r.Protocol)
+	}
+	if r.Direction != "" && r.Direction != Send && r.Direction != Receive {
+		return fmt.Errorf("unknown direction %q", r.Direction)
+	}
+	if !validProbability(r.Drop) || !validProbability(r.Duplicate) || !validProbability(r.Corrupt) {
+		return errors.New("probabilities must be between 0 and 1")
+	}
+	if r.DelayMS < 0 || r.JitterMS < 0 {
+		return errors.New("delays must not be negative")
+	}
+	if r.Partition && len(r.Peers) == 0 {
+		return errors.New("a partition needs peers")
+	}
+	return nil
+}
+
+func (r Rule) matches(protocol, direction, peer string) bool {
+	if r.Protocol != "" && r.Protocol != protocol {
+		return false
+	}
+	if r.Direction != "" && r.Direction != direction {
+		return false
+	}
+	if len(r.Peers) == 0 {
+		return true
+	}
+	for _, p := range r.Peers {
+		if p == peer {
+			return true
+		}
+	}
+	return false
+}
+
+// Decision is what happens to a message
+type Decision struct {
+	Drop  bool
+	Delay time.Duration
+	// Duplicates is the number of extra copies delivered
+	Duplicates int
+	// Payload is the payload to deliver, a corrupted copy when Corrupted is set
+	Payload   []byte
+	Corrupted bool
+}
+
+// Stats counts the faults injected since the node started
+type Stats struct {
+	Dropped    int `json:"dropped"`
+	Delayed    int `json:"delayed"`
+	Duplicated int `json:"duplicated"`
+	Corrupted  int `json:"corrupted"`
+}
+
+// Injector decides the faults injected into each message by its rules
+type Injector struct {
+	lock  sync.Mutex
+	rules []Rule
+	stats Stats
+	rand  *rand.Rand
+}
+
+// NewInjector returns an injector without rules, which leaves messages alone
+func NewInjector() *Injector {
+	return &Injector{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SetRules replaces the rules of the injector, no rules stop injecting faults
+func (i *Injector) SetRules(rules []Rule) error {
+	for j, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %v: %v", j, err)
+		}
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.rules = append([]Rule(nil), rules...)
+	return nil
+}
+
+// Rules returns the rules of the injector
+func (i *Injector) Rules() []Rule {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return append([]Rule(nil), i.rules...)
+}
+
+// Stats returns the faults injected so far
+func (i *Injector) Stats() Stats {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.stats
+}
+
+// Apply decides the faults injected into a message of protocol going in direction to or from
+// peer, which is empty for messages that go to no single peer. The effects of every matching
+// rule add up.
+func (i *Injector) Apply(protocol, direction, peer string, payload []byte) Decision {
+	decision := Decision{Payload: payload}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, rule := range i.rules {
+		if !rule.matches(protocol, direction, peer) {
+			continue
+		}
+		if rule.Partition || i.chance(rule.Drop) {
+			decision.Drop = true
+		}
+		if rule.DelayMS > 0 || rule.JitterMS > 0 {
+			delay := rule.DelayMS
+			if rule.JitterMS > 0 {
+				delay += i.rand.Intn(rule.JitterMS + 1)
+			}
+			decision.Delay += time.Duration(delay) * time.Millisecond
+		}
+		if i.chance(rule.Duplicate) {
+			decision.Duplicates++
+		}
+		if direction == Send && !decision.Corrupted && len(payload) > 0 && i.chance(rule.Corrupt) {
+			corrupted := append([]byte(nil), payload...)
+			corrupted[i.rand.Intn(len(corrupted))] ^= 1 << uint(i.rand.Intn(8))
+			decision.Payload = corrupted
+			decision.Corrupted = true
+		}
+	}
+	switch {
+	case decision.Drop:
+		i.stats.Dropped++
+		return Decision{Drop: true}
+	case decision.Corrupted:
+		i.stats.Corrupted++
+	}
+	if decision.Delay > 0 {
+		i.stats.Delayed++
+	}
+	i.stats.Duplicated += decision.Duplicates
+	return decision
+}
+
+func (i *Injector) chance(p float64) bool {
+	return p > 0 && i.rand.Float64() < p
+}
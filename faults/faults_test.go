@@ -0,0 +1,82 @@
+package faults
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNoRules(t *testing.T) {
+	i := NewInjector()
+	payload := []byte("message")
+	d := i.Apply(ProtocolKeygen, Send, "peer", payload)
+	if d.Drop || d.Delay != 0 || d.Duplicates != 0 || d.Corrupted || !bytes.Equal(d.Payload, payload) {
+		t.Fatalf("expected the message to be left alone, got %+v", d)
+	}
+}
+
+func TestRules(t *testing.T) {
+	i := NewInjector()
+	err := i.SetRules([]Rule{
+		{Protocol: ProtocolPSS, Direction: Receive, Drop: 1},
+		{Protocol: ProtocolKeygen, DelayMS: 100, JitterMS: 50, Duplicate: 1},
+		{Protocol: ProtocolMapping, Corrupt: 1},
+		{Peers: []string{"partitioned"}, Partition: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := i.Apply(ProtocolPSS, Receive, "peer", []byte("m")); !d.Drop {
+		t.Fatal("expected received PSS messages to be dropped")
+	}
+	if d := i.Apply(ProtocolPSS, Send, "peer", []byte("m")); d.Drop {
+		t.Fatal("expected sent PSS messages to be delivered")
+	}
+	d := i.Apply(ProtocolKeygen, Send, "", []byte("m"))
+	if d.Delay < 100*time.Millisecond || d.Delay > 150*time.Millisecond || d.Duplicates != 1 {
+		t.Fatalf("expected keygen messages to be delayed and duplicated, got %+v", d)
+	}
+
+	payload := []byte("mapping message")
+	d = i.Apply(ProtocolMapping, Send, "peer", payload)
+	if !d.Corrupted || bytes.Equal(d.Payload, payload) || len(d.Payload) != len(payload) || string(payload) != "mapping message" {
+		t.Fatalf("expected a corrupted copy of the payload, got %+v", d)
+	}
+	if d = i.Apply(ProtocolMapping, Receive, "peer", payload); d.Corrupted {
+		t.Fatal("expected received messages not to be corrupted")
+	}
+
+	if d = i.Apply(ProtocolBFT, Send, "partitioned", nil); !d.Drop {
+		t.Fatal("expected messages to a partitioned peer to be dropped")
+	}
+	if d = i.Apply(ProtocolBFT, Send, "", nil); d.Drop {
+		t.Fatal("expected messages to no single peer not to be partitioned")
+	}
+
+	stats := i.Stats()
+	if stats.Dropped != 2 || stats.Delayed != 1 || stats.Duplicated != 1 || stats.Corrupted != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if err := i.SetRules(nil); err != nil || len(i.Rules()) != 0 {
+		t.Fatalf("expected the rules to be cleared, got %v", err)
+	}
+	if d = i.Apply(ProtocolPSS, Receive, "peer", []byte("m")); d.Drop {
+		t.Fatal("expected no faults once the rules are cleared")
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Protocol: "unknown"},
+		{Direction: "sideways"},
+		{Drop: 1.5},
+		{DelayMS: -1},
+		{Partition: true},
+	} {
+		if err := NewInjector().SetRules([]Rule{rule}); err == nil {
+			t.Errorf("expected %+v to be refused", rule)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package framing writes and reads length-prefixed messages, so that many messages can be sent
+// over one long-lived stream and receivers never buffer more than a maximum message size.
+package framing
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// headerSize is the size of a frame header: the length of the body as a big endian uint32,
+// followed by a byte of flags
+const headerSize = 5
+
+// flagCompressed marks bodies compressed with DEFLATE
+const flagCompressed = 1 << 0
+
+// ErrTooLarge is returned for messages that exceed the maximum size, before they are read or written
+var ErrTooLarge = errors.New("framing: message exceeds the maximum size")
+
+// Writer writes messages as frames to an underlying writer, it is not safe for concurrent use
+type Writer struct {
+	w             io.Writer
+	maxSize       int
+	compressAbove int
+	compressed    bytes.Buffer
+	compressor    *flate.Writer
+}
+
+// NewWriter creates a Writer that refuses messages larger than maxSize and compresses messages
+// larger than compressAbove bytes, compressAbove <= 0 disables compression
+func NewWriter(w io.Writer, maxSize, compressAbove int) *Writer {
+	return &Writer{w: w, maxSize: maxSize, compressAbove: compressAbove}
+}
+
+func (w *Writer) compress(msg []byte) ([]byte, error) {
+	w.compressed.Reset()
+	if w.compressor == nil {
+		compressor, err := flate.NewWriter(&w.compressed, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		w.compressor = compressor
+	} else {
+		w.compressor.Reset(&w.compressed)
+	}
+	if _, err := w.compressor.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.compressor.Close(); err != nil {
+		return nil, err
+	}
+	return w.compressed.Bytes(), nil
+}
+
+// WriteMessage writes msg as one frame
+func (w *Writer) WriteMessage(msg []byte) error {
+	if len(msg) > w.maxSize {
+		return ErrTooLarge
+	}
+	body := msg
+	var flags byte
+	if w.compressAbove > 0 && len(msg) > w.compressAbove {
+		compressed, err := w.compress(msg)
+		if err != nil {
+			return err
+		}
+		// messages that do not shrink, such as encrypted shares, are sent as they are
+		if len(compressed) < len(msg) {
+			body = compressed
+			flags |= flagCompressed
+		}
+	}
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(body)))
+	header[4] = flags
+	if _, err := w.w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(body)
+	return err
+}
+
+// Reader reads frames written by a Writer from an underlying reader
+type Reader struct {
+	r       io.Reader
+	maxSize int
+}
+
+// NewReader creates a Reader that returns ErrTooLarge for messages larger than maxSize,
+// checked against the header before the body is read and while decompressing
+func NewReader(r io.Reader, maxSize int) *Reader {
+	return &Reader{r: r, maxSize: maxSize}
+}
+
+// ReadMessage reads the next message, it returns io.EOF if the stream ended between messages.
+// After an error the position of the reader in the stream is unknown and it should be closed.
+func (r *Reader) ReadMessage() ([]byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:4])
+	if uint64(size) > uint64(r.maxSize) {
+		return nil, ErrTooLarge
+	}
+	flags := header[4]
+	if flags&^flagCompressed != 0 {
+		return nil, fmt.Errorf("framing: unknown flags %x", flags)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if flags&flagCompressed == 0 {
+		return body, nil
+	}
+	decompressor := flate.NewReader(bytes.NewReader(body))
+	defer decompressor.Close()
+	return ReadAll(decompressor, r.maxSize)
+}
+
+// ReadAll reads r until EOF like ioutil.ReadAll, but reads at most maxSize+1 bytes and
+// returns ErrTooLarge if r is longer than maxSize
+func ReadAll(r io.Reader, maxSize int) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,152 @@
+package framing
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"testing"
+)
+
+// allocated returns the bytes allocated by f
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestRoundTrip(t *testing.T) {
+	random := make([]byte, 4096)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	messages := [][]byte{
+		[]byte("ping"),
+		{},
+		bytes.Repeat([]byte("commitment"), 1000),
+		random,
+	}
+	var stream bytes.Buffer
+	w := NewWriter(&stream, 1<<20, 1024)
+	for _, msg := range messages {
+		if err := w.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stream.Len() > 4+4096+4*headerSize+2000 {
+		t.Fatalf("expected the repeated message to be compressed, stream is %v bytes", stream.Len())
+	}
+	r := NewReader(&stream, 1<<20)
+	for i, msg := range messages {
+		got, err := r.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Fatalf("message %v differs after a round trip", i)
+		}
+	}
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Fatalf("expected EOF after the last message, got %v", err)
+	}
+}
+
+func TestTruncatedFrame(t *testing.T) {
+	var stream bytes.Buffer
+	if err := NewWriter(&stream, 1024, 0).WriteMessage([]byte("truncated message")); err != nil {
+		t.Fatal(err)
+	}
+	stream.Truncate(stream.Len() - 3)
+	if _, err := NewReader(&stream, 1024).ReadMessage(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected an unexpected EOF, got %v", err)
+	}
+}
+
+func TestWriteTooLarge(t *testing.T) {
+	var stream bytes.Buffer
+	if err := NewWriter(&stream, 10, 0).WriteMessage(make([]byte, 11)); err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if stream.Len() != 0 {
+		t.Fatal("expected nothing to be written")
+	}
+}
+
+func TestOversizedHeader(t *testing.T) {
+	// a header claiming a 4GB body must be refused without allocating it
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[:4], 1<<32-1)
+	var err error
+	alloc := allocated(func() {
+		_, err = NewReader(io.MultiReader(bytes.NewReader(header[:]), zeros{}), 1<<20).ReadMessage()
+	})
+	if err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if alloc > 1<<16 {
+		t.Fatalf("expected the oversized body not to be allocated, allocated %v bytes", alloc)
+	}
+}
+
+func TestDecompressionBomb(t *testing.T) {
+	// 32MB of zeros compress to a frame well under the maximum size
+	var compressed bytes.Buffer
+	compressor, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compressor.Write(make([]byte, 32<<20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressor.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var stream bytes.Buffer
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(compressed.Len()))
+	header[4] = flagCompressed
+	stream.Write(header[:])
+	stream.Write(compressed.Bytes())
+
+	alloc := allocated(func() {
+		_, err = NewReader(&stream, 1<<20).ReadMessage()
+	})
+	if err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if alloc > 16<<20 {
+		t.Fatalf("expected decompression to stop at the maximum size, allocated %v bytes", alloc)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	data, err := ReadAll(bytes.NewReader([]byte("message")), 7)
+	if err != nil || string(data) != "message" {
+		t.Fatalf("expected the message, got %q %v", data, err)
+	}
+	var alloc uint64
+	alloc = allocated(func() {
+		_, err = ReadAll(zeros{}, 1<<20)
+	})
+	if err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge for an endless stream, got %v", err)
+	}
+	if alloc > 16<<20 {
+		t.Fatalf("expected reading to stop at the maximum size, allocated %v bytes", alloc)
+	}
+}
+
+// zeros is an endless stream of zeros
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -6,6 +6,8 @@ require (
 	github.com/TRON-US/go-eccrypto v0.0.1
 	github.com/avast/retry-go v2.4.1+incompatible
 	github.com/caarlos0/env v3.5.0+incompatible
+	github.com/cockroachdb/pebble v0.0.0-20210331181633-27fc006b8bfb
+	github.com/dgraph-io/badger/v2 v2.2007.2
 	github.com/ethereum/go-ethereum v1.8.20
 	github.com/google/uuid v1.1.1
 	github.com/gorilla/context v1.1.1
@@ -13,9 +15,12 @@ require (
 	github.com/jinzhu/copier v0.0.0-20190625015134-976e0346caa8
 	github.com/libp2p/go-libp2p v0.3.0
 	github.com/libp2p/go-libp2p-core v0.2.0
+	github.com/libp2p/go-libp2p-pubsub v0.1.1
 	github.com/mattn/go-sqlite3 v1.11.0
 	github.com/mholt/certmagic v0.6.2
+	github.com/miekg/pkcs11 v1.0.3
 	github.com/multiformats/go-multiaddr v0.0.4
+	github.com/multiformats/go-multistream v0.1.0
 	github.com/nsqio/go-diskqueue v0.0.0-20191213054144-8c228d7a2450
 	github.com/orcaman/concurrent-map v0.0.0-20190826125027-8c72a8bb44f6
 	github.com/patrickmn/go-cache v2.1.0+incompatible
@@ -37,4 +42,5 @@ require (
 	github.com/torusresearch/torus-common v0.0.0-20191123084416-e5561dcd6b3b
 	github.com/torusresearch/torus-public v0.10.4 // indirect
 	github.com/xlab-si/emmy v0.0.0-20190326084334-5eabb180a4d1
+	golang.org/x/crypto v0.0.0-20191105034135-c7e5f84aec59
 )
@@ -1,6 +1,7 @@
 package keygennofsm
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"math"
@@ -15,6 +16,8 @@ import (
 	"github.com/torusresearch/torus-common/secp256k1"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/sim"
+	"github.com/torusresearch/torus-node/version"
 )
 
 // max(roundUp((n+t+1)/2), k)
@@ -43,6 +46,10 @@ func (keygenNode *KeygenNode) ProcessMessage(senderDetails NodeDetails, keygenMe
 	// Add to metrics
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ProcessMessages, pcmn.TelemetryConstants.Keygen.Prefix)
 
+	if !version.Accepts(keygenNode.SupportedVersions, string(keygenMessage.Version)) {
+		return fmt.Errorf("keygen message version %v is not supported", keygenMessage.Version)
+	}
+
 	// ignore message if keygen is completed
 	keygen, complete := keygenNode.KeygenStore.GetOrSetIfNotComplete(keygenMessage.KeygenID, &Keygen{
 		KeygenID: keygenMessage.KeygenID,
@@ -150,6 +157,7 @@ func (keygenNode *KeygenNode) ProcessMessage(senderDetails NodeDetails, keygenMe
 		err = keygenIDDetails.FromKeygenID(keygenMsgComplete.KeygenID)
 		if err != nil {
 			logging.WithError(err).Error("could not get keygenIDDetails from keygenID in keygenMsgComplete")
+			return err
 		}
 		err = keygenNode.processCompleteMessage(keygenMsgComplete, keygenIDDetails, senderDetails)
 		if err != nil {
@@ -190,6 +198,10 @@ func (keygenNode *KeygenNode) ProcessBroadcastMessage(keygenMessage KeygenMessag
 	// Add to metrics
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ProcessedBroadcastMessages, pcmn.TelemetryConstants.Keygen.Prefix)
 
+	if !version.Accepts(keygenNode.SupportedVersions, string(keygenMessage.Version)) {
+		return fmt.Errorf("keygen message version %v is not supported", keygenMessage.Version)
+	}
+
 	logging.WithFields(logging.Fields{
 		"NodeDetails":         string(keygenNode.NodeDetails.ToNodeDetailsID())[0:8],
 		"keygenMessageMethod": keygenMessage.Method,
@@ -226,7 +238,7 @@ func (keygenNode *KeygenNode) ProcessBroadcastMessage(keygenMessage KeygenMessag
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.DecidedSharingsNotComplete, pcmn.TelemetryConstants.Keygen.Prefix)
 
 			logging.WithField("set", keygenMsgDecide.Keygens).Debug("waiting for all sharings in decided set to complete")
-			time.Sleep(1 * time.Second)
+			keygenNode.Clock.Sleep(1 * time.Second)
 		}
 
 		var abarArray []big.Int
@@ -270,7 +282,7 @@ func (keygenNode *KeygenNode) ProcessBroadcastMessage(keygenMessage KeygenMessag
 		}(string(keygenMsgDecide.DKGID))
 
 		// send NIZKP
-		c, u1, u2 := pvss.GenerateNIZKPK(abar, abarprime)
+		c, u1, u2 := pvss.GenerateNIZKPKFrom(keygenNode.Entropy, abar, abarprime)
 		keygenMsgNIZKP := &KeygenMsgNIZKP{
 			DKGID: keygenMsgDecide.DKGID,
 			NIZKP: NIZKP{
@@ -286,19 +298,12 @@ func (keygenNode *KeygenNode) ProcessBroadcastMessage(keygenMessage KeygenMessag
 		if err != nil {
 			return err
 		}
-		nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+		nextKeygenMessage := KeygenMessageRaw{
 			KeygenID: NullKeygenID,
 			Method:   "nizkp",
 			Data:     byt,
-		})
-		for _, currNode := range keygenNode.CurrNodes.Nodes {
-			go func(currNode NodeDetails, msg KeygenMessage) {
-				err := keygenNode.Transport.Send(currNode, msg)
-				if err != nil {
-					logging.WithError(err).Info()
-				}
-			}(currNode, nextKeygenMessage)
 		}
+		keygenNode.sendToAll(keygenNode.CurrNodes, nextKeygenMessage)
 		// state updates
 		dkg.Lock()
 		defer dkg.Unlock()
@@ -319,6 +324,66 @@ func dkgCleanUp(keygenNode *KeygenNode, dkgID DKGID) error {
 }
 func noOpCleanUp(*KeygenNode, DKGID) error { return nil }
 
+// send sends r to node in the version negotiated with it
+func (keygenNode *KeygenNode) send(node NodeDetails, r KeygenMessageRaw) error {
+	r.Version = keygenNode.messageVersion(node)
+	return keygenNode.Transport.Send(node, CreateKeygenMessage(r))
+}
+
+// sendBroadcast broadcasts r in the version negotiated with every node of the keygen
+func (keygenNode *KeygenNode) sendBroadcast(r KeygenMessageRaw) error {
+	r.Version = keygenNode.networkVersion(keygenNode.CurrNodes)
+	return keygenNode.Transport.SendBroadcast(CreateKeygenMessage(r))
+}
+
+// sendToAll sends r to every node in network, gossiping it if the transport can
+func (keygenNode *KeygenNode) sendToAll(network NodeNetwork, r KeygenMessageRaw) {
+	r.Version = keygenNode.networkVersion(network)
+	keygenMessage := CreateKeygenMessage(r)
+	var gossip func() error
+	if transport, ok := keygenNode.Transport.(KeygenGossipTransport); ok {
+		gossip = func() error { return transport.Gossip(network, keygenMessage) }
+	}
+	var sends []func() error
+	for _, node := range network.Nodes {
+		node := node
+		sends = append(sends, func() error { return keygenNode.Transport.Send(node, keygenMessage) })
+	}
+	pcmn.SendToAll(keygenMessage.Method, gossip, sends)
+}
+
+// messageVersion returns the version to send a message to every node of nodes in, the lowest one
+// negotiated with any of them, "" for the latest version if the transport does not negotiate
+func (keygenNode *KeygenNode) messageVersion(nodes ...NodeDetails) string {
+	negotiator, ok := keygenNode.Transport.(KeygenVersionNegotiator)
+	if !ok {
+		return ""
+	}
+	var versions []string
+	for _, node := range nodes {
+		versions = append(versions, negotiator.MessageVersion(node))
+	}
+	return version.Lowest(versions...)
+}
+
+// networkVersion returns the version to send a message to every node of networks in
+func (keygenNode *KeygenNode) networkVersion(networks ...NodeNetwork) string {
+	var nodes []NodeDetails
+	for _, network := range networks {
+		for _, node := range network.Nodes {
+			nodes = append(nodes, node)
+		}
+	}
+	return keygenNode.messageVersion(nodes...)
+}
+
+// reportInvalid reports a message of sender that failed verification, if the transport tracks nodes
+func (keygenNode *KeygenNode) reportInvalid(sender NodeDetails, reason string) {
+	if reporter, ok := keygenNode.Transport.(KeygenMisbehaviourReporter); ok {
+		reporter.ReportInvalidMessage(sender, reason)
+	}
+}
+
 func NewKeygenNode(
 	nodeDetails pcmn.Node,
 	currNodeList []pcmn.Node,
@@ -341,7 +406,10 @@ func NewKeygenNode(
 		DKGStore:     &DKGStoreSyncMap{},
 		CleanUp:      dkgCleanUp,
 		staggerDelay: staggerDelay,
+		Clock:        sim.RealClock,
+		Entropy:      rand.Reader,
 	}
+	newKeygenNode.SupportedVersions = version.SupportedProtocols[version.ProtocolKeygen]
 	newKeygenNode.KeygenStore = &KeygenStoreSyncMap{nodes: &newKeygenNode.CurrNodes}
 	transport.Init()
 	err := transport.SetKeygenNode(newKeygenNode)
@@ -0,0 +1,129 @@
+//go:build go1.18
+// +build go1.18
+
+package keygennofsm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+// sentKeygenMessage is a direct send captured by recordingTransport.
+type sentKeygenMessage struct {
+	from NodeDetails
+	to   NodeDetails
+	msg  KeygenMessage
+}
+
+// recordingTransport captures direct sends so that fuzz seeds come from messages the protocol really produces.
+type recordingTransport struct {
+	LocalOfflineTransport
+	keygenNode *KeygenNode
+	sent       chan sentKeygenMessage
+}
+
+func (r *recordingTransport) SetKeygenNode(ref *KeygenNode) error {
+	r.keygenNode = ref
+	return nil
+}
+func (r *recordingTransport) Send(nodeDetails NodeDetails, keygenMessage KeygenMessage) error {
+	r.sent <- sentKeygenMessage{r.keygenNode.NodeDetails, nodeDetails, keygenMessage}
+	return nil
+}
+
+func (r *recordingTransport) collect(f *testing.F, count int) (res []sentKeygenMessage) {
+	for i := 0; i < count; i++ {
+		select {
+		case s := <-r.sent:
+			res = append(res, s)
+		case <-time.After(5 * time.Second):
+			f.Fatalf("only recorded %v of %v messages", i, count)
+		}
+	}
+	return
+}
+
+func FuzzProcessMessage(f *testing.F) {
+	n, k, t := 5, 3, 1
+	var nodeList []pcmn.Node
+	for i := 0; i < n; i++ {
+		privKey := pvss.RandomBigInt()
+		nodeList = append(nodeList, pcmn.Node{
+			Index:  i + 1,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(privKey.Bytes())),
+		})
+	}
+	position := make(map[NodeDetailsID]uint8)
+	for i, node := range nodeList {
+		nodeDetails := NodeDetails(node)
+		position[nodeDetails.ToNodeDetailsID()] = uint8(i)
+	}
+	newNode := func(i int, transport KeygenTransport) *KeygenNode {
+		node := NewKeygenNode(nodeList[i], nodeList, t, k, nodeList[i].Index, transport, 0)
+		node.CleanUp = noOpCleanUp
+		return node
+	}
+
+	// record a dealing from node 0 and the echoes and readies it causes
+	dkgID := GenerateDKGID(*big.NewInt(int64(0)))
+	keygenID := (&KeygenIDDetails{DKGID: dkgID, DealerIndex: nodeList[0].Index}).ToKeygenID()
+	data, err := bijson.Marshal(KeygenMsgShare{DKGID: dkgID})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(uint8(0), uint8(0), string(keygenID), "share", data)
+	dealerTransport := &recordingTransport{sent: make(chan sentKeygenMessage, n)}
+	dealer := newNode(0, dealerTransport)
+	err = dealer.ProcessMessage(dealer.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{KeygenID: keygenID, Method: "share", Data: data}))
+	if err != nil {
+		f.Fatal(err)
+	}
+	var echoes []sentKeygenMessage
+	for _, send := range dealerTransport.collect(f, n) {
+		receiver := position[send.to.ToNodeDetailsID()]
+		f.Add(uint8(0), receiver, string(send.msg.KeygenID), send.msg.Method, send.msg.Data)
+		transport := &recordingTransport{sent: make(chan sentKeygenMessage, n)}
+		err = newNode(int(receiver), transport).ProcessMessage(NodeDetails(nodeList[0]), send.msg)
+		if err != nil {
+			f.Fatal(err)
+		}
+		echoes = append(echoes, transport.collect(f, n)...)
+	}
+	readyTransport := &recordingTransport{sent: make(chan sentKeygenMessage, n)}
+	readyNode := newNode(1, readyTransport)
+	for _, echo := range echoes {
+		if position[echo.to.ToNodeDetailsID()] != 1 {
+			continue
+		}
+		f.Add(position[echo.from.ToNodeDetailsID()], uint8(1), string(echo.msg.KeygenID), echo.msg.Method, echo.msg.Data)
+		err = readyNode.ProcessMessage(echo.from, echo.msg)
+		if err != nil {
+			f.Fatal(err)
+		}
+	}
+	for _, ready := range readyTransport.collect(f, n) {
+		f.Add(uint8(1), position[ready.to.ToNodeDetailsID()], string(ready.msg.KeygenID), ready.msg.Method, ready.msg.Data)
+	}
+	f.Add(uint8(0), uint8(0), string(NullKeygenID), "complete", []byte(`{"KeygenID":"","CommitmentArr":null}`))
+	f.Add(uint8(2), uint8(3), string(keygenID), "echo", []byte(`{"C":[[]]}`))
+
+	f.Fuzz(func(test *testing.T, sender uint8, receiver uint8, keygenID string, method string, data []byte) {
+		if method == "nizkp" {
+			// processNIZKPMessage waits until the node has its own Dbar, which never happens here
+			test.Skip()
+		}
+		node := newNode(int(receiver)%n, &LocalOfflineTransport{})
+		_ = node.ProcessMessage(NodeDetails(nodeList[int(sender)%n]), CreateKeygenMessage(KeygenMessageRaw{
+			KeygenID: KeygenID(keygenID),
+			Method:   method,
+			Data:     data,
+		}))
+	})
+}
@@ -2,10 +2,14 @@ package keygennofsm
 
 import (
 	"errors"
+	"flag"
+	"io"
 	"math/big"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	logging "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -15,164 +19,179 @@ import (
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/sim"
+	"github.com/torusresearch/torus-node/version"
 )
 
+var (
+	seed  = flag.Int64("seed", 0, "seed of the simulated keygens, picked from the time if 0")
+	seeds = flag.Int("seeds", 1, "number of seeds the simulated keygens run with, drawn from -seed")
+)
+
+// simulate runs f on the simulation of each of -seeds seeds drawn from -seed, as subtests named
+// after their seed so that a failing run can be repeated
+func simulate(test *testing.T, f func(test *testing.T, simulation *sim.Simulation)) {
+	sim.Run(test, *seed, *seeds, f)
+}
+
 func TestKeygenOptimistic(test *testing.T) {
 	logging.SetLevel(logging.ErrorLevel)
 	keys := 10
 	n := 9
 	k := 5
 	t := 2
-	sharedCh, generatedCh, nodes, nodeList, _, mockEngineState := SetupTestNodes(n, k, t)
-	secretSets, dkgIDs := SeedKeys(keys, k, nodeList, nodes)
-	// masterSecrets := make(map[DKGID]*big.Int)
-	for _, dkgID := range dkgIDs {
-		for _, node := range nodes {
-			keygenMsgShare := KeygenMsgShare{
-				DKGID: dkgID,
-			}
-			keygenID := (&KeygenIDDetails{
-				DKGID:       dkgID,
-				DealerIndex: node.NodeDetails.Index,
-			}).ToKeygenID()
-			data, err := bijson.Marshal(keygenMsgShare)
-			if err != nil {
-				test.Fatal(err)
-			}
-			_ = node.Transport.Send(node.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{
-				KeygenID: keygenID,
-				Method:   "share",
-				Data:     data,
-			}))
-		}
-		// hindex, ok := new(big.Int).SetString(strings.Split(string(dkgID), pcmn.Delimiter3)[1], 16)
-		// if !ok {
-		// 	logging.WithField("DKGID", dkgID).Error("Could not set string for big int")
-		// 	return
-		// }
-		// ms := pvss.SumScalars(secretSets[hindex.Int64()]...)
-		// masterSecrets[dkgID] = &ms
-	}
-	completeMessages := 0
-	completeEnough := make(chan bool)
-	nizkpMessages := 0
-	nizkpEnough := make(chan bool)
-	go func() {
-		for {
-			msg := <-sharedCh
-			if strings.Contains(msg, "shared") {
-				completeMessages++
-				if completeMessages == n*n*keys {
-					go func() {
-						completeEnough <- true
-					}()
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, generatedCh, nodes, nodeList, _, mockEngineState := SetupTestNodes(n, k, t, simulation)
+		secretSets, dkgIDs := SeedKeys(keys, k, nodeList, nodes, simulation.Entropy)
+		// masterSecrets := make(map[DKGID]*big.Int)
+		for _, dkgID := range dkgIDs {
+			for _, node := range nodes {
+				keygenMsgShare := KeygenMsgShare{
+					DKGID: dkgID,
 				}
-			} else if strings.Contains(msg, "nizkp") {
-				nizkpMessages++
-				if nizkpMessages == n*keys {
-					go func() {
-						nizkpEnough <- true
-					}()
+				keygenID := (&KeygenIDDetails{
+					DKGID:       dkgID,
+					DealerIndex: node.NodeDetails.Index,
+				}).ToKeygenID()
+				data, err := bijson.Marshal(keygenMsgShare)
+				if err != nil {
+					test.Fatal(err)
 				}
+				_ = node.Transport.Send(node.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{
+					KeygenID: keygenID,
+					Method:   "share",
+					Data:     data,
+				}))
 			}
+			// hindex, ok := new(big.Int).SetString(strings.Split(string(dkgID), pcmn.Delimiter3)[1], 16)
+			// if !ok {
+			// 	logging.WithField("DKGID", dkgID).Error("Could not set string for big int")
+			// 	return
+			// }
+			// ms := pvss.SumScalars(secretSets[hindex.Int64()]...)
+			// masterSecrets[dkgID] = &ms
 		}
-	}()
-
-	<-completeEnough
-
-	generatedMessages := 0
-	generatedEnough := make(chan bool)
-	go func() {
-		for {
-			msg := <-generatedCh
-			if strings.Contains(msg, "generated") {
-				generatedMessages++
-				if generatedMessages == n*keys {
-					go func() {
-						generatedEnough <- true
-					}()
+		completeMessages := 0
+		completeEnough := make(chan bool)
+		nizkpMessages := 0
+		nizkpEnough := make(chan bool)
+		go func() {
+			for {
+				msg := <-sharedCh
+				if strings.Contains(msg, "shared") {
+					completeMessages++
+					if completeMessages == n*n*keys {
+						go func() {
+							completeEnough <- true
+						}()
+					}
+				} else if strings.Contains(msg, "nizkp") {
+					nizkpMessages++
+					if nizkpMessages == n*keys {
+						go func() {
+							nizkpEnough <- true
+						}()
+					}
 				}
 			}
-		}
-	}()
+		}()
 
-	<-generatedEnough
+		<-completeEnough
 
-	assert.Equal(test, generatedMessages, n*keys)
+		generatedMessages := 0
+		generatedEnough := make(chan bool)
+		go func() {
+			for {
+				msg := <-generatedCh
+				if strings.Contains(msg, "generated") {
+					generatedMessages++
+					if generatedMessages == n*keys {
+						go func() {
+							generatedEnough <- true
+						}()
+					}
+				}
+			}
+		}()
 
-	masterSecrets := make(map[DKGID]big.Int)
-	for _, dkgID := range dkgIDs {
-		var shares []pcmn.PrimaryShare
-		selectedKeygenIDs := mockEngineState.KeygenDecision[dkgID]
-		var selectedIndexes []int
-		for _, keygenID := range selectedKeygenIDs {
-			var keygenIDDetails KeygenIDDetails
-			_ = keygenIDDetails.FromKeygenID(keygenID)
-			selectedIndexes = append(selectedIndexes, keygenIDDetails.DealerIndex)
-		}
-		if len(selectedKeygenIDs) == 0 {
-			assert.Fail(test, "Could not get selected keygenIDs")
-		}
-		for _, node := range nodes {
-			var subshares []big.Int
+		<-generatedEnough
+
+		assert.Equal(test, generatedMessages, n*keys)
+
+		masterSecrets := make(map[DKGID]big.Int)
+		for _, dkgID := range dkgIDs {
+			var shares []pcmn.PrimaryShare
+			selectedKeygenIDs := mockEngineState.KeygenDecision[dkgID]
+			var selectedIndexes []int
 			for _, keygenID := range selectedKeygenIDs {
-				keygen, ok := node.KeygenStore.Get(keygenID)
-				if !ok {
-					assert.Fail(test, "Could not find keygen")
-				}
-				keygen.Lock()
-				val := keygen.Si
-				keygen.Unlock()
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, val)
-				} else {
-					assert.Fail(test, "fail")
+				var keygenIDDetails KeygenIDDetails
+				_ = keygenIDDetails.FromKeygenID(keygenID)
+				selectedIndexes = append(selectedIndexes, keygenIDDetails.DealerIndex)
+			}
+			if len(selectedKeygenIDs) == 0 {
+				assert.Fail(test, "Could not get selected keygenIDs")
+			}
+			for _, node := range nodes {
+				var subshares []big.Int
+				for _, keygenID := range selectedKeygenIDs {
+					keygen, ok := node.KeygenStore.Get(keygenID)
+					if !ok {
+						assert.Fail(test, "Could not find keygen")
+					}
+					keygen.Lock()
+					val := keygen.Si
+					keygen.Unlock()
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, val)
+					} else {
+						assert.Fail(test, "fail")
+					}
 				}
+				sumSi := pvss.SumScalars(subshares...)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: sumSi,
+				})
 			}
-			sumSi := pvss.SumScalars(subshares...)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: sumSi,
-			})
-		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
-		dkgIDIndex, err := dkgID.GetIndex()
-		if err != nil {
-			assert.Fail(test, "could not get dkgID Index")
-		}
-		dealerSecrets := secretSets[int(dkgIDIndex.Int64())]
-		masterSecret := big.NewInt(0)
-		for _, selectedIndex := range selectedIndexes {
-			dealerSecret := dealerSecrets[selectedIndex]
-			masterSecret = masterSecret.Add(masterSecret, &dealerSecret)
-		}
-		masterSecret = masterSecret.Mod(masterSecret, secp256k1.GeneratorOrder)
-		masterSecrets[dkgID] = *masterSecret
-		assert.Equal(test, reconstructedSecret.Text(16), masterSecret.Text(16))
-	}
-	<-nizkpEnough
-	for _, dkgID := range dkgIDs {
-		var prevGS string
-		for _, node := range nodes {
-			dkg, ok := node.DKGStore.Get(dkgID)
-			if !ok {
-				assert.Fail(test, "Could not find dkg")
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
+			dkgIDIndex, err := dkgID.GetIndex()
+			if err != nil {
+				assert.Fail(test, "could not get dkgID Index")
 			}
-			if dkg == nil {
-				assert.Fail(test, "dkg is nil")
+			dealerSecrets := secretSets[int(dkgIDIndex.Int64())]
+			masterSecret := big.NewInt(0)
+			for _, selectedIndex := range selectedIndexes {
+				dealerSecret := dealerSecrets[selectedIndex]
+				masterSecret = masterSecret.Add(masterSecret, &dealerSecret)
 			}
-			dkg.Lock()
-			if prevGS != "" {
-				assert.Equal(test, dkg.GS.X.Text(16), prevGS)
-			} else {
-				prevGS = dkg.GS.X.Text(16)
-				masterSecret := masterSecrets[dkgID]
-				masterPubKey := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(masterSecret.Bytes()))
-				assert.Equal(test, prevGS, masterPubKey.X.Text(16))
+			masterSecret = masterSecret.Mod(masterSecret, secp256k1.GeneratorOrder)
+			masterSecrets[dkgID] = *masterSecret
+			assert.Equal(test, reconstructedSecret.Text(16), masterSecret.Text(16))
+		}
+		<-nizkpEnough
+		for _, dkgID := range dkgIDs {
+			var prevGS string
+			for _, node := range nodes {
+				dkg, ok := node.DKGStore.Get(dkgID)
+				if !ok {
+					assert.Fail(test, "Could not find dkg")
+				}
+				if dkg == nil {
+					assert.Fail(test, "dkg is nil")
+				}
+				dkg.Lock()
+				if prevGS != "" {
+					assert.Equal(test, dkg.GS.X.Text(16), prevGS)
+				} else {
+					prevGS = dkg.GS.X.Text(16)
+					masterSecret := masterSecrets[dkgID]
+					masterPubKey := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(masterSecret.Bytes()))
+					assert.Equal(test, prevGS, masterPubKey.X.Text(16))
+				}
+				dkg.Unlock()
 			}
-			dkg.Unlock()
 		}
-	}
+	})
 }
 
 func TestKeygenSomeOffline(test *testing.T) {
@@ -182,159 +201,228 @@ func TestKeygenSomeOffline(test *testing.T) {
 	n := 5
 	k := 3
 	t := 1
-	sharedCh, generatedCh, nodes, nodeList, _, mockEngineState := SetupTestNodes(n, k, t)
-	nodes[n-1].Transport = &LocalOfflineTransport{}
-	secretSets, dkgIDs := SeedKeys(keys, k, nodeList, nodes)
-	// masterSecrets := make(map[DKGID]*big.Int)
-	for _, dkgID := range dkgIDs {
-		for _, node := range nodes {
-			keygenMsgShare := KeygenMsgShare{
-				DKGID: dkgID,
-			}
-			keygenID := (&KeygenIDDetails{
-				DKGID:       dkgID,
-				DealerIndex: node.NodeDetails.Index,
-			}).ToKeygenID()
-			data, err := bijson.Marshal(keygenMsgShare)
-			if err != nil {
-				test.Fatal(err)
-			}
-			_ = node.Transport.Send(node.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{
-				KeygenID: keygenID,
-				Method:   "share",
-				Data:     data,
-			}))
-		}
-		// hindex, ok := new(big.Int).SetString(strings.Split(string(dkgID), pcmn.Delimiter3)[1], 16)
-		// if !ok {
-		// 	logging.WithField("DKGID", dkgID).Error("Could not set string for big int")
-		// 	return
-		// }
-		// ms := pvss.SumScalars(secretSets[hindex.Int64()]...)
-		// masterSecrets[dkgID] = &ms
-	}
-	completeMessages := 0
-	completeEnough := make(chan bool)
-	nizkpMessages := 0
-	nizkpEnough := make(chan bool)
-	go func() {
-		for {
-			msg := <-sharedCh
-			if strings.Contains(msg, "shared") {
-				completeMessages++
-				if completeMessages == (n-1)*(n-1)*keys {
-					go func() {
-						completeEnough <- true
-					}()
-				}
-				if completeMessages > (n-1)*(n-1)*keys {
-					assert.Fail(test, "more nizkp messages than expected")
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, generatedCh, nodes, nodeList, _, mockEngineState := SetupTestNodes(n, k, t, simulation)
+		nodes[n-1].Transport = &LocalOfflineTransport{}
+		secretSets, dkgIDs := SeedKeys(keys, k, nodeList, nodes, simulation.Entropy)
+		// masterSecrets := make(map[DKGID]*big.Int)
+		for _, dkgID := range dkgIDs {
+			for _, node := range nodes {
+				keygenMsgShare := KeygenMsgShare{
+					DKGID: dkgID,
 				}
-			} else if strings.Contains(msg, "nizkp") {
-				nizkpMessages++
-				if nizkpMessages == (n-1)*keys {
-					go func() {
-						nizkpEnough <- true
-					}()
-				}
-				if nizkpMessages > (n-1)*keys {
-					assert.Fail(test, "more nizkp messages than expected")
+				keygenID := (&KeygenIDDetails{
+					DKGID:       dkgID,
+					DealerIndex: node.NodeDetails.Index,
+				}).ToKeygenID()
+				data, err := bijson.Marshal(keygenMsgShare)
+				if err != nil {
+					test.Fatal(err)
 				}
+				_ = node.Transport.Send(node.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{
+					KeygenID: keygenID,
+					Method:   "share",
+					Data:     data,
+				}))
 			}
+			// hindex, ok := new(big.Int).SetString(strings.Split(string(dkgID), pcmn.Delimiter3)[1], 16)
+			// if !ok {
+			// 	logging.WithField("DKGID", dkgID).Error("Could not set string for big int")
+			// 	return
+			// }
+			// ms := pvss.SumScalars(secretSets[hindex.Int64()]...)
+			// masterSecrets[dkgID] = &ms
 		}
-	}()
-
-	<-completeEnough
-
-	generatedMessages := 0
-	generatedEnough := make(chan bool)
-	go func() {
-		for {
-			msg := <-generatedCh
-			if strings.Contains(msg, "generated") {
-				generatedMessages++
-				if generatedMessages == (n-1)*keys {
-					go func() {
-						generatedEnough <- true
-					}()
+		completeMessages := 0
+		completeEnough := make(chan bool)
+		nizkpMessages := 0
+		nizkpEnough := make(chan bool)
+		go func() {
+			for {
+				msg := <-sharedCh
+				if strings.Contains(msg, "shared") {
+					completeMessages++
+					if completeMessages == (n-1)*(n-1)*keys {
+						go func() {
+							completeEnough <- true
+						}()
+					}
+					if completeMessages > (n-1)*(n-1)*keys {
+						assert.Fail(test, "more nizkp messages than expected")
+					}
+				} else if strings.Contains(msg, "nizkp") {
+					nizkpMessages++
+					if nizkpMessages == (n-1)*keys {
+						go func() {
+							nizkpEnough <- true
+						}()
+					}
+					if nizkpMessages > (n-1)*keys {
+						assert.Fail(test, "more nizkp messages than expected")
+					}
 				}
-				if generatedMessages > (n-1)*keys {
-					assert.Fail(test, "more generated messages than expected")
+			}
+		}()
+
+		<-completeEnough
+
+		generatedMessages := 0
+		generatedEnough := make(chan bool)
+		go func() {
+			for {
+				msg := <-generatedCh
+				if strings.Contains(msg, "generated") {
+					generatedMessages++
+					if generatedMessages == (n-1)*keys {
+						go func() {
+							generatedEnough <- true
+						}()
+					}
+					if generatedMessages > (n-1)*keys {
+						assert.Fail(test, "more generated messages than expected")
+					}
 				}
 			}
-		}
-	}()
+		}()
 
-	<-generatedEnough
+		<-generatedEnough
 
-	assert.Equal(test, generatedMessages, (n-1)*keys)
+		assert.Equal(test, generatedMessages, (n-1)*keys)
 
-	masterSecrets := make(map[DKGID]big.Int)
-	for _, dkgID := range dkgIDs {
-		var shares []pcmn.PrimaryShare
-		selectedKeygenIDs := mockEngineState.KeygenDecision[dkgID]
-		var selectedIndexes []int
-		for _, keygenID := range selectedKeygenIDs {
-			var keygenIDDetails KeygenIDDetails
-			_ = keygenIDDetails.FromKeygenID(keygenID)
-			selectedIndexes = append(selectedIndexes, keygenIDDetails.DealerIndex)
-		}
-		if len(selectedKeygenIDs) == 0 {
-			assert.Fail(test, "Could not get selected keygenIDs")
-		}
-		for _, node := range nodes {
-			var subshares []big.Int
+		masterSecrets := make(map[DKGID]big.Int)
+		for _, dkgID := range dkgIDs {
+			var shares []pcmn.PrimaryShare
+			selectedKeygenIDs := mockEngineState.KeygenDecision[dkgID]
+			var selectedIndexes []int
 			for _, keygenID := range selectedKeygenIDs {
-				kStore, ok := node.KeygenStore.Get(keygenID)
+				var keygenIDDetails KeygenIDDetails
+				_ = keygenIDDetails.FromKeygenID(keygenID)
+				selectedIndexes = append(selectedIndexes, keygenIDDetails.DealerIndex)
+			}
+			if len(selectedKeygenIDs) == 0 {
+				assert.Fail(test, "Could not get selected keygenIDs")
+			}
+			for _, node := range nodes {
+				var subshares []big.Int
+				for _, keygenID := range selectedKeygenIDs {
+					kStore, ok := node.KeygenStore.Get(keygenID)
+					if !ok {
+						assert.Fail(test, "could not get back keygen store")
+					}
+					val := kStore.Si
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, val)
+					} else {
+						assert.Fail(test, "fail")
+					}
+				}
+				sumSi := pvss.SumScalars(subshares...)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: sumSi,
+				})
+			}
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
+			dkgIDIndex, err := dkgID.GetIndex()
+			if err != nil {
+				assert.Fail(test, "could not get dkgID Index")
+			}
+			dealerSecrets := secretSets[int(dkgIDIndex.Int64())]
+			masterSecret := big.NewInt(0)
+			for _, selectedIndex := range selectedIndexes {
+				dealerSecret := dealerSecrets[selectedIndex]
+				masterSecret = masterSecret.Add(masterSecret, &dealerSecret)
+			}
+			masterSecret = masterSecret.Mod(masterSecret, secp256k1.GeneratorOrder)
+			masterSecrets[dkgID] = *masterSecret
+			assert.Equal(test, reconstructedSecret.Text(16), masterSecret.Text(16))
+		}
+		<-nizkpEnough
+		for _, dkgID := range dkgIDs {
+			var prevGS string
+			for _, node := range nodes {
+				if _, ok := node.Transport.(*LocalOfflineTransport); ok {
+					continue
+				}
+				dkg, ok := node.DKGStore.Get(dkgID)
 				if !ok {
-					assert.Fail(test, "could not get back keygen store")
+					assert.Fail(test, "dkg is nil")
 				}
-				val := kStore.Si
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, val)
+				if prevGS != "" {
+					assert.Equal(test, dkg.GS.X.Text(16), prevGS)
 				} else {
-					assert.Fail(test, "fail")
+					prevGS = dkg.GS.X.Text(16)
 				}
 			}
-			sumSi := pvss.SumScalars(subshares...)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: sumSi,
-			})
 		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
-		dkgIDIndex, err := dkgID.GetIndex()
-		if err != nil {
-			assert.Fail(test, "could not get dkgID Index")
-		}
-		dealerSecrets := secretSets[int(dkgIDIndex.Int64())]
-		masterSecret := big.NewInt(0)
-		for _, selectedIndex := range selectedIndexes {
-			dealerSecret := dealerSecrets[selectedIndex]
-			masterSecret = masterSecret.Add(masterSecret, &dealerSecret)
-		}
-		masterSecret = masterSecret.Mod(masterSecret, secp256k1.GeneratorOrder)
-		masterSecrets[dkgID] = *masterSecret
-		assert.Equal(test, reconstructedSecret.Text(16), masterSecret.Text(16))
-	}
-	<-nizkpEnough
-	for _, dkgID := range dkgIDs {
-		var prevGS string
-		for _, node := range nodes {
-			if _, ok := node.Transport.(*LocalOfflineTransport); ok {
-				continue
+	})
+}
+
+// TestKeygenMixedVersions runs keygens between nodes that support different keygen message versions,
+// as during a rolling upgrade. Every node has to receive its messages in a version it supports,
+// and upgraded nodes use the newer version between them.
+func TestKeygenMixedVersions(test *testing.T) {
+	logging.SetLevel(logging.ErrorLevel)
+	keys := 2
+	n := 5
+	k := 3
+	t := 1
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, generatedCh, nodes, nodeList, _, _ := SetupTestNodes(n, k, t, simulation)
+		var unsupported, upgraded int32
+		for i, node := range nodes {
+			node := node
+			if i%2 == 0 {
+				node.SupportedVersions = []string{"0.9.0"}
+			} else {
+				node.SupportedVersions = []string{"0.9.0", "1.0.0"}
 			}
-			dkg, ok := node.DKGStore.Get(dkgID)
-			if !ok {
-				assert.Fail(test, "dkg is nil")
+			transport := node.Transport.(*LocalTransport)
+			transport.ReceiveMiddleware = append(transport.ReceiveMiddleware, func(keygenMessage KeygenMessage) (KeygenMessage, bool, error) {
+				if !version.Accepts(node.SupportedVersions, string(keygenMessage.Version)) {
+					atomic.AddInt32(&unsupported, 1)
+				}
+				if keygenMessage.Version == "1.0.0" {
+					atomic.AddInt32(&upgraded, 1)
+				}
+				return keygenMessage, false, nil
+			})
+		}
+		_, dkgIDs := SeedKeys(keys, k, nodeList, nodes, simulation.Entropy)
+		for _, dkgID := range dkgIDs {
+			for _, node := range nodes {
+				data, err := bijson.Marshal(KeygenMsgShare{DKGID: dkgID})
+				if err != nil {
+					test.Fatal(err)
+				}
+				keygenID := (&KeygenIDDetails{
+					DKGID:       dkgID,
+					DealerIndex: node.NodeDetails.Index,
+				}).ToKeygenID()
+				_ = node.Transport.Send(node.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{
+					KeygenID: keygenID,
+					Method:   "share",
+					Data:     data,
+				}))
 			}
-			if prevGS != "" {
-				assert.Equal(test, dkg.GS.X.Text(16), prevGS)
-			} else {
-				prevGS = dkg.GS.X.Text(16)
+		}
+
+		generated := 0
+		timeout := time.After(time.Minute)
+		for generated < n*keys {
+			select {
+			case <-sharedCh:
+			case msg := <-generatedCh:
+				if strings.Contains(msg, "generated") {
+					generated++
+				}
+			case <-timeout:
+				test.Fatalf("only %v of %v keys were generated", generated, n*keys)
 			}
 		}
-	}
+		assert.Equal(test, int32(0), atomic.LoadInt32(&unsupported), "expected every message in a version its receiver supports")
+		assert.NotEqual(test, int32(0), atomic.LoadInt32(&upgraded), "expected upgraded nodes to send each other the newer version")
+	})
 }
 
 // var LocalNodeDirectory map[string]*LocalTransport
@@ -438,6 +526,22 @@ func (l *LocalTransport) Output(sinter interface{}) {
 func (l *LocalTransport) CheckIfNIZKPProcessed(keyIndex big.Int) bool {
 	return false
 }
+
+// MessageVersion implements KeygenVersionNegotiator with the versions supported by both nodes
+func (l *LocalTransport) MessageVersion(nodeDetails NodeDetails) string {
+	receiver, ok := (*l.NodeDirectory)[nodeDetails.ToNodeDetailsID()].(*LocalTransport)
+	if !ok {
+		return ""
+	}
+	agreement, err := version.Negotiate(
+		version.Hello{Protocols: map[string][]string{version.ProtocolKeygen: l.KeygenNode.SupportedVersions}},
+		version.Hello{Protocols: map[string][]string{version.ProtocolKeygen: receiver.KeygenNode.SupportedVersions}},
+	)
+	if err != nil {
+		return ""
+	}
+	return agreement.Version(version.ProtocolKeygen)
+}
 func (l *LocalTransport) runSendMiddleware(keygenMessage KeygenMessage) (KeygenMessage, error) {
 	modifiedMessage := keygenMessage
 	for _, middleware := range l.SendMiddleware {
@@ -556,17 +660,17 @@ type MockEngineState struct {
 	KeygenDecision map[DKGID][]KeygenID
 }
 
-func SetupTestNodes(n int, k int, t int) (chan string, chan string, []*KeygenNode, []pcmn.Node, *map[NodeDetailsID]KeygenTransport, *MockEngineState) {
+func SetupTestNodes(n int, k int, t int, simulation *sim.Simulation) (chan string, chan string, []*KeygenNode, []pcmn.Node, *map[NodeDetailsID]KeygenTransport, *MockEngineState) {
 	// setup
 	sharedCh := make(chan string)
 	generatedCh := make(chan string)
 	var currNodePrivKeys []big.Int
 	var currNodePubKeys []common.Point
 	for i := 0; i < n; i++ {
-		currNodePrivKeys = append(currNodePrivKeys, *pvss.RandomBigInt())
+		currNodePrivKeys = append(currNodePrivKeys, *pvss.RandomBigIntFrom(simulation.Entropy))
 		currNodePubKeys = append(currNodePubKeys, common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(currNodePrivKeys[i].Bytes())))
 	}
-	currNodeIndexes := pcmn.RandIndexes(1, 50, n)
+	currNodeIndexes := pcmn.RandIndexesFrom(simulation.Rand, 1, 50, n)
 	var currNodeList []pcmn.Node
 	for i := 0; i < n; i++ {
 		currNodeList = append(currNodeList, pcmn.Node{
@@ -602,6 +706,8 @@ func SetupTestNodes(n int, k int, t int) (chan string, chan string, []*KeygenNod
 			0,
 		)
 		newKeygenNode.CleanUp = noOpCleanUp
+		newKeygenNode.Clock = simulation.Clock
+		newKeygenNode.Entropy = simulation.Entropy.Fork()
 		currNodes = append(currNodes, newKeygenNode)
 		_ = localTransport.SetKeygenNode(newKeygenNode)
 		nodeDetails := NodeDetails(node)
@@ -611,7 +717,7 @@ func SetupTestNodes(n int, k int, t int) (chan string, chan string, []*KeygenNod
 	return sharedCh, generatedCh, currNodes, currNodeList, &localTransportNodeDirectory, &currEngineState
 }
 
-func SeedKeys(keys int, k int, currNodeList []pcmn.Node, currNodes []*KeygenNode) ([]map[int]big.Int, []DKGID) {
+func SeedKeys(keys int, k int, currNodeList []pcmn.Node, currNodes []*KeygenNode, entropy io.Reader) ([]map[int]big.Int, []DKGID) {
 	var dkgIDs []DKGID
 	var secretSets []map[int]big.Int
 	for h := 0; h < keys; h++ {
@@ -620,8 +726,8 @@ func SeedKeys(keys int, k int, currNodeList []pcmn.Node, currNodes []*KeygenNode
 		dkgIDs = append(dkgIDs, dkgID)
 		for _, node := range currNodes {
 			index := node.NodeDetails.Index
-			S := *pvss.RandomBigInt()
-			Sprime := *pvss.RandomBigInt()
+			S := *pvss.RandomBigIntFrom(entropy)
+			Sprime := *pvss.RandomBigIntFrom(entropy)
 			secrets[index] = S
 			node.ShareStore.Set(dkgID, &Sharing{
 				DKGID:  dkgID,
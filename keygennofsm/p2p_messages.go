@@ -16,6 +16,7 @@ import (
 	"github.com/torusresearch/torus-common/common"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/secret"
 )
 
 func (keygenNode *KeygenNode) processShareMessage(keygenMsgShare KeygenMsgShare, keygen *Keygen) error {
@@ -32,8 +33,8 @@ func (keygenNode *KeygenNode) processShareMessage(keygenMsgShare KeygenMsgShare,
 	// Add to metrics
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ShareMessage, pcmn.TelemetryConstants.Keygen.Prefix)
 
-	S := *pvss.RandomBigInt()
-	Sprime := *pvss.RandomBigInt()
+	S := *pvss.RandomBigIntFrom(keygenNode.Entropy)
+	Sprime := *pvss.RandomBigIntFrom(keygenNode.Entropy)
 	sharing, _ := keygenNode.ShareStore.GetOrSet(keygenMsgShare.DKGID, &Sharing{
 		DKGID:  keygenMsgShare.DKGID,
 		I:      keygenNode.NodeIndex,
@@ -46,8 +47,10 @@ func (keygenNode *KeygenNode) processShareMessage(keygenMsgShare KeygenMsgShare,
 	if sharing.S.Cmp(big.NewInt(int64(0))) == 0 || sharing.Sprime.Cmp(big.NewInt(int64(0))) == 0 {
 		return fmt.Errorf("DKGID %v was not initialized with Si and Siprime. Si: %v, Siprime: %v", keygenMsgShare.DKGID, sharing.Sprime, sharing.Sprime)
 	}
-	keygen.F = pvss.GenerateRandomBivariatePolynomial(sharing.S, keygenNode.CurrNodes.K)
-	keygen.Fprime = pvss.GenerateRandomBivariatePolynomial(sharing.Sprime, keygenNode.CurrNodes.K)
+	keygen.F = pvss.GenerateRandomBivariatePolynomialFrom(keygenNode.Entropy, sharing.S, keygenNode.CurrNodes.K)
+	keygen.Fprime = pvss.GenerateRandomBivariatePolynomialFrom(keygenNode.Entropy, sharing.Sprime, keygenNode.CurrNodes.K)
+	// the polynomials are only needed to deal the shares
+	defer keygen.wipePolynomials()
 	keygen.C = pvss.GetCommitmentMatrix(keygen.F, keygen.Fprime)
 
 	for _, newNode := range keygenNode.CurrNodes.Nodes {
@@ -64,22 +67,24 @@ func (keygenNode *KeygenNode) processShareMessage(keygenMsgShare KeygenMsgShare,
 			Bprime:   pvss.EvaluateBivarPolyAtY(keygen.Fprime, *big.NewInt(int64(newNode.Index))).Coeff,
 		}
 		data, err := bijson.Marshal(keygenMsgSend)
+		for _, evaluation := range [][]big.Int{keygenMsgSend.A, keygenMsgSend.Aprime, keygenMsgSend.B, keygenMsgSend.Bprime} {
+			secret.WipeInts(evaluation)
+		}
 		if err != nil {
 			return err
 		}
-		nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+		nextKeygenMessage := KeygenMessageRaw{
 			KeygenID: keygenID,
 			Method:   "send",
 			Data:     data,
-		})
-		go func(newN NodeDetails, msg KeygenMessage) {
-			err := keygenNode.Transport.Send(newN, msg)
+		}
+		go func(newN NodeDetails, msg KeygenMessageRaw) {
+			err := keygenNode.send(newN, msg)
 			if err != nil {
 				logging.WithError(err).Error("could not send keygenMsgSend")
 			}
 		}(newNode, nextKeygenMessage)
 	}
-
 	// state updates
 	keygen.State.Phase = States.Phases.Started
 
@@ -114,6 +119,7 @@ func (keygenNode *KeygenNode) processSendMessage(keygenMsgSend KeygenMsgSend, ke
 		pcmn.PrimaryPolynomial{Coeff: keygenMsgSend.Bprime, Threshold: keygenNode.CurrNodes.K},
 	)
 	if !verified {
+		keygenNode.reportInvalid(senderDetails, "send")
 		return errors.New("Could not verify polys against commitment")
 	}
 
@@ -149,13 +155,13 @@ func (keygenNode *KeygenNode) processSendMessage(keygenMsgSend KeygenMsgSend, ke
 		if err != nil {
 			return err
 		}
-		nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+		nextKeygenMessage := KeygenMessageRaw{
 			KeygenID: keygen.KeygenID,
 			Method:   "echo",
 			Data:     data,
-		})
-		go func(newN NodeDetails, msg KeygenMessage) {
-			err := keygenNode.Transport.Send(newN, msg)
+		}
+		go func(newN NodeDetails, msg KeygenMessageRaw) {
+			err := keygenNode.send(newN, msg)
 			if err != nil {
 				logging.WithError(err).Info()
 			}
@@ -194,6 +200,7 @@ func (keygenNode *KeygenNode) processEchoMessage(keygenMsgEcho KeygenMsgEcho, ke
 
 		// Add to metrics
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.InvalidEcho, pcmn.TelemetryConstants.Keygen.Prefix)
+		keygenNode.reportInvalid(senderDetails, "echo")
 
 		return errors.New("could not verify point against commitments for echo message")
 	}
@@ -283,13 +290,13 @@ func (keygenNode *KeygenNode) processEchoMessage(keygenMsgEcho KeygenMsgEcho, ke
 			if err != nil {
 				return err
 			}
-			nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+			nextKeygenMessage := KeygenMessageRaw{
 				KeygenID: keygen.KeygenID,
 				Method:   "ready",
 				Data:     data,
-			})
-			go func(newN NodeDetails, msg KeygenMessage) {
-				err := keygenNode.Transport.Send(newN, msg)
+			}
+			go func(newN NodeDetails, msg KeygenMessageRaw) {
+				err := keygenNode.send(newN, msg)
 				if err != nil {
 					logging.WithError(err).Info()
 				}
@@ -333,6 +340,7 @@ func (keygenNode *KeygenNode) processReadyMessage(keygenMsgReady KeygenMsgReady,
 
 		// Add telemetry
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.InvalidReady, pcmn.TelemetryConstants.Keygen.Prefix)
+		keygenNode.reportInvalid(senderDetails, "ready")
 
 		return errors.New("could not verify point against commitments for ready message")
 	}
@@ -345,6 +353,7 @@ func (keygenNode *KeygenNode) processReadyMessage(keygenMsgReady KeygenMsgReady,
 
 		// Add telemetry
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.ReadySigInvalid, pcmn.TelemetryConstants.Keygen.Prefix)
+		keygenNode.reportInvalid(senderDetails, "ready signature")
 
 		return errors.New("could not verify signature on ready message for keygen " + string(keygen.KeygenID))
 	}
@@ -436,13 +445,13 @@ func (keygenNode *KeygenNode) processReadyMessage(keygenMsgReady KeygenMsgReady,
 			if err != nil {
 				return err
 			}
-			nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+			nextKeygenMessage := KeygenMessageRaw{
 				KeygenID: keygen.KeygenID,
 				Method:   "ready",
 				Data:     data,
-			})
-			go func(newN NodeDetails, msg KeygenMessage) {
-				err := keygenNode.Transport.Send(newN, msg)
+			}
+			go func(newN NodeDetails, msg KeygenMessageRaw) {
+				err := keygenNode.send(newN, msg)
 				if err != nil {
 					logging.WithError(err).Error("could not send keygenMsgReady")
 				}
@@ -470,13 +479,13 @@ func (keygenNode *KeygenNode) processReadyMessage(keygenMsgReady KeygenMsgReady,
 		if err != nil {
 			return err
 		}
-		nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+		nextKeygenMessage := KeygenMessageRaw{
 			KeygenID: NullKeygenID,
 			Method:   "complete",
 			Data:     data,
-		})
-		go func(ownNode NodeDetails, ownMsg KeygenMessage) {
-			err := keygenNode.Transport.Send(ownNode, ownMsg)
+		}
+		go func(ownNode NodeDetails, ownMsg KeygenMessageRaw) {
+			err := keygenNode.send(ownNode, ownMsg)
 			if err != nil {
 				logging.WithError(err).Error("could not send keygenMsgComplete")
 			}
@@ -560,25 +569,25 @@ func (keygenNode *KeygenNode) processCompleteMessage(keygenMsgComplete KeygenMsg
 	if err != nil {
 		return err
 	}
-	nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+	nextKeygenMessage := KeygenMessageRaw{
 		KeygenID: NullKeygenID,
 		Method:   "propose",
 		Data:     data,
-	})
+	}
 	keyIndex, err := dkgID.GetIndex()
 	if err != nil {
 		return err
 	}
 
 	// send broadcast if propose message has not been fully processed
-	go func(keygenMessage KeygenMessage, kI big.Int) {
+	go func(keygenMessage KeygenMessageRaw, kI big.Int) {
 		// Stagger propose message for optimization
 		keygenNode.stagger(int(kI.Int64()))
 		dkg.Lock()
 		sendBroadcast := dkg.Si.Cmp(big.NewInt(0)) == 0
 		dkg.Unlock()
 		if sendBroadcast {
-			err := keygenNode.Transport.SendBroadcast(keygenMessage)
+			err := keygenNode.sendBroadcast(keygenMessage)
 			if err != nil {
 				logging.WithError(err).Error("could not send keygenMsgPropose")
 			}
@@ -611,7 +620,7 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 			keygen.Unlock()
 		}
 		logging.WithField("dkgid", keygenMsgNIZKP.DKGID).Debug("Waiting for dbar to be in...")
-		time.Sleep(1 * time.Second)
+		keygenNode.Clock.Sleep(1 * time.Second)
 		if keygen != nil {
 			keygen.Lock()
 		}
@@ -622,6 +631,7 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 
 		// Add to metrics
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.InvalidShareCommitment, pcmn.TelemetryConstants.Keygen.Prefix)
+		keygenNode.reportInvalid(senderDetails, "nizkp share commitment")
 
 		return errors.New("could not verify share commitment against existing Dbar")
 	}
@@ -629,6 +639,7 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 
 		// Add to metrics
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.InvalidNizkp, pcmn.TelemetryConstants.Keygen.Prefix)
+		keygenNode.reportInvalid(senderDetails, "nizkp")
 
 		return errors.New("could not verify NIZKP")
 	}
@@ -640,7 +651,9 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 		var nizkps []NIZKP
 		for nodeDetailsID, nizkp := range dkg.NIZKPStore {
 			var nodeDetails NodeDetails
-			nodeDetails.FromNodeDetailsID(nodeDetailsID)
+			if err := nodeDetails.FromNodeDetailsID(nodeDetailsID); err != nil {
+				return err
+			}
 			indexes = append(indexes, nodeDetails.Index)
 			points = append(points, nizkp.GSi)
 			nizkps = append(nizkps, nizkp)
@@ -650,15 +663,11 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 		if err != nil {
 			return errors.New("could not get dkgID Index")
 		}
-		go func(msg string, keyIndex big.Int, si big.Int, siprime big.Int, commitmentPoly []common.Point) {
+		// the share is copied while dkg is locked
+		go func(msg string, keyStorage KeyStorage) {
 			keygenNode.Transport.Output(msg + " nizkp completed")
-			keygenNode.Transport.Output(KeyStorage{
-				KeyIndex:       keyIndex,
-				Si:             si,
-				Siprime:        siprime,
-				CommitmentPoly: commitmentPoly,
-			})
-		}(string(dkg.DKGID), keyIndex, dkg.Si, dkg.Siprime, dkg.Dbar)
+			keygenNode.Transport.Output(keyStorage)
+		}(string(dkg.DKGID), newKeyStorage(keyIndex, &dkg.Si, &dkg.Siprime, dkg.Dbar))
 
 		// Add to metrics
 		telemetry.IncrementCounter(pcmn.TelemetryConstants.Keygen.NumSharesVerified, pcmn.TelemetryConstants.Keygen.Prefix)
@@ -672,11 +681,11 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 		if err != nil {
 			return err
 		}
-		nextKeygenMessage := CreateKeygenMessage(KeygenMessageRaw{
+		nextKeygenMessage := KeygenMessageRaw{
 			KeygenID: NullKeygenID,
 			Method:   "pubkey",
 			Data:     data,
-		})
+		}
 
 		err = keygenNode.CleanUp(keygenNode, dkg.DKGID)
 		if err != nil {
@@ -685,11 +694,11 @@ func (keygenNode *KeygenNode) processNIZKPMessage(keygenMsgNIZKP KeygenMsgNIZKP,
 		}
 
 		// send broadcast if propose message has not been fully processed
-		go func(keygenMessage KeygenMessage, kI big.Int) {
+		go func(keygenMessage KeygenMessageRaw, kI big.Int) {
 			// Stagger nizkp message for optimization
 			keygenNode.stagger(int(kI.Int64()))
 			if !keygenNode.Transport.CheckIfNIZKPProcessed(keyIndex) {
-				err := keygenNode.Transport.SendBroadcast(keygenMessage)
+				err := keygenNode.sendBroadcast(keygenMessage)
 				if err != nil {
 					logging.WithError(err).Error("could not send broadcast keygenMsgPubKey")
 				}
@@ -742,5 +751,5 @@ func getDKGIfDbarExists(keygenNode *KeygenNode, dkgID DKGID) (dkg *DKG, alreadyC
 func (keygenNode *KeygenNode) stagger(seed int) {
 	index := keygenNode.NodeDetails.Index
 	nodeSetLength := keygenNode.CurrNodes.N
-	time.Sleep(time.Duration(((seed+index)%nodeSetLength)*keygenNode.staggerDelay) * time.Millisecond)
+	keygenNode.Clock.Sleep(time.Duration(((seed+index)%nodeSetLength)*keygenNode.staggerDelay) * time.Millisecond)
 }
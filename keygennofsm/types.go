@@ -3,6 +3,7 @@ package keygennofsm
 import (
 	"encoding/hex"
 	"errors"
+	"io"
 	"math/big"
 	"strconv"
 	"strings"
@@ -13,6 +14,8 @@ import (
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/sim"
 
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/secp256k1"
@@ -29,28 +32,29 @@ func (n *NodeDetails) ToNodeDetailsID() NodeDetailsID {
 		n.PubKey.Y.Text(16),
 	}, pcmn.Delimiter1))
 }
-func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) {
+func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) error {
 	s := string(nodeDetailsID)
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 3 {
-		return
+		return errors.New("Error parsing NodeDetails, did not find 3 fields exactly")
 	}
 	index, err := strconv.Atoi(substrings[0])
 	if err != nil {
-		return
+		return err
 	}
-	n.Index = index
 	pubkeyX, ok := new(big.Int).SetString(substrings[1], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey X")
 	}
-	n.PubKey.X = *pubkeyX
 	pubkeyY, ok := new(big.Int).SetString(substrings[2], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey Y")
 	}
+	n.Index = index
+	n.PubKey.X = *pubkeyX
 	n.PubKey.Y = *pubkeyY
+	return nil
 }
 
 type DKGID string
@@ -92,11 +96,11 @@ func (keygenIDDetails *KeygenIDDetails) FromKeygenID(keygenID KeygenID) error {
 	if len(substrings) != 2 {
 		return errors.New("Error parsing keygenIDDetails, did not find 2 fields exactly")
 	}
-	keygenIDDetails.DKGID = DKGID(substrings[0])
 	index, err := strconv.Atoi(substrings[1])
 	if err != nil {
 		return err
 	}
+	keygenIDDetails.DKGID = DKGID(substrings[0])
 	keygenIDDetails.DealerIndex = index
 	return nil
 }
@@ -116,6 +120,23 @@ type KeygenTransport interface {
 	CheckIfNIZKPProcessed(keyIndex big.Int) bool
 }
 
+// KeygenGossipTransport is implemented by transports that can gossip a message to a whole network, see common.SendToAll
+type KeygenGossipTransport interface {
+	Gossip(NodeNetwork, KeygenMessage) error
+}
+
+// KeygenVersionNegotiator is implemented by transports that negotiate message versions with other
+// nodes, MessageVersion returns the version negotiated with a node, "" if it is not known
+type KeygenVersionNegotiator interface {
+	MessageVersion(NodeDetails) string
+}
+
+// KeygenMisbehaviourReporter is implemented by transports that keep track of the reputation of
+// nodes, messages that fail verification, such as invalid commitments or proofs, are reported to it
+type KeygenMisbehaviourReporter interface {
+	ReportInvalidMessage(sender NodeDetails, reason string)
+}
+
 type NodeNetwork struct {
 	Nodes map[NodeDetailsID]NodeDetails
 	N     int
@@ -162,10 +183,10 @@ type C struct {
 	ACprime         map[NodeDetailsID]common.Point
 	BC              map[NodeDetailsID]common.Point
 	BCprime         map[NodeDetailsID]common.Point
-	Abar            []big.Int
-	Abarprime       []big.Int
-	Bbar            []big.Int
-	Bbarprime       []big.Int
+	Abar            []big.Int `secret:"true"`
+	Abarprime       []big.Int `secret:"true"`
+	Bbar            []big.Int `secret:"true"`
+	Bbarprime       []big.Int `secret:"true"`
 	SignedTextStore map[NodeDetailsID]SignedText
 }
 
@@ -243,24 +264,31 @@ type Keygen struct {
 	idmutex.Mutex
 	KeygenID KeygenID
 	Epoch    int
-	Si       big.Int
-	Siprime  big.Int
-	F        [][]big.Int
-	Fprime   [][]big.Int
+	Si       big.Int     `secret:"true"`
+	Siprime  big.Int     `secret:"true"`
+	F        [][]big.Int `secret:"true"`
+	Fprime   [][]big.Int `secret:"true"`
 	Cbar     [][]common.Point
 	C        [][]common.Point
 	CStore   map[CID]*C
 	State    KeygenState
 }
 
+// wipePolynomials wipes the polynomials of a dealing
+func (keygen *Keygen) wipePolynomials() {
+	secret.WipeMatrix(keygen.F)
+	secret.WipeMatrix(keygen.Fprime)
+	keygen.F, keygen.Fprime = nil, nil
+}
+
 type Sharing struct {
 	idmutex.Mutex
 	DKGID DKGID
 	// Nodes  []pcmn.Node
 	// Epoch  int
 	I      int
-	S      big.Int
-	Sprime big.Int
+	S      big.Int `secret:"true"`
+	Sprime big.Int `secret:"true"`
 }
 
 type DKG struct {
@@ -269,8 +297,8 @@ type DKG struct {
 	GS         common.Point
 	NIZKPStore map[NodeDetailsID]NIZKP
 	DMap       map[KeygenID][]common.Point
-	Si         big.Int
-	Siprime    big.Int
+	Si         big.Int `secret:"true"`
+	Siprime    big.Int `secret:"true"`
 	Dbar       []common.Point
 }
 
@@ -382,11 +410,21 @@ type KeygenNode struct {
 	CleanUp     func(*KeygenNode, DKGID) error
 	// optimization for broadcast messages
 	staggerDelay int
+	// SupportedVersions are the keygen message versions this node processes
+	SupportedVersions []string
+	// Clock and Entropy are the real clock and crypto/rand unless a simulation replaces them
+	// before the node processes messages
+	Clock   sim.Clock
+	Entropy io.Reader
 }
 
 func CreateKeygenMessage(r KeygenMessageRaw) KeygenMessage {
+	messageVersion := r.Version
+	if messageVersion == "" {
+		messageVersion = version.KeygenMessageVersion
+	}
 	return KeygenMessage{
-		Version:  keygenMessageVersion(version.NodeVersion),
+		Version:  keygenMessageVersion(messageVersion),
 		KeygenID: r.KeygenID,
 		Method:   r.Method,
 		Data:     r.Data,
@@ -394,6 +432,8 @@ func CreateKeygenMessage(r KeygenMessageRaw) KeygenMessage {
 }
 
 type KeygenMessageRaw struct {
+	// Version is negotiated with the nodes the message is sent to, the latest version if empty
+	Version  string
 	KeygenID KeygenID
 	Method   string
 	Data     []byte
@@ -405,7 +445,7 @@ type KeygenMessage struct {
 	Version  keygenMessageVersion `json:"version,omitempty"`
 	KeygenID KeygenID             `json:"keygenid"`
 	Method   string               `json:"type"`
-	Data     []byte               `json:"data"`
+	Data     []byte               `json:"data" secret:"true"`
 }
 type KeygenMsgShare struct {
 	DKGID DKGID
@@ -413,26 +453,26 @@ type KeygenMsgShare struct {
 type KeygenMsgSend struct {
 	KeygenID KeygenID
 	C        [][]common.Point
-	A        []big.Int
-	Aprime   []big.Int
-	B        []big.Int
-	Bprime   []big.Int
+	A        []big.Int `secret:"true"`
+	Aprime   []big.Int `secret:"true"`
+	B        []big.Int `secret:"true"`
+	Bprime   []big.Int `secret:"true"`
 }
 type KeygenMsgEcho struct {
 	KeygenID   KeygenID
 	C          [][]common.Point
-	Alpha      big.Int
-	Alphaprime big.Int
-	Beta       big.Int
-	Betaprime  big.Int
+	Alpha      big.Int `secret:"true"`
+	Alphaprime big.Int `secret:"true"`
+	Beta       big.Int `secret:"true"`
+	Betaprime  big.Int `secret:"true"`
 }
 type KeygenMsgReady struct {
 	KeygenID   KeygenID
 	C          [][]common.Point
-	Alpha      big.Int
-	Alphaprime big.Int
-	Beta       big.Int
-	Betaprime  big.Int
+	Alpha      big.Int `secret:"true"`
+	Alphaprime big.Int `secret:"true"`
+	Beta       big.Int `secret:"true"`
+	Betaprime  big.Int `secret:"true"`
 	SignedText SignedText
 }
 type KeygenMsgPropose struct {
@@ -471,13 +511,30 @@ type KeygenMsgNIZKP struct {
 	NIZKP NIZKP
 }
 
+// KeyStorage is the output of a completed keygen. It holds copies of the share,
+// which the transport wipes once it has stored them.
 type KeyStorage struct {
 	KeyIndex       big.Int
-	Si             big.Int
-	Siprime        big.Int
+	Si             *secret.Int
+	Siprime        *secret.Int
 	CommitmentPoly []common.Point
 }
 
+func newKeyStorage(keyIndex big.Int, si, siprime *big.Int, commitmentPoly []common.Point) KeyStorage {
+	return KeyStorage{
+		KeyIndex:       keyIndex,
+		Si:             secret.NewInt(si),
+		Siprime:        secret.NewInt(siprime),
+		CommitmentPoly: commitmentPoly,
+	}
+}
+
+// Wipe wipes the share
+func (k KeyStorage) Wipe() {
+	k.Si.Wipe()
+	k.Siprime.Wipe()
+}
+
 func GetCIDFromPointMatrix(pm [][]common.Point) CID {
 	var bytes []byte
 	delimiter := pcmn.Delimiter1
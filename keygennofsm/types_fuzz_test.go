@@ -0,0 +1,54 @@
+//go:build go1.18
+// +build go1.18
+
+package keygennofsm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+func FuzzFromNodeDetailsID(f *testing.F) {
+	for i := 1; i <= 3; i++ {
+		nodeDetails := NodeDetails{
+			Index:  i,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		}
+		f.Add(string(nodeDetails.ToNodeDetailsID()))
+	}
+	f.Add("1\x1c2")
+	f.Add("1\x1cz\x1c2")
+	f.Add("-1\x1c\x1c")
+	f.Fuzz(func(t *testing.T, id string) {
+		var nodeDetails NodeDetails
+		if nodeDetails.FromNodeDetailsID(NodeDetailsID(id)) != nil {
+			assert.Equal(t, NodeDetails{}, nodeDetails)
+			return
+		}
+		var reparsed NodeDetails
+		assert.NoError(t, reparsed.FromNodeDetailsID(nodeDetails.ToNodeDetailsID()))
+		assert.Equal(t, nodeDetails.ToNodeDetailsID(), reparsed.ToNodeDetailsID())
+	})
+}
+
+func FuzzFromKeygenID(f *testing.F) {
+	f.Add(string((&KeygenIDDetails{DKGID: GenerateDKGID(*big.NewInt(int64(42))), DealerIndex: 3}).ToKeygenID()))
+	f.Add(string(NullKeygenID))
+	f.Add("SHARING\x1e2a\x1f")
+	f.Add("SHARING\x1e2a\x1f1\x1f2")
+	f.Fuzz(func(t *testing.T, id string) {
+		var keygenIDDetails KeygenIDDetails
+		if keygenIDDetails.FromKeygenID(KeygenID(id)) != nil {
+			assert.Equal(t, KeygenIDDetails{}, keygenIDDetails)
+			return
+		}
+		var reparsed KeygenIDDetails
+		assert.NoError(t, reparsed.FromKeygenID(keygenIDDetails.ToKeygenID()))
+		assert.Equal(t, keygenIDDetails, reparsed)
+	})
+}
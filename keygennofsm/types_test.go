@@ -0,0 +1,90 @@
+package keygennofsm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+// assertWiped checks that every word which backed a secret was zeroed
+func assertWiped(t *testing.T, words [][]big.Word) {
+	for _, w := range words {
+		for i := range w {
+			assert.Equal(t, big.Word(0), w[i])
+		}
+	}
+}
+
+func TestKeyStorageWipe(t *testing.T) {
+	si, siprime := pvss.RandomBigInt(), pvss.RandomBigInt()
+	expectedSi, expectedSiprime := new(big.Int).Set(si), new(big.Int).Set(siprime)
+	keyStorage := newKeyStorage(*big.NewInt(1), si, siprime, nil)
+	assert.Equal(t, 0, keyStorage.Si.Value().Cmp(expectedSi))
+	assert.Equal(t, 0, keyStorage.Siprime.Value().Cmp(expectedSiprime))
+
+	words := [][]big.Word{keyStorage.Si.Value().Bits(), keyStorage.Siprime.Value().Bits()}
+	keyStorage.Wipe()
+	assertWiped(t, words)
+	assert.Equal(t, 0, keyStorage.Si.Value().Sign())
+	// the share of the keygen is still needed after it is output
+	assert.Equal(t, 0, si.Cmp(expectedSi))
+	assert.Equal(t, 0, siprime.Cmp(expectedSiprime))
+}
+
+func TestKeygenWipePolynomials(t *testing.T) {
+	s := pvss.RandomBigInt()
+	keygen := &Keygen{
+		F:      pvss.GenerateRandomBivariatePolynomial(*s, 3),
+		Fprime: pvss.GenerateRandomBivariatePolynomial(*pvss.RandomBigInt(), 3),
+	}
+	var words [][]big.Word
+	for _, poly := range [][][]big.Int{keygen.F, keygen.Fprime} {
+		for i := range poly {
+			for j := range poly[i] {
+				words = append(words, poly[i][j].Bits())
+			}
+		}
+	}
+	keygen.wipePolynomials()
+	assertWiped(t, words)
+	assert.Nil(t, keygen.F)
+	assert.Nil(t, keygen.Fprime)
+	assert.NotEqual(t, 0, s.Sign())
+}
+
+func TestDealingWipesPolynomials(t *testing.T) {
+	var nodeList []pcmn.Node
+	for i := 0; i < 3; i++ {
+		nodeList = append(nodeList, pcmn.Node{
+			Index:  i + 1,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		})
+	}
+	dealer := NewKeygenNode(nodeList[0], nodeList, 1, 2, nodeList[0].Index, &LocalOfflineTransport{}, 0)
+	dealer.CleanUp = noOpCleanUp
+	dkgID := GenerateDKGID(*big.NewInt(int64(0)))
+	keygenID := (&KeygenIDDetails{DKGID: dkgID, DealerIndex: nodeList[0].Index}).ToKeygenID()
+	data, err := bijson.Marshal(KeygenMsgShare{DKGID: dkgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dealer.ProcessMessage(dealer.NodeDetails, CreateKeygenMessage(KeygenMessageRaw{KeygenID: keygenID, Method: "share", Data: data}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keygen, found := dealer.KeygenStore.Get(keygenID)
+	if !found {
+		t.Fatal("expected the dealer to have started the keygen")
+	}
+	keygen.Lock()
+	defer keygen.Unlock()
+	assert.NotEmpty(t, keygen.C)
+	assert.Nil(t, keygen.F)
+	assert.Nil(t, keygen.Fprime)
+}
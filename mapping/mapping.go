@@ -13,6 +13,8 @@ import (
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
 	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/sim"
+	"github.com/torusresearch/torus-node/version"
 )
 
 // ProcessMessage is called when the transport for the node receives a message via direct send.
@@ -24,6 +26,9 @@ import (
 func (mappingNode *MappingNode) ProcessMessage(senderDetails NodeDetails, mappingMessage MappingMessage) error {
 	mappingNode.Lock()
 	defer mappingNode.Unlock()
+	if !version.Accepts(mappingNode.SupportedVersions, string(mappingMessage.Version)) {
+		return fmt.Errorf("mapping message version %v is not supported", mappingMessage.Version)
+	}
 	if mappingMessage.Method == "mapping_propose_freeze" {
 		if !mappingNode.IsOldNode {
 			return errors.New("mapping propose freeze must be handled by old nodes")
@@ -45,11 +50,11 @@ func (mappingNode *MappingNode) ProcessMessage(senderDetails NodeDetails, mappin
 			return err
 		}
 		go func() {
-			err := mappingNode.Transport.SendBroadcast(CreateMappingMessage(MappingMessageRaw{
+			err := mappingNode.sendBroadcast(MappingMessageRaw{
 				MappingID: mappingNode.getMappingID(),
 				Method:    "mapping_propose_freeze",
 				Data:      byt,
-			}))
+			})
 			if err != nil {
 				logging.WithError(err).Error("could not mapping propose freeze")
 			}
@@ -94,11 +99,11 @@ func (mappingNode *MappingNode) ProcessMessage(senderDetails NodeDetails, mappin
 					// Add telemetry
 					telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.SummarySendBroadcast, pcmn.TelemetryConstants.Mapping.Prefix)
 
-					err := mappingNode.Transport.SendBroadcast(CreateMappingMessage(MappingMessageRaw{
+					err := mappingNode.sendBroadcast(MappingMessageRaw{
 						MappingID: mappingNode.getMappingID(),
 						Method:    "mapping_summary_broadcast",
 						Data:      byt,
-					}))
+					})
 					if err != nil {
 						logging.WithError(err).Error("Could not send broadcast for mapping_summary_send_broadcast")
 					}
@@ -146,11 +151,11 @@ func (mappingNode *MappingNode) ProcessMessage(senderDetails NodeDetails, mappin
 
 					telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.KeySendBroadcast, pcmn.TelemetryConstants.Mapping.Prefix)
 
-					err = mappingNode.Transport.SendBroadcast(CreateMappingMessage(MappingMessageRaw{
+					err = mappingNode.sendBroadcast(MappingMessageRaw{
 						MappingID: mappingNode.getMappingID(),
 						Method:    "mapping_key_broadcast",
 						Data:      byt,
-					}))
+					})
 					if err != nil {
 						logging.WithError(err).Error("Could not send broadcast for mapping_key_send_broadcast")
 					}
@@ -168,6 +173,9 @@ func (mappingNode *MappingNode) ProcessMessage(senderDetails NodeDetails, mappin
 func (mappingNode *MappingNode) ProcessBroadcastMessage(mappingMessage MappingMessage) error {
 	mappingNode.Lock()
 	defer mappingNode.Unlock()
+	if !version.Accepts(mappingNode.SupportedVersions, string(mappingMessage.Version)) {
+		return fmt.Errorf("mapping message version %v is not supported", mappingMessage.Version)
+	}
 	if mappingMessage.Method == "mapping_summary_frozen" {
 		if !mappingNode.IsOldNode {
 			return errors.New("mapping propose freeze must be handled by old nodes")
@@ -181,25 +189,17 @@ func (mappingNode *MappingNode) ProcessBroadcastMessage(mappingMessage MappingMe
 		if err != nil {
 			return err
 		}
-		for _, node := range mappingNode.NewNodes.Nodes {
-
-			// Add to metrics
-			telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.SendSummary, pcmn.TelemetryConstants.Mapping.Prefix)
-			go func(node NodeDetails) {
-				err := mappingNode.Transport.Send(node, CreateMappingMessage(MappingMessageRaw{
-					MappingID: mappingNode.getMappingID(),
-					Method:    "mapping_summary",
-					Data:      mappingMessage.Data,
-				}))
-				if err != nil {
-					logging.WithField("message", stringify(mappingMessage)).WithError(err).Error("error when sending mapping message")
-				}
-			}(node)
-		}
+		// Add to metrics
+		telemetry.IncrementCounterBy(pcmn.TelemetryConstants.Mapping.SendSummary, pcmn.TelemetryConstants.Mapping.Prefix, len(mappingNode.NewNodes.Nodes))
+		mappingNode.sendToAll(mappingNode.NewNodes, MappingMessageRaw{
+			MappingID: mappingNode.getMappingID(),
+			Method:    "mapping_summary",
+			Data:      mappingMessage.Data,
+		})
 
 		go func() {
 			for i := 0; i < int(mappingSummaryMessage.TransferSummary.LastUnassignedIndex); i++ {
-				time.Sleep(time.Duration(config.GlobalMutableConfig.GetI("PSSShareDelayMS")) * time.Millisecond)
+				mappingNode.Clock.Sleep(time.Duration(config.GlobalMutableConfig.GetI("PSSShareDelayMS")) * time.Millisecond)
 				key, err := mappingNode.DataSource.RetrieveKeyMapping(*big.NewInt(int64(i)))
 				if err != nil {
 					logging.WithField("index", i).WithError(err).Error("could not retrieve any key for index")
@@ -220,21 +220,13 @@ func (mappingNode *MappingNode) ProcessBroadcastMessage(mappingMessage MappingMe
 					continue
 				}
 
-				for _, node := range mappingNode.NewNodes.Nodes {
-
-					// Add to metrics
-					telemetry.IncrementCounter(pcmn.TelemetryConstants.Mapping.SendKey, pcmn.TelemetryConstants.Mapping.Prefix)
-					go func(node NodeDetails) {
-						err := mappingNode.Transport.Send(node, CreateMappingMessage(MappingMessageRaw{
-							MappingID: mappingNode.getMappingID(),
-							Method:    "mapping_key",
-							Data:      byt,
-						}))
-						if err != nil {
-							logging.WithError(err).Error("could not send mapping key")
-						}
-					}(node)
-				}
+				// Add to metrics
+				telemetry.IncrementCounterBy(pcmn.TelemetryConstants.Mapping.SendKey, pcmn.TelemetryConstants.Mapping.Prefix, len(mappingNode.NewNodes.Nodes))
+				mappingNode.sendToAll(mappingNode.NewNodes, MappingMessageRaw{
+					MappingID: mappingNode.getMappingID(),
+					Method:    "mapping_key",
+					Data:      byt,
+				})
 			}
 		}()
 
@@ -251,6 +243,53 @@ func (mappingNode *MappingNode) getMappingID() MappingID {
 	}).ToMappingID()
 }
 
+// messageVersion returns the version to send a message to every node of nodes in, the lowest one
+// negotiated with any of them, "" for the latest version if the transport does not negotiate
+func (mappingNode *MappingNode) messageVersion(nodes ...NodeDetails) string {
+	negotiator, ok := mappingNode.Transport.(MappingVersionNegotiator)
+	if !ok {
+		return ""
+	}
+	var versions []string
+	for _, node := range nodes {
+		versions = append(versions, negotiator.MessageVersion(node))
+	}
+	return version.Lowest(versions...)
+}
+
+// networkVersion returns the version to send a message to every node of networks in
+func (mappingNode *MappingNode) networkVersion(networks ...NodeNetwork) string {
+	var nodes []NodeDetails
+	for _, network := range networks {
+		for _, node := range network.Nodes {
+			nodes = append(nodes, node)
+		}
+	}
+	return mappingNode.messageVersion(nodes...)
+}
+
+// sendBroadcast broadcasts r in the version negotiated with every node of both epochs
+func (mappingNode *MappingNode) sendBroadcast(r MappingMessageRaw) error {
+	r.Version = mappingNode.networkVersion(mappingNode.OldNodes, mappingNode.NewNodes)
+	return mappingNode.Transport.SendBroadcast(CreateMappingMessage(r))
+}
+
+// sendToAll sends r to every node in network, gossiping it if the transport can
+func (mappingNode *MappingNode) sendToAll(network NodeNetwork, r MappingMessageRaw) {
+	r.Version = mappingNode.networkVersion(network)
+	mappingMessage := CreateMappingMessage(r)
+	var gossip func() error
+	if transport, ok := mappingNode.Transport.(MappingGossipTransport); ok {
+		gossip = func() error { return transport.Gossip(network, mappingMessage) }
+	}
+	var sends []func() error
+	for _, node := range network.Nodes {
+		node := node
+		sends = append(sends, func() error { return mappingNode.Transport.Send(node, mappingMessage) })
+	}
+	pcmn.SendToAll(mappingMessage.Method, gossip, sends)
+}
+
 func NewMappingNode(
 	nodeDetails pcmn.Node,
 	oldEpoch int,
@@ -294,7 +333,9 @@ func NewMappingNode(
 		},
 		IsOldNode: isOldNode,
 		IsNewNode: isNewNode,
+		Clock:     sim.RealClock,
 	}
+	newMappingNode.SupportedVersions = version.SupportedProtocols[version.ProtocolMapping]
 	transport.Init()
 	err := transport.SetMappingNode(newMappingNode)
 	if err != nil {
@@ -0,0 +1,63 @@
+//go:build go1.18
+// +build go1.18
+
+package mapping
+
+import (
+	"testing"
+
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+func FuzzProcessMessage(f *testing.F) {
+	n, k, t := 5, 3, 1
+	var nodeList []pcmn.Node
+	for i := 0; i < n; i++ {
+		nodeList = append(nodeList, pcmn.Node{
+			Index:  i + 1,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		})
+	}
+	keyMappings := make(map[hexstring]MappingKey)
+	var verifiers []pcmn.VerifierData
+	SeedMappings(keyMappings, &verifiers, 3)
+	newNode := func(i int) *MappingNode {
+		return NewMappingNode(nodeList[i], 1, nodeList, t, k, 2, nodeList, t, k, nodeList[i].Index,
+			&LocalOfflineMappingTransport{}, &LocalMappingDataSource{KeyMappings: keyMappings, Verifiers: &verifiers}, true, true)
+	}
+
+	mappingID := (&MappingIDDetails{OldEpoch: 1, NewEpoch: 2}).ToMappingID()
+	type seed struct {
+		sender uint8
+		method string
+		data   interface{}
+	}
+	seeds := []seed{
+		{0, "mapping_propose_freeze", MappingProposeFreezeMessage{MappingID: mappingID}},
+		{1, "mapping_summary", MappingSummaryMessage{TransferSummary: TransferSummary{LastUnassignedIndex: uint(len(keyMappings))}}},
+	}
+	for _, mappingKey := range keyMappings {
+		seeds = append(seeds, seed{2, "mapping_key", MappingKeyMessage{MappingKey: mappingKey}})
+	}
+	for _, seed := range seeds {
+		data, err := bijson.Marshal(seed.data)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed.sender, uint8(0), string(mappingID), seed.method, data)
+	}
+	f.Add(uint8(2), uint8(0), string(mappingID), "mapping_key", []byte(`{"MappingKey":{"index":"zz","publicKey":null}}`))
+
+	f.Fuzz(func(t *testing.T, sender uint8, receiver uint8, mappingID string, method string, data []byte) {
+		node := newNode(int(receiver) % n)
+		_ = node.ProcessMessage(NodeDetails(nodeList[int(sender)%n]), CreateMappingMessage(MappingMessageRaw{
+			MappingID: MappingID(mappingID),
+			Method:    method,
+			Data:      data,
+		}))
+	})
+}
@@ -1,9 +1,12 @@
 package mapping
 
 import (
+	"flag"
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/torusresearch/bijson"
 	"github.com/torusresearch/torus-common/common"
@@ -11,10 +14,22 @@ import (
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/sim"
 )
 
 type hexstring string
 
+var (
+	seed  = flag.Int64("seed", 0, "seed of the simulated mappings, picked from the time if 0")
+	seeds = flag.Int("seeds", 1, "number of seeds the simulated mappings run with, drawn from -seed")
+)
+
+// simulate runs f on the simulation of each of -seeds seeds drawn from -seed, as subtests named
+// after their seed so that a failing run can be repeated
+func simulate(t *testing.T, f func(t *testing.T, simulation *sim.Simulation)) {
+	sim.Run(t, *seed, *seeds, f)
+}
+
 func TestOptimisticMapping(t *testing.T) {
 	numberOfKeys := 1000
 	oldEpoch := 1
@@ -30,58 +45,61 @@ func TestOptimisticMapping(t *testing.T) {
 		MappingProposeSummarys: make(map[MappingID]map[TransferSummaryID]map[NodeDetailsID]bool),
 		MappingProposeKeys:     make(map[MappingID]map[MappingKeyID]map[NodeDetailsID]bool),
 	}
-	outputCh, keyMappings, verifiers, _, oldNodes, newNodes := setupTestNodes(
-		oldEpoch,
-		oldEpochN,
-		oldEpochK,
-		oldEpochT,
-		newEpoch,
-		newEpochN,
-		newEpochK,
-		newEpochT,
-		numberOfKeys,
-		&mockState,
-	)
-	mockState.OldNodes = oldNodes
-	mockState.NewNodes = newNodes
-	mockState.OutputCh = outputCh
-	mappingIDDetails := MappingIDDetails{
-		OldEpoch: oldEpoch,
-		NewEpoch: newEpoch,
-	}
-	mappingID := mappingIDDetails.ToMappingID()
-	SeedMappings(keyMappings, verifiers, numberOfKeys)
-	for _, oldNode := range oldNodes {
-		go func(node *MappingNode) {
-			mappingProposeFreezeMessage := MappingProposeFreezeMessage{
-				MappingID: mappingID,
-			}
-			byt, _ := bijson.Marshal(mappingProposeFreezeMessage)
-			_ = node.Transport.Send(node.NodeDetails, CreateMappingMessage(MappingMessageRaw{
-				MappingID: mappingID,
-				Method:    "mapping_propose_freeze",
-				Data:      byt,
-			}))
-		}(oldNode)
-	}
-	mappingSummaryConfirmedCount := 0
-	mappingKeyConfirmedCount := 0
-	for output := range outputCh {
-		outputStr, ok := output.(string)
-		if !ok {
-			t.Fatal("could not parse output into string")
+	simulate(t, func(t *testing.T, simulation *sim.Simulation) {
+		outputCh, keyMappings, verifiers, _, oldNodes, newNodes := setupTestNodes(
+			oldEpoch,
+			oldEpochN,
+			oldEpochK,
+			oldEpochT,
+			newEpoch,
+			newEpochN,
+			newEpochK,
+			newEpochT,
+			numberOfKeys,
+			&mockState,
+			simulation,
+		)
+		mockState.OldNodes = oldNodes
+		mockState.NewNodes = newNodes
+		mockState.OutputCh = outputCh
+		mappingIDDetails := MappingIDDetails{
+			OldEpoch: oldEpoch,
+			NewEpoch: newEpoch,
 		}
-		if outputStr == "mapping_summary_confirmed" {
-			mappingSummaryConfirmedCount++
-		} else if outputStr == "mapping_key_confirmed" {
-			mappingKeyConfirmedCount++
-		} else {
-			t.Fatal("unexpected string output " + outputStr)
+		mappingID := mappingIDDetails.ToMappingID()
+		SeedMappings(keyMappings, verifiers, numberOfKeys)
+		for _, oldNode := range oldNodes {
+			go func(node *MappingNode) {
+				mappingProposeFreezeMessage := MappingProposeFreezeMessage{
+					MappingID: mappingID,
+				}
+				byt, _ := bijson.Marshal(mappingProposeFreezeMessage)
+				_ = node.Transport.Send(node.NodeDetails, CreateMappingMessage(MappingMessageRaw{
+					MappingID: mappingID,
+					Method:    "mapping_propose_freeze",
+					Data:      byt,
+				}))
+			}(oldNode)
 		}
-		if mappingSummaryConfirmedCount == 1 && mappingKeyConfirmedCount == numberOfKeys {
-			break
+		mappingSummaryConfirmedCount := 0
+		mappingKeyConfirmedCount := 0
+		for output := range outputCh {
+			outputStr, ok := output.(string)
+			if !ok {
+				t.Fatal("could not parse output into string")
+			}
+			if outputStr == "mapping_summary_confirmed" {
+				mappingSummaryConfirmedCount++
+			} else if outputStr == "mapping_key_confirmed" {
+				mappingKeyConfirmedCount++
+			} else {
+				t.Fatal("unexpected string output " + outputStr)
+			}
+			if mappingSummaryConfirmedCount == 1 && mappingKeyConfirmedCount == numberOfKeys {
+				break
+			}
 		}
-	}
+	})
 }
 
 func TestOfflineMapping(t *testing.T) {
@@ -102,65 +120,68 @@ func TestOfflineMapping(t *testing.T) {
 		MappingProposeSummarys: make(map[MappingID]map[TransferSummaryID]map[NodeDetailsID]bool),
 		MappingProposeKeys:     make(map[MappingID]map[MappingKeyID]map[NodeDetailsID]bool),
 	}
-	outputCh, keyMappings, verifiers, localNodeDirectory, oldNodes, newNodes := setupTestNodes(
-		oldEpoch,
-		oldEpochN,
-		oldEpochK,
-		oldEpochT,
-		newEpoch,
-		newEpochN,
-		newEpochK,
-		newEpochT,
-		numberOfKeys,
-		&mockState,
-	)
-	for i := 0; i < offlineNodes; i++ {
-		offlineTransport := &LocalOfflineMappingTransport{}
-		oldNodes[i].Transport = offlineTransport
-		_ = oldNodes[i].Transport.SetMappingNode(oldNodes[i])
-		oldNodes[i].Transport.Init()
-		localNodeDirectory[oldNodes[i].NodeDetails.ToNodeDetailsID()] = offlineTransport
-	}
-	mockState.OldNodes = oldNodes
-	mockState.NewNodes = newNodes
-	mockState.OutputCh = outputCh
-	mappingIDDetails := MappingIDDetails{
-		OldEpoch: oldEpoch,
-		NewEpoch: newEpoch,
-	}
-	mappingID := mappingIDDetails.ToMappingID()
-	SeedMappings(keyMappings, verifiers, numberOfKeys)
-	for _, oldNode := range oldNodes {
-		go func(node *MappingNode) {
-			mappingProposeFreezeMessage := MappingProposeFreezeMessage{
-				MappingID: mappingID,
-			}
-			byt, _ := bijson.Marshal(mappingProposeFreezeMessage)
-			_ = node.Transport.Send(node.NodeDetails, CreateMappingMessage(MappingMessageRaw{
-				MappingID: mappingID,
-				Method:    "mapping_propose_freeze",
-				Data:      byt,
-			}))
-		}(oldNode)
-	}
-	mappingSummaryConfirmedCount := 0
-	mappingKeyConfirmedCount := 0
-	for output := range outputCh {
-		outputStr, ok := output.(string)
-		if !ok {
-			t.Fatal("could not parse output into string")
+	simulate(t, func(t *testing.T, simulation *sim.Simulation) {
+		outputCh, keyMappings, verifiers, localNodeDirectory, oldNodes, newNodes := setupTestNodes(
+			oldEpoch,
+			oldEpochN,
+			oldEpochK,
+			oldEpochT,
+			newEpoch,
+			newEpochN,
+			newEpochK,
+			newEpochT,
+			numberOfKeys,
+			&mockState,
+			simulation,
+		)
+		for i := 0; i < offlineNodes; i++ {
+			offlineTransport := &LocalOfflineMappingTransport{}
+			oldNodes[i].Transport = offlineTransport
+			_ = oldNodes[i].Transport.SetMappingNode(oldNodes[i])
+			oldNodes[i].Transport.Init()
+			localNodeDirectory[oldNodes[i].NodeDetails.ToNodeDetailsID()] = offlineTransport
 		}
-		if outputStr == "mapping_summary_confirmed" {
-			mappingSummaryConfirmedCount++
-		} else if outputStr == "mapping_key_confirmed" {
-			mappingKeyConfirmedCount++
-		} else {
-			t.Fatal("unexpected string output " + outputStr)
+		mockState.OldNodes = oldNodes
+		mockState.NewNodes = newNodes
+		mockState.OutputCh = outputCh
+		mappingIDDetails := MappingIDDetails{
+			OldEpoch: oldEpoch,
+			NewEpoch: newEpoch,
 		}
-		if mappingSummaryConfirmedCount == 1 && mappingKeyConfirmedCount == numberOfKeys {
-			break
+		mappingID := mappingIDDetails.ToMappingID()
+		SeedMappings(keyMappings, verifiers, numberOfKeys)
+		for _, oldNode := range oldNodes {
+			go func(node *MappingNode) {
+				mappingProposeFreezeMessage := MappingProposeFreezeMessage{
+					MappingID: mappingID,
+				}
+				byt, _ := bijson.Marshal(mappingProposeFreezeMessage)
+				_ = node.Transport.Send(node.NodeDetails, CreateMappingMessage(MappingMessageRaw{
+					MappingID: mappingID,
+					Method:    "mapping_propose_freeze",
+					Data:      byt,
+				}))
+			}(oldNode)
 		}
-	}
+		mappingSummaryConfirmedCount := 0
+		mappingKeyConfirmedCount := 0
+		for output := range outputCh {
+			outputStr, ok := output.(string)
+			if !ok {
+				t.Fatal("could not parse output into string")
+			}
+			if outputStr == "mapping_summary_confirmed" {
+				mappingSummaryConfirmedCount++
+			} else if outputStr == "mapping_key_confirmed" {
+				mappingKeyConfirmedCount++
+			} else {
+				t.Fatal("unexpected string output " + outputStr)
+			}
+			if mappingSummaryConfirmedCount == 1 && mappingKeyConfirmedCount == numberOfKeys {
+				break
+			}
+		}
+	})
 }
 
 func SeedMappings(keyMappings map[hexstring]MappingKey, verifiers *[]pcmn.VerifierData, numberOfKeys int) {
@@ -187,7 +208,7 @@ func SeedMappings(keyMappings map[hexstring]MappingKey, verifiers *[]pcmn.Verifi
 	*verifiers = verifiersArr
 }
 
-func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngineState *MockTMEngineState) (
+func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngineState *MockTMEngineState, simulation *sim.Simulation) (
 	outputCh chan interface{},
 	keyMappings map[hexstring]MappingKey,
 	keyVerifiers *[]pcmn.VerifierData,
@@ -205,14 +226,14 @@ func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngine
 	var oldNodePubKeys []common.Point
 	var newNodePubKeys []common.Point
 	for i := 0; i < oN; i++ {
-		oldNodePrivKeys = append(oldNodePrivKeys, *pvss.RandomBigInt())
+		oldNodePrivKeys = append(oldNodePrivKeys, *pvss.RandomBigIntFrom(simulation.Entropy))
 		oldNodePubKeys = append(oldNodePubKeys, common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(oldNodePrivKeys[i].Bytes())))
 	}
 	for i := 0; i < nN; i++ {
-		newNodePrivKeys = append(newNodePrivKeys, *pvss.RandomBigInt())
+		newNodePrivKeys = append(newNodePrivKeys, *pvss.RandomBigIntFrom(simulation.Entropy))
 		newNodePubKeys = append(newNodePubKeys, common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(newNodePrivKeys[i].Bytes())))
 	}
-	oldNodeIndexes := pcmn.RandIndexes(1, 50, oN)
+	oldNodeIndexes := pcmn.RandIndexesFrom(simulation.Rand, 1, 50, oN)
 	var oldNodeList []pcmn.Node
 	for i := 0; i < oN; i++ {
 		oldNodeList = append(oldNodeList, pcmn.Node{
@@ -220,7 +241,7 @@ func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngine
 			PubKey: oldNodePubKeys[i],
 		})
 	}
-	newNodeIndexes := pcmn.RandIndexes(51, 100, nN)
+	newNodeIndexes := pcmn.RandIndexesFrom(simulation.Rand, 51, 100, nN)
 	var newNodeList []pcmn.Node
 	for i := 0; i < nN; i++ {
 		newNodeList = append(newNodeList, pcmn.Node{
@@ -261,6 +282,7 @@ func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngine
 			true,
 			false,
 		)
+		newMappingNode.Clock = simulation.Clock
 		oldNodes = append(oldNodes, newMappingNode)
 		nodeDetails := NodeDetails(node)
 		localNodeDirectory[nodeDetails.ToNodeDetailsID()] = &localMappingTransport
@@ -297,6 +319,7 @@ func setupTestNodes(o, oN, oK, oT, n, nN, nK, nT, numberOfKeys int, mockTMEngine
 			false,
 			true,
 		)
+		newMappingNode.Clock = simulation.Clock
 		newNodes = append(newNodes, newMappingNode)
 		_ = localMappingTransport.SetMappingNode(newMappingNode)
 		_ = localMappingDataSource.SetMappingNode(newMappingNode)
@@ -491,3 +514,95 @@ func NewMockTMEngine(mockTMEngineState *MockTMEngineState, localNodeDirectory *m
 	}
 	return &engine
 }
+
+type recordingMappingTransport struct {
+	LocalOfflineMappingTransport
+	lock     sync.Mutex
+	sent     map[NodeDetailsID]MappingMessage
+	gossiped []NodeNetwork
+}
+
+func (tp *recordingMappingTransport) Send(nodeDetails NodeDetails, mappingMessage MappingMessage) error {
+	tp.lock.Lock()
+	defer tp.lock.Unlock()
+	tp.sent[nodeDetails.ToNodeDetailsID()] = mappingMessage
+	return nil
+}
+
+type gossipMappingTransport struct {
+	recordingMappingTransport
+	err error
+}
+
+func (tp *gossipMappingTransport) Gossip(network NodeNetwork, mappingMessage MappingMessage) error {
+	tp.lock.Lock()
+	defer tp.lock.Unlock()
+	tp.gossiped = append(tp.gossiped, network)
+	return tp.err
+}
+
+type versionedMappingTransport struct {
+	recordingMappingTransport
+	versions map[NodeDetailsID]string
+}
+
+func (tp *versionedMappingTransport) MessageVersion(nodeDetails NodeDetails) string {
+	return tp.versions[nodeDetails.ToNodeDetailsID()]
+}
+
+func TestSendToAll(t *testing.T) {
+	network := NodeNetwork{Nodes: make(map[NodeDetailsID]NodeDetails), EpochID: 2}
+	for i := 1; i <= 3; i++ {
+		node := NodeDetails(pcmn.Node{Index: i, PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(big.NewInt(int64(i)).Bytes()))})
+		network.Nodes[node.ToNodeDetailsID()] = node
+	}
+	message := MappingMessageRaw{Method: "mapping_key"}
+	waitForSends := func(tp *recordingMappingTransport, count int) map[NodeDetailsID]MappingMessage {
+		for i := 0; i < 100; i++ {
+			tp.lock.Lock()
+			sent := len(tp.sent)
+			tp.lock.Unlock()
+			if sent >= count {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		tp.lock.Lock()
+		defer tp.lock.Unlock()
+		return tp.sent
+	}
+
+	direct := &recordingMappingTransport{sent: make(map[NodeDetailsID]MappingMessage)}
+	(&MappingNode{Transport: direct}).sendToAll(network, message)
+	if sent := waitForSends(direct, 3); len(sent) != 3 {
+		t.Fatalf("expected the message to be sent to 3 nodes, got %v", sent)
+	}
+
+	gossip := &gossipMappingTransport{recordingMappingTransport: recordingMappingTransport{sent: make(map[NodeDetailsID]MappingMessage)}}
+	(&MappingNode{Transport: gossip}).sendToAll(network, message)
+	time.Sleep(50 * time.Millisecond)
+	if len(gossip.gossiped) != 1 || gossip.gossiped[0].EpochID != 2 || len(gossip.sent) != 0 {
+		t.Fatalf("expected the message to be gossiped once and not sent, got %v gossips and %v sends", gossip.gossiped, gossip.sent)
+	}
+
+	failing := &gossipMappingTransport{recordingMappingTransport: recordingMappingTransport{sent: make(map[NodeDetailsID]MappingMessage)}, err: fmt.Errorf("not subscribed")}
+	(&MappingNode{Transport: failing}).sendToAll(network, message)
+	if sent := waitForSends(&failing.recordingMappingTransport, 3); len(sent) != 3 {
+		t.Fatalf("expected the message to be sent to each node after gossip failed, got %v", sent)
+	}
+
+	// a message for the whole network is sent in the lowest version negotiated with its nodes
+	versioned := &versionedMappingTransport{recordingMappingTransport: recordingMappingTransport{sent: make(map[NodeDetailsID]MappingMessage)}, versions: make(map[NodeDetailsID]string)}
+	for nodeDetailsID := range network.Nodes {
+		versioned.versions[nodeDetailsID] = "1.0.0"
+		if len(versioned.versions) == 1 {
+			versioned.versions[nodeDetailsID] = "0.9.0"
+		}
+	}
+	(&MappingNode{Transport: versioned}).sendToAll(network, message)
+	for _, sent := range waitForSends(&versioned.recordingMappingTransport, 3) {
+		if sent.Version != "0.9.0" {
+			t.Fatalf("expected the message to be sent in version 0.9.0, got %v", sent.Version)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/torusresearch/torus-common/common"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/sim"
 	"github.com/torusresearch/torus-node/version"
 )
 
@@ -27,6 +29,10 @@ type MappingNode struct {
 	MappingKeys    *MappingKeys
 	IsOldNode      bool
 	IsNewNode      bool
+	// SupportedVersions are the mapping message versions this node processes
+	SupportedVersions []string
+	// Clock is the real clock unless a simulation replaces it before the node processes messages
+	Clock sim.Clock
 }
 type MappingSummary struct {
 	SendCount           *NodeDetailsToIntCMap
@@ -74,6 +80,17 @@ type MappingTransport interface {
 	Output(interface{})
 }
 
+// MappingGossipTransport is implemented by transports that can gossip a message to a whole network, see common.SendToAll
+type MappingGossipTransport interface {
+	Gossip(NodeNetwork, MappingMessage) error
+}
+
+// MappingVersionNegotiator is implemented by transports that negotiate message versions with other
+// nodes, MessageVersion returns the version negotiated with a node, "" if it is not known
+type MappingVersionNegotiator interface {
+	MessageVersion(NodeDetails) string
+}
+
 type MappingDataSource interface {
 	Init()
 	GetType() string
@@ -82,8 +99,12 @@ type MappingDataSource interface {
 }
 
 func CreateMappingMessage(r MappingMessageRaw) MappingMessage {
+	messageVersion := r.Version
+	if messageVersion == "" {
+		messageVersion = version.MappingMessageVersion
+	}
 	return MappingMessage{
-		Version:   mappingMessageVersion(version.NodeVersion),
+		Version:   mappingMessageVersion(messageVersion),
 		MappingID: r.MappingID,
 		Method:    r.Method,
 		Data:      r.Data,
@@ -91,6 +112,8 @@ func CreateMappingMessage(r MappingMessageRaw) MappingMessage {
 }
 
 type MappingMessageRaw struct {
+	// Version is negotiated with the nodes the message is sent to, the latest version if empty
+	Version   string
 	MappingID MappingID
 	Method    string
 	Data      []byte
@@ -125,7 +148,7 @@ func (mappingIDDetails *MappingIDDetails) FromMappingID(mappingID MappingID) err
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 2 {
-		return errors.New("Error parsing PSSIDDetails, too few fields")
+		return errors.New("Error parsing MappingIDDetails, did not find 2 fields exactly")
 	}
 	oldEpoch, err := strconv.Atoi(substrings[0])
 	if err != nil {
@@ -167,28 +190,29 @@ func (n *NodeDetails) ToNodeDetailsID() NodeDetailsID {
 		n.PubKey.Y.Text(16),
 	}, pcmn.Delimiter1))
 }
-func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) {
+func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) error {
 	s := string(nodeDetailsID)
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 3 {
-		return
+		return errors.New("Error parsing NodeDetails, did not find 3 fields exactly")
 	}
 	index, err := strconv.Atoi(substrings[0])
 	if err != nil {
-		return
+		return err
 	}
-	n.Index = index
 	pubkeyX, ok := new(big.Int).SetString(substrings[1], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey X")
 	}
-	n.PubKey.X = *pubkeyX
 	pubkeyY, ok := new(big.Int).SetString(substrings[2], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey Y")
 	}
+	n.Index = index
+	n.PubKey.X = *pubkeyX
 	n.PubKey.Y = *pubkeyY
+	return nil
 }
 
 func mapFromNodeList(nodeList []pcmn.Node) (res map[NodeDetailsID]NodeDetails) {
@@ -230,7 +254,7 @@ func stringify(i interface{}) string {
 	if ok {
 		return str
 	}
-	byt, err := bijson.Marshal(i)
+	byt, err := bijson.Marshal(secret.Redact(i))
 	if err != nil {
 		logging.WithError(err).Error("Could not bijsonmarshal")
 	}
@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package mapping
+
+import (
+	"testing"
+
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+func FuzzFromNodeDetailsID(f *testing.F) {
+	for i := 1; i <= 3; i++ {
+		nodeDetails := NodeDetails{
+			Index:  i,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		}
+		f.Add(string(nodeDetails.ToNodeDetailsID()))
+	}
+	f.Add("1\x1c2")
+	f.Add("1\x1cz\x1c2")
+	f.Add("-1\x1c\x1c")
+	f.Fuzz(func(t *testing.T, id string) {
+		var nodeDetails NodeDetails
+		if nodeDetails.FromNodeDetailsID(NodeDetailsID(id)) != nil {
+			if nodeDetails.ToNodeDetailsID() != (&NodeDetails{}).ToNodeDetailsID() {
+				t.Fatalf("failed parse of %q modified node details to %v", id, nodeDetails)
+			}
+			return
+		}
+		var reparsed NodeDetails
+		if err := reparsed.FromNodeDetailsID(nodeDetails.ToNodeDetailsID()); err != nil {
+			t.Fatalf("could not parse back %v: %v", nodeDetails, err)
+		}
+		if reparsed.ToNodeDetailsID() != nodeDetails.ToNodeDetailsID() {
+			t.Fatalf("round trip of %q gave %v, expected %v", id, reparsed, nodeDetails)
+		}
+	})
+}
+
+func FuzzFromMappingID(f *testing.F) {
+	f.Add(string((&MappingIDDetails{OldEpoch: 1, NewEpoch: 2}).ToMappingID()))
+	f.Add(string(NullMappingID))
+	f.Add("1\x1c")
+	f.Add("1\x1c2\x1c3")
+	f.Fuzz(func(t *testing.T, id string) {
+		var mappingIDDetails MappingIDDetails
+		if mappingIDDetails.FromMappingID(MappingID(id)) != nil {
+			if mappingIDDetails != (MappingIDDetails{}) {
+				t.Fatalf("failed parse of %q modified mapping ID details to %v", id, mappingIDDetails)
+			}
+			return
+		}
+		var reparsed MappingIDDetails
+		if err := reparsed.FromMappingID(mappingIDDetails.ToMappingID()); err != nil {
+			t.Fatalf("could not parse back %v: %v", mappingIDDetails, err)
+		}
+		if reparsed != mappingIDDetails {
+			t.Fatalf("round trip of %q gave %v, expected %v", id, reparsed, mappingIDDetails)
+		}
+	})
+}
@@ -2,7 +2,9 @@ package mrpc
 
 import (
 	"github.com/torusresearch/jsonrpc"
+	"github.com/torusresearch/torus-node/backup"
 	"github.com/torusresearch/torus-node/dealer"
+	"github.com/torusresearch/torus-node/faults"
 )
 
 type (
@@ -43,9 +45,16 @@ type (
 	Actions            struct {
 		RetriggerPSS        RetriggerPSSAction
 		HandleDealerMessage HandleDealerMessage
+		CreateBackup        CreateBackupAction
+		// SetFaults and GetFaults are only set on nodes in debug mode
+		SetFaults SetFaultsAction
+		GetFaults GetFaultsAction
 	}
 	RetriggerPSSAction  func() error
 	HandleDealerMessage func(dealer.Message) error
+	CreateBackupAction  func(path string, passphrase string) (backup.Manifest, error)
+	SetFaultsAction     func(rules []faults.Rule) error
+	GetFaultsAction     func() ([]faults.Rule, faults.Stats)
 
 	DealerMessageHandler struct {
 		HandleDealerMessage HandleDealerMessage
@@ -56,6 +65,35 @@ type (
 	DealerMessageResult struct {
 		Result string
 	}
+
+	CreateBackupHandler struct {
+		CreateBackup CreateBackupAction
+	}
+	CreateBackupParams struct {
+		// Path is on the node's filesystem and must not exist yet
+		Path       string `json:"path"`
+		Passphrase string `json:"passphrase"`
+	}
+	CreateBackupResult struct {
+		Manifest backup.Manifest `json:"manifest"`
+	}
+
+	SetFaultsHandler struct {
+		SetFaults SetFaultsAction
+		GetFaults GetFaultsAction
+	}
+	SetFaultsParams struct {
+		// Rules replace the faults injected by the node, no rules stop injecting faults
+		Rules []faults.Rule `json:"rules"`
+	}
+	GetFaultsHandler struct {
+		GetFaults GetFaultsAction
+	}
+	GetFaultsParams struct{}
+	GetFaultsResult struct {
+		Rules []faults.Rule `json:"rules"`
+		Stats faults.Stats  `json:"stats"`
+	}
 )
 
 func SetupManagementRPCHander(actions Actions) (*jsonrpc.MethodRepository, error) {
@@ -91,5 +129,35 @@ func SetupManagementRPCHander(actions Actions) (*jsonrpc.MethodRepository, error
 		return nil, err
 	}
 
+	err = mr.RegisterMethod(
+		"CreateBackup",
+		CreateBackupHandler{CreateBackup: actions.CreateBackup},
+		CreateBackupParams{},
+		CreateBackupResult{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.RegisterMethod(
+		"SetFaults",
+		SetFaultsHandler{SetFaults: actions.SetFaults, GetFaults: actions.GetFaults},
+		SetFaultsParams{},
+		GetFaultsResult{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.RegisterMethod(
+		"GetFaults",
+		GetFaultsHandler{GetFaults: actions.GetFaults},
+		GetFaultsParams{},
+		GetFaultsResult{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return mr, nil
 }
@@ -105,3 +105,47 @@ func (h DealerMessageHandler) ServeJSONRPC(c context.Context, params *bijson.Raw
 	}
 	return resp, nil
 }
+
+func (h CreateBackupHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	// params are not logged, they contain the passphrase
+	logging.Debug("creating backup")
+	var p CreateBackupParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" || p.Passphrase == "" {
+		return nil, jsonrpc.ErrInvalidParams()
+	}
+	if h.CreateBackup == nil {
+		return nil, &jsonrpc.Error{Code: -32604, Message: "actions are undefined"}
+	}
+	manifest, err := h.CreateBackup(p.Path, p.Passphrase)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32604, Message: "Could not create backup, error: " + err.Error()}
+	}
+	return CreateBackupResult{Manifest: manifest}, nil
+}
+
+func (h SetFaultsHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	logging.WithField("params", params).Debug("setting faults")
+	var p SetFaultsParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if h.SetFaults == nil || h.GetFaults == nil {
+		return nil, &jsonrpc.Error{Code: -32604, Message: "fault injection is only available in debug mode"}
+	}
+	if err := h.SetFaults(p.Rules); err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Could not set faults, error: " + err.Error()}
+	}
+	rules, stats := h.GetFaults()
+	return GetFaultsResult{Rules: rules, Stats: stats}, nil
+}
+
+func (h GetFaultsHandler) ServeJSONRPC(c context.Context, params *bijson.RawMessage) (interface{}, *jsonrpc.Error) {
+	if h.GetFaults == nil {
+		return nil, &jsonrpc.Error{Code: -32604, Message: "fault injection is only available in debug mode"}
+	}
+	rules, stats := h.GetFaults()
+	return GetFaultsResult{Rules: rules, Stats: stats}, nil
+}
@@ -1,6 +1,7 @@
 package pss
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"math"
@@ -18,6 +19,9 @@ import (
 	"github.com/torusresearch/torus-common/secp256k1"
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/sim"
+	"github.com/torusresearch/torus-node/version"
 )
 
 // max(roundUp((n+t+1)/2), k)
@@ -45,6 +49,10 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 	// Add to metrics
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.ProcessedMessageCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
+	if !version.Accepts(pssNode.SupportedVersions, string(pssMessage.Version)) {
+		return fmt.Errorf("pss message version %v is not supported", pssMessage.Version)
+	}
+
 	var dealer dealerState
 	var player playerState
 	if pssNode.IsDealer {
@@ -111,8 +119,10 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 		}
 		sharing.Lock()
 		defer sharing.Unlock()
-		pss.F = pvss.GenerateRandomBivariatePolynomial(sharing.Si, pssNode.NewNodes.K)
-		pss.Fprime = pvss.GenerateRandomBivariatePolynomial(sharing.Siprime, pssNode.NewNodes.K)
+		pss.F = pvss.GenerateRandomBivariatePolynomialFrom(pssNode.Entropy, sharing.Si, pssNode.NewNodes.K)
+		pss.Fprime = pvss.GenerateRandomBivariatePolynomialFrom(pssNode.Entropy, sharing.Siprime, pssNode.NewNodes.K)
+		// the polynomials are only needed to deal the shares
+		defer pss.wipePolynomials()
 		pss.C = pvss.GetCommitmentMatrix(pss.F, pss.Fprime)
 
 		for _, newNode := range pssNode.NewNodes.Nodes {
@@ -129,40 +139,37 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 				Bprime: pvss.EvaluateBivarPolyAtY(pss.Fprime, *big.NewInt(int64(newNode.Index))).Coeff,
 			}
 			data, err := bijson.Marshal(pssMsgSend)
+			for _, evaluation := range [][]big.Int{pssMsgSend.A, pssMsgSend.Aprime, pssMsgSend.B, pssMsgSend.Bprime} {
+				secret.WipeInts(evaluation)
+			}
 			if err != nil {
 				return err
 			}
-			nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+			nextPSSMessage := PSSMessageRaw{
 				PSSID:  pssID,
 				Method: "send",
 				Data:   data,
-			})
-			go func(newN NodeDetails, msg PSSMessage) {
-				err := pssNode.Transport.Send(newN, msg)
+			}
+			go func(newN NodeDetails, msg PSSMessageRaw) {
+				err := pssNode.send(newN, msg)
 				if err != nil {
 					logging.WithError(err).Error("could not send pssMsgSend")
 				}
 			}(newNode, nextPSSMessage)
-
-			data, err = bijson.Marshal(PSSMsgRecover{
-				SharingID: pssMsgShare.SharingID,
-				V:         sharing.C,
-			})
-			if err != nil {
-				return err
-			}
-			nextNextPSSMessage := CreatePSSMessage(PSSMessageRaw{
-				PSSID:  NullPSSID,
-				Method: "recover",
-				Data:   data,
-			})
-			go func(newN NodeDetails, msg PSSMessage) {
-				err := pssNode.Transport.Send(newN, msg)
-				if err != nil {
-					logging.WithError(err).Error("could not send pssMsgRecover")
-				}
-			}(newNode, nextNextPSSMessage)
 		}
+		// the recover message is the same for every new node
+		data, err := bijson.Marshal(PSSMsgRecover{
+			SharingID: pssMsgShare.SharingID,
+			V:         sharing.C,
+		})
+		if err != nil {
+			return err
+		}
+		pssNode.sendToAll(pssNode.NewNodes, PSSMessageRaw{
+			PSSID:  NullPSSID,
+			Method: "recover",
+			Data:   data,
+		})
 		defer func() { pss.State.Phase = States.Phases.Started }()
 		return nil
 	} else if pssMessage.Method == "recover" {
@@ -253,6 +260,7 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 			pcmn.PrimaryPolynomial{Coeff: pssMsgSend.Bprime, Threshold: pssNode.NewNodes.K},
 		)
 		if !verified {
+			pssNode.reportInvalid(senderDetails, "send")
 			return errors.New("Could not verify polys against commitment")
 		}
 		logging.WithFields(logging.Fields{
@@ -288,13 +296,13 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 			if err != nil {
 				return err
 			}
-			nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+			nextPSSMessage := PSSMessageRaw{
 				PSSID:  pss.PSSID,
 				Method: "echo",
 				Data:   data,
-			})
-			go func(newN NodeDetails, msg PSSMessage) {
-				err := pssNode.Transport.Send(newN, msg)
+			}
+			go func(newN NodeDetails, msg PSSMessageRaw) {
+				err := pssNode.send(newN, msg)
 				if err != nil {
 					logging.WithError(err).Info()
 				}
@@ -345,6 +353,7 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 
 			// Add to metrics
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.InvalidEchoCounter, pcmn.TelemetryConstants.PSS.Prefix)
+			pssNode.reportInvalid(senderDetails, "echo")
 
 			return fmt.Errorf("Could not verify point against commitments for echo message for sender %v", senderDetails)
 		}
@@ -430,13 +439,13 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 				if err != nil {
 					return err
 				}
-				nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+				nextPSSMessage := PSSMessageRaw{
 					PSSID:  pss.PSSID,
 					Method: "ready",
 					Data:   data,
-				})
-				go func(newN NodeDetails, msg PSSMessage) {
-					err := pssNode.Transport.Send(newN, msg)
+				}
+				go func(newN NodeDetails, msg PSSMessageRaw) {
+					err := pssNode.send(newN, msg)
 					if err != nil {
 						logging.WithError(err).Info()
 					}
@@ -498,6 +507,7 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 
 			// Add to metrics
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.InvalidReadyCounter, pcmn.TelemetryConstants.PSS.Prefix)
+			pssNode.reportInvalid(senderDetails, "ready")
 
 			return fmt.Errorf("Could not verify point against commitments for ready message for sender %v, msg %v, senderDetailsIndex %v, pssNodeIndex %v", senderDetails, stringify(pssMsgReady), senderDetails.Index, pssNode.NodeDetails.Index)
 		}
@@ -506,6 +516,7 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 
 			// Add to metrics
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.ReadySigInvalidCounter, pcmn.TelemetryConstants.PSS.Prefix)
+			pssNode.reportInvalid(senderDetails, "ready signature")
 
 			return errors.New("Could not verify signature on message: " + strings.Join([]string{string(pss.PSSID), "ready"}, pcmn.Delimiter1))
 		}
@@ -593,13 +604,13 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 				if err != nil {
 					return err
 				}
-				nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+				nextPSSMessage := PSSMessageRaw{
 					PSSID:  pss.PSSID,
 					Method: "ready",
 					Data:   data,
-				})
-				go func(newN NodeDetails, msg PSSMessage) {
-					err := pssNode.Transport.Send(newN, msg)
+				}
+				go func(newN NodeDetails, msg PSSMessageRaw) {
+					err := pssNode.send(newN, msg)
 					if err != nil {
 						logging.WithError(err).Error("could not send pssMsgReady")
 					}
@@ -627,13 +638,13 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 			if err != nil {
 				return err
 			}
-			nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+			nextPSSMessage := PSSMessageRaw{
 				PSSID:  NullPSSID,
 				Method: "complete",
 				Data:   data,
-			})
-			go func(ownNode NodeDetails, ownMsg PSSMessage) {
-				err := pssNode.Transport.Send(ownNode, ownMsg)
+			}
+			go func(ownNode NodeDetails, ownMsg PSSMessageRaw) {
+				err := pssNode.send(ownNode, ownMsg)
 				if err != nil {
 					logging.WithError(err).Error("could not send pssMsgComplete in ready")
 				}
@@ -678,7 +689,7 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.InsufficientRecoversCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
 			pss.Unlock()
-			time.Sleep(1 * time.Second)
+			pssNode.Clock.Sleep(1 * time.Second)
 			pss.Lock()
 		}
 		recover.Lock()
@@ -740,21 +751,21 @@ func (pssNode *PSSNode) ProcessMessage(senderDetails NodeDetails, pssMessage PSS
 			if err != nil {
 				return err
 			}
-			nextPSSMessage := CreatePSSMessage(PSSMessageRaw{
+			nextPSSMessage := PSSMessageRaw{
 				PSSID:  NullPSSID,
 				Method: "propose",
 				Data:   data,
-			})
+			}
 			keygenID := pssIDDetails.SharingID.GetKeygenID()
 			keyIndex := keygenID.GetIndex()
-			go func(pssMessage PSSMessage, kI int) {
+			go func(pssMessage PSSMessageRaw, kI int) {
 				// stagger as an optimization
 				pssNode.stagger(keyIndex)
 				recover.Lock()
 				sendBroadcast := recover.Si.Cmp(big.NewInt(0)) == 0
 				recover.Unlock()
 				if sendBroadcast {
-					err := pssNode.Transport.SendBroadcast(pssMessage)
+					err := pssNode.sendBroadcast(pssMessage)
 					if err != nil {
 						logging.WithError(err).Error("could not send broadcast pssMsgPropose")
 					}
@@ -782,6 +793,10 @@ func (pssNode *PSSNode) ProcessBroadcastMessage(pssMessage PSSMessage) error {
 	// Add to metrics
 	telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.ProcessedBroadcastMessagesCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
+	if !version.Accepts(pssNode.SupportedVersions, string(pssMessage.Version)) {
+		return fmt.Errorf("pss message version %v is not supported", pssMessage.Version)
+	}
+
 	logging.WithFields(logging.Fields{
 		"NodeDetails":      string(pssNode.NodeDetails.ToNodeDetailsID())[0:8],
 		"pssMessageMethod": pssMessage.Method,
@@ -815,7 +830,7 @@ func (pssNode *PSSNode) ProcessBroadcastMessage(pssMessage PSSMessage) error {
 			telemetry.IncrementCounter(pcmn.TelemetryConstants.PSS.DecidedSharingsNotCompleteCounter, pcmn.TelemetryConstants.PSS.Prefix)
 
 			logging.WithField("pssMsgDecide", pssMsgDecide).WithField("pssRefs", pssRefs).WithField("set", pssMsgDecide.PSSs).Debug("waiting for all sharings in decided set to complete")
-			time.Sleep(1 * time.Second)
+			pssNode.Clock.Sleep(1 * time.Second)
 		}
 		recover.Lock()
 		defer recover.Unlock()
@@ -895,14 +910,10 @@ func (pssNode *PSSNode) ProcessBroadcastMessage(pssMessage PSSMessage) error {
 			return errors.New("Could not setstring for keygenID " + string(keygenID))
 		}
 
-		go func(keyIndex big.Int, si big.Int, siprime big.Int, commitmentPoly []common.Point) {
-			pssNode.Transport.Output(RefreshKeyStorage{
-				KeyIndex:       keyIndex,
-				Si:             si,
-				Siprime:        siprime,
-				CommitmentPoly: commitmentPoly,
-			})
-		}(*keyIndex, recover.Si, recover.Siprime, recover.Vbar)
+		// the share is copied while recover is locked
+		go func(refreshKeyStorage RefreshKeyStorage) {
+			pssNode.Transport.Output(refreshKeyStorage)
+		}(newRefreshKeyStorage(*keyIndex, &recover.Si, &recover.Siprime, recover.Vbar))
 		err = pssNode.CleanUp(pssNode, recover.SharingID)
 		if err != nil {
 			logging.WithError(err).WithField("sharingID", recover.SharingID).Error("could not clean up pss")
@@ -922,6 +933,66 @@ var pssCleanUp = func(pssNode *PSSNode, sharingID SharingID) error {
 
 var noOpPSSCleanUp = func(*PSSNode, SharingID) error { return nil }
 
+// messageVersion returns the version to send a message to every node of nodes in, the lowest one
+// negotiated with any of them, "" for the latest version if the transport does not negotiate
+func (pssNode *PSSNode) messageVersion(nodes ...NodeDetails) string {
+	negotiator, ok := pssNode.Transport.(PSSVersionNegotiator)
+	if !ok {
+		return ""
+	}
+	var versions []string
+	for _, node := range nodes {
+		versions = append(versions, negotiator.MessageVersion(node))
+	}
+	return version.Lowest(versions...)
+}
+
+// networkVersion returns the version to send a message to every node of networks in
+func (pssNode *PSSNode) networkVersion(networks ...NodeNetwork) string {
+	var nodes []NodeDetails
+	for _, network := range networks {
+		for _, node := range network.Nodes {
+			nodes = append(nodes, node)
+		}
+	}
+	return pssNode.messageVersion(nodes...)
+}
+
+// send sends r to node in the version negotiated with it
+func (pssNode *PSSNode) send(node NodeDetails, r PSSMessageRaw) error {
+	r.Version = pssNode.messageVersion(node)
+	return pssNode.Transport.Send(node, CreatePSSMessage(r))
+}
+
+// sendBroadcast broadcasts r in the version negotiated with every node of both epochs
+func (pssNode *PSSNode) sendBroadcast(r PSSMessageRaw) error {
+	r.Version = pssNode.networkVersion(pssNode.OldNodes, pssNode.NewNodes)
+	return pssNode.Transport.SendBroadcast(CreatePSSMessage(r))
+}
+
+// sendToAll sends r to every node in network, gossiping it if the transport can
+func (pssNode *PSSNode) sendToAll(network NodeNetwork, r PSSMessageRaw) {
+	r.Version = pssNode.networkVersion(network)
+	pssMessage := CreatePSSMessage(r)
+	var gossip func() error
+	if transport, ok := pssNode.Transport.(PSSGossipTransport); ok {
+		gossip = func() error { return transport.Gossip(network, pssMessage) }
+	}
+	var sends []func() error
+	for _, node := range network.Nodes {
+		node := node
+		sends = append(sends, func() error { return pssNode.Transport.Send(node, pssMessage) })
+	}
+	pcmn.SendToAll(pssMessage.Method, gossip, sends)
+}
+
+// reportInvalid reports a message of sender that failed verification, if the transport tracks nodes
+func (pssNode *PSSNode) reportInvalid(sender NodeDetails, reason string) {
+	if reporter, ok := pssNode.Transport.(PSSMisbehaviourReporter); ok {
+		reporter.ReportInvalidMessage(sender, reason)
+	}
+}
+
 // NewPSSNode creates a new pss node instance
 func NewPSSNode(
 	nodeDetails pcmn.Node,
@@ -962,7 +1033,10 @@ func NewPSSNode(
 		IsPlayer:     isPlayer,
 		CleanUp:      pssCleanUp,
 		staggerDelay: staggerDelay,
+		Clock:        sim.RealClock,
+		Entropy:      rand.Reader,
 	}
+	newPSSNode.SupportedVersions = version.SupportedProtocols[version.ProtocolPSS]
 	newPSSNode.PSSStore = &PSSStoreSyncMap{
 		nodes: &newPSSNode.NewNodes,
 	}
@@ -990,7 +1064,7 @@ func stringify(i interface{}) string {
 	if ok {
 		return str
 	}
-	byt, err := bijson.Marshal(i)
+	byt, err := bijson.Marshal(secret.Redact(i))
 	if err != nil {
 		logging.WithError(err).Error("Could not bijsonmarshal")
 	}
@@ -1055,5 +1129,5 @@ func checkSufficientRecoversIfNotComplete(pssNode *PSSNode, sharingID SharingID)
 func (pssNode *PSSNode) stagger(seed int) {
 	index := pssNode.NodeDetails.Index
 	nodeSetLength := pssNode.NewNodes.N
-	time.Sleep(time.Duration(((seed+index)%nodeSetLength)*pssNode.staggerDelay) * time.Millisecond)
+	pssNode.Clock.Sleep(time.Duration(((seed+index)%nodeSetLength)*pssNode.staggerDelay) * time.Millisecond)
 }
@@ -0,0 +1,143 @@
+//go:build go1.18
+// +build go1.18
+
+package pss
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/torusresearch/bijson"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+// sentPSSMessage is a direct send captured by recordingTransport.
+type sentPSSMessage struct {
+	from NodeDetails
+	to   NodeDetails
+	msg  PSSMessage
+}
+
+// recordingTransport captures direct sends so that fuzz seeds come from messages the protocol really produces.
+// Sends are dropped when there is no channel to record them on.
+type recordingTransport struct {
+	LocalOfflineTransport
+	pssNode *PSSNode
+	sent    chan sentPSSMessage
+}
+
+func (r *recordingTransport) SetPSSNode(ref *PSSNode) error {
+	r.pssNode = ref
+	return nil
+}
+
+func (r *recordingTransport) Send(nodeDetails NodeDetails, pssMessage PSSMessage) error {
+	if r.sent != nil {
+		r.sent <- sentPSSMessage{r.pssNode.NodeDetails, nodeDetails, pssMessage}
+	}
+	return nil
+}
+
+func (r *recordingTransport) Output(sinter interface{}) {}
+
+func (r *recordingTransport) collect(f *testing.F, count int) (res []sentPSSMessage) {
+	for i := 0; i < count; i++ {
+		select {
+		case s := <-r.sent:
+			res = append(res, s)
+		case <-time.After(5 * time.Second):
+			f.Fatalf("only recorded %v of %v messages", i, count)
+		}
+	}
+	return
+}
+
+func FuzzProcessMessage(f *testing.F) {
+	n, k, t := 5, 3, 1
+	var nodeList []pcmn.Node
+	for i := 0; i < n; i++ {
+		privKey := pvss.RandomBigInt()
+		nodeList = append(nodeList, pcmn.Node{
+			Index:  i + 1,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(privKey.Bytes())),
+		})
+	}
+	position := make(map[NodeDetailsID]uint8)
+	for i, node := range nodeList {
+		nodeDetails := NodeDetails(node)
+		position[nodeDetails.ToNodeDetailsID()] = uint8(i)
+	}
+	keygenID := GenerateKeygenID(0)
+	sharingID := keygenID.GetSharingID(1, n, k, t, 2, n, k, t)
+	randPoly := pvss.RandomPoly(*pvss.RandomBigInt(), k)
+	randPolyprime := pvss.RandomPoly(*pvss.RandomBigInt(), k)
+	sumCommitments := pvss.AddCommitments(pvss.GetCommit(*randPoly), pvss.GetCommitH(*randPolyprime))
+	sharings := make(map[int]map[KeygenID]*Sharing)
+	for _, node := range nodeList {
+		sharings[node.Index] = map[KeygenID]*Sharing{
+			keygenID: {
+				KeygenID: keygenID,
+				Nodes:    nodeList,
+				Epoch:    1,
+				I:        node.Index,
+				Si:       *pvss.PolyEval(*randPoly, *big.NewInt(int64(node.Index))),
+				Siprime:  *pvss.PolyEval(*randPolyprime, *big.NewInt(int64(node.Index))),
+				C:        sumCommitments,
+			},
+		}
+	}
+	newNode := func(i int, transport PSSTransport) *PSSNode {
+		node := NewPSSNode(nodeList[i], 1, nodeList, t, k, 2, nodeList, t, k, nodeList[i].Index,
+			&LocalDataSource{Index: nodeList[i].Index, Sharings: &sharings}, transport, true, true, 0)
+		node.CleanUp = noOpPSSCleanUp
+		return node
+	}
+
+	// record a dealing from node 0 and the echoes it causes
+	pssID := (&PSSIDDetails{SharingID: sharingID, DealerIndex: nodeList[0].Index}).ToPSSID()
+	data, err := bijson.Marshal(PSSMsgShare{SharingID: sharingID})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(uint8(0), uint8(0), string(pssID), "share", data)
+	dealerTransport := &recordingTransport{sent: make(chan sentPSSMessage, 2*n)}
+	dealer := newNode(0, dealerTransport)
+	err = dealer.ProcessMessage(dealer.NodeDetails, CreatePSSMessage(PSSMessageRaw{PSSID: pssID, Method: "share", Data: data}))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, sent := range dealerTransport.collect(f, 2*n) {
+		receiver := position[sent.to.ToNodeDetailsID()]
+		f.Add(uint8(0), receiver, string(sent.msg.PSSID), sent.msg.Method, sent.msg.Data)
+		if sent.msg.Method != "send" {
+			continue
+		}
+		transport := &recordingTransport{sent: make(chan sentPSSMessage, n)}
+		err = newNode(int(receiver), transport).ProcessMessage(sent.from, sent.msg)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, echo := range transport.collect(f, n) {
+			f.Add(receiver, position[echo.to.ToNodeDetailsID()], string(echo.msg.PSSID), echo.msg.Method, echo.msg.Data)
+		}
+	}
+	f.Add(uint8(1), uint8(2), string(pssID), "ready", []byte(`{"C":[[]]}`))
+	f.Add(uint8(1), uint8(2), string(NullPSSID), "recover", []byte(`{"V":[]}`))
+
+	f.Fuzz(func(test *testing.T, sender uint8, receiver uint8, pssID string, method string, data []byte) {
+		if method == "complete" {
+			// complete waits until the node has enough recover messages, which never happens here
+			test.Skip()
+		}
+		node := newNode(int(receiver)%n, &recordingTransport{})
+		_ = node.ProcessMessage(NodeDetails(nodeList[int(sender)%n]), CreatePSSMessage(PSSMessageRaw{
+			PSSID:  PSSID(pssID),
+			Method: method,
+			Data:   data,
+		}))
+	})
+}
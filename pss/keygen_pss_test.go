@@ -2,9 +2,9 @@ package pss
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"math/big"
-	"math/rand"
 	"runtime"
 	"strconv"
 	"strings"
@@ -19,96 +19,110 @@ import (
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
 	"github.com/torusresearch/torus-node/pvss"
+	"github.com/torusresearch/torus-node/sim"
 )
 
+var (
+	seed  = flag.Int64("seed", 0, "seed of the simulated PSS runs, picked from the time if 0")
+	seeds = flag.Int("seeds", 1, "number of seeds the simulated PSS runs run with, drawn from -seed")
+)
+
+// simulate runs f on the simulation of each of -seeds seeds drawn from -seed, as subtests named
+// after their seed so that a failing run can be repeated
+func simulate(test *testing.T, f func(test *testing.T, simulation *sim.Simulation)) {
+	sim.Run(test, *seed, *seeds, f)
+}
+
 func TestPSSOptimistic(test *testing.T) {
 	logging.SetLevel(logging.DebugLevel)
 	keys := 10
 	n := 9
 	k := 5
 	t := 2
-	sharedCh, refreshedCh, nodes, _, _, _, _, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, n, k, t, n, k, t, true)
-	fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(n) + " nodes with reconstruction " + strconv.Itoa(k) + " and threshold " + strconv.Itoa(t))
-	for _, keygenID := range keygenIDs {
-		for _, node := range nodes {
-			pssMsgShare := PSSMsgShare{
-				SharingID: keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
-			}
-			data, err := bijson.Marshal(pssMsgShare)
-			if err != nil {
-				test.Fatal(err)
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, refreshedCh, nodes, _, _, _, _, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, n, k, t, n, k, t, true, simulation)
+		fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(n) + " nodes with reconstruction " + strconv.Itoa(k) + " and threshold " + strconv.Itoa(t))
+		for _, keygenID := range keygenIDs {
+			for _, node := range nodes {
+				pssMsgShare := PSSMsgShare{
+					SharingID: keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
+				}
+				data, err := bijson.Marshal(pssMsgShare)
+				if err != nil {
+					test.Fatal(err)
+				}
+				err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
+					PSSID: (&PSSIDDetails{
+						SharingID:   keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
+						DealerIndex: node.NodeDetails.Index,
+					}).ToPSSID(),
+					Method: "share",
+					Data:   data,
+				}))
+				assert.NoError(test, err)
 			}
-			err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
-				PSSID: (&PSSIDDetails{
-					SharingID:   keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
-					DealerIndex: node.NodeDetails.Index,
-				}).ToPSSID(),
-				Method: "share",
-				Data:   data,
-			}))
-			assert.NoError(test, err)
 		}
-	}
-	completeMessages := 0
-	for completeMessages < n*n*keys {
-		msg := <-sharedCh
-		if strings.Contains(msg, "shared") {
-			completeMessages++
-		} else {
-			assert.Fail(test, "did not get shared message")
+		completeMessages := 0
+		for completeMessages < n*n*keys {
+			msg := <-sharedCh
+			if strings.Contains(msg, "shared") {
+				completeMessages++
+			} else {
+				assert.Fail(test, "did not get shared message")
+			}
 		}
-	}
-	assert.Equal(test, completeMessages, n*n*keys)
+		assert.Equal(test, completeMessages, n*n*keys)
 
-	refreshedMessages := 0
-	for refreshedMessages < n*keys {
-		msg := <-refreshedCh
-		if strings.Contains(msg, "refreshed") {
-			refreshedMessages++
-		} else {
-			assert.Fail(test, "did not get refreshed message")
+		refreshedMessages := 0
+		for refreshedMessages < n*keys {
+			msg := <-refreshedCh
+			if strings.Contains(msg, "refreshed") {
+				refreshedMessages++
+			} else {
+				assert.Fail(test, "did not get refreshed message")
+			}
 		}
-	}
-	assert.Equal(test, refreshedMessages, n*keys)
-
-	for g, keygenID := range keygenIDs {
-		var shares []pcmn.PrimaryShare
-		for _, node := range nodes {
-			var subshares []pcmn.PrimaryShare
-			for _, noderef := range nodes { // assuming that all nodes are part of the valid set
-				pss, _ := node.PSSStore.Get((&PSSIDDetails{
-					SharingID:   keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
-					DealerIndex: noderef.NodeDetails.Index,
-				}).ToPSSID())
-				val := pss.Si
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, pcmn.PrimaryShare{
-						Index: noderef.NodeDetails.Index,
-						Value: val,
-					})
+		assert.Equal(test, refreshedMessages, n*keys)
+
+		for g, keygenID := range keygenIDs {
+			var shares []pcmn.PrimaryShare
+			for _, node := range nodes {
+				var subshares []pcmn.PrimaryShare
+				for _, noderef := range nodes { // assuming that all nodes are part of the valid set
+					pss, _ := node.PSSStore.Get((&PSSIDDetails{
+						SharingID:   keygenID.GetSharingID(1, n, k, t, 2, n, k, t),
+						DealerIndex: noderef.NodeDetails.Index,
+					}).ToPSSID())
+					val := pss.Si
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, pcmn.PrimaryShare{
+							Index: noderef.NodeDetails.Index,
+							Value: val,
+						})
+					}
 				}
+				reconstructedSi := pvss.LagrangeScalar(subshares[0:k], 0)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: *reconstructedSi,
+				})
 			}
-			reconstructedSi := pvss.LagrangeScalar(subshares[0:k], 0)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: *reconstructedSi,
-			})
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
+			assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
 		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:k], 0)
-		assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
-	}
 
-	for i, keygenID := range keygenIDs {
-		var pts []common.Point
-		for _, node := range nodes {
-			recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, n, k, t, 2, n, k, t))
-			pts = append(pts, common.Point{
-				X: *big.NewInt(int64(node.NodeDetails.Index)),
-				Y: recover.Si,
-			})
+		for i, keygenID := range keygenIDs {
+			var pts []common.Point
+			for _, node := range nodes {
+				recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, n, k, t, 2, n, k, t))
+				pts = append(pts, common.Point{
+					X: *big.NewInt(int64(node.NodeDetails.Index)),
+					Y: recover.Si,
+				})
+			}
+			assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
 		}
-		assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
-	}
+	})
 }
 
 func TestPSSDifferentThresholds(test *testing.T) {
@@ -121,103 +135,105 @@ func TestPSSDifferentThresholds(test *testing.T) {
 	nNew := 13
 	kNew := 7
 	tNew := 3
-	sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, _, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false)
-	fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
-	fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
-	logging.WithFields(logging.Fields{
-		"oldNodeList": oldNodeList,
-		"newNodeList": newNodeList,
-	}).Debug()
-	for _, keygenID := range keygenIDs {
-		for _, node := range oldNodes {
-			pssMsgShare := PSSMsgShare{
-				SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-			}
-			data, err := bijson.Marshal(pssMsgShare)
-			if err != nil {
-				test.Fatal(err)
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, _, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false, simulation)
+		fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
+		fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
+		logging.WithFields(logging.Fields{
+			"oldNodeList": oldNodeList,
+			"newNodeList": newNodeList,
+		}).Debug()
+		for _, keygenID := range keygenIDs {
+			for _, node := range oldNodes {
+				pssMsgShare := PSSMsgShare{
+					SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+				}
+				data, err := bijson.Marshal(pssMsgShare)
+				if err != nil {
+					test.Fatal(err)
+				}
+				pssID := (&PSSIDDetails{
+					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+					DealerIndex: node.NodeDetails.Index,
+				}).ToPSSID()
+				err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
+					PSSID:  pssID,
+					Method: "share",
+					Data:   data,
+				}))
+				assert.NoError(test, err)
 			}
-			pssID := (&PSSIDDetails{
-				SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-				DealerIndex: node.NodeDetails.Index,
-			}).ToPSSID()
-			err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
-				PSSID:  pssID,
-				Method: "share",
-				Data:   data,
-			}))
-			assert.NoError(test, err)
 		}
-	}
-	completeMessages := 0
-	for completeMessages < nOld*nNew*keys {
-		msg := <-sharedCh
-		if strings.Contains(msg, "shared") {
-			completeMessages++
-		} else {
-			assert.Fail(test, "did not get shared message")
+		completeMessages := 0
+		for completeMessages < nOld*nNew*keys {
+			msg := <-sharedCh
+			if strings.Contains(msg, "shared") {
+				completeMessages++
+			} else {
+				assert.Fail(test, "did not get shared message")
+			}
 		}
-	}
-	assert.Equal(test, completeMessages, nOld*nNew*keys)
+		assert.Equal(test, completeMessages, nOld*nNew*keys)
 
-	refreshedMessages := 0
-	for refreshedMessages < nNew*keys {
-		msg := <-refreshedCh
-		if strings.Contains(msg, "refreshed") {
-			refreshedMessages++
-		} else {
-			assert.Fail(test, "did not get refreshed message")
+		refreshedMessages := 0
+		for refreshedMessages < nNew*keys {
+			msg := <-refreshedCh
+			if strings.Contains(msg, "refreshed") {
+				refreshedMessages++
+			} else {
+				assert.Fail(test, "did not get refreshed message")
+			}
 		}
-	}
-	assert.Equal(test, refreshedMessages, nNew*keys)
-
-	for g, keygenID := range keygenIDs {
-		var shares []pcmn.PrimaryShare
-		for _, node := range newNodes {
-			var subshares []pcmn.PrimaryShare
-			for _, noderef := range oldNodes { // assuming that all nodes are part of the valid set
-				pss, _ := node.PSSStore.Get((&PSSIDDetails{
-					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-					DealerIndex: noderef.NodeDetails.Index,
-				}).ToPSSID())
-				val := pss.Si
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, pcmn.PrimaryShare{
-						Index: noderef.NodeDetails.Index,
-						Value: val,
-					})
-				} else {
-					test.Fatal("Si is 0")
+		assert.Equal(test, refreshedMessages, nNew*keys)
+
+		for g, keygenID := range keygenIDs {
+			var shares []pcmn.PrimaryShare
+			for _, node := range newNodes {
+				var subshares []pcmn.PrimaryShare
+				for _, noderef := range oldNodes { // assuming that all nodes are part of the valid set
+					pss, _ := node.PSSStore.Get((&PSSIDDetails{
+						SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+						DealerIndex: noderef.NodeDetails.Index,
+					}).ToPSSID())
+					val := pss.Si
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, pcmn.PrimaryShare{
+							Index: noderef.NodeDetails.Index,
+							Value: val,
+						})
+					} else {
+						test.Fatal("Si is 0")
+					}
 				}
+				reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: *reconstructedSi,
+				})
 			}
-			reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: *reconstructedSi,
-			})
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
+			assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
 		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
-		assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
-	}
 
-	for i, keygenID := range keygenIDs {
-		var pts []common.Point
-		for _, node := range newNodes {
-			recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
-			if recover == nil {
-				node.RecoverStore.Range(func(key, inter interface{}) bool {
-					fmt.Println(key, inter)
-					return true
+		for i, keygenID := range keygenIDs {
+			var pts []common.Point
+			for _, node := range newNodes {
+				recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
+				if recover == nil {
+					node.RecoverStore.Range(func(key, inter interface{}) bool {
+						fmt.Println(key, inter)
+						return true
+					})
+				}
+				pts = append(pts, common.Point{
+					X: *big.NewInt(int64(node.NodeDetails.Index)),
+					Y: recover.Si,
 				})
 			}
-			pts = append(pts, common.Point{
-				X: *big.NewInt(int64(node.NodeDetails.Index)),
-				Y: recover.Si,
-			})
+			assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
 		}
-		assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
-	}
 
+	})
 }
 
 func TestPSSLaggyNodes(test *testing.T) {
@@ -230,167 +246,169 @@ func TestPSSLaggyNodes(test *testing.T) {
 	nNew := 9
 	kNew := 5
 	tNew := 2
-	sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, localDirectory, oldNodePrivKeys, newNodePrivKeys, secrets, keygenIDs, oldTMEngine, newTMEngine := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false)
-	fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
-	fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
-	logging.WithFields(logging.Fields{
-		"oldNodeList": oldNodeList,
-		"newNodeList": newNodeList,
-	}).Debug()
-
-	// make offline nodes
-	for i := 0; i < tOld; i++ {
-		oldNode := oldNodes[i]
-		fmt.Println("Laggy Old Node: ", string(oldNode.NodeDetails.ToNodeDetailsID())[0:8])
-		localLaggyTransport := &LocalLaggyTransport{
-			NodeDirectory:          localDirectory,
-			PrivateKey:             &oldNodePrivKeys[i],
-			OutputSharedChannel:    &sharedCh,
-			OutputRefreshedChannel: &refreshedCh,
-			MockTMEngine:           oldTMEngine,
-		}
-		oldNode.Transport = localLaggyTransport
-		_ = localLaggyTransport.SetPSSNode(oldNode)
-		(*localDirectory)[oldNode.NodeDetails.ToNodeDetailsID()] = localLaggyTransport
-	}
-	for i := 0; i < tNew; i++ {
-		newNode := newNodes[i]
-		fmt.Println("Laggy New Node: ", string(newNode.NodeDetails.ToNodeDetailsID())[0:8])
-		localLaggyTransport := &LocalLaggyTransport{
-			NodeDirectory:          localDirectory,
-			PrivateKey:             &newNodePrivKeys[i],
-			OutputSharedChannel:    &sharedCh,
-			OutputRefreshedChannel: &refreshedCh,
-			MockTMEngine:           newTMEngine,
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, localDirectory, oldNodePrivKeys, newNodePrivKeys, secrets, keygenIDs, oldTMEngine, newTMEngine := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false, simulation)
+		fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
+		fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
+		logging.WithFields(logging.Fields{
+			"oldNodeList": oldNodeList,
+			"newNodeList": newNodeList,
+		}).Debug()
+
+		// make offline nodes
+		for i := 0; i < tOld; i++ {
+			oldNode := oldNodes[i]
+			fmt.Println("Laggy Old Node: ", string(oldNode.NodeDetails.ToNodeDetailsID())[0:8])
+			localLaggyTransport := &LocalLaggyTransport{
+				NodeDirectory:          localDirectory,
+				PrivateKey:             &oldNodePrivKeys[i],
+				OutputSharedChannel:    &sharedCh,
+				OutputRefreshedChannel: &refreshedCh,
+				MockTMEngine:           oldTMEngine,
+			}
+			oldNode.Transport = localLaggyTransport
+			_ = localLaggyTransport.SetPSSNode(oldNode)
+			(*localDirectory)[oldNode.NodeDetails.ToNodeDetailsID()] = localLaggyTransport
 		}
+		for i := 0; i < tNew; i++ {
+			newNode := newNodes[i]
+			fmt.Println("Laggy New Node: ", string(newNode.NodeDetails.ToNodeDetailsID())[0:8])
+			localLaggyTransport := &LocalLaggyTransport{
+				NodeDirectory:          localDirectory,
+				PrivateKey:             &newNodePrivKeys[i],
+				OutputSharedChannel:    &sharedCh,
+				OutputRefreshedChannel: &refreshedCh,
+				MockTMEngine:           newTMEngine,
+			}
 
-		newNode.Transport = localLaggyTransport
-		_ = localLaggyTransport.SetPSSNode(newNode)
-		(*localDirectory)[newNode.NodeDetails.ToNodeDetailsID()] = localLaggyTransport
-	}
+			newNode.Transport = localLaggyTransport
+			_ = localLaggyTransport.SetPSSNode(newNode)
+			(*localDirectory)[newNode.NodeDetails.ToNodeDetailsID()] = localLaggyTransport
+		}
 
-	for _, keygenID := range keygenIDs {
-		for _, node := range oldNodes {
-			pssMsgShare := PSSMsgShare{
-				SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-			}
-			pssID := (&PSSIDDetails{
-				SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-				DealerIndex: node.NodeDetails.Index,
-			}).ToPSSID()
-			data, err := bijson.Marshal(pssMsgShare)
-			if err != nil {
-				test.Fatal(err)
+		for _, keygenID := range keygenIDs {
+			for _, node := range oldNodes {
+				pssMsgShare := PSSMsgShare{
+					SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+				}
+				pssID := (&PSSIDDetails{
+					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+					DealerIndex: node.NodeDetails.Index,
+				}).ToPSSID()
+				data, err := bijson.Marshal(pssMsgShare)
+				if err != nil {
+					test.Fatal(err)
+				}
+				// fmt.Println(node.Transport, node.NodeDetails, node.Transport)
+				err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
+					PSSID:  pssID,
+					Method: "share",
+					Data:   data,
+				}))
+				assert.NoError(test, err)
 			}
-			// fmt.Println(node.Transport, node.NodeDetails, node.Transport)
-			err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
-				PSSID:  pssID,
-				Method: "share",
-				Data:   data,
-			}))
-			assert.NoError(test, err)
 		}
-	}
-	cont := make(chan bool)
-	completeMessages := 0
-	go func() {
-		for completeMessages < (tOld+kOld)*(tNew+kNew)*keys {
-			msg := <-sharedCh
-			if strings.Contains(msg, "shared") {
+		cont := make(chan bool)
+		completeMessages := 0
+		go func() {
+			for completeMessages < (tOld+kOld)*(tNew+kNew)*keys {
+				msg := <-sharedCh
+				if strings.Contains(msg, "shared") {
+					completeMessages++
+					logging.WithField("completeMessages", completeMessages).Debug("received shared message")
+				} else {
+					assert.Fail(test, "did not get shared message")
+				}
+			}
+			cont <- true
+			for {
+				<-sharedCh
 				completeMessages++
-				logging.WithField("completeMessages", completeMessages).Debug("received shared message")
-			} else {
-				assert.Fail(test, "did not get shared message")
 			}
-		}
-		cont <- true
-		for {
-			<-sharedCh
-			completeMessages++
-		}
-	}()
-	<-cont
-
-	// assert.Equal(test, completeMessages, (tOld+kOld)*(tNew+kNew)*keys)
-	// logging.Debug("all completed messages received")
-
-	refreshedMessages := 0
-	go func(cont chan bool) {
-		for refreshedMessages < (tNew+kNew)*keys {
-			msg := <-refreshedCh
-			if strings.Contains(msg, "refreshed") {
+		}()
+		<-cont
+
+		// assert.Equal(test, completeMessages, (tOld+kOld)*(tNew+kNew)*keys)
+		// logging.Debug("all completed messages received")
+
+		refreshedMessages := 0
+		go func(cont chan bool) {
+			for refreshedMessages < (tNew+kNew)*keys {
+				msg := <-refreshedCh
+				if strings.Contains(msg, "refreshed") {
+					refreshedMessages++
+					logging.WithField("refreshedMessages", refreshedMessages).Debug("received refreshed message")
+				} else {
+					assert.Fail(test, "did not get refreshed message")
+				}
+			}
+			cont <- true
+			for {
+				<-refreshedCh
 				refreshedMessages++
-				logging.WithField("refreshedMessages", refreshedMessages).Debug("received refreshed message")
-			} else {
-				assert.Fail(test, "did not get refreshed message")
 			}
-		}
-		cont <- true
-		for {
-			<-refreshedCh
-			refreshedMessages++
-		}
-	}(cont)
-	<-cont
-	// assert.Equal(test, refreshedMessages, (tNew+kNew)*keys)
-	// logging.Debug("all refreshed messages received")
-here:
-	fmt.Println("RERUN", refreshedMessages, completeMessages)
-	for g, keygenID := range keygenIDs {
-		var shares []pcmn.PrimaryShare
-		for _, node := range newNodes[2:] {
-			var subshares []pcmn.PrimaryShare
-			for _, noderef := range oldNodes[2:] { // assuming that all nodes are part of the valid set
-				pss, _ := node.PSSStore.Get((&PSSIDDetails{
-					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-					DealerIndex: noderef.NodeDetails.Index,
-				}).ToPSSID())
-				val := pss.Si
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, pcmn.PrimaryShare{
-						Index: noderef.NodeDetails.Index,
-						Value: val,
-					})
-				} else {
-					time.Sleep(50 * time.Millisecond)
-					// test.Fatal("Si is 0")
-					goto here
+		}(cont)
+		<-cont
+		// assert.Equal(test, refreshedMessages, (tNew+kNew)*keys)
+		// logging.Debug("all refreshed messages received")
+	here:
+		fmt.Println("RERUN", refreshedMessages, completeMessages)
+		for g, keygenID := range keygenIDs {
+			var shares []pcmn.PrimaryShare
+			for _, node := range newNodes[2:] {
+				var subshares []pcmn.PrimaryShare
+				for _, noderef := range oldNodes[2:] { // assuming that all nodes are part of the valid set
+					pss, _ := node.PSSStore.Get((&PSSIDDetails{
+						SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+						DealerIndex: noderef.NodeDetails.Index,
+					}).ToPSSID())
+					val := pss.Si
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, pcmn.PrimaryShare{
+							Index: noderef.NodeDetails.Index,
+							Value: val,
+						})
+					} else {
+						time.Sleep(50 * time.Millisecond)
+						// test.Fatal("Si is 0")
+						goto here
+					}
 				}
+				reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: *reconstructedSi,
+				})
 			}
-			reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: *reconstructedSi,
-			})
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
+			assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
 		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
-		assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
-	}
 
-	for i, keygenID := range keygenIDs {
-		var pts []common.Point
-		for _, node := range newNodes[2:] {
-			recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
-			pts = append(pts, common.Point{
-				X: *big.NewInt(int64(node.NodeDetails.Index)),
-				Y: recover.Si,
-			})
-		}
+		for i, keygenID := range keygenIDs {
+			var pts []common.Point
+			for _, node := range newNodes[2:] {
+				recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
+				pts = append(pts, common.Point{
+					X: *big.NewInt(int64(node.NodeDetails.Index)),
+					Y: recover.Si,
+				})
+			}
 
-		// retry a few times
-		fails := 0
-		for fails < 3 {
-			if pvss.LagrangeScalarCP(pts, 0).Text(16) == secrets[i].Text(16) {
-				break
-			} else {
-				fails++
-				time.Sleep(1 * time.Second)
+			// retry a few times
+			fails := 0
+			for fails < 3 {
+				if pvss.LagrangeScalarCP(pts, 0).Text(16) == secrets[i].Text(16) {
+					break
+				} else {
+					fails++
+					time.Sleep(1 * time.Second)
+				}
+			}
+			if fails == 2 {
+				assert.Fail(test, "failed thrice")
 			}
 		}
-		if fails == 2 {
-			assert.Fail(test, "failed thrice")
-		}
-	}
+	})
 }
 
 func TestPSSOfflineNodes(test *testing.T) {
@@ -403,124 +421,126 @@ func TestPSSOfflineNodes(test *testing.T) {
 	nNew := 9
 	kNew := 5
 	tNew := 2
-	sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, localDirectory, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false)
-	fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
-	fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
-	logging.WithFields(logging.Fields{
-		"oldNodeList": oldNodeList,
-		"newNodeList": newNodeList,
-	}).Debug()
-
-	// make offline nodes
-	for i := 0; i < tOld; i++ {
-		oldNode := oldNodes[i]
-		fmt.Println("Offline Old Node: ", string(oldNode.NodeDetails.ToNodeDetailsID())[0:8])
-		localOfflineTransport := &LocalOfflineTransport{
-			OutputSharedChannel:    &sharedCh,
-			OutputRefreshedChannel: &refreshedCh,
+	simulate(test, func(test *testing.T, simulation *sim.Simulation) {
+		sharedCh, refreshedCh, oldNodes, oldNodeList, newNodes, newNodeList, localDirectory, _, _, secrets, keygenIDs, _, _ := SetupTestNodes(keys, nOld, kOld, tOld, nNew, kNew, tNew, false, simulation)
+		fmt.Println("Running TestKeygenSharing for " + strconv.Itoa(keys) + " keys, " + strconv.Itoa(nOld) + " nodes with reconstruction " + strconv.Itoa(kOld) + " and threshold " + strconv.Itoa(tOld))
+		fmt.Println("redistributing to " + strconv.Itoa(nNew) + " nodes with reconstruction " + strconv.Itoa(kNew) + " and threshold " + strconv.Itoa(tNew))
+		logging.WithFields(logging.Fields{
+			"oldNodeList": oldNodeList,
+			"newNodeList": newNodeList,
+		}).Debug()
+
+		// make offline nodes
+		for i := 0; i < tOld; i++ {
+			oldNode := oldNodes[i]
+			fmt.Println("Offline Old Node: ", string(oldNode.NodeDetails.ToNodeDetailsID())[0:8])
+			localOfflineTransport := &LocalOfflineTransport{
+				OutputSharedChannel:    &sharedCh,
+				OutputRefreshedChannel: &refreshedCh,
+			}
+			oldNode.Transport = localOfflineTransport
+			(*localDirectory)[oldNode.NodeDetails.ToNodeDetailsID()] = localOfflineTransport
 		}
-		oldNode.Transport = localOfflineTransport
-		(*localDirectory)[oldNode.NodeDetails.ToNodeDetailsID()] = localOfflineTransport
-	}
-	for i := 0; i < tNew; i++ {
-		newNode := newNodes[i]
-		fmt.Println("Offline New Node: ", string(newNode.NodeDetails.ToNodeDetailsID())[0:8])
-		localOfflineTransport := &LocalOfflineTransport{
-			OutputSharedChannel:    &sharedCh,
-			OutputRefreshedChannel: &refreshedCh,
+		for i := 0; i < tNew; i++ {
+			newNode := newNodes[i]
+			fmt.Println("Offline New Node: ", string(newNode.NodeDetails.ToNodeDetailsID())[0:8])
+			localOfflineTransport := &LocalOfflineTransport{
+				OutputSharedChannel:    &sharedCh,
+				OutputRefreshedChannel: &refreshedCh,
+			}
+			newNode.Transport = localOfflineTransport
+			(*localDirectory)[newNode.NodeDetails.ToNodeDetailsID()] = localOfflineTransport
 		}
-		newNode.Transport = localOfflineTransport
-		(*localDirectory)[newNode.NodeDetails.ToNodeDetailsID()] = localOfflineTransport
-	}
 
-	for _, keygenID := range keygenIDs {
-		for _, node := range oldNodes {
-			pssMsgShare := PSSMsgShare{
-				SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-			}
-			pssID := (&PSSIDDetails{
-				SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-				DealerIndex: node.NodeDetails.Index,
-			}).ToPSSID()
-			data, err := bijson.Marshal(pssMsgShare)
-			if err != nil {
-				test.Fatal(err)
+		for _, keygenID := range keygenIDs {
+			for _, node := range oldNodes {
+				pssMsgShare := PSSMsgShare{
+					SharingID: keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+				}
+				pssID := (&PSSIDDetails{
+					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+					DealerIndex: node.NodeDetails.Index,
+				}).ToPSSID()
+				data, err := bijson.Marshal(pssMsgShare)
+				if err != nil {
+					test.Fatal(err)
+				}
+				err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
+					PSSID:  pssID,
+					Method: "share",
+					Data:   data,
+				}))
+				assert.NoError(test, err)
 			}
-			err = node.Transport.Send(node.NodeDetails, CreatePSSMessage(PSSMessageRaw{
-				PSSID:  pssID,
-				Method: "share",
-				Data:   data,
-			}))
-			assert.NoError(test, err)
 		}
-	}
-	completeMessages := 0
-	for completeMessages < (tOld+kOld)*(tNew+kNew)*keys {
-		msg := <-sharedCh
-		if strings.Contains(msg, "shared") {
-			completeMessages++
-			logging.WithField("completeMessages", completeMessages).Debug("received shared message")
-		} else {
-			assert.Fail(test, "did not get shared message")
-		}
-	}
-	assert.Equal(test, completeMessages, (tOld+kOld)*(tNew+kNew)*keys)
-	logging.Debug("all completed messages received")
-
-	refreshedMessages := 0
-	for refreshedMessages < (tNew+kNew)*keys {
-		msg := <-refreshedCh
-		if strings.Contains(msg, "refreshed") {
-			refreshedMessages++
-			logging.WithField("refreshedMessages", refreshedMessages).Debug("received refreshed message")
-		} else {
-			assert.Fail(test, "did not get refreshed message")
+		completeMessages := 0
+		for completeMessages < (tOld+kOld)*(tNew+kNew)*keys {
+			msg := <-sharedCh
+			if strings.Contains(msg, "shared") {
+				completeMessages++
+				logging.WithField("completeMessages", completeMessages).Debug("received shared message")
+			} else {
+				assert.Fail(test, "did not get shared message")
+			}
 		}
-	}
-	assert.Equal(test, refreshedMessages, (tNew+kNew)*keys)
-	logging.Debug("all refreshed messages received")
-
-	for g, keygenID := range keygenIDs {
-		var shares []pcmn.PrimaryShare
-		for _, node := range newNodes[2:] {
+		assert.Equal(test, completeMessages, (tOld+kOld)*(tNew+kNew)*keys)
+		logging.Debug("all completed messages received")
 
-			var subshares []pcmn.PrimaryShare
-			for _, noderef := range oldNodes[2:] { // assuming that all nodes are part of the valid set
-				pss, _ := node.PSSStore.Get((&PSSIDDetails{
-					SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
-					DealerIndex: noderef.NodeDetails.Index,
-				}).ToPSSID())
-				val := pss.Si
-				if val.Cmp(big.NewInt(int64(0))) != 0 {
-					subshares = append(subshares, pcmn.PrimaryShare{
-						Index: noderef.NodeDetails.Index,
-						Value: val,
-					})
-				} else {
-					test.Fatal("Si is 0")
+		refreshedMessages := 0
+		for refreshedMessages < (tNew+kNew)*keys {
+			msg := <-refreshedCh
+			if strings.Contains(msg, "refreshed") {
+				refreshedMessages++
+				logging.WithField("refreshedMessages", refreshedMessages).Debug("received refreshed message")
+			} else {
+				assert.Fail(test, "did not get refreshed message")
+			}
+		}
+		assert.Equal(test, refreshedMessages, (tNew+kNew)*keys)
+		logging.Debug("all refreshed messages received")
+
+		for g, keygenID := range keygenIDs {
+			var shares []pcmn.PrimaryShare
+			for _, node := range newNodes[2:] {
+
+				var subshares []pcmn.PrimaryShare
+				for _, noderef := range oldNodes[2:] { // assuming that all nodes are part of the valid set
+					pss, _ := node.PSSStore.Get((&PSSIDDetails{
+						SharingID:   keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew),
+						DealerIndex: noderef.NodeDetails.Index,
+					}).ToPSSID())
+					val := pss.Si
+					if val.Cmp(big.NewInt(int64(0))) != 0 {
+						subshares = append(subshares, pcmn.PrimaryShare{
+							Index: noderef.NodeDetails.Index,
+							Value: val,
+						})
+					} else {
+						test.Fatal("Si is 0")
+					}
 				}
+				reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
+				shares = append(shares, pcmn.PrimaryShare{
+					Index: node.NodeDetails.Index,
+					Value: *reconstructedSi,
+				})
 			}
-			reconstructedSi := pvss.LagrangeScalar(subshares[0:kOld], 0)
-			shares = append(shares, pcmn.PrimaryShare{
-				Index: node.NodeDetails.Index,
-				Value: *reconstructedSi,
-			})
+			reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
+			assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
 		}
-		reconstructedSecret := pvss.LagrangeScalar(shares[0:kNew], 0)
-		assert.Equal(test, reconstructedSecret.Text(16), secrets[g].Text(16))
-	}
 
-	for i, keygenID := range keygenIDs {
-		var pts []common.Point
-		for _, node := range newNodes[2:] {
-			recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
-			pts = append(pts, common.Point{
-				X: *big.NewInt(int64(node.NodeDetails.Index)),
-				Y: recover.Si,
-			})
+		for i, keygenID := range keygenIDs {
+			var pts []common.Point
+			for _, node := range newNodes[2:] {
+				recover, _ := node.RecoverStore.Get(keygenID.GetSharingID(1, nOld, kOld, tOld, 2, nNew, kNew, tNew))
+				pts = append(pts, common.Point{
+					X: *big.NewInt(int64(node.NodeDetails.Index)),
+					Y: recover.Si,
+				})
+			}
+			assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
 		}
-		assert.Equal(test, pvss.LagrangeScalarCP(pts, 0).Text(16), secrets[i].Text(16))
-	}
+	})
 }
 
 // var LocalNodeDirectory map[string]*LocalTransport
@@ -822,19 +842,6 @@ func (l *LocalTransport) runReceiveMiddleware(pssMessage PSSMessage) (PSSMessage
 	return modifiedMessage, nil
 }
 
-func randIndexes(min int, max int, length int) (res []int) {
-	if max < min {
-		return
-	}
-	diff := max - min
-	rand.Seed(time.Now().UnixNano())
-	p := rand.Perm(diff)
-	for _, r := range p[:length] {
-		res = append(res, r+min)
-	}
-	return
-}
-
 func MockEngine(engineState *MockEngineState, localTransportNodeDirectory *map[NodeDetailsID]PSSTransport) func(NodeDetails, PSSMessage) error {
 
 	return func(senderDetails NodeDetails, pssMessage PSSMessage) error {
@@ -953,6 +960,7 @@ func SetupTestNodes(
 	kNew int,
 	tNew int,
 	sameNodes bool,
+	simulation *sim.Simulation,
 ) (sharedChannel chan string,
 	refreshedChannel chan string,
 	theOldNodes []*PSSNode,
@@ -973,15 +981,15 @@ func SetupTestNodes(
 	var oldNodePubKeys []common.Point
 	var newNodePubKeys []common.Point
 	for i := 0; i < nOld; i++ {
-		oldNodePrivKeys = append(oldNodePrivKeys, *pvss.RandomBigInt())
+		oldNodePrivKeys = append(oldNodePrivKeys, *pvss.RandomBigIntFrom(simulation.Entropy))
 		oldNodePubKeys = append(oldNodePubKeys, common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(oldNodePrivKeys[i].Bytes())))
 	}
-	oldNodeIndexes := randIndexes(1, 50, nOld)
+	oldNodeIndexes := pcmn.RandIndexesFrom(simulation.Rand, 1, 50, nOld)
 	for i := 0; i < nNew; i++ {
-		newNodePrivKeys = append(newNodePrivKeys, *pvss.RandomBigInt())
+		newNodePrivKeys = append(newNodePrivKeys, *pvss.RandomBigIntFrom(simulation.Entropy))
 		newNodePubKeys = append(newNodePubKeys, common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(newNodePrivKeys[i].Bytes())))
 	}
-	newNodeIndexes := randIndexes(51, 100, nNew)
+	newNodeIndexes := pcmn.RandIndexesFrom(simulation.Rand, 51, 100, nNew)
 	var oldNodeList []pcmn.Node
 	for i := 0; i < nOld; i++ {
 		oldNodeList = append(oldNodeList, pcmn.Node{
@@ -1005,11 +1013,11 @@ func SetupTestNodes(
 		currentExistingSharings[newNodeIndex] = make(map[KeygenID]*Sharing)
 	}
 	for h := 0; h < keys; h++ {
-		secret := pvss.RandomBigInt()
+		secret := pvss.RandomBigIntFrom(simulation.Entropy)
 		secrets = append(secrets, *secret)
-		mask := pvss.RandomBigInt()
-		randPoly := pvss.RandomPoly(*secret, kOld)
-		randPolyprime := pvss.RandomPoly(*mask, kOld)
+		mask := pvss.RandomBigIntFrom(simulation.Entropy)
+		randPoly := pvss.RandomPolyFrom(simulation.Entropy, *secret, kOld)
+		randPolyprime := pvss.RandomPolyFrom(simulation.Entropy, *mask, kOld)
 		commit := pvss.GetCommit(*randPoly)
 		commitH := pvss.GetCommitH(*randPolyprime)
 		sumCommitments := pvss.AddCommitments(commit, commitH)
@@ -1084,6 +1092,8 @@ func SetupTestNodes(
 			0,
 		)
 		newPssNode.CleanUp = noOpPSSCleanUp
+		newPssNode.Clock = simulation.Clock
+		newPssNode.Entropy = simulation.Entropy.Fork()
 		oldNodes = append(oldNodes, newPssNode)
 		_ = localTransport.SetPSSNode(newPssNode)
 		nodeDetails := NodeDetails(node)
@@ -1129,6 +1139,8 @@ func SetupTestNodes(
 				0,
 			)
 			newPssNode.CleanUp = noOpPSSCleanUp
+			newPssNode.Clock = simulation.Clock
+			newPssNode.Entropy = simulation.Entropy.Fork()
 			newNodes = append(newNodes, newPssNode)
 			_ = localTransport.SetPSSNode(newPssNode)
 			nodeDetails := NodeDetails(node)
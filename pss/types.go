@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"strconv"
 	"strings"
@@ -15,6 +16,8 @@ import (
 
 	pcmn "github.com/torusresearch/torus-node/common"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/secret"
+	"github.com/torusresearch/torus-node/sim"
 
 	"github.com/torusresearch/torus-node/version"
 )
@@ -158,29 +161,29 @@ func GetVIDFromPointArray(ptArr []common.Point) VID {
 type PSSMsgSend struct {
 	PSSID  PSSID
 	C      [][]common.Point
-	A      []big.Int
-	Aprime []big.Int
-	B      []big.Int
-	Bprime []big.Int
+	A      []big.Int `secret:"true"`
+	Aprime []big.Int `secret:"true"`
+	B      []big.Int `secret:"true"`
+	Bprime []big.Int `secret:"true"`
 }
 
 type PSSMsgEcho struct {
 	PSSID      PSSID
 	C          [][]common.Point
-	Alpha      big.Int
-	Alphaprime big.Int
-	Beta       big.Int
-	Betaprime  big.Int
+	Alpha      big.Int `secret:"true"`
+	Alphaprime big.Int `secret:"true"`
+	Beta       big.Int `secret:"true"`
+	Betaprime  big.Int `secret:"true"`
 }
 
 type SignedText []byte
 type PSSMsgReady struct {
 	PSSID      PSSID
 	C          [][]common.Point
-	Alpha      big.Int
-	Alphaprime big.Int
-	Beta       big.Int
-	Betaprime  big.Int
+	Alpha      big.Int `secret:"true"`
+	Alphaprime big.Int `secret:"true"`
+	Beta       big.Int `secret:"true"`
+	Betaprime  big.Int `secret:"true"`
 	SignedText SignedText
 }
 
@@ -223,6 +226,7 @@ func (keygenID *KeygenID) GetIndex() int {
 	i, ok := new(big.Int).SetString(substrs[1], 16)
 	if !ok {
 		logging.WithField("keygenID", keygenID).Error("could not convert index of keygenID")
+		return 0
 	}
 	return int(i.Int64())
 }
@@ -276,8 +280,8 @@ type SharingData struct {
 	Nodes    []pcmn.Node
 	Epoch    int
 	I        int
-	Si       big.Int
-	Siprime  big.Int
+	Si       big.Int `secret:"true"`
+	Siprime  big.Int `secret:"true"`
 	C        []common.Point
 }
 
@@ -287,8 +291,8 @@ type Sharing struct {
 	Nodes    []pcmn.Node
 	Epoch    int
 	I        int
-	Si       big.Int
-	Siprime  big.Int
+	Si       big.Int `secret:"true"`
+	Siprime  big.Int `secret:"true"`
 	C        []common.Point
 }
 
@@ -298,8 +302,8 @@ type Recover struct {
 	D                *[]common.Point
 	DCount           map[VID]map[NodeDetailsID]bool
 	PSSCompleteCount map[PSSID]bool
-	Si               big.Int
-	Siprime          big.Int
+	Si               big.Int `secret:"true"`
+	Siprime          big.Int `secret:"true"`
 	Vbar             []common.Point
 }
 
@@ -307,16 +311,23 @@ type PSS struct {
 	idmutex.Mutex
 	PSSID   PSSID
 	Epoch   int
-	Si      big.Int
-	Siprime big.Int
-	F       [][]big.Int
-	Fprime  [][]big.Int
+	Si      big.Int     `secret:"true"`
+	Siprime big.Int     `secret:"true"`
+	F       [][]big.Int `secret:"true"`
+	Fprime  [][]big.Int `secret:"true"`
 	Cbar    [][]common.Point
 	C       [][]common.Point
 	CStore  map[CID]*C
 	State   PSSState
 }
 
+// wipePolynomials wipes the polynomials of a dealing
+func (pss *PSS) wipePolynomials() {
+	secret.WipeMatrix(pss.F)
+	secret.WipeMatrix(pss.Fprime)
+	pss.F, pss.Fprime = nil, nil
+}
+
 func GetCIDFromPointMatrix(pm [][]common.Point) CID {
 	var bytes []byte
 	delimiter := pcmn.Delimiter1
@@ -342,10 +353,10 @@ type C struct {
 	ACprime         map[NodeDetailsID]common.Point
 	BC              map[NodeDetailsID]common.Point
 	BCprime         map[NodeDetailsID]common.Point
-	Abar            []big.Int
-	Abarprime       []big.Int
-	Bbar            []big.Int
-	Bbarprime       []big.Int
+	Abar            []big.Int `secret:"true"`
+	Abarprime       []big.Int `secret:"true"`
+	Bbar            []big.Int `secret:"true"`
+	Bbarprime       []big.Int `secret:"true"`
 	SignedTextStore map[NodeDetailsID]SignedText
 }
 
@@ -364,8 +375,12 @@ func GetSignedTextArrayFromMap(sts map[NodeDetailsID]SignedText) (res []SignedTe
 }
 
 func CreatePSSMessage(r PSSMessageRaw) PSSMessage {
+	messageVersion := r.Version
+	if messageVersion == "" {
+		messageVersion = version.PSSMessageVersion
+	}
 	return PSSMessage{
-		Version: pssMessageVersion(version.NodeVersion),
+		Version: pssMessageVersion(messageVersion),
 		PSSID:   r.PSSID,
 		Method:  r.Method,
 		Data:    r.Data,
@@ -373,9 +388,11 @@ func CreatePSSMessage(r PSSMessageRaw) PSSMessage {
 }
 
 type PSSMessageRaw struct {
-	PSSID  PSSID
-	Method string
-	Data   []byte
+	// Version is negotiated with the nodes the message is sent to, the latest version if empty
+	Version string
+	PSSID   PSSID
+	Method  string
+	Data    []byte
 }
 
 type pssMessageVersion string
@@ -384,7 +401,7 @@ type PSSMessage struct {
 	Version pssMessageVersion `json:"version,omitempty"`
 	PSSID   PSSID             `json:"pssid"`
 	Method  string            `json:"type"`
-	Data    []byte            `json:"data"`
+	Data    []byte            `json:"data" secret:"true"`
 }
 
 // PSSID is the identifying string for PSSMessage, each PSS has n PSSIDs, all associated with a single SharingID
@@ -407,13 +424,13 @@ func (pssIDDetails *PSSIDDetails) FromPSSID(pssID PSSID) error {
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 2 {
-		return errors.New("Error parsing PSSIDDetails, too few fields")
+		return errors.New("Error parsing PSSIDDetails, did not find 2 fields exactly")
 	}
-	pssIDDetails.SharingID = SharingID(substrings[0])
 	index, err := strconv.Atoi(substrings[1])
 	if err != nil {
 		return err
 	}
+	pssIDDetails.SharingID = SharingID(substrings[0])
 	pssIDDetails.DealerIndex = index
 	return nil
 }
@@ -429,28 +446,29 @@ func (n *NodeDetails) ToNodeDetailsID() NodeDetailsID {
 		n.PubKey.Y.Text(16),
 	}, pcmn.Delimiter1))
 }
-func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) {
+func (n *NodeDetails) FromNodeDetailsID(nodeDetailsID NodeDetailsID) error {
 	s := string(nodeDetailsID)
 	substrings := strings.Split(s, pcmn.Delimiter1)
 
 	if len(substrings) != 3 {
-		return
+		return errors.New("Error parsing NodeDetails, did not find 3 fields exactly")
 	}
 	index, err := strconv.Atoi(substrings[0])
 	if err != nil {
-		return
+		return err
 	}
-	n.Index = index
 	pubkeyX, ok := new(big.Int).SetString(substrings[1], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey X")
 	}
-	n.PubKey.X = *pubkeyX
 	pubkeyY, ok := new(big.Int).SetString(substrings[2], 16)
 	if !ok {
-		return
+		return errors.New("Error parsing NodeDetails, invalid pubkey Y")
 	}
+	n.Index = index
+	n.PubKey.X = *pubkeyX
 	n.PubKey.Y = *pubkeyY
+	return nil
 }
 
 type PSSDataSource interface {
@@ -471,6 +489,23 @@ type PSSTransport interface {
 	Output(interface{})
 }
 
+// PSSGossipTransport is implemented by transports that can gossip a message to a whole network, see common.SendToAll
+type PSSGossipTransport interface {
+	Gossip(NodeNetwork, PSSMessage) error
+}
+
+// PSSVersionNegotiator is implemented by transports that negotiate message versions with other
+// nodes, MessageVersion returns the version negotiated with a node, "" if it is not known
+type PSSVersionNegotiator interface {
+	MessageVersion(NodeDetails) string
+}
+
+// PSSMisbehaviourReporter is implemented by transports that keep track of the reputation of
+// nodes, messages that fail verification, such as invalid commitments or proofs, are reported to it
+type PSSMisbehaviourReporter interface {
+	ReportInvalidMessage(sender NodeDetails, reason string)
+}
+
 type NodeNetwork struct {
 	Nodes   map[NodeDetailsID]NodeDetails
 	N       int
@@ -500,6 +535,12 @@ type PSSNode struct {
 	CleanUp      func(*PSSNode, SharingID) error
 
 	staggerDelay int
+	// SupportedVersions are the pss message versions this node processes
+	SupportedVersions []string
+	// Clock and Entropy are the real clock and crypto/rand unless a simulation replaces them
+	// before the node processes messages
+	Clock   sim.Clock
+	Entropy io.Reader
 }
 
 type PSSStoreSyncMap struct {
@@ -585,13 +626,30 @@ func (m *ShareStoreSyncMap) Complete(keygenID KeygenID) {
 	m.Set(keygenID, nil)
 }
 
+// RefreshKeyStorage is the output of a completed PSS. It holds copies of the share,
+// which the transport wipes once it has stored them.
 type RefreshKeyStorage struct {
 	KeyIndex       big.Int
-	Si             big.Int
-	Siprime        big.Int
+	Si             *secret.Int
+	Siprime        *secret.Int
 	CommitmentPoly []common.Point
 }
 
+func newRefreshKeyStorage(keyIndex big.Int, si, siprime *big.Int, commitmentPoly []common.Point) RefreshKeyStorage {
+	return RefreshKeyStorage{
+		KeyIndex:       keyIndex,
+		Si:             secret.NewInt(si),
+		Siprime:        secret.NewInt(siprime),
+		CommitmentPoly: commitmentPoly,
+	}
+}
+
+// Wipe wipes the share
+func (r RefreshKeyStorage) Wipe() {
+	r.Si.Wipe()
+	r.Siprime.Wipe()
+}
+
 func mapFromNodeList(nodeList []pcmn.Node) (res map[NodeDetailsID]NodeDetails) {
 	res = make(map[NodeDetailsID]NodeDetails)
 	for _, node := range nodeList {
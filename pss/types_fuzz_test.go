@@ -0,0 +1,57 @@
+//go:build go1.18
+// +build go1.18
+
+package pss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/pvss"
+)
+
+func FuzzFromNodeDetailsID(f *testing.F) {
+	for i := 1; i <= 3; i++ {
+		nodeDetails := NodeDetails{
+			Index:  i,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		}
+		f.Add(string(nodeDetails.ToNodeDetailsID()))
+	}
+	f.Add("1\x1c2")
+	f.Add("1\x1cz\x1c2")
+	f.Add("-1\x1c\x1c")
+	f.Fuzz(func(t *testing.T, id string) {
+		var nodeDetails NodeDetails
+		if nodeDetails.FromNodeDetailsID(NodeDetailsID(id)) != nil {
+			assert.Equal(t, NodeDetails{}, nodeDetails)
+			return
+		}
+		var reparsed NodeDetails
+		assert.NoError(t, reparsed.FromNodeDetailsID(nodeDetails.ToNodeDetailsID()))
+		assert.Equal(t, nodeDetails.ToNodeDetailsID(), reparsed.ToNodeDetailsID())
+	})
+}
+
+func FuzzFromPSSID(f *testing.F) {
+	keygenID := GenerateKeygenID(42)
+	f.Add(string((&PSSIDDetails{SharingID: keygenID.GetSharingID(1, 9, 5, 2, 2, 9, 5, 2), DealerIndex: 3}).ToPSSID()))
+	f.Add(string(NullPSSID))
+	f.Add("sharing\x1c")
+	f.Add("sharing\x1c1\x1c2")
+	f.Fuzz(func(t *testing.T, id string) {
+		var pssIDDetails PSSIDDetails
+		if pssIDDetails.FromPSSID(PSSID(id)) != nil {
+			assert.Equal(t, PSSIDDetails{}, pssIDDetails)
+			return
+		}
+		var reparsed PSSIDDetails
+		assert.NoError(t, reparsed.FromPSSID(pssIDDetails.ToPSSID()))
+		assert.Equal(t, pssIDDetails, reparsed)
+		// the index is parsed leniently and must not panic on any sharing ID
+		sharingKeygenID := pssIDDetails.SharingID.GetKeygenID()
+		sharingKeygenID.GetIndex()
+	})
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/torusresearch/bijson"
 
 	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/secp256k1"
+	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/pvss"
 )
 
 func TestTypeSerialization(t *testing.T) {
@@ -37,3 +40,99 @@ func TestTypeSerialization(t *testing.T) {
 	}
 	assert.True(t, reflect.DeepEqual(p1, p2))
 }
+
+// assertWiped checks that every word which backed a secret was zeroed
+func assertWiped(t *testing.T, words [][]big.Word) {
+	for _, w := range words {
+		for i := range w {
+			assert.Equal(t, big.Word(0), w[i])
+		}
+	}
+}
+
+func TestRefreshKeyStorageWipe(t *testing.T) {
+	si, siprime := pvss.RandomBigInt(), pvss.RandomBigInt()
+	expectedSi, expectedSiprime := new(big.Int).Set(si), new(big.Int).Set(siprime)
+	refreshKeyStorage := newRefreshKeyStorage(*big.NewInt(1), si, siprime, nil)
+	assert.Equal(t, 0, refreshKeyStorage.Si.Value().Cmp(expectedSi))
+	assert.Equal(t, 0, refreshKeyStorage.Siprime.Value().Cmp(expectedSiprime))
+
+	words := [][]big.Word{refreshKeyStorage.Si.Value().Bits(), refreshKeyStorage.Siprime.Value().Bits()}
+	refreshKeyStorage.Wipe()
+	assertWiped(t, words)
+	assert.Equal(t, 0, refreshKeyStorage.Si.Value().Sign())
+	// the share of the recover is still needed after it is output
+	assert.Equal(t, 0, si.Cmp(expectedSi))
+	assert.Equal(t, 0, siprime.Cmp(expectedSiprime))
+}
+
+func TestPSSWipePolynomials(t *testing.T) {
+	s := pvss.RandomBigInt()
+	pss := &PSS{
+		F:      pvss.GenerateRandomBivariatePolynomial(*s, 3),
+		Fprime: pvss.GenerateRandomBivariatePolynomial(*pvss.RandomBigInt(), 3),
+	}
+	var words [][]big.Word
+	for _, poly := range [][][]big.Int{pss.F, pss.Fprime} {
+		for i := range poly {
+			for j := range poly[i] {
+				words = append(words, poly[i][j].Bits())
+			}
+		}
+	}
+	pss.wipePolynomials()
+	assertWiped(t, words)
+	assert.Nil(t, pss.F)
+	assert.Nil(t, pss.Fprime)
+	assert.NotEqual(t, 0, s.Sign())
+}
+
+func TestDealingWipesPolynomials(t *testing.T) {
+	n, k, threshold := 3, 2, 1
+	var nodeList []pcmn.Node
+	for i := 0; i < n; i++ {
+		nodeList = append(nodeList, pcmn.Node{
+			Index:  i + 1,
+			PubKey: common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(pvss.RandomBigInt().Bytes())),
+		})
+	}
+	keygenID := GenerateKeygenID(0)
+	sharingID := keygenID.GetSharingID(1, n, k, threshold, 2, n, k, threshold)
+	si := pvss.RandomBigInt()
+	sharings := map[int]map[KeygenID]*Sharing{
+		nodeList[0].Index: {
+			keygenID: {
+				KeygenID: keygenID,
+				Nodes:    nodeList,
+				Epoch:    1,
+				I:        nodeList[0].Index,
+				Si:       *si,
+				Siprime:  *pvss.RandomBigInt(),
+			},
+		},
+	}
+	dealer := NewPSSNode(nodeList[0], 1, nodeList, threshold, k, 2, nodeList, threshold, k, nodeList[0].Index,
+		&LocalDataSource{Index: nodeList[0].Index, Sharings: &sharings}, &LocalOfflineTransport{}, true, true, 0)
+	dealer.CleanUp = noOpPSSCleanUp
+	pssID := (&PSSIDDetails{SharingID: sharingID, DealerIndex: nodeList[0].Index}).ToPSSID()
+	data, err := bijson.Marshal(PSSMsgShare{SharingID: sharingID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dealer.ProcessMessage(dealer.NodeDetails, CreatePSSMessage(PSSMessageRaw{PSSID: pssID, Method: "share", Data: data}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pss, found := dealer.PSSStore.Get(pssID)
+	if !found {
+		t.Fatal("expected the dealer to have started the pss")
+	}
+	pss.Lock()
+	defer pss.Unlock()
+	assert.NotEmpty(t, pss.C)
+	assert.Nil(t, pss.F)
+	assert.Nil(t, pss.Fprime)
+	// the polynomials held a copy of the share of the dealer
+	sharing := sharings[nodeList[0].Index][keygenID]
+	assert.Equal(t, 0, sharing.Si.Cmp(si))
+}
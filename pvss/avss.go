@@ -3,7 +3,9 @@ package pvss
 // Asynchronous Verifiable Secret Sharing and Proactive Cryptosystems
 
 import (
+	"crypto/rand"
 	"errors"
+	"io"
 	"math/big"
 
 	"github.com/torusresearch/torus-common/common"
@@ -20,14 +22,20 @@ import (
 // f10.x,   f11.x.y,  f12.x.y^2
 // f20.x^2, f21.x^2.y f22.x^2.y^2
 func GenerateRandomBivariatePolynomial(secret big.Int, threshold int) [][]big.Int {
+	return GenerateRandomBivariatePolynomialFrom(rand.Reader, secret, threshold)
+}
+
+// GenerateRandomBivariatePolynomialFrom - GenerateRandomBivariatePolynomial with coeffs drawn from entropy
+func GenerateRandomBivariatePolynomialFrom(entropy io.Reader, secret big.Int, threshold int) [][]big.Int {
 	bivarPolyCoeffs := make([][]big.Int, threshold)
 	for j := range bivarPolyCoeffs {
 		bivarPolyCoeffs[j] = make([]big.Int, threshold)
 		for l := range bivarPolyCoeffs[j] {
 			if j == 0 && l == 0 {
-				bivarPolyCoeffs[j][l] = secret // f_00
+				// f_00, copied so that wiping the polynomial does not wipe secret
+				bivarPolyCoeffs[j][l].Set(&secret)
 			} else {
-				bivarPolyCoeffs[j][l] = *RandomBigInt() // f_jl . x^j . y^l
+				bivarPolyCoeffs[j][l] = *RandomBigIntFrom(entropy) // f_jl . x^j . y^l
 			}
 		}
 	}
@@ -109,9 +117,14 @@ func AVSSVerifyPoly(
 	if a.Threshold != aprime.Threshold || a.Threshold != b.Threshold || a.Threshold != bprime.Threshold {
 		return false
 	}
-	if a.Threshold != len(C) || a.Threshold != len(C[0]) {
+	if a.Threshold != len(C) || !isSquare(C) {
 		return false
 	}
+	for _, poly := range []pcmn.PrimaryPolynomial{a, aprime, b, bprime} {
+		if len(poly.Coeff) != a.Threshold {
+			return false
+		}
+	}
 
 	// check that g^(a_l).h^(aprime_l) = product (C_jl)^(i^j)
 	for l := 0; l < len(a.Coeff); l++ {
@@ -158,6 +171,9 @@ func AVSSVerifyPoint(
 	beta big.Int,
 	betaprime big.Int,
 ) bool {
+	if !isSquare(C) {
+		return false
+	}
 	galpha := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(alpha.Bytes()))
 	halphaprime := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&secp256k1.H.X, &secp256k1.H.Y, alphaprime.Bytes()))
 	galphahalphaprime := common.BigIntToPoint(secp256k1.Curve.Add(&galpha.X, &galpha.Y, &halphaprime.X, &halphaprime.Y))
@@ -181,6 +197,9 @@ func AVSSVerifyPoint(
 
 // AVSSVerifyShare - Verify-share from Cachin et al. 2002
 func AVSSVerifyShare(C [][]common.Point, m big.Int, sigma big.Int, sigmaprime big.Int) bool {
+	if !isSquare(C) {
+		return false
+	}
 	gsigma := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(sigma.Bytes()))
 	hsigmaprime := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&secp256k1.H.X, &secp256k1.H.Y, sigmaprime.Bytes()))
 	gsigmahsigmaprime := common.BigIntToPoint(secp256k1.Curve.Add(&gsigma.X, &gsigma.Y, &hsigmaprime.X, &hsigmaprime.Y))
@@ -199,6 +218,20 @@ func AVSSVerifyShare(C [][]common.Point, m big.Int, sigma big.Int, sigmaprime bi
 	return true
 }
 
+// isSquare - checks that the commitment matrix C is square and not empty, so that
+// commitments received from other nodes can be indexed
+func isSquare(C [][]common.Point) bool {
+	if len(C) == 0 {
+		return false
+	}
+	for _, row := range C {
+		if len(row) != len(C) {
+			return false
+		}
+	}
+	return true
+}
+
 // To add commitments together
 func AVSSAddCommitment(C1 [][]common.Point, C2 [][]common.Point) ([][]common.Point, error) {
 	if len(C1) != len(C2) {
@@ -209,7 +242,7 @@ func AVSSAddCommitment(C1 [][]common.Point, C2 [][]common.Point) ([][]common.Poi
 		if len(C1[i]) != len(C2[i]) {
 			return nil, errors.New("Commitment not same length")
 		}
-		sumC[i] = make([]common.Point, len(C1))
+		sumC[i] = make([]common.Point, len(C1[i]))
 		for j := range C1[i] {
 			sumC[i][j] = common.BigIntToPoint(secp256k1.Curve.Add(&C1[i][j].X, &C1[i][j].Y, &C2[i][j].X, &C2[i][j].Y))
 		}
@@ -219,6 +252,9 @@ func AVSSAddCommitment(C1 [][]common.Point, C2 [][]common.Point) ([][]common.Poi
 
 // AVSSVerifyShareCommitment to test gsihr against C
 func AVSSVerifyShareCommitment(C [][]common.Point, m big.Int, gsigmahsigmaprime common.Point) bool {
+	if !isSquare(C) {
+		return false
+	}
 	pt := common.Point{X: *big.NewInt(int64(0)), Y: *big.NewInt(int64(0))}
 	for j := range C {
 		Cj0 := C[j][0]
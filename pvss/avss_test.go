@@ -107,6 +107,29 @@ func TestAVSSVerifyShare(t *testing.T) {
 	assert.True(t, AVSSVerifyShare(C, *big.NewInt(int64(3)), *sigma, *sigmaprime))
 }
 
+func TestAVSSMalformedCommitment(t *testing.T) {
+	threshold := 3
+	f := GenerateRandomBivariatePolynomial(*RandomBigInt(), threshold)
+	fprime := GenerateRandomBivariatePolynomial(*RandomBigInt(), threshold)
+	C := GetCommitmentMatrix(f, fprime)
+	index := *big.NewInt(int64(5))
+	a := EvaluateBivarPolyAtX(f, index)
+	aprime := EvaluateBivarPolyAtX(fprime, index)
+	b := EvaluateBivarPolyAtY(f, index)
+	bprime := EvaluateBivarPolyAtY(fprime, index)
+	zero := *big.NewInt(int64(0))
+
+	// commitments and polys from other nodes may have any size
+	ragged := [][]common.Point{C[0], C[1][:1], C[2]}
+	assert.False(t, AVSSVerifyPoly(ragged, index, a, aprime, b, bprime))
+	short := pcmn.PrimaryPolynomial{Coeff: a.Coeff[:1], Threshold: threshold}
+	assert.False(t, AVSSVerifyPoly(C, index, short, aprime, b, bprime))
+	assert.False(t, AVSSVerifyPoint(nil, index, index, zero, zero, zero, zero))
+	assert.False(t, AVSSVerifyPoint(ragged, index, index, zero, zero, zero, zero))
+	assert.False(t, AVSSVerifyShare([][]common.Point{{}}, index, zero, zero))
+	assert.False(t, AVSSVerifyShareCommitment([][]common.Point{{}}, index, common.Point{}))
+}
+
 func TestAVSS(t *testing.T) {
 	// dealer chooses two bivar polys, f and fprime
 	total := 9
@@ -3,6 +3,8 @@
 package pvss
 
 import (
+	"crypto/rand"
+	"io"
 	"math/big"
 
 	logging "github.com/sirupsen/logrus"
@@ -23,9 +25,14 @@ func pad(b []byte) []byte {
 // Generates NIZK Proof with the aims of increasing asynchronicity within AVSS
 // Returns proof in the form of c, u1, u2
 func GenerateNIZKPK(s big.Int, r big.Int) (big.Int, big.Int, big.Int) {
+	return GenerateNIZKPKFrom(rand.Reader, s, r)
+}
+
+// GenerateNIZKPKFrom - GenerateNIZKPK with its randomness drawn from entropy
+func GenerateNIZKPKFrom(entropy io.Reader, s big.Int, r big.Int) (big.Int, big.Int, big.Int) {
 	// create randomness
-	v1 := *RandomBigInt()
-	v2 := *RandomBigInt()
+	v1 := *RandomBigIntFrom(entropy)
+	v2 := *RandomBigIntFrom(entropy)
 	t1 := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(v1.Bytes()))
 	t2 := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&secp256k1.H.X, &secp256k1.H.Y, v2.Bytes()))
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/sim"
 )
 
 func TestNIZKPK(t *testing.T) {
@@ -25,3 +26,18 @@ func TestNIZKPK(t *testing.T) {
 	assert.False(t, falseVerify, "false verify should be false")
 
 }
+
+func TestNIZKPKFromEntropy(t *testing.T) {
+	s := *RandomBigInt()
+	r := *RandomBigInt()
+	c, u1, u2 := GenerateNIZKPKFrom(sim.NewEntropy(1), s, r)
+	otherC, otherU1, otherU2 := GenerateNIZKPKFrom(sim.NewEntropy(1), s, r)
+	assert.Equal(t, c.Text(16), otherC.Text(16), "proofs from the same seed should be equal")
+	assert.Equal(t, u1.Text(16), otherU1.Text(16), "proofs from the same seed should be equal")
+	assert.Equal(t, u2.Text(16), otherU2.Text(16), "proofs from the same seed should be equal")
+
+	gs := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(s.Bytes()))
+	hr := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&secp256k1.H.X, &secp256k1.H.Y, r.Bytes()))
+	gshr := common.BigIntToPoint(secp256k1.Curve.Add(&gs.X, &gs.Y, &hr.X, &hr.Y))
+	assert.True(t, VerifyNIZKPK(c, u1, u2, gs, gshr), "verification should be true")
+}
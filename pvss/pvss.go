@@ -7,6 +7,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"errors"
+	"io"
 	"log"
 	"math/big"
 	"sort"
@@ -16,14 +17,25 @@ import (
 	"github.com/torusresearch/torus-common/common"
 	"github.com/torusresearch/torus-common/secp256k1"
 	pcmn "github.com/torusresearch/torus-node/common"
+	"github.com/torusresearch/torus-node/secret"
 )
 
 func RandomPoly(secret big.Int, threshold int) *pcmn.PrimaryPolynomial {
 	return generateRandomZeroPolynomial(secret, threshold)
 }
 
+// RandomPolyFrom returns a polynomial of secret whose other coefficients are drawn from entropy
+func RandomPolyFrom(entropy io.Reader, secret big.Int, threshold int) *pcmn.PrimaryPolynomial {
+	return generateRandomZeroPolynomialFrom(entropy, secret, threshold)
+}
+
 func RandomBigInt() *big.Int {
-	randomInt, _ := rand.Int(rand.Reader, secp256k1.GeneratorOrder)
+	return RandomBigIntFrom(rand.Reader)
+}
+
+// RandomBigIntFrom returns a random scalar drawn from entropy
+func RandomBigIntFrom(entropy io.Reader) *big.Int {
+	randomInt, _ := rand.Int(entropy, secp256k1.GeneratorOrder)
 	return randomInt
 }
 
@@ -104,11 +116,16 @@ func AddPolynomials(poly1 pcmn.PrimaryPolynomial, poly2 pcmn.PrimaryPolynomial)
 }
 
 func generateRandomZeroPolynomial(secret big.Int, threshold int) *pcmn.PrimaryPolynomial {
+	return generateRandomZeroPolynomialFrom(rand.Reader, secret, threshold)
+}
+
+func generateRandomZeroPolynomialFrom(entropy io.Reader, secret big.Int, threshold int) *pcmn.PrimaryPolynomial {
 	// Create secret sharing polynomial
 	coeff := make([]big.Int, threshold)
-	coeff[0] = secret                //assign secret as coeff of x^0
+	// assign secret as coeff of x^0, copied so that wiping the polynomial does not wipe secret
+	coeff[0].Set(&secret)
 	for i := 1; i < threshold; i++ { //randomly choose coeffs
-		coeff[i] = *RandomBigInt()
+		coeff[i] = *RandomBigIntFrom(entropy)
 	}
 	return &pcmn.PrimaryPolynomial{Coeff: coeff, Threshold: threshold}
 }
@@ -178,6 +195,9 @@ func ECDSASign(s string, privKey *big.Int) []byte {
 // }
 
 func ECDSAVerify(str string, pubKey *common.Point, signature []byte) bool {
+	if len(signature) < 64 {
+		return false
+	}
 	r := new(big.Int)
 	s := new(big.Int)
 	r.SetBytes(signature[:32])
@@ -203,17 +223,20 @@ func ECDSAVerifyBytes(b []byte, pubKey *common.Point, signature []byte) bool {
 
 func UnSignCrypt(signcryption pcmn.Signcryption, privKey big.Int, senderPubKey common.Point) (*[]byte, error) {
 	xR := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&signcryption.R.X, &signcryption.R.Y, privKey.Bytes()))
-	M, err := AESdecrypt(xR.X.Bytes(), signcryption.Ciphertext)
+	sharedKey := xR.X.Bytes()
+	M, err := AESdecrypt(sharedKey, signcryption.Ciphertext)
+	secret.WipeBytes(sharedKey)
 	if err != nil {
 		return nil, err
 	}
 
-	//Concat hashing bytes
-	cb := []byte(*M)
-	cb = append(cb[:], signcryption.R.X.Bytes()...)
+	//Concat hashing bytes, into a new buffer which is wiped after hashing
+	cb := make([]byte, 0, len(*M)+32)
+	cb = append(append(cb, *M...), signcryption.R.X.Bytes()...)
 
 	//hash h = secp256k1.H(M|r1)
 	hashed := secp256k1.Keccak256(cb)
+	secret.WipeBytes(cb)
 	h := new(big.Int).SetBytes(hashed)
 	h.Mod(h, secp256k1.GeneratorOrder)
 
@@ -227,6 +250,7 @@ func UnSignCrypt(signcryption pcmn.Signcryption, privKey big.Int, senderPubKey c
 			"senderPubKey":     senderPubKey,
 			"testsenderPubKey": testsenderPubKey,
 		}).Debug()
+		secret.WipeBytes(*M)
 		return nil, errors.New("sending node PK does not register with signcryption unsigncrypt")
 	}
 
@@ -251,6 +275,7 @@ func signcryptShare(nodePubKey common.Point, share big.Int, privKey big.Int) (*p
 
 	// hash h = secp256k1.H(M|r1)
 	hashed := secp256k1.Keccak256(cb)
+	secret.WipeBytes(cb)
 	h := new(big.Int).SetBytes(hashed)
 	h.Mod(h, secp256k1.GeneratorOrder)
 
@@ -260,6 +285,7 @@ func signcryptShare(nodePubKey common.Point, share big.Int, privKey big.Int) (*p
 	temp.Mod(temp, secp256k1.GeneratorOrder)
 	szecret.Sub(&privKey, temp)
 	szecret.Mod(szecret, secp256k1.GeneratorOrder)
+	secret.Wipe(r, temp)
 
 	return &pcmn.Signcryption{Ciphertext: *ciphertext, R: rG, Signature: *szecret}, nil
 }
@@ -311,17 +337,20 @@ func CreateAndPrepareShares(nodes []pcmn.Node, secret big.Int, threshold int, pr
 
 func UnsigncryptShare(signcryption pcmn.Signcryption, privKey big.Int, sendingNodePubKey common.Point) (*[]byte, error) {
 	xR := common.BigIntToPoint(secp256k1.Curve.ScalarMult(&signcryption.R.X, &signcryption.R.Y, privKey.Bytes()))
-	M, err := AESdecrypt(xR.X.Bytes(), signcryption.Ciphertext)
+	sharedKey := xR.X.Bytes()
+	M, err := AESdecrypt(sharedKey, signcryption.Ciphertext)
+	secret.WipeBytes(sharedKey)
 	if err != nil {
 		return nil, err
 	}
 
-	//Concat hashing bytes
-	cb := []byte(*M)
-	cb = append(cb[:], signcryption.R.X.Bytes()...)
+	//Concat hashing bytes, into a new buffer which is wiped after hashing
+	cb := make([]byte, 0, len(*M)+32)
+	cb = append(append(cb, *M...), signcryption.R.X.Bytes()...)
 
 	//hash h = secp256k1.H(M|r1)
 	hashed := secp256k1.Keccak256(cb)
+	secret.WipeBytes(cb)
 	h := new(big.Int).SetBytes(hashed)
 	h.Mod(h, secp256k1.GeneratorOrder)
 
@@ -335,6 +364,7 @@ func UnsigncryptShare(signcryption pcmn.Signcryption, privKey big.Int, sendingNo
 			"senderNodePubKey": sendingNodePubKey,
 			"testSenderPubKey": testSendingNodePubKey,
 		}).Debug()
+		secret.WipeBytes(*M)
 		return nil, errors.New("sending node PK does not register with signcryption")
 	}
 
@@ -275,6 +275,7 @@ func TestECDSA(test *testing.T) {
 	sig := ECDSASign(testStr, privKey)
 	pubKey := common.BigIntToPoint(secp256k1.Curve.ScalarBaseMult(privKey.Bytes()))
 	assert.True(test, ECDSAVerify(testStr, &pubKey, sig))
+	assert.False(test, ECDSAVerify(testStr, &pubKey, sig[:10]), "short signatures should not verify")
 }
 
 func TestPedersons(test *testing.T) {
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/ethwatch"
+	nodelist "github.com/torusresearch/torus-node/solidity/goContracts"
+	"github.com/torusresearch/torus-node/txmanager"
+)
+
+// contractRegistry is the NodeList contract
+type contractRegistry struct {
+	ethwatch.Reader
+	address  common.Address
+	abi      abi.ABI
+	contract *nodelist.NodeList
+	txs      *txmanager.Manager
+}
+
+// NewContractRegistry returns the NodeList contract at address as a registry, registering
+// with transactions sent by txs
+func NewContractRegistry(address common.Address, backend bind.ContractBackend, txs *txmanager.Manager) (NodeRegistry, error) {
+	parsed, err := abi.JSON(strings.NewReader(nodelist.NodeListABI))
+	if err != nil {
+		return nil, err
+	}
+	contract, err := nodelist.NewNodeList(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &contractRegistry{
+		Reader:   ethwatch.NewContractReader(contract),
+		address:  address,
+		abi:      parsed,
+		contract: contract,
+		txs:      txs,
+	}, nil
+}
+
+// RegistrationTxID is the ID of the transaction of an attempt to register the node in epoch.
+// Attempts after the first are sent once the previous one reverted.
+func RegistrationTxID(epoch int, attempt int) string {
+	id := "register-epoch-" + strconv.Itoa(epoch)
+	if attempt > 1 {
+		id += "-" + strconv.Itoa(attempt)
+	}
+	return id
+}
+
+// RegistrationTx returns the latest attempt to register the node in epoch and the state of its
+// transaction, and false if no attempt was sent yet
+func RegistrationTx(txs *txmanager.Manager, epoch int) (int, txmanager.State, bool, error) {
+	attempt := 1
+	state, sent, err := txs.State(RegistrationTxID(epoch, attempt))
+	for err == nil && sent && state.Status == txmanager.StatusFailed {
+		next, nextSent, nextErr := txs.State(RegistrationTxID(epoch, attempt+1))
+		if nextErr != nil {
+			return attempt, state, sent, nextErr
+		}
+		if !nextSent {
+			break
+		}
+		attempt, state = attempt+1, next
+	}
+	return attempt, state, sent, err
+}
+
+func (r *contractRegistry) NodeDetails(ctx context.Context, address common.Address) (NodeDetails, error) {
+	details, err := r.contract.NodeDetails(&bind.CallOpts{Context: ctx}, address)
+	if err != nil {
+		return NodeDetails{}, err
+	}
+	if details.PubKx == nil || details.PubKx.Sign() == 0 {
+		return NodeDetails{}, ErrNodeNotFound{Address: address}
+	}
+	return NodeDetails{
+		DeclaredIP:         details.DeclaredIp,
+		Position:           int(details.Position.Int64()),
+		PubKx:              details.PubKx,
+		PubKy:              details.PubKy,
+		TMP2PListenAddress: details.TmP2PListenAddress,
+		P2PListenAddress:   details.P2pListenAddress,
+	}, nil
+}
+
+func (r *contractRegistry) NodeRegistered(ctx context.Context, epoch int, address common.Address) (bool, error) {
+	return r.contract.NodeRegistered(&bind.CallOpts{Context: ctx, From: r.txs.From()}, big.NewInt(int64(epoch)), address)
+}
+
+// Register lists the node with its declared IP only, and waits for the transaction to be
+// confirmed. Connection addresses are not published on chain, other nodes request them from
+// the declared IP. It returns txmanager.ErrReverted if the transaction reverted, registering
+// again then sends a new transaction.
+func (r *contractRegistry) Register(ctx context.Context, epoch int, details NodeDetails) error {
+	data, err := r.abi.Pack("listNode", big.NewInt(int64(epoch)), details.DeclaredIP, details.PubKx, details.PubKy, "", "")
+	if err != nil {
+		return err
+	}
+	attempt, state, sent, err := RegistrationTx(r.txs, epoch)
+	if err != nil {
+		return err
+	}
+	if sent && state.Status == txmanager.StatusFailed {
+		// the transaction of an attempt that reverted is never sent again, the next attempt is
+		attempt++
+	}
+	id := RegistrationTxID(epoch, attempt)
+	if _, err := r.txs.Send(ctx, txmanager.Request{ID: id, To: r.address, Data: data}); err != nil {
+		// a transaction that was persisted is sent again when it is replaced
+		if _, sent, _ := r.txs.State(id); !sent {
+			return err
+		}
+		logging.WithField("epoch", epoch).WithError(err).Warn("could not send registration transaction")
+	}
+	_, err = r.txs.Wait(ctx, id)
+	return err
+}
@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	nodelist "github.com/torusresearch/torus-node/solidity/goContracts"
+	"github.com/torusresearch/torus-node/txmanager"
+)
+
+// miningChain mines every transaction in its own block as soon as it is sent, with the
+// statuses queued in revert
+type miningChain struct {
+	lock     sync.Mutex
+	nonce    uint64
+	head     uint64
+	revert   []bool
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (c *miningChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.nonce, nil
+}
+
+func (c *miningChain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(100), nil
+}
+
+func (c *miningChain) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (c *miningChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	status := types.ReceiptStatusSuccessful
+	if len(c.revert) > 0 {
+		if c.revert[0] {
+			status = types.ReceiptStatusFailed
+		}
+		c.revert = c.revert[1:]
+	}
+	c.head++
+	c.nonce = tx.Nonce() + 1
+	c.receipts[tx.Hash()] = &types.Receipt{Status: status, TxHash: tx.Hash()}
+	return nil
+}
+
+func (c *miningChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	receipt, ok := c.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *miningChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+}
+
+func TestRegisterAfterRevert(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "txmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := txmanager.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(nodelist.NodeListABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &miningChain{revert: []bool{true}, receipts: make(map[common.Hash]*types.Receipt)}
+	node := newTestSigner(t)
+	txs := txmanager.NewManager(chain, node, store, txmanager.Config{PollInterval: time.Millisecond})
+	r := &contractRegistry{address: common.HexToAddress("0x1"), abi: parsed, txs: txs}
+
+	if err := r.Register(ctx, 1, details(node, "")); err != txmanager.ErrReverted {
+		t.Fatalf("expected the registration to revert, got %v", err)
+	}
+	attempt, state, sent, err := RegistrationTx(txs, 1)
+	if err != nil || !sent || attempt != 1 || state.Status != txmanager.StatusFailed {
+		t.Fatalf("expected the first attempt to have failed, got %v %+v %v %v", attempt, state, sent, err)
+	}
+
+	// the node registers again once it restarts or retries
+	if err := r.Register(ctx, 1, details(node, "")); err != nil {
+		t.Fatalf("expected the node to register again after a revert, got %v", err)
+	}
+	attempt, state, sent, err = RegistrationTx(txs, 1)
+	if err != nil || !sent || attempt != 2 || state.ID != "register-epoch-1-2" || state.Status != txmanager.StatusConfirmed {
+		t.Fatalf("expected the second attempt to be confirmed, got %v %+v %v %v", attempt, state, sent, err)
+	}
+	if state.Nonce != 1 {
+		t.Fatalf("expected the second attempt to be a new transaction, got nonce %v", state.Nonce)
+	}
+
+	// registering after the node was registered follows the confirmed attempt
+	if err := r.Register(ctx, 1, details(node, "")); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.receipts) != 2 {
+		t.Fatalf("expected no transaction to be sent once registered, got %v", len(chain.receipts))
+	}
+	if attempt, _, sent, _ := RegistrationTx(txs, 2); sent || attempt != 1 {
+		t.Fatal("expected no registration in an epoch the node never registered in")
+	}
+}
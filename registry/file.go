@@ -0,0 +1,377 @@
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/ethwatch"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+const (
+	// EpochsFile is the file of a file registry that lists its epochs, signed by its owner
+	EpochsFile = "epochs.json"
+	// nodesDir holds a directory per epoch with a file per node registered in it, signed by the node
+	nodesDir = "nodes"
+)
+
+// Epochs is the content of the epochs file. Version is increased by WriteEpochs on every write,
+// nodes refuse epochs files older than one they read, so that an old file signed by the owner
+// cannot be put back in place of the current one.
+type Epochs struct {
+	Version   uint64      `json:"version"`
+	Epochs    []Epoch     `json:"epochs"`
+	PSSStatus []PSSStatus `json:"pssStatus,omitempty"`
+}
+
+// Epoch is an epoch of a file registry. Nodes is its whitelist in the order of the node
+// indexes, its N is the number of nodes.
+type Epoch struct {
+	ID        int              `json:"id"`
+	K         int              `json:"k"`
+	T         int              `json:"t"`
+	PrevEpoch int              `json:"prevEpoch"`
+	NextEpoch int              `json:"nextEpoch"`
+	Nodes     []common.Address `json:"nodes"`
+}
+
+// PSSStatus is the status of the PSS from OldEpoch to NewEpoch
+type PSSStatus struct {
+	OldEpoch int `json:"oldEpoch"`
+	NewEpoch int `json:"newEpoch"`
+	Status   int `json:"status"`
+}
+
+// registration is what a node writes to register in an epoch. The epoch and the owner of the
+// registry are signed with it, so that it cannot be copied to another epoch or registry.
+type registration struct {
+	Epoch              int            `json:"epoch"`
+	Registry           common.Address `json:"registry"`
+	DeclaredIP         string         `json:"declaredIP"`
+	PubKx              *hexutil.Big   `json:"pubKx"`
+	PubKy              *hexutil.Big   `json:"pubKy"`
+	TMP2PListenAddress string         `json:"tmp2pListenAddress"`
+	P2PListenAddress   string         `json:"p2pListenAddress"`
+}
+
+// signedFile is a file signed by the key of Signer, over the keccak256 hash of Data
+type signedFile struct {
+	Data      json.RawMessage `json:"data"`
+	Signature hexutil.Bytes   `json:"signature"`
+}
+
+// fileRegistry is a directory with the epochs file and the registrations of nodes
+type fileRegistry struct {
+	dir    string
+	owner  common.Address
+	signer signer.Signer
+
+	lock sync.Mutex
+	head uint64
+	// version is the highest version of the epochs file read so far
+	version uint64
+}
+
+// FileRegistry is a NodeRegistry kept in files. It has no blocks, so it is also the Chain its
+// changes are followed on, every read of its head being a new block.
+type FileRegistry interface {
+	NodeRegistry
+	ethwatch.Chain
+}
+
+// NewFileRegistry returns the registry in dir, whose epochs file must be signed by owner. This
+// node registers by writing its registration signed by s into dir.
+func NewFileRegistry(dir string, owner common.Address, s signer.Signer) (FileRegistry, error) {
+	if owner == (common.Address{}) {
+		return nil, errors.New("the owner of the file registry is not configured")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, nodesDir), 0755); err != nil {
+		return nil, err
+	}
+	return &fileRegistry{dir: dir, owner: owner, signer: s}, nil
+}
+
+// WriteEpochs signs epochs with s, the owner of the registry, and writes them to the epochs file of dir
+// with the version following that of the epochs file s signed before, if it can be read
+func WriteEpochs(dir string, epochs Epochs, s signer.Signer) error {
+	path := filepath.Join(dir, EpochsFile)
+	var current Epochs
+	signedBy, err := readSigned(path, &current)
+	epochs.Version = 1
+	if err == nil && signedBy == s.Address() {
+		epochs.Version = current.Version + 1
+	}
+	return writeSigned(path, epochs, s)
+}
+
+func writeSigned(path string, v interface{}, s signer.Signer) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	signature, err := signer.SignData(s, data)
+	if err != nil {
+		return err
+	}
+	// not indented, as indenting would also change the signed data
+	file, err := json.Marshal(signedFile{Data: data, Signature: signature})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// written to a temporary file first, so that nodes never read half a file
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, file, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSigned reads the signed file at path into v and returns the address that signed it
+func readSigned(path string, v interface{}) (common.Address, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signed signedFile
+	if err := json.Unmarshal(file, &signed); err != nil {
+		return common.Address{}, fmt.Errorf("could not parse %v: %v", path, err)
+	}
+	pubKey, err := ethCrypto.SigToPub(ethCrypto.Keccak256(signed.Data), signed.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature of %v: %v", path, err)
+	}
+	if err := json.Unmarshal(signed.Data, v); err != nil {
+		return common.Address{}, fmt.Errorf("could not parse %v: %v", path, err)
+	}
+	return ethCrypto.PubkeyToAddress(*pubKey), nil
+}
+
+func (r *fileRegistry) epochs() (Epochs, error) {
+	var epochs Epochs
+	signedBy, err := readSigned(filepath.Join(r.dir, EpochsFile), &epochs)
+	if os.IsNotExist(err) {
+		return Epochs{}, nil
+	}
+	if err != nil {
+		return Epochs{}, err
+	}
+	if signedBy != r.owner {
+		return Epochs{}, fmt.Errorf("epochs file is signed by %v instead of the owner %v", signedBy.Hex(), r.owner.Hex())
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if epochs.Version < r.version {
+		return Epochs{}, fmt.Errorf("epochs file has version %v, older than version %v read before", epochs.Version, r.version)
+	}
+	r.version = epochs.Version
+	return epochs, nil
+}
+
+func (r *fileRegistry) epoch(epoch int) (Epoch, bool, error) {
+	epochs, err := r.epochs()
+	if err != nil {
+		return Epoch{}, false, err
+	}
+	for _, e := range epochs.Epochs {
+		if e.ID == epoch {
+			return e, true, nil
+		}
+	}
+	return Epoch{}, false, nil
+}
+
+func (r *fileRegistry) registrationPath(epoch int, address common.Address) string {
+	return filepath.Join(r.dir, nodesDir, strconv.Itoa(epoch), address.Hex()+".json")
+}
+
+// registration reads the registration of address in epoch. Registrations that were not
+// signed by the key they register, or that were signed for another epoch or registry, are ignored.
+func (r *fileRegistry) registration(epoch int, address common.Address) (registration, bool, error) {
+	var reg registration
+	signedBy, err := readSigned(r.registrationPath(epoch, address), &reg)
+	if os.IsNotExist(err) {
+		return registration{}, false, nil
+	}
+	if err != nil {
+		return registration{}, false, err
+	}
+	if reg.PubKx == nil || reg.PubKy == nil {
+		return registration{}, false, fmt.Errorf("registration of %v in epoch %v has no public key", address.Hex(), epoch)
+	}
+	pubKeyAddress := ethCrypto.PubkeyToAddress(ecdsa.PublicKey{Curve: ethCrypto.S256(), X: reg.PubKx.ToInt(), Y: reg.PubKy.ToInt()})
+	if signedBy != address || pubKeyAddress != address {
+		logging.WithFields(logging.Fields{
+			"epoch":    epoch,
+			"address":  address.Hex(),
+			"signedBy": signedBy.Hex(),
+		}).Warn("ignoring registration that is not signed by the node it registers")
+		return registration{}, false, nil
+	}
+	if reg.Epoch != epoch || reg.Registry != r.owner {
+		logging.WithFields(logging.Fields{
+			"epoch":             epoch,
+			"address":           address.Hex(),
+			"signedForEpoch":    reg.Epoch,
+			"signedForRegistry": reg.Registry.Hex(),
+		}).Warn("ignoring registration that was signed for another epoch or registry")
+		return registration{}, false, nil
+	}
+	return reg, true, nil
+}
+
+// registered returns the nodes of epoch that registered, in the order of their indexes. Invalid
+// registrations are skipped, so that one bad file does not hide the other nodes.
+func (r *fileRegistry) registered(e Epoch) []common.Address {
+	var nodes []common.Address
+	for _, address := range e.Nodes {
+		_, ok, err := r.registration(e.ID, address)
+		if err != nil {
+			logging.WithField("epoch", e.ID).WithError(err).Warn("skipping invalid registration")
+			continue
+		}
+		if ok {
+			nodes = append(nodes, address)
+		}
+	}
+	return nodes
+}
+
+func (r *fileRegistry) EpochInfo(ctx context.Context, epoch int) (ethwatch.EpochInfo, error) {
+	e, ok, err := r.epoch(epoch)
+	if err != nil || !ok {
+		return ethwatch.EpochInfo{}, err
+	}
+	return ethwatch.EpochInfo{
+		ID:        e.ID,
+		N:         len(e.Nodes),
+		K:         e.K,
+		T:         e.T,
+		PrevEpoch: e.PrevEpoch,
+		NextEpoch: e.NextEpoch,
+		Nodes:     r.registered(e),
+	}, nil
+}
+
+func (r *fileRegistry) PSSStatus(ctx context.Context, oldEpoch, newEpoch int) (int, error) {
+	epochs, err := r.epochs()
+	if err != nil {
+		return 0, err
+	}
+	for _, status := range epochs.PSSStatus {
+		if status.OldEpoch == oldEpoch && status.NewEpoch == newEpoch {
+			return status.Status, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *fileRegistry) Whitelisted(ctx context.Context, epoch int, address common.Address) (bool, error) {
+	e, _, err := r.epoch(epoch)
+	if err != nil {
+		return false, err
+	}
+	return position(e, address) != 0, nil
+}
+
+// position returns the index of address in e, or 0 if it is not whitelisted
+func position(e Epoch, address common.Address) int {
+	for i, node := range e.Nodes {
+		if node == address {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// NodesListed returns no listings, as files keep no history. Registrations show up in the
+// nodes of their epoch instead.
+func (r *fileRegistry) NodesListed(ctx context.Context, from, to uint64) ([]ethwatch.NodeListing, error) {
+	return nil, nil
+}
+
+func (r *fileRegistry) NodeDetails(ctx context.Context, address common.Address) (NodeDetails, error) {
+	epochs, err := r.epochs()
+	if err != nil {
+		return NodeDetails{}, err
+	}
+	// the registration in the latest epoch wins, as with the contract
+	var details NodeDetails
+	var latest int
+	for _, e := range epochs.Epochs {
+		pos := position(e, address)
+		if pos == 0 || e.ID < latest {
+			continue
+		}
+		reg, ok, err := r.registration(e.ID, address)
+		if err != nil {
+			return NodeDetails{}, err
+		}
+		if !ok {
+			continue
+		}
+		latest = e.ID
+		details = NodeDetails{
+			DeclaredIP:         reg.DeclaredIP,
+			Position:           pos,
+			PubKx:              reg.PubKx.ToInt(),
+			PubKy:              reg.PubKy.ToInt(),
+			TMP2PListenAddress: reg.TMP2PListenAddress,
+			P2PListenAddress:   reg.P2PListenAddress,
+		}
+	}
+	if latest == 0 {
+		return NodeDetails{}, ErrNodeNotFound{Address: address}
+	}
+	return details, nil
+}
+
+func (r *fileRegistry) NodeRegistered(ctx context.Context, epoch int, address common.Address) (bool, error) {
+	_, ok, err := r.registration(epoch, address)
+	return ok, err
+}
+
+// Register writes the registration of this node, which must be whitelisted in epoch
+func (r *fileRegistry) Register(ctx context.Context, epoch int, details NodeDetails) error {
+	e, _, err := r.epoch(epoch)
+	if err != nil {
+		return err
+	}
+	address := r.signer.Address()
+	if position(e, address) == 0 {
+		return fmt.Errorf("node %v is not whitelisted in epoch %v", address.Hex(), epoch)
+	}
+	return writeSigned(r.registrationPath(epoch, address), registration{
+		Epoch:              epoch,
+		Registry:           r.owner,
+		DeclaredIP:         details.DeclaredIP,
+		PubKx:              (*hexutil.Big)(details.PubKx),
+		PubKy:              (*hexutil.Big)(details.PubKy),
+		TMP2PListenAddress: details.TMP2PListenAddress,
+		P2PListenAddress:   details.P2PListenAddress,
+	}, r.signer)
+}
+
+// HeaderByNumber returns a new head on every call
+func (r *fileRegistry) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.head++
+	return &types.Header{Number: new(big.Int).SetUint64(r.head)}, nil
+}
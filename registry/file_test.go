@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+func newTestSigner(t *testing.T) signer.Signer {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.NewMemorySigner(key)
+}
+
+func details(s signer.Signer, p2p string) NodeDetails {
+	return NodeDetails{
+		DeclaredIP:       "127.0.0.1:443",
+		PubKx:            s.PublicKey().X,
+		PubKy:            s.PublicKey().Y,
+		P2PListenAddress: p2p,
+	}
+}
+
+func newTestRegistry(t *testing.T, owner signer.Signer, nodes ...signer.Signer) (string, []FileRegistry) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registries []FileRegistry
+	for _, node := range nodes {
+		r, err := NewFileRegistry(dir, owner.Address(), node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registries = append(registries, r)
+	}
+	return dir, registries
+}
+
+func TestFileRegistry(t *testing.T) {
+	ctx := context.Background()
+	owner, a, b, c := newTestSigner(t), newTestSigner(t), newTestSigner(t), newTestSigner(t)
+	dir, registries := newTestRegistry(t, owner, a, b, c)
+	defer os.RemoveAll(dir)
+	ra, rb, rc := registries[0], registries[1], registries[2]
+
+	if info, err := ra.EpochInfo(ctx, 1); err != nil || info.ID != 0 {
+		t.Fatalf("expected a registry without epochs file to have no epochs, got %+v, %v", info, err)
+	}
+	err := WriteEpochs(dir, Epochs{
+		Epochs: []Epoch{
+			{ID: 1, K: 2, T: 1, NextEpoch: 2, Nodes: []common.Address{a.Address(), b.Address()}},
+			{ID: 2, K: 1, T: 0, PrevEpoch: 1, Nodes: []common.Address{b.Address()}},
+		},
+		PSSStatus: []PSSStatus{{OldEpoch: 1, NewEpoch: 2, Status: 1}},
+	}, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ra.EpochInfo(ctx, 1)
+	if err != nil || info.ID != 1 || info.N != 2 || info.K != 2 || info.NextEpoch != 2 || len(info.Nodes) != 0 {
+		t.Fatalf("expected epoch 1 of 2 nodes with none registered, got %+v, %v", info, err)
+	}
+	if whitelisted, _ := rc.Whitelisted(ctx, 1, c.Address()); whitelisted {
+		t.Fatal("expected c not to be whitelisted")
+	}
+	if status, _ := ra.PSSStatus(ctx, 1, 2); status != 1 {
+		t.Fatalf("expected the pss status to be 1, got %v", status)
+	}
+	if err := rc.Register(ctx, 1, details(c, "")); err == nil {
+		t.Fatal("expected a node that is not whitelisted not to be able to register")
+	}
+
+	// nodes are listed in the order of the whitelist, not in the order they registered in
+	if err := rb.Register(ctx, 1, details(b, "/ip4/127.0.0.1/tcp/1080")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ra.Register(ctx, 1, details(a, "")); err != nil {
+		t.Fatal(err)
+	}
+	info, err = rc.EpochInfo(ctx, 1)
+	if err != nil || len(info.Nodes) != 2 || info.Nodes[0] != a.Address() || info.Nodes[1] != b.Address() {
+		t.Fatalf("expected a and b to be the nodes of epoch 1, got %+v, %v", info, err)
+	}
+	if registered, _ := rc.NodeRegistered(ctx, 2, b.Address()); registered {
+		t.Fatal("expected b not to be registered in epoch 2 yet")
+	}
+	nodeDetails, err := rc.NodeDetails(ctx, b.Address())
+	if err != nil || nodeDetails.Position != 2 || nodeDetails.P2PListenAddress != "/ip4/127.0.0.1/tcp/1080" || nodeDetails.PubKx.Cmp(b.PublicKey().X) != 0 {
+		t.Fatalf("expected b to be the second node of epoch 1, got %+v, %v", nodeDetails, err)
+	}
+	if err := rb.Register(ctx, 2, details(b, "")); err != nil {
+		t.Fatal(err)
+	}
+	if nodeDetails, err := rc.NodeDetails(ctx, b.Address()); err != nil || nodeDetails.Position != 1 {
+		t.Fatalf("expected the details of b to be those of its registration in epoch 2, got %+v, %v", nodeDetails, err)
+	}
+	if _, err := rc.NodeDetails(ctx, c.Address()); err == nil {
+		t.Fatal("expected c to have no details")
+	}
+}
+
+func TestFileRegistrySignatures(t *testing.T) {
+	ctx := context.Background()
+	owner, a, b := newTestSigner(t), newTestSigner(t), newTestSigner(t)
+	dir, registries := newTestRegistry(t, owner, a, b)
+	defer os.RemoveAll(dir)
+	ra := registries[0].(*fileRegistry)
+
+	epochs := Epochs{Epochs: []Epoch{{ID: 1, K: 1, Nodes: []common.Address{a.Address(), b.Address()}}}}
+	if err := WriteEpochs(dir, epochs, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ra.EpochInfo(ctx, 1); err == nil {
+		t.Fatal("expected an epochs file that is not signed by the owner to be refused")
+	}
+	if err := WriteEpochs(dir, epochs, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	// registrations in place of b that b did not sign are refused or ignored
+	if err := writeSigned(ra.registrationPath(1, b.Address()), registration{}, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ra.NodeRegistered(ctx, 1, b.Address()); err == nil {
+		t.Fatal("expected a registration without a public key to be refused")
+	}
+	if err := ra.Register(ctx, 1, details(a, "")); err != nil {
+		t.Fatal(err)
+	}
+	forged := ra.registrationPath(1, b.Address())
+	if err := os.Rename(ra.registrationPath(1, a.Address()), forged); err != nil {
+		t.Fatal(err)
+	}
+	if registered, err := ra.NodeRegistered(ctx, 1, b.Address()); err != nil || registered {
+		t.Fatalf("expected the registration of a in place of b to be ignored, got %v, %v", registered, err)
+	}
+	if info, err := ra.EpochInfo(ctx, 1); err != nil || len(info.Nodes) != 0 {
+		t.Fatalf("expected no nodes to be registered, got %+v, %v", info, err)
+	}
+	// registrations signed by b for another epoch or registry are ignored
+	for _, reg := range []registration{
+		{Epoch: 2, Registry: owner.Address()},
+		{Epoch: 1, Registry: a.Address()},
+	} {
+		reg.PubKx, reg.PubKy = (*hexutil.Big)(b.PublicKey().X), (*hexutil.Big)(b.PublicKey().Y)
+		if err := writeSigned(ra.registrationPath(1, b.Address()), reg, b); err != nil {
+			t.Fatal(err)
+		}
+		if registered, err := ra.NodeRegistered(ctx, 1, b.Address()); err != nil || registered {
+			t.Fatalf("expected the registration of b for epoch %v of %v to be ignored, got %v, %v", reg.Epoch, reg.Registry.Hex(), registered, err)
+		}
+	}
+}
+
+func TestFileRegistryEpochsVersion(t *testing.T) {
+	ctx := context.Background()
+	owner, a := newTestSigner(t), newTestSigner(t)
+	dir, registries := newTestRegistry(t, owner, a)
+	defer os.RemoveAll(dir)
+	ra := registries[0]
+
+	if err := WriteEpochs(dir, Epochs{Epochs: []Epoch{{ID: 1, K: 1, Nodes: []common.Address{a.Address()}}}}, owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ra.EpochInfo(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	old, err := ioutil.ReadFile(filepath.Join(dir, EpochsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEpochs(dir, Epochs{Epochs: []Epoch{{ID: 1, K: 1}}}, owner); err != nil {
+		t.Fatal(err)
+	}
+	if whitelisted, err := ra.Whitelisted(ctx, 1, a.Address()); err != nil || whitelisted {
+		t.Fatalf("expected a to be removed from epoch 1 by the second version, got %v, %v", whitelisted, err)
+	}
+
+	// the first version is still signed by the owner, but older than the one read
+	if err := ioutil.WriteFile(filepath.Join(dir, EpochsFile), old, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ra.Whitelisted(ctx, 1, a.Address()); err == nil {
+		t.Fatal("expected an epochs file older than the one read to be refused")
+	}
+}
@@ -0,0 +1,50 @@
+// Package registry is where nodes find the epochs, the nodes of each epoch and the status of PSS
+// between epochs, and where they register themselves. The NodeList contract is the default
+// registry, a directory of signed files can replace it for deployments without Ethereum.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/torusresearch/torus-node/ethwatch"
+)
+
+// Registry types selectable through config.NodeRegistry
+const (
+	TypeContract = "contract"
+	TypeFile     = "file"
+)
+
+// NodeDetails is what a node published about itself when it registered. Position is the index
+// of the node in the epoch it last registered in.
+type NodeDetails struct {
+	DeclaredIP         string
+	Position           int
+	PubKx              *big.Int
+	PubKy              *big.Int
+	TMP2PListenAddress string
+	P2PListenAddress   string
+}
+
+// NodeRegistry reads and updates the registry. Reading the info of an epoch that does not exist
+// returns an EpochInfo with ID 0 and no error, as the contract does.
+type NodeRegistry interface {
+	ethwatch.Reader
+	// NodeDetails returns the details of the last registration of address
+	NodeDetails(ctx context.Context, address common.Address) (NodeDetails, error)
+	NodeRegistered(ctx context.Context, epoch int, address common.Address) (bool, error)
+	// Register registers this node in epoch, Position is assigned by the registry
+	Register(ctx context.Context, epoch int, details NodeDetails) error
+}
+
+// ErrNodeNotFound is returned for the details of nodes that never registered
+type ErrNodeNotFound struct {
+	Address common.Address
+}
+
+func (e ErrNodeNotFound) Error() string {
+	return fmt.Sprintf("node %v is not registered", e.Address.Hex())
+}
@@ -0,0 +1,210 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	logging "github.com/sirupsen/logrus"
+)
+
+// maxRedactDepth bounds the walk over cyclic or deeply nested values
+const maxRedactDepth = 32
+
+var secretTypes = map[reflect.Type]bool{
+	reflect.TypeOf(Int{}):    true,
+	reflect.TypeOf(&Int{}):   true,
+	reflect.TypeOf(Bytes{}):  true,
+	reflect.TypeOf(&Bytes{}): true,
+}
+
+var (
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// secretTypeCache caches containsSecret by reflect.Type
+var secretTypeCache sync.Map
+
+func isSecretField(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "true"
+}
+
+// containsSecret reports whether values of type t may hold a secret.
+// Interfaces are resolved against the dynamic type when the value is walked.
+func containsSecret(t reflect.Type) bool {
+	if cached, ok := secretTypeCache.Load(t); ok {
+		return cached.(bool)
+	}
+	result := typeContainsSecret(t, make(map[reflect.Type]bool))
+	secretTypeCache.Store(t, result)
+	return result
+}
+
+func typeContainsSecret(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if secretTypes[t] {
+		return true
+	}
+	// errors and stringers are logged through their own methods
+	if t.Kind() != reflect.Interface && (t.Implements(errorType) || t.Implements(stringerType)) {
+		return false
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return typeContainsSecret(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if isSecretField(f) || typeContainsSecret(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Redact returns v with every secret replaced by Redacted, for use in logs.
+// Secrets are *Int and *Bytes values and struct fields tagged `secret:"true"`.
+// Errors and fmt.Stringers are left to format themselves.
+// Values that can not hold a secret are returned unchanged, others are
+// converted to maps and slices keyed like their JSON encoding.
+func Redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !containsSecret(rv.Type()) {
+		return v
+	}
+	return redactValue(addressable(rv), 0)
+}
+
+// addressable copies v if needed so that marshalers with pointer receivers,
+// such as the one of big.Int, are still used for its fields
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Elem()
+}
+
+func redactValue(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if secretTypes[t] {
+		return Redacted
+	}
+	if t.Kind() != reflect.Interface && !containsSecret(t) {
+		if v.CanAddr() && t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(marshalerType) {
+			return v.Addr().Interface()
+		}
+		return v.Interface()
+	}
+	if depth > maxRedactDepth {
+		return Redacted
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(addressable(v.Elem()), depth+1)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), depth+1)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			out[fmt.Sprint(key.Interface())] = redactValue(addressable(v.MapIndex(key)), depth+1)
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]interface{})
+		redactStruct(v, depth, out)
+		return out
+	}
+	return v.Interface()
+}
+
+func redactStruct(v reflect.Value, depth int, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+		switch {
+		case isSecretField(f):
+			out[name] = Redacted
+		case f.Anonymous && f.Type.Kind() == reflect.Struct:
+			// embedded structs are flattened like in JSON
+			redactStruct(v.Field(i), depth+1, out)
+		default:
+			out[name] = redactValue(v.Field(i), depth+1)
+		}
+	}
+}
+
+// Formatter redacts the fields of every log entry before passing it on to the wrapped Formatter
+type Formatter struct {
+	logging.Formatter
+}
+
+func (f *Formatter) Format(entry *logging.Entry) ([]byte, error) {
+	data := make(logging.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = Redact(v)
+	}
+	redacted := &logging.Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Caller:  entry.Caller,
+		Message: entry.Message,
+		Buffer:  entry.Buffer,
+	}
+	return f.Formatter.Format(redacted)
+}
+
+// RedactLogs wraps the formatter of logger with a Formatter
+func RedactLogs(logger *logging.Logger) {
+	if _, ok := logger.Formatter.(*Formatter); ok {
+		return
+	}
+	logger.SetFormatter(&Formatter{Formatter: logger.Formatter})
+}
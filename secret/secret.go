@@ -0,0 +1,128 @@
+// Package secret holds share material so that it can be wiped once it is no
+// longer needed, and keeps it out of logs.
+//
+// Wiping is best effort: the garbage collector may already have copied the
+// memory, and big.Int values copied by value share their backing array.
+package secret
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+)
+
+// Redacted is printed in place of secret values
+const Redacted = "[REDACTED]"
+
+// Wipe zeroes the words backing each int and sets it to 0
+func Wipe(ints ...*big.Int) {
+	for _, x := range ints {
+		if x == nil {
+			continue
+		}
+		words := x.Bits()
+		for i := range words {
+			words[i] = 0
+		}
+		x.SetInt64(0)
+	}
+}
+
+// WipeInts wipes every int of a polynomial
+func WipeInts(ints []big.Int) {
+	for i := range ints {
+		Wipe(&ints[i])
+	}
+}
+
+// WipeMatrix wipes every int of a bivariate polynomial
+func WipeMatrix(matrix [][]big.Int) {
+	for i := range matrix {
+		WipeInts(matrix[i])
+	}
+}
+
+// WipeBytes zeroes b
+func WipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Int holds a secret scalar such as a share. It never prints or marshals its value
+// and is wiped by Wipe, or by the garbage collector if Wipe was not called.
+type Int struct {
+	v big.Int
+}
+
+// NewInt copies x into a new Int, the caller remains responsible for wiping x
+func NewInt(x *big.Int) *Int {
+	s := &Int{}
+	s.v.Set(x)
+	runtime.SetFinalizer(s, (*Int).Wipe)
+	return s
+}
+
+// Value returns the secret. It is only valid until Wipe and must not be retained.
+func (s *Int) Value() *big.Int {
+	return &s.v
+}
+
+// Bytes returns a copy of the secret as a big-endian byte slice, which the caller must wipe
+func (s *Int) Bytes() []byte {
+	return s.v.Bytes()
+}
+
+// Wipe zeroes the secret
+func (s *Int) Wipe() {
+	Wipe(&s.v)
+}
+
+func (s *Int) String() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter so that no verb prints the value
+func (s *Int) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+func (s *Int) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+// Bytes holds secret bytes such as a decrypted payload, see Int
+type Bytes struct {
+	b []byte
+}
+
+// NewBytes takes ownership of b, which is wiped together with the returned Bytes
+func NewBytes(b []byte) *Bytes {
+	s := &Bytes{b: b}
+	runtime.SetFinalizer(s, (*Bytes).Wipe)
+	return s
+}
+
+// Value returns the secret. It is only valid until Wipe and must not be retained.
+func (s *Bytes) Value() []byte {
+	return s.b
+}
+
+// Wipe zeroes the secret
+func (s *Bytes) Wipe() {
+	WipeBytes(s.b)
+}
+
+func (s *Bytes) String() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter so that no verb prints the value
+func (s *Bytes) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+func (s *Bytes) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
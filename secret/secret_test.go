@@ -0,0 +1,138 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "123456789123456789123456789123456789"
+
+func testSecretInt() *big.Int {
+	x, _ := new(big.Int).SetString(testSecret, 10)
+	return x
+}
+
+// assertNoSecret checks that neither the decimal nor the hex encoding of testSecret appears in out
+func assertNoSecret(t *testing.T, out string) {
+	x := testSecretInt()
+	assert.NotContains(t, out, x.Text(10))
+	assert.NotContains(t, out, x.Text(16))
+}
+
+type share struct {
+	KeyIndex big.Int `json:"keyindex"`
+	Si       big.Int `json:"si" secret:"true"`
+}
+
+type message struct {
+	Method string
+	Shares []share
+	ByNode map[string]*share
+	Data   interface{}
+}
+
+func TestWipe(t *testing.T) {
+	x := testSecretInt()
+	words := x.Bits()
+	Wipe(x, nil)
+	assert.Equal(t, 0, x.Sign())
+	for _, w := range words {
+		assert.Equal(t, big.Word(0), w)
+	}
+
+	poly := []big.Int{*testSecretInt(), *testSecretInt()}
+	WipeMatrix([][]big.Int{poly})
+	for i := range poly {
+		assert.Equal(t, 0, poly[i].Sign())
+	}
+
+	b := []byte("secret")
+	WipeBytes(b)
+	assert.Equal(t, make([]byte, 6), b)
+}
+
+func TestIntNeverPrints(t *testing.T) {
+	s := NewInt(testSecretInt())
+	assert.Equal(t, testSecret, s.Value().Text(10))
+	for _, verb := range []string{"%v", "%+v", "%s", "%d", "%x"} {
+		out := fmt.Sprintf(verb, s)
+		assert.Equal(t, Redacted, out, verb)
+	}
+	byt, err := json.Marshal(struct{ S *Int }{s})
+	assert.NoError(t, err)
+	assertNoSecret(t, string(byt))
+
+	s.Wipe()
+	assert.Equal(t, 0, s.Value().Sign())
+
+	sb := NewBytes([]byte(testSecret))
+	assert.Equal(t, Redacted, fmt.Sprintf("%s", sb))
+	sb.Wipe()
+	assert.Equal(t, make([]byte, len(testSecret)), sb.Value())
+}
+
+func TestNewIntCopies(t *testing.T) {
+	x := testSecretInt()
+	s := NewInt(x)
+	Wipe(x)
+	assert.Equal(t, testSecret, s.Value().Text(10))
+}
+
+func TestRedact(t *testing.T) {
+	msg := message{
+		Method: "send",
+		Shares: []share{{KeyIndex: *big.NewInt(42), Si: *testSecretInt()}},
+		ByNode: map[string]*share{"1": {KeyIndex: *big.NewInt(42), Si: *testSecretInt()}},
+		Data:   NewInt(testSecretInt()),
+	}
+	for _, v := range []interface{}{msg, &msg, []interface{}{msg}} {
+		byt, err := json.Marshal(Redact(v))
+		assert.NoError(t, err)
+		out := string(byt)
+		assertNoSecret(t, out)
+		assert.Contains(t, out, `"keyindex":42`)
+		assert.Contains(t, out, `"Method":"send"`)
+	}
+	// the original is left untouched
+	assert.Equal(t, testSecret, msg.Shares[0].Si.Text(10))
+
+	// values without secrets are returned as is
+	public := struct{ A big.Int }{*big.NewInt(1)}
+	assert.Equal(t, public, Redact(public))
+	err := errors.New("failed")
+	assert.Equal(t, err, Redact(err))
+	assert.Nil(t, Redact(nil))
+}
+
+func TestRedactLogs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logging.DebugLevel)
+	logger.SetFormatter(&logging.JSONFormatter{})
+	RedactLogs(logger)
+	RedactLogs(logger)
+
+	msg := message{Method: "send", Shares: []share{{KeyIndex: *big.NewInt(42), Si: *testSecretInt()}}}
+	logger.WithFields(logging.Fields{
+		"message": msg,
+		"share":   NewInt(testSecretInt()),
+		"bytes":   NewBytes([]byte(testSecretInt().Text(16))),
+	}).WithError(errors.New("could not verify")).Debug("received message")
+	logger.Debugf("share %v", NewInt(testSecretInt()))
+
+	out := buf.String()
+	assertNoSecret(t, out)
+	assert.Contains(t, out, "received message")
+	assert.Contains(t, out, "could not verify")
+	assert.Contains(t, out, `"keyindex":42`)
+	assert.Equal(t, 2, strings.Count(out, "\n"))
+}
@@ -0,0 +1,21 @@
+package signer
+
+import (
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// NewKeystoreSigner decrypts a go-ethereum (web3 secret storage) keystore file.
+// The decrypted key is then held in memory for the lifetime of the node.
+func NewKeystoreSigner(path string, password string) (*MemorySigner, error) {
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, err
+	}
+	return &MemorySigner{signerType: TypeKeystore, privKey: key.PrivateKey}, nil
+}
@@ -0,0 +1,84 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+)
+
+// Libp2pPrivKey returns a libp2p identity backed by s. Signers which export their key
+// use libp2p's own secp256k1 implementation, otherwise signing is delegated to s.
+func Libp2pPrivKey(s Signer) (libp2pcrypto.PrivKey, error) {
+	if privKey, err := PrivateKey(s); err == nil {
+		return libp2pcrypto.UnmarshalSecp256k1PrivateKey(padPrivKeyBytes(privKey.D.Bytes()))
+	}
+	pubKey, err := libp2pcrypto.UnmarshalSecp256k1PublicKey(ethCrypto.CompressPubkey(s.PublicKey()))
+	if err != nil {
+		return nil, err
+	}
+	return &libp2pPrivKey{signer: s, pubKey: pubKey}, nil
+}
+
+func padPrivKeyBytes(kBytes []byte) []byte {
+	if len(kBytes) < 32 {
+		tmp := make([]byte, 32)
+		copy(tmp[32-len(kBytes):], kBytes)
+		return tmp
+	}
+	return kBytes
+}
+
+// libp2pPrivKey mirrors libp2p's Secp256k1PrivateKey, which signs the sha256 digest
+// and DER encodes the signature
+type libp2pPrivKey struct {
+	signer Signer
+	pubKey libp2pcrypto.PubKey
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+func (k *libp2pPrivKey) Bytes() ([]byte, error) {
+	return nil, ErrKeyNotExportable
+}
+
+func (k *libp2pPrivKey) Raw() ([]byte, error) {
+	return nil, ErrKeyNotExportable
+}
+
+func (k *libp2pPrivKey) Type() pb.KeyType {
+	return pb.KeyType_Secp256k1
+}
+
+func (k *libp2pPrivKey) Equals(o libp2pcrypto.Key) bool {
+	other, ok := o.(*libp2pPrivKey)
+	if !ok {
+		return false
+	}
+	return k.pubKey.Equals(other.pubKey)
+}
+
+func (k *libp2pPrivKey) Sign(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	sig, err := k.signer.SignHash(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, errors.New("unexpected signature length")
+	}
+	return asn1.Marshal(derSignature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	})
+}
+
+func (k *libp2pPrivKey) GetPublic() libp2pcrypto.PubKey {
+	return k.pubKey
+}
@@ -0,0 +1,51 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// MemorySigner signs with a private key held in process memory
+type MemorySigner struct {
+	signerType string
+	privKey    *ecdsa.PrivateKey
+}
+
+func NewMemorySigner(privKey *ecdsa.PrivateKey) *MemorySigner {
+	return &MemorySigner{signerType: TypeMemory, privKey: privKey}
+}
+
+// NewMemorySignerFromHex parses a hex encoded private key, as found in config.EthPrivateKey
+func NewMemorySignerFromHex(hexKey string) (*MemorySigner, error) {
+	privKey, err := ethCrypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewMemorySigner(privKey), nil
+}
+
+func (m *MemorySigner) Type() string {
+	return m.signerType
+}
+
+func (m *MemorySigner) PublicKey() *ecdsa.PublicKey {
+	return &m.privKey.PublicKey
+}
+
+func (m *MemorySigner) Address() ethCommon.Address {
+	return ethCrypto.PubkeyToAddress(m.privKey.PublicKey)
+}
+
+func (m *MemorySigner) SignHash(hash []byte) ([]byte, error) {
+	return ethCrypto.Sign(hash, m.privKey)
+}
+
+func (m *MemorySigner) PrivateKey() *ecdsa.PrivateKey {
+	return m.privKey
+}
+
+func (m *MemorySigner) Close() error {
+	return nil
+}
@@ -0,0 +1,186 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Config locates a secp256k1 key pair on a PKCS#11 token
+type PKCS11Config struct {
+	// Module is the path to the PKCS#11 shared library, e.g. /usr/lib/softhsm/libsofthsm2.so
+	Module     string
+	TokenLabel string
+	Pin        string
+	// KeyLabel is the CKA_LABEL shared by the private and public key objects
+	KeyLabel string
+}
+
+// PKCS11Signer signs on a PKCS#11 token. The private key never leaves the token,
+// so PKCS11Signer does not implement KeyExporter and dkgnode signs tendermint votes through it.
+type PKCS11Signer struct {
+	sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	privKey pkcs11.ObjectHandle
+	pubKey  *ecdsa.PublicKey
+}
+
+func NewPKCS11Signer(cfg PKCS11Config) (*PKCS11Signer, error) {
+	ctx := pkcs11.New(cfg.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("could not load PKCS#11 module %v", cfg.Module)
+	}
+	err := ctx.Initialize()
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, err
+	}
+	p := &PKCS11Signer{ctx: ctx}
+	err = p.open(cfg)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PKCS11Signer) open(cfg PKCS11Config) error {
+	slot, err := findSlot(p.ctx, cfg.TokenLabel)
+	if err != nil {
+		return err
+	}
+	session, err := p.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return err
+	}
+	p.session = session
+	err = p.ctx.Login(session, pkcs11.CKU_USER, cfg.Pin)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return err
+	}
+	p.privKey, err = p.findObject(pkcs11.CKO_PRIVATE_KEY, cfg.KeyLabel)
+	if err != nil {
+		return err
+	}
+	pubKeyHandle, err := p.findObject(pkcs11.CKO_PUBLIC_KEY, cfg.KeyLabel)
+	if err != nil {
+		return err
+	}
+	attrs, err := p.ctx.GetAttributeValue(session, pubKeyHandle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return err
+	}
+	if len(attrs) != 1 {
+		return errors.New("public key object has no CKA_EC_POINT")
+	}
+	p.pubKey, err = parseECPoint(attrs[0].Value)
+	return err
+}
+
+func findSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		tokenInfo, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if tokenInfo.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find PKCS#11 token with label %v", tokenLabel)
+}
+
+func (p *PKCS11Signer) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	err := p.ctx.FindObjectsInit(p.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return 0, err
+	}
+	handles, _, err := p.ctx.FindObjects(p.session, 1)
+	finalErr := p.ctx.FindObjectsFinal(p.session)
+	if err != nil {
+		return 0, err
+	}
+	if finalErr != nil {
+		return 0, finalErr
+	}
+	if len(handles) == 0 {
+		return 0, fmt.Errorf("could not find PKCS#11 object of class %v with label %v", class, label)
+	}
+	return handles[0], nil
+}
+
+// parseECPoint reads CKA_EC_POINT, which is a DER encoded OCTET STRING holding the
+// uncompressed point. Some modules return the raw point instead.
+func parseECPoint(ecPoint []byte) (*ecdsa.PublicKey, error) {
+	var raw []byte
+	rest, err := asn1.Unmarshal(ecPoint, &raw)
+	if err != nil || len(rest) != 0 {
+		raw = ecPoint
+	}
+	return ethCrypto.UnmarshalPubkey(raw)
+}
+
+func (p *PKCS11Signer) Type() string {
+	return TypePKCS11
+}
+
+func (p *PKCS11Signer) PublicKey() *ecdsa.PublicKey {
+	return p.pubKey
+}
+
+func (p *PKCS11Signer) Address() ethCommon.Address {
+	return ethCrypto.PubkeyToAddress(*p.pubKey)
+}
+
+// SignHash signs using CKM_ECDSA, which returns r || s for the raw digest
+func (p *PKCS11Signer) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
+	}
+	// PKCS#11 sessions must not be used concurrently
+	p.Lock()
+	err := p.ctx.SignInit(p.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, p.privKey)
+	if err != nil {
+		p.Unlock()
+		return nil, err
+	}
+	rs, err := p.ctx.Sign(p.session, hash)
+	p.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) != 64 {
+		return nil, fmt.Errorf("unexpected PKCS#11 signature length %d", len(rs))
+	}
+	r := new(big.Int).SetBytes(rs[:32])
+	s := new(big.Int).SetBytes(rs[32:])
+	return recoverableSignature(hash, r, s, p.pubKey)
+}
+
+func (p *PKCS11Signer) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	_ = p.ctx.Logout(p.session)
+	err := p.ctx.CloseSession(p.session)
+	_ = p.ctx.Finalize()
+	p.ctx.Destroy()
+	return err
+}
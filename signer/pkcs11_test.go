@@ -0,0 +1,120 @@
+package signer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+)
+
+// secp256k1 named curve OID 1.3.132.0.10, DER encoded
+var secp256k1ECParams = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// TestPKCS11Signer runs against an initialized SoftHSM token, e.g.
+//
+//	softhsm2-util --init-token --free --label torus --pin 1234 --so-pin 1234
+//	SOFTHSM_MODULE=/usr/lib/softhsm/libsofthsm2.so SOFTHSM_TOKEN_LABEL=torus SOFTHSM_PIN=1234 go test ./signer
+func TestPKCS11Signer(t *testing.T) {
+	module := os.Getenv("SOFTHSM_MODULE")
+	if module == "" {
+		t.Skip("SOFTHSM_MODULE not set, skipping PKCS#11 test")
+	}
+	cfg := PKCS11Config{
+		Module:     module,
+		TokenLabel: os.Getenv("SOFTHSM_TOKEN_LABEL"),
+		Pin:        os.Getenv("SOFTHSM_PIN"),
+		KeyLabel:   "torus-signer-test",
+	}
+	generateTokenKey(t, cfg)
+
+	s, err := NewPKCS11Signer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	defer destroyTokenKey(t, s, cfg.KeyLabel)
+
+	assert.Equal(t, TypePKCS11, s.Type())
+	_, err = PrivateKey(s)
+	assert.Equal(t, ErrKeyNotExportable, err)
+	testSignerCompatibility(t, s)
+
+	libp2pKey, err := Libp2pPrivKey(s)
+	assert.NoError(t, err)
+	data := []byte("libp2p handshake")
+	sig, err := libp2pKey.Sign(data)
+	assert.NoError(t, err)
+	ok, err := libp2pKey.GetPublic().Verify(data, sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func generateTokenKey(t *testing.T, cfg PKCS11Config) {
+	ctx := pkcs11.New(cfg.Module)
+	if err := ctx.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Destroy()
+	defer ctx.Finalize()
+	slot, err := findSlot(ctx, cfg.TokenLabel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.CloseSession(session)
+	if err := ctx.Login(session, pkcs11.CKU_USER, cfg.Pin); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, secp256k1ECParams),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, cfg.KeyLabel),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, cfg.KeyLabel),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// destroyTokenKey removes the generated key pair so that the test can be rerun on the same token
+func destroyTokenKey(t *testing.T, s *PKCS11Signer, label string) {
+	s.Lock()
+	defer s.Unlock()
+	info, err := s.ctx.GetSessionInfo(s.session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := s.ctx.OpenSession(info.SlotID, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.ctx.CloseSession(session)
+	for _, class := range []uint{pkcs11.CKO_PRIVATE_KEY, pkcs11.CKO_PUBLIC_KEY} {
+		if err := s.ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		handles, _, _ := s.ctx.FindObjects(session, 10)
+		_ = s.ctx.FindObjectsFinal(session)
+		for _, handle := range handles {
+			_ = s.ctx.DestroyObject(session, handle)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/torus-common/secp256k1"
+	"github.com/torusresearch/torus-node/config"
+)
+
+// Signer types selectable through config.Signer
+const (
+	TypeMemory   = "memory"
+	TypeKeystore = "keystore"
+	TypePKCS11   = "pkcs11"
+)
+
+// ErrKeyNotExportable is returned when private key material is requested from a
+// signer which does not hold the key in process memory
+var ErrKeyNotExportable = errors.New("signer does not expose private key material")
+
+// Signer holds the node's secp256k1 key and signs on its behalf. Signatures are
+// returned in the 65 byte [R || S || V] format used by go-ethereum, with V being 0 or 1.
+type Signer interface {
+	// Type returns one of the Type* constants
+	Type() string
+	PublicKey() *ecdsa.PublicKey
+	Address() ethCommon.Address
+	// SignHash signs a 32 byte digest
+	SignHash(hash []byte) ([]byte, error)
+	Close() error
+}
+
+// KeyExporter is implemented by signers which hold the private key in process memory
+type KeyExporter interface {
+	PrivateKey() *ecdsa.PrivateKey
+}
+
+// SignData signs the keccak256 hash of data, matching crypto.SignData from torus-common
+func SignData(s Signer, data []byte) ([]byte, error) {
+	return s.SignHash(ethCrypto.Keccak256(data))
+}
+
+// PrivateKey returns the private key held by s, or ErrKeyNotExportable
+func PrivateKey(s Signer) (*ecdsa.PrivateKey, error) {
+	exporter, ok := s.(KeyExporter)
+	if !ok {
+		return nil, ErrKeyNotExportable
+	}
+	return exporter.PrivateKey(), nil
+}
+
+// NewTransactor is the Signer equivalent of bind.NewKeyedTransactor
+func NewTransactor(s Signer) *bind.TransactOpts {
+	keyAddr := s.Address()
+	return &bind.TransactOpts{
+		From: keyAddr,
+		Signer: func(txSigner types.Signer, address ethCommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != keyAddr {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			signature, err := s.SignHash(txSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, signature)
+		},
+	}
+}
+
+// New creates the signer selected in cfg
+func New(cfg *config.Config) (Signer, error) {
+	switch cfg.Signer {
+	case "", TypeMemory:
+		return NewMemorySignerFromHex(cfg.EthPrivateKey)
+	case TypeKeystore:
+		return NewKeystoreSigner(cfg.KeystorePath, cfg.KeystorePassword)
+	case TypePKCS11:
+		return NewPKCS11Signer(PKCS11Config{
+			Module:     cfg.PKCS11Module,
+			TokenLabel: cfg.PKCS11TokenLabel,
+			Pin:        cfg.PKCS11Pin,
+			KeyLabel:   cfg.PKCS11KeyLabel,
+		})
+	default:
+		return nil, fmt.Errorf("unknown signer type %v", cfg.Signer)
+	}
+}
+
+// recoverableSignature converts an (r, s) pair into the [R || S || V] format,
+// normalizing s to the lower half of the curve order and finding V by public key recovery
+func recoverableSignature(hash []byte, r, s *big.Int, pubKey *ecdsa.PublicKey) ([]byte, error) {
+	halfOrder := new(big.Int).Rsh(secp256k1.GeneratorOrder, 1)
+	if s.Cmp(halfOrder) > 0 {
+		s = new(big.Int).Sub(secp256k1.GeneratorOrder, s)
+	}
+	sig := make([]byte, 65)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(sig[32-len(rBytes):32], rBytes)
+	copy(sig[64-len(sBytes):64], sBytes)
+	expected := ethCrypto.FromECDSAPub(pubKey)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := ethCrypto.Ecrecover(hash, sig)
+		if err != nil {
+			continue
+		}
+		if string(recovered) == string(expected) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("could not compute recovery id for signature")
+}
@@ -0,0 +1,104 @@
+package signer
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/torusresearch/torus-common/common"
+	"github.com/torusresearch/torus-common/crypto"
+	"github.com/torusresearch/torus-common/secp256k1"
+)
+
+func testSignerCompatibility(t *testing.T, s Signer) {
+	data := []byte("torus signer test data")
+	sig, err := SignData(s, data)
+	assert.NoError(t, err)
+	assert.Len(t, sig, 65)
+	pk := common.Point{X: *s.PublicKey().X, Y: *s.PublicKey().Y}
+	assert.True(t, crypto.VerifyPtFromRaw(data, pk, sig), "signature should verify against node public key")
+	assert.Equal(t, ethCrypto.PubkeyToAddress(*s.PublicKey()), s.Address())
+}
+
+func TestMemorySigner(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	assert.NoError(t, err)
+	s, err := NewMemorySignerFromHex(ethCommon.Bytes2Hex(ethCrypto.FromECDSA(privKey)))
+	assert.NoError(t, err)
+	testSignerCompatibility(t, s)
+
+	// must produce exactly what the node produced before the signer was introduced
+	data := []byte("legacy")
+	sig, err := SignData(s, data)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.SignData(data, privKey).Raw, sig)
+
+	exported, err := PrivateKey(s)
+	assert.NoError(t, err)
+	assert.Equal(t, privKey.D, exported.D)
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	privKey, err := ethCrypto.GenerateKey()
+	assert.NoError(t, err)
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(privKey, "password")
+	assert.NoError(t, err)
+
+	_, err = NewKeystoreSigner(account.URL.Path, "wrong password")
+	assert.Error(t, err)
+
+	s, err := NewKeystoreSigner(account.URL.Path, "password")
+	assert.NoError(t, err)
+	assert.Equal(t, TypeKeystore, s.Type())
+	assert.Equal(t, account.Address, s.Address())
+	testSignerCompatibility(t, s)
+}
+
+func TestNewTransactor(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	assert.NoError(t, err)
+	s := NewMemorySigner(privKey)
+	opts := NewTransactor(s)
+
+	txSigner := types.HomesteadSigner{}
+	tx := types.NewTransaction(0, ethCommon.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	signedTx, err := opts.Signer(txSigner, s.Address(), tx)
+	assert.NoError(t, err)
+	sender, err := types.Sender(txSigner, signedTx)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Address(), sender)
+
+	_, err = opts.Signer(txSigner, ethCommon.Address{}, tx)
+	assert.Error(t, err)
+}
+
+func TestRecoverableSignature(t *testing.T) {
+	privKey, err := ethCrypto.GenerateKey()
+	assert.NoError(t, err)
+	hash := ethCrypto.Keccak256([]byte("recover"))
+	expected, err := ethCrypto.Sign(hash, privKey)
+	assert.NoError(t, err)
+
+	r := new(big.Int).SetBytes(expected[:32])
+	s := new(big.Int).SetBytes(expected[32:64])
+	sig, err := recoverableSignature(hash, r, s, &privKey.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sig)
+
+	// tokens may return the high-s form of the same signature
+	highS := new(big.Int).Sub(secp256k1.GeneratorOrder, s)
+	sig, err = recoverableSignature(hash, r, highS, &privKey.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sig)
+}
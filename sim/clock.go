@@ -0,0 +1,191 @@
+// Package sim provides the clock and entropy the protocols run on. Nodes use the real clock
+// and crypto/rand, simulations a virtual clock that only moves when they advance it and
+// entropy seeded so that a failing scenario can be run again from its seed.
+package sim
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits on it
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	// After sends the time on the returned channel once d passed
+	After(d time.Duration) <-chan time.Time
+	// NewTicker sends the time on its channel every d, dropping ticks for slow receivers
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is a ticker of a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the clock of the time package
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// VirtualClock is a clock whose time only moves when it is advanced. Goroutines waiting on it
+// are woken in the order of their deadlines.
+type VirtualClock struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a goroutine waiting for at, or a ticker if period is not 0
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewVirtualClock returns a virtual clock at start
+func NewVirtualClock(start time.Time) *VirtualClock {
+	c := &VirtualClock{now: start}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.add(&waiter{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	w := &waiter{at: c.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	c.add(w)
+	return &virtualTicker{clock: c, waiter: w}
+}
+
+// add adds w, the lock must be held
+func (c *VirtualClock) add(w *waiter) {
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+func (c *VirtualClock) remove(w *waiter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Waiters returns the number of sleeps, timers and tickers waiting on the clock
+func (c *VirtualClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until n sleeps, timers or tickers wait on the clock, so that a simulation
+// advances the clock only once the goroutines it runs are waiting
+func (c *VirtualClock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// Advance moves the clock d forward, waking the waiters whose deadlines pass in order
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// AdvanceToNext moves the clock to the earliest deadline of its waiters and wakes them. It
+// returns false if nothing waits on the clock.
+func (c *VirtualClock) AdvanceToNext() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.waiters) == 0 {
+		return false
+	}
+	c.sortWaiters()
+	c.advanceTo(c.waiters[0].at)
+	return true
+}
+
+func (c *VirtualClock) sortWaiters() {
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+}
+
+// advanceTo wakes the waiters until end and moves the clock to end, the lock must be held
+func (c *VirtualClock) advanceTo(end time.Time) {
+	for {
+		c.sortWaiters()
+		if len(c.waiters) == 0 || c.waiters[0].at.After(end) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.at
+		select {
+		case w.c <- c.now:
+		default:
+			// a ticker whose last tick was not received yet
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+	if end.After(c.now) {
+		c.now = end
+	}
+}
+
+type virtualTicker struct {
+	clock  *VirtualClock
+	waiter *waiter
+}
+
+func (t *virtualTicker) C() <-chan time.Time { return t.waiter.c }
+func (t *virtualTicker) Stop()               { t.clock.remove(t.waiter) }
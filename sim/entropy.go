@@ -0,0 +1,36 @@
+package sim
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// Entropy is a stream of random bytes determined by its seed, for simulations only. Draws are
+// reproduced from the seed as long as they are made in the same order, so concurrent nodes of a
+// simulation should each have their own stream from Fork.
+type Entropy struct {
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+var _ io.Reader = &Entropy{}
+
+// NewEntropy returns the stream of seed
+func NewEntropy(seed int64) *Entropy {
+	return &Entropy{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Read fills p with the next bytes of the stream, it never fails
+func (e *Entropy) Read(p []byte) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.rand.Read(p)
+}
+
+// Fork returns a new stream seeded from the next draw of e
+func (e *Entropy) Fork() *Entropy {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return NewEntropy(e.rand.Int63())
+}
@@ -0,0 +1,79 @@
+package sim
+
+import (
+	"bytes"
+	"runtime"
+	"time"
+)
+
+// idleChecks is how many checks in a row must find every goroutine blocked, so that a goroutine
+// woken by the last one to block is seen running
+const idleChecks = 2
+
+// awaitIdle waits until every goroutine but the calling one is blocked, on the clock, a channel
+// or a lock. It returns false if done is closed first.
+func awaitIdle(done <-chan struct{}) bool {
+	var buf []byte
+	checks := 0
+	for wait := time.Duration(0); checks < idleChecks; {
+		select {
+		case <-done:
+			return false
+		default:
+		}
+		buf = stacks(buf)
+		if idle(buf) {
+			checks++
+			runtime.Gosched()
+			continue
+		}
+		checks = 0
+		// dumping the stacks stops the world, so busy goroutines are checked less often the
+		// longer they run
+		if wait < time.Millisecond {
+			wait += 50 * time.Microsecond
+		}
+		time.Sleep(wait)
+	}
+	return true
+}
+
+// stacks returns the stacks of all goroutines, reusing buf
+func stacks(buf []byte) []byte {
+	if len(buf) == 0 {
+		buf = make([]byte, 1<<16)
+	}
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// idle reports whether the goroutines of dump other than the first, which is the caller, are
+// all blocked
+func idle(dump []byte) bool {
+	goroutines := bytes.Split(dump, []byte("\n\n"))
+	for _, g := range goroutines[1:] {
+		start, end := bytes.IndexByte(g, '['), bytes.IndexByte(g, ']')
+		if start < 0 || end < start {
+			continue
+		}
+		state := g[start+1 : end]
+		if i := bytes.IndexByte(state, ','); i >= 0 {
+			state = state[:i]
+		}
+		switch string(state) {
+		case "running", "runnable", "preempted":
+			return false
+		case "syscall":
+			// the signal handling loop waits for signals in a system call
+			if !bytes.Contains(g, []byte("os/signal.signal_recv")) {
+				return false
+			}
+		}
+	}
+	return true
+}
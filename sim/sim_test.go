@@ -0,0 +1,121 @@
+package sim
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVirtualSleep(t *testing.T) {
+	c := NewVirtualClock(start)
+	woken := make(chan time.Duration, 3)
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		go func(d time.Duration) {
+			c.Sleep(d)
+			woken <- d
+		}(d)
+	}
+	c.BlockUntil(3)
+	c.Advance(1500 * time.Millisecond)
+	if got := c.Now(); !got.Equal(start.Add(1500 * time.Millisecond)) {
+		t.Fatalf("expected the clock to move by the advance, got %v", got)
+	}
+	if d := <-woken; d != time.Second || c.Waiters() != 2 {
+		t.Fatalf("expected only the shortest sleep to be woken, got %v with %v waiting", d, c.Waiters())
+	}
+	for c.AdvanceToNext() {
+	}
+	<-woken
+	<-woken
+	if !c.Now().Equal(start.Add(3 * time.Second)) {
+		t.Fatalf("expected every sleep to be woken by 3s, got %v", c.Now())
+	}
+}
+
+func TestVirtualTicker(t *testing.T) {
+	c := NewVirtualClock(start)
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected a tick at 1s, got %v", tick)
+	}
+	// ticks are dropped while the last one was not received
+	c.Advance(3 * time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(2 * time.Second)) {
+		t.Fatalf("expected the tick at 2s, got %v", tick)
+	}
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("expected no other tick, got %v", tick)
+	default:
+	}
+	ticker.Stop()
+	if c.Waiters() != 0 || c.AdvanceToNext() {
+		t.Fatal("expected a stopped ticker not to wait on the clock")
+	}
+}
+
+func TestSimulationStep(t *testing.T) {
+	s := NewSimulation(1)
+	defer s.Stop()
+	woken := make(chan struct{})
+	go func() {
+		s.Clock.Sleep(time.Minute)
+		close(woken)
+	}()
+	// the clock does not move while a goroutine runs, even though another one waits on it
+	s.Clock.BlockUntil(1)
+	for busy := time.Now(); time.Since(busy) < 50*time.Millisecond; {
+		if !s.Clock.Now().Equal(start) {
+			t.Fatal("expected the clock not to move while a goroutine is running")
+		}
+	}
+	<-woken
+	for i := 0; i < 3; i++ {
+		s.Clock.Sleep(time.Hour)
+	}
+	if !s.Clock.Now().Equal(start.Add(3*time.Hour + time.Minute)) {
+		t.Fatalf("expected the stepped clock to wake every sleep at its deadline, got %v", s.Clock.Now())
+	}
+}
+
+func TestRun(t *testing.T) {
+	var seeds []int64
+	Run(t, 42, 3, func(t *testing.T, s *Simulation) {
+		seeds = append(seeds, s.Seed)
+		s.Clock.Sleep(time.Second)
+	})
+	if len(seeds) != 3 || seeds[0] != 42 || seeds[1] == seeds[0] || seeds[2] == seeds[1] {
+		t.Fatalf("expected 3 seeds starting with -seed, got %v", seeds)
+	}
+	var again []int64
+	Run(t, 42, 3, func(t *testing.T, s *Simulation) {
+		again = append(again, s.Seed)
+	})
+	if !reflect.DeepEqual(seeds, again) {
+		t.Fatalf("expected the seeds drawn from a seed to be the same, got %v and %v", seeds, again)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	read := func(e *Entropy) []byte {
+		b := make([]byte, 64)
+		if _, err := e.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	a, b := NewEntropy(7), NewEntropy(7)
+	if !bytes.Equal(read(a), read(b)) {
+		t.Fatal("expected streams of the same seed to be equal")
+	}
+	if !bytes.Equal(read(a.Fork()), read(b.Fork())) {
+		t.Fatal("expected forks of equal streams to be equal")
+	}
+	if bytes.Equal(read(NewEntropy(7)), read(NewEntropy(8))) {
+		t.Fatal("expected streams of different seeds to differ")
+	}
+}
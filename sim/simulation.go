@@ -0,0 +1,88 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Simulation is the virtual clock and the entropy a test runs its nodes on, drawn from Seed so
+// that a failing run picks the same keys and indexes again. Rand draws what is not a secret,
+// such as node indexes.
+type Simulation struct {
+	Seed    int64
+	Clock   *VirtualClock
+	Entropy *Entropy
+	Rand    *rand.Rand
+	done    chan struct{}
+}
+
+// NewSimulation returns a simulation of seed, or of a seed picked from the time if seed is 0.
+// Its clock is stepped with Step until Stop, as nodes wait on it at times tests do not control.
+func NewSimulation(seed int64) *Simulation {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &Simulation{
+		Seed:    seed,
+		Clock:   NewVirtualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Entropy: NewEntropy(seed),
+		Rand:    rand.New(rand.NewSource(seed)),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Run runs f as a subtest on the simulation of each of n seeds. The first seed is seed, or one
+// picked from the time if seed is 0, and the others are drawn from it. Subtests are named after
+// their seed, so that a failing one is run again with the seed in its name.
+func Run(t *testing.T, seed int64, n int, f func(t *testing.T, s *Simulation)) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	seeds := rand.New(rand.NewSource(seed))
+	for i := 0; i < n || i == 0; i++ {
+		s := NewSimulation(seed)
+		t.Run(fmt.Sprintf("seed=%d", s.Seed), func(t *testing.T) {
+			defer s.Stop()
+			t.Logf("simulating with -seed %v", s.Seed)
+			f(t, s)
+		})
+		seed = seeds.Int63()
+	}
+}
+
+// run steps the clock until Stop
+func (s *Simulation) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		if s.Clock.Waiters() == 0 {
+			// the clock is not moved while nothing waits on it, real time is only polled here
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		s.Step()
+	}
+}
+
+// Step waits until every other goroutine is blocked, so that the nodes did everything they can
+// at the current time, and moves the clock to the next deadline of its waiters. The clock thus
+// moves at the same points of a run however fast it is. It returns false if nothing waits on the
+// clock, or once the simulation is stopped.
+func (s *Simulation) Step() bool {
+	if !awaitIdle(s.done) {
+		return false
+	}
+	return s.Clock.AdvanceToNext()
+}
+
+// Stop stops stepping the clock
+func (s *Simulation) Stop() {
+	close(s.done)
+}
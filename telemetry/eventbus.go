@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EventBusMetrics exposes the per topic metrics of an event bus to prometheus.
+// It implements eventbus.Metrics.
+type EventBusMetrics struct {
+	topicLabel func(topic string) string
+	inFlight   *prometheus.GaugeVec
+	latency    *prometheus.HistogramVec
+	dropped    *prometheus.CounterVec
+	queueDepth *prometheus.GaugeVec
+}
+
+// NewEventBusMetrics creates the event bus metrics, topicLabel groups topics so that
+// topics that are not fixed, such as response IDs, do not create a series each
+func NewEventBusMetrics(topicLabel func(topic string) string) *EventBusMetrics {
+	return &EventBusMetrics{
+		topicLabel: topicLabel,
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "eventbus_handlers_in_flight",
+			Help: "number of async event bus handlers running",
+		}, []string{"topic"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "eventbus_handler_seconds",
+			Help:    "time taken by async event bus handlers",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}, []string{"topic"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "eventbus_dropped_events",
+			Help: "number of events dropped because the queue of their topic was full",
+		}, []string{"topic"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "eventbus_queue_depth",
+			Help: "number of events waiting for a worker",
+		}, []string{"topic"}),
+	}
+}
+
+func (m *EventBusMetrics) label(topic string) string {
+	if m.topicLabel == nil {
+		return topic
+	}
+	return m.topicLabel(topic)
+}
+
+// HandlerStarted implements eventbus.Metrics
+func (m *EventBusMetrics) HandlerStarted(topic string) {
+	m.inFlight.WithLabelValues(m.label(topic)).Inc()
+}
+
+// HandlerFinished implements eventbus.Metrics
+func (m *EventBusMetrics) HandlerFinished(topic string, d time.Duration) {
+	label := m.label(topic)
+	m.inFlight.WithLabelValues(label).Dec()
+	m.latency.WithLabelValues(label).Observe(d.Seconds())
+}
+
+// Dropped implements eventbus.Metrics
+func (m *EventBusMetrics) Dropped(topic string) {
+	m.dropped.WithLabelValues(m.label(topic)).Inc()
+}
+
+// QueueDepth implements eventbus.Metrics
+func (m *EventBusMetrics) QueueDepth(topic string, depth int) {
+	m.queueDepth.WithLabelValues(m.label(topic)).Set(float64(depth))
+}
+
+func (m *EventBusMetrics) collector() prometheus.Collector {
+	return eventBusCollector{m}
+}
+
+// eventBusCollector collects the vectors of EventBusMetrics as one Metric
+type eventBusCollector struct {
+	m *EventBusMetrics
+}
+
+func (c eventBusCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.m.inFlight.Describe(ch)
+	c.m.latency.Describe(ch)
+	c.m.dropped.Describe(ch)
+	c.m.queueDepth.Describe(ch)
+}
+
+func (c eventBusCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.inFlight.Collect(ch)
+	c.m.latency.Collect(ch)
+	c.m.dropped.Collect(ch)
+	c.m.queueDepth.Collect(ch)
+}
@@ -0,0 +1,41 @@
+package telemetry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEventBusMetrics(t *testing.T) {
+	metrics := NewEventBusMetrics(func(topic string) string {
+		if strings.HasPrefix(topic, "response:") {
+			return "response"
+		}
+		return topic
+	})
+
+	metrics.HandlerStarted("method")
+	metrics.HandlerStarted("response:1")
+	metrics.HandlerStarted("response:2")
+	metrics.HandlerFinished("response:1", time.Millisecond)
+	metrics.Dropped("method")
+	metrics.QueueDepth("method", 3)
+
+	if v := testutil.ToFloat64(metrics.inFlight.WithLabelValues("method")); v != 1 {
+		t.Fatalf("expected 1 method handler in flight, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.inFlight.WithLabelValues("response")); v != 1 {
+		t.Fatalf("expected response topics to share a label with 1 handler in flight, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.dropped.WithLabelValues("method")); v != 1 {
+		t.Fatalf("expected 1 dropped event, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.queueDepth.WithLabelValues("method")); v != 3 {
+		t.Fatalf("expected a queue depth of 3, got %v", v)
+	}
+	if err := NewTelemetry().Register(metrics); err != nil {
+		t.Fatal(err)
+	}
+}
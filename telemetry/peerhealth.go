@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PeerHealthMetrics exposes the results of pinging the peers of a node to prometheus
+type PeerHealthMetrics struct {
+	rtt           *prometheus.HistogramVec
+	lastSeen      *prometheus.GaugeVec
+	failureStreak *prometheus.GaugeVec
+	failures      *prometheus.CounterVec
+}
+
+// NewPeerHealthMetrics creates the peer health metrics
+func NewPeerHealthMetrics() *PeerHealthMetrics {
+	return &PeerHealthMetrics{
+		rtt: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "p2p_peer_ping_rtt_seconds",
+			Help:    "round trip time of pings to a peer",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"peer"}),
+		lastSeen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "p2p_peer_last_seen_timestamp_seconds",
+			Help: "unix time of the last ping a peer responded to",
+		}, []string{"peer"}),
+		failureStreak: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "p2p_peer_ping_failure_streak",
+			Help: "number of consecutive pings a peer did not respond to",
+		}, []string{"peer"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "p2p_peer_ping_failures_total",
+			Help: "number of pings a peer did not respond to",
+		}, []string{"peer"}),
+	}
+}
+
+// Success records a ping to peer answered after rtt seconds at the unix time seen
+func (m *PeerHealthMetrics) Success(peer string, rtt float64, seen float64) {
+	m.rtt.WithLabelValues(peer).Observe(rtt)
+	m.lastSeen.WithLabelValues(peer).Set(seen)
+	m.failureStreak.WithLabelValues(peer).Set(0)
+}
+
+// Failure records a ping to peer that was not answered, streak is the number of consecutive failures
+func (m *PeerHealthMetrics) Failure(peer string, streak int) {
+	m.failures.WithLabelValues(peer).Inc()
+	m.failureStreak.WithLabelValues(peer).Set(float64(streak))
+}
+
+// Remove deletes the metrics of peer, such as once it left the epochs that are monitored
+func (m *PeerHealthMetrics) Remove(peer string) {
+	m.rtt.DeleteLabelValues(peer)
+	m.lastSeen.DeleteLabelValues(peer)
+	m.failureStreak.DeleteLabelValues(peer)
+	m.failures.DeleteLabelValues(peer)
+}
+
+func (m *PeerHealthMetrics) collector() prometheus.Collector {
+	return peerHealthCollector{m}
+}
+
+// peerHealthCollector collects the vectors of PeerHealthMetrics as one Metric
+type peerHealthCollector struct {
+	m *PeerHealthMetrics
+}
+
+func (c peerHealthCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.m.rtt.Describe(ch)
+	c.m.lastSeen.Describe(ch)
+	c.m.failureStreak.Describe(ch)
+	c.m.failures.Describe(ch)
+}
+
+func (c peerHealthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.rtt.Collect(ch)
+	c.m.lastSeen.Collect(ch)
+	c.m.failureStreak.Collect(ch)
+	c.m.failures.Collect(ch)
+}
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func countMetrics(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
+
+func TestPeerHealthMetrics(t *testing.T) {
+	metrics := NewPeerHealthMetrics()
+
+	metrics.Success("peer-1", 0.02, 1000)
+	metrics.Success("peer-1", 0.04, 1030)
+	metrics.Failure("peer-2", 1)
+	metrics.Failure("peer-2", 2)
+
+	if v := testutil.ToFloat64(metrics.lastSeen.WithLabelValues("peer-1")); v != 1030 {
+		t.Fatalf("expected peer-1 to be last seen at 1030, got %v", v)
+	}
+	if n := countMetrics(metrics.rtt); n != 1 {
+		t.Fatalf("expected a histogram for peer-1, got %v", n)
+	}
+	if v := testutil.ToFloat64(metrics.failureStreak.WithLabelValues("peer-2")); v != 2 {
+		t.Fatalf("expected a failure streak of 2, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.failures.WithLabelValues("peer-2")); v != 2 {
+		t.Fatalf("expected 2 failures, got %v", v)
+	}
+	metrics.Success("peer-2", 0.01, 1060)
+	if v := testutil.ToFloat64(metrics.failureStreak.WithLabelValues("peer-2")); v != 0 {
+		t.Fatalf("expected a response to end the failure streak, got %v", v)
+	}
+	metrics.Remove("peer-1")
+	if n := countMetrics(metrics.rtt); n != 1 {
+		t.Fatalf("expected only the histogram of peer-2 after removing peer-1, got %v", n)
+	}
+	if err := NewTelemetry().Register(metrics); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PeerScoreMetrics exposes the reputation of the peers of a node to prometheus
+type PeerScoreMetrics struct {
+	score    *prometheus.GaugeVec
+	banned   *prometheus.GaugeVec
+	offences *prometheus.CounterVec
+	bans     *prometheus.CounterVec
+}
+
+// NewPeerScoreMetrics creates the peer score metrics
+func NewPeerScoreMetrics() *PeerScoreMetrics {
+	return &PeerScoreMetrics{
+		score: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "p2p_peer_score",
+			Help: "reputation of a peer, peers are banned when it falls to the ban threshold",
+		}, []string{"peer"}),
+		banned: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "p2p_peer_banned",
+			Help: "1 while a peer is banned",
+		}, []string{"peer"}),
+		offences: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "p2p_peer_offences_total",
+			Help: "number of offences reported for a peer",
+		}, []string{"peer", "offence"}),
+		bans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "p2p_peer_bans_total",
+			Help: "number of times a peer was banned",
+		}, []string{"peer"}),
+	}
+}
+
+// Offence records an offence of peer and its resulting score
+func (m *PeerScoreMetrics) Offence(peer, offence string, score float64) {
+	m.offences.WithLabelValues(peer, offence).Inc()
+	m.score.WithLabelValues(peer).Set(score)
+}
+
+// Score sets the score of peer
+func (m *PeerScoreMetrics) Score(peer string, score float64) {
+	m.score.WithLabelValues(peer).Set(score)
+}
+
+// Banned records that peer was banned, or that its ban ended
+func (m *PeerScoreMetrics) Banned(peer string, banned bool) {
+	if banned {
+		m.bans.WithLabelValues(peer).Inc()
+		m.banned.WithLabelValues(peer).Set(1)
+		return
+	}
+	m.banned.WithLabelValues(peer).Set(0)
+}
+
+func (m *PeerScoreMetrics) collector() prometheus.Collector {
+	return peerScoreCollector{m}
+}
+
+// peerScoreCollector collects the vectors of PeerScoreMetrics as one Metric
+type peerScoreCollector struct {
+	m *PeerScoreMetrics
+}
+
+func (c peerScoreCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.m.score.Describe(ch)
+	c.m.banned.Describe(ch)
+	c.m.offences.Describe(ch)
+	c.m.bans.Describe(ch)
+}
+
+func (c peerScoreCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.score.Collect(ch)
+	c.m.banned.Collect(ch)
+	c.m.offences.Collect(ch)
+	c.m.bans.Collect(ch)
+}
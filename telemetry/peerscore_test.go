@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPeerScoreMetrics(t *testing.T) {
+	metrics := NewPeerScoreMetrics()
+
+	metrics.Offence("peer-1", "unauthenticated", -25)
+	metrics.Offence("peer-1", "unauthenticated", -50)
+	metrics.Offence("peer-1", "invalid_protocol_message", -100)
+	metrics.Banned("peer-1", true)
+	metrics.Score("peer-2", -5)
+
+	if v := testutil.ToFloat64(metrics.offences.WithLabelValues("peer-1", "unauthenticated")); v != 2 {
+		t.Fatalf("expected 2 unauthenticated offences, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.score.WithLabelValues("peer-1")); v != -100 {
+		t.Fatalf("expected the score of the last offence, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.banned.WithLabelValues("peer-1")); v != 1 {
+		t.Fatalf("expected peer-1 to be banned, got %v", v)
+	}
+	metrics.Banned("peer-1", false)
+	if v := testutil.ToFloat64(metrics.banned.WithLabelValues("peer-1")); v != 0 {
+		t.Fatalf("expected the ban of peer-1 to end, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.bans.WithLabelValues("peer-1")); v != 1 {
+		t.Fatalf("expected 1 ban, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.score.WithLabelValues("peer-2")); v != -5 {
+		t.Fatalf("expected a score of -5, got %v", v)
+	}
+	if err := NewTelemetry().Register(metrics); err != nil {
+		t.Fatal(err)
+	}
+}
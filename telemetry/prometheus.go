@@ -92,6 +92,10 @@ func (c *Counter) Inc() {
 	c.promCounter.Inc()
 }
 
+func (c *Counter) Add(n float64) {
+	c.promCounter.Add(n)
+}
+
 func (c *Counter) collector() prometheus.Collector {
 	return c.promCounter
 }
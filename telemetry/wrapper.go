@@ -103,10 +103,17 @@ func CreateAndRegister(countersMetadata []CounterMetadata) error {
 // A public method which increments the counter for the specified counter name
 func IncrementCounter(metricName, prefix string) {
 
-	go incrementCounter(metricName, prefix)
+	go incrementCounter(metricName, prefix, 1)
 }
 
-func incrementCounter(metricName, prefix string) {
+// IncrementCounterBy ...
+// A public method which increments the counter for the specified counter name by n
+func IncrementCounterBy(metricName, prefix string, n int) {
+
+	go incrementCounter(metricName, prefix, n)
+}
+
+func incrementCounter(metricName, prefix string, n int) {
 
 	name := prefix + metricName
 
@@ -137,6 +144,6 @@ func incrementCounter(metricName, prefix string) {
 	if !ok {
 		logging.WithField("telemetry: IncrementCounter", name).Errorln("error while casting interface{} to counter")
 	} else {
-		counter.Inc()
+		counter.Add(float64(n))
 	}
 }
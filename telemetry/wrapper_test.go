@@ -3,6 +3,8 @@ package telemetry
 import (
 	"strconv"
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestCreateAndRegister(t *testing.T) {
@@ -81,6 +83,20 @@ func TestCreateAndRegister(t *testing.T) {
 	}
 }
 
+func TestIncrementCounterBy(t *testing.T) {
+	incrementCounter("test_counter_by", "prefix_", 1)
+	incrementCounter("test_counter_by", "prefix_", 3)
+
+	value, _ := counters.Load("prefix_test_counter_by")
+	counter, ok := value.(*Counter)
+	if !ok {
+		t.Fatal("expected the counter to be created")
+	}
+	if v := testutil.ToFloat64(counter.promCounter); v != 4 {
+		t.Fatalf("expected the counter to be 4, got %v", v)
+	}
+}
+
 func BenchmarkIncrementCounter(b *testing.B) {
 
 	for i := 0; i < 10000; i++ {
@@ -7,6 +7,7 @@ import (
 	logging "github.com/sirupsen/logrus"
 	"github.com/torusresearch/tendermint/libs/log"
 	"github.com/torusresearch/torus-node/idmutex"
+	"github.com/torusresearch/torus-node/secret"
 	//"github.com/torusresearch/torus-node/idmutex"
 )
 
@@ -37,8 +38,15 @@ func keyvalsToFields(keyvals []interface{}) logging.Fields {
 	return fields
 }
 
+// newLogger returns a logger which redacts secrets from the tendermint fields
+func newLogger() *logging.Logger {
+	logger := logging.New()
+	secret.RedactLogs(logger)
+	return logger
+}
+
 func NewTMLoggerLogrus() log.Logger {
-	return &tmLoggerLogrus{logging.Fields{}, logging.New()}
+	return &tmLoggerLogrus{logging.Fields{}, newLogger()}
 }
 
 func (l *tmLoggerLogrus) Info(msg string, keyvals ...interface{}) {
@@ -68,7 +76,7 @@ func (l *tmLoggerLogrus) With(keyvals ...interface{}) log.Logger {
 		fields[k] = v
 	}
 
-	return &tmLoggerLogrus{fields, logging.New()}
+	return &tmLoggerLogrus{fields, newLogger()}
 }
 
 func NewNoopLogger() log.Logger {
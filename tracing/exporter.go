@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileExporter writes spans to a file as JSON lines, it is meant for tests and
+// for nodes without a tracing backend. Use ReadSpans to read the file back.
+type FileExporter struct {
+	lock sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// NewFileExporter creates a FileExporter appending to the file at path
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// Export implements Exporter
+func (e *FileExporter) Export(spans []SpanData) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		if err := encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// Shutdown implements Exporter
+func (e *FileExporter) Shutdown() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err := e.w.Flush(); err != nil {
+		_ = e.file.Close()
+		return err
+	}
+	return e.file.Close()
+}
+
+// ReadSpans reads the spans written by a FileExporter
+func ReadSpans(r io.Reader) ([]SpanData, error) {
+	var spans []SpanData
+	decoder := json.NewDecoder(r)
+	for {
+		var span SpanData
+		err := decoder.Decode(&span)
+		if err == io.EOF {
+			return spans, nil
+		}
+		if err != nil {
+			return spans, err
+		}
+		spans = append(spans, span)
+	}
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector with OTLP over HTTP, encoded as JSON
+type OTLPExporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewOTLPExporter creates an OTLPExporter for the collector at endpoint, such as
+// http://localhost:4318. headers are added to every request, e.g. for authentication.
+func NewOTLPExporter(endpoint string, headers map[string]string) *OTLPExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	return &OTLPExporter{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           TraceID         `json:"traceId"`
+	SpanID            SpanID          `json:"spanId"`
+	ParentSpanID      SpanID          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpStatusError is the OTLP status code of a failed span
+const otlpStatusError = 2
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	otlp := make([]otlpAttribute, 0, len(keys))
+	for _, key := range keys {
+		otlp = append(otlp, otlpAttribute{Key: key, Value: otlpValue{StringValue: attributes[key]}})
+	}
+	return otlp
+}
+
+func newOTLPRequest(spans []SpanData) otlpRequest {
+	var request otlpRequest
+	resources := make(map[string]int)
+	for _, span := range spans {
+		i, ok := resources[span.Service]
+		if !ok {
+			i = len(request.ResourceSpans)
+			resources[span.Service] = i
+			var resourceSpans otlpResourceSpans
+			resourceSpans.Resource.Attributes = otlpAttributes(map[string]string{"service.name": span.Service})
+			resourceSpans.ScopeSpans = make([]otlpScopeSpans, 1)
+			resourceSpans.ScopeSpans[0].Scope.Name = "github.com/torusresearch/torus-node/tracing"
+			request.ResourceSpans = append(request.ResourceSpans, resourceSpans)
+		}
+		s := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentID,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		scopeSpans := &request.ResourceSpans[i].ScopeSpans[0]
+		scopeSpans.Spans = append(scopeSpans.Spans, s)
+	}
+	return request
+}
+
+// Export implements Exporter
+func (e *OTLPExporter) Export(spans []SpanData) error {
+	body, err := json.Marshal(newOTLPRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("could not export %v spans to %v: %v %s", len(spans), e.url, resp.Status, message)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// Shutdown implements Exporter
+func (e *OTLPExporter) Shutdown() error {
+	return nil
+}
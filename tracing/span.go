@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, it is shared by every span of the trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid reports whether t is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// MarshalText encodes t as hex
+func (t TraceID) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes t from hex
+func (t *TraceID) UnmarshalText(text []byte) error {
+	return decodeHex(text, t[:])
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid reports whether s is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// MarshalText encodes s as hex, the zero SpanID is encoded as an empty string
+func (s SpanID) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return []byte{}, nil
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes s from hex
+func (s *SpanID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = SpanID{}
+		return nil
+	}
+	return decodeHex(text, s[:])
+}
+
+func decodeHex(text []byte, dst []byte) error {
+	if hex.DecodedLen(len(text)) != len(dst) {
+		return fmt.Errorf("expected %v hex characters, got %v", len(dst)*2, len(text))
+	}
+	_, err := hex.Decode(dst, text)
+	return err
+}
+
+// SpanContext identifies a span across services and nodes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether sc has a trace and span ID
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent encodes sc as a W3C traceparent header, "" if sc is not valid
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"
+}
+
+// ParseTraceparent decodes a W3C traceparent header, an empty header gives the zero SpanContext
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	var sc SpanContext
+	if traceparent == "" {
+		return sc, nil
+	}
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %v", traceparent)
+	}
+	if err := sc.TraceID.UnmarshalText([]byte(parts[1])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace ID in traceparent %v: %v", traceparent, err)
+	}
+	if err := sc.SpanID.UnmarshalText([]byte(parts[2])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span ID in traceparent %v: %v", traceparent, err)
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %v", traceparent)
+	}
+	return sc, nil
+}
+
+// InstanceContext returns a span context derived from key. Nodes use it as the parent of
+// the spans of a protocol instance, such as a keygen, so that the spans of every node
+// taking part in the instance belong to the same trace without any of them sending it
+func InstanceContext(key string) SpanContext {
+	sum := sha256.Sum256([]byte("torus-trace:" + key))
+	var sc SpanContext
+	copy(sc.TraceID[:], sum[:16])
+	copy(sc.SpanID[:], sum[16:24])
+	return sc
+}
+
+func randomSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func randomTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// SpanKind describes the relationship of a span to its parent, the values match OTLP
+type SpanKind int
+
+const (
+	// KindInternal - an operation within a service
+	KindInternal SpanKind = iota + 1
+	// KindServer - handling a call from another service or node
+	KindServer
+	// KindClient - a call to another service or node which waits for the response
+	KindClient
+	// KindProducer - a message sent to other nodes
+	KindProducer
+	// KindConsumer - a message received from another node
+	KindConsumer
+)
+
+// SpanData - a finished span as it is exported
+type SpanData struct {
+	Service    string            `json:"service"`
+	Name       string            `json:"name"`
+	Kind       SpanKind          `json:"kind"`
+	TraceID    TraceID           `json:"traceId"`
+	SpanID     SpanID            `json:"spanId"`
+	ParentID   SpanID            `json:"parentSpanId"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Span - an operation being traced. All methods can be called on a nil Span,
+// which is returned when tracing is disabled
+type Span struct {
+	tracer *Tracer
+
+	lock  sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// Context returns the span context of s
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID}
+}
+
+// Traceparent returns the W3C traceparent header that makes a span the child of s
+func (s *Span) Traceparent() string {
+	return s.Context().Traceparent()
+}
+
+// SetAttribute sets an attribute of s
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = fmt.Sprint(value)
+}
+
+// RecordError marks s as failed with err, nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes s and hands it to the exporter, later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.lock.Unlock()
+	s.tracer.export(data)
+}
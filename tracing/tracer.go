@@ -0,0 +1,234 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Exporter sends finished spans to a tracing backend
+type Exporter interface {
+	Export(spans []SpanData) error
+	Shutdown() error
+}
+
+// Config configures a Tracer, the zero value of every field except Exporter has a default
+type Config struct {
+	Exporter    Exporter
+	ServiceName string
+	// BatchSize is the number of spans exported at once
+	BatchSize int
+	// QueueSize is the number of spans waiting for export, spans ending while the queue is full are dropped
+	QueueSize int
+	// FlushInterval is the longest time a span waits for export
+	FlushInterval time.Duration
+	// OnError is called with the errors of the exporter
+	OnError func(error)
+}
+
+// Tracer creates spans and exports them in batches
+type Tracer struct {
+	config   Config
+	queue    chan SpanData
+	flush    chan chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewTracer creates a Tracer and starts exporting the spans it creates
+func NewTracer(config Config) *Tracer {
+	if config.ServiceName == "" {
+		config.ServiceName = "torus-node"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 512
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 4096
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	t := &Tracer{
+		config: config,
+		queue:  make(chan SpanData, config.QueueSize),
+		flush:  make(chan chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.config.FlushInterval)
+	defer ticker.Stop()
+	var batch []SpanData
+	exportBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.config.Exporter.Export(batch); err != nil && t.config.OnError != nil {
+			t.config.OnError(err)
+		}
+		batch = nil
+	}
+	drain := func() {
+		for {
+			select {
+			case span := <-t.queue:
+				batch = append(batch, span)
+				if len(batch) >= t.config.BatchSize {
+					exportBatch()
+				}
+			default:
+				exportBatch()
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= t.config.BatchSize {
+				exportBatch()
+			}
+		case <-ticker.C:
+			exportBatch()
+		case flushed := <-t.flush:
+			drain()
+			close(flushed)
+		case <-t.stop:
+			drain()
+			return
+		}
+	}
+}
+
+func (t *Tracer) export(span SpanData) {
+	if t == nil {
+		return
+	}
+	select {
+	case t.queue <- span:
+	default:
+	}
+}
+
+// Flush exports the spans that have ended so far
+func (t *Tracer) Flush() {
+	if t == nil {
+		return
+	}
+	flushed := make(chan struct{})
+	select {
+	case t.flush <- flushed:
+		<-flushed
+	case <-t.done:
+	}
+}
+
+// Shutdown exports the remaining spans and shuts the exporter down
+func (t *Tracer) Shutdown() error {
+	if t == nil {
+		return nil
+	}
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+	return t.config.Exporter.Shutdown()
+}
+
+// Start starts a span named name, its parent is the span in ctx if there is one.
+// It returns a nil Span and ctx itself when t is nil.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return t.StartWithParent(ctx, name, kind, SpanFromContext(ctx).Context())
+}
+
+// StartWithParent starts a span named name whose parent is the given span context,
+// usually one received from another node. An invalid parent starts a new trace.
+func (t *Tracer) StartWithParent(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Service:  t.config.ServiceName,
+			Name:     name,
+			Kind:     kind,
+			TraceID:  parent.TraceID,
+			SpanID:   randomSpanID(),
+			ParentID: parent.SpanID,
+			Start:    time.Now(),
+		},
+	}
+	if !parent.IsValid() {
+		span.data.TraceID = randomTraceID()
+		span.data.ParentID = SpanID{}
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, nil if there is none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+var global struct {
+	sync.RWMutex
+	tracer *Tracer
+}
+
+// SetTracer sets the tracer used by the package level functions, nil disables tracing.
+// It returns the previous tracer.
+func SetTracer(t *Tracer) *Tracer {
+	global.Lock()
+	defer global.Unlock()
+	previous := global.tracer
+	global.tracer = t
+	return previous
+}
+
+// GetTracer returns the tracer used by the package level functions, nil if tracing is disabled
+func GetTracer() *Tracer {
+	global.RLock()
+	defer global.RUnlock()
+	return global.tracer
+}
+
+// Start starts a span with the global tracer, see Tracer.Start
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return GetTracer().Start(ctx, name, kind)
+}
+
+// StartWithParent starts a span with the global tracer, see Tracer.StartWithParent
+func StartWithParent(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	return GetTracer().StartWithParent(ctx, name, kind, parent)
+}
+
+// StartFromTraceparent starts a span with the global tracer whose parent is given by a
+// W3C traceparent header, an empty or invalid header starts a new trace
+func StartFromTraceparent(ctx context.Context, name string, kind SpanKind, traceparent string) (context.Context, *Span) {
+	parent, err := ParseTraceparent(traceparent)
+	if err != nil {
+		parent = SpanContext{}
+	}
+	return StartWithParent(ctx, name, kind, parent)
+}
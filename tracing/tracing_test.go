@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type memoryExporter struct {
+	spans chan SpanData
+}
+
+func (e *memoryExporter) Export(spans []SpanData) error {
+	for _, span := range spans {
+		e.spans <- span
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown() error {
+	return nil
+}
+
+func TestTraceparent(t *testing.T) {
+	sc := InstanceContext("keygen-1")
+	parsed, err := ParseTraceparent(sc.Traceparent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != sc {
+		t.Fatalf("expected %v, got %v", sc, parsed)
+	}
+	if sc != InstanceContext("keygen-1") || sc == InstanceContext("keygen-2") {
+		t.Fatal("expected instance contexts to depend only on the key")
+	}
+	for _, invalid := range []string{
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331",
+		"00-0af7651916cd43dd8448eb211c80319-b7ad6b7169203331-01",
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01",
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-zzad6b7169203331-01",
+	} {
+		if _, err := ParseTraceparent(invalid); err == nil {
+			t.Fatalf("expected %v to be invalid", invalid)
+		}
+	}
+	if sc, err := ParseTraceparent(""); err != nil || sc.IsValid() {
+		t.Fatalf("expected an empty traceparent to give no parent, got %v %v", sc, err)
+	}
+}
+
+func TestSpanParents(t *testing.T) {
+	exporter := &memoryExporter{spans: make(chan SpanData, 10)}
+	tracer := NewTracer(Config{Exporter: exporter, ServiceName: "test"})
+
+	ctx, root := tracer.Start(context.Background(), "root", KindServer)
+	_, child := tracer.Start(ctx, "child", KindClient)
+	child.SetAttribute("method", "ping")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	child.End()
+	root.End()
+
+	parent := InstanceContext("pss-1")
+	_, remote := tracer.StartWithParent(context.Background(), "remote", KindConsumer, parent)
+	remote.End()
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	close(exporter.spans)
+
+	spans := make(map[string]SpanData)
+	for span := range exporter.spans {
+		spans[span.Name] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 exported spans, got %v", spans)
+	}
+	if spans["root"].ParentID.IsValid() || !spans["root"].TraceID.IsValid() {
+		t.Fatalf("expected root to start a trace, got %+v", spans["root"])
+	}
+	if spans["child"].TraceID != spans["root"].TraceID || spans["child"].ParentID != spans["root"].SpanID {
+		t.Fatalf("expected child to be a child of root, got %+v", spans["child"])
+	}
+	if spans["child"].Attributes["method"] != "ping" || spans["child"].Error != "failed" || spans["child"].Service != "test" {
+		t.Fatalf("unexpected child span %+v", spans["child"])
+	}
+	if spans["remote"].TraceID != parent.TraceID || spans["remote"].ParentID != parent.SpanID {
+		t.Fatalf("expected remote to be a child of the instance context, got %+v", spans["remote"])
+	}
+}
+
+func TestDisabledTracing(t *testing.T) {
+	previous := SetTracer(nil)
+	defer SetTracer(previous)
+
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "noop", KindInternal)
+	if span != nil || spanCtx != ctx {
+		t.Fatal("expected no span when tracing is disabled")
+	}
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	if span.Traceparent() != "" {
+		t.Fatal("expected no traceparent for a nil span")
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spans.jsonl")
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := NewTracer(Config{Exporter: exporter, ServiceName: "node-1"})
+	ctx, parent := tracer.Start(context.Background(), "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	child.SetAttribute("count", 3)
+	child.End()
+	parent.End()
+	tracer.Flush()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans, err := ReadSpans(file)
+	_ = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans after flushing, got %v", spans)
+	}
+	if spans[0].Name != "child" || spans[0].ParentID != spans[1].SpanID || spans[0].Attributes["count"] != "3" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan otlpRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var request otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- request
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, map[string]string{"Authorization": "secret"})
+	start := time.Unix(1, 0)
+	span := SpanData{
+		Service:    "node-1",
+		Name:       "keygen",
+		Kind:       KindConsumer,
+		TraceID:    InstanceContext("keygen-1").TraceID,
+		SpanID:     randomSpanID(),
+		ParentID:   InstanceContext("keygen-1").SpanID,
+		Start:      start,
+		End:        start.Add(time.Second),
+		Attributes: map[string]string{"keygen": "1"},
+		Error:      "failed",
+	}
+	if err := exporter.Export([]SpanData{span}); err != nil {
+		t.Fatal(err)
+	}
+	request := <-requests
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	resource := request.ResourceSpans[0]
+	if resource.Resource.Attributes[0].Key != "service.name" || resource.Resource.Attributes[0].Value.StringValue != "node-1" {
+		t.Fatalf("unexpected resource %+v", resource.Resource)
+	}
+	got := resource.ScopeSpans[0].Spans[0]
+	if got.TraceID != span.TraceID || got.SpanID != span.SpanID || got.ParentSpanID != span.ParentID {
+		t.Fatalf("unexpected IDs %+v", got)
+	}
+	if got.StartTimeUnixNano != "1000000000" || got.EndTimeUnixNano != "2000000000" || got.Kind != KindConsumer {
+		t.Fatalf("unexpected span %+v", got)
+	}
+	if got.Status.Code != otlpStatusError || got.Status.Message != "failed" {
+		t.Fatalf("unexpected status %+v", got.Status)
+	}
+
+	if err := NewOTLPExporter(server.URL, nil).Export([]SpanData{span}); err == nil {
+		t.Fatal("expected an error when the collector rejects the spans")
+	}
+}
@@ -0,0 +1,389 @@
+// Package txmanager sends transactions and sees them through to confirmation. Gas is estimated,
+// transactions that are not mined in time are replaced with the same nonce at a higher gas
+// price, and their state is persisted so that they are followed again after a restart.
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	logging "github.com/sirupsen/logrus"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+const (
+	// DefaultBumpAfter is how long a transaction may stay unmined before it is replaced
+	DefaultBumpAfter = 3 * time.Minute
+	// DefaultBumpPercent is how much the gas price of a replacement is raised by, nodes refuse
+	// replacements raised by less than 10%
+	DefaultBumpPercent = 20
+	// DefaultGasMarginPercent is added to the estimated gas
+	DefaultGasMarginPercent = 20
+	// DefaultPollInterval is how often receipts are checked
+	DefaultPollInterval = 5 * time.Second
+)
+
+// ErrReverted is returned for transactions that were mined but failed
+var ErrReverted = errors.New("transaction reverted")
+
+// Status is where a transaction is in its life
+type Status string
+
+const (
+	// StatusPending transactions were sent and are not mined yet
+	StatusPending Status = "pending"
+	// StatusMined transactions are waiting for their confirmations
+	StatusMined Status = "mined"
+	// StatusConfirmed transactions were mined and buried under enough blocks
+	StatusConfirmed Status = "confirmed"
+	// StatusFailed transactions were confirmed but reverted
+	StatusFailed Status = "failed"
+)
+
+// Backend is what the manager uses of the chain, it is implemented by ethrpc.Client
+type Backend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Request is a transaction to send. Requests with the ID of a transaction that was already
+// sent return that transaction instead of sending another one.
+type Request struct {
+	ID    string
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+}
+
+// Attempt is a transaction sent for a request, attempts after the first replace the previous one
+type Attempt struct {
+	Hash     common.Hash `json:"hash"`
+	GasPrice *big.Int    `json:"gasPrice"`
+	SentAt   time.Time   `json:"sentAt"`
+}
+
+// State is the state of the transaction of a request. Receipts of go-ethereum 1.8 do not carry
+// their block, so MinedAt is the head the receipt was first seen at.
+type State struct {
+	ID        string         `json:"id"`
+	From      common.Address `json:"from"`
+	To        common.Address `json:"to"`
+	Data      hexutil.Bytes  `json:"data"`
+	Value     *big.Int       `json:"value"`
+	Nonce     uint64         `json:"nonce"`
+	Gas       uint64         `json:"gas"`
+	Attempts  []Attempt      `json:"attempts"`
+	Status    Status         `json:"status"`
+	MinedHash *common.Hash   `json:"minedHash,omitempty"`
+	MinedAt   uint64         `json:"minedAt,omitempty"`
+	LastError string         `json:"lastError,omitempty"`
+}
+
+// last returns the latest attempt
+func (s State) last() Attempt {
+	return s.Attempts[len(s.Attempts)-1]
+}
+
+// Config configures a Manager
+type Config struct {
+	// Confirmations is the number of blocks a transaction must be buried under
+	Confirmations uint64
+	// BumpAfter defaults to DefaultBumpAfter
+	BumpAfter time.Duration
+	// BumpPercent defaults to DefaultBumpPercent
+	BumpPercent int
+	// GasMarginPercent defaults to DefaultGasMarginPercent
+	GasMarginPercent int
+	// MaxGasPrice caps the gas price of replacements, nil leaves it uncapped
+	MaxGasPrice *big.Int
+	// PollInterval defaults to DefaultPollInterval
+	PollInterval time.Duration
+}
+
+// Manager sends transactions signed by a signer. Sends are serialized, so that transactions
+// sent by the manager never collide on their nonce.
+type Manager struct {
+	backend          Backend
+	signer           signer.Signer
+	store            Store
+	confirmations    uint64
+	bumpAfter        time.Duration
+	bumpPercent      int
+	gasMarginPercent int
+	maxGasPrice      *big.Int
+	pollInterval     time.Duration
+	now              func() time.Time
+
+	lock sync.Mutex
+}
+
+// NewManager returns a manager of the transactions of s, persisted in store
+func NewManager(backend Backend, s signer.Signer, store Store, cfg Config) *Manager {
+	m := &Manager{
+		backend:          backend,
+		signer:           s,
+		store:            store,
+		confirmations:    cfg.Confirmations,
+		bumpAfter:        cfg.BumpAfter,
+		bumpPercent:      cfg.BumpPercent,
+		gasMarginPercent: cfg.GasMarginPercent,
+		maxGasPrice:      cfg.MaxGasPrice,
+		pollInterval:     cfg.PollInterval,
+		now:              time.Now,
+	}
+	if m.bumpAfter <= 0 {
+		m.bumpAfter = DefaultBumpAfter
+	}
+	if m.bumpPercent <= 0 {
+		m.bumpPercent = DefaultBumpPercent
+	}
+	if m.gasMarginPercent <= 0 {
+		m.gasMarginPercent = DefaultGasMarginPercent
+	}
+	if m.pollInterval <= 0 {
+		m.pollInterval = DefaultPollInterval
+	}
+	return m
+}
+
+// From returns the address transactions are sent from
+func (m *Manager) From() common.Address {
+	return m.signer.Address()
+}
+
+// State returns the state of the transaction with id, and false if it was never sent
+func (m *Manager) State(id string) (State, bool, error) {
+	return m.store.Load(id)
+}
+
+// Send sends the transaction of req, unless a transaction was already sent for its ID
+func (m *Manager) Send(ctx context.Context, req Request) (State, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	state, ok, err := m.store.Load(req.ID)
+	if err != nil {
+		return State{}, err
+	}
+	if ok {
+		logging.WithFields(logging.Fields{
+			"id":     req.ID,
+			"status": state.Status,
+			"nonce":  state.Nonce,
+		}).Info("following transaction that was already sent")
+		return state, nil
+	}
+	value := req.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	from := m.signer.Address()
+	gas, err := m.backend.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &req.To, Value: value, Data: req.Data})
+	if err != nil {
+		return State{}, err
+	}
+	gas += gas * uint64(m.gasMarginPercent) / 100
+	state = State{
+		ID:    req.ID,
+		From:  from,
+		To:    req.To,
+		Data:  req.Data,
+		Value: value,
+		Gas:   gas,
+	}
+	err = m.sendNew(ctx, &state)
+	if err != nil && isNonceTaken(err) {
+		// the pending nonce was read from a node that had not seen the latest transactions yet
+		err = m.sendNew(ctx, &state)
+	}
+	return state, err
+}
+
+// sendNew sends state with the next nonce of the account at the suggested gas price
+func (m *Manager) sendNew(ctx context.Context, state *State) error {
+	nonce, err := m.backend.PendingNonceAt(ctx, state.From)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := m.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	state.Nonce = nonce
+	return m.send(ctx, state, gasPrice)
+}
+
+// send signs and sends state at gasPrice. The attempt is persisted before it is sent, so that
+// it is followed even if the node stops right after sending it.
+func (m *Manager) send(ctx context.Context, state *State, gasPrice *big.Int) error {
+	tx := types.NewTransaction(state.Nonce, state.To, state.Value, state.Gas, gasPrice, state.Data)
+	signed, err := signer.NewTransactor(m.signer).Signer(types.HomesteadSigner{}, state.From, tx)
+	if err != nil {
+		return err
+	}
+	state.Attempts = append(state.Attempts, Attempt{Hash: signed.Hash(), GasPrice: gasPrice, SentAt: m.now()})
+	state.Status = StatusPending
+	if err := m.store.Save(*state); err != nil {
+		return err
+	}
+	err = m.backend.SendTransaction(ctx, signed)
+	if err != nil && !isKnown(err) {
+		state.LastError = err.Error()
+		if saveErr := m.store.Save(*state); saveErr != nil {
+			logging.WithError(saveErr).Error("could not save transaction state")
+		}
+		return err
+	}
+	logging.WithFields(logging.Fields{
+		"id":       state.ID,
+		"hash":     signed.Hash().Hex(),
+		"nonce":    state.Nonce,
+		"gasPrice": gasPrice,
+		"attempt":  len(state.Attempts),
+	}).Info("sent transaction")
+	return nil
+}
+
+// isKnown returns true for errors of nodes that already have the transaction
+func isKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "known transaction") || strings.Contains(msg, "already known")
+}
+
+// isNonceTaken returns true for errors of nodes that have mined another transaction with the nonce
+func isNonceTaken(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// Wait follows the transaction with id until it is confirmed, replacing it when it is not
+// mined in time. It returns ErrReverted if the transaction failed.
+func (m *Manager) Wait(ctx context.Context, id string) (State, error) {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		state, err := m.update(ctx, id)
+		if err != nil {
+			logging.WithField("id", id).WithError(err).Warn("could not update transaction")
+		} else if state.Status == StatusConfirmed {
+			return state, nil
+		} else if state.Status == StatusFailed {
+			return state, ErrReverted
+		}
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// update checks the receipts of the attempts of the transaction with id, and replaces it if it
+// was not mined within BumpAfter
+func (m *Manager) update(ctx context.Context, id string) (State, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	state, ok, err := m.store.Load(id)
+	if err != nil {
+		return State{}, err
+	}
+	if !ok {
+		return State{}, errors.New("transaction " + id + " was never sent")
+	}
+	if state.Status == StatusConfirmed || state.Status == StatusFailed {
+		return state, nil
+	}
+	header, err := m.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return state, err
+	}
+	head := header.Number.Uint64()
+
+	receipt, minedHash := m.receipt(ctx, state)
+	switch {
+	case receipt != nil:
+		if state.MinedHash == nil || *state.MinedHash != minedHash {
+			state.MinedHash, state.MinedAt = &minedHash, head
+		}
+		state.Status = StatusMined
+		if head >= state.MinedAt+m.confirmations {
+			state.Status = StatusConfirmed
+			if receipt.Status == types.ReceiptStatusFailed {
+				state.Status = StatusFailed
+			}
+			logging.WithFields(logging.Fields{
+				"id":     state.ID,
+				"hash":   minedHash.Hex(),
+				"status": state.Status,
+			}).Info("transaction confirmed")
+		}
+	case state.MinedHash != nil:
+		// the block it was mined in was reorged out
+		logging.WithField("id", state.ID).Warn("mined transaction is pending again")
+		state.MinedHash, state.MinedAt, state.Status = nil, 0, StatusPending
+	case m.now().Sub(state.last().SentAt) >= m.bumpAfter:
+		if err := m.replace(ctx, &state); err != nil {
+			return state, err
+		}
+		return state, nil
+	}
+	return state, m.store.Save(state)
+}
+
+// receipt returns the receipt of the attempt of state that was mined, or nil. Any attempt may
+// have been mined, they all share the nonce.
+func (m *Manager) receipt(ctx context.Context, state State) (*types.Receipt, common.Hash) {
+	for i := len(state.Attempts) - 1; i >= 0; i-- {
+		receipt, err := m.backend.TransactionReceipt(ctx, state.Attempts[i].Hash)
+		if err == nil && receipt != nil {
+			return receipt, state.Attempts[i].Hash
+		}
+	}
+	return nil, common.Hash{}
+}
+
+// replace sends the transaction again with the same nonce at a higher gas price, or with a new
+// nonce if another transaction took its nonce
+func (m *Manager) replace(ctx context.Context, state *State) error {
+	gasPrice := new(big.Int).Mul(state.last().GasPrice, big.NewInt(int64(100+m.bumpPercent)))
+	gasPrice.Div(gasPrice, big.NewInt(100))
+	if suggested, err := m.backend.SuggestGasPrice(ctx); err == nil && suggested.Cmp(gasPrice) > 0 {
+		gasPrice = suggested
+	}
+	if m.maxGasPrice != nil && gasPrice.Cmp(m.maxGasPrice) > 0 {
+		if state.last().GasPrice.Cmp(m.maxGasPrice) >= 0 {
+			logging.WithField("id", state.ID).Warn("transaction is not mined at the maximum gas price")
+			state.Attempts[len(state.Attempts)-1].SentAt = m.now()
+			return m.store.Save(*state)
+		}
+		gasPrice = new(big.Int).Set(m.maxGasPrice)
+	}
+	logging.WithFields(logging.Fields{
+		"id":       state.ID,
+		"nonce":    state.Nonce,
+		"gasPrice": gasPrice,
+	}).Warn("transaction is not mined, replacing it")
+	err := m.send(ctx, state, gasPrice)
+	if err != nil && isNonceTaken(err) {
+		// the nonce may have been taken by an attempt mined since its receipt was checked
+		if receipt, _ := m.receipt(ctx, *state); receipt != nil {
+			return nil
+		}
+		logging.WithFields(logging.Fields{
+			"id":    state.ID,
+			"nonce": state.Nonce,
+		}).Warn("nonce of transaction was taken by another transaction, sending it with a new nonce")
+		return m.sendNew(ctx, state)
+	}
+	return err
+}
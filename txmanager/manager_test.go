@@ -0,0 +1,246 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/torus-node/signer"
+)
+
+// fakeChain keeps sent transactions in a pool until they are mined
+type fakeChain struct {
+	lock     sync.Mutex
+	nonce    uint64
+	gasPrice int64
+	head     uint64
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	sendErr  error
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{gasPrice: 100, head: 1, receipts: make(map[common.Hash]*types.Receipt)}
+}
+
+func (c *fakeChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.nonce, nil
+}
+
+func (c *fakeChain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return big.NewInt(c.gasPrice), nil
+}
+
+func (c *fakeChain) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (c *fakeChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.sendErr != nil {
+		err := c.sendErr
+		c.sendErr = nil
+		return err
+	}
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+func (c *fakeChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	receipt, ok := c.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+}
+
+// mine mines tx in a new block
+func (c *fakeChain) mine(tx *types.Transaction, status uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head++
+	c.nonce = tx.Nonce() + 1
+	c.receipts[tx.Hash()] = &types.Receipt{Status: status, TxHash: tx.Hash()}
+}
+
+func (c *fakeChain) advance(blocks uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head += blocks
+}
+
+func (c *fakeChain) lastSent() *types.Transaction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.sent[len(c.sent)-1]
+}
+
+func newTestManager(t *testing.T, chain *fakeChain, cfg Config) (*Manager, string) {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "txmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(chain, signer.NewMemorySigner(key), store, cfg), dir
+}
+
+var testRequest = Request{ID: "register-1", To: common.HexToAddress("0x1"), Data: []byte{1, 2, 3}}
+
+func TestConfirmations(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	m, dir := newTestManager(t, chain, Config{Confirmations: 2})
+	defer os.RemoveAll(dir)
+
+	state, err := m.Send(ctx, testRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Gas != 120000 || state.Status != StatusPending || len(chain.sent) != 1 {
+		t.Fatalf("expected a pending transaction with a gas margin of 20%%, got %+v", state)
+	}
+	chain.mine(chain.lastSent(), types.ReceiptStatusSuccessful)
+	if state, _ = m.update(ctx, testRequest.ID); state.Status != StatusMined {
+		t.Fatalf("expected the transaction to be mined, got %v", state.Status)
+	}
+	chain.advance(1)
+	if state, _ = m.update(ctx, testRequest.ID); state.Status != StatusMined {
+		t.Fatalf("expected the transaction to wait for its confirmations, got %v", state.Status)
+	}
+	chain.advance(1)
+	state, err = m.Wait(ctx, testRequest.ID)
+	if err != nil || state.Status != StatusConfirmed || *state.MinedHash != chain.sent[0].Hash() {
+		t.Fatalf("expected the transaction to be confirmed, got %+v, %v", state, err)
+	}
+
+	failed := Request{ID: "register-2", To: testRequest.To}
+	if _, err := m.Send(ctx, failed); err != nil {
+		t.Fatal(err)
+	}
+	chain.mine(chain.lastSent(), types.ReceiptStatusFailed)
+	if _, err := m.update(ctx, failed.ID); err != nil {
+		t.Fatal(err)
+	}
+	chain.advance(2)
+	if _, err := m.Wait(ctx, failed.ID); err != ErrReverted {
+		t.Fatalf("expected the transaction to have reverted, got %v", err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	m, dir := newTestManager(t, chain, Config{BumpAfter: time.Minute, MaxGasPrice: big.NewInt(130)})
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if _, err := m.Send(ctx, testRequest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.update(ctx, testRequest.ID); err != nil || len(chain.sent) != 1 {
+		t.Fatalf("expected the transaction not to be replaced before BumpAfter, got %v sent, %v", len(chain.sent), err)
+	}
+	now = now.Add(time.Minute)
+	state, err := m.update(ctx, testRequest.ID)
+	if err != nil || len(chain.sent) != 2 {
+		t.Fatalf("expected the transaction to be replaced, got %v sent, %v", len(chain.sent), err)
+	}
+	if replacement := chain.sent[1]; replacement.Nonce() != chain.sent[0].Nonce() || replacement.GasPrice().Int64() != 120 {
+		t.Fatalf("expected a replacement with the same nonce at a 20%% higher gas price, got nonce %v at %v", replacement.Nonce(), replacement.GasPrice())
+	}
+	now = now.Add(time.Minute)
+	if _, err := m.update(ctx, testRequest.ID); err != nil || chain.lastSent().GasPrice().Int64() != 130 {
+		t.Fatalf("expected the gas price to be capped, got %v, %v", chain.lastSent().GasPrice(), err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := m.update(ctx, testRequest.ID); err != nil || len(chain.sent) != 3 {
+		t.Fatalf("expected no replacement above the maximum gas price, got %v sent, %v", len(chain.sent), err)
+	}
+
+	// the first attempt may still be the one that is mined
+	chain.mine(chain.sent[0], types.ReceiptStatusSuccessful)
+	if state, err = m.update(ctx, testRequest.ID); err != nil || state.Status != StatusConfirmed || *state.MinedHash != chain.sent[0].Hash() {
+		t.Fatalf("expected the first attempt to be confirmed, got %+v, %v", state, err)
+	}
+}
+
+func TestNonceTaken(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	m, dir := newTestManager(t, chain, Config{BumpAfter: time.Minute})
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if _, err := m.Send(ctx, testRequest); err != nil {
+		t.Fatal(err)
+	}
+	// another transaction of the account is mined with the nonce
+	chain.lock.Lock()
+	chain.nonce = 1
+	chain.sendErr = errors.New("nonce too low")
+	chain.lock.Unlock()
+	now = now.Add(time.Minute)
+	state, err := m.update(ctx, testRequest.ID)
+	if err != nil || state.Nonce != 1 || chain.lastSent().Nonce() != 1 {
+		t.Fatalf("expected the transaction to be sent with a new nonce, got %+v, %v", state, err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	m, dir := newTestManager(t, chain, Config{})
+	defer os.RemoveAll(dir)
+
+	// the node stops before the transaction is sent, once it is persisted
+	chain.sendErr = errors.New("connection refused")
+	if _, err := m.Send(ctx, testRequest); err == nil {
+		t.Fatal("expected sending to fail")
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := NewManager(chain, m.signer, store, Config{})
+	state, err := restarted.Send(ctx, testRequest)
+	if err != nil || len(state.Attempts) != 1 || len(chain.sent) != 0 {
+		t.Fatalf("expected the persisted transaction to be followed instead of sending another, got %+v, %v", state, err)
+	}
+	if _, ok, _ := restarted.State("unknown"); ok {
+		t.Fatal("expected no state for a transaction that was never sent")
+	}
+	if _, _, err := store.Load("../escape"); err == nil {
+		t.Fatal("expected ids that are not file names to be refused")
+	}
+}
@@ -0,0 +1,76 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Store persists the state of transactions, so that a node that restarts follows the
+// transactions it sent instead of sending them again with another nonce
+type Store interface {
+	// Load returns the state of the transaction with id, and false if there is none
+	Load(id string) (State, bool, error)
+	Save(state State) error
+}
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// FileStore keeps the state of each transaction in a JSON file of a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid transaction id %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func (s *FileStore) Load(id string) (State, bool, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return State{}, false, err
+	}
+	file, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return State{}, false, nil
+	}
+	if err != nil {
+		return State{}, false, err
+	}
+	var state State
+	if err := json.Unmarshal(file, &state); err != nil {
+		return State{}, false, fmt.Errorf("could not parse state of transaction %v: %v", id, err)
+	}
+	return state, true, nil
+}
+
+func (s *FileStore) Save(state State) error {
+	path, err := s.path(state.ID)
+	if err != nil {
+		return err
+	}
+	file, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	// written to a temporary file first, so that a crash never leaves half a state
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, file, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,223 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Protocols whose message versions are negotiated between nodes
+const (
+	ProtocolP2P     = "p2p"
+	ProtocolKeygen  = "keygen"
+	ProtocolPSS     = "pss"
+	ProtocolMapping = "mapping"
+)
+
+// Optional features of the P2P layer, used only with peers that support them
+const (
+	CapabilityFramedStreams = "framed-streams"
+	CapabilityCompression   = "compression"
+	CapabilityGossip        = "gossip"
+)
+
+// SupportedProtocols lists the message versions of each protocol this node can send and receive
+var SupportedProtocols = map[string][]string{
+	ProtocolP2P:     {P2PMessageVersion},
+	ProtocolKeygen:  {KeygenMessageVersion},
+	ProtocolPSS:     {PSSMessageVersion},
+	ProtocolMapping: {MappingMessageVersion},
+}
+
+// SupportedCapabilities lists the optional features of this node
+var SupportedCapabilities = []string{
+	CapabilityFramedStreams,
+	CapabilityCompression,
+	CapabilityGossip,
+}
+
+// Hello is exchanged by nodes when they connect
+type Hello struct {
+	NodeVersion  string              `json:"nodeVersion"`
+	Protocols    map[string][]string `json:"protocols"`
+	Capabilities []string            `json:"capabilities"`
+}
+
+// LocalHello returns the Hello of this node
+func LocalHello() Hello {
+	protocols := make(map[string][]string, len(SupportedProtocols))
+	for protocol, versions := range SupportedProtocols {
+		protocols[protocol] = append([]string(nil), versions...)
+	}
+	return Hello{
+		NodeVersion:  NodeVersion,
+		Protocols:    protocols,
+		Capabilities: append([]string(nil), SupportedCapabilities...),
+	}
+}
+
+// legacyMessageVersion is the version of every protocol spoken by nodes that predate the handshake
+const legacyMessageVersion = "0.9.0"
+
+// LegacyHello returns the Hello assumed for nodes that predate the handshake, which have no optional features
+func LegacyHello() Hello {
+	return Hello{
+		Protocols: map[string][]string{
+			ProtocolP2P:     {legacyMessageVersion},
+			ProtocolKeygen:  {legacyMessageVersion},
+			ProtocolPSS:     {legacyMessageVersion},
+			ProtocolMapping: {legacyMessageVersion},
+		},
+	}
+}
+
+// Agreement is the result of negotiating with a peer
+type Agreement struct {
+	NodeVersion  string            `json:"nodeVersion"`
+	Versions     map[string]string `json:"versions"`
+	Capabilities []string          `json:"capabilities"`
+}
+
+// Version returns the version to send messages of protocol in, "" if there is none
+func (a Agreement) Version(protocol string) string {
+	return a.Versions[protocol]
+}
+
+// Has returns true if both nodes support capability
+func (a Agreement) Has(capability string) bool {
+	for _, c := range a.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// IncompatibleError is returned by Negotiate if two nodes have no version of a protocol in common
+type IncompatibleError struct {
+	Protocol string
+	Local    []string
+	Remote   []string
+}
+
+func (e *IncompatibleError) Error() string {
+	if len(e.Remote) == 0 {
+		return fmt.Sprintf("peer does not support protocol %v, local versions are %v", e.Protocol, e.Local)
+	}
+	return fmt.Sprintf("no common version of protocol %v, local versions are %v and peer versions are %v", e.Protocol, e.Local, e.Remote)
+}
+
+// Negotiate picks the highest version of each protocol supported by both local and remote, and
+// the capabilities they share. It returns an IncompatibleError for the first protocol, in name
+// order, that they have no version in common for.
+func Negotiate(local, remote Hello) (Agreement, error) {
+	agreement := Agreement{
+		NodeVersion: remote.NodeVersion,
+		Versions:    make(map[string]string, len(local.Protocols)),
+	}
+	protocols := make([]string, 0, len(local.Protocols))
+	for protocol := range local.Protocols {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	var incompatible *IncompatibleError
+	for _, protocol := range protocols {
+		highest := ""
+		for _, v := range local.Protocols[protocol] {
+			if !contains(remote.Protocols[protocol], v) {
+				continue
+			}
+			if highest == "" || CompareVersions(v, highest) > 0 {
+				highest = v
+			}
+		}
+		if highest == "" {
+			if incompatible == nil {
+				incompatible = &IncompatibleError{
+					Protocol: protocol,
+					Local:    local.Protocols[protocol],
+					Remote:   remote.Protocols[protocol],
+				}
+			}
+			continue
+		}
+		agreement.Versions[protocol] = highest
+	}
+	for _, capability := range local.Capabilities {
+		if contains(remote.Capabilities, capability) {
+			agreement.Capabilities = append(agreement.Capabilities, capability)
+		}
+	}
+	sort.Strings(agreement.Capabilities)
+	if incompatible != nil {
+		return agreement, incompatible
+	}
+	return agreement, nil
+}
+
+// CompareVersions compares dot separated versions such as 0.9.0 component by component,
+// numerically when both components are numbers. It returns -1, 0 or 1 if a is lower than,
+// equal to or higher than b.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var ac, bc string
+		if i < len(as) {
+			ac = as[i]
+		}
+		if i < len(bs) {
+			bc = bs[i]
+		}
+		an, aErr := strconv.Atoi(ac)
+		bn, bErr := strconv.Atoi(bc)
+		if ac == "" {
+			an, aErr = 0, nil
+		}
+		if bc == "" {
+			bn, bErr = 0, nil
+		}
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if ac != bc {
+			if ac < bc {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Lowest returns the lowest of versions, ignoring empty ones, "" if there are none
+func Lowest(versions ...string) string {
+	lowest := ""
+	for _, v := range versions {
+		if v != "" && (lowest == "" || CompareVersions(v, lowest) < 0) {
+			lowest = v
+		}
+	}
+	return lowest
+}
+
+// Accepts reports whether a message in version v can be processed by a node that supports
+// versions. Messages of nodes that predate versioning carry no version and are always accepted.
+func Accepts(versions []string, v string) bool {
+	return v == "" || contains(versions, v)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, c := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"0.9.0", "0.9.0", 0},
+		{"0.9.0", "0.10.0", -1},
+		{"1.0.0", "0.10.0", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0", 1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+	} {
+		if got := CompareVersions(c.a, c.b); got != c.expected {
+			t.Fatalf("expected CompareVersions(%v, %v) to be %v, got %v", c.a, c.b, c.expected, got)
+		}
+		if got := CompareVersions(c.b, c.a); got != -c.expected {
+			t.Fatalf("expected CompareVersions(%v, %v) to be %v, got %v", c.b, c.a, -c.expected, got)
+		}
+	}
+}
+
+func hello(nodeVersion string, versions []string, capabilities ...string) Hello {
+	return Hello{
+		NodeVersion: nodeVersion,
+		Protocols: map[string][]string{
+			ProtocolP2P:     versions,
+			ProtocolKeygen:  versions,
+			ProtocolPSS:     versions,
+			ProtocolMapping: versions,
+		},
+		Capabilities: capabilities,
+	}
+}
+
+// TestMixedVersionCluster negotiates between every pair of nodes of a cluster during a rolling
+// upgrade: nodes that predate the handshake, current nodes, upgraded nodes that still speak the
+// current version, and nodes that dropped it.
+func TestMixedVersionCluster(t *testing.T) {
+	legacy := LegacyHello()
+	current := LocalHello()
+	upgraded := hello("0.1.0", []string{"0.9.0", "1.0.0"}, CapabilityFramedStreams, CapabilityGossip, "batching")
+	dropped := hello("0.2.0", []string{"1.0.0"}, CapabilityFramedStreams, CapabilityGossip, "batching")
+
+	for _, c := range []struct {
+		name         string
+		a, b         Hello
+		version      string
+		capabilities []string
+		incompatible bool
+	}{
+		{"legacy with legacy", legacy, legacy, "0.9.0", nil, false},
+		{"current with legacy", current, legacy, "0.9.0", nil, false},
+		{"current with current", current, current, "0.9.0", []string{CapabilityCompression, CapabilityFramedStreams, CapabilityGossip}, false},
+		{"current with upgraded", current, upgraded, "0.9.0", []string{CapabilityFramedStreams, CapabilityGossip}, false},
+		{"upgraded with upgraded", upgraded, upgraded, "1.0.0", []string{"batching", CapabilityFramedStreams, CapabilityGossip}, false},
+		{"upgraded with dropped", upgraded, dropped, "1.0.0", []string{"batching", CapabilityFramedStreams, CapabilityGossip}, false},
+		{"current with dropped", current, dropped, "", []string{CapabilityFramedStreams, CapabilityGossip}, true},
+		{"legacy with dropped", legacy, dropped, "", nil, true},
+	} {
+		for _, order := range [][2]Hello{{c.a, c.b}, {c.b, c.a}} {
+			agreement, err := Negotiate(order[0], order[1])
+			if c.incompatible {
+				incompatible, ok := err.(*IncompatibleError)
+				if !ok {
+					t.Fatalf("%v: expected an IncompatibleError, got %v", c.name, err)
+				}
+				if incompatible.Protocol != ProtocolKeygen {
+					t.Fatalf("%v: expected the first incompatible protocol to be reported, got %v", c.name, incompatible.Protocol)
+				}
+			} else if err != nil {
+				t.Fatalf("%v: %v", c.name, err)
+			}
+			for _, protocol := range []string{ProtocolP2P, ProtocolKeygen, ProtocolPSS, ProtocolMapping} {
+				if got := agreement.Version(protocol); got != c.version {
+					t.Fatalf("%v: expected version %q of %v, got %q", c.name, c.version, protocol, got)
+				}
+			}
+			if !reflect.DeepEqual(agreement.Capabilities, c.capabilities) {
+				t.Fatalf("%v: expected capabilities %v, got %v", c.name, c.capabilities, agreement.Capabilities)
+			}
+			if agreement.NodeVersion != order[1].NodeVersion {
+				t.Fatalf("%v: expected the node version of the peer, got %v", c.name, agreement.NodeVersion)
+			}
+		}
+	}
+}
+
+func TestMissingProtocol(t *testing.T) {
+	remote := LocalHello()
+	delete(remote.Protocols, ProtocolPSS)
+	agreement, err := Negotiate(LocalHello(), remote)
+	incompatible, ok := err.(*IncompatibleError)
+	if !ok || incompatible.Protocol != ProtocolPSS || len(incompatible.Remote) != 0 {
+		t.Fatalf("expected pss to be incompatible, got %v", err)
+	}
+	if agreement.Version(ProtocolKeygen) != KeygenMessageVersion || !agreement.Has(CapabilityGossip) {
+		t.Fatalf("expected the other protocols to be negotiated, got %+v", agreement)
+	}
+}
+
+func TestLowestAndAccepts(t *testing.T) {
+	if got := Lowest("1.0.0", "", "0.9.0", "0.10.0"); got != "0.9.0" {
+		t.Fatalf("expected the lowest version to be 0.9.0, got %v", got)
+	}
+	if got := Lowest("", ""); got != "" {
+		t.Fatalf("expected no lowest version, got %v", got)
+	}
+	if !Accepts([]string{"0.9.0"}, "") {
+		t.Fatal("expected a message without version to be accepted")
+	}
+	if Accepts([]string{"0.9.0"}, "1.0.0") {
+		t.Fatal("expected a message in an unsupported version to be refused")
+	}
+}